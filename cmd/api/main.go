@@ -1,15 +1,63 @@
 package main
 
 import (
+	"context"
 	"fmt"
-    "github.com/jaga-project/jaga-backend/internal/server"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/config"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+	"github.com/jaga-project/jaga-backend/internal/server"
+	"github.com/jaga-project/jaga-backend/internal/tracing"
 )
 
 func main() {
-	server := server.NewServer()
-	fmt.Printf("JAGA Backend Starting ...\n\nStarting server on %s\n", server.Addr)
-	err := server.ListenAndServe()
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	logger, err := logging.New(os.Stdout, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	// Juga mengalihkan package log standar ke handler yang sama.
+	slog.SetDefault(logger)
+
+	if err := run(cfg); err != nil {
+		slog.Error("server stopped with error", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("server stopped gracefully")
+}
+
+func run(cfg *config.Config) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Flush span yang tersisa; ctx utama sudah dibatalkan saat shutdown.
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Warn("failed to flush traces", "error", err)
+		}
+	}()
+
+	srv, err := server.NewServer(cfg)
 	if err != nil {
-		panic(fmt.Sprintf("cannot start server: %s", err))
+		return fmt.Errorf("initialize server: %w", err)
 	}
-}
\ No newline at end of file
+	slog.Info("JAGA backend starting", "addr", srv.Addr())
+	return srv.Run(ctx)
+}
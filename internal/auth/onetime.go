@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// NewOneTimeToken membuat token acak 256-bit untuk link reset password atau
+// verifikasi email. raw dikirim ke user, hash disimpan di database.
+func NewOneTimeToken() (raw, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	raw = base64.RawURLEncoding.EncodeToString(b)
+	return raw, HashOneTimeToken(raw), nil
+}
+
+// HashOneTimeToken cukup memakai SHA-256 tanpa salt karena token sudah
+// berentropi tinggi; bcrypt tidak diperlukan dan membuat lookup tidak mungkin.
+func HashOneTimeToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
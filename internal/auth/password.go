@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxPasswordBytes adalah batas input bcrypt; byte setelahnya diabaikan
+// (atau ditolak oleh x/crypto versi baru).
+const maxPasswordBytes = 72
+
+//go:embed breached_passwords.txt
+var defaultBreachedPasswords string
+
+// PasswordPolicy memeriksa password baru saat registrasi, reset, dan
+// perubahan password.
+type PasswordPolicy struct {
+	minLength int
+	breached  map[string]struct{}
+}
+
+// NewPasswordPolicy memakai daftar password bocor bawaan. Daftar tambahan
+// dimuat dengan LoadFile.
+func NewPasswordPolicy(minLength int) *PasswordPolicy {
+	p := &PasswordPolicy{minLength: minLength, breached: make(map[string]struct{})}
+	p.load(strings.NewReader(defaultBreachedPasswords))
+	return p
+}
+
+// LoadFile menambah daftar password bocor dari file (satu password per
+// baris, baris diawali # diabaikan). Dipanggil saat startup sebelum server
+// melayani request.
+func (p *PasswordPolicy) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("auth: cannot open breached password list: %w", err)
+	}
+	defer f.Close()
+	if err := p.load(f); err != nil {
+		return fmt.Errorf("auth: cannot read breached password list %s: %w", path, err)
+	}
+	return nil
+}
+
+func (p *PasswordPolicy) load(r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.breached[strings.ToLower(line)] = struct{}{}
+	}
+	return sc.Err()
+}
+
+var (
+	ErrPasswordTooShort = errors.New("password is too short")
+	ErrPasswordTooLong  = errors.New("password must be at most 72 bytes")
+	ErrPasswordBreached = errors.New("password appears in a list of breached passwords; choose a different one")
+	ErrPasswordPersonal = errors.New("password must not contain your email or name")
+)
+
+// Validate mengembalikan error yang aman ditampilkan ke user. personal berisi
+// data akun (email, nama) yang tidak boleh dipakai sebagai password.
+func (p *PasswordPolicy) Validate(password string, personal ...string) error {
+	if utf8.RuneCountInString(password) < p.minLength {
+		return fmt.Errorf("%w: minimum %d characters", ErrPasswordTooShort, p.minLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return ErrPasswordTooLong
+	}
+	lower := strings.ToLower(password)
+	if _, ok := p.breached[lower]; ok {
+		return ErrPasswordBreached
+	}
+	for _, v := range personal {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if local, _, ok := strings.Cut(v, "@"); ok {
+			v = local
+		}
+		if len(v) >= 4 && strings.Contains(lower, v) {
+			return ErrPasswordPersonal
+		}
+	}
+	return nil
+}
@@ -2,43 +2,43 @@ package auth
 
 import (
 	"errors"
-	"log"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
-	"github.com/joho/godotenv"
 )
 
-var jwtKey []byte
-
-func init() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("auth: .env file not found, relying on system environment variables")
-	}
+// Manager menandatangani dan memvalidasi JWT dengan secret dari config.
+// Dibuat sekali saat startup lalu diteruskan ke server dan middleware.
+type Manager struct {
+	key []byte
+	ttl time.Duration
+}
 
-	keyStr := os.Getenv("JWT_SECRET")
-	if keyStr == "" {
-		log.Fatal("FATAL: JWT_SECRET environment variable not set. Application cannot start securely.")
-	}
-	jwtKey = []byte(keyStr)
-	log.Println("auth: JWT Secret Key loaded successfully.")
+func NewManager(secret string, ttl time.Duration) *Manager {
+	return &Manager{key: []byte(secret), ttl: ttl}
 }
 
 type Claims struct {
 	UserID string `json:"user_id"`
 	IsAdmin bool   `json:"is_admin"`
+	// Purpose kosong untuk token sesi biasa. Token challenge (mis. langkah
+	// 2FA saat login) selalu berisi purpose dan ditolak oleh ValidateJWT.
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID string, isAdmin bool) (string, time.Time, error) {
-	if len(jwtKey) == 0 {
+const (
+	PurposeMFALogin  = "mfa_login"
+	PurposeMFAEnroll = "mfa_enroll"
+)
+
+func (m *Manager) GenerateJWT(userID string, isAdmin bool) (string, time.Time, error) {
+	if len(m.key) == 0 {
 		return "", time.Time{}, errors.New("JWT secret key is not initialized")
 	}
 
-	expirationTime := time.Now().Add(24 * time.Hour) 
+	expirationTime := time.Now().Add(m.ttl)
 
 	claims := &Claims{
 		UserID:  userID,
@@ -51,7 +51,7 @@ func GenerateJWT(userID string, isAdmin bool) (string, time.Time, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtKey)
+	tokenString, err := token.SignedString(m.key)
 	if err != nil {
 		return "", time.Time{}, err
 	}
@@ -59,14 +59,63 @@ func GenerateJWT(userID string, isAdmin bool) (string, time.Time, error) {
 	return tokenString, expirationTime, nil
 }
 
-func ValidateJWT(tokenString string) (*Claims, error) {
-	if len(jwtKey) == 0 {
+func (m *Manager) ValidateJWT(tokenString string) (*Claims, error) {
+	claims, err := m.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// GenerateChallenge menerbitkan token berumur pendek yang hanya berlaku untuk
+// satu langkah lanjutan (purpose), bukan untuk mengakses API.
+func (m *Manager) GenerateChallenge(userID, purpose string, ttl time.Duration) (string, time.Time, error) {
+	if len(m.key) == 0 {
+		return "", time.Time{}, errors.New("JWT secret key is not initialized")
+	}
+	now := time.Now()
+	expirationTime := now.Add(ttl)
+	claims := &Claims{
+		UserID:  userID,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.New().String(),
+		},
+	}
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.key)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return tokenString, expirationTime, nil
+}
+
+func (m *Manager) ValidateChallenge(tokenString, purpose string) (*Claims, error) {
+	claims, err := m.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != purpose {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+func (m *Manager) parse(tokenString string) (*Claims, error) {
+	if len(m.key) == 0 {
 		return nil, errors.New("JWT secret key is not initialized")
 	}
 
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return m.key, nil
 	})
 
 	if err != nil {
@@ -81,4 +130,4 @@ func ValidateJWT(tokenString string) (*Claims, error) {
 	}
 
 	return claims, nil
-}
\ No newline at end of file
+}
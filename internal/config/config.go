@@ -0,0 +1,653 @@
+// Package config memuat konfigurasi aplikasi dari (urutan prioritas naik):
+// nilai default, file YAML opsional, environment variable (termasuk .env),
+// dan flag command line. Config divalidasi sekali saat startup lalu
+// diteruskan secara eksplisit ke package lain.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/fieldcrypt"
+	"github.com/jaga-project/jaga-backend/internal/ratelimit"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Server     ServerConfig     `yaml:"server"`
+	Database   DatabaseConfig   `yaml:"database"`
+	Auth       AuthConfig       `yaml:"auth"`
+	Storage    StorageConfig    `yaml:"storage"`
+	Log        LogConfig        `yaml:"log"`
+	Tracing    TracingConfig    `yaml:"tracing"`
+	RateLimit  RateLimitConfig  `yaml:"rate_limit"`
+	Mail       MailConfig       `yaml:"mail"`
+	Retention  RetentionConfig  `yaml:"retention"`
+	Encryption EncryptionConfig `yaml:"encryption"`
+	Analysis   AnalysisConfig   `yaml:"analysis"`
+	OCR        OCRConfig        `yaml:"ocr"`
+}
+
+type ServerConfig struct {
+	Port               int           `yaml:"port"`
+	CORSAllowedOrigins []string      `yaml:"cors_allowed_origins"`
+	ReadTimeout        time.Duration `yaml:"read_timeout"`
+	WriteTimeout       time.Duration `yaml:"write_timeout"`
+	IdleTimeout        time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout    time.Duration `yaml:"shutdown_timeout"`
+}
+
+type DatabaseConfig struct {
+	URI             string        `yaml:"uri"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+}
+
+type AuthConfig struct {
+	JWTSecret string        `yaml:"jwt_secret"`
+	TokenTTL  time.Duration `yaml:"token_ttl"`
+
+	PasswordMinLength int `yaml:"password_min_length"`
+	// BreachedPasswordsFile menambah daftar password bocor bawaan (satu
+	// password per baris).
+	BreachedPasswordsFile string        `yaml:"breached_passwords_file"`
+	PasswordResetTTL      time.Duration `yaml:"password_reset_ttl"`
+	EmailVerificationTTL  time.Duration `yaml:"email_verification_ttl"`
+}
+
+type StorageConfig struct {
+	// UploadDir adalah direktori root upload yang dilayani di /uploads/.
+	// Gambar disimpan di subdirektori images.
+	UploadDir string `yaml:"upload_dir"`
+}
+
+type LogConfig struct {
+	// Level salah satu dari debug, info, warn, error.
+	Level string `yaml:"level"`
+	// Format json (default) atau text untuk pengembangan lokal.
+	Format string `yaml:"format"`
+}
+
+type TracingConfig struct {
+	// Exporter "none" (default, no-op) atau "otlp" (OTLP/HTTP).
+	Exporter     string  `yaml:"exporter"`
+	OTLPEndpoint string  `yaml:"otlp_endpoint"`
+	Insecure     bool    `yaml:"insecure"`
+	ServiceName  string  `yaml:"service_name"`
+	SampleRatio  float64 `yaml:"sample_ratio"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Store "memory" (default) atau "postgres" untuk berbagi state antar replika.
+	Store string `yaml:"store"`
+	// TrustProxyHeaders memakai X-Forwarded-For/X-Real-IP sebagai IP client.
+	// Aktifkan hanya di belakang reverse proxy tepercaya.
+	TrustProxyHeaders bool                    `yaml:"trust_proxy_headers"`
+	PerIP             ratelimit.Limit         `yaml:"per_ip"`
+	PerUser           ratelimit.Limit         `yaml:"per_user"`
+	PerAPIKey         ratelimit.Limit         `yaml:"per_api_key"`
+	Login             ratelimit.Limit         `yaml:"login"`
+	Upload            ratelimit.Limit         `yaml:"upload"`
+	Lockout           ratelimit.LockoutPolicy `yaml:"lockout"`
+}
+
+type MailConfig struct {
+	// Driver "log" (default, isi email ditulis ke log untuk pengembangan)
+	// atau "smtp".
+	Driver       string `yaml:"driver"`
+	From         string `yaml:"from"`
+	SMTPHost     string `yaml:"smtp_host"`
+	SMTPPort     int    `yaml:"smtp_port"`
+	SMTPUsername string `yaml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password"`
+	// AppBaseURL adalah URL frontend yang dipakai untuk link di email,
+	// mis. https://jaga.example.com/reset-password?token=...
+	AppBaseURL string `yaml:"app_base_url"`
+}
+
+type RetentionConfig struct {
+	// SoftDeletePeriod adalah lama data yang dihapus lewat API (user,
+	// kendaraan, laporan, kamera) masih bisa di-restore sebelum dihapus
+	// permanen beserta gambarnya. 0 menonaktifkan job purge.
+	SoftDeletePeriod time.Duration `yaml:"soft_delete_period"`
+	PurgeInterval    time.Duration `yaml:"purge_interval"`
+}
+
+// AnalysisConfig berisi parameter pencarian suspect untuk run yang diantrekan
+// otomatis saat laporan dibuat.
+type AnalysisConfig struct {
+	DefaultRadiusKm    float64 `yaml:"default_radius_km"`
+	DefaultWindowHours int     `yaml:"default_window_hours"`
+}
+
+// OCRConfig mengatur pembacaan NIK, nama dan plat dari foto KTP dan STNK
+// yang dijalankan di background setelah upload.
+type OCRConfig struct {
+	// Engine "none" (default, OCR dimatikan) atau "tesseract" (CLI lokal).
+	Engine        string `yaml:"engine"`
+	TesseractPath string `yaml:"tesseract_path"`
+	// Language adalah kode bahasa tesseract, mis. "ind" atau "ind+eng".
+	Language string        `yaml:"language"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+// EncryptionConfig mengatur enkripsi kolom NIK dan nomor telepon. Semua key
+// ditulis dalam base64.
+type EncryptionConfig struct {
+	// Keys memetakan key ID ke key AES-256. Key lama tetap dicantumkan
+	// sampai semua baris selesai di-rotate (cmd/rotate-keys).
+	Keys map[string]string `yaml:"keys"`
+	// ActiveKeyID adalah key yang dipakai untuk mengenkripsi nilai baru.
+	// Boleh kosong bila ring hanya berisi satu key.
+	ActiveKeyID string `yaml:"active_key_id"`
+	// BlindIndexKey adalah key HMAC (minimal 32 byte) untuk pencarian NIK.
+	// Mengganti key ini mengharuskan rotate ulang semua baris.
+	BlindIndexKey string `yaml:"blind_index_key"`
+}
+
+// KeyRing membangun key ring dari config.
+func (c EncryptionConfig) KeyRing() (*fieldcrypt.KeyRing, error) {
+	keys := make(map[string][]byte, len(c.Keys))
+	for id, v := range c.Keys {
+		key, err := fieldcrypt.ParseKey(v)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		keys[id] = key
+	}
+	active := c.ActiveKeyID
+	if active == "" && len(keys) == 1 {
+		for id := range keys {
+			active = id
+		}
+	}
+	indexKey, err := fieldcrypt.ParseKey(c.BlindIndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("blind index key: %w", err)
+	}
+	return fieldcrypt.NewKeyRing(keys, active, indexKey)
+}
+
+// parseKeyList membaca format env "id1:base64,id2:base64".
+func parseKeyList(v string) (map[string]string, error) {
+	keys := map[string]string{}
+	for _, item := range strings.Split(v, ",") {
+		id, key, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok || id == "" || key == "" {
+			return nil, fmt.Errorf("FIELD_ENCRYPTION_KEYS must look like id1:base64key,id2:base64key, got item %q", item)
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+// Default mengembalikan nilai yang sebelumnya di-hard-code.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			CORSAllowedOrigins: []string{"http://localhost:3000"},
+			ReadTimeout:        10 * time.Second,
+			WriteTimeout:       30 * time.Second,
+			IdleTimeout:        time.Minute,
+			ShutdownTimeout:    30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			MaxOpenConns:    10,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: time.Hour,
+		},
+		Auth: AuthConfig{
+			TokenTTL:             24 * time.Hour,
+			PasswordMinLength:    8,
+			PasswordResetTTL:     time.Hour,
+			EmailVerificationTTL: 48 * time.Hour,
+		},
+		Storage: StorageConfig{
+			UploadDir: "./uploads",
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Exporter:     "none",
+			OTLPEndpoint: "localhost:4318",
+			ServiceName:  "jaga-backend",
+			SampleRatio:  1,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:   true,
+			Store:     "memory",
+			PerIP:     ratelimit.Limit{Requests: 300, Per: time.Minute, Burst: 100},
+			PerUser:   ratelimit.Limit{Requests: 120, Per: time.Minute, Burst: 60},
+			PerAPIKey: ratelimit.Limit{Requests: 600, Per: time.Minute, Burst: 200},
+			Login:     ratelimit.Limit{Requests: 10, Per: time.Minute, Burst: 5},
+			Upload:    ratelimit.Limit{Requests: 30, Per: time.Hour, Burst: 10},
+			Lockout: ratelimit.LockoutPolicy{
+				Threshold:  5,
+				BaseDelay:  30 * time.Second,
+				MaxDelay:   time.Hour,
+				ResetAfter: time.Hour,
+			},
+		},
+		Mail: MailConfig{
+			Driver:     "log",
+			From:       "JAGA <no-reply@localhost>",
+			SMTPPort:   587,
+			AppBaseURL: "http://localhost:3000",
+		},
+		Retention: RetentionConfig{
+			SoftDeletePeriod: 30 * 24 * time.Hour,
+			PurgeInterval:    time.Hour,
+		},
+		Analysis: AnalysisConfig{
+			DefaultRadiusKm:    5,
+			DefaultWindowHours: 24,
+		},
+		OCR: OCRConfig{
+			Engine:        "none",
+			TesseractPath: "tesseract",
+			Language:      "ind",
+			Timeout:       30 * time.Second,
+		},
+	}
+}
+
+// Load membaca konfigurasi dari semua sumber. args biasanya os.Args[1:].
+// Path file YAML diambil dari flag -config atau env JAGA_CONFIG.
+func Load(args []string) (*Config, error) {
+	cfg, err := read(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadKeyRotation membaca sumber yang sama dengan Load tetapi hanya
+// memvalidasi pengaturan database, log dan enkripsi, sehingga cmd/rotate-keys
+// bisa dijalankan tanpa secret aplikasi lain seperti JWT_SECRET atau SMTP.
+func LoadKeyRotation(args []string) (*Config, error) {
+	cfg, err := read(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.ValidateKeyRotation(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func read(args []string) (*Config, error) {
+	// .env opsional; tanpa file ini environment sistem yang dipakai.
+	_ = godotenv.Load()
+
+	cfg := Default()
+
+	fs := flag.NewFlagSet("jaga", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv("JAGA_CONFIG"), "path to YAML config file")
+	port := fs.Int("port", 0, "HTTP port (overrides PORT)")
+	dbURI := fs.String("database-uri", "", "Postgres connection string (overrides POSTGRES_URI)")
+	uploadDir := fs.String("upload-dir", "", "upload root directory (overrides UPLOAD_DIR)")
+	logLevel := fs.String("log-level", "", "log level: debug, info, warn, error (overrides LOG_LEVEL)")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configPath != "" {
+		if err := loadFile(&cfg, *configPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyEnv(&cfg); err != nil {
+		return nil, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Server.Port = *port
+		case "database-uri":
+			cfg.Database.URI = *dbURI
+		case "upload-dir":
+			cfg.Storage.UploadDir = *uploadDir
+		case "log-level":
+			cfg.Log.Level = *logLevel
+		}
+	})
+	return &cfg, nil
+}
+
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: cannot read %s: %w", path, err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil {
+		return fmt.Errorf("config: invalid YAML in %s: %w", path, err)
+	}
+	return nil
+}
+
+func applyEnv(cfg *Config) error {
+	var errs []error
+
+	envString("CORS_ALLOWED_ORIGINS", func(v string) {
+		origins := strings.Split(v, ",")
+		for i := range origins {
+			origins[i] = strings.TrimSpace(origins[i])
+		}
+		cfg.Server.CORSAllowedOrigins = origins
+	})
+	envString("POSTGRES_URI", func(v string) { cfg.Database.URI = v })
+	envString("JWT_SECRET", func(v string) { cfg.Auth.JWTSecret = v })
+	envString("UPLOAD_DIR", func(v string) { cfg.Storage.UploadDir = v })
+	envString("LOG_LEVEL", func(v string) { cfg.Log.Level = v })
+	envString("LOG_FORMAT", func(v string) { cfg.Log.Format = v })
+	envString("TRACING_EXPORTER", func(v string) { cfg.Tracing.Exporter = v })
+	envString("OTEL_EXPORTER_OTLP_ENDPOINT", func(v string) { cfg.Tracing.OTLPEndpoint = v })
+	envString("OTEL_SERVICE_NAME", func(v string) { cfg.Tracing.ServiceName = v })
+	envString("RATE_LIMIT_STORE", func(v string) { cfg.RateLimit.Store = v })
+	envString("BREACHED_PASSWORDS_FILE", func(v string) { cfg.Auth.BreachedPasswordsFile = v })
+	envString("MAIL_DRIVER", func(v string) { cfg.Mail.Driver = v })
+	envString("MAIL_FROM", func(v string) { cfg.Mail.From = v })
+	envString("SMTP_HOST", func(v string) { cfg.Mail.SMTPHost = v })
+	envString("SMTP_USERNAME", func(v string) { cfg.Mail.SMTPUsername = v })
+	envString("SMTP_PASSWORD", func(v string) { cfg.Mail.SMTPPassword = v })
+	envString("APP_BASE_URL", func(v string) { cfg.Mail.AppBaseURL = v })
+	envString("FIELD_ENCRYPTION_ACTIVE_KEY", func(v string) { cfg.Encryption.ActiveKeyID = v })
+	envString("OCR_ENGINE", func(v string) { cfg.OCR.Engine = v })
+	envString("TESSERACT_PATH", func(v string) { cfg.OCR.TesseractPath = v })
+	envString("OCR_LANGUAGE", func(v string) { cfg.OCR.Language = v })
+	envString("BLIND_INDEX_KEY", func(v string) { cfg.Encryption.BlindIndexKey = v })
+	envString("FIELD_ENCRYPTION_KEYS", func(v string) {
+		keys, err := parseKeyList(v)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		cfg.Encryption.Keys = keys
+	})
+
+	errs = append(errs,
+		envInt("PORT", &cfg.Server.Port),
+		envInt("DB_MAX_OPEN_CONNS", &cfg.Database.MaxOpenConns),
+		envInt("DB_MAX_IDLE_CONNS", &cfg.Database.MaxIdleConns),
+		envDuration("DB_CONN_MAX_LIFETIME", &cfg.Database.ConnMaxLifetime),
+		envDuration("HTTP_READ_TIMEOUT", &cfg.Server.ReadTimeout),
+		envDuration("HTTP_WRITE_TIMEOUT", &cfg.Server.WriteTimeout),
+		envDuration("HTTP_IDLE_TIMEOUT", &cfg.Server.IdleTimeout),
+		envDuration("SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout),
+		envDuration("JWT_TTL", &cfg.Auth.TokenTTL),
+		envInt("PASSWORD_MIN_LENGTH", &cfg.Auth.PasswordMinLength),
+		envInt("SMTP_PORT", &cfg.Mail.SMTPPort),
+		envBool("OTEL_EXPORTER_OTLP_INSECURE", &cfg.Tracing.Insecure),
+		envFloat("TRACING_SAMPLE_RATIO", &cfg.Tracing.SampleRatio),
+		envBool("RATE_LIMIT_ENABLED", &cfg.RateLimit.Enabled),
+		envBool("RATE_LIMIT_TRUST_PROXY", &cfg.RateLimit.TrustProxyHeaders),
+		envDuration("SOFT_DELETE_RETENTION", &cfg.Retention.SoftDeletePeriod),
+		envDuration("PURGE_INTERVAL", &cfg.Retention.PurgeInterval),
+		envFloat("ANALYSIS_DEFAULT_RADIUS_KM", &cfg.Analysis.DefaultRadiusKm),
+		envInt("ANALYSIS_DEFAULT_WINDOW_HOURS", &cfg.Analysis.DefaultWindowHours),
+		envDuration("OCR_TIMEOUT", &cfg.OCR.Timeout),
+	)
+	return errors.Join(errs...)
+}
+
+func envString(key string, set func(string)) {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		set(v)
+	}
+}
+
+func envInt(key string, dst *int) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%s must be an integer, got %q", key, v)
+	}
+	*dst = n
+	return nil
+}
+
+func envBool(key string, dst *bool) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%s must be true or false, got %q", key, v)
+	}
+	*dst = b
+	return nil
+}
+
+func envFloat(key string, dst *float64) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fmt.Errorf("%s must be a number, got %q", key, v)
+	}
+	*dst = f
+	return nil
+}
+
+func envDuration(key string, dst *time.Duration) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%s must be a duration such as 30s or 5m, got %q", key, v)
+	}
+	*dst = d
+	return nil
+}
+
+// Validate mengumpulkan semua kesalahan konfigurasi sekaligus agar operator
+// tidak perlu memperbaikinya satu per satu.
+func (c *Config) Validate() error {
+	return collect(c.validateServer, c.validateDatabase, c.validateAuth, c.validateLog,
+		c.validateServices, c.validateEncryption)
+}
+
+// ValidateKeyRotation hanya memeriksa bagian yang dipakai cmd/rotate-keys.
+func (c *Config) ValidateKeyRotation() error {
+	return collect(c.validateDatabase, c.validateLog, c.validateEncryption)
+}
+
+type addFunc func(format string, args ...interface{})
+
+func collect(checks ...func(add addFunc)) error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+	for _, check := range checks {
+		check(add)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (c *Config) validateServer(add addFunc) {
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		add("server.port must be between 1 and 65535 (set PORT or -port), got %d", c.Server.Port)
+	}
+	if len(c.Server.CORSAllowedOrigins) == 0 {
+		add("server.cors_allowed_origins must not be empty (set CORS_ALLOWED_ORIGINS)")
+	}
+	if c.Server.ReadTimeout <= 0 || c.Server.WriteTimeout <= 0 || c.Server.IdleTimeout <= 0 {
+		add("server read/write/idle timeouts must be positive")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		add("server.shutdown_timeout must be positive, got %s", c.Server.ShutdownTimeout)
+	}
+}
+
+func (c *Config) validateDatabase(add addFunc) {
+	if c.Database.URI == "" {
+		add("database.uri is required (set POSTGRES_URI or -database-uri)")
+	}
+	if c.Database.MaxOpenConns < 1 {
+		add("database.max_open_conns must be at least 1, got %d", c.Database.MaxOpenConns)
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		add("database.max_idle_conns must be between 0 and max_open_conns (%d), got %d", c.Database.MaxOpenConns, c.Database.MaxIdleConns)
+	}
+	if c.Database.ConnMaxLifetime < 0 {
+		add("database.conn_max_lifetime must not be negative, got %s", c.Database.ConnMaxLifetime)
+	}
+}
+
+func (c *Config) validateAuth(add addFunc) {
+	if c.Auth.JWTSecret == "" {
+		add("auth.jwt_secret is required (set JWT_SECRET)")
+	}
+	if c.Auth.TokenTTL <= 0 {
+		add("auth.token_ttl must be positive, got %s", c.Auth.TokenTTL)
+	}
+	if c.Auth.PasswordMinLength < 8 || c.Auth.PasswordMinLength > 72 {
+		add("auth.password_min_length must be between 8 and 72, got %d", c.Auth.PasswordMinLength)
+	}
+	if c.Auth.BreachedPasswordsFile != "" {
+		if _, err := os.Stat(c.Auth.BreachedPasswordsFile); err != nil {
+			add("auth.breached_passwords_file: %v", err)
+		}
+	}
+	if c.Auth.PasswordResetTTL <= 0 || c.Auth.EmailVerificationTTL <= 0 {
+		add("auth.password_reset_ttl and auth.email_verification_ttl must be positive")
+	}
+
+	if c.Storage.UploadDir == "" {
+		add("storage.upload_dir must not be empty")
+	}
+}
+
+func (c *Config) validateLog(add addFunc) {
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		add("log.level must be one of debug, info, warn, error, got %q", c.Log.Level)
+	}
+	switch strings.ToLower(c.Log.Format) {
+	case "json", "text":
+	default:
+		add("log.format must be json or text, got %q", c.Log.Format)
+	}
+}
+
+// validateServices memeriksa tracing, mail, rate limit, retensi, analisis
+// dan OCR.
+func (c *Config) validateServices(add addFunc) {
+	switch c.Tracing.Exporter {
+	case "none", "":
+	case "otlp":
+		if c.Tracing.OTLPEndpoint == "" {
+			add("tracing.otlp_endpoint is required when tracing.exporter is otlp")
+		}
+	default:
+		add("tracing.exporter must be none or otlp, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		add("tracing.sample_ratio must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	}
+
+	switch c.Mail.Driver {
+	case "log":
+	case "smtp":
+		if c.Mail.SMTPHost == "" {
+			add("mail.smtp_host is required when mail.driver is smtp (set SMTP_HOST)")
+		}
+		if c.Mail.SMTPPort < 1 || c.Mail.SMTPPort > 65535 {
+			add("mail.smtp_port must be between 1 and 65535, got %d", c.Mail.SMTPPort)
+		}
+	default:
+		add("mail.driver must be log or smtp, got %q", c.Mail.Driver)
+	}
+	if c.Mail.From == "" {
+		add("mail.from must not be empty (set MAIL_FROM)")
+	}
+	if !strings.HasPrefix(c.Mail.AppBaseURL, "http://") && !strings.HasPrefix(c.Mail.AppBaseURL, "https://") {
+		add("mail.app_base_url must be an http(s) URL (set APP_BASE_URL), got %q", c.Mail.AppBaseURL)
+	}
+
+	if c.RateLimit.Enabled {
+		if c.RateLimit.Store != "memory" && c.RateLimit.Store != "postgres" {
+			add("rate_limit.store must be memory or postgres, got %q", c.RateLimit.Store)
+		}
+		for name, l := range map[string]ratelimit.Limit{
+			"per_ip": c.RateLimit.PerIP, "per_user": c.RateLimit.PerUser, "per_api_key": c.RateLimit.PerAPIKey,
+			"login": c.RateLimit.Login, "upload": c.RateLimit.Upload,
+		} {
+			if l.Requests < 1 || l.Per <= 0 || l.Burst < 1 {
+				add("rate_limit.%s needs positive requests, per and burst, got %+v", name, l)
+			}
+		}
+		lo := c.RateLimit.Lockout
+		if lo.Threshold < 1 || lo.BaseDelay <= 0 || lo.MaxDelay < lo.BaseDelay || lo.ResetAfter <= 0 {
+			add("rate_limit.lockout needs threshold >= 1, base_delay > 0, max_delay >= base_delay and reset_after > 0")
+		}
+	}
+
+	if c.Retention.SoftDeletePeriod < 0 {
+		add("retention.soft_delete_period must not be negative (set SOFT_DELETE_RETENTION), got %s", c.Retention.SoftDeletePeriod)
+	}
+	if c.Retention.SoftDeletePeriod > 0 && c.Retention.PurgeInterval <= 0 {
+		add("retention.purge_interval must be positive when soft delete purge is enabled (set PURGE_INTERVAL), got %s", c.Retention.PurgeInterval)
+	}
+
+	if c.Analysis.DefaultRadiusKm <= 0 {
+		add("analysis.default_radius_km must be positive (set ANALYSIS_DEFAULT_RADIUS_KM), got %v", c.Analysis.DefaultRadiusKm)
+	}
+	if c.Analysis.DefaultWindowHours < 1 {
+		add("analysis.default_window_hours must be at least 1 (set ANALYSIS_DEFAULT_WINDOW_HOURS), got %d", c.Analysis.DefaultWindowHours)
+	}
+
+	switch c.OCR.Engine {
+	case "none", "":
+	case "tesseract":
+		if c.OCR.TesseractPath == "" || c.OCR.Language == "" {
+			add("ocr.tesseract_path and ocr.language are required when ocr.engine is tesseract")
+		}
+		if c.OCR.Timeout <= 0 {
+			add("ocr.timeout must be positive (set OCR_TIMEOUT), got %s", c.OCR.Timeout)
+		}
+	default:
+		add("ocr.engine must be none or tesseract (set OCR_ENGINE), got %q", c.OCR.Engine)
+	}
+}
+
+func (c *Config) validateEncryption(add addFunc) {
+	if len(c.Encryption.Keys) == 0 {
+		add("encryption.keys is required (set FIELD_ENCRYPTION_KEYS)")
+	} else if c.Encryption.BlindIndexKey == "" {
+		add("encryption.blind_index_key is required (set BLIND_INDEX_KEY)")
+	} else if _, err := c.Encryption.KeyRing(); err != nil {
+		add("encryption: %v (set FIELD_ENCRYPTION_KEYS, FIELD_ENCRYPTION_ACTIVE_KEY and BLIND_INDEX_KEY)", err)
+	}
+}
@@ -14,10 +14,10 @@ type Admin struct {
     CreatedAt  time.Time `json:"created_at"`
 }
 
-func IsUserAdmin(db *sql.DB, userID string) (bool, error) {
+func IsUserAdmin(ctx context.Context, db Querier, userID string) (bool, error) {
     query := `SELECT EXISTS(SELECT 1 FROM admins WHERE user_id = $1)`
     var isAdmin bool
-    err := db.QueryRow(query, userID).Scan(&isAdmin)
+    err := db.QueryRowContext(ctx, query, userID).Scan(&isAdmin)
     if err != nil {
         if err == sql.ErrNoRows {
             return false, nil
@@ -27,26 +27,26 @@ func IsUserAdmin(db *sql.DB, userID string) (bool, error) {
     return isAdmin, nil
 }
 
-func CreateAdminTx(ctx context.Context, tx *sql.Tx, a *Admin) error {
+func CreateAdminTx(ctx context.Context, tx Querier, a *Admin) error {
     query := `INSERT INTO admins (user_id, admin_level, created_at) VALUES ($1, $2, $3)`
     _, err := tx.ExecContext(ctx, query, a.UserID, a.AdminLevel, a.CreatedAt)
     return err
 }
 
-func GetAdminByUserID(ctx context.Context, db *sql.DB, userID string) (*Admin, error) {
+func GetAdminByUserID(ctx context.Context, db Querier, userID string) (*Admin, error) {
     var a Admin
     query := `SELECT user_id, admin_level, created_at FROM admins WHERE user_id = $1`
     err := db.QueryRowContext(ctx, query, userID).Scan(&a.UserID, &a.AdminLevel, &a.CreatedAt)
     if err != nil {
         if err == sql.ErrNoRows {
-            return nil, errors.New("admin not found")
+            return nil, ErrAdminNotFound
         }
         return nil, err
     }
     return &a, nil
 }
 
-func ListAdmin(ctx context.Context, db *sql.DB) ([]Admin, error) {
+func ListAdmin(ctx context.Context, db Querier) ([]Admin, error) {
     query := `SELECT user_id, admin_level, created_at FROM admins`
     rows, err := db.QueryContext(ctx, query)
     if err != nil {
@@ -65,7 +65,7 @@ func ListAdmin(ctx context.Context, db *sql.DB) ([]Admin, error) {
     return list, nil
 }
 
-func UpdateAdmin(ctx context.Context, db *sql.DB, userID string, a *Admin) error {
+func UpdateAdmin(ctx context.Context, db Querier, userID string, a *Admin) error {
     query := `UPDATE admins SET admin_level=$1, created_at=$2 WHERE user_id=$3`
     res, err := db.ExecContext(ctx, query, a.AdminLevel, a.CreatedAt, userID)
     if err != nil {
@@ -78,7 +78,7 @@ func UpdateAdmin(ctx context.Context, db *sql.DB, userID string, a *Admin) error
     return err
 }
 
-func DeleteAdmin(ctx context.Context, db *sql.DB, userID string) error {
+func DeleteAdmin(ctx context.Context, db Querier, userID string) error {
     query := `DELETE FROM admins WHERE user_id = $1`
     res, err := db.ExecContext(ctx, query, userID)
     if err != nil {
@@ -94,7 +94,7 @@ func DeleteAdmin(ctx context.Context, db *sql.DB, userID string) error {
     return nil
 }
 
-func DeleteAdminTx(ctx context.Context, tx *sql.Tx, userID string) error {
+func DeleteAdminTx(ctx context.Context, tx Querier, userID string) error {
     query := `DELETE FROM admins WHERE user_id = $1`
     res, err := tx.ExecContext(ctx, query, userID)
     if err != nil {
@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// AdminMFA adalah pendaftaran TOTP seorang admin. EnabledAt nil berarti
+// pendaftaran belum dikonfirmasi.
+type AdminMFA struct {
+	UserID       string
+	Secret       string
+	EnabledAt    *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+}
+
+// MFAPolicy menentukan admin mana yang wajib memakai 2FA.
+type MFAPolicy struct {
+	RequireAboveLevel int       `json:"require_above_level"`
+	UpdatedBy         *string   `json:"updated_by"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+func (p MFAPolicy) Requires(adminLevel int) bool {
+	return adminLevel > p.RequireAboveLevel
+}
+
+func GetAdminMFA(ctx context.Context, db Querier, userID string) (*AdminMFA, error) {
+	var m AdminMFA
+	err := db.QueryRowContext(ctx, `SELECT user_id, secret, enabled_at, last_used_step, created_at FROM admin_mfa WHERE user_id = $1`, userID).
+		Scan(&m.UserID, &m.Secret, &m.EnabledAt, &m.LastUsedStep, &m.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMFANotFound
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SaveAdminMFAEnrollment memulai (atau mengulang) pendaftaran dengan secret
+// baru. Pendaftaran yang sudah aktif tidak ditimpa.
+func SaveAdminMFAEnrollment(ctx context.Context, db Querier, userID, secret string) error {
+	res, err := db.ExecContext(ctx, `INSERT INTO admin_mfa (user_id, secret, created_at) VALUES ($1, $2, NOW())
+        ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, created_at = NOW()
+        WHERE admin_mfa.enabled_at IS NULL`, userID, secret)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrMFAAlreadyEnabled
+	}
+	return err
+}
+
+func EnableAdminMFA(ctx context.Context, db Querier, userID string, step int64, at time.Time) error {
+	res, err := db.ExecContext(ctx, `UPDATE admin_mfa SET enabled_at = $2, last_used_step = $3 WHERE user_id = $1 AND enabled_at IS NULL`, userID, at, step)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrMFANotFound
+	}
+	return err
+}
+
+// UseAdminMFAStep mencatat langkah TOTP yang dipakai. false berarti langkah
+// itu (atau yang lebih baru) sudah pernah dipakai, yaitu upaya replay.
+func UseAdminMFAStep(ctx context.Context, db Querier, userID string, step int64) (bool, error) {
+	res, err := db.ExecContext(ctx, `UPDATE admin_mfa SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`, userID, step)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func DeleteAdminMFA(ctx context.Context, db Querier, userID string) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM admin_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	res, err := db.ExecContext(ctx, `DELETE FROM admin_mfa WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrMFANotFound
+	}
+	return err
+}
+
+func ReplaceRecoveryCodes(ctx context.Context, db Querier, userID string, hashes []string) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM admin_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, h := range hashes {
+		if _, err := db.ExecContext(ctx, `INSERT INTO admin_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func UseRecoveryCode(ctx context.Context, db Querier, userID, hash string, at time.Time) (bool, error) {
+	res, err := db.ExecContext(ctx, `UPDATE admin_recovery_codes SET used_at = $3
+        WHERE id = (SELECT id FROM admin_recovery_codes WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL LIMIT 1)`, userID, hash, at)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func CountUnusedRecoveryCodes(ctx context.Context, db Querier, userID string) (int, error) {
+	var n int
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM admin_recovery_codes WHERE user_id = $1 AND used_at IS NULL`, userID).Scan(&n)
+	return n, err
+}
+
+func GetMFAPolicy(ctx context.Context, db Querier) (*MFAPolicy, error) {
+	var p MFAPolicy
+	err := db.QueryRowContext(ctx, `SELECT require_above_level, updated_by, updated_at FROM mfa_policy WHERE id`).
+		Scan(&p.RequireAboveLevel, &p.UpdatedBy, &p.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Tanpa baris kebijakan, pakai default paling ketat.
+		return &MFAPolicy{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func UpdateMFAPolicy(ctx context.Context, db Querier, p *MFAPolicy) error {
+	return db.QueryRowContext(ctx, `INSERT INTO mfa_policy (id, require_above_level, updated_by, updated_at) VALUES (TRUE, $1, $2, NOW())
+        ON CONFLICT (id) DO UPDATE SET require_above_level = EXCLUDED.require_above_level,
+            updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+        RETURNING updated_at`, p.RequireAboveLevel, p.UpdatedBy).Scan(&p.UpdatedAt)
+}
@@ -0,0 +1,176 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	AnalysisRunQueued    = "QUEUED"
+	AnalysisRunRunning   = "RUNNING"
+	AnalysisRunCompleted = "COMPLETED"
+	AnalysisRunFailed    = "FAILED"
+)
+
+// AnalysisRun adalah satu kali pencarian suspect untuk sebuah laporan.
+// Cakupan pencarian (radius, jendela waktu, kamera dan zona) disalin dari
+// parameter laporan saat run dibuat.
+type AnalysisRun struct {
+	RunID             int64      `json:"run_id"`
+	LostID            int        `json:"lost_id"`
+	Status            string     `json:"status"`
+	RadiusKm          float64    `json:"radius_km"`
+	WindowHours       int        `json:"window_hours"`
+	CameraIDs         []int64    `json:"camera_ids,omitempty"`
+	Zones             []string   `json:"zones,omitempty"`
+	RequestedBy       *string    `json:"requested_by,omitempty"`
+	ScorerVersion     string     `json:"scorer_version,omitempty"`
+	DetectionsScanned *int       `json:"detections_scanned,omitempty"`
+	Error             string     `json:"error,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	StartedAt         *time.Time `json:"started_at,omitempty"`
+	FinishedAt        *time.Time `json:"finished_at,omitempty"`
+}
+
+func (r AnalysisRun) SearchParams() SearchParams {
+	return SearchParams{RadiusKm: r.RadiusKm, WindowHours: r.WindowHours, CameraIDs: r.CameraIDs, Zones: r.Zones}
+}
+
+// Active bernilai true selama run masih antre atau berjalan.
+func (r AnalysisRun) Active() bool {
+	return r.Status == AnalysisRunQueued || r.Status == AnalysisRunRunning
+}
+
+const analysisRunColumns = `run_id, lost_id, status, radius_km, window_hours, camera_ids, zones, requested_by, scorer_version,
+        detections_scanned, error, created_at, started_at, finished_at`
+
+func scanAnalysisRun(row interface{ Scan(...interface{}) error }) (*AnalysisRun, error) {
+	var a AnalysisRun
+	if err := row.Scan(&a.RunID, &a.LostID, &a.Status, &a.RadiusKm, &a.WindowHours, pq.Array(&a.CameraIDs), pq.Array(&a.Zones), &a.RequestedBy, &a.ScorerVersion,
+		&a.DetectionsScanned, &a.Error, &a.CreatedAt, &a.StartedAt, &a.FinishedAt); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// CreateAnalysisRun memasukkan run baru berstatus QUEUED. Laporan yang
+// sudah punya run aktif melanggar unique index idx_analysis_runs_active.
+func CreateAnalysisRun(ctx context.Context, db Querier, a *AnalysisRun) error {
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	a.Status = AnalysisRunQueued
+	err := db.QueryRowContext(ctx, `INSERT INTO analysis_runs (lost_id, status, radius_km, window_hours, camera_ids, zones, requested_by, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING run_id`, a.LostID, a.Status, a.RadiusKm, a.WindowHours,
+		pq.Array(nonNilInt64s(a.CameraIDs)), pq.Array(nonNilStrings(a.Zones)), a.RequestedBy, a.CreatedAt).Scan(&a.RunID)
+	if err != nil {
+		return fmt.Errorf("error creating analysis run: %w", err)
+	}
+	return nil
+}
+
+func GetAnalysisRun(ctx context.Context, db Querier, id int64) (*AnalysisRun, error) {
+	a, err := scanAnalysisRun(db.QueryRowContext(ctx, `SELECT `+analysisRunColumns+` FROM analysis_runs WHERE run_id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAnalysisRunNotFound
+		}
+		return nil, fmt.Errorf("error getting analysis run ID %d: %w", id, err)
+	}
+	return a, nil
+}
+
+func GetLatestAnalysisRun(ctx context.Context, db Querier, lostID int) (*AnalysisRun, error) {
+	a, err := scanAnalysisRun(db.QueryRowContext(ctx, `SELECT `+analysisRunColumns+` FROM analysis_runs
+        WHERE lost_id = $1 ORDER BY run_id DESC LIMIT 1`, lostID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAnalysisRunNotFound
+		}
+		return nil, fmt.Errorf("error getting latest analysis run of lost report ID %d: %w", lostID, err)
+	}
+	return a, nil
+}
+
+// ListAnalysisRuns mengembalikan run terbaru lebih dulu.
+func ListAnalysisRuns(ctx context.Context, db Querier, lostID int) ([]AnalysisRun, error) {
+	rows, err := db.QueryContext(ctx, `SELECT `+analysisRunColumns+` FROM analysis_runs
+        WHERE lost_id = $1 ORDER BY run_id DESC`, lostID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying analysis runs: %w", err)
+	}
+	defer rows.Close()
+
+	var list []AnalysisRun
+	for rows.Next() {
+		a, err := scanAnalysisRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning analysis run row: %w", err)
+		}
+		list = append(list, *a)
+	}
+	return list, rows.Err()
+}
+
+// ClaimAnalysisRun mengambil run QUEUED terlama dari laporan yang belum
+// dihapus dan menandainya RUNNING. SKIP LOCKED membuat beberapa worker bisa
+// mengambil run secara paralel tanpa saling menunggu. Mengembalikan
+// ErrAnalysisRunNotFound bila antrean kosong.
+func ClaimAnalysisRun(ctx context.Context, db Querier, scorerVersion string, at time.Time) (*AnalysisRun, error) {
+	a, err := scanAnalysisRun(db.QueryRowContext(ctx, `UPDATE analysis_runs SET status = $1, scorer_version = $2, started_at = $3
+        WHERE run_id = (
+            SELECT ar.run_id FROM analysis_runs ar
+            JOIN lost_report lr ON lr.lost_id = ar.lost_id AND lr.deleted_at IS NULL
+            WHERE ar.status = $4
+            ORDER BY ar.run_id
+            LIMIT 1
+            FOR UPDATE OF ar SKIP LOCKED
+        )
+        RETURNING `+analysisRunColumns, AnalysisRunRunning, scorerVersion, at, AnalysisRunQueued))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAnalysisRunNotFound
+		}
+		return nil, fmt.Errorf("error claiming analysis run: %w", err)
+	}
+	return a, nil
+}
+
+// UpdateQueuedAnalysisRunScope mengganti cakupan run yang belum diambil
+// worker. ok=false bila run sudah tidak QUEUED.
+func UpdateQueuedAnalysisRunScope(ctx context.Context, db Querier, id int64, p SearchParams) (bool, error) {
+	res, err := db.ExecContext(ctx, `UPDATE analysis_runs SET radius_km = $2, window_hours = $3, camera_ids = $4, zones = $5
+        WHERE run_id = $1 AND status = $6`, id, p.RadiusKm, p.WindowHours, pq.Array(nonNilInt64s(p.CameraIDs)), pq.Array(nonNilStrings(p.Zones)), AnalysisRunQueued)
+	if err != nil {
+		return false, fmt.Errorf("error updating scope of analysis run ID %d: %w", id, err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FinishAnalysisRun menandai run RUNNING sebagai COMPLETED atau FAILED.
+// Mengembalikan ErrAnalysisRunNotRunning bila run sudah selesai atau belum
+// diambil worker.
+func FinishAnalysisRun(ctx context.Context, db Querier, id int64, status string, detectionsScanned *int, errMsg string, at time.Time) error {
+	res, err := db.ExecContext(ctx, `UPDATE analysis_runs SET status = $2, detections_scanned = $3, error = $4, finished_at = $5
+        WHERE run_id = $1 AND status = $6`, id, status, detectionsScanned, errMsg, at, AnalysisRunRunning)
+	if err != nil {
+		return fmt.Errorf("error finishing analysis run ID %d: %w", id, err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrAnalysisRunNotRunning
+	}
+	return nil
+}
@@ -2,44 +2,50 @@ package database
 
 import (
 	"context"
-	"database/sql"
-	"errors"
 
+	"github.com/jaga-project/jaga-backend/internal/logging"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func ValidateAPIKeyAndGetUser(ctx context.Context, db *sql.DB, apiKey string) (*User, error) {
-    rows, err := db.QueryContext(ctx, "SELECT user_id, key_hash FROM service_api_keys")
+// ValidateAPIKeyAndGetUser mengembalikan pemilik API key beserta ID key-nya.
+func ValidateAPIKeyAndGetUser(ctx context.Context, db Querier, apiKey string) (*User, int64, error) {
+    rows, err := db.QueryContext(ctx, "SELECT id, user_id, key_hash FROM service_api_keys")
     if err != nil {
-        return nil, err
+        return nil, 0, err
     }
     defer rows.Close()
 
     var validUserID string
+    var validKeyID int64
 
     for rows.Next() {
+        var keyID int64
         var userID, keyHash string
-        if err := rows.Scan(&userID, &keyHash); err != nil {
-            return nil, err
+        if err := rows.Scan(&keyID, &userID, &keyHash); err != nil {
+            return nil, 0, err
         }
 
         err := bcrypt.CompareHashAndPassword([]byte(keyHash), []byte(apiKey))
         if err == nil {
             validUserID = userID
+            validKeyID = keyID
             break
         }
     }
 
     if validUserID == "" {
-        return nil, errors.New("invalid API key")
+        return nil, 0, ErrInvalidAPIKey
     }
 
     user, err := FindUserByID(db, validUserID, ctx)
     if err != nil {
-        return nil, err
+        return nil, 0, err
     }
 
-    go db.ExecContext(context.Background(), "UPDATE service_api_keys SET last_used_at = NOW() WHERE user_id = $1", validUserID)
+    // Sinkron agar tidak ada goroutine yang masih memakai koneksi saat shutdown.
+    if _, err := db.ExecContext(ctx, "UPDATE service_api_keys SET last_used_at = NOW() WHERE id = $1", validKeyID); err != nil {
+        logging.FromContext(ctx).Warn("failed to update last_used_at for API key", "user_id", validUserID, "error", err)
+    }
 
-    return user, nil
+    return user, validKeyID, nil
 }
\ No newline at end of file
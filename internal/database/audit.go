@@ -0,0 +1,275 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	AuditAuthJWT       = "jwt"
+	AuditAuthAPIKey    = "api_key"
+	AuditAuthAnonymous = "anonymous"
+)
+
+// AuditEntry adalah satu baris audit_log. Before dan After hanya berisi
+// field yang berubah (untuk update) atau seluruh objek (untuk create dan
+// delete), dengan field sensitif sudah disamarkan.
+type AuditEntry struct {
+	ID          int64           `json:"id"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	ActorUserID *string         `json:"actor_user_id"`
+	AuthMethod  string          `json:"auth_method"`
+	APIKeyID    *int64          `json:"api_key_id,omitempty"`
+	Action      string          `json:"action"`
+	EntityType  string          `json:"entity_type"`
+	EntityID    string          `json:"entity_id"`
+	Before      json.RawMessage `json:"before,omitempty"`
+	After       json.RawMessage `json:"after,omitempty"`
+	IP          string          `json:"ip"`
+	RequestID   string          `json:"request_id"`
+	PrevHash    string          `json:"prev_hash"`
+	Hash        string          `json:"hash"`
+}
+
+// ComputeHash menghitung hash entri dari seluruh isinya ditambah PrevHash.
+// OccurredAt harus sudah dibulatkan ke mikrodetik (presisi Postgres).
+func (e *AuditEntry) ComputeHash() string {
+	payload, _ := json.Marshal(struct {
+		PrevHash    string          `json:"prev_hash"`
+		OccurredAt  string          `json:"occurred_at"`
+		ActorUserID *string         `json:"actor_user_id"`
+		AuthMethod  string          `json:"auth_method"`
+		APIKeyID    *int64          `json:"api_key_id"`
+		Action      string          `json:"action"`
+		EntityType  string          `json:"entity_type"`
+		EntityID    string          `json:"entity_id"`
+		Before      json.RawMessage `json:"before"`
+		After       json.RawMessage `json:"after"`
+		IP          string          `json:"ip"`
+		RequestID   string          `json:"request_id"`
+	}{e.PrevHash, e.OccurredAt.UTC().Format(time.RFC3339Nano), e.ActorUserID, e.AuthMethod, e.APIKeyID,
+		e.Action, e.EntityType, e.EntityID, e.Before, e.After, e.IP, e.RequestID})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// Seal mengisi waktu, PrevHash dan Hash sebelum entri disimpan.
+func (e *AuditEntry) Seal(prevHash string) {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+	e.OccurredAt = e.OccurredAt.Truncate(time.Microsecond)
+	e.PrevHash = prevHash
+	e.Hash = e.ComputeHash()
+}
+
+// AuditFilter membatasi hasil ListAuditEntries. Limit 0 berarti tanpa batas.
+type AuditFilter struct {
+	ActorUserID string
+	Action      string
+	EntityType  string
+	EntityID    string
+	From        *time.Time
+	To          *time.Time
+	Limit       int
+	Offset      int
+}
+
+// Match dipakai implementasi in-memory; Postgres memakai klausa WHERE yang
+// setara.
+func (f AuditFilter) Match(e *AuditEntry) bool {
+	switch {
+	case f.ActorUserID != "" && (e.ActorUserID == nil || *e.ActorUserID != f.ActorUserID):
+		return false
+	case f.Action != "" && e.Action != f.Action:
+		return false
+	case f.EntityType != "" && e.EntityType != f.EntityType:
+		return false
+	case f.EntityID != "" && e.EntityID != f.EntityID:
+		return false
+	case f.From != nil && e.OccurredAt.Before(*f.From):
+		return false
+	case f.To != nil && !e.OccurredAt.Before(*f.To):
+		return false
+	}
+	return true
+}
+
+// AuditVerification adalah hasil pemeriksaan rantai hash. BrokenAtID berisi
+// ID entri pertama yang hash-nya tidak cocok atau tidak menyambung dengan
+// entri sebelumnya.
+type AuditVerification struct {
+	Valid      bool   `json:"valid"`
+	Checked    int    `json:"checked"`
+	BrokenAtID *int64 `json:"broken_at_id,omitempty"`
+	LastHash   string `json:"last_hash"`
+}
+
+// AuditChain memeriksa entri secara berurutan menurut ID. Entri dapat
+// diberikan bertahap agar verifikasi tidak perlu memuat seluruh tabel.
+type AuditChain struct {
+	result AuditVerification
+}
+
+func NewAuditChain() *AuditChain {
+	return &AuditChain{result: AuditVerification{Valid: true}}
+}
+
+// Add mengembalikan false setelah ditemukan entri yang rusak.
+func (c *AuditChain) Add(e *AuditEntry) bool {
+	if !c.result.Valid {
+		return false
+	}
+	c.result.Checked++
+	if e.PrevHash != c.result.LastHash || e.ComputeHash() != e.Hash {
+		c.result.Valid = false
+		id := e.ID
+		c.result.BrokenAtID = &id
+		return false
+	}
+	c.result.LastHash = e.Hash
+	return true
+}
+
+func (c *AuditChain) Result() *AuditVerification {
+	r := c.result
+	return &r
+}
+
+const auditColumns = `id, occurred_at, actor_user_id, auth_method, api_key_id, action, entity_type, entity_id,
+        before_data, after_data, ip, request_id, prev_hash, hash`
+
+func scanAuditEntry(rows *sql.Rows) (*AuditEntry, error) {
+	var e AuditEntry
+	var before, after []byte
+	if err := rows.Scan(&e.ID, &e.OccurredAt, &e.ActorUserID, &e.AuthMethod, &e.APIKeyID, &e.Action,
+		&e.EntityType, &e.EntityID, &before, &after, &e.IP, &e.RequestID, &e.PrevHash, &e.Hash); err != nil {
+		return nil, err
+	}
+	if before != nil {
+		e.Before = append(json.RawMessage(nil), before...)
+	}
+	if after != nil {
+		e.After = append(json.RawMessage(nil), after...)
+	}
+	return &e, nil
+}
+
+// AppendAuditEntry menyambung entri ke rantai hash. Harus dijalankan di dalam
+// transaksi: LOCK TABLE menolak dijalankan di luar transaksi, dan kunci ini
+// menjaga agar dua entri tidak memakai PrevHash yang sama. Panggil sebagai
+// penulisan terakhir sebelum Commit agar kunci tidak ditahan lama.
+func AppendAuditEntry(ctx context.Context, db Querier, e *AuditEntry) error {
+	if _, err := db.ExecContext(ctx, `LOCK TABLE audit_log IN EXCLUSIVE MODE`); err != nil {
+		return err
+	}
+	var prevHash string
+	err := db.QueryRowContext(ctx, `SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`).Scan(&prevHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	e.Seal(prevHash)
+
+	var before, after interface{}
+	if e.Before != nil {
+		before = string(e.Before)
+	}
+	if e.After != nil {
+		after = string(e.After)
+	}
+	return db.QueryRowContext(ctx, `INSERT INTO audit_log (occurred_at, actor_user_id, auth_method, api_key_id,
+        action, entity_type, entity_id, before_data, after_data, ip, request_id, prev_hash, hash)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id`,
+		e.OccurredAt, e.ActorUserID, e.AuthMethod, e.APIKeyID, e.Action, e.EntityType, e.EntityID,
+		before, after, e.IP, e.RequestID, e.PrevHash, e.Hash).Scan(&e.ID)
+}
+
+// ListAuditEntries mengembalikan entri terbaru lebih dulu.
+func ListAuditEntries(ctx context.Context, db Querier, f AuditFilter) ([]AuditEntry, error) {
+	var conds []string
+	var args []interface{}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.ActorUserID != "" {
+		add("actor_user_id = $%d", f.ActorUserID)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.EntityType != "" {
+		add("entity_type = $%d", f.EntityType)
+	}
+	if f.EntityID != "" {
+		add("entity_id = $%d", f.EntityID)
+	}
+	if f.From != nil {
+		add("occurred_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("occurred_at < $%d", *f.To)
+	}
+
+	query := "SELECT " + auditColumns + " FROM audit_log"
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if f.Limit > 0 {
+		args = append(args, f.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if f.Offset > 0 {
+		args = append(args, f.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *e)
+	}
+	return entries, rows.Err()
+}
+
+// VerifyAuditLog membaca seluruh audit_log berurutan dan memeriksa rantai
+// hash-nya.
+func VerifyAuditLog(ctx context.Context, db Querier) (*AuditVerification, error) {
+	rows, err := db.QueryContext(ctx, "SELECT "+auditColumns+" FROM audit_log ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chain := NewAuditChain()
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		if !chain.Add(e) {
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return chain.Result(), nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"time"
 )
 
 type Camera struct {
@@ -14,31 +16,33 @@ type Camera struct {
     Longitude float64 `json:"longitude"`  
     Address   string  `json:"address"`
     IsActive  bool    `json:"is_active"`
+    // Zone mengelompokkan kamera per wilayah untuk filter statistik.
+    Zone      string  `json:"zone"`
 }
 
-func CreateCamera(ctx context.Context, db *sql.DB, c *Camera) error {
-    query := `INSERT INTO cameras (name, ip_camera, latitude, longitude, address, is_active)
-              VALUES ($1, $2, $3, $4, $5, $6) RETURNING camera_id`
-    return db.QueryRowContext(ctx, query, c.Name, c.IPCamera, c.Latitude, c.Longitude, c.Address, c.IsActive).Scan(&c.CameraID)
+func CreateCamera(ctx context.Context, db Querier, c *Camera) error {
+    query := `INSERT INTO cameras (name, ip_camera, latitude, longitude, address, is_active, zone)
+              VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING camera_id`
+    return db.QueryRowContext(ctx, query, c.Name, c.IPCamera, c.Latitude, c.Longitude, c.Address, c.IsActive, c.Zone).Scan(&c.CameraID)
 }
 
-func GetCameraByID(ctx context.Context, db *sql.DB, id int64) (*Camera, error) {
+func GetCameraByID(ctx context.Context, db Querier, id int64) (*Camera, error) {
     var cam Camera
-    query := `SELECT camera_id, name, ip_camera, latitude, longitude, address, is_active FROM cameras WHERE camera_id = $1`
+    query := `SELECT camera_id, name, ip_camera, latitude, longitude, address, is_active, zone FROM cameras WHERE camera_id = $1 AND deleted_at IS NULL`
     err := db.QueryRowContext(ctx, query, id).Scan(
-        &cam.CameraID, &cam.Name, &cam.IPCamera, &cam.Latitude, &cam.Longitude, &cam.Address, &cam.IsActive,
+        &cam.CameraID, &cam.Name, &cam.IPCamera, &cam.Latitude, &cam.Longitude, &cam.Address, &cam.IsActive, &cam.Zone,
     )
     if err != nil {
         if err == sql.ErrNoRows {
-            return nil, errors.New("camera not found")
+            return nil, ErrCameraNotFound
         }
         return nil, err
     }
     return &cam, nil
 }
 
-func ListCameras(ctx context.Context, db *sql.DB) ([]Camera, error) {
-    query := `SELECT camera_id, name, ip_camera, latitude, longitude, address, is_active FROM cameras ORDER BY name`
+func ListCameras(ctx context.Context, db Querier) ([]Camera, error) {
+    query := `SELECT camera_id, name, ip_camera, latitude, longitude, address, is_active, zone FROM cameras WHERE deleted_at IS NULL ORDER BY name`
     rows, err := db.QueryContext(ctx, query)
     if err != nil {
         return nil, err
@@ -48,7 +52,7 @@ func ListCameras(ctx context.Context, db *sql.DB) ([]Camera, error) {
     var list []Camera
     for rows.Next() {
         var cam Camera
-        if err := rows.Scan(&cam.CameraID, &cam.Name, &cam.IPCamera, &cam.Latitude, &cam.Longitude, &cam.Address, &cam.IsActive); err != nil {
+        if err := rows.Scan(&cam.CameraID, &cam.Name, &cam.IPCamera, &cam.Latitude, &cam.Longitude, &cam.Address, &cam.IsActive, &cam.Zone); err != nil {
             return nil, err
         }
         list = append(list, cam)
@@ -56,9 +60,9 @@ func ListCameras(ctx context.Context, db *sql.DB) ([]Camera, error) {
     return list, nil
 }
 
-func UpdateCamera(ctx context.Context, db *sql.DB, id int64, c *Camera) error {
-    query := `UPDATE cameras SET name=$1, ip_camera=$2, latitude=$3, longitude=$4, address=$5, is_active=$6 WHERE camera_id=$7`
-    res, err := db.ExecContext(ctx, query, c.Name, c.IPCamera, c.Latitude, c.Longitude, c.Address, c.IsActive, id)
+func UpdateCamera(ctx context.Context, db Querier, id int64, c *Camera) error {
+    query := `UPDATE cameras SET name=$1, ip_camera=$2, latitude=$3, longitude=$4, address=$5, is_active=$6, zone=$7 WHERE camera_id=$8 AND deleted_at IS NULL`
+    res, err := db.ExecContext(ctx, query, c.Name, c.IPCamera, c.Latitude, c.Longitude, c.Address, c.IsActive, c.Zone, id)
     if err != nil {
         return err
     }
@@ -69,8 +73,10 @@ func UpdateCamera(ctx context.Context, db *sql.DB, id int64, c *Camera) error {
     return err
 }
 
-func DeleteCamera(ctx context.Context, db *sql.DB, id int64) error {
-    res, err := db.ExecContext(ctx, `DELETE FROM cameras WHERE camera_id = $1`, id)
+// DeleteCamera menandai kamera sebagai terhapus. Deteksi dari kamera ini
+// tetap tersimpan sebagai riwayat.
+func DeleteCamera(ctx context.Context, db Querier, id int64) error {
+    res, err := db.ExecContext(ctx, `UPDATE cameras SET deleted_at = NOW() WHERE camera_id = $1 AND deleted_at IS NULL`, id)
     if err != nil {
         return err
     }
@@ -80,3 +86,51 @@ func DeleteCamera(ctx context.Context, db *sql.DB, id int64) error {
     }
     return err
 }
+
+// RestoreCamera mengembalikan ErrCameraNotFound bila kamera tidak sedang
+// terhapus.
+func RestoreCamera(ctx context.Context, db Querier, id int64) error {
+    res, err := db.ExecContext(ctx, `UPDATE cameras SET deleted_at = NULL WHERE camera_id = $1 AND deleted_at IS NOT NULL`, id)
+    if err != nil {
+        return err
+    }
+    count, err := res.RowsAffected()
+    if err == nil && count == 0 {
+        return ErrCameraNotFound
+    }
+    return err
+}
+
+// PurgeDeletedCameras menghapus permanen kamera yang di-soft delete sebelum
+// before. Deteksi kamera tersebut ikut terhapus (ON DELETE CASCADE), jadi
+// gambar deteksinya dikumpulkan lebih dulu.
+func PurgeDeletedCameras(ctx context.Context, db Querier, before time.Time) (*PurgedRows, error) {
+    rows, err := db.QueryContext(ctx, `
+        SELECT d.person_image_id, d.motorcycle_image_id
+        FROM detected d
+        JOIN cameras c ON d.camera_id = c.camera_id
+        WHERE c.deleted_at < $1`, before)
+    if err != nil {
+        return nil, fmt.Errorf("error listing detections of deleted cameras: %w", err)
+    }
+    images, err := scanPurgedRows(rows)
+    if err != nil {
+        return nil, err
+    }
+
+    res, err := db.ExecContext(ctx, `DELETE FROM cameras WHERE deleted_at < $1`, before)
+    if err != nil {
+        return nil, fmt.Errorf("error purging deleted cameras: %w", err)
+    }
+    count, err := res.RowsAffected()
+    if err != nil {
+        return nil, err
+    }
+    return &PurgedRows{Count: int(count), ImageIDs: images.ImageIDs}, nil
+}
+
+func CountActiveCameras(ctx context.Context, db Querier) (int, error) {
+    var n int
+    err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM cameras WHERE is_active AND deleted_at IS NULL`).Scan(&n)
+    return n, err
+}
@@ -1,52 +1,69 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
-	"time"
+	"log/slog"
+	"strconv"
 
-	_ "github.com/joho/godotenv/autoload"
+	"github.com/jaga-project/jaga-backend/internal/config"
 	_ "github.com/lib/pq"
 )
 
 type Service interface {
-	Health() map[string]string
+	// Health melakukan ping ke database dan mengembalikan status beserta
+	// statistik connection pool. Tidak pernah menghentikan proses.
+	Health(ctx context.Context) map[string]string
 	Get() *sql.DB
+	Close() error
 }
 
 type service struct {
 	db *sql.DB
 }
 
-func New() Service {
-	connStr := os.Getenv("POSTGRES_URI")
-	db, err := sql.Open("postgres", connStr)
+func New(cfg config.DatabaseConfig) (Service, error) {
+	db, err := sql.Open("postgres", cfg.URI)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("open database: %w", err)
 	}
 
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(time.Hour)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	return &service{
 		db: db,
-	}
+	}, nil
 }
 
-func (s *service) Health() map[string]string {
-	err := s.db.Ping()
-	if err != nil {
-		log.Fatalf("%s", fmt.Sprintf("db down: %v", err))
-	}
-    log.Printf("db up: %v", err)
-	return map[string]string{
-		"message": "It's healthy",
+func (s *service) Health(ctx context.Context) map[string]string {
+	stats := make(map[string]string)
+
+	if err := s.db.PingContext(ctx); err != nil {
+		stats["status"] = "down"
+		stats["error"] = fmt.Sprintf("db down: %v", err)
+		return stats
 	}
+
+	dbStats := s.db.Stats()
+	stats["status"] = "up"
+	stats["open_connections"] = strconv.Itoa(dbStats.OpenConnections)
+	stats["in_use"] = strconv.Itoa(dbStats.InUse)
+	stats["idle"] = strconv.Itoa(dbStats.Idle)
+	stats["wait_count"] = strconv.FormatInt(dbStats.WaitCount, 10)
+	stats["wait_duration"] = dbStats.WaitDuration.String()
+	stats["max_idle_closed"] = strconv.FormatInt(dbStats.MaxIdleClosed, 10)
+	stats["max_lifetime_closed"] = strconv.FormatInt(dbStats.MaxLifetimeClosed, 10)
+	return stats
 }
 
 func (s *service) Get() *sql.DB {
 	return s.db
+}
+
+func (s *service) Close() error {
+	slog.Info("disconnected from database")
+	return s.db.Close()
 }
\ No newline at end of file
@@ -5,7 +5,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type Detected struct {
@@ -14,30 +17,48 @@ type Detected struct {
 	PersonImageID     sql.NullInt64   `json:"person_image_id,omitempty"`     
 	MotorcycleImageID sql.NullInt64   `json:"motorcycle_image_id,omitempty"` 
 	Timestamp         time.Time       `json:"timestamp"`
+	// Attributes diisi detector lewat SetDetectedAttributes; Update tidak
+	// mengubahnya.
+	Attributes        DetectedAttributes `json:"attributes"`
 }
 
-func CreateDetectedTx(ctx context.Context, tx *sql.Tx, d *Detected) error {
-	query := `INSERT INTO detected (camera_id, person_image_id, motorcycle_image_id, timestamp)
-              VALUES ($1, $2, $3, $4) RETURNING detected_id`
-	return tx.QueryRowContext(ctx, query, d.CameraID, d.PersonImageID, d.MotorcycleImageID, d.Timestamp).Scan(&d.DetectedID)
-}
+const detectedColumns = `detected_id, camera_id, person_image_id, motorcycle_image_id, timestamp,
+              attr_brand, attr_model, attr_body_type, attr_color, attr_confidence`
 
-func GetDetectedByID(ctx context.Context, db *sql.DB, id int) (*Detected, error) {
+func scanDetected(row interface{ Scan(...interface{}) error }) (*Detected, error) {
 	var d Detected
-	query := `SELECT detected_id, camera_id, person_image_id, motorcycle_image_id, timestamp 
+	a := &d.Attributes
+	if err := row.Scan(&d.DetectedID, &d.CameraID, &d.PersonImageID, &d.MotorcycleImageID, &d.Timestamp,
+		&a.Brand, &a.Model, &a.BodyType, &a.Color, &a.Confidence); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func CreateDetectedTx(ctx context.Context, tx Querier, d *Detected) error {
+	query := `INSERT INTO detected (camera_id, person_image_id, motorcycle_image_id, timestamp,
+              attr_brand, attr_model, attr_body_type, attr_color, attr_confidence)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING detected_id`
+	a := d.Attributes
+	return tx.QueryRowContext(ctx, query, d.CameraID, d.PersonImageID, d.MotorcycleImageID, d.Timestamp,
+		a.Brand, a.Model, a.BodyType, a.Color, a.Confidence).Scan(&d.DetectedID)
+}
+
+func GetDetectedByID(ctx context.Context, db Querier, id int) (*Detected, error) {
+	query := `SELECT ` + detectedColumns + `
               FROM detected WHERE detected_id = $1`
-	err := db.QueryRowContext(ctx, query, id).Scan(&d.DetectedID, &d.CameraID, &d.PersonImageID, &d.MotorcycleImageID, &d.Timestamp)
+	d, err := scanDetected(db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) { 
-			return nil, errors.New("detected not found")
+			return nil, ErrDetectedNotFound
 		}
 		return nil, err
 	}
-	return &d, nil
+	return d, nil
 }
 
-func ListDetectedByTimestampRange(ctx context.Context, db *sql.DB, startTime, endTime time.Time) ([]Detected, error) {
-	query := `SELECT detected_id, camera_id, person_image_id, motorcycle_image_id, timestamp 
+func ListDetectedByTimestampRange(ctx context.Context, db Querier, startTime, endTime time.Time) ([]Detected, error) {
+	query := `SELECT ` + detectedColumns + `
               FROM detected 
               WHERE timestamp >= $1 AND timestamp <= $2 
               ORDER BY timestamp DESC` 
@@ -50,11 +71,11 @@ func ListDetectedByTimestampRange(ctx context.Context, db *sql.DB, startTime, en
 
 	var detectedList []Detected
 	for rows.Next() {
-		var d Detected
-		if err := rows.Scan(&d.DetectedID, &d.CameraID, &d.PersonImageID, &d.MotorcycleImageID, &d.Timestamp); err != nil {
+		d, err := scanDetected(rows)
+		if err != nil {
 			return nil, fmt.Errorf("error scanning detected record: %w", err)
 		}
-		detectedList = append(detectedList, d)
+		detectedList = append(detectedList, *d)
 	}
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("error after iterating detected rows: %w", err)
@@ -62,11 +83,12 @@ func ListDetectedByTimestampRange(ctx context.Context, db *sql.DB, startTime, en
 	return detectedList, nil
 }
 
-func ListDetectedByCoordinates(ctx context.Context, db *sql.DB, lat, lon, radiusKm float64) ([]Detected, error) {
+func ListDetectedByCoordinates(ctx context.Context, db Querier, lat, lon, radiusKm float64) ([]Detected, error) {
 	// menggunakan rumus Haversine 
 	// 6371 adalah radius rata-rata Bumi dalam kilometer.
 	query := `
-        SELECT d.detected_id, d.camera_id, d.person_image_id, d.motorcycle_image_id, d.timestamp
+        SELECT d.detected_id, d.camera_id, d.person_image_id, d.motorcycle_image_id, d.timestamp,
+            d.attr_brand, d.attr_model, d.attr_body_type, d.attr_color, d.attr_confidence
         FROM detected d
         JOIN cameras c ON d.camera_id = c.camera_id
         WHERE (
@@ -87,11 +109,11 @@ func ListDetectedByCoordinates(ctx context.Context, db *sql.DB, lat, lon, radius
 
 	var detectedList []Detected
 	for rows.Next() {
-		var d Detected
-		if err := rows.Scan(&d.DetectedID, &d.CameraID, &d.PersonImageID, &d.MotorcycleImageID, &d.Timestamp); err != nil {
+		d, err := scanDetected(rows)
+		if err != nil {
 			return nil, fmt.Errorf("error scanning detected record: %w", err)
 		}
-		detectedList = append(detectedList, d)
+		detectedList = append(detectedList, *d)
 	}
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("error after iterating detected rows: %w", err)
@@ -99,11 +121,12 @@ func ListDetectedByCoordinates(ctx context.Context, db *sql.DB, lat, lon, radius
 	return detectedList, nil
 }
 
-func ListDetectedByProximityAndTimestamp(ctx context.Context, db *sql.DB, lat, lon, radiusKm float64, startTime, endTime time.Time) ([]Detected, error) {
+func ListDetectedByProximityAndTimestamp(ctx context.Context, db Querier, lat, lon, radiusKm float64, startTime, endTime time.Time) ([]Detected, error) {
 	// Query ini menggabungkan rumus Haversine untuk jarak dengan filter rentang waktu.
 	// 6371 adalah radius rata-rata Bumi dalam kilometer.
 	query := `
-        SELECT d.detected_id, d.camera_id, d.person_image_id, d.motorcycle_image_id, d.timestamp
+        SELECT d.detected_id, d.camera_id, d.person_image_id, d.motorcycle_image_id, d.timestamp,
+            d.attr_brand, d.attr_model, d.attr_body_type, d.attr_color, d.attr_confidence
         FROM detected d
         JOIN cameras c ON d.camera_id = c.camera_id
         WHERE 
@@ -127,11 +150,11 @@ func ListDetectedByProximityAndTimestamp(ctx context.Context, db *sql.DB, lat, l
 
 	var detectedList []Detected
 	for rows.Next() {
-		var d Detected
-		if err := rows.Scan(&d.DetectedID, &d.CameraID, &d.PersonImageID, &d.MotorcycleImageID, &d.Timestamp); err != nil {
+		d, err := scanDetected(rows)
+		if err != nil {
 			return nil, fmt.Errorf("error scanning detected record: %w", err)
 		}
-		detectedList = append(detectedList, d)
+		detectedList = append(detectedList, *d)
 	}
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("error after iterating detected rows: %w", err)
@@ -139,8 +162,8 @@ func ListDetectedByProximityAndTimestamp(ctx context.Context, db *sql.DB, lat, l
 	return detectedList, nil
 }
 
-func ListDetected(ctx context.Context, db *sql.DB) ([]Detected, error) {
-	query := `SELECT detected_id, camera_id, person_image_id, motorcycle_image_id, timestamp 
+func ListDetected(ctx context.Context, db Querier) ([]Detected, error) {
+	query := `SELECT ` + detectedColumns + `
               FROM detected ORDER BY timestamp DESC`
 	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
@@ -150,11 +173,11 @@ func ListDetected(ctx context.Context, db *sql.DB) ([]Detected, error) {
 
 	var detectedList []Detected
 	for rows.Next() {
-		var d Detected
-		if err := rows.Scan(&d.DetectedID, &d.CameraID, &d.PersonImageID, &d.MotorcycleImageID, &d.Timestamp); err != nil {
+		d, err := scanDetected(rows)
+		if err != nil {
 			return nil, err
 		}
-		detectedList = append(detectedList, d)
+		detectedList = append(detectedList, *d)
 	}
 	if err = rows.Err(); err != nil {
 		return nil, err
@@ -162,7 +185,7 @@ func ListDetected(ctx context.Context, db *sql.DB) ([]Detected, error) {
 	return detectedList, nil
 }
 
-func UpdateDetected(ctx context.Context, db *sql.DB, id int, d *Detected) error {
+func UpdateDetected(ctx context.Context, db Querier, id int, d *Detected) error {
 	query := `UPDATE detected SET camera_id=$1, person_image_id=$2, motorcycle_image_id=$3, timestamp=$4 
               WHERE detected_id=$5`
 	res, err := db.ExecContext(ctx, query, d.CameraID, d.PersonImageID, d.MotorcycleImageID, d.Timestamp, id)
@@ -179,7 +202,7 @@ func UpdateDetected(ctx context.Context, db *sql.DB, id int, d *Detected) error
 	return nil
 }
 
-func DeleteDetectedTx(ctx context.Context, tx *sql.Tx, id int) error {
+func DeleteDetectedTx(ctx context.Context, tx Querier, id int) error {
     query := `DELETE FROM detected WHERE detected_id = $1`
     res, err := tx.ExecContext(ctx, query, id)
     if err != nil {
@@ -196,3 +219,89 @@ func DeleteDetectedTx(ctx context.Context, tx *sql.Tx, id int) error {
     }
     return nil
 }
+
+// SetDetectedAttributes mengganti seluruh atribut prediksi sebuah deteksi.
+func SetDetectedAttributes(ctx context.Context, db Querier, id int, a DetectedAttributes) error {
+	res, err := db.ExecContext(ctx, `UPDATE detected SET attr_brand = $2, attr_model = $3, attr_body_type = $4, attr_color = $5, attr_confidence = $6
+        WHERE detected_id = $1`, id, a.Brand, a.Model, a.BodyType, a.Color, a.Confidence)
+	if err != nil {
+		return fmt.Errorf("error setting attributes of detected ID %d: %w", id, err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrDetectedNotFound
+	}
+	return nil
+}
+
+func detectionSearchWhere(f DetectionSearchFilter) (string, []interface{}) {
+	conds := []string{"timestamp >= $1", "timestamp < $2"}
+	args := []interface{}{f.From, f.To}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, strings.ReplaceAll(cond, "?", fmt.Sprintf("$%d", len(args))))
+	}
+	if f.Brand != "" {
+		add("lower(attr_brand) = lower(?)", f.Brand)
+	}
+	if f.Model != "" {
+		add(`attr_model ILIKE '%' || ? || '%' ESCAPE '\'`, escapeLike(f.Model))
+	}
+	if f.BodyType != "" {
+		add("attr_body_type = ?", f.BodyType)
+	}
+	if f.Color != "" {
+		add("attr_color = ?", f.Color)
+	}
+	if len(f.CameraIDs) > 0 {
+		add("camera_id = ANY(?)", pq.Array(f.CameraIDs))
+	}
+	if f.MinConfidence > 0 {
+		add("attr_confidence >= ?", f.MinConfidence)
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// likeEscaper membuat %, _ dan \ dicari apa adanya dalam pola LIKE yang
+// memakai ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string { return likeEscaper.Replace(s) }
+
+// SearchDetected mengembalikan deteksi yang cocok, terbaru lebih dulu.
+func SearchDetected(ctx context.Context, db Querier, f DetectionSearchFilter) ([]Detected, error) {
+	where, args := detectionSearchWhere(f)
+	var limit interface{}
+	if f.Limit > 0 {
+		limit = f.Limit
+	}
+	args = append(args, limit, f.Offset)
+	query := `SELECT ` + detectedColumns + ` FROM detected` + where +
+		fmt.Sprintf(" ORDER BY timestamp DESC, detected_id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error searching detected: %w", err)
+	}
+	defer rows.Close()
+	var list []Detected
+	for rows.Next() {
+		d, err := scanDetected(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning detected record: %w", err)
+		}
+		list = append(list, *d)
+	}
+	return list, rows.Err()
+}
+
+func CountSearchDetected(ctx context.Context, db Querier, f DetectionSearchFilter) (int, error) {
+	where, args := detectionSearchWhere(f)
+	var n int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM detected`+where, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("error counting detected: %w", err)
+	}
+	return n, nil
+}
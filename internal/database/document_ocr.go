@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Status OCR satu foto dokumen.
+const (
+	OCRPending = "PENDING"
+	OCRDone    = "DONE"
+	OCRFailed  = "FAILED"
+)
+
+// DocumentOCR adalah hasil OCR foto KTP (NIK dan nama) atau STNK (plat).
+// Confidence adalah rata-rata confidence engine di rentang 0..1.
+type DocumentOCR struct {
+	ImageID      int64     `json:"image_id"`
+	DocumentType string    `json:"document_type"`
+	Status       string    `json:"status"`
+	Engine       string    `json:"engine,omitempty"`
+	NIK          string    `json:"nik,omitempty"`
+	Name         string    `json:"name,omitempty"`
+	PlateNumber  string    `json:"plate_number,omitempty"`
+	Confidence   float64   `json:"confidence"`
+	Error        string    `json:"error,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SaveDocumentOCR menyimpan atau menimpa hasil OCR sebuah foto. NIK
+// disimpan apa adanya; lihat WithFieldEncryption untuk enkripsinya.
+func SaveDocumentOCR(ctx context.Context, db Querier, o *DocumentOCR) error {
+	err := db.QueryRowContext(ctx, `INSERT INTO document_ocr_results
+        (image_id, document_type, status, engine, nik, name, plate_number, confidence, error, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, now())
+        ON CONFLICT (image_id) DO UPDATE SET document_type = EXCLUDED.document_type, status = EXCLUDED.status,
+            engine = EXCLUDED.engine, nik = EXCLUDED.nik, name = EXCLUDED.name, plate_number = EXCLUDED.plate_number,
+            confidence = EXCLUDED.confidence, error = EXCLUDED.error, updated_at = EXCLUDED.updated_at
+        RETURNING updated_at`,
+		o.ImageID, o.DocumentType, o.Status, o.Engine, o.NIK, o.Name, o.PlateNumber, o.Confidence, o.Error,
+	).Scan(&o.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("error saving OCR result of image ID %d: %w", o.ImageID, err)
+	}
+	return nil
+}
+
+// GetDocumentOCR mengembalikan ErrDocumentOCRNotFound bila foto belum
+// pernah diproses.
+func GetDocumentOCR(ctx context.Context, db Querier, imageID int64) (*DocumentOCR, error) {
+	var o DocumentOCR
+	err := db.QueryRowContext(ctx, `SELECT image_id, document_type, status, engine, nik, name, plate_number,
+        confidence, error, updated_at FROM document_ocr_results WHERE image_id = $1`, imageID).Scan(
+		&o.ImageID, &o.DocumentType, &o.Status, &o.Engine, &o.NIK, &o.Name, &o.PlateNumber, &o.Confidence, &o.Error, &o.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDocumentOCRNotFound
+		}
+		return nil, fmt.Errorf("error getting OCR result of image ID %d: %w", imageID, err)
+	}
+	return &o, nil
+}
@@ -0,0 +1,186 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Status verifikasi dokumen (KTP, STNK, KK). Dokumen yang baru diunggah
+// PENDING sampai diputuskan admin; tanpa dokumen statusnya UNVERIFIED.
+const (
+	VerificationUnverified = "UNVERIFIED"
+	VerificationPending    = "PENDING"
+	VerificationVerified   = "VERIFIED"
+	VerificationRejected   = "REJECTED"
+)
+
+const (
+	DocumentKTP  = "KTP"
+	DocumentSTNK = "STNK"
+	DocumentKK   = "KK"
+)
+
+// DocumentTypes adalah jenis dokumen yang diperiksa admin.
+var DocumentTypes = []string{DocumentKTP, DocumentSTNK, DocumentKK}
+
+// KTPVerification adalah hasil pemeriksaan foto KTP user oleh admin.
+type KTPVerification struct {
+	KTPVerificationStatus string     `json:"ktp_verification_status"`
+	KTPVerificationNote   *string    `json:"ktp_verification_note,omitempty"`
+	KTPVerifiedBy         *string    `json:"ktp_verified_by,omitempty"`
+	KTPVerifiedAt         *time.Time `json:"ktp_verified_at,omitempty"`
+}
+
+// KKVerification adalah hasil pemeriksaan foto KK kendaraan oleh admin.
+type KKVerification struct {
+	KKVerificationStatus string     `json:"kk_verification_status"`
+	KKVerificationNote   *string    `json:"kk_verification_note,omitempty"`
+	KKVerifiedBy         *string    `json:"kk_verified_by,omitempty"`
+	KKVerifiedAt         *time.Time `json:"kk_verified_at,omitempty"`
+}
+
+// DocumentVerification adalah satu dokumen di antrean verifikasi beserta
+// data yang diketik user (NIK dan plat) untuk dicocokkan dengan fotonya.
+// VehicleID dan PlateNumber kosong untuk KTP. OCR berisi hasil OCR foto
+// KTP atau STNK bila sudah dijalankan.
+type DocumentVerification struct {
+	DocumentType string       `json:"document_type"`
+	UserID       string       `json:"user_id"`
+	UserName     string       `json:"user_name"`
+	NIK          string       `json:"nik"`
+	VehicleID    *int64       `json:"vehicle_id,omitempty"`
+	PlateNumber  string       `json:"plate_number,omitempty"`
+	ImageID      int64        `json:"image_id"`
+	ImagePath    string       `json:"-"`
+	Status       string       `json:"status"`
+	Note         *string      `json:"note,omitempty"`
+	VerifiedBy   *string      `json:"verified_by,omitempty"`
+	VerifiedAt   *time.Time   `json:"verified_at,omitempty"`
+	SubmittedAt  time.Time    `json:"submitted_at"`
+	OCR          *DocumentOCR `json:"ocr,omitempty"`
+}
+
+// VerificationFilter memilih dokumen di antrean. DocumentType kosong
+// berarti semua jenis dokumen.
+type VerificationFilter struct {
+	DocumentType  string
+	Status        string
+	Limit, Offset int
+}
+
+// documentVerificationQuery menggabungkan KTP, STNK dan KK dokumen yang
+// masih ada fotonya. Waktu pengajuan diambil dari waktu unggah foto.
+const documentVerificationQuery = `
+    SELECT 'KTP' AS document_type, u.user_id, u.name AS user_name, u.nik, NULL::bigint AS vehicle_id, '' AS plate_number,
+           i.image_id, i.storage_path, u.ktp_verification_status AS status, u.ktp_verification_note AS note,
+           u.ktp_verified_by AS verified_by, u.ktp_verified_at AS verified_at, i.uploaded_at AS submitted_at
+    FROM users u JOIN images i ON i.image_id = u.ktp_image_id
+    WHERE u.deleted_at IS NULL
+    UNION ALL
+    SELECT 'STNK', u.user_id, u.name, u.nik, v.vehicle_id, v.plate_number,
+           i.image_id, i.storage_path, v.verification_status, v.verification_note,
+           v.verified_by, v.verified_at, i.uploaded_at
+    FROM vehicle v JOIN users u ON u.user_id = v.user_id JOIN images i ON i.image_id = v.stnk_image_id
+    WHERE v.deleted_at IS NULL
+    UNION ALL
+    SELECT 'KK', u.user_id, u.name, u.nik, v.vehicle_id, v.plate_number,
+           i.image_id, i.storage_path, v.kk_verification_status, v.kk_verification_note,
+           v.kk_verified_by, v.kk_verified_at, i.uploaded_at
+    FROM vehicle v JOIN users u ON u.user_id = v.user_id JOIN images i ON i.image_id = v.kk_image_id
+    WHERE v.deleted_at IS NULL`
+
+// ListDocumentVerifications mengembalikan antrean verifikasi, pengajuan
+// terlama lebih dulu. NIK dikembalikan apa adanya dari database; lihat
+// WithFieldEncryption untuk dekripsinya.
+func ListDocumentVerifications(ctx context.Context, db Querier, f VerificationFilter) ([]DocumentVerification, error) {
+	rows, err := db.QueryContext(ctx, `SELECT d.document_type, d.user_id, d.user_name, d.nik, d.vehicle_id, d.plate_number,
+        d.image_id, d.storage_path, d.status, d.note, d.verified_by, d.verified_at, d.submitted_at,
+        o.status, COALESCE(o.engine, ''), COALESCE(o.nik, ''), COALESCE(o.name, ''), COALESCE(o.plate_number, ''),
+        COALESCE(o.confidence, 0), COALESCE(o.error, ''), COALESCE(o.updated_at, d.submitted_at)
+        FROM (`+documentVerificationQuery+`) d
+        LEFT JOIN document_ocr_results o ON o.image_id = d.image_id AND o.document_type = d.document_type
+        WHERE ($1 = '' OR d.document_type = $1) AND d.status = $2
+        ORDER BY d.submitted_at, d.image_id LIMIT $3 OFFSET $4`, f.DocumentType, f.Status, f.Limit, f.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("error querying document verifications: %w", err)
+	}
+	defer rows.Close()
+
+	var list []DocumentVerification
+	for rows.Next() {
+		var d DocumentVerification
+		var o DocumentOCR
+		var ocrStatus sql.NullString
+		if err := rows.Scan(&d.DocumentType, &d.UserID, &d.UserName, &d.NIK, &d.VehicleID, &d.PlateNumber, &d.ImageID,
+			&d.ImagePath, &d.Status, &d.Note, &d.VerifiedBy, &d.VerifiedAt, &d.SubmittedAt,
+			&ocrStatus, &o.Engine, &o.NIK, &o.Name, &o.PlateNumber, &o.Confidence, &o.Error, &o.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning document verification row: %w", err)
+		}
+		if ocrStatus.Valid {
+			o.ImageID, o.DocumentType, o.Status = d.ImageID, d.DocumentType, ocrStatus.String
+			d.OCR = &o
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// CountDocumentVerifications menghitung dokumen yang cocok dengan f tanpa
+// memperhatikan Limit dan Offset.
+func CountDocumentVerifications(ctx context.Context, db Querier, f VerificationFilter) (int, error) {
+	var n int
+	err := db.QueryRowContext(ctx, `SELECT count(*) FROM (`+documentVerificationQuery+`) d
+        WHERE ($1 = '' OR document_type = $1) AND status = $2`, f.DocumentType, f.Status).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("error counting document verifications: %w", err)
+	}
+	return n, nil
+}
+
+// SetUserKTPVerification mengubah status verifikasi KTP user. Seperti
+// SetVehicleVerification, verifiedBy kosong mengosongkan ktp_verified_by
+// dan ktp_verified_at.
+func SetUserKTPVerification(ctx context.Context, db Querier, userID, status, verifiedBy, note string, at time.Time) error {
+	var verifiedAt *time.Time
+	if verifiedBy != "" {
+		verifiedAt = &at
+	}
+	res, err := db.ExecContext(ctx, `UPDATE users SET ktp_verification_status = $2, ktp_verified_by = NULLIF($3, ''),
+        ktp_verification_note = NULLIF($4, ''), ktp_verified_at = $5 WHERE user_id = $1 AND deleted_at IS NULL`,
+		userID, status, verifiedBy, note, verifiedAt)
+	if err != nil {
+		return fmt.Errorf("error setting KTP verification of user ID %s: %w", userID, err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// SetVehicleKKVerification mengubah status verifikasi KK kendaraan.
+func SetVehicleKKVerification(ctx context.Context, db Querier, id int64, status, verifiedBy, note string, at time.Time) error {
+	var verifiedAt *time.Time
+	if verifiedBy != "" {
+		verifiedAt = &at
+	}
+	res, err := db.ExecContext(ctx, `UPDATE vehicle SET kk_verification_status = $2, kk_verified_by = NULLIF($3, ''),
+        kk_verification_note = NULLIF($4, ''), kk_verified_at = $5 WHERE vehicle_id = $1 AND deleted_at IS NULL`,
+		id, status, verifiedBy, note, verifiedAt)
+	if err != nil {
+		return fmt.Errorf("error setting KK verification of vehicle ID %d: %w", id, err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrVehicleNotFound
+	}
+	return nil
+}
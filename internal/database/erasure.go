@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	ErasureStatusPending  = "PENDING"
+	ErasureStatusApproved = "APPROVED"
+	ErasureStatusRejected = "REJECTED"
+)
+
+// AnonymizedUserName menggantikan nama user yang datanya sudah dihapus.
+const AnonymizedUserName = "Pengguna terhapus"
+
+// ErasureRequest adalah permintaan user agar data pribadinya dihapus.
+type ErasureRequest struct {
+	RequestID    int64      `json:"request_id"`
+	UserID       string     `json:"user_id"`
+	Reason       string     `json:"reason"`
+	Status       string     `json:"status"`
+	RequestedAt  time.Time  `json:"requested_at"`
+	DecidedBy    *string    `json:"decided_by,omitempty"`
+	DecidedAt    *time.Time `json:"decided_at,omitempty"`
+	DecisionNote string     `json:"decision_note,omitempty"`
+}
+
+const erasureRequestColumns = `request_id, user_id, reason, status, requested_at, decided_by, decided_at, decision_note`
+
+func scanErasureRequest(row interface{ Scan(...interface{}) error }) (*ErasureRequest, error) {
+	var e ErasureRequest
+	if err := row.Scan(&e.RequestID, &e.UserID, &e.Reason, &e.Status, &e.RequestedAt, &e.DecidedBy, &e.DecidedAt, &e.DecisionNote); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func CreateErasureRequest(ctx context.Context, db Querier, e *ErasureRequest) error {
+	if e.RequestedAt.IsZero() {
+		e.RequestedAt = time.Now()
+	}
+	e.Status = ErasureStatusPending
+	err := db.QueryRowContext(ctx, `INSERT INTO erasure_requests (user_id, reason, status, requested_at)
+        VALUES ($1, $2, $3, $4) RETURNING request_id`, e.UserID, e.Reason, e.Status, e.RequestedAt).Scan(&e.RequestID)
+	if err != nil {
+		return fmt.Errorf("error creating erasure request: %w", err)
+	}
+	return nil
+}
+
+func GetErasureRequest(ctx context.Context, db Querier, id int64) (*ErasureRequest, error) {
+	e, err := scanErasureRequest(db.QueryRowContext(ctx, `SELECT `+erasureRequestColumns+` FROM erasure_requests WHERE request_id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrErasureRequestNotFound
+		}
+		return nil, fmt.Errorf("error getting erasure request ID %d: %w", id, err)
+	}
+	return e, nil
+}
+
+func GetLatestErasureRequest(ctx context.Context, db Querier, userID string) (*ErasureRequest, error) {
+	e, err := scanErasureRequest(db.QueryRowContext(ctx, `SELECT `+erasureRequestColumns+` FROM erasure_requests
+        WHERE user_id = $1 ORDER BY requested_at DESC, request_id DESC LIMIT 1`, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrErasureRequestNotFound
+		}
+		return nil, fmt.Errorf("error getting erasure request of user ID %s: %w", userID, err)
+	}
+	return e, nil
+}
+
+// ListErasureRequests mengembalikan permintaan terlama lebih dulu. status
+// kosong berarti semua status.
+func ListErasureRequests(ctx context.Context, db Querier, status string) ([]ErasureRequest, error) {
+	rows, err := db.QueryContext(ctx, `SELECT `+erasureRequestColumns+` FROM erasure_requests
+        WHERE ($1 = '' OR status = $1) ORDER BY requested_at, request_id`, status)
+	if err != nil {
+		return nil, fmt.Errorf("error querying erasure requests: %w", err)
+	}
+	defer rows.Close()
+
+	var list []ErasureRequest
+	for rows.Next() {
+		e, err := scanErasureRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning erasure request row: %w", err)
+		}
+		list = append(list, *e)
+	}
+	return list, rows.Err()
+}
+
+// DecideErasureRequest menyetujui atau menolak permintaan yang masih
+// PENDING. Mengembalikan ErrErasureRequestDecided bila sudah diputuskan.
+func DecideErasureRequest(ctx context.Context, db Querier, id int64, status, decidedBy, note string, at time.Time) error {
+	res, err := db.ExecContext(ctx, `UPDATE erasure_requests SET status = $2, decided_by = $3, decision_note = $4, decided_at = $5
+        WHERE request_id = $1 AND status = $6`, id, status, decidedBy, note, at, ErasureStatusPending)
+	if err != nil {
+		return fmt.Errorf("error deciding erasure request ID %d: %w", id, err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrErasureRequestDecided
+	}
+	return nil
+}
+
+// AnonymizeUserTx menghapus data identitas user dan mengembalikan ID gambar
+// pribadinya (KTP, STNK, KK, bukti laporan) yang sudah dilepas dari
+// barisnya. Baris user, kendaraan dan laporan tetap ada agar statistik
+// (jumlah laporan per status, kendaraan, waktu) tidak berubah; koordinat
+// laporan dibulatkan ke dua desimal (sekitar 1 km). Kredensial, token, API
+// key dan hak admin ikut dihapus, dan permintaan pindah kepemilikan yang
+// masih PENDING dibatalkan. Kendaraan dan laporan yang sedang di-soft delete
+// ikut dianonimkan.
+func AnonymizeUserTx(ctx context.Context, tx Querier, userID string) ([]int64, error) {
+	var ktpImage sql.NullInt64
+	err := tx.QueryRowContext(ctx, `SELECT ktp_image_id FROM users WHERE user_id = $1 AND deleted_at IS NULL FOR UPDATE`, userID).Scan(&ktpImage)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("error locking user ID %s for anonymization: %w", userID, err)
+	}
+	images := &PurgedRows{}
+	images.addImage(ktpImage)
+	for _, q := range []string{
+		`SELECT stnk_image_id, kk_image_id FROM vehicle WHERE user_id = $1`,
+		`SELECT motor_evidence_image_id, person_evidence_image_id FROM lost_report WHERE user_id = $1`,
+	} {
+		rows, err := tx.QueryContext(ctx, q, userID)
+		if err != nil {
+			return nil, fmt.Errorf("error collecting images of user ID %s: %w", userID, err)
+		}
+		found, err := scanPurgedRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		images.ImageIDs = append(images.ImageIDs, found.ImageIDs...)
+	}
+
+	statements := []string{
+		`UPDATE users SET name = '` + AnonymizedUserName + `', email = 'erased-' || user_id || '@jaga.invalid',
+            phone = '', password = '', nik = '', nik_hash = '', ktp_image_id = NULL, email_verified_at = NULL WHERE user_id = $1`,
+		`UPDATE vehicle SET plate_number = '', plate_normalized = NULL, stnk_image_id = NULL, kk_image_id = NULL, distinguishing_marks = NULL WHERE user_id = $1`,
+		`UPDATE vehicle_transfers SET status = 'CANCELLED', decided_at = now() WHERE status = 'PENDING' AND (from_user_id = $1 OR to_user_id = $1)`,
+		`UPDATE lost_report SET address = '', latitude = ROUND(latitude::numeric, 2)::double precision,
+            longitude = ROUND(longitude::numeric, 2)::double precision,
+            motor_evidence_image_id = NULL, person_evidence_image_id = NULL WHERE user_id = $1`,
+		`DELETE FROM user_tokens WHERE user_id = $1`,
+		`DELETE FROM service_api_keys WHERE user_id = $1`,
+		`DELETE FROM admin_recovery_codes WHERE user_id = $1`,
+		`DELETE FROM admin_mfa WHERE user_id = $1`,
+		`DELETE FROM admins WHERE user_id = $1`,
+	}
+	for _, q := range statements {
+		if _, err := tx.ExecContext(ctx, q, userID); err != nil {
+			return nil, fmt.Errorf("error anonymizing user ID %s: %w", userID, err)
+		}
+	}
+	return images.ImageIDs, nil
+}
@@ -0,0 +1,39 @@
+package database
+
+import "errors"
+
+// Error sentinel yang dipakai bersama oleh implementasi Postgres dan
+// implementasi in-memory, sehingga handler dapat membedakan kasus
+// "tidak ditemukan" dengan errors.Is tanpa mencocokkan string.
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrAdminNotFound      = errors.New("admin not found")
+	ErrVehicleNotFound    = errors.New("vehicle not found")
+	ErrLostReportNotFound = errors.New("lost_report not found")
+	ErrDetectedNotFound   = errors.New("detected not found")
+	ErrSuspectNotFound    = errors.New("suspect not found")
+	ErrImageNotFound      = errors.New("image not found")
+	ErrCameraNotFound     = errors.New("camera not found")
+	ErrInvalidAPIKey      = errors.New("invalid API key")
+	// ErrTokenInvalid dipakai untuk token yang tidak ada, sudah dipakai, atau
+	// kedaluwarsa; ketiganya sengaja tidak dibedakan.
+	ErrTokenInvalid = errors.New("token invalid or expired")
+
+	ErrMFANotFound       = errors.New("two-factor authentication is not set up")
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+
+	ErrErasureRequestNotFound = errors.New("erasure request not found")
+	ErrErasureRequestDecided  = errors.New("erasure request has already been decided")
+
+	ErrAnalysisRunNotFound   = errors.New("analysis run not found")
+	ErrAnalysisRunNotRunning = errors.New("analysis run is not running")
+	ErrSearchParamsNotFound  = errors.New("search parameters not found")
+
+	// ErrPlateTaken dipakai bila plat nomor sudah terdaftar pada kendaraan
+	// aktif lain.
+	ErrPlateTaken              = errors.New("plate number is already registered")
+	ErrVehicleTransferNotFound = errors.New("vehicle transfer not found")
+	ErrVehicleTransferPending  = errors.New("vehicle already has a pending transfer")
+	ErrVehicleTransferDecided  = errors.New("vehicle transfer has already been decided")
+	ErrDocumentOCRNotFound     = errors.New("document OCR result not found")
+)
@@ -0,0 +1,415 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/fieldcrypt"
+)
+
+// WithFieldEncryption membungkus store agar NIK dan nomor telepon user
+// dienkripsi sebelum ditulis dan didekripsi setelah dibaca, sehingga
+// handler tetap bekerja dengan plaintext. NIKHash diisi otomatis dari NIK.
+func WithFieldEncryption(store Store, ring *fieldcrypt.KeyRing) Store {
+	return encryptedStore{store: store, ring: ring}
+}
+
+type encryptedStore struct {
+	store Store
+	ring  *fieldcrypt.KeyRing
+}
+
+func (s encryptedStore) Repos() Repositories {
+	return encryptRepos(s.store.Repos(), s.ring)
+}
+
+func (s encryptedStore) BeginTx(ctx context.Context) (Tx, error) {
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return encryptedTx{Tx: tx, ring: s.ring}, nil
+}
+
+type encryptedTx struct {
+	Tx
+	ring *fieldcrypt.KeyRing
+}
+
+func (t encryptedTx) Repos() Repositories {
+	return encryptRepos(t.Tx.Repos(), t.ring)
+}
+
+func encryptRepos(r Repositories, ring *fieldcrypt.KeyRing) Repositories {
+	r.Users = encryptedUserRepo{next: r.Users, ring: ring}
+	r.Verifications = encryptedVerificationRepo{next: r.Verifications, ring: ring}
+	r.DocumentOCR = encryptedDocumentOCRRepo{next: r.DocumentOCR, ring: ring}
+	return r
+}
+
+type encryptedUserRepo struct {
+	next UserRepo
+	ring *fieldcrypt.KeyRing
+}
+
+func (r encryptedUserRepo) Create(ctx context.Context, u *User) error {
+	stored := *u
+	if err := r.seal(&stored); err != nil {
+		return err
+	}
+	if err := r.next.Create(ctx, &stored); err != nil {
+		return err
+	}
+	u.NIKHash = stored.NIKHash
+	return nil
+}
+
+func (r encryptedUserRepo) FindByEmail(ctx context.Context, email string) (*User, error) {
+	return r.open(r.next.FindByEmail(ctx, email))
+}
+
+func (r encryptedUserRepo) FindByID(ctx context.Context, userID string) (*User, error) {
+	return r.open(r.next.FindByID(ctx, userID))
+}
+
+func (r encryptedUserRepo) FindByNIKHash(ctx context.Context, hash string) (*User, error) {
+	return r.open(r.next.FindByNIKHash(ctx, hash))
+}
+
+func (r encryptedUserRepo) List(ctx context.Context) ([]User, error) {
+	users, err := r.next.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		if err := r.decrypt(&users[i]); err != nil {
+			return nil, err
+		}
+	}
+	return users, nil
+}
+
+func (r encryptedUserRepo) Update(ctx context.Context, userID string, updates map[string]interface{}) error {
+	if _, ok := updates["nik_hash"]; ok {
+		return errors.New("nik_hash is derived from nik and cannot be set directly")
+	}
+	sealed := make(map[string]interface{}, len(updates)+1)
+	for col, val := range updates {
+		sealed[col] = val
+	}
+	for _, col := range []string{"nik", "phone"} {
+		val, ok := updates[col]
+		if !ok {
+			continue
+		}
+		plain, ok := val.(string)
+		if !ok && val != nil {
+			return fmt.Errorf("%s must be a string, got %T", col, val)
+		}
+		enc, err := r.ring.Encrypt(plain)
+		if err != nil {
+			return fmt.Errorf("error encrypting %s: %w", col, err)
+		}
+		sealed[col] = enc
+		if col == "nik" {
+			sealed["nik_hash"] = r.ring.BlindIndex(plain)
+		}
+	}
+	return r.next.Update(ctx, userID, sealed)
+}
+
+func (r encryptedUserRepo) Delete(ctx context.Context, userID string) error {
+	return r.next.Delete(ctx, userID)
+}
+
+func (r encryptedUserRepo) Restore(ctx context.Context, userID string) error {
+	return r.next.Restore(ctx, userID)
+}
+
+func (r encryptedUserRepo) PurgeDeleted(ctx context.Context, before time.Time) (*PurgedRows, error) {
+	return r.next.PurgeDeleted(ctx, before)
+}
+
+func (r encryptedUserRepo) Anonymize(ctx context.Context, userID string) ([]int64, error) {
+	return r.next.Anonymize(ctx, userID)
+}
+
+func (r encryptedUserRepo) FindByAPIKey(ctx context.Context, apiKey string) (*User, int64, error) {
+	u, id, err := r.next.FindByAPIKey(ctx, apiKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := r.decrypt(u); err != nil {
+		return nil, 0, err
+	}
+	return u, id, nil
+}
+
+func (r encryptedUserRepo) SetKTPVerification(ctx context.Context, userID, status, verifiedBy, note string, at time.Time) error {
+	return r.next.SetKTPVerification(ctx, userID, status, verifiedBy, note, at)
+}
+
+func (r encryptedUserRepo) seal(u *User) error {
+	u.NIKHash = r.ring.BlindIndex(u.NIK)
+	var err error
+	if u.NIK, err = r.ring.Encrypt(u.NIK); err != nil {
+		return fmt.Errorf("error encrypting nik: %w", err)
+	}
+	if u.Phone, err = r.ring.Encrypt(u.Phone); err != nil {
+		return fmt.Errorf("error encrypting phone: %w", err)
+	}
+	return nil
+}
+
+func (r encryptedUserRepo) decrypt(u *User) error {
+	var err error
+	if u.NIK, err = r.ring.Decrypt(u.NIK); err != nil {
+		return fmt.Errorf("error decrypting nik of user ID %s: %w", u.UserID, err)
+	}
+	if u.Phone, err = r.ring.Decrypt(u.Phone); err != nil {
+		return fmt.Errorf("error decrypting phone of user ID %s: %w", u.UserID, err)
+	}
+	return nil
+}
+
+func (r encryptedUserRepo) open(u *User, err error) (*User, error) {
+	if err != nil {
+		return nil, err
+	}
+	if err := r.decrypt(u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// encryptedVerificationRepo mendekripsi NIK di antrean verifikasi agar
+// admin bisa mencocokkannya dengan foto KTP.
+type encryptedVerificationRepo struct {
+	next VerificationRepo
+	ring *fieldcrypt.KeyRing
+}
+
+func (r encryptedVerificationRepo) List(ctx context.Context, f VerificationFilter) ([]DocumentVerification, error) {
+	list, err := r.next.List(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i].NIK, err = r.ring.Decrypt(list[i].NIK); err != nil {
+			return nil, fmt.Errorf("error decrypting nik of user ID %s: %w", list[i].UserID, err)
+		}
+		if o := list[i].OCR; o != nil {
+			if o.NIK, err = r.ring.Decrypt(o.NIK); err != nil {
+				return nil, fmt.Errorf("error decrypting OCR nik of image ID %d: %w", o.ImageID, err)
+			}
+		}
+	}
+	return list, nil
+}
+
+func (r encryptedVerificationRepo) Count(ctx context.Context, f VerificationFilter) (int, error) {
+	return r.next.Count(ctx, f)
+}
+
+// encryptedDocumentOCRRepo mengenkripsi NIK hasil OCR seperti NIK user.
+type encryptedDocumentOCRRepo struct {
+	next DocumentOCRRepo
+	ring *fieldcrypt.KeyRing
+}
+
+func (r encryptedDocumentOCRRepo) Save(ctx context.Context, o *DocumentOCR) error {
+	stored := *o
+	var err error
+	if stored.NIK, err = r.ring.Encrypt(o.NIK); err != nil {
+		return fmt.Errorf("error encrypting OCR nik: %w", err)
+	}
+	if err := r.next.Save(ctx, &stored); err != nil {
+		return err
+	}
+	o.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+func (r encryptedDocumentOCRRepo) GetByImageID(ctx context.Context, imageID int64) (*DocumentOCR, error) {
+	o, err := r.next.GetByImageID(ctx, imageID)
+	if err != nil {
+		return nil, err
+	}
+	if o.NIK, err = r.ring.Decrypt(o.NIK); err != nil {
+		return nil, fmt.Errorf("error decrypting OCR nik of image ID %d: %w", imageID, err)
+	}
+	return o, nil
+}
+
+// RotateUserFields mengenkripsi ulang nik dan phone semua user, termasuk
+// yang sedang di-soft delete, dengan key aktif dan menghitung ulang
+// nik_hash. Setiap batch berisi batchSize baris dalam satu transaksi
+// sehingga proses aman dihentikan lalu diulang. Plaintext lama ikut
+// dienkripsi. Mengembalikan jumlah baris yang diubah.
+func RotateUserFields(ctx context.Context, db *sql.DB, ring *fieldcrypt.KeyRing, batchSize int) (int, error) {
+	if batchSize < 1 {
+		return 0, fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+	total, after := 0, ""
+	for {
+		updated, last, err := rotateUserBatch(ctx, db, ring, after, batchSize)
+		if err != nil {
+			return total, err
+		}
+		total += updated
+		if last == "" {
+			return total, nil
+		}
+		after = last
+	}
+}
+
+// rotateUserBatch memproses user dengan user_id > after. last kosong
+// berarti tidak ada baris lagi setelah batch ini.
+func rotateUserBatch(ctx context.Context, db *sql.DB, ring *fieldcrypt.KeyRing, after string, limit int) (updated int, last string, err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT user_id, nik, phone, nik_hash FROM users
+        WHERE user_id > $1 ORDER BY user_id LIMIT $2 FOR UPDATE`, after, limit)
+	if err != nil {
+		return 0, "", fmt.Errorf("error selecting users to rotate: %w", err)
+	}
+	type row struct{ id, nik, phone, hash string }
+	var batch []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.nik, &r.phone, &r.hash); err != nil {
+			rows.Close()
+			return 0, "", err
+		}
+		batch = append(batch, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, "", err
+	}
+
+	for _, r := range batch {
+		nik, err := ring.Decrypt(r.nik)
+		if err != nil {
+			return 0, "", fmt.Errorf("error decrypting nik of user ID %s: %w", r.id, err)
+		}
+		phone, err := ring.Decrypt(r.phone)
+		if err != nil {
+			return 0, "", fmt.Errorf("error decrypting phone of user ID %s: %w", r.id, err)
+		}
+		hash := ring.BlindIndex(nik)
+		if !ring.NeedsRotation(r.nik) && !ring.NeedsRotation(r.phone) && hash == r.hash {
+			continue
+		}
+		encNIK, encPhone := r.nik, r.phone
+		if ring.NeedsRotation(r.nik) {
+			if encNIK, err = ring.Encrypt(nik); err != nil {
+				return 0, "", err
+			}
+		}
+		if ring.NeedsRotation(r.phone) {
+			if encPhone, err = ring.Encrypt(phone); err != nil {
+				return 0, "", err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE users SET nik = $2, phone = $3, nik_hash = $4 WHERE user_id = $1`,
+			r.id, encNIK, encPhone, hash); err != nil {
+			return 0, "", fmt.Errorf("error rotating user ID %s: %w", r.id, err)
+		}
+		updated++
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, "", err
+	}
+	if len(batch) < limit {
+		return updated, "", nil
+	}
+	return updated, batch[len(batch)-1].id, nil
+}
+
+// RotateDocumentOCRFields mengenkripsi ulang NIK hasil OCR dengan key
+// aktif, per batch seperti RotateUserFields. Mengembalikan jumlah baris
+// yang diubah.
+func RotateDocumentOCRFields(ctx context.Context, db *sql.DB, ring *fieldcrypt.KeyRing, batchSize int) (int, error) {
+	if batchSize < 1 {
+		return 0, fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+	total := 0
+	var after int64
+	for {
+		updated, last, err := rotateDocumentOCRBatch(ctx, db, ring, after, batchSize)
+		if err != nil {
+			return total, err
+		}
+		total += updated
+		if last == 0 {
+			return total, nil
+		}
+		after = last
+	}
+}
+
+// rotateDocumentOCRBatch memproses hasil OCR dengan image_id > after. last
+// nol berarti tidak ada baris lagi setelah batch ini.
+func rotateDocumentOCRBatch(ctx context.Context, db *sql.DB, ring *fieldcrypt.KeyRing, after int64, limit int) (updated int, last int64, err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT image_id, nik FROM document_ocr_results
+        WHERE image_id > $1 ORDER BY image_id LIMIT $2 FOR UPDATE`, after, limit)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error selecting OCR results to rotate: %w", err)
+	}
+	type row struct {
+		id  int64
+		nik string
+	}
+	var batch []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.nik); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		batch = append(batch, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	for _, r := range batch {
+		if !ring.NeedsRotation(r.nik) {
+			continue
+		}
+		nik, err := ring.Decrypt(r.nik)
+		if err != nil {
+			return 0, 0, fmt.Errorf("error decrypting OCR nik of image ID %d: %w", r.id, err)
+		}
+		if nik, err = ring.Encrypt(nik); err != nil {
+			return 0, 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE document_ocr_results SET nik = $2 WHERE image_id = $1`, r.id, nik); err != nil {
+			return 0, 0, fmt.Errorf("error rotating OCR nik of image ID %d: %w", r.id, err)
+		}
+		updated++
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	if len(batch) < limit {
+		return updated, 0, nil
+	}
+	return updated, batch[len(batch)-1].id, nil
+}
@@ -6,6 +6,8 @@ import (
     "errors"
     "fmt"
     "time"
+
+    "github.com/jaga-project/jaga-backend/internal/logging"
 )
 
 type Image struct {
@@ -36,20 +38,19 @@ func GetImageStoragePath(ctx context.Context, q Querier, imageID int64) (string,
     return storagePath, nil
 }
 
-func CreateImageTx(ctx context.Context, tx *sql.Tx, img *Image) error {
+func CreateImageTx(ctx context.Context, tx Querier, img *Image) error {
     query := `INSERT INTO images (storage_path, filename_original, mime_type, size_bytes, uploaded_at)
               VALUES ($1, $2, $3, $4, NOW()) RETURNING image_id, uploaded_at`
-    fmt.Printf("DEBUG DB CreateImageTx: Attempting to insert image. Path: %s, OriginalName: %s, Mime: %s, Size: %d\n", img.StoragePath, img.FilenameOriginal, img.MimeType, img.SizeBytes)
     err := tx.QueryRowContext(ctx, query, img.StoragePath, img.FilenameOriginal, img.MimeType, img.SizeBytes).Scan(&img.ImageID, &img.UploadedAt)
     if err != nil {
-        fmt.Printf("ERROR DB CreateImageTx: Failed to insert/scan image: %v\n", err)
+        logging.FromContext(ctx).Error("failed to insert image", "path", img.StoragePath, "error", err)
         return err
     }
-    fmt.Printf("DEBUG DB CreateImageTx: Successfully inserted image. ImageID: %d, UploadedAt: %v\n", img.ImageID, img.UploadedAt)
+    logging.FromContext(ctx).Debug("image inserted", "image_id", img.ImageID, "path", img.StoragePath)
     return nil
 }
 
-func GetImageByID(ctx context.Context, db *sql.DB, id int64) (*Image, error) {
+func GetImageByID(ctx context.Context, db Querier, id int64) (*Image, error) {
     var img Image
     query := `SELECT image_id, storage_path, filename_original, mime_type, size_bytes, uploaded_at
               FROM images WHERE image_id = $1`
@@ -58,14 +59,14 @@ func GetImageByID(ctx context.Context, db *sql.DB, id int64) (*Image, error) {
     )
     if err != nil {
         if errors.Is(err, sql.ErrNoRows) { 
-            return nil, errors.New("image not found")
+            return nil, ErrImageNotFound
         }
         return nil, fmt.Errorf("error getting image by ID %d: %w", id, err)
     }
     return &img, nil
 }
 
-func DeleteImageTx(ctx context.Context, tx *sql.Tx, id int64) error {
+func DeleteImageTx(ctx context.Context, tx Querier, id int64) error {
     query := `DELETE FROM images WHERE image_id = $1`
     res, err := tx.ExecContext(ctx, query, id)
     if err != nil {
@@ -81,7 +82,7 @@ func DeleteImageTx(ctx context.Context, tx *sql.Tx, id int64) error {
     return nil
 }
 
-func UpdateImageMetadataTx(ctx context.Context, tx *sql.Tx, id int64, newFilenameOriginal string) error {
+func UpdateImageMetadataTx(ctx context.Context, tx Querier, id int64, newFilenameOriginal string) error {
     query := `UPDATE images SET filename_original = $1 WHERE image_id = $2`
     res, err := tx.ExecContext(ctx, query, newFilenameOriginal, id)
     if err != nil {
@@ -97,7 +98,7 @@ func UpdateImageMetadataTx(ctx context.Context, tx *sql.Tx, id int64, newFilenam
     return nil
 }
 
-func GetImageStoragePathAndDeleteTx(ctx context.Context, tx *sql.Tx, imageID int64) (string, error) {
+func GetImageStoragePathAndDeleteTx(ctx context.Context, tx Querier, imageID int64) (string, error) {
     var storagePath string
 
     err := tx.QueryRowContext(ctx, "SELECT storage_path FROM images WHERE image_id = $1", imageID).Scan(&storagePath)
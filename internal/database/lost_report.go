@@ -45,9 +45,9 @@ type LostReportWithVehicleInfo struct {
 	PlateNumber sql.NullString `json:"plate_number"`
 }
 
-func CreateLostReportTx(ctx context.Context, tx *sql.Tx, lr *LostReport) error {
-	query := `INSERT INTO lost_report (user_id, timestamp, vehicle_id, address, latitude, longitude, status, motor_evidence_image_id, person_evidence_image_id)
-              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING lost_id`
+func CreateLostReportTx(ctx context.Context, tx Querier, lr *LostReport) error {
+	query := `INSERT INTO lost_report (user_id, timestamp, vehicle_id, address, latitude, longitude, status, motor_evidence_image_id, person_evidence_image_id, found_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CASE WHEN $7 = '` + StatusLostReportSudahDitemukan + `' THEN NOW() END) RETURNING lost_id`
 	err := tx.QueryRowContext(ctx, query, lr.UserID, lr.Timestamp, lr.VehicleID, lr.Address, lr.Latitude, lr.Longitude, lr.Status, lr.MotorEvidenceImageID, lr.PersonEvidenceImageID).Scan(&lr.LostID)
 	if err != nil {
 		return fmt.Errorf("error creating lost report in tx: %w", err)
@@ -55,23 +55,23 @@ func CreateLostReportTx(ctx context.Context, tx *sql.Tx, lr *LostReport) error {
 	return nil
 }
 
-func GetLostReportByID(ctx context.Context, db *sql.DB, id int) (*LostReport, error) {
+func GetLostReportByID(ctx context.Context, db Querier, id int) (*LostReport, error) {
 	var lr LostReport
-	query := `SELECT lost_id, user_id, timestamp, vehicle_id, address, status, motor_evidence_image_id, person_evidence_image_id 
-              FROM lost_report WHERE lost_id = $1`
+	query := `SELECT lost_id, user_id, timestamp, vehicle_id, address, latitude, longitude, status, motor_evidence_image_id, person_evidence_image_id 
+              FROM lost_report WHERE lost_id = $1 AND deleted_at IS NULL`
 	err := db.QueryRowContext(ctx, query, id).Scan(
-		&lr.LostID, &lr.UserID, &lr.Timestamp, &lr.VehicleID, &lr.Address, &lr.Status, &lr.MotorEvidenceImageID, &lr.PersonEvidenceImageID,
+		&lr.LostID, &lr.UserID, &lr.Timestamp, &lr.VehicleID, &lr.Address, &lr.Latitude, &lr.Longitude, &lr.Status, &lr.MotorEvidenceImageID, &lr.PersonEvidenceImageID,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("lost_report not found")
+			return nil, ErrLostReportNotFound
 		}
 		return nil, fmt.Errorf("error getting lost report by ID %d: %w", id, err)
 	}
 	return &lr, nil
 }
 
-func GetLostReportWithVehicleInfoByID(ctx context.Context, db *sql.DB, id int) (*LostReportWithVehicleInfo, error) {
+func GetLostReportWithVehicleInfoByID(ctx context.Context, db Querier, id int) (*LostReportWithVehicleInfo, error) {
 	var lr LostReportWithVehicleInfo
 	query := `
         SELECT 
@@ -80,7 +80,7 @@ func GetLostReportWithVehicleInfoByID(ctx context.Context, db *sql.DB, id int) (
             v.vehicle_name, v.plate_number
         FROM lost_report lr
         LEFT JOIN vehicle v ON lr.vehicle_id = v.vehicle_id
-        WHERE lr.lost_id = $1`
+        WHERE lr.lost_id = $1 AND lr.deleted_at IS NULL`
 
 	err := db.QueryRowContext(ctx, query, id).Scan(
 		&lr.LostID, &lr.UserID, &lr.Timestamp, &lr.VehicleID, &lr.Address, &lr.Latitude, &lr.Longitude, &lr.Status,
@@ -89,22 +89,22 @@ func GetLostReportWithVehicleInfoByID(ctx context.Context, db *sql.DB, id int) (
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("lost_report not found")
+			return nil, ErrLostReportNotFound
 		}
 		return nil, fmt.Errorf("error getting lost report with vehicle info by ID %d: %w", id, err)
 	}
 	return &lr, nil
 }
 
-func ListLostReports(ctx context.Context, db *sql.DB, statusFilter string) ([]LostReport, error) {
+func ListLostReports(ctx context.Context, db Querier, statusFilter string) ([]LostReport, error) {
 	var queryBuilder strings.Builder
-	queryBuilder.WriteString(`SELECT lost_id, user_id, timestamp, vehicle_id, address, status, motor_evidence_image_id, person_evidence_image_id FROM lost_report`)
+	queryBuilder.WriteString(`SELECT lost_id, user_id, timestamp, vehicle_id, address, status, motor_evidence_image_id, person_evidence_image_id FROM lost_report WHERE deleted_at IS NULL`)
 
 	var args []interface{}
 	paramIndex := 1
 
 	if statusFilter != "" {
-		queryBuilder.WriteString(fmt.Sprintf(" WHERE status = $%d", paramIndex))
+		queryBuilder.WriteString(fmt.Sprintf(" AND status = $%d", paramIndex))
 		args = append(args, statusFilter)
 		paramIndex++
 	}
@@ -120,7 +120,7 @@ func ListLostReports(ctx context.Context, db *sql.DB, statusFilter string) ([]Lo
 	var list []LostReport
 	for rows.Next() {
 		var lr LostReport
-		if errScan := rows.Scan(&lr.LostID, &lr.UserID, &lr.Timestamp, &lr.VehicleID, &lr.Address, &lr.Status, &lr.MotorEvidenceImageID, &lr.PersonEvidenceImageID); errScan != nil {
+		if errScan := rows.Scan(&lr.LostID, &lr.UserID, &lr.Timestamp, &lr.VehicleID, &lr.Address, &lr.Latitude, &lr.Longitude, &lr.Status, &lr.MotorEvidenceImageID, &lr.PersonEvidenceImageID); errScan != nil {
 			return nil, fmt.Errorf("error scanning lost report row: %w", errScan)
 		}
 		list = append(list, lr)
@@ -131,7 +131,7 @@ func ListLostReports(ctx context.Context, db *sql.DB, statusFilter string) ([]Lo
 	return list, nil
 }
 
-func ListLostReportsWithVehicleInfo(ctx context.Context, db *sql.DB, statusFilter string) ([]LostReportWithVehicleInfo, error) {
+func ListLostReportsWithVehicleInfo(ctx context.Context, db Querier, statusFilter string) ([]LostReportWithVehicleInfo, error) {
 	var queryBuilder strings.Builder
 	queryBuilder.WriteString(`
         SELECT 
@@ -139,11 +139,12 @@ func ListLostReportsWithVehicleInfo(ctx context.Context, db *sql.DB, statusFilte
             lr.motor_evidence_image_id, lr.person_evidence_image_id,
             v.vehicle_name, v.plate_number
         FROM lost_report lr
-        LEFT JOIN vehicle v ON lr.vehicle_id = v.vehicle_id`)
+        LEFT JOIN vehicle v ON lr.vehicle_id = v.vehicle_id
+        WHERE lr.deleted_at IS NULL`)
 
 	var args []interface{}
 	if statusFilter != "" {
-		queryBuilder.WriteString(" WHERE lr.status = $1")
+		queryBuilder.WriteString(" AND lr.status = $1")
 		args = append(args, statusFilter)
 	}
 	queryBuilder.WriteString(" ORDER BY lr.timestamp DESC")
@@ -172,7 +173,7 @@ func ListLostReportsWithVehicleInfo(ctx context.Context, db *sql.DB, statusFilte
 	return list, nil
 }
 
-func ListLostReportsWithVehicleInfoByUserID(ctx context.Context, db *sql.DB, userID string) ([]LostReportWithVehicleInfo, error) {
+func ListLostReportsWithVehicleInfoByUserID(ctx context.Context, db Querier, userID string) ([]LostReportWithVehicleInfo, error) {
     query := `
         SELECT 
             lr.lost_id, lr.user_id, lr.timestamp, lr.vehicle_id, lr.address, lr.latitude, lr.longitude, lr.status, 
@@ -180,7 +181,7 @@ func ListLostReportsWithVehicleInfoByUserID(ctx context.Context, db *sql.DB, use
             v.vehicle_name, v.plate_number
         FROM lost_report lr
         LEFT JOIN vehicle v ON lr.vehicle_id = v.vehicle_id
-        WHERE lr.user_id = $1
+        WHERE lr.user_id = $1 AND lr.deleted_at IS NULL
         ORDER BY lr.timestamp DESC`
 
     rows, err := db.QueryContext(ctx, query, userID)
@@ -207,10 +208,10 @@ func ListLostReportsWithVehicleInfoByUserID(ctx context.Context, db *sql.DB, use
     return list, nil
 }
 
-func ListLostReportsByUserID(ctx context.Context, db *sql.DB, userID string) ([]LostReport, error) {
-    query := `SELECT lost_id, user_id, timestamp, vehicle_id, address, status, motor_evidence_image_id, person_evidence_image_id 
+func ListLostReportsByUserID(ctx context.Context, db Querier, userID string) ([]LostReport, error) {
+    query := `SELECT lost_id, user_id, timestamp, vehicle_id, address, latitude, longitude, status, motor_evidence_image_id, person_evidence_image_id 
               FROM lost_report 
-              WHERE user_id = $1 
+              WHERE user_id = $1 AND deleted_at IS NULL
               ORDER BY timestamp DESC`
 
     rows, err := db.QueryContext(ctx, query, userID)
@@ -222,7 +223,7 @@ func ListLostReportsByUserID(ctx context.Context, db *sql.DB, userID string) ([]
     var list []LostReport
     for rows.Next() {
         var lr LostReport
-        if errScan := rows.Scan(&lr.LostID, &lr.UserID, &lr.Timestamp, &lr.VehicleID, &lr.Address, &lr.Status, &lr.MotorEvidenceImageID, &lr.PersonEvidenceImageID); errScan != nil {
+        if errScan := rows.Scan(&lr.LostID, &lr.UserID, &lr.Timestamp, &lr.VehicleID, &lr.Address, &lr.Latitude, &lr.Longitude, &lr.Status, &lr.MotorEvidenceImageID, &lr.PersonEvidenceImageID); errScan != nil {
             return nil, fmt.Errorf("error scanning lost report row for user ID %s: %w", userID, errScan)
         }
         list = append(list, lr)
@@ -233,7 +234,9 @@ func ListLostReportsByUserID(ctx context.Context, db *sql.DB, userID string) ([]
     return list, nil
 }
 
-func UpdateLostReport(ctx context.Context, db *sql.DB, id int, lr *LostReport) error {
+// UpdateLostReport juga mencatat found_at saat status pertama kali menjadi
+// SUDAH_DITEMUKAN dan mengosongkannya bila status dikembalikan.
+func UpdateLostReport(ctx context.Context, db Querier, id int, lr *LostReport) error {
 	query := `UPDATE lost_report SET 
                 user_id=$1, 
                 timestamp=$2, 
@@ -243,8 +246,9 @@ func UpdateLostReport(ctx context.Context, db *sql.DB, id int, lr *LostReport) e
                 longitude=$6, 
                 status=$7, 
                 motor_evidence_image_id=$8, 
-                person_evidence_image_id=$9 
-              WHERE lost_id=$10`
+                person_evidence_image_id=$9,
+                found_at = CASE WHEN $7 = '` + StatusLostReportSudahDitemukan + `' THEN COALESCE(found_at, NOW()) END
+              WHERE lost_id=$10 AND deleted_at IS NULL`
 	res, err := db.ExecContext(ctx, query, lr.UserID, lr.Timestamp, lr.VehicleID, lr.Address, lr.Latitude, lr.Longitude, lr.Status, lr.MotorEvidenceImageID, lr.PersonEvidenceImageID, id)
 	if err != nil {
 		return fmt.Errorf("error updating lost report ID %d: %w", id, err)
@@ -259,8 +263,10 @@ func UpdateLostReport(ctx context.Context, db *sql.DB, id int, lr *LostReport) e
 	return nil
 }
 
-func DeleteLostReport(ctx context.Context, db *sql.DB, id int) error {
-	res, err := db.ExecContext(ctx, `DELETE FROM lost_report WHERE lost_id = $1`, id)
+// DeleteLostReport menandai laporan sebagai terhapus. Suspect yang
+// merujuknya tetap ada sampai laporan di-purge.
+func DeleteLostReport(ctx context.Context, db Querier, id int) error {
+	res, err := db.ExecContext(ctx, `UPDATE lost_report SET deleted_at = NOW() WHERE lost_id = $1 AND deleted_at IS NULL`, id)
 	if err != nil {
 		return fmt.Errorf("error deleting lost report ID %d: %w", id, err)
 	}
@@ -273,3 +279,95 @@ func DeleteLostReport(ctx context.Context, db *sql.DB, id int) error {
 	}
 	return nil
 }
+
+// RestoreLostReport mengembalikan ErrLostReportNotFound bila laporan tidak
+// sedang terhapus.
+func RestoreLostReport(ctx context.Context, db Querier, id int) error {
+	res, err := db.ExecContext(ctx, `UPDATE lost_report SET deleted_at = NULL WHERE lost_id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return fmt.Errorf("error restoring lost report ID %d: %w", id, err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected for lost report ID %d restore: %w", id, err)
+	}
+	if count == 0 {
+		return ErrLostReportNotFound
+	}
+	return nil
+}
+
+// PurgeDeletedLostReports menghapus permanen laporan yang di-soft delete
+// sebelum before, beserta suspect-nya (ON DELETE CASCADE).
+func PurgeDeletedLostReports(ctx context.Context, db Querier, before time.Time) (*PurgedRows, error) {
+	rows, err := db.QueryContext(ctx, `DELETE FROM lost_report WHERE deleted_at < $1
+        RETURNING motor_evidence_image_id, person_evidence_image_id`, before)
+	if err != nil {
+		return nil, fmt.Errorf("error purging deleted lost reports: %w", err)
+	}
+	return scanPurgedRows(rows)
+}
+
+// CountLostReportsByStatus dipakai untuk metrik jumlah laporan per status.
+func CountLostReportsByStatus(ctx context.Context, db Querier) (map[string]int, error) {
+	rows, err := db.QueryContext(ctx, `SELECT status, COUNT(*) FROM lost_report WHERE deleted_at IS NULL GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("error counting lost reports by status: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, fmt.Errorf("error scanning lost report count: %w", err)
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
+
+// LostReportLocation adalah titik kejadian laporan untuk analisis hotspot.
+type LostReportLocation struct {
+	LostID    int
+	Latitude  float64
+	Longitude float64
+	Timestamp time.Time
+}
+
+// ListLostReportLocations mengembalikan laporan yang punya koordinat dengan
+// waktu kejadian di [from, to).
+func ListLostReportLocations(ctx context.Context, db Querier, from, to time.Time) ([]LostReportLocation, error) {
+	rows, err := db.QueryContext(ctx, `SELECT lost_id, latitude, longitude, timestamp FROM lost_report
+        WHERE deleted_at IS NULL AND latitude IS NOT NULL AND longitude IS NOT NULL
+            AND timestamp >= $1 AND timestamp < $2
+        ORDER BY lost_id`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error listing lost report locations: %w", err)
+	}
+	defer rows.Close()
+
+	var locations []LostReportLocation
+	for rows.Next() {
+		var l LostReportLocation
+		if err := rows.Scan(&l.LostID, &l.Latitude, &l.Longitude, &l.Timestamp); err != nil {
+			return nil, fmt.Errorf("error scanning lost report location: %w", err)
+		}
+		locations = append(locations, l)
+	}
+	return locations, rows.Err()
+}
+
+// CountOpenLostReportsByVehicleID menghitung laporan aktif kendaraan yang
+// belum ditemukan (BELUM_DIPROSES atau SEDANG_DIPROSES).
+func CountOpenLostReportsByVehicleID(ctx context.Context, db Querier, vehicleID int64) (int, error) {
+	var n int
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM lost_report
+        WHERE vehicle_id = $1 AND deleted_at IS NULL AND status IN ($2, $3)`,
+		vehicleID, StatusLostReportBelumDiproses, StatusLostReportSedangDiproses).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("error counting open lost reports of vehicle ID %d: %w", vehicleID, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+type recoveryCode struct {
+	userID string
+	hash   string
+	used   bool
+}
+
+type adminMFARepo struct{ s *Store }
+
+func (r adminMFARepo) Get(ctx context.Context, userID string) (*database.AdminMFA, error) {
+	defer r.s.lock()()
+	m, ok := r.s.st.adminMFA[userID]
+	if !ok {
+		return nil, database.ErrMFANotFound
+	}
+	return &m, nil
+}
+
+func (r adminMFARepo) SaveEnrollment(ctx context.Context, userID, secret string) error {
+	defer r.s.lock()()
+	if m, ok := r.s.st.adminMFA[userID]; ok && m.EnabledAt != nil {
+		return database.ErrMFAAlreadyEnabled
+	}
+	r.s.st.adminMFA[userID] = database.AdminMFA{UserID: userID, Secret: secret, CreatedAt: time.Now()}
+	return nil
+}
+
+func (r adminMFARepo) Enable(ctx context.Context, userID string, step int64, at time.Time) error {
+	defer r.s.lock()()
+	m, ok := r.s.st.adminMFA[userID]
+	if !ok || m.EnabledAt != nil {
+		return database.ErrMFANotFound
+	}
+	m.EnabledAt = &at
+	m.LastUsedStep = step
+	r.s.st.adminMFA[userID] = m
+	return nil
+}
+
+func (r adminMFARepo) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	defer r.s.lock()()
+	m, ok := r.s.st.adminMFA[userID]
+	if !ok || m.LastUsedStep >= step {
+		return false, nil
+	}
+	m.LastUsedStep = step
+	r.s.st.adminMFA[userID] = m
+	return true, nil
+}
+
+func (r adminMFARepo) Delete(ctx context.Context, userID string) error {
+	defer r.s.lock()()
+	r.s.st.removeRecoveryCodes(userID)
+	if _, ok := r.s.st.adminMFA[userID]; !ok {
+		return database.ErrMFANotFound
+	}
+	delete(r.s.st.adminMFA, userID)
+	return nil
+}
+
+func (r adminMFARepo) ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error {
+	defer r.s.lock()()
+	r.s.st.removeRecoveryCodes(userID)
+	for _, h := range hashes {
+		r.s.st.recoveryCodes = append(r.s.st.recoveryCodes, recoveryCode{userID: userID, hash: h})
+	}
+	return nil
+}
+
+func (r adminMFARepo) UseRecoveryCode(ctx context.Context, userID, hash string, at time.Time) (bool, error) {
+	defer r.s.lock()()
+	for i, c := range r.s.st.recoveryCodes {
+		if c.userID == userID && c.hash == hash && !c.used {
+			r.s.st.recoveryCodes[i].used = true
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r adminMFARepo) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	defer r.s.lock()()
+	n := 0
+	for _, c := range r.s.st.recoveryCodes {
+		if c.userID == userID && !c.used {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r adminMFARepo) GetPolicy(ctx context.Context) (*database.MFAPolicy, error) {
+	defer r.s.lock()()
+	p := r.s.st.mfaPolicy
+	return &p, nil
+}
+
+func (r adminMFARepo) UpdatePolicy(ctx context.Context, p *database.MFAPolicy) error {
+	defer r.s.lock()()
+	p.UpdatedAt = time.Now()
+	r.s.st.mfaPolicy = *p
+	return nil
+}
+
+func (st *state) removeRecoveryCodes(userID string) {
+	kept := st.recoveryCodes[:0]
+	for _, c := range st.recoveryCodes {
+		if c.userID != userID {
+			kept = append(kept, c)
+		}
+	}
+	st.recoveryCodes = kept
+}
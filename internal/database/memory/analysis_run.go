@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+type analysisRunRepo struct{ s *Store }
+
+func (r analysisRunRepo) Create(ctx context.Context, a *database.AnalysisRun) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.lostReports[a.LostID]; !ok {
+		return errors.New("pq: insert or update on table \"analysis_runs\" violates foreign key constraint")
+	}
+	// Meniru unique index idx_analysis_runs_active.
+	for _, existing := range r.s.st.analysisRuns {
+		if existing.LostID == a.LostID && existing.Active() {
+			return errDuplicateKey
+		}
+	}
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	a.Status = database.AnalysisRunQueued
+	r.s.st.nextAnalysisRunID++
+	a.RunID = r.s.st.nextAnalysisRunID
+	r.s.st.analysisRuns[a.RunID] = *a
+	return nil
+}
+
+func (r analysisRunRepo) GetByID(ctx context.Context, id int64) (*database.AnalysisRun, error) {
+	defer r.s.lock()()
+	a, ok := r.s.st.analysisRuns[id]
+	if !ok {
+		return nil, database.ErrAnalysisRunNotFound
+	}
+	return &a, nil
+}
+
+func (r analysisRunRepo) GetLatestByLostReportID(ctx context.Context, lostID int) (*database.AnalysisRun, error) {
+	runs := r.list(lostID)
+	if len(runs) == 0 {
+		return nil, database.ErrAnalysisRunNotFound
+	}
+	return &runs[0], nil
+}
+
+func (r analysisRunRepo) ListByLostReportID(ctx context.Context, lostID int) ([]database.AnalysisRun, error) {
+	return r.list(lostID), nil
+}
+
+func (r analysisRunRepo) list(lostID int) []database.AnalysisRun {
+	defer r.s.lock()()
+	var runs []database.AnalysisRun
+	for _, a := range r.s.st.analysisRuns {
+		if a.LostID == lostID {
+			runs = append(runs, a)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].RunID > runs[j].RunID })
+	return runs
+}
+
+func (r analysisRunRepo) Claim(ctx context.Context, scorerVersion string, at time.Time) (*database.AnalysisRun, error) {
+	defer r.s.lock()()
+	for _, id := range sortedKeys(r.s.st.analysisRuns, func(a, b int64) bool { return a < b }) {
+		a := r.s.st.analysisRuns[id]
+		if a.Status != database.AnalysisRunQueued {
+			continue
+		}
+		if _, ok := r.s.st.lostReport(a.LostID); !ok {
+			continue
+		}
+		a.Status = database.AnalysisRunRunning
+		a.ScorerVersion = scorerVersion
+		a.StartedAt = &at
+		r.s.st.analysisRuns[id] = a
+		return &a, nil
+	}
+	return nil, database.ErrAnalysisRunNotFound
+}
+
+func (r analysisRunRepo) UpdateQueuedScope(ctx context.Context, id int64, p database.SearchParams) (bool, error) {
+	defer r.s.lock()()
+	a, ok := r.s.st.analysisRuns[id]
+	if !ok || a.Status != database.AnalysisRunQueued {
+		return false, nil
+	}
+	a.RadiusKm, a.WindowHours = p.RadiusKm, p.WindowHours
+	a.CameraIDs = append([]int64(nil), p.CameraIDs...)
+	a.Zones = append([]string(nil), p.Zones...)
+	r.s.st.analysisRuns[id] = a
+	return true, nil
+}
+
+func (r analysisRunRepo) Finish(ctx context.Context, id int64, status string, detectionsScanned *int, errMsg string, at time.Time) error {
+	defer r.s.lock()()
+	a, ok := r.s.st.analysisRuns[id]
+	if !ok || a.Status != database.AnalysisRunRunning {
+		return database.ErrAnalysisRunNotRunning
+	}
+	a.Status = status
+	a.DetectionsScanned = detectionsScanned
+	a.Error = errMsg
+	a.FinishedAt = &at
+	r.s.st.analysisRuns[id] = a
+	return nil
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+type auditLogRepo struct{ s *Store }
+
+func (r auditLogRepo) Append(ctx context.Context, e *database.AuditEntry) error {
+	defer r.s.lock()()
+	prevHash := ""
+	if n := len(r.s.st.auditLog); n > 0 {
+		prevHash = r.s.st.auditLog[n-1].Hash
+	}
+	e.Seal(prevHash)
+	e.ID = int64(len(r.s.st.auditLog) + 1)
+	r.s.st.auditLog = append(r.s.st.auditLog, *e)
+	return nil
+}
+
+func (r auditLogRepo) List(ctx context.Context, f database.AuditFilter) ([]database.AuditEntry, error) {
+	defer r.s.lock()()
+	out := []database.AuditEntry{}
+	skipped := 0
+	for i := len(r.s.st.auditLog) - 1; i >= 0; i-- {
+		e := r.s.st.auditLog[i]
+		if !f.Match(&e) {
+			continue
+		}
+		if skipped < f.Offset {
+			skipped++
+			continue
+		}
+		out = append(out, e)
+		if f.Limit > 0 && len(out) == f.Limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (r auditLogRepo) Verify(ctx context.Context) (*database.AuditVerification, error) {
+	defer r.s.lock()()
+	chain := database.NewAuditChain()
+	for i := range r.s.st.auditLog {
+		if !chain.Add(&r.s.st.auditLog[i]) {
+			break
+		}
+	}
+	return chain.Result(), nil
+}
+
+// TamperAuditEntry mengubah action entri audit tanpa memperbarui hash-nya,
+// untuk menguji verifikasi rantai.
+func (s *Store) TamperAuditEntry(id int64, action string) {
+	defer s.lock()()
+	for i := range s.st.auditLog {
+		if s.st.auditLog[i].ID == id {
+			s.st.auditLog[i].Action = action
+		}
+	}
+}
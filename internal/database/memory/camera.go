@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+type cameraRepo struct{ s *Store }
+
+func (r cameraRepo) Create(ctx context.Context, c *database.Camera) error {
+	defer r.s.lock()()
+	r.s.st.nextCameraID++
+	c.CameraID = r.s.st.nextCameraID
+	r.s.st.cameras[c.CameraID] = *c
+	return nil
+}
+
+func (r cameraRepo) GetByID(ctx context.Context, id int64) (*database.Camera, error) {
+	defer r.s.lock()()
+	c, ok := r.s.st.camera(id)
+	if !ok {
+		return nil, database.ErrCameraNotFound
+	}
+	return &c, nil
+}
+
+func (r cameraRepo) List(ctx context.Context) ([]database.Camera, error) {
+	defer r.s.lock()()
+	var list []database.Camera
+	for id, c := range r.s.st.cameras {
+		if _, deleted := r.s.st.deletedCameras[id]; !deleted {
+			list = append(list, c)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+func (r cameraRepo) CountActive(ctx context.Context) (int, error) {
+	defer r.s.lock()()
+	n := 0
+	for id, c := range r.s.st.cameras {
+		if _, deleted := r.s.st.deletedCameras[id]; !deleted && c.IsActive {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r cameraRepo) Update(ctx context.Context, id int64, c *database.Camera) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.camera(id); !ok {
+		return errors.New("no camera record updated")
+	}
+	updated := *c
+	updated.CameraID = id
+	r.s.st.cameras[id] = updated
+	return nil
+}
+
+func (r cameraRepo) Delete(ctx context.Context, id int64) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.camera(id); !ok {
+		return errors.New("no camera record deleted")
+	}
+	r.s.st.deletedCameras[id] = time.Now()
+	return nil
+}
+
+func (r cameraRepo) Restore(ctx context.Context, id int64) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.deletedCameras[id]; !ok {
+		return database.ErrCameraNotFound
+	}
+	delete(r.s.st.deletedCameras, id)
+	return nil
+}
+
+func (r cameraRepo) PurgeDeleted(ctx context.Context, before time.Time) (*database.PurgedRows, error) {
+	defer r.s.lock()()
+	p := &database.PurgedRows{}
+	for _, id := range expiredKeys(r.s.st.deletedCameras, before, func(a, b int64) bool { return a < b }) {
+		p.Count++
+		for _, d := range r.s.filterDetected(func(d database.Detected) bool { return int64(d.CameraID) == id }) {
+			p.ImageIDs = appendImageID(appendImageID(p.ImageIDs, d.PersonImageID), d.MotorcycleImageID)
+		}
+		r.s.st.purgeCamera(id)
+	}
+	return p, nil
+}
+
+// camera mengembalikan kamera yang tidak sedang di-soft delete.
+func (st *state) camera(id int64) (database.Camera, bool) {
+	c, ok := st.cameras[id]
+	if _, deleted := st.deletedCameras[id]; deleted {
+		return database.Camera{}, false
+	}
+	return c, ok
+}
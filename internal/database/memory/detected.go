@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/geo"
+)
+
+type detectedRepo struct{ s *Store }
+
+func (r detectedRepo) Create(ctx context.Context, d *database.Detected) error {
+	defer r.s.lock()()
+	r.s.st.nextDetectedID++
+	d.DetectedID = r.s.st.nextDetectedID
+	r.s.st.detected[d.DetectedID] = *d
+	return nil
+}
+
+func (r detectedRepo) GetByID(ctx context.Context, id int) (*database.Detected, error) {
+	defer r.s.lock()()
+	d, ok := r.s.st.detected[id]
+	if !ok {
+		return nil, database.ErrDetectedNotFound
+	}
+	return &d, nil
+}
+
+func (r detectedRepo) List(ctx context.Context) ([]database.Detected, error) {
+	defer r.s.lock()()
+	return r.s.filterDetected(func(database.Detected) bool { return true }), nil
+}
+
+func (r detectedRepo) ListByTimestampRange(ctx context.Context, startTime, endTime time.Time) ([]database.Detected, error) {
+	defer r.s.lock()()
+	return r.s.filterDetected(func(d database.Detected) bool {
+		return !d.Timestamp.Before(startTime) && !d.Timestamp.After(endTime)
+	}), nil
+}
+
+func (r detectedRepo) ListByCoordinates(ctx context.Context, lat, lon, radiusKm float64) ([]database.Detected, error) {
+	defer r.s.lock()()
+	return r.s.filterDetected(func(d database.Detected) bool {
+		return r.s.cameraWithinRadius(d.CameraID, lat, lon, radiusKm)
+	}), nil
+}
+
+func (r detectedRepo) ListByProximityAndTimestamp(ctx context.Context, lat, lon, radiusKm float64, startTime, endTime time.Time) ([]database.Detected, error) {
+	defer r.s.lock()()
+	return r.s.filterDetected(func(d database.Detected) bool {
+		return !d.Timestamp.Before(startTime) && !d.Timestamp.After(endTime) &&
+			r.s.cameraWithinRadius(d.CameraID, lat, lon, radiusKm)
+	}), nil
+}
+
+func (r detectedRepo) Update(ctx context.Context, id int, d *database.Detected) error {
+	defer r.s.lock()()
+	existing, ok := r.s.st.detected[id]
+	if !ok {
+		return errors.New("no detected record updated or record not found")
+	}
+	updated := *d
+	updated.DetectedID = id
+	updated.Attributes = existing.Attributes
+	r.s.st.detected[id] = updated
+	return nil
+}
+
+func (r detectedRepo) SetAttributes(ctx context.Context, id int, a database.DetectedAttributes) error {
+	defer r.s.lock()()
+	d, ok := r.s.st.detected[id]
+	if !ok {
+		return database.ErrDetectedNotFound
+	}
+	d.Attributes = a
+	r.s.st.detected[id] = d
+	return nil
+}
+
+func (r detectedRepo) Search(ctx context.Context, f database.DetectionSearchFilter) ([]database.Detected, error) {
+	defer r.s.lock()()
+	list := r.s.filterDetected(func(d database.Detected) bool { return matchDetectionSearch(d, f) })
+	return paginate(list, f.Limit, f.Offset), nil
+}
+
+func (r detectedRepo) CountSearch(ctx context.Context, f database.DetectionSearchFilter) (int, error) {
+	defer r.s.lock()()
+	return len(r.s.filterDetected(func(d database.Detected) bool { return matchDetectionSearch(d, f) })), nil
+}
+
+func matchDetectionSearch(d database.Detected, f database.DetectionSearchFilter) bool {
+	a := d.Attributes
+	if d.Timestamp.Before(f.From) || !d.Timestamp.Before(f.To) {
+		return false
+	}
+	if f.Brand != "" && (a.Brand == nil || !strings.EqualFold(*a.Brand, f.Brand)) {
+		return false
+	}
+	if f.Model != "" && (a.Model == nil || !strings.Contains(strings.ToLower(*a.Model), strings.ToLower(f.Model))) {
+		return false
+	}
+	if f.BodyType != "" && (a.BodyType == nil || *a.BodyType != f.BodyType) {
+		return false
+	}
+	if f.Color != "" && (a.Color == nil || *a.Color != f.Color) {
+		return false
+	}
+	if f.MinConfidence > 0 && (a.Confidence == nil || *a.Confidence < f.MinConfidence) {
+		return false
+	}
+	if len(f.CameraIDs) > 0 {
+		for _, id := range f.CameraIDs {
+			if int64(d.CameraID) == id {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (r detectedRepo) Delete(ctx context.Context, id int) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.detected[id]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(r.s.st.detected, id)
+	return nil
+}
+
+// filterDetected mengembalikan deteksi yang lolos keep, terbaru lebih dulu.
+func (s *Store) filterDetected(keep func(database.Detected) bool) []database.Detected {
+	var list []database.Detected
+	for _, d := range s.st.detected {
+		if keep(d) {
+			list = append(list, d)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Timestamp.Equal(list[j].Timestamp) {
+			return list[i].DetectedID > list[j].DetectedID
+		}
+		return list[i].Timestamp.After(list[j].Timestamp)
+	})
+	return list
+}
+
+func (s *Store) cameraWithinRadius(cameraID int, lat, lon, radiusKm float64) bool {
+	cam, ok := s.st.cameras[int64(cameraID)]
+	if !ok {
+		return false
+	}
+	return geo.HaversineKm(lat, lon, cam.Latitude, cam.Longitude) <= radiusKm
+}
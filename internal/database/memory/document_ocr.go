@@ -0,0 +1,29 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+type documentOCRRepo struct{ s *Store }
+
+func (r documentOCRRepo) Save(ctx context.Context, o *database.DocumentOCR) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.images[o.ImageID]; !ok {
+		return database.ErrImageNotFound
+	}
+	o.UpdatedAt = time.Now()
+	r.s.st.documentOCR[o.ImageID] = *o
+	return nil
+}
+
+func (r documentOCRRepo) GetByImageID(ctx context.Context, imageID int64) (*database.DocumentOCR, error) {
+	defer r.s.lock()()
+	o, ok := r.s.st.documentOCR[imageID]
+	if !ok {
+		return nil, database.ErrDocumentOCRNotFound
+	}
+	return &o, nil
+}
@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+func (r userRepo) SetKTPVerification(ctx context.Context, userID, status, verifiedBy, note string, at time.Time) error {
+	defer r.s.lock()()
+	u, ok := r.s.st.user(userID)
+	if !ok {
+		return database.ErrUserNotFound
+	}
+	u.KTPVerification = database.KTPVerification{KTPVerificationStatus: status}
+	if note != "" {
+		u.KTPVerificationNote = &note
+	}
+	if verifiedBy != "" {
+		u.KTPVerifiedBy = &verifiedBy
+		u.KTPVerifiedAt = &at
+	}
+	r.s.st.users[userID] = u
+	return nil
+}
+
+func (r vehicleRepo) SetKKVerification(ctx context.Context, id int64, status, verifiedBy, note string, at time.Time) error {
+	defer r.s.lock()()
+	v, ok := r.s.st.vehicle(id)
+	if !ok {
+		return database.ErrVehicleNotFound
+	}
+	v.KKVerification = database.KKVerification{KKVerificationStatus: status}
+	if note != "" {
+		v.KKVerificationNote = &note
+	}
+	if verifiedBy != "" {
+		v.KKVerifiedBy = &verifiedBy
+		v.KKVerifiedAt = &at
+	}
+	r.s.st.vehicles[id] = v
+	return nil
+}
+
+type verificationRepo struct{ s *Store }
+
+func (r verificationRepo) List(ctx context.Context, f database.VerificationFilter) ([]database.DocumentVerification, error) {
+	defer r.s.lock()()
+	list := r.s.st.documentVerifications(f)
+	if f.Offset >= len(list) {
+		return nil, nil
+	}
+	list = list[f.Offset:]
+	if f.Limit < len(list) {
+		list = list[:f.Limit]
+	}
+	return list, nil
+}
+
+func (r verificationRepo) Count(ctx context.Context, f database.VerificationFilter) (int, error) {
+	defer r.s.lock()()
+	return len(r.s.st.documentVerifications(f)), nil
+}
+
+// documentVerifications meniru ListDocumentVerifications tanpa Limit dan
+// Offset.
+func (st *state) documentVerifications(f database.VerificationFilter) []database.DocumentVerification {
+	var list []database.DocumentVerification
+	add := func(d database.DocumentVerification, imageID *int64) {
+		if imageID == nil || (f.DocumentType != "" && d.DocumentType != f.DocumentType) || d.Status != f.Status {
+			return
+		}
+		img, ok := st.images[*imageID]
+		if !ok {
+			return
+		}
+		d.ImageID, d.ImagePath, d.SubmittedAt = img.ImageID, img.StoragePath, img.UploadedAt
+		if o, ok := st.documentOCR[img.ImageID]; ok && o.DocumentType == d.DocumentType {
+			d.OCR = &o
+		}
+		list = append(list, d)
+	}
+	for id := range st.users {
+		u, ok := st.user(id)
+		if !ok {
+			continue
+		}
+		add(database.DocumentVerification{
+			DocumentType: database.DocumentKTP, UserID: u.UserID, UserName: u.Name, NIK: u.NIK,
+			Status: u.KTPVerificationStatus, Note: u.KTPVerificationNote, VerifiedBy: u.KTPVerifiedBy, VerifiedAt: u.KTPVerifiedAt,
+		}, u.KTPImageID)
+	}
+	for id := range st.vehicles {
+		v, ok := st.vehicle(id)
+		if !ok {
+			continue
+		}
+		u := st.users[v.UserID]
+		vehicleID := v.VehicleID
+		base := database.DocumentVerification{UserID: u.UserID, UserName: u.Name, NIK: u.NIK, VehicleID: &vehicleID, PlateNumber: v.PlateNumber}
+		stnk, kk := base, base
+		stnk.DocumentType, stnk.Status, stnk.Note, stnk.VerifiedBy, stnk.VerifiedAt =
+			database.DocumentSTNK, v.VerificationStatus, v.VerificationNote, v.VerifiedBy, v.VerifiedAt
+		kk.DocumentType, kk.Status, kk.Note, kk.VerifiedBy, kk.VerifiedAt =
+			database.DocumentKK, v.KKVerificationStatus, v.KKVerificationNote, v.KKVerifiedBy, v.KKVerifiedAt
+		add(stnk, nullInt64Ptr(v.STNKImageID))
+		add(kk, nullInt64Ptr(v.KKImageID))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].SubmittedAt.Equal(list[j].SubmittedAt) {
+			return list[i].SubmittedAt.Before(list[j].SubmittedAt)
+		}
+		return list[i].ImageID < list[j].ImageID
+	})
+	return list
+}
@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+type erasureRequestRepo struct{ s *Store }
+
+func (r erasureRequestRepo) Create(ctx context.Context, e *database.ErasureRequest) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.users[e.UserID]; !ok {
+		return errors.New("pq: insert or update on table \"erasure_requests\" violates foreign key constraint")
+	}
+	for _, existing := range r.s.st.erasureRequests {
+		if existing.UserID == e.UserID && existing.Status == database.ErasureStatusPending {
+			return errDuplicateKey
+		}
+	}
+	if e.RequestedAt.IsZero() {
+		e.RequestedAt = time.Now()
+	}
+	e.Status = database.ErasureStatusPending
+	r.s.st.nextErasureRequestID++
+	e.RequestID = r.s.st.nextErasureRequestID
+	r.s.st.erasureRequests[e.RequestID] = *e
+	return nil
+}
+
+func (r erasureRequestRepo) GetByID(ctx context.Context, id int64) (*database.ErasureRequest, error) {
+	defer r.s.lock()()
+	e, ok := r.s.st.erasureRequests[id]
+	if !ok {
+		return nil, database.ErrErasureRequestNotFound
+	}
+	return &e, nil
+}
+
+func (r erasureRequestRepo) GetLatestByUserID(ctx context.Context, userID string) (*database.ErasureRequest, error) {
+	defer r.s.lock()()
+	var latest *database.ErasureRequest
+	for _, e := range r.s.st.erasureRequests {
+		if e.UserID != userID {
+			continue
+		}
+		if latest == nil || e.RequestedAt.After(latest.RequestedAt) ||
+			e.RequestedAt.Equal(latest.RequestedAt) && e.RequestID > latest.RequestID {
+			e := e
+			latest = &e
+		}
+	}
+	if latest == nil {
+		return nil, database.ErrErasureRequestNotFound
+	}
+	return latest, nil
+}
+
+func (r erasureRequestRepo) List(ctx context.Context, status string) ([]database.ErasureRequest, error) {
+	defer r.s.lock()()
+	var list []database.ErasureRequest
+	for _, e := range r.s.st.erasureRequests {
+		if status == "" || e.Status == status {
+			list = append(list, e)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].RequestedAt.Equal(list[j].RequestedAt) {
+			return list[i].RequestedAt.Before(list[j].RequestedAt)
+		}
+		return list[i].RequestID < list[j].RequestID
+	})
+	return list, nil
+}
+
+func (r erasureRequestRepo) Decide(ctx context.Context, id int64, status, decidedBy, note string, at time.Time) error {
+	defer r.s.lock()()
+	e, ok := r.s.st.erasureRequests[id]
+	if !ok || e.Status != database.ErasureStatusPending {
+		return database.ErrErasureRequestDecided
+	}
+	e.Status = status
+	e.DecidedBy = &decidedBy
+	e.DecidedAt = &at
+	e.DecisionNote = note
+	r.s.st.erasureRequests[id] = e
+	return nil
+}
+
+func (r userRepo) Anonymize(ctx context.Context, userID string) ([]int64, error) {
+	defer r.s.lock()()
+	st := &r.s.st
+	u, ok := st.user(userID)
+	if !ok {
+		return nil, database.ErrUserNotFound
+	}
+	images := appendImagePtr(nil, u.KTPImageID)
+	u.Name = database.AnonymizedUserName
+	u.Email = "erased-" + userID + "@jaga.invalid"
+	u.Phone, u.Password, u.NIK, u.NIKHash = "", "", "", ""
+	u.KTPImageID = nil
+	u.EmailVerifiedAt = nil
+	st.users[userID] = u
+
+	for _, id := range sortedKeys(st.vehicles, func(a, b int64) bool { return a < b }) {
+		v := st.vehicles[id]
+		if v.UserID != userID {
+			continue
+		}
+		images = appendImageID(images, v.STNKImageID)
+		images = appendImageID(images, v.KKImageID)
+		v.PlateNumber = ""
+		v.DistinguishingMarks = nil
+		v.STNKImageID.Valid, v.KKImageID.Valid = false, false
+		st.vehicles[id] = v
+	}
+	now := time.Now()
+	for id, t := range st.transfers {
+		if t.Status == database.TransferPending && (t.FromUserID == userID || t.ToUserID == userID) {
+			t.Status, t.DecidedAt = database.TransferCancelled, &now
+			st.transfers[id] = t
+		}
+	}
+	for _, id := range sortedKeys(st.lostReports, func(a, b int) bool { return a < b }) {
+		lr := st.lostReports[id]
+		if lr.UserID != userID {
+			continue
+		}
+		images = appendImagePtr(images, lr.MotorEvidenceImageID)
+		images = appendImagePtr(images, lr.PersonEvidenceImageID)
+		lr.Address = ""
+		lr.Latitude = roundCoordinate(lr.Latitude)
+		lr.Longitude = roundCoordinate(lr.Longitude)
+		lr.MotorEvidenceImageID, lr.PersonEvidenceImageID = nil, nil
+		st.lostReports[id] = lr
+	}
+	st.removeCredentials(userID)
+	return images, nil
+}
+
+// roundCoordinate meniru ROUND(x::numeric, 2) pada AnonymizeUserTx.
+func roundCoordinate(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	rounded := math.Round(*v*100) / 100
+	return &rounded
+}
+
+func sortedKeys[K comparable, V any](m map[K]V, less func(a, b K) bool) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	return keys
+}
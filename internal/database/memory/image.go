@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+type imageRepo struct{ s *Store }
+
+func (r imageRepo) Create(ctx context.Context, img *database.Image) error {
+	defer r.s.lock()()
+	r.s.st.nextImageID++
+	img.ImageID = r.s.st.nextImageID
+	img.UploadedAt = time.Now()
+	r.s.st.images[img.ImageID] = *img
+	return nil
+}
+
+func (r imageRepo) GetByID(ctx context.Context, id int64) (*database.Image, error) {
+	defer r.s.lock()()
+	img, ok := r.s.st.images[id]
+	if !ok {
+		return nil, database.ErrImageNotFound
+	}
+	return &img, nil
+}
+
+func (r imageRepo) GetStoragePath(ctx context.Context, id int64) (string, error) {
+	defer r.s.lock()()
+	img, ok := r.s.st.images[id]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return img.StoragePath, nil
+}
+
+func (r imageRepo) UpdateMetadata(ctx context.Context, id int64, newFilenameOriginal string) error {
+	defer r.s.lock()()
+	img, ok := r.s.st.images[id]
+	if !ok {
+		return errors.New("no image record updated in tx or image not found")
+	}
+	img.FilenameOriginal = newFilenameOriginal
+	r.s.st.images[id] = img
+	return nil
+}
+
+func (r imageRepo) Delete(ctx context.Context, id int64) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.images[id]; !ok {
+		return errors.New("no image record deleted in tx or image not found")
+	}
+	delete(r.s.st.images, id)
+	delete(r.s.st.documentOCR, id)
+	return nil
+}
+
+func (r imageRepo) GetStoragePathAndDelete(ctx context.Context, id int64) (string, error) {
+	defer r.s.lock()()
+	img, ok := r.s.st.images[id]
+	if !ok {
+		return "", nil
+	}
+	delete(r.s.st.images, id)
+	delete(r.s.st.documentOCR, id)
+	return img.StoragePath, nil
+}
@@ -0,0 +1,207 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+type lostReportRepo struct{ s *Store }
+
+func (r lostReportRepo) Create(ctx context.Context, lr *database.LostReport) error {
+	defer r.s.lock()()
+	r.s.st.nextLostReportID++
+	lr.LostID = r.s.st.nextLostReportID
+	r.s.st.lostReports[lr.LostID] = *lr
+	times := reportTimes{createdAt: time.Now()}
+	r.s.st.lostReportTimes[lr.LostID] = times.withStatus(lr.Status)
+	return nil
+}
+
+type reportTimes struct {
+	createdAt time.Time
+	foundAt   *time.Time
+}
+
+// withStatus meniru CASE found_at pada CreateLostReportTx dan
+// UpdateLostReport.
+func (t reportTimes) withStatus(status string) reportTimes {
+	if status != database.StatusLostReportSudahDitemukan {
+		t.foundAt = nil
+	} else if t.foundAt == nil {
+		now := time.Now()
+		t.foundAt = &now
+	}
+	return t
+}
+
+func (r lostReportRepo) GetByID(ctx context.Context, id int) (*database.LostReport, error) {
+	defer r.s.lock()()
+	lr, ok := r.s.st.lostReport(id)
+	if !ok {
+		return nil, database.ErrLostReportNotFound
+	}
+	return &lr, nil
+}
+
+func (r lostReportRepo) GetWithVehicleInfoByID(ctx context.Context, id int) (*database.LostReportWithVehicleInfo, error) {
+	defer r.s.lock()()
+	lr, ok := r.s.st.lostReport(id)
+	if !ok {
+		return nil, database.ErrLostReportNotFound
+	}
+	info := r.s.withVehicleInfo(lr)
+	return &info, nil
+}
+
+func (r lostReportRepo) ListWithVehicleInfo(ctx context.Context, statusFilter string) ([]database.LostReportWithVehicleInfo, error) {
+	defer r.s.lock()()
+	var list []database.LostReportWithVehicleInfo
+	for _, lr := range r.s.lostReportsByTimestampDesc() {
+		if statusFilter == "" || lr.Status == statusFilter {
+			list = append(list, r.s.withVehicleInfo(lr))
+		}
+	}
+	return list, nil
+}
+
+func (r lostReportRepo) ListWithVehicleInfoByUserID(ctx context.Context, userID string) ([]database.LostReportWithVehicleInfo, error) {
+	defer r.s.lock()()
+	var list []database.LostReportWithVehicleInfo
+	for _, lr := range r.s.lostReportsByTimestampDesc() {
+		if lr.UserID == userID {
+			list = append(list, r.s.withVehicleInfo(lr))
+		}
+	}
+	return list, nil
+}
+
+func (r lostReportRepo) CountByStatus(ctx context.Context) (map[string]int, error) {
+	defer r.s.lock()()
+	counts := make(map[string]int)
+	for id, lr := range r.s.st.lostReports {
+		if _, deleted := r.s.st.deletedLostReports[id]; !deleted {
+			counts[lr.Status]++
+		}
+	}
+	return counts, nil
+}
+
+func (r lostReportRepo) ListLocations(ctx context.Context, from, to time.Time) ([]database.LostReportLocation, error) {
+	defer r.s.lock()()
+	var locations []database.LostReportLocation
+	for _, id := range sortedKeys(r.s.st.lostReports, func(a, b int) bool { return a < b }) {
+		lr := r.s.st.lostReports[id]
+		if _, deleted := r.s.st.deletedLostReports[id]; deleted || lr.Latitude == nil || lr.Longitude == nil ||
+			lr.Timestamp.Before(from) || !lr.Timestamp.Before(to) {
+			continue
+		}
+		locations = append(locations, database.LostReportLocation{LostID: id, Latitude: *lr.Latitude, Longitude: *lr.Longitude, Timestamp: lr.Timestamp})
+	}
+	return locations, nil
+}
+
+func (r lostReportRepo) CountOpenByVehicleID(ctx context.Context, vehicleID int64) (int, error) {
+	defer r.s.lock()()
+	n := 0
+	for id, lr := range r.s.st.lostReports {
+		if _, deleted := r.s.st.deletedLostReports[id]; deleted || int64(lr.VehicleID) != vehicleID {
+			continue
+		}
+		if lr.Status == database.StatusLostReportBelumDiproses || lr.Status == database.StatusLostReportSedangDiproses {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r lostReportRepo) Update(ctx context.Context, id int, lr *database.LostReport) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.lostReport(id); !ok {
+		return errors.New("no lost_report record updated or no changes made")
+	}
+	updated := *lr
+	updated.LostID = id
+	r.s.st.lostReports[id] = updated
+	r.s.st.lostReportTimes[id] = r.s.st.lostReportTimes[id].withStatus(updated.Status)
+	return nil
+}
+
+func (r lostReportRepo) Delete(ctx context.Context, id int) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.lostReport(id); !ok {
+		return errors.New("no lost_report record deleted")
+	}
+	r.s.st.deletedLostReports[id] = time.Now()
+	return nil
+}
+
+func (r lostReportRepo) Restore(ctx context.Context, id int) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.deletedLostReports[id]; !ok {
+		return database.ErrLostReportNotFound
+	}
+	delete(r.s.st.deletedLostReports, id)
+	return nil
+}
+
+func (r lostReportRepo) PurgeDeleted(ctx context.Context, before time.Time) (*database.PurgedRows, error) {
+	defer r.s.lock()()
+	p := &database.PurgedRows{}
+	for _, id := range expiredKeys(r.s.st.deletedLostReports, before, func(a, b int) bool { return a < b }) {
+		lr := r.s.st.lostReports[id]
+		p.Count++
+		p.ImageIDs = appendImagePtr(appendImagePtr(p.ImageIDs, lr.MotorEvidenceImageID), lr.PersonEvidenceImageID)
+		r.s.st.purgeLostReport(id)
+	}
+	return p, nil
+}
+
+// lostReport mengembalikan laporan yang tidak sedang di-soft delete.
+func (st *state) lostReport(id int) (database.LostReport, bool) {
+	lr, ok := st.lostReports[id]
+	if _, deleted := st.deletedLostReports[id]; deleted {
+		return database.LostReport{}, false
+	}
+	return lr, ok
+}
+
+func (s *Store) withVehicleInfo(lr database.LostReport) database.LostReportWithVehicleInfo {
+	info := database.LostReportWithVehicleInfo{
+		LostID:                lr.LostID,
+		UserID:                lr.UserID,
+		Timestamp:             lr.Timestamp,
+		VehicleID:             lr.VehicleID,
+		Address:               lr.Address,
+		Latitude:              lr.Latitude,
+		Longitude:             lr.Longitude,
+		Status:                lr.Status,
+		MotorEvidenceImageID:  lr.MotorEvidenceImageID,
+		PersonEvidenceImageID: lr.PersonEvidenceImageID,
+	}
+	if v, ok := s.st.vehicles[int64(lr.VehicleID)]; ok {
+		info.VehicleName = sql.NullString{String: v.VehicleName, Valid: true}
+		info.PlateNumber = sql.NullString{String: v.PlateNumber, Valid: true}
+	}
+	return info
+}
+
+func (s *Store) lostReportsByTimestampDesc() []database.LostReport {
+	list := make([]database.LostReport, 0, len(s.st.lostReports))
+	for id, lr := range s.st.lostReports {
+		if _, deleted := s.st.deletedLostReports[id]; !deleted {
+			list = append(list, lr)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Timestamp.Equal(list[j].Timestamp) {
+			return list[i].LostID > list[j].LostID
+		}
+		return list[i].Timestamp.After(list[j].Timestamp)
+	})
+	return list
+}
@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+)
+
+// Fungsi purge* menghapus baris secara permanen dan meniru ON DELETE
+// CASCADE pada skema Postgres.
+
+func (st *state) purgeUser(userID string) {
+	delete(st.users, userID)
+	delete(st.deletedUsers, userID)
+	st.removeCredentials(userID)
+	for id, e := range st.erasureRequests {
+		if e.UserID == userID {
+			delete(st.erasureRequests, id)
+		}
+	}
+	for id, v := range st.vehicles {
+		if v.UserID == userID {
+			st.purgeVehicle(id)
+		}
+	}
+	for id, lr := range st.lostReports {
+		if lr.UserID == userID {
+			st.purgeLostReport(id)
+		}
+	}
+	for id, t := range st.transfers {
+		if t.FromUserID == userID || t.ToUserID == userID {
+			delete(st.transfers, id)
+		}
+	}
+}
+
+// removeCredentials menghapus hak admin, 2FA, token dan API key user.
+func (st *state) removeCredentials(userID string) {
+	delete(st.admins, userID)
+	delete(st.adminMFA, userID)
+	st.removeRecoveryCodes(userID)
+	for hash, t := range st.userTokens {
+		if t.UserID == userID {
+			delete(st.userTokens, hash)
+		}
+	}
+	keys := st.apiKeys[:0]
+	for _, k := range st.apiKeys {
+		if k.userID != userID {
+			keys = append(keys, k)
+		}
+	}
+	st.apiKeys = keys
+}
+
+func (st *state) purgeVehicle(id int64) {
+	delete(st.vehicles, id)
+	delete(st.deletedVehicles, id)
+	for transferID, t := range st.transfers {
+		if t.VehicleID == id {
+			delete(st.transfers, transferID)
+		}
+	}
+	for lostID, lr := range st.lostReports {
+		if int64(lr.VehicleID) == id {
+			st.purgeLostReport(lostID)
+		}
+	}
+}
+
+func (st *state) purgeLostReport(id int) {
+	delete(st.lostReports, id)
+	delete(st.deletedLostReports, id)
+	delete(st.lostReportTimes, id)
+	delete(st.reportSearch, id)
+	for suspectID, s := range st.suspects {
+		if s.LostID == int64(id) {
+			delete(st.suspects, suspectID)
+		}
+	}
+	for runID, a := range st.analysisRuns {
+		if a.LostID == id {
+			delete(st.analysisRuns, runID)
+		}
+	}
+}
+
+func (st *state) purgeCamera(id int64) {
+	delete(st.cameras, id)
+	delete(st.deletedCameras, id)
+	for detectedID, d := range st.detected {
+		if int64(d.CameraID) != id {
+			continue
+		}
+		delete(st.detected, detectedID)
+		for suspectID, s := range st.suspects {
+			if s.DetectedID == int64(detectedID) {
+				delete(st.suspects, suspectID)
+			}
+		}
+	}
+}
+
+// expiredKeys mengembalikan kunci yang di-soft delete sebelum before,
+// terurut agar hasil purge deterministik.
+func expiredKeys[K comparable](deleted map[K]time.Time, before time.Time, less func(a, b K) bool) []K {
+	var keys []K
+	for k, at := range deleted {
+		if at.Before(before) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	return keys
+}
+
+func appendImageID(ids []int64, id sql.NullInt64) []int64 {
+	if id.Valid {
+		return append(ids, id.Int64)
+	}
+	return ids
+}
+
+func appendImagePtr(ids []int64, id *int64) []int64 {
+	if id != nil {
+		return append(ids, *id)
+	}
+	return ids
+}
@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+type searchRepo struct{ s *Store }
+
+func (r searchRepo) GetLimits(ctx context.Context) (*database.AnalysisLimits, error) {
+	defer r.s.lock()()
+	if r.s.st.analysisLimits == nil {
+		l := database.DefaultAnalysisLimits()
+		return &l, nil
+	}
+	l := *r.s.st.analysisLimits
+	return &l, nil
+}
+
+func (r searchRepo) UpdateLimits(ctx context.Context, l *database.AnalysisLimits) error {
+	defer r.s.lock()()
+	l.UpdatedAt = time.Now()
+	stored := *l
+	r.s.st.analysisLimits = &stored
+	return nil
+}
+
+func (r searchRepo) GetByLostReportID(ctx context.Context, lostID int) (*database.LostReportSearch, error) {
+	defer r.s.lock()()
+	s, ok := r.s.st.reportSearch[lostID]
+	if !ok {
+		return nil, database.ErrSearchParamsNotFound
+	}
+	return copySearch(s), nil
+}
+
+func (r searchRepo) Save(ctx context.Context, s *database.LostReportSearch) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.lostReports[s.LostID]; !ok {
+		return errors.New("pq: insert or update on table \"lost_report_search\" violates foreign key constraint")
+	}
+	if s.UpdatedAt.IsZero() {
+		s.UpdatedAt = time.Now()
+	}
+	r.s.st.reportSearch[s.LostID] = *copySearch(*s)
+	return nil
+}
+
+func (r searchRepo) CountDetectionsByCamera(ctx context.Context, cameraIDs []int64, from, to time.Time) (map[int64]int, error) {
+	defer r.s.lock()()
+	wanted := make(map[int64]bool, len(cameraIDs))
+	for _, id := range cameraIDs {
+		wanted[id] = true
+	}
+	counts := make(map[int64]int)
+	for _, d := range r.s.st.detected {
+		if wanted[int64(d.CameraID)] && !d.Timestamp.Before(from) && d.Timestamp.Before(to) {
+			counts[int64(d.CameraID)]++
+		}
+	}
+	return counts, nil
+}
+
+// copySearch mencegah slice di state ikut berubah lewat pointer milik
+// pemanggil.
+func copySearch(s database.LostReportSearch) *database.LostReportSearch {
+	s.CameraIDs = append([]int64(nil), s.CameraIDs...)
+	s.Zones = append([]string(nil), s.Zones...)
+	return &s
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+type statsRepo struct{ s *Store }
+
+func (r statsRepo) Dashboard(ctx context.Context, f database.StatsFilter) (*database.DashboardStats, error) {
+	defer r.s.lock()()
+	st := &r.s.st
+	inRange := func(t time.Time) bool { return !t.Before(f.From) && t.Before(f.To) }
+	stats := &database.DashboardStats{}
+
+	// Laporan yang punya suspect dari kamera di zona filter.
+	inZone := map[int]bool{}
+	for _, s := range st.suspects {
+		if c, ok := st.suspectCamera(s); ok && c.Zone == f.Zone {
+			inZone[int(s.LostID)] = true
+		}
+	}
+	reportMatches := func(id int) bool {
+		_, deleted := st.deletedLostReports[id]
+		return !deleted && (f.Zone == "" || inZone[id])
+	}
+
+	byStart := map[time.Time]int{}
+	locations := map[[2]float64]int{}
+	var foundSeconds float64
+	for _, id := range sortedKeys(st.lostReports, func(a, b int) bool { return a < b }) {
+		if !reportMatches(id) {
+			continue
+		}
+		lr, times := st.lostReports[id], st.lostReportTimes[id]
+		if inRange(times.createdAt) {
+			start := database.TruncateBucket(times.createdAt, f.Bucket)
+			i, ok := byStart[start]
+			if !ok {
+				i = len(stats.LostReportsByStatus)
+				byStart[start] = i
+				stats.LostReportsByStatus = append(stats.LostReportsByStatus, database.StatusBucket{Start: start, Counts: map[string]int{}})
+			}
+			stats.LostReportsByStatus[i].Counts[lr.Status]++
+		}
+		if times.foundAt != nil && inRange(*times.foundAt) {
+			stats.TimeToFound.FoundReports++
+			foundSeconds += times.foundAt.Sub(times.createdAt).Seconds()
+		}
+		if lr.Latitude != nil && lr.Longitude != nil && inRange(lr.Timestamp) {
+			locations[[2]float64{*roundCoordinate(lr.Latitude), *roundCoordinate(lr.Longitude)}]++
+		}
+	}
+	sort.Slice(stats.LostReportsByStatus, func(i, j int) bool {
+		return stats.LostReportsByStatus[i].Start.Before(stats.LostReportsByStatus[j].Start)
+	})
+	if n := stats.TimeToFound.FoundReports; n > 0 {
+		hours := foundSeconds / float64(n) / 3600
+		stats.TimeToFound.MeanHours = &hours
+	}
+	for loc, n := range locations {
+		stats.TopTheftLocations = append(stats.TopTheftLocations, database.LocationCount{Latitude: loc[0], Longitude: loc[1], Count: n})
+	}
+	database.SortLocationCounts(stats.TopTheftLocations)
+	if len(stats.TopTheftLocations) > f.TopLocations {
+		stats.TopTheftLocations = stats.TopTheftLocations[:f.TopLocations]
+	}
+
+	type cameraHour struct {
+		camera int64
+		hour   time.Time
+	}
+	perHour := map[cameraHour]int{}
+	for _, d := range st.detected {
+		c, ok := st.cameras[int64(d.CameraID)]
+		if !ok || !inRange(d.Timestamp) || f.Zone != "" && c.Zone != f.Zone {
+			continue
+		}
+		perHour[cameraHour{c.CameraID, d.Timestamp.UTC().Truncate(time.Hour)}]++
+	}
+	for k, n := range perHour {
+		c := st.cameras[k.camera]
+		stats.DetectionsPerCamera = append(stats.DetectionsPerCamera, database.CameraHourCount{
+			CameraID: c.CameraID, CameraName: c.Name, Zone: c.Zone, Hour: k.hour, Count: n,
+		})
+	}
+	sort.Slice(stats.DetectionsPerCamera, func(i, j int) bool {
+		a, b := stats.DetectionsPerCamera[i], stats.DetectionsPerCamera[j]
+		if !a.Hour.Equal(b.Hour) {
+			return a.Hour.Before(b.Hour)
+		}
+		return a.CameraID < b.CameraID
+	})
+
+	perReport := map[int64]int{}
+	var histogram [database.StatsScoreBuckets]int
+	suspects, maxPerReport := 0, 0
+	for _, s := range st.suspects {
+		c, ok := st.suspectCamera(s)
+		if _, deleted := st.deletedLostReports[int(s.LostID)]; deleted || !ok || !inRange(s.CreatedAt) || f.Zone != "" && c.Zone != f.Zone {
+			continue
+		}
+		perReport[s.LostID]++
+		suspects++
+		if perReport[s.LostID] > maxPerReport {
+			maxPerReport = perReport[s.LostID]
+		}
+		histogram[database.ScoreBucketIndex(s.FinalScore)]++
+	}
+	stats.Suspects = database.NewSuspectStats(len(perReport), suspects, maxPerReport, histogram)
+
+	newUsers := map[time.Time]int{}
+	for id, u := range st.users {
+		if _, deleted := st.deletedUsers[id]; !deleted && inRange(u.CreatedAt) {
+			newUsers[database.TruncateBucket(u.CreatedAt, f.Bucket)]++
+		}
+	}
+	for _, start := range sortedKeys(newUsers, func(a, b time.Time) bool { return a.Before(b) }) {
+		stats.NewUsers = append(stats.NewUsers, database.CountBucket{Start: start, Count: newUsers[start]})
+	}
+	return stats, nil
+}
+
+// suspectCamera mengembalikan kamera yang mendeteksi suspect, termasuk
+// kamera yang sedang di-soft delete.
+func (st *state) suspectCamera(s database.Suspect) (database.Camera, bool) {
+	d, ok := st.detected[int(s.DetectedID)]
+	if !ok {
+		return database.Camera{}, false
+	}
+	c, ok := st.cameras[int64(d.CameraID)]
+	return c, ok
+}
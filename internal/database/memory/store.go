@@ -0,0 +1,233 @@
+// Package memory menyediakan implementasi database.Store yang sepenuhnya
+// berada di memori. Dipakai oleh test handler agar tidak membutuhkan
+// Postgres; semantik error dibuat sama dengan implementasi Postgres.
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var errDuplicateKey = errors.New("pq: duplicate key value violates unique constraint")
+
+type apiKey struct {
+	id      int64
+	userID  string
+	keyHash []byte
+}
+
+// state berisi seluruh "tabel". Disalin utuh saat BeginTx agar Rollback
+// bisa mengembalikan kondisi sebelum transaksi.
+type state struct {
+	users       map[string]database.User
+	userTokens  map[string]database.UserToken
+	admins      map[string]database.Admin
+	adminMFA    map[string]database.AdminMFA
+	apiKeys     []apiKey
+	vehicles    map[int64]database.Vehicle
+	lostReports map[int]database.LostReport
+	detected    map[int]database.Detected
+	suspects    map[int64]database.Suspect
+	images      map[int64]database.Image
+	cameras     map[int64]database.Camera
+
+	// deleted* mencatat waktu soft delete. Barisnya tetap ada di map utama
+	// tetapi tidak terlihat oleh query biasa, sama seperti deleted_at.
+	deletedUsers       map[string]time.Time
+	deletedVehicles    map[int64]time.Time
+	deletedLostReports map[int]time.Time
+	deletedCameras     map[int64]time.Time
+
+	// mfaPolicy bernilai nol (semua admin wajib 2FA), sama dengan default
+	// migrasi.
+	mfaPolicy     database.MFAPolicy
+	recoveryCodes []recoveryCode
+	auditLog      []database.AuditEntry
+	suspectLabels []database.SuspectLabel
+
+	erasureRequests map[int64]database.ErasureRequest
+	analysisRuns    map[int64]database.AnalysisRun
+	reportSearch    map[int]database.LostReportSearch
+	transfers       map[int64]database.VehicleTransfer
+	documentOCR     map[int64]database.DocumentOCR
+	// analysisLimits nil berarti batas default migrasi.
+	analysisLimits *database.AnalysisLimits
+
+	// lostReportTimes meniru kolom created_at dan found_at lost_report yang
+	// tidak ada di struct LostReport.
+	lostReportTimes map[int]reportTimes
+
+	nextVehicleID    int64
+	nextLostReportID int
+	nextDetectedID   int
+	nextSuspectID    int64
+	nextImageID      int64
+	nextCameraID     int64
+
+	nextErasureRequestID int64
+	nextAnalysisRunID    int64
+	nextTransferID       int64
+}
+
+func newState() state {
+	return state{
+		users:       make(map[string]database.User),
+		userTokens:  make(map[string]database.UserToken),
+		admins:      make(map[string]database.Admin),
+		adminMFA:    make(map[string]database.AdminMFA),
+		vehicles:    make(map[int64]database.Vehicle),
+		lostReports: make(map[int]database.LostReport),
+		detected:    make(map[int]database.Detected),
+		suspects:    make(map[int64]database.Suspect),
+		images:      make(map[int64]database.Image),
+		cameras:     make(map[int64]database.Camera),
+
+		deletedUsers:       make(map[string]time.Time),
+		deletedVehicles:    make(map[int64]time.Time),
+		deletedLostReports: make(map[int]time.Time),
+		deletedCameras:     make(map[int64]time.Time),
+
+		erasureRequests: make(map[int64]database.ErasureRequest),
+		analysisRuns:    make(map[int64]database.AnalysisRun),
+		reportSearch:    make(map[int]database.LostReportSearch),
+		transfers:       make(map[int64]database.VehicleTransfer),
+		documentOCR:     make(map[int64]database.DocumentOCR),
+		lostReportTimes: make(map[int]reportTimes),
+	}
+}
+
+func (s state) clone() state {
+	c := s
+	c.users = cloneMap(s.users)
+	c.userTokens = cloneMap(s.userTokens)
+	c.admins = cloneMap(s.admins)
+	c.adminMFA = cloneMap(s.adminMFA)
+	c.recoveryCodes = append([]recoveryCode(nil), s.recoveryCodes...)
+	c.auditLog = append([]database.AuditEntry(nil), s.auditLog...)
+	c.suspectLabels = append([]database.SuspectLabel(nil), s.suspectLabels...)
+	c.apiKeys = append([]apiKey(nil), s.apiKeys...)
+	c.vehicles = cloneMap(s.vehicles)
+	c.lostReports = cloneMap(s.lostReports)
+	c.detected = cloneMap(s.detected)
+	c.suspects = cloneMap(s.suspects)
+	c.images = cloneMap(s.images)
+	c.cameras = cloneMap(s.cameras)
+	c.deletedUsers = cloneMap(s.deletedUsers)
+	c.deletedVehicles = cloneMap(s.deletedVehicles)
+	c.deletedLostReports = cloneMap(s.deletedLostReports)
+	c.deletedCameras = cloneMap(s.deletedCameras)
+	c.erasureRequests = cloneMap(s.erasureRequests)
+	c.analysisRuns = cloneMap(s.analysisRuns)
+	c.reportSearch = cloneMap(s.reportSearch)
+	c.transfers = cloneMap(s.transfers)
+	c.documentOCR = cloneMap(s.documentOCR)
+	c.lostReportTimes = cloneMap(s.lostReportTimes)
+	return c
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+var _ database.Store = (*Store)(nil)
+
+// Store adalah database.Store in-memory. Nilai nol tidak siap pakai;
+// gunakan NewStore.
+type Store struct {
+	mu sync.Mutex
+	st state
+}
+
+func NewStore() *Store {
+	return &Store{st: newState()}
+}
+
+func (s *Store) Repos() database.Repositories {
+	return database.Repositories{
+		Users:         userRepo{s},
+		UserTokens:    userTokenRepo{s},
+		Admins:        adminRepo{s},
+		AdminMFA:      adminMFARepo{s},
+		AuditLog:      auditLogRepo{s},
+		Erasures:      erasureRequestRepo{s},
+		Vehicles:      vehicleRepo{s},
+		Transfers:     vehicleTransferRepo{s},
+		Verifications: verificationRepo{s},
+		DocumentOCR:   documentOCRRepo{s},
+		LostReports:   lostReportRepo{s},
+		Detected:      detectedRepo{s},
+		Suspects:      suspectRepo{s},
+		SuspectLabels: suspectLabelRepo{s},
+		AnalysisRuns:  analysisRunRepo{s},
+		Search:        searchRepo{s},
+		Images:        imageRepo{s},
+		Cameras:       cameraRepo{s},
+		Stats:         statsRepo{s},
+	}
+}
+
+// BeginTx mengambil snapshot state. Perubahan di dalam transaksi langsung
+// terlihat oleh pembaca lain (tidak ada isolasi), cukup untuk kebutuhan test.
+func (s *Store) BeginTx(ctx context.Context) (database.Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &tx{store: s, snapshot: s.st.clone()}, nil
+}
+
+// AddAPIKey mendaftarkan API key (disimpan sebagai hash bcrypt) untuk userID.
+func (s *Store) AddAPIKey(userID, key string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(key), bcrypt.MinCost)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.st.apiKeys = append(s.st.apiKeys, apiKey{id: int64(len(s.st.apiKeys) + 1), userID: userID, keyHash: hash})
+	return nil
+}
+
+func (s *Store) lock() func() {
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+type tx struct {
+	store    *Store
+	snapshot state
+	done     bool
+}
+
+func (t *tx) Repos() database.Repositories { return t.store.Repos() }
+
+func (t *tx) Commit() error {
+	defer t.store.lock()()
+	if t.done {
+		return errTxDone
+	}
+	t.done = true
+	return nil
+}
+
+func (t *tx) Rollback() error {
+	defer t.store.lock()()
+	if t.done {
+		return errTxDone
+	}
+	t.done = true
+	t.store.st = t.snapshot
+	return nil
+}
+
+var errTxDone = errors.New("sql: transaction has already been committed or rolled back")
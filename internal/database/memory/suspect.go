@@ -0,0 +1,214 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+type suspectRepo struct{ s *Store }
+
+func (r suspectRepo) Create(ctx context.Context, sp *database.Suspect) error {
+	defer r.s.lock()()
+	r.s.st.nextSuspectID++
+	sp.SuspectID = r.s.st.nextSuspectID
+	sp.ReviewStatus = database.SuspectReviewPending
+	sp.ReviewedBy, sp.ReviewedAt, sp.ReviewComment = nil, nil, ""
+	sp.OwnerFeedback, sp.OwnerFeedbackAt = nil, nil
+	r.s.st.suspects[sp.SuspectID] = *sp
+	return nil
+}
+
+func (r suspectRepo) GetByID(ctx context.Context, id int64) (*database.Suspect, error) {
+	defer r.s.lock()()
+	sp, ok := r.s.st.suspects[id]
+	if !ok {
+		return nil, database.ErrSuspectNotFound
+	}
+	return &sp, nil
+}
+
+func (r suspectRepo) List(ctx context.Context) ([]database.Suspect, error) {
+	defer r.s.lock()()
+	var list []database.Suspect
+	for _, sp := range r.s.st.suspects {
+		list = append(list, sp)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list, nil
+}
+
+// ListResultsByLostReportID meniru JOIN pada GetSuspectsByLostReportID:
+// satu baris per suspect, urut skor lalu suspect_id.
+func (r suspectRepo) ListResultsByLostReportID(ctx context.Context, lostReportID int, f database.SuspectResultFilter) ([]database.SuspectResult, error) {
+	defer r.s.lock()()
+	return paginate(r.results(lostReportID, f), f.Limit, f.Offset), nil
+}
+
+// paginate meniru LIMIT/OFFSET; limit 0 berarti tanpa batas.
+func paginate[T any](list []T, limit, offset int) []T {
+	if offset >= len(list) {
+		return nil
+	}
+	list = list[offset:]
+	if limit > 0 && limit < len(list) {
+		list = list[:limit]
+	}
+	return list
+}
+
+func (r suspectRepo) CountResultsByLostReportID(ctx context.Context, lostReportID int, f database.SuspectResultFilter) (int, error) {
+	defer r.s.lock()()
+	return len(r.results(lostReportID, f)), nil
+}
+
+func (r suspectRepo) results(lostReportID int, f database.SuspectResultFilter) []database.SuspectResult {
+	imagePath := func(id sql.NullInt64) sql.NullString {
+		if !id.Valid {
+			return sql.NullString{}
+		}
+		img, ok := r.s.st.images[id.Int64]
+		if !ok {
+			return sql.NullString{}
+		}
+		return sql.NullString{String: img.StoragePath, Valid: true}
+	}
+	var results []database.SuspectResult
+	for _, sp := range r.s.st.suspects {
+		if sp.LostID != int64(lostReportID) || sp.FinalScore < f.MinScore {
+			continue
+		}
+		d, ok := r.s.st.detected[int(sp.DetectedID)]
+		if !ok {
+			continue
+		}
+		cam, ok := r.s.st.cameras[int64(d.CameraID)]
+		if !ok {
+			continue
+		}
+		results = append(results, database.SuspectResult{
+			SuspectID:         sp.SuspectID,
+			DetectedID:        sp.DetectedID,
+			PersonScore:       sp.PersonScore,
+			MotorScore:        sp.MotorScore,
+			FinalScore:        sp.FinalScore,
+			DetectedTimestamp: d.Timestamp,
+			PersonImagePath:   imagePath(d.PersonImageID),
+			MotorImagePath:    imagePath(d.MotorcycleImageID),
+			CameraID:          cam.CameraID,
+			CameraName:        cam.Name,
+			CameraLatitude:    cam.Latitude,
+			CameraLongitude:   cam.Longitude,
+			ReviewStatus:      sp.ReviewStatus,
+			OwnerFeedback:     sp.OwnerFeedback,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].FinalScore != results[j].FinalScore {
+			return results[i].FinalScore > results[j].FinalScore
+		}
+		return results[i].SuspectID < results[j].SuspectID
+	})
+	return results
+}
+
+func (r suspectRepo) Update(ctx context.Context, id int64, sp *database.Suspect) error {
+	defer r.s.lock()()
+	existing, ok := r.s.st.suspects[id]
+	if !ok {
+		return errors.New("no suspect record updated or record not found")
+	}
+	existing.DetectedID = sp.DetectedID
+	existing.LostID = sp.LostID
+	existing.PersonScore = sp.PersonScore
+	existing.MotorScore = sp.MotorScore
+	existing.FinalScore = sp.FinalScore
+	r.s.st.suspects[id] = existing
+	return nil
+}
+
+func (r suspectRepo) Delete(ctx context.Context, id int64) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.suspects[id]; !ok {
+		return errors.New("no suspect record deleted or record not found")
+	}
+	delete(r.s.st.suspects, id)
+	return nil
+}
+
+func (r suspectRepo) Review(ctx context.Context, id int64, status, reviewerID, comment string, at time.Time) error {
+	defer r.s.lock()()
+	sp, ok := r.s.st.suspects[id]
+	if !ok {
+		return database.ErrSuspectNotFound
+	}
+	sp.ReviewStatus, sp.ReviewedBy, sp.ReviewedAt, sp.ReviewComment = status, &reviewerID, &at, comment
+	r.s.st.suspects[id] = sp
+	return nil
+}
+
+func (r suspectRepo) SetOwnerFeedback(ctx context.Context, id int64, feedback string, at time.Time) error {
+	defer r.s.lock()()
+	sp, ok := r.s.st.suspects[id]
+	if !ok {
+		return database.ErrSuspectNotFound
+	}
+	sp.OwnerFeedback, sp.OwnerFeedbackAt = &feedback, &at
+	r.s.st.suspects[id] = sp
+	return nil
+}
+
+type suspectLabelRepo struct{ s *Store }
+
+// Create meniru INSERT ... SELECT pada CreateSuspectLabel.
+func (r suspectLabelRepo) Create(ctx context.Context, l *database.SuspectLabel) error {
+	defer r.s.lock()()
+	sp, ok := r.s.st.suspects[l.SuspectID]
+	if !ok {
+		return database.ErrSuspectNotFound
+	}
+	d, ok := r.s.st.detected[int(sp.DetectedID)]
+	if !ok {
+		return database.ErrSuspectNotFound
+	}
+	if l.CreatedAt.IsZero() {
+		l.CreatedAt = time.Now()
+	}
+	l.LabelID = int64(len(r.s.st.suspectLabels) + 1)
+	l.LostID, l.DetectedID = sp.LostID, sp.DetectedID
+	l.PersonImageID, l.MotorcycleImageID = nullInt64Ptr(d.PersonImageID), nullInt64Ptr(d.MotorcycleImageID)
+	l.PersonScore, l.MotorScore, l.FinalScore = sp.PersonScore, sp.MotorScore, sp.FinalScore
+	l.PersonImagePath, l.MotorcycleImagePath = "", ""
+	r.s.st.suspectLabels = append(r.s.st.suspectLabels, *l)
+	return nil
+}
+
+func (r suspectLabelRepo) List(ctx context.Context, f database.SuspectLabelFilter) ([]database.SuspectLabel, error) {
+	defer r.s.lock()()
+	imagePath := func(id *int64) string {
+		if id == nil {
+			return ""
+		}
+		return r.s.st.images[*id].StoragePath
+	}
+	var labels []database.SuspectLabel
+	for _, l := range r.s.st.suspectLabels {
+		if f.Match(&l) {
+			l.PersonImagePath, l.MotorcycleImagePath = imagePath(l.PersonImageID), imagePath(l.MotorcycleImageID)
+			labels = append(labels, l)
+		}
+	}
+	return labels, nil
+}
+
+func nullInt64Ptr(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	n := v.Int64
+	return &n
+}
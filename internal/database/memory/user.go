@@ -0,0 +1,325 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type userRepo struct{ s *Store }
+
+func (r userRepo) Create(ctx context.Context, u *database.User) error {
+	defer r.s.lock()()
+	if _, exists := r.s.st.users[u.UserID]; exists {
+		return errDuplicateKey
+	}
+	for _, existing := range r.s.st.users {
+		if existing.Email == u.Email {
+			return errDuplicateKey
+		}
+	}
+	if u.KTPVerificationStatus == "" {
+		u.KTPVerificationStatus = database.VerificationUnverified
+	}
+	r.s.st.users[u.UserID] = *u
+	return nil
+}
+
+func (r userRepo) FindByEmail(ctx context.Context, email string) (*database.User, error) {
+	defer r.s.lock()()
+	for _, u := range r.s.st.users {
+		if _, deleted := r.s.st.deletedUsers[u.UserID]; !deleted && u.Email == email {
+			return &u, nil
+		}
+	}
+	return nil, database.ErrUserNotFound
+}
+
+func (r userRepo) FindByID(ctx context.Context, userID string) (*database.User, error) {
+	defer r.s.lock()()
+	u, ok := r.s.st.user(userID)
+	if !ok {
+		return nil, database.ErrUserNotFound
+	}
+	return &u, nil
+}
+
+func (r userRepo) FindByNIKHash(ctx context.Context, hash string) (*database.User, error) {
+	defer r.s.lock()()
+	var found *database.User
+	for _, u := range r.s.st.users {
+		if _, deleted := r.s.st.deletedUsers[u.UserID]; deleted || hash == "" || u.NIKHash != hash {
+			continue
+		}
+		if found == nil || u.CreatedAt.Before(found.CreatedAt) {
+			u := u
+			found = &u
+		}
+	}
+	if found == nil {
+		return nil, database.ErrUserNotFound
+	}
+	return found, nil
+}
+
+func (r userRepo) List(ctx context.Context) ([]database.User, error) {
+	defer r.s.lock()()
+	var users []database.User
+	for _, u := range r.s.st.users {
+		if _, deleted := r.s.st.deletedUsers[u.UserID]; !deleted {
+			users = append(users, u)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].CreatedAt.Before(users[j].CreatedAt) })
+	return users, nil
+}
+
+func (r userRepo) Update(ctx context.Context, userID string, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return errors.New("no fields provided for user update")
+	}
+	defer r.s.lock()()
+	u, ok := r.s.st.user(userID)
+	if !ok {
+		return sql.ErrNoRows
+	}
+	for col, val := range updates {
+		switch col {
+		case "name":
+			u.Name = fmt.Sprint(val)
+		case "email":
+			u.Email = fmt.Sprint(val)
+		case "phone":
+			u.Phone = fmt.Sprint(val)
+		case "password":
+			u.Password = fmt.Sprint(val)
+		case "nik":
+			u.NIK = fmt.Sprint(val)
+		case "nik_hash":
+			u.NIKHash = fmt.Sprint(val)
+		case "email_verified_at":
+			switch v := val.(type) {
+			case nil:
+				u.EmailVerifiedAt = nil
+			case time.Time:
+				u.EmailVerifiedAt = &v
+			default:
+				return fmt.Errorf("unsupported value type %T for email_verified_at", val)
+			}
+		case "ktp_image_id":
+			id, valid, err := toNullInt64(val)
+			if err != nil {
+				return err
+			}
+			if valid {
+				u.KTPImageID = &id
+			} else {
+				u.KTPImageID = nil
+			}
+		default:
+			return fmt.Errorf("invalid or forbidden column for update: %s", col)
+		}
+	}
+	r.s.st.users[userID] = u
+	return nil
+}
+
+func (r userRepo) Delete(ctx context.Context, userID string) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.user(userID); !ok {
+		return sql.ErrNoRows
+	}
+	now := time.Now()
+	r.s.st.deletedUsers[userID] = now
+	for id, v := range r.s.st.vehicles {
+		if _, deleted := r.s.st.deletedVehicles[id]; !deleted && v.UserID == userID {
+			r.s.st.deletedVehicles[id] = now
+		}
+	}
+	for id, lr := range r.s.st.lostReports {
+		if _, deleted := r.s.st.deletedLostReports[id]; !deleted && lr.UserID == userID {
+			r.s.st.deletedLostReports[id] = now
+		}
+	}
+	return nil
+}
+
+func (r userRepo) Restore(ctx context.Context, userID string) error {
+	defer r.s.lock()()
+	deletedAt, ok := r.s.st.deletedUsers[userID]
+	if !ok {
+		return database.ErrUserNotFound
+	}
+	for id, at := range r.s.st.deletedVehicles {
+		if v := r.s.st.vehicles[id]; at.Equal(deletedAt) && v.UserID == userID && r.s.st.plateTaken(v.PlateNumber, id) {
+			return database.ErrPlateTaken
+		}
+	}
+	delete(r.s.st.deletedUsers, userID)
+	for id, at := range r.s.st.deletedVehicles {
+		if at.Equal(deletedAt) && r.s.st.vehicles[id].UserID == userID {
+			delete(r.s.st.deletedVehicles, id)
+		}
+	}
+	for id, at := range r.s.st.deletedLostReports {
+		if at.Equal(deletedAt) && r.s.st.lostReports[id].UserID == userID {
+			delete(r.s.st.deletedLostReports, id)
+		}
+	}
+	return nil
+}
+
+func (r userRepo) PurgeDeleted(ctx context.Context, before time.Time) (*database.PurgedRows, error) {
+	defer r.s.lock()()
+	p := &database.PurgedRows{}
+	for _, id := range expiredKeys(r.s.st.deletedUsers, before, func(a, b string) bool { return a < b }) {
+		p.Count++
+		p.ImageIDs = appendImagePtr(p.ImageIDs, r.s.st.users[id].KTPImageID)
+		r.s.st.purgeUser(id)
+	}
+	return p, nil
+}
+
+func (r userRepo) FindByAPIKey(ctx context.Context, key string) (*database.User, int64, error) {
+	defer r.s.lock()()
+	for _, k := range r.s.st.apiKeys {
+		if bcrypt.CompareHashAndPassword(k.keyHash, []byte(key)) == nil {
+			u, ok := r.s.st.user(k.userID)
+			if !ok {
+				return nil, 0, database.ErrUserNotFound
+			}
+			return &u, k.id, nil
+		}
+	}
+	return nil, 0, database.ErrInvalidAPIKey
+}
+
+// user mengembalikan user yang tidak sedang di-soft delete.
+func (st *state) user(userID string) (database.User, bool) {
+	u, ok := st.users[userID]
+	if _, deleted := st.deletedUsers[userID]; deleted {
+		return database.User{}, false
+	}
+	return u, ok
+}
+
+type userTokenRepo struct{ s *Store }
+
+func (r userTokenRepo) Create(ctx context.Context, t *database.UserToken) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.users[t.UserID]; !ok {
+		return errors.New("pq: insert or update on table \"user_tokens\" violates foreign key constraint")
+	}
+	if _, exists := r.s.st.userTokens[t.TokenHash]; exists {
+		return errDuplicateKey
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	r.s.st.userTokens[t.TokenHash] = *t
+	return nil
+}
+
+func (r userTokenRepo) Consume(ctx context.Context, tokenHash, purpose string, now time.Time) (*database.UserToken, error) {
+	defer r.s.lock()()
+	t, ok := r.s.st.userTokens[tokenHash]
+	if !ok || t.Purpose != purpose || t.UsedAt != nil || !t.ExpiresAt.After(now) {
+		return nil, database.ErrTokenInvalid
+	}
+	t.UsedAt = &now
+	r.s.st.userTokens[tokenHash] = t
+	return &t, nil
+}
+
+func (r userTokenRepo) InvalidateForUser(ctx context.Context, userID, purpose string, now time.Time) error {
+	defer r.s.lock()()
+	for hash, t := range r.s.st.userTokens {
+		if t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil {
+			t.UsedAt = &now
+			r.s.st.userTokens[hash] = t
+		}
+	}
+	return nil
+}
+
+type adminRepo struct{ s *Store }
+
+func (r adminRepo) IsAdmin(ctx context.Context, userID string) (bool, error) {
+	defer r.s.lock()()
+	_, ok := r.s.st.admins[userID]
+	return ok, nil
+}
+
+func (r adminRepo) Create(ctx context.Context, a *database.Admin) error {
+	defer r.s.lock()()
+	if _, exists := r.s.st.admins[a.UserID]; exists {
+		return errDuplicateKey
+	}
+	r.s.st.admins[a.UserID] = *a
+	return nil
+}
+
+func (r adminRepo) GetByUserID(ctx context.Context, userID string) (*database.Admin, error) {
+	defer r.s.lock()()
+	a, ok := r.s.st.admins[userID]
+	if !ok {
+		return nil, database.ErrAdminNotFound
+	}
+	return &a, nil
+}
+
+func (r adminRepo) List(ctx context.Context) ([]database.Admin, error) {
+	defer r.s.lock()()
+	var list []database.Admin
+	for _, a := range r.s.st.admins {
+		list = append(list, a)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].UserID < list[j].UserID })
+	return list, nil
+}
+
+func (r adminRepo) Update(ctx context.Context, userID string, a *database.Admin) error {
+	defer r.s.lock()()
+	existing, ok := r.s.st.admins[userID]
+	if !ok {
+		return errors.New("no admin record updated")
+	}
+	existing.AdminLevel = a.AdminLevel
+	existing.CreatedAt = a.CreatedAt
+	r.s.st.admins[userID] = existing
+	return nil
+}
+
+func (r adminRepo) Delete(ctx context.Context, userID string) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.admins[userID]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(r.s.st.admins, userID)
+	return nil
+}
+
+// toNullInt64 mengonversi nilai dari map update (hasil decode JSON atau
+// nilai Go langsung) menjadi ID. nil berarti kolom di-set NULL.
+func toNullInt64(val interface{}) (int64, bool, error) {
+	switch v := val.(type) {
+	case nil:
+		return 0, false, nil
+	case int:
+		return int64(v), true, nil
+	case int64:
+		return v, true, nil
+	case float64:
+		return int64(v), true, nil
+	case sql.NullInt64:
+		return v.Int64, v.Valid, nil
+	default:
+		return 0, false, fmt.Errorf("unsupported value type %T for id column", val)
+	}
+}
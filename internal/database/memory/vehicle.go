@@ -0,0 +1,254 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+type vehicleRepo struct{ s *Store }
+
+func (r vehicleRepo) Create(ctx context.Context, v *database.Vehicle) error {
+	defer r.s.lock()()
+	if r.s.st.plateTaken(v.PlateNumber, 0) {
+		return database.ErrPlateTaken
+	}
+	if v.VerificationStatus == "" {
+		v.VerificationStatus = database.VerificationUnverified
+	}
+	if v.KKVerificationStatus == "" {
+		v.KKVerificationStatus = database.VerificationUnverified
+	}
+	r.s.st.nextVehicleID++
+	v.VehicleID = r.s.st.nextVehicleID
+	r.s.st.vehicles[v.VehicleID] = *v
+	return nil
+}
+
+func (r vehicleRepo) GetByID(ctx context.Context, id int64) (*database.Vehicle, error) {
+	defer r.s.lock()()
+	v, ok := r.s.st.vehicle(id)
+	if !ok {
+		return nil, database.ErrVehicleNotFound
+	}
+	return &v, nil
+}
+
+func (r vehicleRepo) GetByPlate(ctx context.Context, plateNumber string) (*database.Vehicle, error) {
+	defer r.s.lock()()
+	plate := database.NormalizePlate(plateNumber)
+	for _, v := range r.s.sortedVehicles() {
+		if plate != "" && database.NormalizePlate(v.PlateNumber) == plate {
+			return &v, nil
+		}
+	}
+	return nil, database.ErrVehicleNotFound
+}
+
+func (r vehicleRepo) List(ctx context.Context) ([]database.Vehicle, error) {
+	defer r.s.lock()()
+	return r.s.sortedVehicles(), nil
+}
+
+func (r vehicleRepo) ListByUserID(ctx context.Context, userID string) ([]database.Vehicle, error) {
+	defer r.s.lock()()
+	var list []database.Vehicle
+	for _, v := range r.s.sortedVehicles() {
+		if v.UserID == userID {
+			list = append(list, v)
+		}
+	}
+	return list, nil
+}
+
+func (r vehicleRepo) Update(ctx context.Context, id int64, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return errors.New("no fields provided for vehicle update")
+	}
+	defer r.s.lock()()
+	v, ok := r.s.st.vehicle(id)
+	if !ok {
+		return sql.ErrNoRows
+	}
+	for col, val := range updates {
+		switch col {
+		case "vehicle_name":
+			v.VehicleName = fmt.Sprint(val)
+		case "color":
+			v.Color = fmt.Sprint(val)
+		case "plate_number":
+			v.PlateNumber = fmt.Sprint(val)
+			if r.s.st.plateTaken(v.PlateNumber, id) {
+				return database.ErrPlateTaken
+			}
+		case "stnk_image_id", "kk_image_id":
+			imgID, valid, err := toNullInt64(val)
+			if err != nil {
+				return err
+			}
+			if col == "stnk_image_id" {
+				v.STNKImageID = sql.NullInt64{Int64: imgID, Valid: valid}
+			} else {
+				v.KKImageID = sql.NullInt64{Int64: imgID, Valid: valid}
+			}
+		case "ownership":
+			if val == nil {
+				v.Ownership = sql.NullString{}
+			} else {
+				v.Ownership = sql.NullString{String: fmt.Sprint(val), Valid: true}
+			}
+		case "brand", "model", "body_type", "normalized_color", "distinguishing_marks":
+			var str *string
+			if val != nil {
+				s := fmt.Sprint(val)
+				str = &s
+			}
+			switch col {
+			case "brand":
+				v.Brand = str
+			case "model":
+				v.Model = str
+			case "body_type":
+				v.BodyType = str
+			case "normalized_color":
+				v.NormalizedColor = str
+			default:
+				v.DistinguishingMarks = str
+			}
+		case "year":
+			if val == nil {
+				v.Year = nil
+			} else {
+				year, ok := val.(int)
+				if !ok {
+					return fmt.Errorf("invalid year value: %v", val)
+				}
+				v.Year = &year
+			}
+		default:
+			return fmt.Errorf("invalid or forbidden column for update: %s", col)
+		}
+	}
+	r.s.st.vehicles[id] = v
+	return nil
+}
+
+func (r vehicleRepo) SetVerification(ctx context.Context, id int64, status, verifiedBy, note string, at time.Time) error {
+	defer r.s.lock()()
+	v, ok := r.s.st.vehicle(id)
+	if !ok {
+		return database.ErrVehicleNotFound
+	}
+	v.VehicleVerification = database.VehicleVerification{VerificationStatus: status}
+	if note != "" {
+		v.VerificationNote = &note
+	}
+	if verifiedBy != "" {
+		v.VerifiedBy = &verifiedBy
+		v.VerifiedAt = &at
+	}
+	r.s.st.vehicles[id] = v
+	return nil
+}
+
+func (r vehicleRepo) Transfer(ctx context.Context, id int64, fromUserID, toUserID string) ([]int64, error) {
+	defer r.s.lock()()
+	v, ok := r.s.st.vehicle(id)
+	if !ok || v.UserID != fromUserID {
+		return nil, database.ErrVehicleNotFound
+	}
+	images := appendImageID(appendImageID(nil, v.STNKImageID), v.KKImageID)
+	v.UserID = toUserID
+	v.Ownership = sql.NullString{}
+	v.STNKImageID, v.KKImageID = sql.NullInt64{}, sql.NullInt64{}
+	v.VehicleVerification = database.VehicleVerification{VerificationStatus: database.VerificationUnverified}
+	v.KKVerification = database.KKVerification{KKVerificationStatus: database.VerificationUnverified}
+	r.s.st.vehicles[id] = v
+	return images, nil
+}
+
+func (r vehicleRepo) Delete(ctx context.Context, id int64) error {
+	defer r.s.lock()()
+	if _, ok := r.s.st.vehicle(id); !ok {
+		return sql.ErrNoRows
+	}
+	now := time.Now()
+	r.s.st.deletedVehicles[id] = now
+	for lostID, lr := range r.s.st.lostReports {
+		if _, deleted := r.s.st.deletedLostReports[lostID]; !deleted && int64(lr.VehicleID) == id {
+			r.s.st.deletedLostReports[lostID] = now
+		}
+	}
+	return nil
+}
+
+func (r vehicleRepo) Restore(ctx context.Context, id int64) error {
+	defer r.s.lock()()
+	deletedAt, ok := r.s.st.deletedVehicles[id]
+	if !ok {
+		return database.ErrVehicleNotFound
+	}
+	if r.s.st.plateTaken(r.s.st.vehicles[id].PlateNumber, id) {
+		return database.ErrPlateTaken
+	}
+	delete(r.s.st.deletedVehicles, id)
+	for lostID, at := range r.s.st.deletedLostReports {
+		if at.Equal(deletedAt) && int64(r.s.st.lostReports[lostID].VehicleID) == id {
+			delete(r.s.st.deletedLostReports, lostID)
+		}
+	}
+	return nil
+}
+
+func (r vehicleRepo) PurgeDeleted(ctx context.Context, before time.Time) (*database.PurgedRows, error) {
+	defer r.s.lock()()
+	p := &database.PurgedRows{}
+	for _, id := range expiredKeys(r.s.st.deletedVehicles, before, func(a, b int64) bool { return a < b }) {
+		v := r.s.st.vehicles[id]
+		p.Count++
+		p.ImageIDs = appendImageID(appendImageID(p.ImageIDs, v.STNKImageID), v.KKImageID)
+		r.s.st.purgeVehicle(id)
+	}
+	return p, nil
+}
+
+// plateTaken meniru idx_vehicle_plate_active: plat yang sama setelah
+// dinormalisasi tidak boleh dipakai dua kendaraan aktif. exceptID adalah
+// kendaraan yang sedang diubah.
+func (st *state) plateTaken(plate string, exceptID int64) bool {
+	n := database.NormalizePlate(plate)
+	if n == "" {
+		return false
+	}
+	for id, v := range st.vehicles {
+		if _, deleted := st.deletedVehicles[id]; !deleted && id != exceptID && database.NormalizePlate(v.PlateNumber) == n {
+			return true
+		}
+	}
+	return false
+}
+
+// vehicle mengembalikan kendaraan yang tidak sedang di-soft delete.
+func (st *state) vehicle(id int64) (database.Vehicle, bool) {
+	v, ok := st.vehicles[id]
+	if _, deleted := st.deletedVehicles[id]; deleted {
+		return database.Vehicle{}, false
+	}
+	return v, ok
+}
+
+func (s *Store) sortedVehicles() []database.Vehicle {
+	list := make([]database.Vehicle, 0, len(s.st.vehicles))
+	for id, v := range s.st.vehicles {
+		if _, deleted := s.st.deletedVehicles[id]; !deleted {
+			list = append(list, v)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].VehicleID < list[j].VehicleID })
+	return list
+}
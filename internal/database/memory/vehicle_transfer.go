@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+type vehicleTransferRepo struct{ s *Store }
+
+func (r vehicleTransferRepo) Create(ctx context.Context, t *database.VehicleTransfer) error {
+	defer r.s.lock()()
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	for id, old := range r.s.st.transfers {
+		if old.VehicleID != t.VehicleID || old.Status != database.TransferPending {
+			continue
+		}
+		if t.CreatedAt.Before(old.ExpiresAt) {
+			return database.ErrVehicleTransferPending
+		}
+		expiredAt := old.ExpiresAt
+		old.Status, old.DecidedAt = database.TransferExpired, &expiredAt
+		r.s.st.transfers[id] = old
+	}
+	r.s.st.nextTransferID++
+	t.TransferID = r.s.st.nextTransferID
+	t.Status = database.TransferPending
+	t.DecidedAt = nil
+	r.s.st.transfers[t.TransferID] = *t
+	return nil
+}
+
+func (r vehicleTransferRepo) GetByID(ctx context.Context, id int64) (*database.VehicleTransfer, error) {
+	defer r.s.lock()()
+	t, ok := r.s.st.transfers[id]
+	if !ok {
+		return nil, database.ErrVehicleTransferNotFound
+	}
+	r.s.st.fillTransfer(&t)
+	return &t, nil
+}
+
+func (r vehicleTransferRepo) ListByUserID(ctx context.Context, userID string) ([]database.VehicleTransfer, error) {
+	defer r.s.lock()()
+	return r.s.st.listTransfers(func(t database.VehicleTransfer) bool {
+		return t.FromUserID == userID || t.ToUserID == userID
+	}), nil
+}
+
+func (r vehicleTransferRepo) ListByVehicleID(ctx context.Context, vehicleID int64) ([]database.VehicleTransfer, error) {
+	defer r.s.lock()()
+	return r.s.st.listTransfers(func(t database.VehicleTransfer) bool { return t.VehicleID == vehicleID }), nil
+}
+
+func (r vehicleTransferRepo) Decide(ctx context.Context, id int64, status string, at time.Time) error {
+	defer r.s.lock()()
+	t, ok := r.s.st.transfers[id]
+	if !ok || t.Status != database.TransferPending {
+		return database.ErrVehicleTransferDecided
+	}
+	t.Status, t.DecidedAt = status, &at
+	r.s.st.transfers[id] = t
+	return nil
+}
+
+// fillTransfer meniru JOIN ke tabel vehicle, termasuk kendaraan yang sedang
+// di-soft delete.
+func (st *state) fillTransfer(t *database.VehicleTransfer) {
+	v := st.vehicles[t.VehicleID]
+	t.VehicleName, t.PlateNumber = v.VehicleName, v.PlateNumber
+}
+
+func (st *state) listTransfers(keep func(database.VehicleTransfer) bool) []database.VehicleTransfer {
+	var list []database.VehicleTransfer
+	for _, t := range st.transfers {
+		if keep(t) {
+			st.fillTransfer(&t)
+			list = append(list, t)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.After(list[j].CreatedAt)
+		}
+		return list[i].TransferID > list[j].TransferID
+	})
+	return list
+}
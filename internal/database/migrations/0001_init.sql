@@ -0,0 +1,90 @@
+-- Skema awal JAGA, disusun dari query di internal/database.
+
+CREATE TABLE IF NOT EXISTS images (
+    image_id          BIGSERIAL PRIMARY KEY,
+    storage_path      TEXT NOT NULL,
+    filename_original TEXT,
+    mime_type         TEXT,
+    size_bytes        BIGINT,
+    uploaded_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    user_id      TEXT PRIMARY KEY,
+    name         TEXT NOT NULL,
+    email        TEXT NOT NULL UNIQUE,
+    phone        TEXT NOT NULL DEFAULT '',
+    password     TEXT NOT NULL,
+    nik          TEXT NOT NULL,
+    ktp_image_id BIGINT REFERENCES images (image_id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS admins (
+    user_id     TEXT PRIMARY KEY REFERENCES users (user_id) ON DELETE CASCADE,
+    admin_level INTEGER NOT NULL DEFAULT 1,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS service_api_keys (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    key_hash     TEXT NOT NULL,
+    last_used_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS vehicle (
+    vehicle_id    BIGSERIAL PRIMARY KEY,
+    vehicle_name  TEXT NOT NULL,
+    color         TEXT NOT NULL DEFAULT '',
+    user_id       TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    plate_number  TEXT NOT NULL,
+    stnk_image_id BIGINT REFERENCES images (image_id) ON DELETE SET NULL,
+    kk_image_id   BIGINT REFERENCES images (image_id) ON DELETE SET NULL,
+    ownership     TEXT
+);
+
+CREATE TABLE IF NOT EXISTS cameras (
+    camera_id BIGSERIAL PRIMARY KEY,
+    name      TEXT NOT NULL,
+    ip_camera TEXT NOT NULL DEFAULT '',
+    latitude  DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    address   TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE TABLE IF NOT EXISTS detected (
+    detected_id         SERIAL PRIMARY KEY,
+    camera_id           INTEGER NOT NULL REFERENCES cameras (camera_id) ON DELETE CASCADE,
+    person_image_id     BIGINT REFERENCES images (image_id) ON DELETE SET NULL,
+    motorcycle_image_id BIGINT REFERENCES images (image_id) ON DELETE SET NULL,
+    timestamp           TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_detected_timestamp ON detected (timestamp);
+
+CREATE TABLE IF NOT EXISTS lost_report (
+    lost_id                  SERIAL PRIMARY KEY,
+    user_id                  TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    timestamp                TIMESTAMPTZ NOT NULL,
+    vehicle_id               INTEGER NOT NULL REFERENCES vehicle (vehicle_id) ON DELETE CASCADE,
+    address                  TEXT NOT NULL,
+    latitude                 DOUBLE PRECISION,
+    longitude                DOUBLE PRECISION,
+    status                   TEXT NOT NULL DEFAULT 'BELUM_DIPROSES',
+    motor_evidence_image_id  BIGINT REFERENCES images (image_id) ON DELETE SET NULL,
+    person_evidence_image_id BIGINT REFERENCES images (image_id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS suspect (
+    suspect_id   BIGSERIAL PRIMARY KEY,
+    detected_id  INTEGER NOT NULL REFERENCES detected (detected_id) ON DELETE CASCADE,
+    lost_id      INTEGER NOT NULL REFERENCES lost_report (lost_id) ON DELETE CASCADE,
+    person_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    motor_score  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    final_score  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_suspect_lost_id ON suspect (lost_id);
@@ -0,0 +1,29 @@
+-- TOTP dua faktor untuk admin.
+CREATE TABLE IF NOT EXISTS admin_mfa (
+    user_id        TEXT PRIMARY KEY REFERENCES users (user_id) ON DELETE CASCADE,
+    secret         TEXT NOT NULL,
+    -- NULL selama pendaftaran belum dikonfirmasi dengan kode pertama.
+    enabled_at     TIMESTAMPTZ,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS admin_recovery_codes (
+    id        BIGSERIAL PRIMARY KEY,
+    user_id   TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_recovery_codes_user ON admin_recovery_codes (user_id);
+
+-- Kebijakan 2FA (satu baris): admin dengan admin_level di atas
+-- require_above_level wajib memakai 2FA. Default 0 berarti semua admin.
+CREATE TABLE IF NOT EXISTS mfa_policy (
+    id                  BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    require_above_level INTEGER NOT NULL DEFAULT 0,
+    updated_by          TEXT REFERENCES users (user_id) ON DELETE SET NULL,
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO mfa_policy (id, require_above_level) VALUES (TRUE, 0) ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,36 @@
+-- Jejak audit perubahan data dan aksi admin. Setiap baris menyimpan hash
+-- baris sebelumnya sehingga perubahan atau penghapusan baris di tengah
+-- rantai terdeteksi saat verifikasi.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id            BIGSERIAL PRIMARY KEY,
+    occurred_at   TIMESTAMPTZ NOT NULL,
+    -- Tanpa foreign key: riwayat tetap ada walau user dihapus.
+    actor_user_id TEXT,
+    auth_method   TEXT NOT NULL,
+    api_key_id    BIGINT,
+    action        TEXT NOT NULL,
+    entity_type   TEXT NOT NULL,
+    entity_id     TEXT NOT NULL DEFAULT '',
+    -- JSON (bukan JSONB) agar teks tersimpan persis seperti saat di-hash.
+    before_data   JSON,
+    after_data    JSON,
+    ip            TEXT NOT NULL DEFAULT '',
+    request_id    TEXT NOT NULL DEFAULT '',
+    prev_hash     TEXT NOT NULL,
+    hash          TEXT NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor_user_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_occurred_at ON audit_log (occurred_at);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
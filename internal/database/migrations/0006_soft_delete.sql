@@ -0,0 +1,13 @@
+-- Soft delete untuk data yang dirujuk riwayat deteksi dan suspect. Baris
+-- yang dihapus lewat API hanya diberi deleted_at; job purge menghapusnya
+-- permanen setelah masa retensi.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE vehicle ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE lost_report ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE cameras ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Hanya baris terhapus yang diindeks; dipakai oleh purge.
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_vehicle_deleted_at ON vehicle (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_lost_report_deleted_at ON lost_report (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_cameras_deleted_at ON cameras (deleted_at) WHERE deleted_at IS NOT NULL;
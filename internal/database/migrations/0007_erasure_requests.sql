@@ -0,0 +1,16 @@
+-- Permintaan penghapusan data pribadi (UU PDP). Data user baru dianonimkan
+-- setelah permintaan disetujui admin.
+CREATE TABLE IF NOT EXISTS erasure_requests (
+    request_id    BIGSERIAL PRIMARY KEY,
+    user_id       TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    reason        TEXT NOT NULL DEFAULT '',
+    status        TEXT NOT NULL DEFAULT 'PENDING',
+    requested_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    decided_by    TEXT REFERENCES users (user_id) ON DELETE SET NULL,
+    decided_at    TIMESTAMPTZ,
+    decision_note TEXT NOT NULL DEFAULT ''
+);
+
+-- Satu user hanya boleh punya satu permintaan yang belum diputuskan.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_erasure_requests_pending ON erasure_requests (user_id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_erasure_requests_status ON erasure_requests (status, requested_at);
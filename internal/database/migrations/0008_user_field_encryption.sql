@@ -0,0 +1,6 @@
+-- NIK dan nomor telepon kini disimpan terenkripsi (lihat package
+-- fieldcrypt). nik_hash adalah blind index HMAC untuk pencarian NIK persis;
+-- baris lama diisi oleh cmd/rotate-keys.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS nik_hash TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_users_nik_hash ON users (nik_hash) WHERE nik_hash <> '';
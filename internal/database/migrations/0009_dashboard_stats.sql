@@ -0,0 +1,19 @@
+-- Data tambahan untuk statistik dashboard admin.
+
+-- Zona kamera (mis. wilayah polres) untuk memfilter statistik.
+ALTER TABLE cameras ADD COLUMN IF NOT EXISTS zone TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_cameras_zone ON cameras (zone);
+
+-- timestamp adalah waktu kejadian; created_at adalah waktu laporan dibuat
+-- (status BELUM_DIPROSES) dan found_at waktu status menjadi
+-- SUDAH_DITEMUKAN. Laporan lama memakai waktu kejadian sebagai perkiraan
+-- created_at; found_at laporan lama tidak diketahui dan dibiarkan NULL.
+ALTER TABLE lost_report ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ;
+UPDATE lost_report SET created_at = timestamp WHERE created_at IS NULL;
+ALTER TABLE lost_report ALTER COLUMN created_at SET DEFAULT NOW();
+ALTER TABLE lost_report ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE lost_report ADD COLUMN IF NOT EXISTS found_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_lost_report_created_at ON lost_report (created_at);
+CREATE INDEX IF NOT EXISTS idx_suspect_created_at ON suspect (created_at);
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users (created_at);
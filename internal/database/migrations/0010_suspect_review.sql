@@ -0,0 +1,32 @@
+-- Review hasil pencocokan: admin menandai suspect benar atau salah dan
+-- pemilik laporan memberi feedback apakah itu motornya.
+ALTER TABLE suspect ADD COLUMN IF NOT EXISTS review_status TEXT NOT NULL DEFAULT 'PENDING';
+ALTER TABLE suspect ADD COLUMN IF NOT EXISTS reviewed_by TEXT REFERENCES users (user_id) ON DELETE SET NULL;
+ALTER TABLE suspect ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+ALTER TABLE suspect ADD COLUMN IF NOT EXISTS review_comment TEXT NOT NULL DEFAULT '';
+ALTER TABLE suspect ADD COLUMN IF NOT EXISTS owner_feedback TEXT;
+ALTER TABLE suspect ADD COLUMN IF NOT EXISTS owner_feedback_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_suspect_review_status ON suspect (review_status);
+
+-- Setiap review dan feedback disimpan sebagai label untuk melatih ulang
+-- model skor. Skor dan gambar deteksi disalin saat label dibuat sehingga
+-- label tetap ada walaupun suspect-nya terhapus.
+CREATE TABLE IF NOT EXISTS suspect_labels (
+    label_id            BIGSERIAL PRIMARY KEY,
+    suspect_id          BIGINT NOT NULL,
+    lost_id             INTEGER NOT NULL,
+    detected_id         INTEGER NOT NULL,
+    person_image_id     BIGINT,
+    motorcycle_image_id BIGINT,
+    person_score        DOUBLE PRECISION NOT NULL,
+    motor_score         DOUBLE PRECISION NOT NULL,
+    final_score         DOUBLE PRECISION NOT NULL,
+    source              TEXT NOT NULL,
+    label               TEXT NOT NULL,
+    labeled_by          TEXT REFERENCES users (user_id) ON DELETE SET NULL,
+    comment             TEXT NOT NULL DEFAULT '',
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_suspect_labels_created_at ON suspect_labels (created_at);
@@ -0,0 +1,22 @@
+-- Setiap analisis (pencarian suspect) untuk sebuah laporan dicatat sebagai
+-- run agar bisa dibedakan antara "belum dianalisis", "sedang berjalan",
+-- "selesai tanpa suspect" dan "gagal".
+CREATE TABLE IF NOT EXISTS analysis_runs (
+    run_id             BIGSERIAL PRIMARY KEY,
+    lost_id            INTEGER NOT NULL REFERENCES lost_report (lost_id) ON DELETE CASCADE,
+    status             TEXT NOT NULL DEFAULT 'QUEUED',
+    radius_km          DOUBLE PRECISION NOT NULL,
+    window_hours       INTEGER NOT NULL,
+    requested_by       TEXT REFERENCES users (user_id) ON DELETE SET NULL,
+    scorer_version     TEXT NOT NULL DEFAULT '',
+    detections_scanned INTEGER,
+    error              TEXT NOT NULL DEFAULT '',
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at         TIMESTAMPTZ,
+    finished_at        TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_analysis_runs_lost_id ON analysis_runs (lost_id, run_id DESC);
+CREATE INDEX IF NOT EXISTS idx_analysis_runs_queued ON analysis_runs (run_id) WHERE status = 'QUEUED';
+-- Hanya boleh ada satu run yang antre atau berjalan per laporan.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_analysis_runs_active ON analysis_runs (lost_id) WHERE status IN ('QUEUED', 'RUNNING');
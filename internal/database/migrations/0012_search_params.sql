@@ -0,0 +1,27 @@
+-- Batas parameter pencarian yang boleh dipilih pemilik laporan atau admin.
+CREATE TABLE IF NOT EXISTS analysis_limits (
+    id               BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    max_radius_km    DOUBLE PRECISION NOT NULL DEFAULT 50,
+    max_window_hours INTEGER NOT NULL DEFAULT 168,
+    max_cameras      INTEGER NOT NULL DEFAULT 50,
+    updated_by       TEXT REFERENCES users (user_id) ON DELETE SET NULL,
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO analysis_limits (id) VALUES (TRUE) ON CONFLICT (id) DO NOTHING;
+
+-- Parameter pencarian per laporan. Laporan tanpa baris di sini memakai
+-- default dari konfigurasi.
+CREATE TABLE IF NOT EXISTS lost_report_search (
+    lost_id      INTEGER PRIMARY KEY REFERENCES lost_report (lost_id) ON DELETE CASCADE,
+    radius_km    DOUBLE PRECISION NOT NULL,
+    window_hours INTEGER NOT NULL,
+    camera_ids   BIGINT[] NOT NULL DEFAULT '{}',
+    zones        TEXT[] NOT NULL DEFAULT '{}',
+    updated_by   TEXT REFERENCES users (user_id) ON DELETE SET NULL,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Run menyimpan salinan cakupan kamera yang dipakai.
+ALTER TABLE analysis_runs ADD COLUMN IF NOT EXISTS camera_ids BIGINT[] NOT NULL DEFAULT '{}';
+ALTER TABLE analysis_runs ADD COLUMN IF NOT EXISTS zones TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,20 @@
+-- Atribut kendaraan terstruktur. normalized_color dan body_type memakai
+-- kode dari palet tetap di database.ColorPalette dan database.BodyTypes.
+ALTER TABLE vehicle
+    ADD COLUMN IF NOT EXISTS brand                TEXT,
+    ADD COLUMN IF NOT EXISTS model                TEXT,
+    ADD COLUMN IF NOT EXISTS year                 INTEGER,
+    ADD COLUMN IF NOT EXISTS body_type            TEXT,
+    ADD COLUMN IF NOT EXISTS normalized_color     TEXT,
+    ADD COLUMN IF NOT EXISTS distinguishing_marks TEXT;
+
+-- Atribut hasil prediksi detector untuk setiap deteksi.
+ALTER TABLE detected
+    ADD COLUMN IF NOT EXISTS attr_brand      TEXT,
+    ADD COLUMN IF NOT EXISTS attr_model      TEXT,
+    ADD COLUMN IF NOT EXISTS attr_body_type  TEXT,
+    ADD COLUMN IF NOT EXISTS attr_color      TEXT,
+    ADD COLUMN IF NOT EXISTS attr_confidence DOUBLE PRECISION CHECK (attr_confidence BETWEEN 0 AND 1);
+
+CREATE INDEX IF NOT EXISTS idx_detected_attr_color ON detected (attr_color, timestamp) WHERE attr_color IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_detected_attr_brand ON detected (lower(attr_brand), timestamp) WHERE attr_brand IS NOT NULL;
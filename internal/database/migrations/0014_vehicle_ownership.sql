@@ -0,0 +1,46 @@
+-- Plat nomor dalam bentuk normal (huruf besar tanpa spasi dan tanda baca)
+-- agar "B 1234 XYZ" dan "b-1234-xyz" dianggap plat yang sama. Status
+-- verifikasi mengikuti foto STNK: PENDING setelah STNK diunggah, lalu
+-- VERIFIED atau REJECTED oleh admin.
+ALTER TABLE vehicle
+    ADD COLUMN IF NOT EXISTS plate_normalized    TEXT,
+    ADD COLUMN IF NOT EXISTS verification_status TEXT NOT NULL DEFAULT 'UNVERIFIED'
+        CHECK (verification_status IN ('UNVERIFIED', 'PENDING', 'VERIFIED', 'REJECTED')),
+    ADD COLUMN IF NOT EXISTS verification_note   TEXT,
+    ADD COLUMN IF NOT EXISTS verified_by         TEXT REFERENCES users (user_id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS verified_at         TIMESTAMPTZ;
+
+UPDATE vehicle SET plate_normalized = NULLIF(upper(regexp_replace(plate_number, '[^A-Za-z0-9]', '', 'g')), '')
+WHERE plate_normalized IS NULL;
+
+UPDATE vehicle SET verification_status = 'PENDING'
+WHERE stnk_image_id IS NOT NULL AND verification_status = 'UNVERIFIED';
+
+-- Plat ganda yang terdaftar sebelum migrasi ini: hanya kendaraan tertua yang
+-- memegang plat tersebut, sisanya perlu ditinjau admin.
+UPDATE vehicle v SET plate_normalized = NULL,
+    verification_note = 'duplicate plate number registered before uniqueness check'
+FROM vehicle older
+WHERE older.plate_normalized = v.plate_normalized AND older.vehicle_id < v.vehicle_id
+  AND older.deleted_at IS NULL AND v.deleted_at IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_vehicle_plate_active ON vehicle (plate_normalized) WHERE deleted_at IS NULL;
+
+-- Permintaan pindah kepemilikan. Dibuat oleh pemilik saat ini dan berlaku
+-- setelah diterima penerima; baris yang sudah diputuskan menjadi riwayat.
+CREATE TABLE IF NOT EXISTS vehicle_transfers (
+    transfer_id  BIGSERIAL PRIMARY KEY,
+    vehicle_id   BIGINT NOT NULL REFERENCES vehicle (vehicle_id) ON DELETE CASCADE,
+    from_user_id TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    to_user_id   TEXT NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    status       TEXT NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'ACCEPTED', 'REJECTED', 'CANCELLED', 'EXPIRED')),
+    note         TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at   TIMESTAMPTZ NOT NULL,
+    decided_at   TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_vehicle_transfers_pending ON vehicle_transfers (vehicle_id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_vehicle_transfers_from ON vehicle_transfers (from_user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_vehicle_transfers_to ON vehicle_transfers (to_user_id, created_at);
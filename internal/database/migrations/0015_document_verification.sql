@@ -0,0 +1,26 @@
+-- Status verifikasi KTP (per user) dan KK (per kendaraan), melengkapi
+-- verification_status kendaraan yang mengikuti STNK. Dokumen yang sudah
+-- diunggah sebelum migrasi ini masuk antrean sebagai PENDING.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS ktp_verification_status TEXT NOT NULL DEFAULT 'UNVERIFIED'
+        CHECK (ktp_verification_status IN ('UNVERIFIED', 'PENDING', 'VERIFIED', 'REJECTED')),
+    ADD COLUMN IF NOT EXISTS ktp_verification_note   TEXT,
+    ADD COLUMN IF NOT EXISTS ktp_verified_by         TEXT REFERENCES users (user_id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS ktp_verified_at         TIMESTAMPTZ;
+
+ALTER TABLE vehicle
+    ADD COLUMN IF NOT EXISTS kk_verification_status TEXT NOT NULL DEFAULT 'UNVERIFIED'
+        CHECK (kk_verification_status IN ('UNVERIFIED', 'PENDING', 'VERIFIED', 'REJECTED')),
+    ADD COLUMN IF NOT EXISTS kk_verification_note   TEXT,
+    ADD COLUMN IF NOT EXISTS kk_verified_by         TEXT REFERENCES users (user_id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS kk_verified_at         TIMESTAMPTZ;
+
+UPDATE users SET ktp_verification_status = 'PENDING'
+WHERE ktp_image_id IS NOT NULL AND ktp_verification_status = 'UNVERIFIED';
+
+UPDATE vehicle SET kk_verification_status = 'PENDING'
+WHERE kk_image_id IS NOT NULL AND kk_verification_status = 'UNVERIFIED';
+
+CREATE INDEX IF NOT EXISTS idx_users_ktp_pending ON users (user_id) WHERE ktp_verification_status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_vehicle_verification_pending ON vehicle (vehicle_id)
+    WHERE verification_status = 'PENDING' OR kk_verification_status = 'PENDING';
@@ -0,0 +1,14 @@
+-- Hasil OCR foto KTP dan STNK, satu baris per foto. NIK hasil ekstraksi
+-- dienkripsi seperti users.nik. Baris ikut terhapus bersama fotonya.
+CREATE TABLE IF NOT EXISTS document_ocr_results (
+    image_id      BIGINT PRIMARY KEY REFERENCES images (image_id) ON DELETE CASCADE,
+    document_type TEXT NOT NULL CHECK (document_type IN ('KTP', 'STNK')),
+    status        TEXT NOT NULL CHECK (status IN ('PENDING', 'DONE', 'FAILED')),
+    engine        TEXT NOT NULL DEFAULT '',
+    nik           TEXT NOT NULL DEFAULT '',
+    name          TEXT NOT NULL DEFAULT '',
+    plate_number  TEXT NOT NULL DEFAULT '',
+    confidence    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    error         TEXT NOT NULL DEFAULT '',
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
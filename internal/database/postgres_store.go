@@ -0,0 +1,516 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore membungkus koneksi database/sql menjadi Store.
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{db: db}
+}
+
+func (s *postgresStore) Repos() Repositories {
+	return newPostgresRepositories(s.db, nil)
+}
+
+// BeginTx membuka span "db transaction" yang ditutup oleh Commit atau
+// Rollback pertama. Span query di dalam transaksi menjadi anak span ini.
+func (s *postgresStore) BeginTx(ctx context.Context) (Tx, error) {
+	ctx, span := tracing.Start(ctx, "db transaction", semconv.DBSystemPostgreSQL)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		tracing.End(span, err)
+		return nil, err
+	}
+	return &postgresTx{tx: tx, span: span}, nil
+}
+
+type postgresTx struct {
+	tx   *sql.Tx
+	span trace.Span
+	done bool
+}
+
+func (t *postgresTx) Repos() Repositories { return newPostgresRepositories(t.tx, t.span) }
+
+func (t *postgresTx) Commit() error {
+	err := t.tx.Commit()
+	t.finish("commit", err)
+	return err
+}
+
+func (t *postgresTx) Rollback() error {
+	err := t.tx.Rollback()
+	if err == sql.ErrTxDone {
+		return err
+	}
+	t.finish("rollback", err)
+	return err
+}
+
+func (t *postgresTx) finish(outcome string, err error) {
+	if t.done {
+		return
+	}
+	t.done = true
+	t.span.SetAttributes(attribute.String("db.transaction.outcome", outcome))
+	tracing.End(t.span, err)
+}
+
+// newPostgresRepositories membuat repository di atas q. parent, bila tidak
+// nil, dipakai sebagai induk span setiap query.
+func newPostgresRepositories(q Querier, parent trace.Span) Repositories {
+	q = tracedQuerier{q: q, parent: parent}
+	return Repositories{
+		Users:         pgUserRepo{q},
+		UserTokens:    pgUserTokenRepo{q},
+		Admins:        pgAdminRepo{q},
+		AdminMFA:      pgAdminMFARepo{q},
+		AuditLog:      pgAuditLogRepo{q},
+		Erasures:      pgErasureRequestRepo{q},
+		Vehicles:      pgVehicleRepo{q},
+		Transfers:     pgVehicleTransferRepo{q},
+		Verifications: pgVerificationRepo{q},
+		DocumentOCR:   pgDocumentOCRRepo{q},
+		LostReports:   pgLostReportRepo{q},
+		Detected:      pgDetectedRepo{q},
+		Suspects:      pgSuspectRepo{q},
+		SuspectLabels: pgSuspectLabelRepo{q},
+		AnalysisRuns:  pgAnalysisRunRepo{q},
+		Search:        pgSearchRepo{q},
+		Images:        pgImageRepo{q},
+		Cameras:       pgCameraRepo{q},
+		Stats:         pgStatsRepo{q},
+	}
+}
+
+type pgUserRepo struct{ q Querier }
+
+func (r pgUserRepo) Create(ctx context.Context, u *User) error {
+	return CreateUserTx(ctx, r.q, u)
+}
+func (r pgUserRepo) FindByEmail(ctx context.Context, email string) (*User, error) {
+	return FindSingleUser(r.q, email, ctx)
+}
+func (r pgUserRepo) FindByID(ctx context.Context, userID string) (*User, error) {
+	return FindUserByID(r.q, userID, ctx)
+}
+func (r pgUserRepo) FindByNIKHash(ctx context.Context, hash string) (*User, error) {
+	return FindUserByNIKHash(ctx, r.q, hash)
+}
+func (r pgUserRepo) List(ctx context.Context) ([]User, error) {
+	return FindManyUser(r.q, ctx)
+}
+func (r pgUserRepo) Update(ctx context.Context, userID string, updates map[string]interface{}) error {
+	return UpdateUserTx(ctx, r.q, userID, updates)
+}
+func (r pgUserRepo) Delete(ctx context.Context, userID string) error {
+	return DeleteUserTx(ctx, r.q, userID)
+}
+func (r pgUserRepo) Restore(ctx context.Context, userID string) error {
+	return RestoreUserTx(ctx, r.q, userID)
+}
+func (r pgUserRepo) PurgeDeleted(ctx context.Context, before time.Time) (*PurgedRows, error) {
+	return PurgeDeletedUsers(ctx, r.q, before)
+}
+func (r pgUserRepo) Anonymize(ctx context.Context, userID string) ([]int64, error) {
+	return AnonymizeUserTx(ctx, r.q, userID)
+}
+func (r pgUserRepo) FindByAPIKey(ctx context.Context, apiKey string) (*User, int64, error) {
+	return ValidateAPIKeyAndGetUser(ctx, r.q, apiKey)
+}
+func (r pgUserRepo) SetKTPVerification(ctx context.Context, userID, status, verifiedBy, note string, at time.Time) error {
+	return SetUserKTPVerification(ctx, r.q, userID, status, verifiedBy, note, at)
+}
+
+type pgUserTokenRepo struct{ q Querier }
+
+func (r pgUserTokenRepo) Create(ctx context.Context, t *UserToken) error {
+	return CreateUserToken(ctx, r.q, t)
+}
+func (r pgUserTokenRepo) Consume(ctx context.Context, tokenHash, purpose string, now time.Time) (*UserToken, error) {
+	return ConsumeUserToken(ctx, r.q, tokenHash, purpose, now)
+}
+func (r pgUserTokenRepo) InvalidateForUser(ctx context.Context, userID, purpose string, now time.Time) error {
+	return InvalidateUserTokens(ctx, r.q, userID, purpose, now)
+}
+
+type pgErasureRequestRepo struct{ q Querier }
+
+func (r pgErasureRequestRepo) Create(ctx context.Context, e *ErasureRequest) error {
+	return CreateErasureRequest(ctx, r.q, e)
+}
+func (r pgErasureRequestRepo) GetByID(ctx context.Context, id int64) (*ErasureRequest, error) {
+	return GetErasureRequest(ctx, r.q, id)
+}
+func (r pgErasureRequestRepo) GetLatestByUserID(ctx context.Context, userID string) (*ErasureRequest, error) {
+	return GetLatestErasureRequest(ctx, r.q, userID)
+}
+func (r pgErasureRequestRepo) List(ctx context.Context, status string) ([]ErasureRequest, error) {
+	return ListErasureRequests(ctx, r.q, status)
+}
+func (r pgErasureRequestRepo) Decide(ctx context.Context, id int64, status, decidedBy, note string, at time.Time) error {
+	return DecideErasureRequest(ctx, r.q, id, status, decidedBy, note, at)
+}
+
+type pgAdminRepo struct{ q Querier }
+
+func (r pgAdminRepo) IsAdmin(ctx context.Context, userID string) (bool, error) {
+	return IsUserAdmin(ctx, r.q, userID)
+}
+func (r pgAdminRepo) Create(ctx context.Context, a *Admin) error {
+	return CreateAdminTx(ctx, r.q, a)
+}
+func (r pgAdminRepo) GetByUserID(ctx context.Context, userID string) (*Admin, error) {
+	return GetAdminByUserID(ctx, r.q, userID)
+}
+func (r pgAdminRepo) List(ctx context.Context) ([]Admin, error) {
+	return ListAdmin(ctx, r.q)
+}
+func (r pgAdminRepo) Update(ctx context.Context, userID string, a *Admin) error {
+	return UpdateAdmin(ctx, r.q, userID, a)
+}
+func (r pgAdminRepo) Delete(ctx context.Context, userID string) error {
+	return DeleteAdmin(ctx, r.q, userID)
+}
+
+type pgAdminMFARepo struct{ q Querier }
+
+func (r pgAdminMFARepo) Get(ctx context.Context, userID string) (*AdminMFA, error) {
+	return GetAdminMFA(ctx, r.q, userID)
+}
+func (r pgAdminMFARepo) SaveEnrollment(ctx context.Context, userID, secret string) error {
+	return SaveAdminMFAEnrollment(ctx, r.q, userID, secret)
+}
+func (r pgAdminMFARepo) Enable(ctx context.Context, userID string, step int64, at time.Time) error {
+	return EnableAdminMFA(ctx, r.q, userID, step, at)
+}
+func (r pgAdminMFARepo) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	return UseAdminMFAStep(ctx, r.q, userID, step)
+}
+func (r pgAdminMFARepo) Delete(ctx context.Context, userID string) error {
+	return DeleteAdminMFA(ctx, r.q, userID)
+}
+func (r pgAdminMFARepo) ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error {
+	return ReplaceRecoveryCodes(ctx, r.q, userID, hashes)
+}
+func (r pgAdminMFARepo) UseRecoveryCode(ctx context.Context, userID, hash string, at time.Time) (bool, error) {
+	return UseRecoveryCode(ctx, r.q, userID, hash, at)
+}
+func (r pgAdminMFARepo) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	return CountUnusedRecoveryCodes(ctx, r.q, userID)
+}
+func (r pgAdminMFARepo) GetPolicy(ctx context.Context) (*MFAPolicy, error) {
+	return GetMFAPolicy(ctx, r.q)
+}
+func (r pgAdminMFARepo) UpdatePolicy(ctx context.Context, p *MFAPolicy) error {
+	return UpdateMFAPolicy(ctx, r.q, p)
+}
+
+type pgAuditLogRepo struct{ q Querier }
+
+func (r pgAuditLogRepo) Append(ctx context.Context, e *AuditEntry) error {
+	return AppendAuditEntry(ctx, r.q, e)
+}
+func (r pgAuditLogRepo) List(ctx context.Context, f AuditFilter) ([]AuditEntry, error) {
+	return ListAuditEntries(ctx, r.q, f)
+}
+func (r pgAuditLogRepo) Verify(ctx context.Context) (*AuditVerification, error) {
+	return VerifyAuditLog(ctx, r.q)
+}
+
+type pgVehicleRepo struct{ q Querier }
+
+func (r pgVehicleRepo) Create(ctx context.Context, v *Vehicle) error {
+	return CreateVehicleTx(ctx, r.q, v)
+}
+func (r pgVehicleRepo) GetByID(ctx context.Context, id int64) (*Vehicle, error) {
+	return GetVehicleByID(ctx, r.q, id)
+}
+func (r pgVehicleRepo) GetByPlate(ctx context.Context, plateNumber string) (*Vehicle, error) {
+	return GetVehicleByPlate(ctx, r.q, plateNumber)
+}
+func (r pgVehicleRepo) List(ctx context.Context) ([]Vehicle, error) {
+	return ListVehicles(ctx, r.q)
+}
+func (r pgVehicleRepo) ListByUserID(ctx context.Context, userID string) ([]Vehicle, error) {
+	return ListVehiclesByUserID(ctx, r.q, userID)
+}
+func (r pgVehicleRepo) Update(ctx context.Context, id int64, updates map[string]interface{}) error {
+	return UpdateVehicleTx(ctx, r.q, id, updates)
+}
+func (r pgVehicleRepo) SetVerification(ctx context.Context, id int64, status, verifiedBy, note string, at time.Time) error {
+	return SetVehicleVerification(ctx, r.q, id, status, verifiedBy, note, at)
+}
+func (r pgVehicleRepo) SetKKVerification(ctx context.Context, id int64, status, verifiedBy, note string, at time.Time) error {
+	return SetVehicleKKVerification(ctx, r.q, id, status, verifiedBy, note, at)
+}
+func (r pgVehicleRepo) Transfer(ctx context.Context, id int64, fromUserID, toUserID string) ([]int64, error) {
+	return TransferVehicleTx(ctx, r.q, id, fromUserID, toUserID)
+}
+func (r pgVehicleRepo) Delete(ctx context.Context, id int64) error {
+	return DeleteVehicleTx(ctx, r.q, id)
+}
+func (r pgVehicleRepo) Restore(ctx context.Context, id int64) error {
+	return RestoreVehicleTx(ctx, r.q, id)
+}
+func (r pgVehicleRepo) PurgeDeleted(ctx context.Context, before time.Time) (*PurgedRows, error) {
+	return PurgeDeletedVehicles(ctx, r.q, before)
+}
+
+type pgVehicleTransferRepo struct{ q Querier }
+
+func (r pgVehicleTransferRepo) Create(ctx context.Context, t *VehicleTransfer) error {
+	return CreateVehicleTransfer(ctx, r.q, t)
+}
+func (r pgVehicleTransferRepo) GetByID(ctx context.Context, id int64) (*VehicleTransfer, error) {
+	return GetVehicleTransfer(ctx, r.q, id)
+}
+func (r pgVehicleTransferRepo) ListByUserID(ctx context.Context, userID string) ([]VehicleTransfer, error) {
+	return ListVehicleTransfersByUserID(ctx, r.q, userID)
+}
+func (r pgVehicleTransferRepo) ListByVehicleID(ctx context.Context, vehicleID int64) ([]VehicleTransfer, error) {
+	return ListVehicleTransfersByVehicleID(ctx, r.q, vehicleID)
+}
+func (r pgVehicleTransferRepo) Decide(ctx context.Context, id int64, status string, at time.Time) error {
+	return DecideVehicleTransfer(ctx, r.q, id, status, at)
+}
+
+type pgVerificationRepo struct{ q Querier }
+
+func (r pgVerificationRepo) List(ctx context.Context, f VerificationFilter) ([]DocumentVerification, error) {
+	return ListDocumentVerifications(ctx, r.q, f)
+}
+func (r pgVerificationRepo) Count(ctx context.Context, f VerificationFilter) (int, error) {
+	return CountDocumentVerifications(ctx, r.q, f)
+}
+
+type pgDocumentOCRRepo struct{ q Querier }
+
+func (r pgDocumentOCRRepo) Save(ctx context.Context, o *DocumentOCR) error {
+	return SaveDocumentOCR(ctx, r.q, o)
+}
+func (r pgDocumentOCRRepo) GetByImageID(ctx context.Context, imageID int64) (*DocumentOCR, error) {
+	return GetDocumentOCR(ctx, r.q, imageID)
+}
+
+type pgLostReportRepo struct{ q Querier }
+
+func (r pgLostReportRepo) Create(ctx context.Context, lr *LostReport) error {
+	return CreateLostReportTx(ctx, r.q, lr)
+}
+func (r pgLostReportRepo) GetByID(ctx context.Context, id int) (*LostReport, error) {
+	return GetLostReportByID(ctx, r.q, id)
+}
+func (r pgLostReportRepo) GetWithVehicleInfoByID(ctx context.Context, id int) (*LostReportWithVehicleInfo, error) {
+	return GetLostReportWithVehicleInfoByID(ctx, r.q, id)
+}
+func (r pgLostReportRepo) ListWithVehicleInfo(ctx context.Context, statusFilter string) ([]LostReportWithVehicleInfo, error) {
+	return ListLostReportsWithVehicleInfo(ctx, r.q, statusFilter)
+}
+func (r pgLostReportRepo) ListWithVehicleInfoByUserID(ctx context.Context, userID string) ([]LostReportWithVehicleInfo, error) {
+	return ListLostReportsWithVehicleInfoByUserID(ctx, r.q, userID)
+}
+func (r pgLostReportRepo) CountByStatus(ctx context.Context) (map[string]int, error) {
+	return CountLostReportsByStatus(ctx, r.q)
+}
+func (r pgLostReportRepo) ListLocations(ctx context.Context, from, to time.Time) ([]LostReportLocation, error) {
+	return ListLostReportLocations(ctx, r.q, from, to)
+}
+func (r pgLostReportRepo) CountOpenByVehicleID(ctx context.Context, vehicleID int64) (int, error) {
+	return CountOpenLostReportsByVehicleID(ctx, r.q, vehicleID)
+}
+func (r pgLostReportRepo) Update(ctx context.Context, id int, lr *LostReport) error {
+	return UpdateLostReport(ctx, r.q, id, lr)
+}
+func (r pgLostReportRepo) Delete(ctx context.Context, id int) error {
+	return DeleteLostReport(ctx, r.q, id)
+}
+func (r pgLostReportRepo) Restore(ctx context.Context, id int) error {
+	return RestoreLostReport(ctx, r.q, id)
+}
+func (r pgLostReportRepo) PurgeDeleted(ctx context.Context, before time.Time) (*PurgedRows, error) {
+	return PurgeDeletedLostReports(ctx, r.q, before)
+}
+
+type pgDetectedRepo struct{ q Querier }
+
+func (r pgDetectedRepo) Create(ctx context.Context, d *Detected) error {
+	return CreateDetectedTx(ctx, r.q, d)
+}
+func (r pgDetectedRepo) GetByID(ctx context.Context, id int) (*Detected, error) {
+	return GetDetectedByID(ctx, r.q, id)
+}
+func (r pgDetectedRepo) List(ctx context.Context) ([]Detected, error) {
+	return ListDetected(ctx, r.q)
+}
+func (r pgDetectedRepo) ListByTimestampRange(ctx context.Context, startTime, endTime time.Time) ([]Detected, error) {
+	return ListDetectedByTimestampRange(ctx, r.q, startTime, endTime)
+}
+func (r pgDetectedRepo) ListByCoordinates(ctx context.Context, lat, lon, radiusKm float64) ([]Detected, error) {
+	return ListDetectedByCoordinates(ctx, r.q, lat, lon, radiusKm)
+}
+func (r pgDetectedRepo) ListByProximityAndTimestamp(ctx context.Context, lat, lon, radiusKm float64, startTime, endTime time.Time) ([]Detected, error) {
+	return ListDetectedByProximityAndTimestamp(ctx, r.q, lat, lon, radiusKm, startTime, endTime)
+}
+func (r pgDetectedRepo) Update(ctx context.Context, id int, d *Detected) error {
+	return UpdateDetected(ctx, r.q, id, d)
+}
+func (r pgDetectedRepo) Delete(ctx context.Context, id int) error {
+	return DeleteDetectedTx(ctx, r.q, id)
+}
+func (r pgDetectedRepo) SetAttributes(ctx context.Context, id int, a DetectedAttributes) error {
+	return SetDetectedAttributes(ctx, r.q, id, a)
+}
+func (r pgDetectedRepo) Search(ctx context.Context, f DetectionSearchFilter) ([]Detected, error) {
+	return SearchDetected(ctx, r.q, f)
+}
+func (r pgDetectedRepo) CountSearch(ctx context.Context, f DetectionSearchFilter) (int, error) {
+	return CountSearchDetected(ctx, r.q, f)
+}
+
+type pgSuspectRepo struct{ q Querier }
+
+func (r pgSuspectRepo) Create(ctx context.Context, s *Suspect) error {
+	return CreateSuspect(ctx, r.q, s)
+}
+func (r pgSuspectRepo) GetByID(ctx context.Context, id int64) (*Suspect, error) {
+	return GetSuspectByID(ctx, r.q, id)
+}
+func (r pgSuspectRepo) List(ctx context.Context) ([]Suspect, error) {
+	return ListSuspects(ctx, r.q)
+}
+func (r pgSuspectRepo) ListResultsByLostReportID(ctx context.Context, lostReportID int, f SuspectResultFilter) ([]SuspectResult, error) {
+	return GetSuspectsByLostReportID(ctx, r.q, lostReportID, f)
+}
+func (r pgSuspectRepo) CountResultsByLostReportID(ctx context.Context, lostReportID int, f SuspectResultFilter) (int, error) {
+	return CountSuspectsByLostReportID(ctx, r.q, lostReportID, f)
+}
+func (r pgSuspectRepo) Update(ctx context.Context, id int64, s *Suspect) error {
+	return UpdateSuspect(ctx, r.q, id, s)
+}
+func (r pgSuspectRepo) Delete(ctx context.Context, id int64) error {
+	return DeleteSuspect(ctx, r.q, id)
+}
+func (r pgSuspectRepo) Review(ctx context.Context, id int64, status, reviewerID, comment string, at time.Time) error {
+	return ReviewSuspect(ctx, r.q, id, status, reviewerID, comment, at)
+}
+func (r pgSuspectRepo) SetOwnerFeedback(ctx context.Context, id int64, feedback string, at time.Time) error {
+	return SetSuspectOwnerFeedback(ctx, r.q, id, feedback, at)
+}
+
+type pgSuspectLabelRepo struct{ q Querier }
+
+func (r pgSuspectLabelRepo) Create(ctx context.Context, l *SuspectLabel) error {
+	return CreateSuspectLabel(ctx, r.q, l)
+}
+func (r pgSuspectLabelRepo) List(ctx context.Context, f SuspectLabelFilter) ([]SuspectLabel, error) {
+	return ListSuspectLabels(ctx, r.q, f)
+}
+
+type pgAnalysisRunRepo struct{ q Querier }
+
+func (r pgAnalysisRunRepo) Create(ctx context.Context, a *AnalysisRun) error {
+	return CreateAnalysisRun(ctx, r.q, a)
+}
+func (r pgAnalysisRunRepo) GetByID(ctx context.Context, id int64) (*AnalysisRun, error) {
+	return GetAnalysisRun(ctx, r.q, id)
+}
+func (r pgAnalysisRunRepo) GetLatestByLostReportID(ctx context.Context, lostID int) (*AnalysisRun, error) {
+	return GetLatestAnalysisRun(ctx, r.q, lostID)
+}
+func (r pgAnalysisRunRepo) ListByLostReportID(ctx context.Context, lostID int) ([]AnalysisRun, error) {
+	return ListAnalysisRuns(ctx, r.q, lostID)
+}
+func (r pgAnalysisRunRepo) Claim(ctx context.Context, scorerVersion string, at time.Time) (*AnalysisRun, error) {
+	return ClaimAnalysisRun(ctx, r.q, scorerVersion, at)
+}
+func (r pgAnalysisRunRepo) UpdateQueuedScope(ctx context.Context, id int64, p SearchParams) (bool, error) {
+	return UpdateQueuedAnalysisRunScope(ctx, r.q, id, p)
+}
+func (r pgAnalysisRunRepo) Finish(ctx context.Context, id int64, status string, detectionsScanned *int, errMsg string, at time.Time) error {
+	return FinishAnalysisRun(ctx, r.q, id, status, detectionsScanned, errMsg, at)
+}
+
+type pgSearchRepo struct{ q Querier }
+
+func (r pgSearchRepo) GetLimits(ctx context.Context) (*AnalysisLimits, error) {
+	return GetAnalysisLimits(ctx, r.q)
+}
+func (r pgSearchRepo) UpdateLimits(ctx context.Context, l *AnalysisLimits) error {
+	return UpdateAnalysisLimits(ctx, r.q, l)
+}
+func (r pgSearchRepo) GetByLostReportID(ctx context.Context, lostID int) (*LostReportSearch, error) {
+	return GetLostReportSearch(ctx, r.q, lostID)
+}
+func (r pgSearchRepo) Save(ctx context.Context, s *LostReportSearch) error {
+	return SaveLostReportSearch(ctx, r.q, s)
+}
+func (r pgSearchRepo) CountDetectionsByCamera(ctx context.Context, cameraIDs []int64, from, to time.Time) (map[int64]int, error) {
+	return CountDetectionsByCamera(ctx, r.q, cameraIDs, from, to)
+}
+
+type pgImageRepo struct{ q Querier }
+
+func (r pgImageRepo) Create(ctx context.Context, img *Image) error {
+	return CreateImageTx(ctx, r.q, img)
+}
+func (r pgImageRepo) GetByID(ctx context.Context, id int64) (*Image, error) {
+	return GetImageByID(ctx, r.q, id)
+}
+func (r pgImageRepo) GetStoragePath(ctx context.Context, id int64) (string, error) {
+	return GetImageStoragePath(ctx, r.q, id)
+}
+func (r pgImageRepo) UpdateMetadata(ctx context.Context, id int64, newFilenameOriginal string) error {
+	return UpdateImageMetadataTx(ctx, r.q, id, newFilenameOriginal)
+}
+func (r pgImageRepo) Delete(ctx context.Context, id int64) error {
+	return DeleteImageTx(ctx, r.q, id)
+}
+func (r pgImageRepo) GetStoragePathAndDelete(ctx context.Context, id int64) (string, error) {
+	return GetImageStoragePathAndDeleteTx(ctx, r.q, id)
+}
+
+type pgCameraRepo struct{ q Querier }
+
+func (r pgCameraRepo) Create(ctx context.Context, c *Camera) error {
+	return CreateCamera(ctx, r.q, c)
+}
+func (r pgCameraRepo) GetByID(ctx context.Context, id int64) (*Camera, error) {
+	return GetCameraByID(ctx, r.q, id)
+}
+func (r pgCameraRepo) List(ctx context.Context) ([]Camera, error) {
+	return ListCameras(ctx, r.q)
+}
+func (r pgCameraRepo) CountActive(ctx context.Context) (int, error) {
+	return CountActiveCameras(ctx, r.q)
+}
+func (r pgCameraRepo) Update(ctx context.Context, id int64, c *Camera) error {
+	return UpdateCamera(ctx, r.q, id, c)
+}
+func (r pgCameraRepo) Delete(ctx context.Context, id int64) error {
+	return DeleteCamera(ctx, r.q, id)
+}
+func (r pgCameraRepo) Restore(ctx context.Context, id int64) error {
+	return RestoreCamera(ctx, r.q, id)
+}
+func (r pgCameraRepo) PurgeDeleted(ctx context.Context, before time.Time) (*PurgedRows, error) {
+	return PurgeDeletedCameras(ctx, r.q, before)
+}
+
+type pgStatsRepo struct{ q Querier }
+
+func (r pgStatsRepo) Dashboard(ctx context.Context, f StatsFilter) (*DashboardStats, error) {
+	return GetDashboardStats(ctx, r.q, f)
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// PurgedRows adalah hasil penghapusan permanen baris yang sudah di-soft
+// delete. ImageIDs berisi gambar yang dirujuk baris tersebut; record gambar
+// dan filenya dihapus terpisah oleh pemanggil.
+type PurgedRows struct {
+	Count    int
+	ImageIDs []int64
+}
+
+func (p *PurgedRows) addImage(id sql.NullInt64) {
+	if id.Valid {
+		p.ImageIDs = append(p.ImageIDs, id.Int64)
+	}
+}
+
+// scanPurgedRows membaca hasil DELETE ... RETURNING yang setiap kolomnya
+// adalah ID gambar nullable, satu baris per baris yang dihapus.
+func scanPurgedRows(rows *sql.Rows) (*PurgedRows, error) {
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]sql.NullInt64, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range ids {
+		dest[i] = &ids[i]
+	}
+
+	p := &PurgedRows{}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("error scanning purged row: %w", err)
+		}
+		p.Count++
+		for _, id := range ids {
+			p.addImage(id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
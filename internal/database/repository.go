@@ -0,0 +1,285 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// UserRepo mengelola data akun pengguna.
+type UserRepo interface {
+	Create(ctx context.Context, u *User) error
+	FindByEmail(ctx context.Context, email string) (*User, error)
+	FindByID(ctx context.Context, userID string) (*User, error)
+	// FindByNIKHash mencari user berdasarkan blind index NIK (lihat
+	// fieldcrypt.KeyRing.BlindIndex).
+	FindByNIKHash(ctx context.Context, hash string) (*User, error)
+	List(ctx context.Context) ([]User, error)
+	Update(ctx context.Context, userID string, updates map[string]interface{}) error
+	// Delete hanya menandai user terhapus; kendaraan dan laporannya ikut
+	// ditandai. Restore membatalkannya, PurgeDeleted menghapus permanen
+	// user yang ditandai sebelum waktu tertentu.
+	Delete(ctx context.Context, userID string) error
+	Restore(ctx context.Context, userID string) error
+	PurgeDeleted(ctx context.Context, before time.Time) (*PurgedRows, error)
+	// Anonymize menghapus data identitas user beserta kendaraan dan
+	// laporannya, lalu mengembalikan ID gambar pribadi yang harus dihapus
+	// pemanggil. Lihat AnonymizeUserTx.
+	Anonymize(ctx context.Context, userID string) ([]int64, error)
+	// FindByAPIKey mengembalikan pemilik API key beserta ID key-nya, atau
+	// ErrInvalidAPIKey.
+	FindByAPIKey(ctx context.Context, apiKey string) (*User, int64, error)
+	// SetKTPVerification mengembalikan ErrUserNotFound. Lihat
+	// SetUserKTPVerification.
+	SetKTPVerification(ctx context.Context, userID, status, verifiedBy, note string, at time.Time) error
+}
+
+// UserTokenRepo menyimpan token sekali pakai untuk reset password dan
+// verifikasi email.
+type UserTokenRepo interface {
+	Create(ctx context.Context, t *UserToken) error
+	// Consume menandai token terpakai dan mengembalikannya, atau
+	// ErrTokenInvalid.
+	Consume(ctx context.Context, tokenHash, purpose string, now time.Time) (*UserToken, error)
+	// InvalidateForUser menandai semua token aktif user untuk purpose
+	// tersebut sebagai terpakai.
+	InvalidateForUser(ctx context.Context, userID, purpose string, now time.Time) error
+}
+
+// AdminRepo mengelola keanggotaan tabel admins.
+type AdminRepo interface {
+	IsAdmin(ctx context.Context, userID string) (bool, error)
+	Create(ctx context.Context, a *Admin) error
+	GetByUserID(ctx context.Context, userID string) (*Admin, error)
+	List(ctx context.Context) ([]Admin, error)
+	Update(ctx context.Context, userID string, a *Admin) error
+	Delete(ctx context.Context, userID string) error
+}
+
+// AdminMFARepo mengelola TOTP, kode pemulihan, dan kebijakan 2FA admin.
+type AdminMFARepo interface {
+	// Get mengembalikan ErrMFANotFound bila admin belum pernah mendaftar.
+	Get(ctx context.Context, userID string) (*AdminMFA, error)
+	// SaveEnrollment mengembalikan ErrMFAAlreadyEnabled bila 2FA sudah aktif.
+	SaveEnrollment(ctx context.Context, userID, secret string) error
+	Enable(ctx context.Context, userID string, step int64, at time.Time) error
+	UseStep(ctx context.Context, userID string, step int64) (bool, error)
+	Delete(ctx context.Context, userID string) error
+	ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error
+	UseRecoveryCode(ctx context.Context, userID, hash string, at time.Time) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID string) (int, error)
+	GetPolicy(ctx context.Context) (*MFAPolicy, error)
+	UpdatePolicy(ctx context.Context, p *MFAPolicy) error
+}
+
+// ErasureRequestRepo menyimpan permintaan penghapusan data pribadi.
+type ErasureRequestRepo interface {
+	Create(ctx context.Context, e *ErasureRequest) error
+	// GetByID dan GetLatestByUserID mengembalikan ErrErasureRequestNotFound.
+	GetByID(ctx context.Context, id int64) (*ErasureRequest, error)
+	GetLatestByUserID(ctx context.Context, userID string) (*ErasureRequest, error)
+	List(ctx context.Context, status string) ([]ErasureRequest, error)
+	// Decide mengembalikan ErrErasureRequestDecided bila permintaan sudah
+	// tidak PENDING.
+	Decide(ctx context.Context, id int64, status, decidedBy, note string, at time.Time) error
+}
+
+// AuditLogRepo menyimpan jejak audit yang hanya bisa ditambah.
+type AuditLogRepo interface {
+	// Append menyambung entri ke rantai hash. Panggil di dalam transaksi yang
+	// sama dengan perubahan yang dicatat, sebagai penulisan terakhir.
+	Append(ctx context.Context, e *AuditEntry) error
+	List(ctx context.Context, f AuditFilter) ([]AuditEntry, error)
+	Verify(ctx context.Context) (*AuditVerification, error)
+}
+
+// VehicleRepo mengelola kendaraan. Create, Update dan Restore mengembalikan
+// ErrPlateTaken bila plat sudah dipakai kendaraan aktif lain.
+type VehicleRepo interface {
+	Create(ctx context.Context, v *Vehicle) error
+	GetByID(ctx context.Context, id int64) (*Vehicle, error)
+	// GetByPlate mencocokkan plat setelah NormalizePlate.
+	GetByPlate(ctx context.Context, plateNumber string) (*Vehicle, error)
+	List(ctx context.Context) ([]Vehicle, error)
+	ListByUserID(ctx context.Context, userID string) ([]Vehicle, error)
+	Update(ctx context.Context, id int64, updates map[string]interface{}) error
+	SetVerification(ctx context.Context, id int64, status, verifiedBy, note string, at time.Time) error
+	SetKKVerification(ctx context.Context, id int64, status, verifiedBy, note string, at time.Time) error
+	// Transfer mengembalikan ErrVehicleNotFound bila kendaraan sudah tidak
+	// dimiliki fromUserID. Lihat TransferVehicleTx.
+	Transfer(ctx context.Context, id int64, fromUserID, toUserID string) ([]int64, error)
+	Delete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) error
+	PurgeDeleted(ctx context.Context, before time.Time) (*PurgedRows, error)
+}
+
+// VerificationRepo membaca antrean verifikasi dokumen KTP, STNK dan KK.
+// Status dokumen diubah lewat Users.SetKTPVerification,
+// Vehicles.SetVerification dan Vehicles.SetKKVerification.
+type VerificationRepo interface {
+	List(ctx context.Context, f VerificationFilter) ([]DocumentVerification, error)
+	Count(ctx context.Context, f VerificationFilter) (int, error)
+}
+
+// DocumentOCRRepo menyimpan hasil OCR foto KTP dan STNK.
+type DocumentOCRRepo interface {
+	// Save menimpa hasil sebelumnya untuk foto yang sama.
+	Save(ctx context.Context, o *DocumentOCR) error
+	// GetByImageID mengembalikan ErrDocumentOCRNotFound.
+	GetByImageID(ctx context.Context, imageID int64) (*DocumentOCR, error)
+}
+
+// VehicleTransferRepo menyimpan permintaan pindah kepemilikan kendaraan
+// beserta riwayatnya.
+type VehicleTransferRepo interface {
+	// Create mengembalikan ErrVehicleTransferPending bila kendaraan masih
+	// punya permintaan yang belum diputuskan dan belum kedaluwarsa.
+	Create(ctx context.Context, t *VehicleTransfer) error
+	// GetByID mengembalikan ErrVehicleTransferNotFound.
+	GetByID(ctx context.Context, id int64) (*VehicleTransfer, error)
+	ListByUserID(ctx context.Context, userID string) ([]VehicleTransfer, error)
+	ListByVehicleID(ctx context.Context, vehicleID int64) ([]VehicleTransfer, error)
+	// Decide mengembalikan ErrVehicleTransferDecided bila permintaan sudah
+	// tidak PENDING.
+	Decide(ctx context.Context, id int64, status string, at time.Time) error
+}
+
+type LostReportRepo interface {
+	Create(ctx context.Context, lr *LostReport) error
+	GetByID(ctx context.Context, id int) (*LostReport, error)
+	GetWithVehicleInfoByID(ctx context.Context, id int) (*LostReportWithVehicleInfo, error)
+	ListWithVehicleInfo(ctx context.Context, statusFilter string) ([]LostReportWithVehicleInfo, error)
+	ListWithVehicleInfoByUserID(ctx context.Context, userID string) ([]LostReportWithVehicleInfo, error)
+	CountByStatus(ctx context.Context) (map[string]int, error)
+	ListLocations(ctx context.Context, from, to time.Time) ([]LostReportLocation, error)
+	CountOpenByVehicleID(ctx context.Context, vehicleID int64) (int, error)
+	Update(ctx context.Context, id int, lr *LostReport) error
+	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) error
+	PurgeDeleted(ctx context.Context, before time.Time) (*PurgedRows, error)
+}
+
+type DetectedRepo interface {
+	Create(ctx context.Context, d *Detected) error
+	GetByID(ctx context.Context, id int) (*Detected, error)
+	List(ctx context.Context) ([]Detected, error)
+	ListByTimestampRange(ctx context.Context, startTime, endTime time.Time) ([]Detected, error)
+	ListByCoordinates(ctx context.Context, lat, lon, radiusKm float64) ([]Detected, error)
+	ListByProximityAndTimestamp(ctx context.Context, lat, lon, radiusKm float64, startTime, endTime time.Time) ([]Detected, error)
+	Update(ctx context.Context, id int, d *Detected) error
+	Delete(ctx context.Context, id int) error
+	// SetAttributes mengembalikan ErrDetectedNotFound.
+	SetAttributes(ctx context.Context, id int, a DetectedAttributes) error
+	Search(ctx context.Context, f DetectionSearchFilter) ([]Detected, error)
+	CountSearch(ctx context.Context, f DetectionSearchFilter) (int, error)
+}
+
+type SuspectRepo interface {
+	Create(ctx context.Context, s *Suspect) error
+	GetByID(ctx context.Context, id int64) (*Suspect, error)
+	List(ctx context.Context) ([]Suspect, error)
+	// ListResultsByLostReportID mengembalikan suspect beserta data deteksi
+	// dan kamera untuk endpoint hasil analisis.
+	ListResultsByLostReportID(ctx context.Context, lostReportID int, f SuspectResultFilter) ([]SuspectResult, error)
+	CountResultsByLostReportID(ctx context.Context, lostReportID int, f SuspectResultFilter) (int, error)
+	Update(ctx context.Context, id int64, s *Suspect) error
+	Delete(ctx context.Context, id int64) error
+	Review(ctx context.Context, id int64, status, reviewerID, comment string, at time.Time) error
+	SetOwnerFeedback(ctx context.Context, id int64, feedback string, at time.Time) error
+}
+
+type ImageRepo interface {
+	Create(ctx context.Context, img *Image) error
+	GetByID(ctx context.Context, id int64) (*Image, error)
+	// GetStoragePath mengembalikan sql.ErrNoRows jika gambar tidak ada.
+	GetStoragePath(ctx context.Context, id int64) (string, error)
+	UpdateMetadata(ctx context.Context, id int64, newFilenameOriginal string) error
+	Delete(ctx context.Context, id int64) error
+	// GetStoragePathAndDelete menghapus record dan mengembalikan path filenya.
+	// Path kosong berarti gambar sudah tidak ada.
+	GetStoragePathAndDelete(ctx context.Context, id int64) (string, error)
+}
+
+type CameraRepo interface {
+	Create(ctx context.Context, c *Camera) error
+	GetByID(ctx context.Context, id int64) (*Camera, error)
+	List(ctx context.Context) ([]Camera, error)
+	CountActive(ctx context.Context) (int, error)
+	Update(ctx context.Context, id int64, c *Camera) error
+	Delete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) error
+	PurgeDeleted(ctx context.Context, before time.Time) (*PurgedRows, error)
+}
+
+// SuspectLabelRepo menyimpan label review untuk melatih ulang model skor.
+type SuspectLabelRepo interface {
+	Create(ctx context.Context, l *SuspectLabel) error
+	List(ctx context.Context, f SuspectLabelFilter) ([]SuspectLabel, error)
+}
+
+// AnalysisRunRepo mencatat setiap analisis suspect per laporan.
+type AnalysisRunRepo interface {
+	Create(ctx context.Context, a *AnalysisRun) error
+	// GetByID, GetLatestByLostReportID dan Claim mengembalikan
+	// ErrAnalysisRunNotFound.
+	GetByID(ctx context.Context, id int64) (*AnalysisRun, error)
+	GetLatestByLostReportID(ctx context.Context, lostID int) (*AnalysisRun, error)
+	ListByLostReportID(ctx context.Context, lostID int) ([]AnalysisRun, error)
+	Claim(ctx context.Context, scorerVersion string, at time.Time) (*AnalysisRun, error)
+	// UpdateQueuedScope mengembalikan false bila run sudah tidak QUEUED.
+	UpdateQueuedScope(ctx context.Context, id int64, p SearchParams) (bool, error)
+	// Finish mengembalikan ErrAnalysisRunNotRunning bila run tidak RUNNING.
+	Finish(ctx context.Context, id int64, status string, detectionsScanned *int, errMsg string, at time.Time) error
+}
+
+// SearchRepo menyimpan parameter pencarian per laporan dan batasnya.
+type SearchRepo interface {
+	GetLimits(ctx context.Context) (*AnalysisLimits, error)
+	UpdateLimits(ctx context.Context, l *AnalysisLimits) error
+	// GetByLostReportID mengembalikan ErrSearchParamsNotFound.
+	GetByLostReportID(ctx context.Context, lostID int) (*LostReportSearch, error)
+	Save(ctx context.Context, s *LostReportSearch) error
+	CountDetectionsByCamera(ctx context.Context, cameraIDs []int64, from, to time.Time) (map[int64]int, error)
+}
+
+// StatsRepo menghitung agregat untuk dashboard admin di sisi database.
+type StatsRepo interface {
+	Dashboard(ctx context.Context, f StatsFilter) (*DashboardStats, error)
+}
+
+// Repositories mengelompokkan repository per agregat. Nilai ini diperoleh
+// dari Store (operasi langsung) atau dari Tx (operasi di dalam transaksi).
+type Repositories struct {
+	Users         UserRepo
+	UserTokens    UserTokenRepo
+	Admins        AdminRepo
+	AdminMFA      AdminMFARepo
+	AuditLog      AuditLogRepo
+	Erasures      ErasureRequestRepo
+	Vehicles      VehicleRepo
+	Transfers     VehicleTransferRepo
+	Verifications VerificationRepo
+	DocumentOCR   DocumentOCRRepo
+	LostReports   LostReportRepo
+	Detected      DetectedRepo
+	Suspects      SuspectRepo
+	SuspectLabels SuspectLabelRepo
+	AnalysisRuns  AnalysisRunRepo
+	Search        SearchRepo
+	Images        ImageRepo
+	Cameras       CameraRepo
+	Stats         StatsRepo
+}
+
+// Tx adalah unit kerja transaksional. Rollback setelah Commit tidak
+// berpengaruh, sehingga aman dipanggil lewat defer.
+type Tx interface {
+	Repos() Repositories
+	Commit() error
+	Rollback() error
+}
+
+// Store adalah titik masuk penyimpanan yang di-inject ke Server.
+type Store interface {
+	Repos() Repositories
+	BeginTx(ctx context.Context) (Tx, error)
+}
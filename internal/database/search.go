@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MinSearchRadiusKm adalah radius pencarian terkecil yang masih bermakna
+// untuk akurasi GPS kamera.
+const MinSearchRadiusKm = 0.1
+
+// SearchParams menentukan deteksi mana yang diperiksa untuk sebuah laporan:
+// kamera dalam RadiusKm dari lokasi laporan (atau kamera di CameraIDs dan
+// kamera Zones dalam RadiusKm bila diisi) selama WindowHours sejak waktu
+// kehilangan.
+type SearchParams struct {
+	RadiusKm    float64  `json:"search_radius_km"`
+	WindowHours int      `json:"search_window_hours"`
+	CameraIDs   []int64  `json:"camera_ids"`
+	Zones       []string `json:"zones"`
+}
+
+// Restricted bernilai true bila pencarian dibatasi ke kamera atau zona
+// tertentu; kamera di luar pilihan tidak diperiksa walau dalam radius.
+func (p SearchParams) Restricted() bool {
+	return len(p.CameraIDs) > 0 || len(p.Zones) > 0
+}
+
+// Window mengembalikan rentang waktu pencarian [from, to) sejak t.
+func (p SearchParams) Window(t time.Time) (time.Time, time.Time) {
+	return t, t.Add(time.Duration(p.WindowHours) * time.Hour)
+}
+
+// LostReportSearch adalah parameter pencarian yang disimpan untuk laporan.
+type LostReportSearch struct {
+	LostID int `json:"lost_id"`
+	SearchParams
+	UpdatedBy *string   `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AnalysisLimits adalah batas parameter pencarian yang ditetapkan admin.
+type AnalysisLimits struct {
+	MaxRadiusKm    float64   `json:"max_radius_km"`
+	MaxWindowHours int       `json:"max_window_hours"`
+	MaxCameras     int       `json:"max_cameras"`
+	UpdatedBy      *string   `json:"updated_by"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// DefaultAnalysisLimits sama dengan default migrasi.
+func DefaultAnalysisLimits() AnalysisLimits {
+	return AnalysisLimits{MaxRadiusKm: 50, MaxWindowHours: 168, MaxCameras: 50}
+}
+
+// Validate memeriksa parameter terhadap batas. Jumlah kamera hasil
+// pemilihan zona dan radius diperiksa terpisah karena butuh daftar kamera.
+// Pesan error-nya aman ditampilkan ke client.
+func (l AnalysisLimits) Validate(p SearchParams) error {
+	switch {
+	case p.RadiusKm < MinSearchRadiusKm || p.RadiusKm > l.MaxRadiusKm:
+		return fmt.Errorf("search radius must be between %v and %v km", MinSearchRadiusKm, l.MaxRadiusKm)
+	case p.WindowHours < 1 || p.WindowHours > l.MaxWindowHours:
+		return fmt.Errorf("search window must be between 1 and %d hours", l.MaxWindowHours)
+	case len(p.CameraIDs) > l.MaxCameras:
+		return fmt.Errorf("at most %d cameras can be selected", l.MaxCameras)
+	}
+	return nil
+}
+
+func GetAnalysisLimits(ctx context.Context, db Querier) (*AnalysisLimits, error) {
+	var l AnalysisLimits
+	err := db.QueryRowContext(ctx, `SELECT max_radius_km, max_window_hours, max_cameras, updated_by, updated_at FROM analysis_limits WHERE id`).
+		Scan(&l.MaxRadiusKm, &l.MaxWindowHours, &l.MaxCameras, &l.UpdatedBy, &l.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		l = DefaultAnalysisLimits()
+		return &l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting analysis limits: %w", err)
+	}
+	return &l, nil
+}
+
+func UpdateAnalysisLimits(ctx context.Context, db Querier, l *AnalysisLimits) error {
+	return db.QueryRowContext(ctx, `INSERT INTO analysis_limits (id, max_radius_km, max_window_hours, max_cameras, updated_by, updated_at)
+        VALUES (TRUE, $1, $2, $3, $4, NOW())
+        ON CONFLICT (id) DO UPDATE SET max_radius_km = EXCLUDED.max_radius_km, max_window_hours = EXCLUDED.max_window_hours,
+            max_cameras = EXCLUDED.max_cameras, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+        RETURNING updated_at`, l.MaxRadiusKm, l.MaxWindowHours, l.MaxCameras, l.UpdatedBy).Scan(&l.UpdatedAt)
+}
+
+// GetLostReportSearch mengembalikan ErrSearchParamsNotFound bila laporan
+// belum punya parameter sendiri.
+func GetLostReportSearch(ctx context.Context, db Querier, lostID int) (*LostReportSearch, error) {
+	s := LostReportSearch{LostID: lostID}
+	err := db.QueryRowContext(ctx, `SELECT radius_km, window_hours, camera_ids, zones, updated_by, updated_at
+        FROM lost_report_search WHERE lost_id = $1`, lostID).
+		Scan(&s.RadiusKm, &s.WindowHours, pq.Array(&s.CameraIDs), pq.Array(&s.Zones), &s.UpdatedBy, &s.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSearchParamsNotFound
+		}
+		return nil, fmt.Errorf("error getting search parameters of lost report ID %d: %w", lostID, err)
+	}
+	return &s, nil
+}
+
+func SaveLostReportSearch(ctx context.Context, db Querier, s *LostReportSearch) error {
+	if s.UpdatedAt.IsZero() {
+		s.UpdatedAt = time.Now()
+	}
+	_, err := db.ExecContext(ctx, `INSERT INTO lost_report_search (lost_id, radius_km, window_hours, camera_ids, zones, updated_by, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (lost_id) DO UPDATE SET radius_km = EXCLUDED.radius_km, window_hours = EXCLUDED.window_hours,
+            camera_ids = EXCLUDED.camera_ids, zones = EXCLUDED.zones, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at`,
+		s.LostID, s.RadiusKm, s.WindowHours, pq.Array(nonNilInt64s(s.CameraIDs)), pq.Array(nonNilStrings(s.Zones)), s.UpdatedBy, s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("error saving search parameters of lost report ID %d: %w", s.LostID, err)
+	}
+	return nil
+}
+
+// CountDetectionsByCamera menghitung deteksi per kamera dalam [from, to).
+// Kamera tanpa deteksi tidak muncul di map.
+func CountDetectionsByCamera(ctx context.Context, db Querier, cameraIDs []int64, from, to time.Time) (map[int64]int, error) {
+	counts := make(map[int64]int)
+	if len(cameraIDs) == 0 {
+		return counts, nil
+	}
+	rows, err := db.QueryContext(ctx, `SELECT camera_id, COUNT(*) FROM detected
+        WHERE camera_id = ANY($1) AND timestamp >= $2 AND timestamp < $3
+        GROUP BY camera_id`, pq.Array(cameraIDs), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error counting detections by camera: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, fmt.Errorf("error scanning detection count: %w", err)
+		}
+		counts[id] = n
+	}
+	return counts, rows.Err()
+}
+
+// nonNil* menjaga agar kolom array NOT NULL terisi '{}' alih-alih NULL.
+func nonNilInt64s(s []int64) []int64 {
+	if s == nil {
+		return []int64{}
+	}
+	return s
+}
+
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,293 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	StatsBucketDay  = "day"
+	StatsBucketWeek = "week"
+)
+
+// StatsScoreBuckets adalah jumlah kelas histogram final_score (lebar 0.1).
+const StatsScoreBuckets = 10
+
+// StatsFilter membatasi agregat dashboard admin ke rentang [From, To).
+// Zone kosong berarti semua zona kamera. Deteksi dan suspect difilter
+// lewat zona kameranya; laporan kehilangan lewat suspect yang terdeteksi
+// di zona tersebut. Registrasi user tidak terikat zona.
+type StatsFilter struct {
+	From         time.Time
+	To           time.Time
+	Zone         string
+	Bucket       string
+	TopLocations int
+}
+
+type StatusBucket struct {
+	Start  time.Time      `json:"start"`
+	Counts map[string]int `json:"counts"`
+}
+
+type CountBucket struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+type CameraHourCount struct {
+	CameraID   int64     `json:"camera_id"`
+	CameraName string    `json:"camera_name"`
+	Zone       string    `json:"zone"`
+	Hour       time.Time `json:"hour"`
+	Count      int       `json:"count"`
+}
+
+type ResolutionStats struct {
+	// FoundReports adalah laporan yang menjadi SUDAH_DITEMUKAN di rentang
+	// filter; MeanHours nil bila tidak ada.
+	FoundReports int      `json:"found_reports"`
+	MeanHours    *float64 `json:"mean_hours"`
+}
+
+type ScoreBucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+type SuspectStats struct {
+	Reports           int           `json:"reports"`
+	Suspects          int           `json:"suspects"`
+	MeanPerReport     float64       `json:"mean_per_report"`
+	MaxPerReport      int           `json:"max_per_report"`
+	ScoreDistribution []ScoreBucket `json:"score_distribution"`
+}
+
+// LocationCount mengelompokkan laporan per sel grid 0.01 derajat (sekitar
+// 1 km).
+type LocationCount struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Count     int     `json:"count"`
+}
+
+type DashboardStats struct {
+	LostReportsByStatus []StatusBucket    `json:"lost_reports_by_status"`
+	TimeToFound         ResolutionStats   `json:"time_to_found"`
+	DetectionsPerCamera []CameraHourCount `json:"detections_per_camera_hour"`
+	Suspects            SuspectStats      `json:"suspects"`
+	TopTheftLocations   []LocationCount   `json:"top_theft_locations"`
+	NewUsers            []CountBucket     `json:"new_users"`
+}
+
+// TruncateBucket membulatkan t (UTC) ke awal hari atau minggu (Senin),
+// sama dengan date_trunc di Postgres.
+func TruncateBucket(t time.Time, bucket string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if bucket == StatsBucketWeek {
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	}
+	return day
+}
+
+// ScoreBucketIndex memetakan final_score ke kelas histogram; skor di luar
+// 0..1 masuk kelas pertama atau terakhir.
+func ScoreBucketIndex(score float64) int {
+	i := int(math.Floor(score * StatsScoreBuckets))
+	if i < 0 {
+		return 0
+	}
+	if i >= StatsScoreBuckets {
+		return StatsScoreBuckets - 1
+	}
+	return i
+}
+
+// NewSuspectStats melengkapi rata-rata dan batas kelas histogram skor.
+func NewSuspectStats(reports, suspects, maxPerReport int, histogram [StatsScoreBuckets]int) SuspectStats {
+	s := SuspectStats{Reports: reports, Suspects: suspects, MaxPerReport: maxPerReport}
+	if s.Reports > 0 {
+		s.MeanPerReport = float64(s.Suspects) / float64(s.Reports)
+	}
+	for i, n := range histogram {
+		s.ScoreDistribution = append(s.ScoreDistribution, ScoreBucket{
+			Min: float64(i) / StatsScoreBuckets, Max: float64(i+1) / StatsScoreBuckets, Count: n,
+		})
+	}
+	return s
+}
+
+// zoneReportCond membatasi laporan (alias lr) ke yang punya suspect dari
+// kamera di zona $n.
+func zoneReportCond(n int) string {
+	return fmt.Sprintf(`($%[1]d = '' OR EXISTS (SELECT 1 FROM suspect zs
+        JOIN detected zd ON zd.detected_id = zs.detected_id
+        JOIN cameras zc ON zc.camera_id = zd.camera_id
+        WHERE zs.lost_id = lr.lost_id AND zc.zone = $%[1]d))`, n)
+}
+
+// GetDashboardStats menjalankan semua agregat dashboard admin. Waktu bucket
+// dihitung dalam UTC.
+func GetDashboardStats(ctx context.Context, db Querier, f StatsFilter) (*DashboardStats, error) {
+	stats := &DashboardStats{}
+
+	rows, err := db.QueryContext(ctx, `SELECT date_trunc($1, lr.created_at AT TIME ZONE 'UTC'), lr.status, COUNT(*)
+        FROM lost_report lr
+        WHERE lr.deleted_at IS NULL AND lr.created_at >= $2 AND lr.created_at < $3 AND `+zoneReportCond(4)+`
+        GROUP BY 1, 2 ORDER BY 1, 2`, f.Bucket, f.From, f.To, f.Zone)
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating lost reports by status: %w", err)
+	}
+	byStart := map[time.Time]int{}
+	for rows.Next() {
+		var start time.Time
+		var status string
+		var n int
+		if err := rows.Scan(&start, &status, &n); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		start = utcWallClock(start)
+		i, ok := byStart[start]
+		if !ok {
+			i = len(stats.LostReportsByStatus)
+			byStart[start] = i
+			stats.LostReportsByStatus = append(stats.LostReportsByStatus, StatusBucket{Start: start, Counts: map[string]int{}})
+		}
+		stats.LostReportsByStatus[i].Counts[status] = n
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var meanSeconds float64
+	err = db.QueryRowContext(ctx, `SELECT COUNT(*), COALESCE(AVG(EXTRACT(EPOCH FROM (lr.found_at - lr.created_at))), 0)
+        FROM lost_report lr
+        WHERE lr.deleted_at IS NULL AND lr.found_at >= $1 AND lr.found_at < $2 AND `+zoneReportCond(3),
+		f.From, f.To, f.Zone).Scan(&stats.TimeToFound.FoundReports, &meanSeconds)
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating time to found: %w", err)
+	}
+	if stats.TimeToFound.FoundReports > 0 {
+		hours := meanSeconds / 3600
+		stats.TimeToFound.MeanHours = &hours
+	}
+
+	rows, err = db.QueryContext(ctx, `SELECT c.camera_id, c.name, c.zone, date_trunc('hour', d.timestamp AT TIME ZONE 'UTC'), COUNT(*)
+        FROM detected d JOIN cameras c ON c.camera_id = d.camera_id
+        WHERE d.timestamp >= $1 AND d.timestamp < $2 AND ($3 = '' OR c.zone = $3)
+        GROUP BY 1, 2, 3, 4 ORDER BY 4, 1`, f.From, f.To, f.Zone)
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating detections per camera: %w", err)
+	}
+	for rows.Next() {
+		var c CameraHourCount
+		if err := rows.Scan(&c.CameraID, &c.CameraName, &c.Zone, &c.Hour, &c.Count); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		c.Hour = utcWallClock(c.Hour)
+		stats.DetectionsPerCamera = append(stats.DetectionsPerCamera, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	const zoneSuspects = `FROM suspect s
+        JOIN lost_report lr ON lr.lost_id = s.lost_id AND lr.deleted_at IS NULL
+        JOIN detected d ON d.detected_id = s.detected_id
+        JOIN cameras c ON c.camera_id = d.camera_id
+        WHERE s.created_at >= $1 AND s.created_at < $2 AND ($3 = '' OR c.zone = $3)`
+	var reports, suspects, maxPerReport int
+	err = db.QueryRowContext(ctx, `SELECT COUNT(*), COALESCE(SUM(n), 0), COALESCE(MAX(n), 0)
+        FROM (SELECT s.lost_id, COUNT(*) AS n `+zoneSuspects+` GROUP BY s.lost_id) per_report`,
+		f.From, f.To, f.Zone).Scan(&reports, &suspects, &maxPerReport)
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating suspects per report: %w", err)
+	}
+	rows, err = db.QueryContext(ctx, fmt.Sprintf(`SELECT LEAST(GREATEST(FLOOR(s.final_score * %[1]d), 0), %[1]d - 1)::int, COUNT(*) `+zoneSuspects+`
+        GROUP BY 1`, StatsScoreBuckets), f.From, f.To, f.Zone)
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating suspect scores: %w", err)
+	}
+	var histogram [StatsScoreBuckets]int
+	for rows.Next() {
+		var i, n int
+		if err := rows.Scan(&i, &n); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		histogram[i] = n
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	stats.Suspects = NewSuspectStats(reports, suspects, maxPerReport, histogram)
+
+	rows, err = db.QueryContext(ctx, `SELECT ROUND(lr.latitude::numeric, 2)::double precision, ROUND(lr.longitude::numeric, 2)::double precision, COUNT(*)
+        FROM lost_report lr
+        WHERE lr.deleted_at IS NULL AND lr.latitude IS NOT NULL AND lr.longitude IS NOT NULL
+            AND lr.timestamp >= $1 AND lr.timestamp < $2 AND `+zoneReportCond(3)+`
+        GROUP BY 1, 2 ORDER BY 3 DESC, 1, 2 LIMIT $4`, f.From, f.To, f.Zone, f.TopLocations)
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating theft locations: %w", err)
+	}
+	for rows.Next() {
+		var l LocationCount
+		if err := rows.Scan(&l.Latitude, &l.Longitude, &l.Count); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stats.TopTheftLocations = append(stats.TopTheftLocations, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `SELECT date_trunc($1, created_at AT TIME ZONE 'UTC'), COUNT(*)
+        FROM users WHERE deleted_at IS NULL AND created_at >= $2 AND created_at < $3
+        GROUP BY 1 ORDER BY 1`, f.Bucket, f.From, f.To)
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating new users: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var b CountBucket
+		if err := rows.Scan(&b.Start, &b.Count); err != nil {
+			return nil, err
+		}
+		b.Start = utcWallClock(b.Start)
+		stats.NewUsers = append(stats.NewUsers, b)
+	}
+	return stats, rows.Err()
+}
+
+// utcWallClock menafsirkan nilai TIMESTAMP tanpa zona (hasil date_trunc
+// atas AT TIME ZONE 'UTC') sebagai waktu UTC.
+func utcWallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// SortLocationCounts mengurutkan lokasi dari laporan terbanyak, sama
+// dengan ORDER BY di GetDashboardStats.
+func SortLocationCounts(list []LocationCount) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		if list[i].Latitude != list[j].Latitude {
+			return list[i].Latitude < list[j].Latitude
+		}
+		return list[i].Longitude < list[j].Longitude
+	})
+}
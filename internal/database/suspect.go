@@ -16,43 +16,103 @@ type Suspect struct {
 	MotorScore  float64   `json:"motor_score"`
 	FinalScore  float64   `json:"final_score"`
 	CreatedAt   time.Time `json:"created_at"`
+
+	// Review diisi lewat ReviewSuspect dan SetSuspectOwnerFeedback, bukan
+	// lewat Create atau Update.
+	ReviewStatus    string     `json:"review_status"`
+	ReviewedBy      *string    `json:"reviewed_by,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"`
+	ReviewComment   string     `json:"review_comment,omitempty"`
+	OwnerFeedback   *string    `json:"owner_feedback,omitempty"`
+	OwnerFeedbackAt *time.Time `json:"owner_feedback_at,omitempty"`
 }
 
+const suspectColumns = `suspect_id, detected_id, lost_id, person_score, motor_score, final_score, created_at,
+        review_status, reviewed_by, reviewed_at, review_comment, owner_feedback, owner_feedback_at`
+
+func scanSuspect(row interface{ Scan(...interface{}) error }) (*Suspect, error) {
+	var s Suspect
+	if err := row.Scan(&s.SuspectID, &s.DetectedID, &s.LostID, &s.PersonScore, &s.MotorScore, &s.FinalScore, &s.CreatedAt,
+		&s.ReviewStatus, &s.ReviewedBy, &s.ReviewedAt, &s.ReviewComment, &s.OwnerFeedback, &s.OwnerFeedbackAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// resetReview menyamakan struct dengan nilai default kolom review setelah
+// insert.
+func (s *Suspect) resetReview() {
+	s.ReviewStatus = SuspectReviewPending
+	s.ReviewedBy, s.ReviewedAt, s.ReviewComment = nil, nil, ""
+	s.OwnerFeedback, s.OwnerFeedbackAt = nil, nil
+}
+
+// SuspectResult adalah satu suspect beserta deteksi, kamera dan gambar
+// buktinya untuk endpoint hasil analisis.
 type SuspectResult struct {
 	SuspectID         int64
+	DetectedID        int64
 	PersonScore       float64
 	MotorScore        float64
 	FinalScore        float64
 	DetectedTimestamp time.Time
-	EvidenceImagePath sql.NullString
+	PersonImagePath   sql.NullString
+	MotorImagePath    sql.NullString
 	CameraID          int64
 	CameraName        string
 	CameraLatitude    float64
 	CameraLongitude   float64
+	ReviewStatus      string
+	OwnerFeedback     *string
 }
 
-func GetSuspectsByLostReportID(ctx context.Context, db *sql.DB, lostReportID int) ([]SuspectResult, error) {
+// SuspectResultFilter: Limit 0 berarti tanpa batas.
+type SuspectResultFilter struct {
+	MinScore float64
+	Limit    int
+	Offset   int
+}
+
+// suspectResultFrom dipakai bersama oleh query daftar dan hitungan agar
+// total selalu sesuai dengan baris yang bisa dikembalikan.
+const suspectResultFrom = `
+        FROM suspect s
+        JOIN detected d ON s.detected_id = d.detected_id
+        JOIN cameras c ON d.camera_id = c.camera_id`
+
+const suspectResultWhere = `
+        WHERE s.lost_id = $1 AND s.final_score >= $2`
+
+// GetSuspectsByLostReportID mengembalikan satu baris per suspect, diurutkan
+// dari final_score tertinggi. Gambar orang dan motor di-join terpisah agar
+// jenisnya tidak perlu ditebak dari nama file.
+func GetSuspectsByLostReportID(ctx context.Context, db Querier, lostReportID int, f SuspectResultFilter) ([]SuspectResult, error) {
+	var limit interface{}
+	if f.Limit > 0 {
+		limit = f.Limit
+	}
 	query := `
         SELECT
             s.suspect_id,
+            s.detected_id,
             s.person_score,
             s.motor_score,
             s.final_score,
             d.timestamp,
-            img.storage_path,
             c.camera_id,
             c.name,
             c.latitude,
-            c.longitude
-        FROM suspect s
-        JOIN detected d ON s.detected_id = d.detected_id
-        JOIN cameras c ON d.camera_id = c.camera_id
-        LEFT JOIN images img ON d.person_image_id = img.image_id OR d.motorcycle_image_id = img.image_id
-        WHERE s.lost_id = $1
-        ORDER BY s.final_score DESC;
-    `
+            c.longitude,
+            s.review_status,
+            s.owner_feedback,
+            pi.storage_path,
+            mi.storage_path` + suspectResultFrom + `
+        LEFT JOIN images pi ON pi.image_id = d.person_image_id
+        LEFT JOIN images mi ON mi.image_id = d.motorcycle_image_id` + suspectResultWhere + `
+        ORDER BY s.final_score DESC, s.suspect_id
+        LIMIT $3 OFFSET $4`
 
-	rows, err := db.QueryContext(ctx, query, lostReportID)
+	rows, err := db.QueryContext(ctx, query, lostReportID, f.MinScore, limit, f.Offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query suspects for lost report id %d: %w", lostReportID, err)
 	}
@@ -63,15 +123,19 @@ func GetSuspectsByLostReportID(ctx context.Context, db *sql.DB, lostReportID int
 		var res SuspectResult
 		if err := rows.Scan(
 			&res.SuspectID,
+			&res.DetectedID,
 			&res.PersonScore,
 			&res.MotorScore,
 			&res.FinalScore,
 			&res.DetectedTimestamp,
-			&res.EvidenceImagePath,
 			&res.CameraID,
 			&res.CameraName,
 			&res.CameraLatitude,
 			&res.CameraLongitude,
+			&res.ReviewStatus,
+			&res.OwnerFeedback,
+			&res.PersonImagePath,
+			&res.MotorImagePath,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan suspect row: %w", err)
 		}
@@ -85,19 +149,34 @@ func GetSuspectsByLostReportID(ctx context.Context, db *sql.DB, lostReportID int
 	return results, nil
 }
 
-func CreateSuspect(ctx context.Context, db *sql.DB, s *Suspect) error {
+// CountSuspectsByLostReportID menghitung suspect yang lolos filter tanpa
+// memperhatikan Limit dan Offset.
+func CountSuspectsByLostReportID(ctx context.Context, db Querier, lostReportID int, f SuspectResultFilter) (int, error) {
+	var n int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*)`+suspectResultFrom+suspectResultWhere, lostReportID, f.MinScore).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count suspects for lost report id %d: %w", lostReportID, err)
+	}
+	return n, nil
+}
+
+func CreateSuspect(ctx context.Context, db Querier, s *Suspect) error {
 	query := `INSERT INTO suspect (detected_id, lost_id, person_score, motor_score, final_score, created_at)
               VALUES ($1, $2, $3, $4, $5, $6) RETURNING suspect_id`
-	return db.QueryRowContext(ctx, query, s.DetectedID, s.LostID, s.PersonScore, s.MotorScore, s.FinalScore, s.CreatedAt).Scan(&s.SuspectID)
+	if err := db.QueryRowContext(ctx, query, s.DetectedID, s.LostID, s.PersonScore, s.MotorScore, s.FinalScore, s.CreatedAt).Scan(&s.SuspectID); err != nil {
+		return err
+	}
+	s.resetReview()
+	return nil
 }
 
-func CreateSuspectTx(ctx context.Context, tx *sql.Tx, s *Suspect) error {
-    query := `INSERT INTO suspects (lost_id, detected_id, person_score, motor_score, final_score, created_at)
+func CreateSuspectTx(ctx context.Context, tx Querier, s *Suspect) error {
+    query := `INSERT INTO suspect (lost_id, detected_id, person_score, motor_score, final_score, created_at)
               VALUES ($1, $2, $3, $4, $5, $6) RETURNING suspect_id`
     err := tx.QueryRowContext(ctx, query, s.LostID, s.DetectedID, s.PersonScore, s.MotorScore, s.FinalScore, s.CreatedAt).Scan(&s.SuspectID)
     if err != nil {
         return fmt.Errorf("error creating suspect in transaction: %w", err)
     }
+    s.resetReview()
     return nil
 }
 
@@ -128,21 +207,19 @@ func CreateManySuspects(ctx context.Context, db *sql.DB, suspects []*Suspect) er
     return tx.Commit()
 }
 
-func GetSuspectByID(ctx context.Context, db *sql.DB, id int64) (*Suspect, error) {
-	var s Suspect
-	query := `SELECT suspect_id, detected_id, lost_id, person_score, motor_score, final_score, created_at FROM suspect WHERE suspect_id = $1`
-	err := db.QueryRowContext(ctx, query, id).Scan(&s.SuspectID, &s.DetectedID, &s.LostID, &s.PersonScore, &s.MotorScore, &s.FinalScore, &s.CreatedAt)
+func GetSuspectByID(ctx context.Context, db Querier, id int64) (*Suspect, error) {
+	s, err := scanSuspect(db.QueryRowContext(ctx, `SELECT `+suspectColumns+` FROM suspect WHERE suspect_id = $1`, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("suspect not found")
+			return nil, ErrSuspectNotFound
 		}
 		return nil, err
 	}
-	return &s, nil
+	return s, nil
 }
 
-func ListSuspects(ctx context.Context, db *sql.DB) ([]Suspect, error) {
-	query := `SELECT suspect_id, detected_id, lost_id, person_score, motor_score, final_score, created_at FROM suspect ORDER BY created_at DESC`
+func ListSuspects(ctx context.Context, db Querier) ([]Suspect, error) {
+	query := `SELECT ` + suspectColumns + ` FROM suspect ORDER BY created_at DESC`
 	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -151,16 +228,16 @@ func ListSuspects(ctx context.Context, db *sql.DB) ([]Suspect, error) {
 
 	var list []Suspect
 	for rows.Next() {
-		var s Suspect
-		if err := rows.Scan(&s.SuspectID, &s.DetectedID, &s.LostID, &s.PersonScore, &s.MotorScore, &s.FinalScore, &s.CreatedAt); err != nil {
+		s, err := scanSuspect(rows)
+		if err != nil {
 			return nil, err
 		}
-		list = append(list, s)
+		list = append(list, *s)
 	}
 	return list, nil
 }
 
-func UpdateSuspect(ctx context.Context, db *sql.DB, id int64, s *Suspect) error {
+func UpdateSuspect(ctx context.Context, db Querier, id int64, s *Suspect) error {
 	query := `UPDATE suspect SET detected_id=$1, lost_id=$2, person_score=$3, motor_score=$4, final_score=$5 WHERE suspect_id=$6`
 	res, err := db.ExecContext(ctx, query, s.DetectedID, s.LostID, s.PersonScore, s.MotorScore, s.FinalScore, id)
 	if err != nil {
@@ -173,7 +250,7 @@ func UpdateSuspect(ctx context.Context, db *sql.DB, id int64, s *Suspect) error
 	return err
 }
 
-func DeleteSuspect(ctx context.Context, db *sql.DB, id int64) error {
+func DeleteSuspect(ctx context.Context, db Querier, id int64) error {
 	res, err := db.ExecContext(ctx, `DELETE FROM suspect WHERE suspect_id = $1`, id)
 	if err != nil {
 		return err
@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	SuspectReviewPending   = "PENDING"
+	SuspectReviewConfirmed = "CONFIRMED"
+	SuspectReviewRejected  = "REJECTED"
+
+	OwnerFeedbackMine    = "MINE"
+	OwnerFeedbackNotMine = "NOT_MINE"
+
+	SuspectLabelSourceAdmin = "ADMIN"
+	SuspectLabelSourceOwner = "OWNER"
+)
+
+// ReviewSuspect menyimpan keputusan admin atas sebuah suspect.
+func ReviewSuspect(ctx context.Context, db Querier, id int64, status, reviewerID, comment string, at time.Time) error {
+	res, err := db.ExecContext(ctx, `UPDATE suspect SET review_status = $1, reviewed_by = $2, reviewed_at = $3, review_comment = $4
+        WHERE suspect_id = $5`, status, reviewerID, at, comment, id)
+	if err != nil {
+		return fmt.Errorf("error reviewing suspect ID %d: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrSuspectNotFound
+	}
+	return nil
+}
+
+// SetSuspectOwnerFeedback menyimpan jawaban pemilik laporan (MINE atau
+// NOT_MINE); review_status tetap diputuskan admin.
+func SetSuspectOwnerFeedback(ctx context.Context, db Querier, id int64, feedback string, at time.Time) error {
+	res, err := db.ExecContext(ctx, `UPDATE suspect SET owner_feedback = $1, owner_feedback_at = $2 WHERE suspect_id = $3`, feedback, at, id)
+	if err != nil {
+		return fmt.Errorf("error saving owner feedback for suspect ID %d: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrSuspectNotFound
+	}
+	return nil
+}
+
+// SuspectLabel adalah satu label review atau feedback untuk melatih ulang
+// model skor. Path gambar hanya diisi saat ekspor dan kosong bila gambarnya
+// sudah di-purge.
+type SuspectLabel struct {
+	LabelID             int64     `json:"label_id"`
+	SuspectID           int64     `json:"suspect_id"`
+	LostID              int64     `json:"lost_id"`
+	DetectedID          int64     `json:"detected_id"`
+	PersonImageID       *int64    `json:"person_image_id,omitempty"`
+	MotorcycleImageID   *int64    `json:"motorcycle_image_id,omitempty"`
+	PersonImagePath     string    `json:"person_image_path,omitempty"`
+	MotorcycleImagePath string    `json:"motorcycle_image_path,omitempty"`
+	PersonScore         float64   `json:"person_score"`
+	MotorScore          float64   `json:"motor_score"`
+	FinalScore          float64   `json:"final_score"`
+	Source              string    `json:"source"`
+	Label               string    `json:"label"`
+	LabeledBy           *string   `json:"labeled_by,omitempty"`
+	Comment             string    `json:"comment,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// IsMatch menerjemahkan label menjadi target training; nil untuk review
+// yang dikembalikan ke PENDING.
+func (l SuspectLabel) IsMatch() *bool {
+	var match bool
+	switch l.Label {
+	case SuspectReviewConfirmed, OwnerFeedbackMine:
+		match = true
+	case SuspectReviewRejected, OwnerFeedbackNotMine:
+		match = false
+	default:
+		return nil
+	}
+	return &match
+}
+
+type SuspectLabelFilter struct {
+	Source string
+	From   *time.Time
+	To     *time.Time
+}
+
+// Match dipakai implementasi in-memory; Postgres memakai klausa WHERE yang
+// setara.
+func (f SuspectLabelFilter) Match(l *SuspectLabel) bool {
+	switch {
+	case f.Source != "" && l.Source != f.Source:
+		return false
+	case f.From != nil && l.CreatedAt.Before(*f.From):
+		return false
+	case f.To != nil && !l.CreatedAt.Before(*f.To):
+		return false
+	}
+	return true
+}
+
+// CreateSuspectLabel menyalin skor dan gambar deteksi dari suspect saat
+// ini. Cukup isi SuspectID, Source, Label, LabeledBy dan Comment.
+func CreateSuspectLabel(ctx context.Context, db Querier, l *SuspectLabel) error {
+	if l.CreatedAt.IsZero() {
+		l.CreatedAt = time.Now()
+	}
+	err := db.QueryRowContext(ctx, `INSERT INTO suspect_labels (suspect_id, lost_id, detected_id, person_image_id, motorcycle_image_id,
+            person_score, motor_score, final_score, source, label, labeled_by, comment, created_at)
+        SELECT s.suspect_id, s.lost_id, s.detected_id, d.person_image_id, d.motorcycle_image_id,
+            s.person_score, s.motor_score, s.final_score, $2, $3, $4, $5, $6
+        FROM suspect s JOIN detected d ON d.detected_id = s.detected_id
+        WHERE s.suspect_id = $1
+        RETURNING label_id, lost_id, detected_id, person_image_id, motorcycle_image_id, person_score, motor_score, final_score`,
+		l.SuspectID, l.Source, l.Label, l.LabeledBy, l.Comment, l.CreatedAt).Scan(
+		&l.LabelID, &l.LostID, &l.DetectedID, &l.PersonImageID, &l.MotorcycleImageID, &l.PersonScore, &l.MotorScore, &l.FinalScore)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSuspectNotFound
+		}
+		return fmt.Errorf("error creating suspect label: %w", err)
+	}
+	return nil
+}
+
+// ListSuspectLabels mengembalikan label terlama lebih dulu beserta path
+// gambar deteksi yang masih ada.
+func ListSuspectLabels(ctx context.Context, db Querier, f SuspectLabelFilter) ([]SuspectLabel, error) {
+	var conds []string
+	var args []interface{}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.Source != "" {
+		add("l.source = $%d", f.Source)
+	}
+	if f.From != nil {
+		add("l.created_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("l.created_at < $%d", *f.To)
+	}
+	query := `SELECT l.label_id, l.suspect_id, l.lost_id, l.detected_id, l.person_image_id, l.motorcycle_image_id,
+            COALESCE(pi.storage_path, ''), COALESCE(mi.storage_path, ''), l.person_score, l.motor_score, l.final_score,
+            l.source, l.label, l.labeled_by, l.comment, l.created_at
+        FROM suspect_labels l
+        LEFT JOIN images pi ON pi.image_id = l.person_image_id
+        LEFT JOIN images mi ON mi.image_id = l.motorcycle_image_id`
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	rows, err := db.QueryContext(ctx, query+" ORDER BY l.label_id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing suspect labels: %w", err)
+	}
+	defer rows.Close()
+
+	var labels []SuspectLabel
+	for rows.Next() {
+		var l SuspectLabel
+		if err := rows.Scan(&l.LabelID, &l.SuspectID, &l.LostID, &l.DetectedID, &l.PersonImageID, &l.MotorcycleImageID,
+			&l.PersonImagePath, &l.MotorcycleImagePath, &l.PersonScore, &l.MotorScore, &l.FinalScore,
+			&l.Source, &l.Label, &l.LabeledBy, &l.Comment, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning suspect label: %w", err)
+		}
+		labels = append(labels, l)
+	}
+	return labels, rows.Err()
+}
@@ -16,22 +16,31 @@ type User struct {
 	Phone      string    `json:"phone"`
 	Password   string    `json:"password"` 
 	NIK        string    `json:"nik"`
+	// NIKHash adalah blind index NIK, diisi oleh WithFieldEncryption.
+	NIKHash    string    `json:"-"`
 	KTPImageID *int64    `json:"ktp_image_id,omitempty"`
 	CreatedAt  time.Time `json:"created_at"`
+	// EmailVerifiedAt nil berarti email belum diverifikasi.
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	KTPVerification
 }
 
-func CreateUserTx(ctx context.Context, tx *sql.Tx, u *User) error {
+func CreateUserTx(ctx context.Context, tx Querier, u *User) error {
     query := `
-        INSERT INTO users (user_id, name, email, phone, password, nik, ktp_image_id, created_at)
-        VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`
+        INSERT INTO users (user_id, name, email, phone, password, nik, nik_hash, ktp_image_id, created_at, email_verified_at, ktp_verification_status)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`
 
     var ktpImage sql.NullInt64
     if u.KTPImageID != nil {
         ktpImage = sql.NullInt64{Int64: *u.KTPImageID, Valid: true}
     }
 
+    if u.KTPVerificationStatus == "" {
+        u.KTPVerificationStatus = VerificationUnverified
+    }
+
     _, err := tx.ExecContext(ctx, query,
-        u.UserID, u.Name, u.Email, u.Phone, u.Password, u.NIK, ktpImage, u.CreatedAt,
+        u.UserID, u.Name, u.Email, u.Phone, u.Password, u.NIK, u.NIKHash, ktpImage, u.CreatedAt, u.EmailVerifiedAt, u.KTPVerificationStatus,
     )
     return err
 }
@@ -44,8 +53,8 @@ func CreateManyUser(db *sql.DB, users []User, ctx context.Context) error {
 	defer tx.Rollback() 
 
 	stmt, err := tx.PrepareContext(ctx, `
-        INSERT INTO users (user_id, name, email, phone, password, nik, ktp_image_id, created_at)
-        VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`) 
+        INSERT INTO users (user_id, name, email, phone, password, nik, nik_hash, ktp_image_id, created_at, email_verified_at, ktp_verification_status)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`) 
 	if err != nil {
 		return err
 	}
@@ -56,8 +65,11 @@ func CreateManyUser(db *sql.DB, users []User, ctx context.Context) error {
 		if users[i].KTPImageID != nil {
 			ktpImage = sql.NullInt64{Int64: *users[i].KTPImageID, Valid: true}
 		}
+		if users[i].KTPVerificationStatus == "" {
+			users[i].KTPVerificationStatus = VerificationUnverified
+		}
 		_, err := stmt.ExecContext(ctx,
-			users[i].UserID, users[i].Name, users[i].Email, users[i].Phone, users[i].Password, users[i].NIK, ktpImage, users[i].CreatedAt,
+			users[i].UserID, users[i].Name, users[i].Email, users[i].Phone, users[i].Password, users[i].NIK, users[i].NIKHash, ktpImage, users[i].CreatedAt, users[i].EmailVerifiedAt, users[i].KTPVerificationStatus,
 		)
 		if err != nil {
 			return err
@@ -66,36 +78,61 @@ func CreateManyUser(db *sql.DB, users []User, ctx context.Context) error {
 	return tx.Commit()
 }
 
-func FindSingleUser(db *sql.DB, email string, ctx context.Context) (*User, error) {
-	q := `SELECT user_id, name, email, phone, password, nik, ktp_image_id, created_at FROM users WHERE email = $1 LIMIT 1`
+func FindSingleUser(db Querier, email string, ctx context.Context) (*User, error) {
+	q := `SELECT user_id, name, email, phone, password, nik, ktp_image_id, created_at, email_verified_at,
+        ktp_verification_status, ktp_verification_note, ktp_verified_by, ktp_verified_at FROM users WHERE email = $1 AND deleted_at IS NULL LIMIT 1`
 	row := db.QueryRowContext(ctx, q, email)
 	var u User
-	err := row.Scan(&u.UserID, &u.Name, &u.Email, &u.Phone, &u.Password, &u.NIK, &u.KTPImageID, &u.CreatedAt)
+	err := row.Scan(&u.UserID, &u.Name, &u.Email, &u.Phone, &u.Password, &u.NIK, &u.KTPImageID, &u.CreatedAt, &u.EmailVerifiedAt,
+		&u.KTPVerificationStatus, &u.KTPVerificationNote, &u.KTPVerifiedBy, &u.KTPVerifiedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) { 
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
 	return &u, nil
 }
 
-func FindUserByID(db *sql.DB, userID string, ctx context.Context) (*User, error) {
-	q := `SELECT user_id, name, email, phone, password, nik, ktp_image_id, created_at FROM users WHERE user_id = $1 LIMIT 1`
+func FindUserByID(db Querier, userID string, ctx context.Context) (*User, error) {
+	q := `SELECT user_id, name, email, phone, password, nik, ktp_image_id, created_at, email_verified_at,
+        ktp_verification_status, ktp_verification_note, ktp_verified_by, ktp_verified_at FROM users WHERE user_id = $1 AND deleted_at IS NULL LIMIT 1`
 	row := db.QueryRowContext(ctx, q, userID)
 	var u User
-	err := row.Scan(&u.UserID, &u.Name, &u.Email, &u.Phone, &u.Password, &u.NIK, &u.KTPImageID, &u.CreatedAt)
+	err := row.Scan(&u.UserID, &u.Name, &u.Email, &u.Phone, &u.Password, &u.NIK, &u.KTPImageID, &u.CreatedAt, &u.EmailVerifiedAt,
+		&u.KTPVerificationStatus, &u.KTPVerificationNote, &u.KTPVerifiedBy, &u.KTPVerifiedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+// FindUserByNIKHash mencari user berdasarkan blind index NIK.
+func FindUserByNIKHash(ctx context.Context, db Querier, hash string) (*User, error) {
+	if hash == "" {
+		return nil, ErrUserNotFound
+	}
+	q := `SELECT user_id, name, email, phone, password, nik, ktp_image_id, created_at, email_verified_at,
+        ktp_verification_status, ktp_verification_note, ktp_verified_by, ktp_verified_at FROM users WHERE nik_hash = $1 AND deleted_at IS NULL ORDER BY created_at LIMIT 1`
+	row := db.QueryRowContext(ctx, q, hash)
+	var u User
+	err := row.Scan(&u.UserID, &u.Name, &u.Email, &u.Phone, &u.Password, &u.NIK, &u.KTPImageID, &u.CreatedAt, &u.EmailVerifiedAt,
+		&u.KTPVerificationStatus, &u.KTPVerificationNote, &u.KTPVerifiedBy, &u.KTPVerifiedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
 	return &u, nil
 }
 
-func FindManyUser(db *sql.DB, ctx context.Context) ([]User, error) {
-	q := `SELECT user_id, name, email, phone, password, nik, ktp_image_id, created_at FROM users`
+func FindManyUser(db Querier, ctx context.Context) ([]User, error) {
+	q := `SELECT user_id, name, email, phone, password, nik, ktp_image_id, created_at, email_verified_at,
+        ktp_verification_status, ktp_verification_note, ktp_verified_by, ktp_verified_at FROM users WHERE deleted_at IS NULL`
 	rows, err := db.QueryContext(ctx, q)
 	if err != nil {
 		return nil, err
@@ -105,7 +142,8 @@ func FindManyUser(db *sql.DB, ctx context.Context) ([]User, error) {
 	var users []User
 	for rows.Next() {
 		var u User
-		err := rows.Scan(&u.UserID, &u.Name, &u.Email, &u.Phone, &u.Password, &u.NIK, &u.KTPImageID, &u.CreatedAt)
+		err := rows.Scan(&u.UserID, &u.Name, &u.Email, &u.Phone, &u.Password, &u.NIK, &u.KTPImageID, &u.CreatedAt, &u.EmailVerifiedAt,
+		&u.KTPVerificationStatus, &u.KTPVerificationNote, &u.KTPVerifiedBy, &u.KTPVerifiedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -117,7 +155,7 @@ func FindManyUser(db *sql.DB, ctx context.Context) ([]User, error) {
 	return users, nil
 }
 
-func UpdateUserTx(ctx context.Context, tx *sql.Tx, userID string, updates map[string]interface{}) error {
+func UpdateUserTx(ctx context.Context, tx Querier, userID string, updates map[string]interface{}) error {
     if len(updates) == 0 {
         return errors.New("no fields provided for user update")
     }
@@ -128,7 +166,9 @@ func UpdateUserTx(ctx context.Context, tx *sql.Tx, userID string, updates map[st
         "phone":        true,
         "password":     true,
         "nik":          true,
+        "nik_hash":     true,
         "ktp_image_id": true,
+        "email_verified_at": true,
     }
 
     var queryBuilder strings.Builder
@@ -147,7 +187,7 @@ func UpdateUserTx(ctx context.Context, tx *sql.Tx, userID string, updates map[st
     }
 
     finalQuery := strings.TrimSuffix(queryBuilder.String(), ", ")
-    finalQuery += fmt.Sprintf(" WHERE user_id = $%d", argCount)
+    finalQuery += fmt.Sprintf(" WHERE user_id = $%d AND deleted_at IS NULL", argCount)
     args = append(args, userID)
 
     res, err := tx.ExecContext(ctx, finalQuery, args...)
@@ -165,9 +205,13 @@ func UpdateUserTx(ctx context.Context, tx *sql.Tx, userID string, updates map[st
     return nil
 }
 
-func DeleteUserTx(ctx context.Context, tx *sql.Tx, userID string) error {
-    q := `DELETE FROM users WHERE user_id=$1`
-    res, err := tx.ExecContext(ctx, q, userID)
+// DeleteUserTx menandai user sebagai terhapus. Kendaraan dan laporan
+// kehilangan miliknya ikut ditandai dengan waktu yang sama sehingga
+// RestoreUserTx dapat mengembalikan semuanya sekaligus.
+func DeleteUserTx(ctx context.Context, tx Querier, userID string) error {
+    deletedAt := time.Now()
+    q := `UPDATE users SET deleted_at = $2 WHERE user_id = $1 AND deleted_at IS NULL`
+    res, err := tx.ExecContext(ctx, q, userID, deletedAt)
     if err != nil {
         return fmt.Errorf("error deleting user ID %s in tx: %w", userID, err)
     }
@@ -178,5 +222,47 @@ func DeleteUserTx(ctx context.Context, tx *sql.Tx, userID string) error {
     if count == 0 {
         return sql.ErrNoRows
     }
+    for _, table := range []string{"vehicle", "lost_report"} {
+        q := `UPDATE ` + table + ` SET deleted_at = $2 WHERE user_id = $1 AND deleted_at IS NULL`
+        if _, err := tx.ExecContext(ctx, q, userID, deletedAt); err != nil {
+            return fmt.Errorf("error deleting %s rows of user ID %s in tx: %w", table, userID, err)
+        }
+    }
+    return nil
+}
+
+// RestoreUserTx membatalkan soft delete user beserta kendaraan dan laporan
+// yang terhapus bersamanya. Mengembalikan ErrUserNotFound bila user tidak
+// ada atau tidak sedang terhapus, atau ErrPlateTaken bila plat salah satu
+// kendaraannya sudah didaftarkan ulang.
+func RestoreUserTx(ctx context.Context, tx Querier, userID string) error {
+    var deletedAt time.Time
+    err := tx.QueryRowContext(ctx, `SELECT deleted_at FROM users WHERE user_id = $1 AND deleted_at IS NOT NULL FOR UPDATE`, userID).Scan(&deletedAt)
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return ErrUserNotFound
+        }
+        return fmt.Errorf("error finding deleted user ID %s: %w", userID, err)
+    }
+    for _, table := range []string{"users", "vehicle", "lost_report"} {
+        q := `UPDATE ` + table + ` SET deleted_at = NULL WHERE user_id = $1 AND deleted_at = $2`
+        if _, err := tx.ExecContext(ctx, q, userID, deletedAt); err != nil {
+            if isUniqueViolation(err, "idx_vehicle_plate_active") {
+                return ErrPlateTaken
+            }
+            return fmt.Errorf("error restoring %s rows of user ID %s: %w", table, userID, err)
+        }
+    }
     return nil
-}
\ No newline at end of file
+}
+
+// PurgeDeletedUsers menghapus permanen user yang di-soft delete sebelum
+// before. Data turunan yang masih tersisa ikut terhapus lewat ON DELETE
+// CASCADE.
+func PurgeDeletedUsers(ctx context.Context, db Querier, before time.Time) (*PurgedRows, error) {
+    rows, err := db.QueryContext(ctx, `DELETE FROM users WHERE deleted_at < $1 RETURNING ktp_image_id`, before)
+    if err != nil {
+        return nil, fmt.Errorf("error purging deleted users: %w", err)
+    }
+    return scanPurgedRows(rows)
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+// UserToken adalah token sekali pakai milik user. Hanya hash SHA-256 token
+// yang disimpan; token mentah hanya dikirim ke email user.
+type UserToken struct {
+	TokenHash string
+	UserID    string
+	Purpose   string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+func CreateUserToken(ctx context.Context, db Querier, t *UserToken) error {
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	_, err := db.ExecContext(ctx, `INSERT INTO user_tokens (token_hash, user_id, purpose, expires_at, created_at)
+        VALUES ($1, $2, $3, $4, $5)`, t.TokenHash, t.UserID, t.Purpose, t.ExpiresAt, t.CreatedAt)
+	return err
+}
+
+// ConsumeUserToken menandai token terpakai secara atomik sehingga token yang
+// sama tidak bisa dipakai dua kali walau request datang bersamaan.
+func ConsumeUserToken(ctx context.Context, db Querier, tokenHash, purpose string, now time.Time) (*UserToken, error) {
+	t := UserToken{TokenHash: tokenHash, Purpose: purpose}
+	err := db.QueryRowContext(ctx, `UPDATE user_tokens SET used_at = $3
+        WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > $3
+        RETURNING user_id, expires_at, used_at, created_at`, tokenHash, purpose, now).
+		Scan(&t.UserID, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTokenInvalid
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func InvalidateUserTokens(ctx context.Context, db Querier, userID, purpose string, now time.Time) error {
+	_, err := db.ExecContext(ctx, `UPDATE user_tokens SET used_at = $3
+        WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`, userID, purpose, now)
+	return err
+}
@@ -3,8 +3,10 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 type OwnershipType string
@@ -23,10 +25,59 @@ type Vehicle struct {
 	STNKImageID sql.NullInt64  `json:"stnk_image_id"`
 	KKImageID   sql.NullInt64  `json:"kk_image_id"`
 	Ownership   sql.NullString `json:"ownership"`
+	VehicleAttributes
+	VehicleVerification
+	KKVerification
 }
 
-func ListVehicles(ctx context.Context, db *sql.DB) ([]Vehicle, error) {
-	query := `SELECT vehicle_id, vehicle_name, color, user_id, plate_number, stnk_image_id, kk_image_id, ownership FROM vehicle`
+// VehicleVerification adalah hasil pemeriksaan STNK oleh admin. Hanya
+// kendaraan VERIFIED yang boleh dilaporkan hilang.
+type VehicleVerification struct {
+	VerificationStatus string     `json:"verification_status"`
+	VerificationNote   *string    `json:"verification_note,omitempty"`
+	VerifiedBy         *string    `json:"verified_by,omitempty"`
+	VerifiedAt         *time.Time `json:"verified_at,omitempty"`
+}
+
+const vehicleColumns = `vehicle_id, vehicle_name, color, user_id, plate_number, stnk_image_id, kk_image_id, ownership,
+              brand, model, year, body_type, normalized_color, distinguishing_marks,
+              verification_status, verification_note, verified_by, verified_at,
+              kk_verification_status, kk_verification_note, kk_verified_by, kk_verified_at`
+
+func scanVehicle(row interface{ Scan(...interface{}) error }) (*Vehicle, error) {
+	var v Vehicle
+	err := row.Scan(
+		&v.VehicleID,
+		&v.VehicleName,
+		&v.Color,
+		&v.UserID,
+		&v.PlateNumber,
+		&v.STNKImageID,
+		&v.KKImageID,
+		&v.Ownership,
+		&v.Brand,
+		&v.Model,
+		&v.Year,
+		&v.BodyType,
+		&v.NormalizedColor,
+		&v.DistinguishingMarks,
+		&v.VerificationStatus,
+		&v.VerificationNote,
+		&v.VerifiedBy,
+		&v.VerifiedAt,
+		&v.KKVerificationStatus,
+		&v.KKVerificationNote,
+		&v.KKVerifiedBy,
+		&v.KKVerifiedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func ListVehicles(ctx context.Context, db Querier) ([]Vehicle, error) {
+	query := `SELECT ` + vehicleColumns + ` FROM vehicle WHERE deleted_at IS NULL`
 	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying vehicles: %w", err)
@@ -34,21 +85,11 @@ func ListVehicles(ctx context.Context, db *sql.DB) ([]Vehicle, error) {
 	defer rows.Close()
 	var vehicles []Vehicle
 	for rows.Next() {
-		var v Vehicle
-		err := rows.Scan(
-			&v.VehicleID,
-			&v.VehicleName,
-			&v.Color,
-			&v.UserID,
-			&v.PlateNumber,
-			&v.STNKImageID,
-			&v.KKImageID,
-			&v.Ownership,
-		)
+		v, err := scanVehicle(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning vehicle row: %w", err)
 		}
-		vehicles = append(vehicles, v)
+		vehicles = append(vehicles, *v)
 	}
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating vehicle rows: %w", err)
@@ -57,8 +98,8 @@ func ListVehicles(ctx context.Context, db *sql.DB) ([]Vehicle, error) {
 }
 
 func ListVehiclesByUserID(ctx context.Context, db Querier, userID string) ([]Vehicle, error) {
-    query := `SELECT vehicle_id, vehicle_name, color, user_id, plate_number, stnk_image_id, kk_image_id, ownership 
-              FROM vehicle WHERE user_id=$1 ORDER BY vehicle_id ASC` 
+    query := `SELECT ` + vehicleColumns + `
+              FROM vehicle WHERE user_id=$1 AND deleted_at IS NULL ORDER BY vehicle_id ASC` 
     rows, err := db.QueryContext(ctx, query, userID)
     if err != nil {
         return nil, fmt.Errorf("error querying vehicles by user_id: %w", err)
@@ -67,21 +108,11 @@ func ListVehiclesByUserID(ctx context.Context, db Querier, userID string) ([]Veh
 
     var vehicles []Vehicle
     for rows.Next() {
-        var v Vehicle
-        err := rows.Scan(
-            &v.VehicleID,
-            &v.VehicleName,
-            &v.Color,
-            &v.UserID,
-            &v.PlateNumber,
-            &v.STNKImageID,
-            &v.KKImageID,
-            &v.Ownership,
-        )
+        v, err := scanVehicle(rows)
         if err != nil {
             return nil, fmt.Errorf("error scanning vehicle row for user_id %s: %w", userID, err)
         }
-        vehicles = append(vehicles, v)
+        vehicles = append(vehicles, *v)
     }
     if err = rows.Err(); err != nil {
         return nil, fmt.Errorf("error iterating vehicle rows for user_id %s: %w", userID, err)
@@ -90,62 +121,57 @@ func ListVehiclesByUserID(ctx context.Context, db Querier, userID string) ([]Veh
 }
 
 func GetVehicleByID(ctx context.Context, db Querier, id int64) (*Vehicle, error) {
-	query := `SELECT vehicle_id, vehicle_name, color, user_id, plate_number, stnk_image_id, kk_image_id, ownership 
-              FROM vehicle WHERE vehicle_id=$1`
-	var v Vehicle
-	err := db.QueryRowContext(ctx, query, id).Scan(
-		&v.VehicleID,
-		&v.VehicleName,
-		&v.Color,
-		&v.UserID,
-		&v.PlateNumber,
-		&v.STNKImageID,
-		&v.KKImageID,
-		&v.Ownership,
-	)
+	query := `SELECT ` + vehicleColumns + `
+              FROM vehicle WHERE vehicle_id=$1 AND deleted_at IS NULL`
+	v, err := scanVehicle(db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("vehicle not found")
+			return nil, ErrVehicleNotFound
 		}
 		return nil, fmt.Errorf("error scanning vehicle by id: %w", err)
 	}
-	return &v, nil
+	return v, nil
 }
 
+// GetVehicleByPlate mencari kendaraan aktif berdasarkan plat yang sudah
+// dinormalisasi, sehingga "b1234xyz" menemukan "B 1234 XYZ".
 func GetVehicleByPlate(ctx context.Context, db Querier, plateNumber string) (*Vehicle, error) {
-	query := `SELECT vehicle_id, vehicle_name, color, user_id, plate_number, stnk_image_id, kk_image_id, ownership 
-              FROM vehicle WHERE plate_number=$1`
-	var v Vehicle
-	err := db.QueryRowContext(ctx, query, plateNumber).Scan(
-		&v.VehicleID,
-		&v.VehicleName,
-		&v.Color,
-		&v.UserID,
-		&v.PlateNumber,
-		&v.STNKImageID,
-		&v.KKImageID,
-		&v.Ownership,
-	)
+	query := `SELECT ` + vehicleColumns + `
+              FROM vehicle WHERE plate_normalized=$1 AND deleted_at IS NULL`
+	v, err := scanVehicle(db.QueryRowContext(ctx, query, NormalizePlate(plateNumber)))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("vehicle not found")
+			return nil, ErrVehicleNotFound
 		}
 		return nil, fmt.Errorf("error scanning vehicle by plate: %w", err)
 	}
-	return &v, nil
+	return v, nil
 }
 
-func CreateVehicleTx(ctx context.Context, tx *sql.Tx, v *Vehicle) error {
-	query := `INSERT INTO vehicle (vehicle_name, color, user_id, plate_number, stnk_image_id, kk_image_id, ownership)
-              VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING vehicle_id`
-	err := tx.QueryRowContext(ctx, query, v.VehicleName, v.Color, v.UserID, v.PlateNumber, v.STNKImageID, v.KKImageID, v.Ownership).Scan(&v.VehicleID)
+// CreateVehicleTx mengembalikan ErrPlateTaken bila plat sudah dipakai
+// kendaraan aktif lain.
+func CreateVehicleTx(ctx context.Context, tx Querier, v *Vehicle) error {
+	if v.VerificationStatus == "" {
+		v.VerificationStatus = VerificationUnverified
+	}
+	if v.KKVerificationStatus == "" {
+		v.KKVerificationStatus = VerificationUnverified
+	}
+	query := `INSERT INTO vehicle (vehicle_name, color, user_id, plate_number, plate_normalized, stnk_image_id, kk_image_id, ownership,
+              brand, model, year, body_type, normalized_color, distinguishing_marks, verification_status, kk_verification_status)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) RETURNING vehicle_id`
+	err := tx.QueryRowContext(ctx, query, v.VehicleName, v.Color, v.UserID, v.PlateNumber, plateKey(v.PlateNumber), v.STNKImageID, v.KKImageID, v.Ownership,
+		v.Brand, v.Model, v.Year, v.BodyType, v.NormalizedColor, v.DistinguishingMarks, v.VerificationStatus, v.KKVerificationStatus).Scan(&v.VehicleID)
 	if err != nil {
+		if isUniqueViolation(err, "idx_vehicle_plate_active") {
+			return ErrPlateTaken
+		}
 		return fmt.Errorf("error creating vehicle in tx: %w", err)
 	}
 	return nil
 }
 
-func UpdateVehicleTx(ctx context.Context, tx *sql.Tx, id int64, updates map[string]interface{}) error {
+func UpdateVehicleTx(ctx context.Context, tx Querier, id int64, updates map[string]interface{}) error {
     if len(updates) == 0 {
         return fmt.Errorf("no fields provided for vehicle update")
     }
@@ -157,6 +183,12 @@ func UpdateVehicleTx(ctx context.Context, tx *sql.Tx, id int64, updates map[stri
         "stnk_image_id": true,
         "kk_image_id":   true,
         "ownership":     true,
+        "brand":                true,
+        "model":                true,
+        "year":                 true,
+        "body_type":            true,
+        "normalized_color":     true,
+        "distinguishing_marks": true,
     }
 
     var queryBuilder strings.Builder
@@ -173,14 +205,22 @@ func UpdateVehicleTx(ctx context.Context, tx *sql.Tx, id int64, updates map[stri
         queryBuilder.WriteString(fmt.Sprintf("%s = $%d, ", col, argCount))
         args = append(args, val)
         argCount++
+        if col == "plate_number" {
+            queryBuilder.WriteString(fmt.Sprintf("plate_normalized = $%d, ", argCount))
+            args = append(args, plateKey(fmt.Sprint(val)))
+            argCount++
+        }
     }
 
     finalQuery := strings.TrimSuffix(queryBuilder.String(), ", ")
-    finalQuery += fmt.Sprintf(" WHERE vehicle_id = $%d", argCount)
+    finalQuery += fmt.Sprintf(" WHERE vehicle_id = $%d AND deleted_at IS NULL", argCount)
     args = append(args, id)
 
     res, err := tx.ExecContext(ctx, finalQuery, args...)
     if err != nil {
+        if isUniqueViolation(err, "idx_vehicle_plate_active") {
+            return ErrPlateTaken
+        }
         return fmt.Errorf("error executing vehicle update in tx: %w", err)
     }
 
@@ -194,9 +234,12 @@ func UpdateVehicleTx(ctx context.Context, tx *sql.Tx, id int64, updates map[stri
     return nil
 }
 
-func DeleteVehicleTx(ctx context.Context, tx *sql.Tx, id int64) error {
-	query := `DELETE FROM vehicle WHERE vehicle_id=$1`
-	res, err := tx.ExecContext(ctx, query, id)
+// DeleteVehicleTx menandai kendaraan sebagai terhapus, termasuk laporan
+// kehilangan untuk kendaraan tersebut.
+func DeleteVehicleTx(ctx context.Context, tx Querier, id int64) error {
+	deletedAt := time.Now()
+	query := `UPDATE vehicle SET deleted_at=$2 WHERE vehicle_id=$1 AND deleted_at IS NULL`
+	res, err := tx.ExecContext(ctx, query, id, deletedAt)
 	if err != nil {
 		return fmt.Errorf("error deleting vehicle in tx: %w", err)
 	}
@@ -207,5 +250,43 @@ func DeleteVehicleTx(ctx context.Context, tx *sql.Tx, id int64) error {
 	if count == 0 {
 		return sql.ErrNoRows 
 	}
+	_, err = tx.ExecContext(ctx, `UPDATE lost_report SET deleted_at=$2 WHERE vehicle_id=$1 AND deleted_at IS NULL`, id, deletedAt)
+	if err != nil {
+		return fmt.Errorf("error deleting lost reports of vehicle in tx: %w", err)
+	}
 	return nil
 }
+
+// RestoreVehicleTx membatalkan soft delete kendaraan beserta laporan yang
+// terhapus bersamanya. Mengembalikan ErrVehicleNotFound bila kendaraan tidak
+// sedang terhapus, atau ErrPlateTaken bila platnya sudah didaftarkan ulang.
+func RestoreVehicleTx(ctx context.Context, tx Querier, id int64) error {
+	var deletedAt time.Time
+	err := tx.QueryRowContext(ctx, `SELECT deleted_at FROM vehicle WHERE vehicle_id=$1 AND deleted_at IS NOT NULL FOR UPDATE`, id).Scan(&deletedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrVehicleNotFound
+		}
+		return fmt.Errorf("error finding deleted vehicle: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE vehicle SET deleted_at=NULL WHERE vehicle_id=$1`, id); err != nil {
+		if isUniqueViolation(err, "idx_vehicle_plate_active") {
+			return ErrPlateTaken
+		}
+		return fmt.Errorf("error restoring vehicle: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE lost_report SET deleted_at=NULL WHERE vehicle_id=$1 AND deleted_at=$2`, id, deletedAt); err != nil {
+		return fmt.Errorf("error restoring lost reports of vehicle: %w", err)
+	}
+	return nil
+}
+
+// PurgeDeletedVehicles menghapus permanen kendaraan yang di-soft delete
+// sebelum before.
+func PurgeDeletedVehicles(ctx context.Context, db Querier, before time.Time) (*PurgedRows, error) {
+	rows, err := db.QueryContext(ctx, `DELETE FROM vehicle WHERE deleted_at < $1 RETURNING stnk_image_id, kk_image_id`, before)
+	if err != nil {
+		return nil, fmt.Errorf("error purging deleted vehicles: %w", err)
+	}
+	return scanPurgedRows(rows)
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ColorPalette adalah kode warna yang boleh disimpan di normalized_color dan
+// attr_color. Warna bebas dari user dipetakan lewat NormalizeColor.
+var ColorPalette = []string{
+	"black", "white", "silver", "grey", "red", "blue", "green", "yellow", "orange", "brown", "purple", "pink",
+}
+
+// BodyTypes adalah jenis bodi motor yang dikenali.
+var BodyTypes = []string{"scooter", "underbone", "sport", "trail", "other"}
+
+// colorAliases memetakan nama warna umum (termasuk bahasa Indonesia) ke
+// kode palet.
+var colorAliases = map[string]string{
+	"hitam": "black", "putih": "white", "perak": "silver", "abu": "grey", "abu-abu": "grey", "abu abu": "grey",
+	"gray": "grey", "merah": "red", "biru": "blue", "hijau": "green", "kuning": "yellow", "oranye": "orange",
+	"jingga": "orange", "coklat": "brown", "cokelat": "brown", "ungu": "purple", "merah muda": "pink",
+}
+
+// NormalizeColor mengembalikan kode palet untuk nama warna. Nama yang
+// diawali kode atau alias ("merah marun", "dark blue") dipetakan ke kata
+// pertama yang dikenali; ok=false bila tidak ada yang cocok.
+func NormalizeColor(s string) (string, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return "", false
+	}
+	if c, ok := colorCode(s); ok {
+		return c, true
+	}
+	for _, word := range strings.Fields(s) {
+		if c, ok := colorCode(word); ok {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+func colorCode(s string) (string, bool) {
+	for _, c := range ColorPalette {
+		if s == c {
+			return c, true
+		}
+	}
+	c, ok := colorAliases[s]
+	return c, ok
+}
+
+// ValidBodyType bernilai true bila s ada di BodyTypes.
+func ValidBodyType(s string) bool {
+	for _, b := range BodyTypes {
+		if s == b {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateVehicleYear menolak tahun di luar rentang motor yang masuk akal.
+func ValidateVehicleYear(year int) error {
+	if max := time.Now().Year() + 1; year < 1950 || year > max {
+		return fmt.Errorf("year must be between 1950 and %d", max)
+	}
+	return nil
+}
+
+// VehicleAttributes adalah ciri fisik kendaraan dalam bentuk terstruktur,
+// melengkapi vehicle_name dan color yang berupa teks bebas.
+type VehicleAttributes struct {
+	Brand               *string `json:"brand,omitempty"`
+	Model               *string `json:"model,omitempty"`
+	Year                *int    `json:"year,omitempty"`
+	BodyType            *string `json:"body_type,omitempty"`
+	NormalizedColor     *string `json:"normalized_color,omitempty"`
+	DistinguishingMarks *string `json:"distinguishing_marks,omitempty"`
+}
+
+// DetectedAttributes adalah atribut kendaraan yang diprediksi detector untuk
+// sebuah deteksi. Confidence berlaku untuk seluruh prediksi.
+type DetectedAttributes struct {
+	Brand      *string  `json:"brand,omitempty"`
+	Model      *string  `json:"model,omitempty"`
+	BodyType   *string  `json:"body_type,omitempty"`
+	Color      *string  `json:"color,omitempty"`
+	Confidence *float64 `json:"confidence,omitempty"`
+}
+
+// Empty bernilai true bila detector belum melampirkan atribut apa pun.
+func (a DetectedAttributes) Empty() bool {
+	return a.Brand == nil && a.Model == nil && a.BodyType == nil && a.Color == nil && a.Confidence == nil
+}
+
+// Normalize merapikan atribut prediksi dan memvalidasi kode palet. Pesan
+// error-nya aman ditampilkan ke client.
+func (a *DetectedAttributes) Normalize() error {
+	a.Brand = trimmedOrNil(a.Brand)
+	a.Model = trimmedOrNil(a.Model)
+	a.BodyType = trimmedOrNil(a.BodyType)
+	if a.BodyType != nil {
+		b := strings.ToLower(*a.BodyType)
+		if !ValidBodyType(b) {
+			return fmt.Errorf("body_type must be one of %s", strings.Join(BodyTypes, ", "))
+		}
+		a.BodyType = &b
+	}
+	if a.Color = trimmedOrNil(a.Color); a.Color != nil {
+		c, ok := NormalizeColor(*a.Color)
+		if !ok {
+			return fmt.Errorf("color must be one of %s", strings.Join(ColorPalette, ", "))
+		}
+		a.Color = &c
+	}
+	if a.Confidence != nil && (*a.Confidence < 0 || *a.Confidence > 1) {
+		return fmt.Errorf("confidence must be between 0 and 1")
+	}
+	return nil
+}
+
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*s)
+	if t == "" {
+		return nil
+	}
+	return &t
+}
+
+// DetectionSearchFilter mencari deteksi berdasarkan atribut prediksi.
+// Brand dan BodyType dicocokkan persis tanpa membedakan huruf besar, Model
+// cukup memuat teks yang dicari. CameraIDs kosong berarti semua kamera.
+type DetectionSearchFilter struct {
+	Brand         string
+	Model         string
+	BodyType      string
+	Color         string
+	CameraIDs     []int64
+	From, To      time.Time
+	MinConfidence float64
+	Limit, Offset int
+}
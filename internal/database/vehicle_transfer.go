@@ -0,0 +1,237 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	TransferPending   = "PENDING"
+	TransferAccepted  = "ACCEPTED"
+	TransferRejected  = "REJECTED"
+	TransferCancelled = "CANCELLED"
+	TransferExpired   = "EXPIRED"
+)
+
+// platePattern adalah format plat nomor Indonesia: kode wilayah 1-2 huruf,
+// nomor 1-4 angka dan akhiran 0-3 huruf.
+var platePattern = regexp.MustCompile(`^([A-Z]{1,2})([0-9]{1,4})([A-Z]{0,3})$`)
+
+// NormalizePlate mengembalikan plat dalam huruf besar tanpa spasi dan tanda
+// baca. Nilai ini yang dijaga unik di antara kendaraan aktif.
+func NormalizePlate(plate string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(plate) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// FormatPlate memvalidasi plat dan mengembalikannya dalam bentuk baku
+// "B 1234 XYZ". Pesan error-nya aman ditampilkan ke client.
+func FormatPlate(plate string) (string, error) {
+	m := platePattern.FindStringSubmatch(NormalizePlate(plate))
+	if m == nil {
+		return "", errors.New("plate_number must look like 'B 1234 XYZ'")
+	}
+	return strings.TrimSpace(m[1] + " " + m[2] + " " + m[3]), nil
+}
+
+// plateKey dipakai untuk kolom plate_normalized; plat kosong (misalnya
+// setelah data user dihapus) disimpan NULL agar tidak ikut dijaga unik.
+func plateKey(plate string) sql.NullString {
+	n := NormalizePlate(plate)
+	return sql.NullString{String: n, Valid: n != ""}
+}
+
+// isUniqueViolation bernilai true bila err berasal dari unique index
+// bernama constraint.
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
+
+// SetVehicleVerification mengubah status verifikasi kendaraan. verifiedBy
+// kosong (status kembali ke UNVERIFIED atau PENDING karena STNK atau plat
+// berubah) mengosongkan verified_by dan verified_at.
+func SetVehicleVerification(ctx context.Context, db Querier, id int64, status, verifiedBy, note string, at time.Time) error {
+	var verifiedAt *time.Time
+	if verifiedBy != "" {
+		verifiedAt = &at
+	}
+	res, err := db.ExecContext(ctx, `UPDATE vehicle SET verification_status = $2, verified_by = NULLIF($3, ''),
+        verification_note = NULLIF($4, ''), verified_at = $5 WHERE vehicle_id = $1 AND deleted_at IS NULL`,
+		id, status, verifiedBy, note, verifiedAt)
+	if err != nil {
+		return fmt.Errorf("error setting verification of vehicle ID %d: %w", id, err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrVehicleNotFound
+	}
+	return nil
+}
+
+// TransferVehicleTx memindahkan kendaraan ke toUserID bila masih dimiliki
+// fromUserID. STNK dan KK milik pemilik lama dilepas dan ID gambarnya
+// dikembalikan agar pemanggil menghapusnya; kepemilikan dan status
+// verifikasi STNK dan KK dikosongkan karena pemilik baru harus mengunggah
+// dokumennya sendiri.
+func TransferVehicleTx(ctx context.Context, tx Querier, id int64, fromUserID, toUserID string) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT stnk_image_id, kk_image_id FROM vehicle
+        WHERE vehicle_id = $1 AND user_id = $2 AND deleted_at IS NULL FOR UPDATE`, id, fromUserID)
+	if err != nil {
+		return nil, fmt.Errorf("error locking vehicle ID %d for transfer: %w", id, err)
+	}
+	images, err := scanPurgedRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if images.Count == 0 {
+		return nil, ErrVehicleNotFound
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE vehicle SET user_id = $2, ownership = NULL, stnk_image_id = NULL, kk_image_id = NULL,
+        verification_status = $3, verification_note = NULL, verified_by = NULL, verified_at = NULL,
+        kk_verification_status = $3, kk_verification_note = NULL, kk_verified_by = NULL, kk_verified_at = NULL WHERE vehicle_id = $1`,
+		id, toUserID, VerificationUnverified)
+	if err != nil {
+		return nil, fmt.Errorf("error transferring vehicle ID %d: %w", id, err)
+	}
+	return images.ImageIDs, nil
+}
+
+// VehicleTransfer adalah permintaan pindah kepemilikan kendaraan.
+// VehicleName dan PlateNumber diisi dari kendaraan saat dibaca.
+type VehicleTransfer struct {
+	TransferID  int64      `json:"transfer_id"`
+	VehicleID   int64      `json:"vehicle_id"`
+	VehicleName string     `json:"vehicle_name"`
+	PlateNumber string     `json:"plate_number"`
+	FromUserID  string     `json:"from_user_id"`
+	ToUserID    string     `json:"to_user_id"`
+	Status      string     `json:"status"`
+	Note        string     `json:"note,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`
+}
+
+// EffectiveStatus mengembalikan EXPIRED untuk permintaan PENDING yang sudah
+// lewat masa berlakunya tetapi belum ditandai.
+func (t VehicleTransfer) EffectiveStatus(now time.Time) string {
+	if t.Status == TransferPending && !now.Before(t.ExpiresAt) {
+		return TransferExpired
+	}
+	return t.Status
+}
+
+const vehicleTransferColumns = `t.transfer_id, t.vehicle_id, v.vehicle_name, v.plate_number, t.from_user_id, t.to_user_id,
+        t.status, t.note, t.created_at, t.expires_at, t.decided_at`
+
+const vehicleTransferFrom = ` FROM vehicle_transfers t JOIN vehicle v ON v.vehicle_id = t.vehicle_id`
+
+func scanVehicleTransfer(row interface{ Scan(...interface{}) error }) (*VehicleTransfer, error) {
+	var t VehicleTransfer
+	if err := row.Scan(&t.TransferID, &t.VehicleID, &t.VehicleName, &t.PlateNumber, &t.FromUserID, &t.ToUserID,
+		&t.Status, &t.Note, &t.CreatedAt, &t.ExpiresAt, &t.DecidedAt); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// CreateVehicleTransfer menandai permintaan lama yang sudah kedaluwarsa
+// sebagai EXPIRED lalu menyimpan permintaan baru. Mengembalikan
+// ErrVehicleTransferPending bila kendaraan masih punya permintaan aktif.
+func CreateVehicleTransfer(ctx context.Context, db Querier, t *VehicleTransfer) error {
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	t.Status = TransferPending
+	_, err := db.ExecContext(ctx, `UPDATE vehicle_transfers SET status = $2, decided_at = expires_at
+        WHERE vehicle_id = $1 AND status = $3 AND expires_at <= $4`, t.VehicleID, TransferExpired, TransferPending, t.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error expiring vehicle transfers: %w", err)
+	}
+	err = db.QueryRowContext(ctx, `INSERT INTO vehicle_transfers (vehicle_id, from_user_id, to_user_id, status, note, created_at, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING transfer_id`,
+		t.VehicleID, t.FromUserID, t.ToUserID, t.Status, t.Note, t.CreatedAt, t.ExpiresAt).Scan(&t.TransferID)
+	if err != nil {
+		if isUniqueViolation(err, "idx_vehicle_transfers_pending") {
+			return ErrVehicleTransferPending
+		}
+		return fmt.Errorf("error creating vehicle transfer: %w", err)
+	}
+	return nil
+}
+
+func GetVehicleTransfer(ctx context.Context, db Querier, id int64) (*VehicleTransfer, error) {
+	t, err := scanVehicleTransfer(db.QueryRowContext(ctx, `SELECT `+vehicleTransferColumns+vehicleTransferFrom+` WHERE t.transfer_id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrVehicleTransferNotFound
+		}
+		return nil, fmt.Errorf("error getting vehicle transfer ID %d: %w", id, err)
+	}
+	return t, nil
+}
+
+// ListVehicleTransfersByUserID mengembalikan permintaan yang dikirim atau
+// diterima user, terbaru lebih dulu.
+func ListVehicleTransfersByUserID(ctx context.Context, db Querier, userID string) ([]VehicleTransfer, error) {
+	return listVehicleTransfers(ctx, db, ` WHERE t.from_user_id = $1 OR t.to_user_id = $1`, userID)
+}
+
+// ListVehicleTransfersByVehicleID mengembalikan riwayat pindah kepemilikan
+// kendaraan, terbaru lebih dulu.
+func ListVehicleTransfersByVehicleID(ctx context.Context, db Querier, vehicleID int64) ([]VehicleTransfer, error) {
+	return listVehicleTransfers(ctx, db, ` WHERE t.vehicle_id = $1`, vehicleID)
+}
+
+func listVehicleTransfers(ctx context.Context, db Querier, where string, arg interface{}) ([]VehicleTransfer, error) {
+	rows, err := db.QueryContext(ctx, `SELECT `+vehicleTransferColumns+vehicleTransferFrom+where+
+		` ORDER BY t.created_at DESC, t.transfer_id DESC`, arg)
+	if err != nil {
+		return nil, fmt.Errorf("error querying vehicle transfers: %w", err)
+	}
+	defer rows.Close()
+
+	var list []VehicleTransfer
+	for rows.Next() {
+		t, err := scanVehicleTransfer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning vehicle transfer row: %w", err)
+		}
+		list = append(list, *t)
+	}
+	return list, rows.Err()
+}
+
+// DecideVehicleTransfer mengubah status permintaan yang masih PENDING.
+// Mengembalikan ErrVehicleTransferDecided bila sudah diputuskan.
+func DecideVehicleTransfer(ctx context.Context, db Querier, id int64, status string, at time.Time) error {
+	res, err := db.ExecContext(ctx, `UPDATE vehicle_transfers SET status = $2, decided_at = $3
+        WHERE transfer_id = $1 AND status = $4`, id, status, at, TransferPending)
+	if err != nil {
+		return fmt.Errorf("error deciding vehicle transfer ID %d: %w", id, err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrVehicleTransferDecided
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+// Package fieldcrypt mengenkripsi kolom berisi data pribadi (NIK, nomor
+// telepon) di level aplikasi dengan envelope encryption: setiap nilai
+// dienkripsi AES-256-GCM memakai data key acak, lalu data key dibungkus
+// dengan key dari key ring. Key ID ikut disimpan sehingga key lama tetap
+// bisa mendekripsi data sampai di-rotate.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix menandai nilai terenkripsi. Nilai tanpa prefix dianggap plaintext
+// lama yang belum dimigrasikan dan dikembalikan apa adanya oleh Decrypt.
+const prefix = "enc:v1:"
+
+const (
+	keySize      = 32
+	minIndexSize = 32
+)
+
+var (
+	ErrUnknownKey = errors.New("fieldcrypt: unknown key id")
+	ErrMalformed  = errors.New("fieldcrypt: malformed ciphertext")
+)
+
+var encoding = base64.RawStdEncoding
+
+// KeyRing menyimpan key enkripsi berdasarkan ID beserta key HMAC untuk blind
+// index. Aman dipakai bersamaan dari banyak goroutine.
+type KeyRing struct {
+	keys     map[string]cipher.AEAD
+	active   string
+	indexKey []byte
+}
+
+// NewKeyRing memvalidasi key (masing-masing 32 byte) dan memastikan
+// activeKeyID ada di dalam ring.
+func NewKeyRing(keys map[string][]byte, activeKeyID string, indexKey []byte) (*KeyRing, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one key is required")
+	}
+	k := &KeyRing{keys: make(map[string]cipher.AEAD, len(keys)), active: activeKeyID}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("key %q must be %d bytes, got %d", id, keySize, len(key))
+		}
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+	}
+	if _, ok := k.keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active key %q is not in the key ring", activeKeyID)
+	}
+	if len(indexKey) < minIndexSize {
+		return nil, fmt.Errorf("blind index key must be at least %d bytes, got %d", minIndexSize, len(indexKey))
+	}
+	k.indexKey = indexKey
+	return k, nil
+}
+
+// ActiveKeyID mengembalikan ID key yang dipakai untuk enkripsi baru.
+func (k *KeyRing) ActiveKeyID() string {
+	return k.active
+}
+
+// Encrypt menghasilkan "enc:v1:<key id>:<data key terbungkus>:<ciphertext>".
+// String kosong tidak dienkripsi agar kolom kosong tetap kosong.
+func (k *KeyRing) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	dek := make([]byte, keySize)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	aead, err := newGCM(dek)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.keys[k.active], dek, []byte(k.active))
+	if err != nil {
+		return "", err
+	}
+	ct, err := seal(aead, []byte(plaintext), nil)
+	if err != nil {
+		return "", err
+	}
+	return prefix + k.active + ":" + encoding.EncodeToString(wrapped) + ":" + encoding.EncodeToString(ct), nil
+}
+
+// Decrypt membuka nilai hasil Encrypt. Nilai tanpa prefix dikembalikan apa
+// adanya.
+func (k *KeyRing) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		return value, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return "", ErrMalformed
+	}
+	kek, ok := k.keys[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownKey, parts[0])
+	}
+	wrapped, err := encoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrMalformed
+	}
+	ct, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrMalformed
+	}
+	dek, err := open(kek, wrapped, []byte(parts[0]))
+	if err != nil {
+		return "", err
+	}
+	aead, err := newGCM(dek)
+	if err != nil {
+		return "", ErrMalformed
+	}
+	pt, err := open(aead, ct, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(pt), nil
+}
+
+// NeedsRotation bernilai true untuk plaintext lama dan nilai yang
+// dienkripsi dengan key selain key aktif.
+func (k *KeyRing) NeedsRotation(value string) bool {
+	if value == "" {
+		return false
+	}
+	return !strings.HasPrefix(value, prefix+k.active+":")
+}
+
+// BlindIndex menghasilkan HMAC-SHA256 (hex) dari nilai yang sudah
+// dinormalisasi, untuk pencarian persis tanpa menyimpan plaintext.
+func (k *KeyRing) BlindIndex(value string) string {
+	value = Normalize(value)
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Normalize membuang spasi, titik dan tanda hubung yang sering ikut
+// diketik pada NIK.
+func Normalize(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-', '\t':
+			return -1
+		}
+		return r
+	}, value)
+}
+
+// Mask menyisakan 4 karakter awal dan akhir, mis. 3201********0001. Nilai
+// pendek hanya menyisakan 2 karakter terakhir.
+func Mask(value string) string {
+	r := []rune(value)
+	switch {
+	case len(r) == 0:
+		return ""
+	case len(r) > 8:
+		return string(r[:4]) + strings.Repeat("*", len(r)-8) + string(r[len(r)-4:])
+	case len(r) > 2:
+		return strings.Repeat("*", len(r)-2) + string(r[len(r)-2:])
+	default:
+		return strings.Repeat("*", len(r))
+	}
+}
+
+// ParseKey mendekode key base64 (standar atau URL-safe, dengan atau tanpa
+// padding).
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.TrimSpace(s), "=")
+	for _, enc := range []*base64.Encoding{base64.RawStdEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, errors.New("key is not valid base64")
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal menaruh nonce acak di depan ciphertext.
+func seal(aead cipher.AEAD, plaintext, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additional), nil
+}
+
+func open(aead cipher.AEAD, data, additional []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	pt, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], additional)
+	if err != nil {
+		return nil, fmt.Errorf("fieldcrypt: decrypt: %w", err)
+	}
+	return pt, nil
+}
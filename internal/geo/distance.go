@@ -0,0 +1,18 @@
+// Package geo berisi perhitungan jarak, clustering hotspot dan tipe GeoJSON
+// untuk endpoint analitik.
+package geo
+
+import "math"
+
+// EarthRadiusKm adalah radius rata-rata Bumi yang juga dipakai query
+// Postgres.
+const EarthRadiusKm = 6371
+
+// HaversineKm menghitung jarak great-circle dengan rumus yang sama dengan
+// query jarak di Postgres (spherical law of cosines).
+func HaversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	cosAngle := math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Cos(toRad(lon2)-toRad(lon1)) +
+		math.Sin(toRad(lat1))*math.Sin(toRad(lat2))
+	return EarthRadiusKm * math.Acos(math.Max(-1, math.Min(1, cosAngle)))
+}
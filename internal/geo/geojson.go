@@ -0,0 +1,50 @@
+package geo
+
+// FeatureCollection, Feature dan Geometry mengikuti RFC 7946. Koordinat
+// ditulis sebagai [longitude, latitude]; Feature tanpa lokasi punya
+// geometry null.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   *Geometry              `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// NewFeatureCollection tidak pernah menghasilkan "features": null.
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+func PointFeature(lat, lon float64, properties map[string]interface{}) Feature {
+	return Feature{
+		Type:       "Feature",
+		Geometry:   &Geometry{Type: "Point", Coordinates: []float64{lon, lat}},
+		Properties: properties,
+	}
+}
+
+// LineStringFeature membutuhkan minimal dua posisi; kurang dari itu
+// geometry-nya null.
+func LineStringFeature(positions []Position, properties map[string]interface{}) Feature {
+	f := Feature{Type: "Feature", Properties: properties}
+	if len(positions) >= 2 {
+		coords := make([][]float64, 0, len(positions))
+		for _, p := range positions {
+			coords = append(coords, []float64{p.Longitude, p.Latitude})
+		}
+		f.Geometry = &Geometry{Type: "LineString", Coordinates: coords}
+	}
+	return f
+}
@@ -0,0 +1,253 @@
+package geo
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	MethodDBSCAN = "dbscan"
+	MethodGrid   = "grid"
+)
+
+// kmPerDegreeLat adalah panjang satu derajat lintang pada EarthRadiusKm.
+const kmPerDegreeLat = EarthRadiusKm * math.Pi / 180
+
+// Incident adalah satu kejadian pencurian yang akan di-cluster.
+type Incident struct {
+	ID        int
+	Latitude  float64
+	Longitude float64
+	Time      time.Time
+}
+
+type Position struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// ClusterOptions: RadiusKm adalah eps untuk DBSCAN atau sisi sel untuk grid,
+// MinPoints adalah jumlah kejadian minimum sebuah hotspot, dan Location
+// menentukan jam serta hari kejadian. Pada DBSCAN, dua kejadian hanya
+// bertetangga bila jam kejadiannya (di Location) berselisih paling lama
+// TimeWindow, mis. 23.00 dan 01.00 berselisih 2 jam; 0 berarti hanya jarak
+// yang dipakai.
+type ClusterOptions struct {
+	Method     string
+	RadiusKm   float64
+	MinPoints  int
+	TimeWindow time.Duration
+	Location   *time.Location
+}
+
+// Hotspot adalah satu cluster kejadian. WeekdayCounts memakai indeks
+// time.Weekday (0 = Minggu).
+type Hotspot struct {
+	Center          Position
+	RadiusKm        float64
+	IncidentIDs     []int
+	HourCounts      [24]int
+	WeekdayCounts   [7]int
+	PeakHours       []int
+	NearestCameraKm *float64
+	UnderCovered    bool
+}
+
+func (h Hotspot) Count() int { return len(h.IncidentIDs) }
+
+// Cluster mengelompokkan kejadian menjadi hotspot, diurutkan dari jumlah
+// kejadian terbanyak.
+func Cluster(incidents []Incident, opts ClusterOptions) []Hotspot {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	var groups [][]int
+	if opts.Method == MethodGrid {
+		groups = gridBins(incidents, opts.RadiusKm, opts.MinPoints)
+	} else {
+		groups = dbscan(incidents, opts.RadiusKm, opts.MinPoints, opts.TimeWindow, loc)
+	}
+	hotspots := make([]Hotspot, 0, len(groups))
+	for _, g := range groups {
+		hotspots = append(hotspots, newHotspot(incidents, g, loc))
+	}
+	sort.SliceStable(hotspots, func(i, j int) bool {
+		a, b := hotspots[i], hotspots[j]
+		if a.Count() != b.Count() {
+			return a.Count() > b.Count()
+		}
+		if a.Center.Latitude != b.Center.Latitude {
+			return a.Center.Latitude < b.Center.Latitude
+		}
+		return a.Center.Longitude < b.Center.Longitude
+	})
+	return hotspots
+}
+
+// dbscan memakai pencarian tetangga O(n²); jumlah laporan per rentang
+// waktu masih kecil sehingga belum perlu indeks spasial.
+func dbscan(pts []Incident, epsKm float64, minPoints int, window time.Duration, loc *time.Location) [][]int {
+	clock := make([]time.Duration, len(pts))
+	for i, p := range pts {
+		h, m, sec := p.Time.In(loc).Clock()
+		clock[i] = time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second
+	}
+	neighbors := func(i int) []int {
+		var nb []int
+		for j := range pts {
+			if window > 0 && clockDistance(clock[i], clock[j]) > window {
+				continue
+			}
+			if HaversineKm(pts[i].Latitude, pts[i].Longitude, pts[j].Latitude, pts[j].Longitude) <= epsKm {
+				nb = append(nb, j)
+			}
+		}
+		return nb
+	}
+	const noise = -1
+	labels := make([]int, len(pts))
+	var clusters [][]int
+	for i := range pts {
+		if labels[i] != 0 {
+			continue
+		}
+		nb := neighbors(i)
+		if len(nb) < minPoints {
+			labels[i] = noise
+			continue
+		}
+		c := len(clusters) + 1
+		labels[i] = c
+		members := []int{i}
+		for k := 0; k < len(nb); k++ {
+			j := nb[k]
+			if labels[j] == noise {
+				// Titik border: ikut cluster tetapi tidak memperluasnya.
+				labels[j] = c
+				members = append(members, j)
+			}
+			if labels[j] != 0 {
+				continue
+			}
+			labels[j] = c
+			members = append(members, j)
+			if more := neighbors(j); len(more) >= minPoints {
+				nb = append(nb, more...)
+			}
+		}
+		sort.Ints(members)
+		clusters = append(clusters, members)
+	}
+	return clusters
+}
+
+// clockDistance adalah selisih dua jam dalam sehari, melingkar lewat tengah
+// malam.
+func clockDistance(a, b time.Duration) time.Duration {
+	d := a - b
+	if d < 0 {
+		d = -d
+	}
+	if day := 24 * time.Hour; d > day/2 {
+		d = day - d
+	}
+	return d
+}
+
+// gridBins membagi peta menjadi sel kira-kira cellKm x cellKm; lebar sel
+// dalam derajat bujur disesuaikan dengan lintang barisnya.
+func gridBins(pts []Incident, cellKm float64, minPoints int) [][]int {
+	cellLat := cellKm / kmPerDegreeLat
+	type cell struct{ row, col int }
+	cells := map[cell][]int{}
+	var order []cell
+	for i, p := range pts {
+		row := int(math.Floor(p.Latitude / cellLat))
+		rowCenter := (float64(row) + 0.5) * cellLat
+		cellLon := cellLat / math.Max(math.Cos(rowCenter*math.Pi/180), 1e-6)
+		c := cell{row, int(math.Floor(p.Longitude / cellLon))}
+		if _, ok := cells[c]; !ok {
+			order = append(order, c)
+		}
+		cells[c] = append(cells[c], i)
+	}
+	var groups [][]int
+	for _, c := range order {
+		if len(cells[c]) >= minPoints {
+			groups = append(groups, cells[c])
+		}
+	}
+	return groups
+}
+
+func newHotspot(pts []Incident, members []int, loc *time.Location) Hotspot {
+	var h Hotspot
+	for _, i := range members {
+		h.Center.Latitude += pts[i].Latitude
+		h.Center.Longitude += pts[i].Longitude
+	}
+	h.Center.Latitude /= float64(len(members))
+	h.Center.Longitude /= float64(len(members))
+
+	peak := 0
+	for _, i := range members {
+		p := pts[i]
+		h.IncidentIDs = append(h.IncidentIDs, p.ID)
+		h.RadiusKm = math.Max(h.RadiusKm, HaversineKm(h.Center.Latitude, h.Center.Longitude, p.Latitude, p.Longitude))
+		local := p.Time.In(loc)
+		h.HourCounts[local.Hour()]++
+		h.WeekdayCounts[local.Weekday()]++
+		if h.HourCounts[local.Hour()] > peak {
+			peak = h.HourCounts[local.Hour()]
+		}
+	}
+	for hour, n := range h.HourCounts {
+		if n == peak {
+			h.PeakHours = append(h.PeakHours, hour)
+		}
+	}
+	return h
+}
+
+// MarkCoverage mengisi jarak kamera terdekat dan menandai hotspot yang
+// tidak punya kamera dalam radius coverageKm.
+func MarkCoverage(hotspots []Hotspot, cameras []Position, coverageKm float64) {
+	for i := range hotspots {
+		h := &hotspots[i]
+		h.NearestCameraKm = nil
+		for _, c := range cameras {
+			d := HaversineKm(h.Center.Latitude, h.Center.Longitude, c.Latitude, c.Longitude)
+			if h.NearestCameraKm == nil || d < *h.NearestCameraKm {
+				h.NearestCameraKm = &d
+			}
+		}
+		h.UnderCovered = h.NearestCameraKm == nil || *h.NearestCameraKm > coverageKm
+	}
+}
+
+// Feature menulis hotspot sebagai GeoJSON Point di centroid-nya; jarak
+// dibulatkan ke meter.
+func (h Hotspot) Feature() Feature {
+	weekdays := make(map[string]int, len(h.WeekdayCounts))
+	for d, n := range h.WeekdayCounts {
+		weekdays[strings.ToLower(time.Weekday(d).String())] = n
+	}
+	props := map[string]interface{}{
+		"count":           h.Count(),
+		"radius_km":       roundKm(h.RadiusKm),
+		"lost_report_ids": h.IncidentIDs,
+		"hour_counts":     h.HourCounts,
+		"peak_hours":      h.PeakHours,
+		"weekday_counts":  weekdays,
+		"under_covered":   h.UnderCovered,
+	}
+	if h.NearestCameraKm != nil {
+		props["nearest_camera_km"] = roundKm(*h.NearestCameraKm)
+	}
+	return PointFeature(h.Center.Latitude, h.Center.Longitude, props)
+}
+
+func roundKm(km float64) float64 { return math.Round(km*1000) / 1000 }
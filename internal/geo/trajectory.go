@@ -0,0 +1,176 @@
+package geo
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Sighting adalah satu suspect: deteksi kendaraan pada sebuah kamera.
+type Sighting struct {
+	SuspectID  int64     `json:"suspect_id"`
+	CameraID   int64     `json:"camera_id"`
+	CameraName string    `json:"camera_name"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	Time       time.Time `json:"timestamp"`
+	Score      float64   `json:"final_score"`
+}
+
+// Hop adalah perpindahan antara dua titik rute yang berurutan.
+type Hop struct {
+	FromSuspectID  int64     `json:"from_suspect_id"`
+	ToSuspectID    int64     `json:"to_suspect_id"`
+	DepartedAt     time.Time `json:"departed_at"`
+	ArrivedAt      time.Time `json:"arrived_at"`
+	DistanceKm     float64   `json:"distance_km"`
+	ElapsedSeconds float64   `json:"elapsed_seconds"`
+	SpeedKmh       float64   `json:"speed_kmh"`
+}
+
+// RejectedSighting adalah suspect yang tidak mungkin masuk rute.
+// RequiredSpeedKmh dihitung terhadap titik rute sebelumnya (atau
+// berikutnya bila tidak ada) dan nil bila kedua deteksi terjadi pada waktu
+// yang sama di kamera berbeda.
+type RejectedSighting struct {
+	Sighting
+	RequiredSpeedKmh *float64 `json:"required_speed_kmh"`
+}
+
+type Trajectory struct {
+	Points   []Sighting
+	Hops     []Hop
+	Rejected []RejectedSighting
+}
+
+// BuildTrajectory memilih rute dengan titik terbanyak (lalu total skor
+// tertinggi) yang setiap hop-nya bisa ditempuh tanpa melebihi maxSpeedKmh.
+// Memilih secara greedy akan membuang seluruh rute bila deteksi pertama
+// adalah false positive, sehingga dipakai dynamic programming O(n²).
+func BuildTrajectory(sightings []Sighting, maxSpeedKmh float64) Trajectory {
+	pts := append([]Sighting(nil), sightings...)
+	sort.SliceStable(pts, func(i, j int) bool {
+		if !pts[i].Time.Equal(pts[j].Time) {
+			return pts[i].Time.Before(pts[j].Time)
+		}
+		if pts[i].Score != pts[j].Score {
+			return pts[i].Score > pts[j].Score
+		}
+		return pts[i].SuspectID < pts[j].SuspectID
+	})
+
+	type chain struct {
+		n     int
+		score float64
+		prev  int
+	}
+	better := func(a, b chain) bool { return a.n > b.n || a.n == b.n && a.score > b.score }
+	best := make([]chain, len(pts))
+	end := -1
+	for i := range pts {
+		best[i] = chain{n: 1, score: pts[i].Score, prev: -1}
+		for j := 0; j < i; j++ {
+			if !feasible(pts[j], pts[i], maxSpeedKmh) {
+				continue
+			}
+			if c := (chain{n: best[j].n + 1, score: best[j].score + pts[i].Score, prev: j}); better(c, best[i]) {
+				best[i] = c
+			}
+		}
+		if end < 0 || better(best[i], best[end]) {
+			end = i
+		}
+	}
+
+	var t Trajectory
+	onRoute := make([]bool, len(pts))
+	var route []int
+	for i := end; i >= 0; i = best[i].prev {
+		route = append([]int{i}, route...)
+		onRoute[i] = true
+	}
+	for k, i := range route {
+		t.Points = append(t.Points, pts[i])
+		if k > 0 {
+			t.Hops = append(t.Hops, newHop(pts[route[k-1]], pts[i]))
+		}
+	}
+	for i, p := range pts {
+		if onRoute[i] {
+			continue
+		}
+		r := RejectedSighting{Sighting: p}
+		ref := -1
+		for _, k := range route {
+			if k < i || ref < 0 {
+				ref = k
+			}
+		}
+		if ref >= 0 {
+			a, b := pts[ref], p
+			if ref > i {
+				a, b = b, a
+			}
+			if v, ok := speedKmh(a, b); ok {
+				r.RequiredSpeedKmh = &v
+			}
+		}
+		t.Rejected = append(t.Rejected, r)
+	}
+	return t
+}
+
+// speedKmh mengembalikan ok=false bila perpindahan antar kamera berbeda
+// terjadi tanpa selang waktu.
+func speedKmh(a, b Sighting) (float64, bool) {
+	d := HaversineKm(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+	hours := b.Time.Sub(a.Time).Hours()
+	if d == 0 {
+		return 0, true
+	}
+	if hours <= 0 {
+		return 0, false
+	}
+	return d / hours, true
+}
+
+func feasible(a, b Sighting, maxSpeedKmh float64) bool {
+	v, ok := speedKmh(a, b)
+	return ok && v <= maxSpeedKmh
+}
+
+func newHop(a, b Sighting) Hop {
+	v, _ := speedKmh(a, b)
+	return Hop{
+		FromSuspectID:  a.SuspectID,
+		ToSuspectID:    b.SuspectID,
+		DepartedAt:     a.Time,
+		ArrivedAt:      b.Time,
+		DistanceKm:     roundKm(HaversineKm(a.Latitude, a.Longitude, b.Latitude, b.Longitude)),
+		ElapsedSeconds: b.Time.Sub(a.Time).Seconds(),
+		SpeedKmh:       math.Round(v*10) / 10,
+	}
+}
+
+// Feature menulis rute sebagai GeoJSON LineString; titik, hop dan suspect
+// yang ditolak ada di properties.
+func (t Trajectory) Feature(properties map[string]interface{}) Feature {
+	positions := make([]Position, 0, len(t.Points))
+	for _, p := range t.Points {
+		positions = append(positions, Position{Latitude: p.Latitude, Longitude: p.Longitude})
+	}
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+	properties["points"] = nonNil(t.Points)
+	properties["hops"] = nonNil(t.Hops)
+	properties["rejected"] = nonNil(t.Rejected)
+	return LineStringFeature(positions, properties)
+}
+
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
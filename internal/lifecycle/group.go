@@ -0,0 +1,69 @@
+// Package lifecycle mengelola goroutine latar belakang agar bisa dihentikan
+// dengan rapi saat server shutdown.
+package lifecycle
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Group menjalankan pekerjaan latar belakang yang berbagi satu context.
+// Context tersebut dibatalkan saat Shutdown dipanggil.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	stopped bool
+}
+
+func NewGroup() *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{ctx: ctx, cancel: cancel}
+}
+
+// Go menjalankan fn di goroutine baru. Setelah Shutdown, fn tidak lagi
+// dijalankan dan Go mengembalikan false.
+func (g *Group) Go(name string, fn func(ctx context.Context)) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.stopped {
+		slog.Warn("background task not started: shutting down", "task", name)
+		return false
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer func() {
+			if p := recover(); p != nil {
+				slog.Error("background task panicked", "task", name, "panic", p)
+			}
+		}()
+		fn(g.ctx)
+	}()
+	return true
+}
+
+// Shutdown membatalkan context group lalu menunggu semua pekerjaan selesai
+// atau ctx habis, mana yang lebih dulu.
+func (g *Group) Shutdown(ctx context.Context) error {
+	g.mu.Lock()
+	g.stopped = true
+	g.mu.Unlock()
+	g.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
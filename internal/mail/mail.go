@@ -0,0 +1,102 @@
+// Package mail mengirim email transaksional (reset password, verifikasi
+// email) lewat SMTP, atau menuliskannya ke log saat pengembangan.
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/config"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New memilih Sender sesuai mail.driver. Config diasumsikan sudah divalidasi.
+func New(cfg config.MailConfig) Sender {
+	if cfg.Driver == "smtp" {
+		return &smtpSender{cfg: cfg}
+	}
+	return logSender{}
+}
+
+// logSender menulis email ke log. Isi email (termasuk token) ikut tercatat,
+// jadi jangan dipakai di produksi.
+type logSender struct{}
+
+func (logSender) Send(ctx context.Context, msg Message) error {
+	logging.FromContext(ctx).Info("email (log driver)", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
+
+type smtpSender struct {
+	cfg config.MailConfig
+}
+
+func (s *smtpSender) Send(ctx context.Context, msg Message) error {
+	from, err := mail.ParseAddress(s.cfg.From)
+	if err != nil {
+		return fmt.Errorf("mail: invalid from address: %w", err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("mail: invalid recipient: %w", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	addr := net.JoinHostPort(s.cfg.SMTPHost, strconv.Itoa(s.cfg.SMTPPort))
+	var auth smtp.Auth
+	if s.cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", s.cfg.SMTPUsername, s.cfg.SMTPPassword, s.cfg.SMTPHost)
+	}
+	if err := smtp.SendMail(addr, auth, from.Address, []string{to.Address}, []byte(b.String())); err != nil {
+		return fmt.Errorf("mail: send to %s via %s: %w", to.Address, addr, err)
+	}
+	slog.Debug("email sent", "to", to.Address, "subject", msg.Subject)
+	return nil
+}
+
+// Recorder menyimpan email di memori; dipakai test handler.
+type Recorder struct {
+	mu   sync.Mutex
+	msgs []Message
+}
+
+func (r *Recorder) Send(ctx context.Context, msg Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, msg)
+	return nil
+}
+
+func (r *Recorder) Messages() []Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Message(nil), r.msgs...)
+}
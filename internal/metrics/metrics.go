@@ -0,0 +1,203 @@
+// Package metrics mendefinisikan metrik Prometheus aplikasi. Setiap Server
+// memiliki registry sendiri sehingga test dapat membuat instance baru tanpa
+// bentrok dengan registry global.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "jaga"
+
+// Kategori gambar untuk metrik upload.
+const (
+	CategoryKTP      = "ktp"
+	CategorySTNK     = "stnk"
+	CategoryKK       = "kk"
+	CategoryEvidence = "evidence"
+	CategoryDetected = "detected"
+	CategoryOther    = "other"
+)
+
+// BusinessSource menyediakan angka untuk gauge bisnis. Nilainya dibaca saat
+// Prometheus melakukan scrape.
+type BusinessSource interface {
+	CountLostReportsByStatus(ctx context.Context) (map[string]int, error)
+	CountActiveCameras(ctx context.Context) (int, error)
+}
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests       *prometheus.CounterVec
+	httpDuration       *prometheus.HistogramVec
+	uploadBytes        *prometheus.CounterVec
+	uploadFailures     *prometheus.CounterVec
+	apiKeyAuthFailures prometheus.Counter
+	rateLimited        *prometheus.CounterVec
+	suspectsCreated    prometheus.Counter
+	suspectsPerRun     prometheus.Histogram
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route template, method and status code.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route template and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		uploadBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "upload_bytes_total",
+			Help:      "Bytes of uploaded images stored, by image category.",
+		}, []string{"category"}),
+		uploadFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "upload_failures_total",
+			Help:      "Rejected or failed image uploads, by image category.",
+		}, []string{"category"}),
+		apiKeyAuthFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "api_key_auth_failures_total",
+			Help:      "Requests rejected because of an invalid X-API-Key.",
+		}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limited_total",
+			Help:      "Requests rejected with 429, by rate limit rule.",
+		}, []string{"rule"}),
+		suspectsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "suspects_created_total",
+			Help:      "Suspects created by the matching pipeline.",
+		}),
+		suspectsPerRun: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "suspects_per_run",
+			Help:      "Number of suspects submitted per matching run.",
+			Buckets:   []float64{0, 1, 2, 5, 10, 20, 50, 100},
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.uploadBytes,
+		m.uploadFailures,
+		m.apiKeyAuthFailures,
+		m.rateLimited,
+		m.suspectsCreated,
+		m.suspectsPerRun,
+	)
+	for _, c := range []string{CategoryKTP, CategorySTNK, CategoryKK, CategoryEvidence, CategoryDetected, CategoryOther} {
+		m.uploadBytes.WithLabelValues(c)
+		m.uploadFailures.WithLabelValues(c)
+	}
+	return m
+}
+
+// RegisterDB menambahkan gauge sql.DBStats untuk pool koneksi db.
+func (m *Metrics) RegisterDB(db *sql.DB) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, "postgres"))
+}
+
+// RegisterBusiness menambahkan gauge jumlah laporan per status dan kamera
+// aktif.
+func (m *Metrics) RegisterBusiness(src BusinessSource) {
+	m.registry.MustRegister(&businessCollector{src: src})
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest mencatat satu request HTTP yang sudah selesai.
+func (m *Metrics) ObserveRequest(method, route string, status int, elapsed time.Duration) {
+	m.httpRequests.WithLabelValues(route, method, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(route, method).Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) UploadSucceeded(category string, bytes int64) {
+	m.uploadBytes.WithLabelValues(category).Add(float64(bytes))
+}
+
+func (m *Metrics) UploadFailed(category string) {
+	m.uploadFailures.WithLabelValues(category).Inc()
+}
+
+func (m *Metrics) APIKeyAuthFailed() {
+	m.apiKeyAuthFailures.Inc()
+}
+
+func (m *Metrics) RateLimited(rule string) {
+	m.rateLimited.WithLabelValues(rule).Inc()
+}
+
+// SuspectsCreated mencatat hasil satu run matching.
+func (m *Metrics) SuspectsCreated(n int) {
+	m.suspectsCreated.Add(float64(n))
+	m.suspectsPerRun.Observe(float64(n))
+}
+
+var (
+	lostReportsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "lost_reports"),
+		"Lost reports by status.",
+		[]string{"status"}, nil,
+	)
+	activeCamerasDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "active_cameras"),
+		"Cameras currently marked active.",
+		nil, nil,
+	)
+)
+
+const businessScrapeTimeout = 5 * time.Second
+
+type businessCollector struct {
+	src BusinessSource
+}
+
+func (c *businessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- lostReportsDesc
+	ch <- activeCamerasDesc
+}
+
+func (c *businessCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), businessScrapeTimeout)
+	defer cancel()
+
+	if counts, err := c.src.CountLostReportsByStatus(ctx); err != nil {
+		slog.Warn("metrics: failed to count lost reports", "error", err)
+		ch <- prometheus.NewInvalidMetric(lostReportsDesc, err)
+	} else {
+		for status, n := range counts {
+			ch <- prometheus.MustNewConstMetric(lostReportsDesc, prometheus.GaugeValue, float64(n), status)
+		}
+	}
+
+	if n, err := c.src.CountActiveCameras(ctx); err != nil {
+		slog.Warn("metrics: failed to count active cameras", "error", err)
+		ch <- prometheus.NewInvalidMetric(activeCamerasDesc, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(activeCamerasDesc, prometheus.GaugeValue, float64(n))
+	}
+}
@@ -2,13 +2,14 @@ package middleware
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 	"net/http"
 	"strings"
 
 	"github.com/jaga-project/jaga-backend/internal/auth"
 	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/metrics"
+	"github.com/jaga-project/jaga-backend/internal/ratelimit"
 )
 
 type contextKey string
@@ -16,30 +17,67 @@ type contextKey string
 const UserIDContextKey = contextKey("userID")
 const AdminStatusContextKey = contextKey("isAdmin")
 
+// AuthMethodContextKey berisi AuthTypeJWT atau AuthTypeAPIKey;
+// APIKeyIDContextKey (int64) hanya diisi untuk autentikasi API key.
+const AuthMethodContextKey = contextKey("authMethod")
+const APIKeyIDContextKey = contextKey("apiKeyID")
+
 func writeJSONError(w http.ResponseWriter, message string, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+	resp := map[string]string{"error": message}
+	if id := w.Header().Get(RequestIDHeader); id != "" {
+		resp["request_id"] = id
+	}
+	json.NewEncoder(w).Encode(resp)
 }
 
-func UnifiedAuthMiddleware(db *sql.DB) func(http.Handler) http.Handler {
+// APIKeyLockout mengunci IP client setelah tebakan API key yang salah
+// berulang, dengan jeda yang berlipat ganda seperti lockout login. Hitungan
+// tidak direset oleh key yang valid agar pemilik satu key tidak bisa terus
+// menebak key lain; kegagalan kedaluwarsa setelah Policy.ResetAfter.
+type APIKeyLockout struct {
+	Limiter    *ratelimit.Limiter
+	Policy     ratelimit.LockoutPolicy
+	TrustProxy bool
+}
+
+// UnifiedAuthMiddleware menerima X-API-Key atau JWT Bearer. lockout boleh
+// nil bila rate limiting dimatikan.
+func UnifiedAuthMiddleware(tokens *auth.Manager, users database.UserRepo, admins database.AdminRepo, lockout *APIKeyLockout, m *metrics.Metrics) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			apiKey := r.Header.Get("X-API-Key")
 			if apiKey != "" {
-				user, err := database.ValidateAPIKeyAndGetUser(r.Context(), db, apiKey)
+				var lockKey string
+				if lockout != nil {
+					lockKey = "api_key_failure:" + ClientIP(r, lockout.TrustProxy)
+					if wait := lockout.Limiter.Locked(r.Context(), lockKey); wait > 0 {
+						SetRetryAfter(w, wait)
+						writeJSONError(w, "Too many failed API key attempts, please retry later", http.StatusTooManyRequests)
+						return
+					}
+				}
+				user, keyID, err := users.FindByAPIKey(r.Context(), apiKey)
 				if err != nil {
+					if lockout != nil {
+						lockout.Limiter.Failure(r.Context(), lockKey, lockout.Policy)
+					}
+					m.APIKeyAuthFailed()
 					writeJSONError(w, "Forbidden: Invalid API Key", http.StatusForbidden)
 					return
 				}
 				
-				isAdmin, err := database.IsUserAdmin(db, user.UserID)
+				isAdmin, err := admins.IsAdmin(r.Context(), user.UserID)
 				if err != nil {
 					writeJSONError(w, "Failed to verify admin status for API key user", http.StatusInternalServerError)
 					return
 				}
+				setAuthInfo(r.Context(), user.UserID, AuthTypeAPIKey)
 				ctx := context.WithValue(r.Context(), UserIDContextKey, user.UserID)
 				ctx = context.WithValue(ctx, AdminStatusContextKey, isAdmin) 
+				ctx = context.WithValue(ctx, AuthMethodContextKey, AuthTypeAPIKey)
+				ctx = context.WithValue(ctx, APIKeyIDContextKey, keyID)
 				next.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
@@ -56,14 +94,16 @@ func UnifiedAuthMiddleware(db *sql.DB) func(http.Handler) http.Handler {
 				return
 			}
 
-			claims, err := auth.ValidateJWT(tokenStr)
+			claims, err := tokens.ValidateJWT(tokenStr)
 			if err != nil {
 				writeJSONError(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
 				return
 			}
 
+			setAuthInfo(r.Context(), claims.UserID, AuthTypeJWT)
 			ctx := context.WithValue(r.Context(), UserIDContextKey, claims.UserID)
 			ctx = context.WithValue(ctx, AdminStatusContextKey, claims.IsAdmin)
+			ctx = context.WithValue(ctx, AuthMethodContextKey, AuthTypeJWT)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/metrics"
+	"github.com/jaga-project/jaga-backend/internal/ratelimit"
+)
+
+// KeyFunc menentukan kunci bucket untuk sebuah request. String kosong berarti
+// request tidak dibatasi oleh aturan ini.
+type KeyFunc func(r *http.Request) string
+
+// RateLimit membatasi request per kunci dengan token bucket dan menjawab 429
+// beserta Retry-After bila bucket habis.
+func RateLimit(l *ratelimit.Limiter, rule string, limit ratelimit.Limit, key KeyFunc, m *metrics.Metrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k := key(r)
+			if k == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			d := l.Allow(r.Context(), rule+":"+k, limit)
+			if !d.Allowed {
+				m.RateLimited(rule)
+				SetRetryAfter(w, d.RetryAfter)
+				writeJSONError(w, "Too many requests, please retry later", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SetRetryAfter menulis header Retry-After dalam detik (dibulatkan ke atas).
+func SetRetryAfter(w http.ResponseWriter, d time.Duration) {
+	secs := int(math.Ceil(d.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+}
+
+// ClientIP mengembalikan IP client. Header proxy hanya dipercaya bila
+// trustProxy aktif.
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			first, _, _ := strings.Cut(xff, ",")
+			if ip := strings.TrimSpace(first); ip != "" {
+				return ip
+			}
+		}
+		if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func KeyByIP(trustProxy bool) KeyFunc {
+	return func(r *http.Request) string {
+		return ClientIP(r, trustProxy)
+	}
+}
+
+// KeyByUser memakai user yang sudah diautentikasi; harus dipasang setelah
+// UnifiedAuthMiddleware.
+func KeyByUser(r *http.Request) string {
+	userID, _ := r.Context().Value(UserIDContextKey).(string)
+	return userID
+}
+
+// KeyByAPIKey memakai ID API key yang sudah diautentikasi; harus dipasang
+// setelah UnifiedAuthMiddleware. Tebakan key yang salah dibatasi oleh
+// APIKeyLockout per IP, bukan oleh aturan ini.
+func KeyByAPIKey(r *http.Request) string {
+	keyID, ok := r.Context().Value(APIKeyIDContextKey).(int64)
+	if !ok {
+		return ""
+	}
+	return strconv.FormatInt(keyID, 10)
+}
+
+// KeyByUserOrIP dipakai untuk kuota upload yang juga berlaku bagi endpoint
+// publik seperti registrasi.
+func KeyByUserOrIP(trustProxy bool) KeyFunc {
+	return func(r *http.Request) string {
+		if userID := KeyByUser(r); userID != "" {
+			return "user:" + userID
+		}
+		return "ip:" + ClientIP(r, trustProxy)
+	}
+}
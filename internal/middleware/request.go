@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+const (
+	AuthTypeJWT    = "jwt"
+	AuthTypeAPIKey = "api_key"
+)
+
+// requestInfo diisi oleh middleware di dalam router (route template, user
+// yang terautentikasi) dan dibaca oleh AccessLog setelah request selesai.
+type requestInfo struct {
+	route    string
+	userID   string
+	authType string
+}
+
+type requestInfoKey struct{}
+
+func infoFromContext(ctx context.Context) *requestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(*requestInfo)
+	return info
+}
+
+func setAuthInfo(ctx context.Context, userID, authType string) {
+	if info := infoFromContext(ctx); info != nil {
+		info.userID = userID
+		info.authType = authType
+	}
+}
+
+// RequestID memakai X-Request-ID dari client bila valid, atau membuat yang
+// baru, lalu mengembalikannya di header response.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+// validRequestID menolak ID kosong, terlalu panjang atau berisi karakter
+// yang bisa merusak log.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(code int) {
+	if sr.status == 0 {
+		sr.status = code
+	}
+	sr.ResponseWriter.WriteHeader(code)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	return sr.ResponseWriter.Write(b)
+}
+
+// RequestObserver menerima ringkasan request yang sudah selesai, mis. untuk
+// metrik.
+type RequestObserver func(method, route string, status int, elapsed time.Duration)
+
+// AccessLog mencatat satu baris log per request dan meneruskan ringkasannya
+// ke observers. Harus dipasang di luar router dan di dalam RequestID.
+func AccessLog(observers ...RequestObserver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			info := &requestInfo{}
+			rec := &statusRecorder{ResponseWriter: w}
+
+			next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info)))
+
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			route := info.route
+			if route == "" {
+				route = "unmatched"
+			}
+
+			elapsed := time.Since(start)
+			for _, observe := range observers {
+				observe(r.Method, route, status, elapsed)
+			}
+
+			level := slog.LevelInfo
+			switch {
+			case status >= 500:
+				level = slog.LevelError
+			case route == "/healthz" || route == "/readyz":
+				level = slog.LevelDebug
+			}
+			logging.FromContext(r.Context()).LogAttrs(r.Context(), level, "http request",
+				slog.String("method", r.Method),
+				slog.String("route", route),
+				slog.Int("status", status),
+				slog.Float64("latency_ms", float64(elapsed.Microseconds())/1000),
+				slog.String("user_id", info.userID),
+				slog.String("auth_type", info.authType),
+			)
+		})
+	}
+}
+
+// RouteTemplate adalah middleware mux yang mencatat template route yang
+// cocok (mis. /api/vehicles/{id}) agar log tidak berisi ID mentah.
+func RouteTemplate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := mux.CurrentRoute(r); route != nil {
+			if tpl, err := route.GetPathTemplate(); err == nil {
+				if info := infoFromContext(r.Context()); info != nil {
+					info.route = tpl
+				}
+				span := trace.SpanFromContext(r.Context())
+				span.SetName(r.Method + " " + tpl)
+				span.SetAttributes(semconv.HTTPRoute(tpl))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
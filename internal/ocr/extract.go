@@ -0,0 +1,65 @@
+package ocr
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Fields adalah data yang diambil dari teks dokumen. Field yang tidak
+// ditemukan dibiarkan kosong.
+type Fields struct {
+	NIK         string
+	Name        string
+	PlateNumber string
+}
+
+var (
+	nikPattern   = regexp.MustCompile(`[0-9]{16}`)
+	platePattern = regexp.MustCompile(`\b([A-Z]{1,2}) ?([0-9]{1,4}) ?([A-Z]{0,3})\b`)
+	// labelPattern membuang label di depan nilai, mis. "Nama : BUDI".
+	labelPattern = regexp.MustCompile(`^[^:]*:\s*`)
+)
+
+// digitFixer memperbaiki huruf yang sering tertukar dengan angka pada
+// baris NIK.
+var digitFixer = strings.NewReplacer("O", "0", "o", "0", "D", "0", "I", "1", "l", "1", "|", "1", "S", "5", "B", "8", "Z", "2", " ", "")
+
+// ExtractKTP mengambil NIK dan nama dari teks KTP.
+func ExtractKTP(text string) Fields {
+	var f Fields
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		upper := strings.ToUpper(line)
+		switch {
+		case f.NIK == "" && strings.HasPrefix(upper, "NIK"):
+			value := labelPattern.ReplaceAllString(line[3:], "")
+			f.NIK = nikPattern.FindString(digitFixer.Replace(value))
+		case f.Name == "" && strings.HasPrefix(upper, "NAMA"):
+			f.Name = strings.Join(strings.Fields(labelPattern.ReplaceAllString(upper[4:], "")), " ")
+		}
+	}
+	if f.NIK == "" {
+		f.NIK = nikPattern.FindString(text)
+	}
+	return f
+}
+
+// ExtractSTNK mengambil nomor plat dari teks STNK. Baris berlabel nomor
+// registrasi atau nomor polisi didahulukan.
+func ExtractSTNK(text string) Fields {
+	var fallback string
+	for _, line := range strings.Split(strings.ToUpper(text), "\n") {
+		m := platePattern.FindStringSubmatch(labelPattern.ReplaceAllString(line, ""))
+		if m == nil {
+			continue
+		}
+		plate := strings.TrimSpace(m[1] + " " + m[2] + " " + m[3])
+		if strings.Contains(line, "REGISTRASI") || strings.Contains(line, "POLISI") {
+			return Fields{PlateNumber: plate}
+		}
+		if fallback == "" {
+			fallback = plate
+		}
+	}
+	return Fields{PlateNumber: fallback}
+}
@@ -0,0 +1,71 @@
+// Package ocr membaca teks dari foto dokumen (KTP dan STNK) agar NIK, nama
+// dan plat yang diketik user bisa dicocokkan dengan fotonya. Engine bisa
+// diganti: tesseract CLI lokal untuk produksi, Stub untuk test.
+package ocr
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/jaga-project/jaga-backend/internal/config"
+)
+
+// Result adalah teks hasil OCR. Confidence berada di rentang 0..1.
+type Result struct {
+	Text       string
+	Confidence float64
+}
+
+type Engine interface {
+	// Name dicatat bersama hasil ekstraksi.
+	Name() string
+	Recognize(ctx context.Context, image io.Reader) (*Result, error)
+}
+
+// New memilih Engine sesuai ocr.engine. Engine "none" mengembalikan nil:
+// upload tetap berjalan tanpa OCR. Config diasumsikan sudah divalidasi.
+func New(cfg config.OCRConfig) Engine {
+	if cfg.Engine == "tesseract" {
+		return &Tesseract{Binary: cfg.TesseractPath, Language: cfg.Language}
+	}
+	return nil
+}
+
+// Stub mengembalikan hasil yang sudah ditentukan; dipakai test handler.
+type Stub struct {
+	mu     sync.Mutex
+	result Result
+	err    error
+	calls  int
+}
+
+// Set menentukan hasil Recognize berikutnya.
+func (s *Stub) Set(result Result, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.result, s.err = result, err
+}
+
+func (s *Stub) Name() string { return "stub" }
+
+func (s *Stub) Recognize(ctx context.Context, image io.Reader) (*Result, error) {
+	if _, err := io.Copy(io.Discard, image); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	res := s.result
+	return &res, nil
+}
+
+// Calls mengembalikan jumlah pemanggilan Recognize.
+func (s *Stub) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
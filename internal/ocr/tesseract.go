@@ -0,0 +1,82 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Tesseract menjalankan CLI tesseract lokal. Gambar dikirim lewat stdin dan
+// hasil dibaca dalam format TSV agar confidence per kata ikut terbaca.
+type Tesseract struct {
+	Binary   string
+	Language string
+}
+
+func (t *Tesseract) Name() string { return "tesseract" }
+
+func (t *Tesseract) Recognize(ctx context.Context, image io.Reader) (*Result, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, t.Binary, "stdin", "stdout", "-l", t.Language, "tsv")
+	cmd.Stdin = image
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("ocr: tesseract: %w", ctx.Err())
+		}
+		return nil, fmt.Errorf("ocr: tesseract: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseTSV(stdout.String())
+}
+
+// parseTSV menyusun ulang kata (level 5) menjadi baris teks dan merata-rata
+// confidence kata yang dikenali. Kolom TSV: level, page_num, block_num,
+// par_num, line_num, word_num, left, top, width, height, conf, text.
+func parseTSV(out string) (*Result, error) {
+	var lines []string
+	var line []string
+	lastKey := ""
+	var confSum float64
+	var words int
+	for i, row := range strings.Split(out, "\n") {
+		if i == 0 || row == "" {
+			continue
+		}
+		cols := strings.SplitN(row, "\t", 12)
+		if len(cols) < 12 || cols[0] != "5" {
+			continue
+		}
+		text := strings.TrimSpace(cols[11])
+		if text == "" {
+			continue
+		}
+		key := strings.Join(cols[1:5], ".")
+		if key != lastKey && len(line) > 0 {
+			lines = append(lines, strings.Join(line, " "))
+			line = nil
+		}
+		lastKey = key
+		line = append(line, text)
+		conf, err := strconv.ParseFloat(cols[10], 64)
+		if err != nil {
+			return nil, fmt.Errorf("ocr: invalid tesseract confidence %q", cols[10])
+		}
+		if conf >= 0 {
+			confSum += conf
+			words++
+		}
+	}
+	if len(line) > 0 {
+		lines = append(lines, strings.Join(line, " "))
+	}
+	res := &Result{Text: strings.Join(lines, "\n")}
+	if words > 0 {
+		res.Confidence = confSum / float64(words) / 100
+	}
+	return res, nil
+}
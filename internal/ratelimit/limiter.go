@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/logging"
+)
+
+// Limiter membungkus Store. Bila Store gagal (mis. database down), request
+// tetap diizinkan agar limiter tidak menjadi single point of failure.
+type Limiter struct {
+	store Store
+}
+
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{store: store}
+}
+
+func (l *Limiter) Store() Store {
+	return l.store
+}
+
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) Decision {
+	d, err := l.store.Take(ctx, key, limit, time.Now())
+	if err != nil {
+		logging.FromContext(ctx).Warn("rate limiter store failed; allowing request", "key", key, "error", err)
+		return Decision{Allowed: true}
+	}
+	return d
+}
+
+// Locked mengembalikan sisa waktu penguncian key, atau 0.
+func (l *Limiter) Locked(ctx context.Context, key string) time.Duration {
+	now := time.Now()
+	until, err := l.store.LockedUntil(ctx, key, now)
+	if err != nil {
+		logging.FromContext(ctx).Warn("rate limiter store failed; skipping lockout check", "key", key, "error", err)
+		return 0
+	}
+	if until.After(now) {
+		return until.Sub(now)
+	}
+	return 0
+}
+
+// Failure mencatat login gagal dan mengembalikan lama penguncian yang
+// dipicu, atau 0.
+func (l *Limiter) Failure(ctx context.Context, key string, policy LockoutPolicy) time.Duration {
+	now := time.Now()
+	until, err := l.store.RecordFailure(ctx, key, policy, now)
+	if err != nil {
+		logging.FromContext(ctx).Warn("rate limiter store failed; login failure not recorded", "key", key, "error", err)
+		return 0
+	}
+	if until.After(now) {
+		return until.Sub(now)
+	}
+	return 0
+}
+
+func (l *Limiter) Success(ctx context.Context, key string) {
+	if err := l.store.ResetFailures(ctx, key); err != nil {
+		logging.FromContext(ctx).Warn("rate limiter store failed; login failures not reset", "key", key, "error", err)
+	}
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type failureState struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// MemoryStore menyimpan state di memori proses. Cocok untuk satu replika.
+type MemoryStore struct {
+	mu       sync.Mutex
+	buckets  map[string]*bucket
+	failures map[string]*failureState
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:  make(map[string]*bucket),
+		failures: make(map[string]*failureState),
+	}
+}
+
+func (m *MemoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Decision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		m.buckets[key] = b
+	}
+	var d Decision
+	b.tokens, d = refill(b.tokens, b.last, now, limit)
+	b.last = now
+	return d, nil
+}
+
+func (m *MemoryStore) LockedUntil(ctx context.Context, key string, now time.Time) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if f, ok := m.failures[key]; ok && f.lockedUntil.After(now) {
+		return f.lockedUntil, nil
+	}
+	return time.Time{}, nil
+}
+
+func (m *MemoryStore) RecordFailure(ctx context.Context, key string, policy LockoutPolicy, now time.Time) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, ok := m.failures[key]
+	if !ok || now.Sub(f.lastFailure) > policy.ResetAfter {
+		f = &failureState{}
+		m.failures[key] = f
+	}
+	f.failures++
+	f.lastFailure = now
+	if d := policy.lockDuration(f.failures); d > 0 {
+		f.lockedUntil = now.Add(d)
+	}
+	return f.lockedUntil, nil
+}
+
+func (m *MemoryStore) ResetFailures(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.failures, key)
+	return nil
+}
+
+func (m *MemoryStore) Cleanup(ctx context.Context, before time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for k, b := range m.buckets {
+		if b.last.Before(before) {
+			delete(m.buckets, k)
+		}
+	}
+	for k, f := range m.failures {
+		if f.lastFailure.Before(before) && f.lockedUntil.Before(before) {
+			delete(m.failures, k)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PostgresStore berbagi state antar replika lewat tabel rate_limit_buckets
+// dan login_failures (lihat migrasi 0002_rate_limits.sql). Setiap operasi
+// mengunci baris key dengan SELECT ... FOR UPDATE.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (p *PostgresStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Decision, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Decision{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+        VALUES ($1, $2, $3) ON CONFLICT (key) DO NOTHING`, key, float64(limit.Burst), now); err != nil {
+		return Decision{}, fmt.Errorf("ratelimit: insert bucket: %w", err)
+	}
+
+	var tokens float64
+	var last time.Time
+	if err := tx.QueryRowContext(ctx, `SELECT tokens, updated_at FROM rate_limit_buckets WHERE key = $1 FOR UPDATE`, key).Scan(&tokens, &last); err != nil {
+		return Decision{}, fmt.Errorf("ratelimit: load bucket: %w", err)
+	}
+
+	tokens, d := refill(tokens, last, now, limit)
+	if _, err := tx.ExecContext(ctx, `UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3 WHERE key = $1`, key, tokens, now); err != nil {
+		return Decision{}, fmt.Errorf("ratelimit: update bucket: %w", err)
+	}
+	return d, tx.Commit()
+}
+
+func (p *PostgresStore) LockedUntil(ctx context.Context, key string, now time.Time) (time.Time, error) {
+	var lockedUntil sql.NullTime
+	err := p.db.QueryRowContext(ctx, `SELECT locked_until FROM login_failures WHERE key = $1`, key).Scan(&lockedUntil)
+	if err == sql.ErrNoRows || (err == nil && (!lockedUntil.Valid || !lockedUntil.Time.After(now))) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ratelimit: load lockout: %w", err)
+	}
+	return lockedUntil.Time, nil
+}
+
+func (p *PostgresStore) RecordFailure(ctx context.Context, key string, policy LockoutPolicy, now time.Time) (time.Time, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO login_failures (key, failures, last_failure_at)
+        VALUES ($1, 0, $2) ON CONFLICT (key) DO NOTHING`, key, now); err != nil {
+		return time.Time{}, fmt.Errorf("ratelimit: insert failure: %w", err)
+	}
+
+	var failures int
+	var lastFailure time.Time
+	var lockedUntil sql.NullTime
+	if err := tx.QueryRowContext(ctx, `SELECT failures, last_failure_at, locked_until FROM login_failures WHERE key = $1 FOR UPDATE`, key).Scan(&failures, &lastFailure, &lockedUntil); err != nil {
+		return time.Time{}, fmt.Errorf("ratelimit: load failure: %w", err)
+	}
+	if now.Sub(lastFailure) > policy.ResetAfter {
+		failures = 0
+		lockedUntil = sql.NullTime{}
+	}
+	failures++
+	if d := policy.lockDuration(failures); d > 0 {
+		lockedUntil = sql.NullTime{Time: now.Add(d), Valid: true}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE login_failures SET failures = $2, last_failure_at = $3, locked_until = $4 WHERE key = $1`,
+		key, failures, now, lockedUntil); err != nil {
+		return time.Time{}, fmt.Errorf("ratelimit: update failure: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, err
+	}
+	if !lockedUntil.Valid {
+		return time.Time{}, nil
+	}
+	return lockedUntil.Time, nil
+}
+
+func (p *PostgresStore) ResetFailures(ctx context.Context, key string) error {
+	_, err := p.db.ExecContext(ctx, `DELETE FROM login_failures WHERE key = $1`, key)
+	return err
+}
+
+func (p *PostgresStore) Cleanup(ctx context.Context, before time.Time) error {
+	if _, err := p.db.ExecContext(ctx, `DELETE FROM rate_limit_buckets WHERE updated_at < $1`, before); err != nil {
+		return err
+	}
+	_, err := p.db.ExecContext(ctx, `DELETE FROM login_failures WHERE last_failure_at < $1 AND (locked_until IS NULL OR locked_until < $1)`, before)
+	return err
+}
@@ -0,0 +1,85 @@
+// Package ratelimit menyediakan token bucket per kunci (IP, user, API key)
+// dan lockout login dengan backoff eksponensial. State disimpan di Store:
+// in-process untuk satu replika, atau Postgres bila ada beberapa replika.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit mendefinisikan token bucket: Requests token diisi ulang setiap Per,
+// dengan kapasitas maksimum Burst.
+type Limit struct {
+	Requests int           `yaml:"requests"`
+	Per      time.Duration `yaml:"per"`
+	Burst    int           `yaml:"burst"`
+}
+
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// Decision adalah hasil satu permintaan token.
+type Decision struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+// LockoutPolicy mengatur penguncian akun setelah login gagal berulang kali.
+// Setelah Threshold kegagalan, akun dikunci selama BaseDelay, lalu dua kali
+// lipat untuk setiap kegagalan berikutnya hingga MaxDelay. Hitungan
+// kegagalan direset setelah login berhasil atau setelah ResetAfter tanpa
+// kegagalan.
+type LockoutPolicy struct {
+	Threshold  int           `yaml:"threshold"`
+	BaseDelay  time.Duration `yaml:"base_delay"`
+	MaxDelay   time.Duration `yaml:"max_delay"`
+	ResetAfter time.Duration `yaml:"reset_after"`
+}
+
+// lockDuration mengembalikan lama penguncian untuk jumlah kegagalan ke-n.
+func (p LockoutPolicy) lockDuration(failures int) time.Duration {
+	if failures < p.Threshold {
+		return 0
+	}
+	exp := failures - p.Threshold
+	if exp > 30 {
+		return p.MaxDelay
+	}
+	d := time.Duration(float64(p.BaseDelay) * math.Pow(2, float64(exp)))
+	if d > p.MaxDelay || d <= 0 {
+		return p.MaxDelay
+	}
+	return d
+}
+
+type Store interface {
+	// Take mengambil satu token dari bucket key.
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Decision, error)
+	// LockedUntil mengembalikan waktu berakhirnya penguncian key, atau waktu
+	// nol bila tidak terkunci.
+	LockedUntil(ctx context.Context, key string, now time.Time) (time.Time, error)
+	// RecordFailure mencatat satu kegagalan login dan mengembalikan waktu
+	// berakhirnya penguncian bila kegagalan ini memicu lockout.
+	RecordFailure(ctx context.Context, key string, policy LockoutPolicy, now time.Time) (time.Time, error)
+	// ResetFailures menghapus hitungan kegagalan key.
+	ResetFailures(ctx context.Context, key string) error
+	// Cleanup menghapus state yang tidak disentuh sejak before.
+	Cleanup(ctx context.Context, before time.Time) error
+}
+
+// refill menghitung isi bucket setelah waktu berlalu lalu mencoba mengambil
+// satu token. Dipakai bersama oleh semua Store.
+func refill(tokens float64, last, now time.Time, limit Limit) (float64, Decision) {
+	burst := float64(limit.Burst)
+	if elapsed := now.Sub(last).Seconds(); elapsed > 0 {
+		tokens = math.Min(burst, tokens+elapsed*limit.rate())
+	}
+	if tokens >= 1 {
+		return tokens - 1, Decision{Allowed: true}
+	}
+	wait := time.Duration((1 - tokens) / limit.rate() * float64(time.Second))
+	return tokens, Decision{Allowed: false, RetryAfter: wait}
+}
@@ -0,0 +1,281 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/jaga-project/jaga-backend/internal/auth"
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+	"github.com/jaga-project/jaga-backend/internal/mail"
+	"github.com/jaga-project/jaga-backend/internal/middleware"
+)
+
+// issueUserToken membatalkan token lama dengan purpose yang sama lalu
+// membuat token baru, sehingga hanya link terakhir yang berlaku.
+func issueUserToken(ctx context.Context, repos database.Repositories, userID, purpose string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	if err := repos.UserTokens.InvalidateForUser(ctx, userID, purpose, now); err != nil {
+		return "", err
+	}
+	raw, hash, err := auth.NewOneTimeToken()
+	if err != nil {
+		return "", err
+	}
+	err = repos.UserTokens.Create(ctx, &database.UserToken{
+		TokenHash: hash,
+		UserID:    userID,
+		Purpose:   purpose,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	})
+	return raw, err
+}
+
+func (s *Server) appLink(path, token string) string {
+	return strings.TrimRight(s.cfg.Mail.AppBaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// sendMail tidak menggagalkan request; kegagalan hanya dicatat karena user
+// dapat meminta email ulang.
+func (s *Server) sendMail(ctx context.Context, msg mail.Message) {
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		logging.FromContext(ctx).Error("failed to send email", "subject", msg.Subject, "error", err)
+	}
+}
+
+func (s *Server) sendVerificationEmail(ctx context.Context, user *database.User, token string) {
+	s.sendMail(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email akun JAGA",
+		Body: fmt.Sprintf("Halo %s,\n\nBuka link berikut untuk memverifikasi email Anda:\n%s\n\nLink berlaku selama %s.\n",
+			user.Name, s.appLink("/verify-email", token), s.cfg.Auth.EmailVerificationTTL),
+	})
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// handleForgotPassword selalu menjawab 202 agar tidak bisa dipakai untuk
+// mengecek apakah sebuah email terdaftar.
+func (s *Server) handleForgotPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req forgotPasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Email == "" {
+			writeJSONError(w, "Email is required", http.StatusBadRequest)
+			return
+		}
+
+		accepted := func() {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(map[string]string{"message": "If the email is registered, a password reset link has been sent"})
+		}
+
+		user, err := s.repos.Users.FindByEmail(r.Context(), req.Email)
+		if err != nil {
+			if !errors.Is(err, database.ErrUserNotFound) {
+				logging.FromContext(r.Context()).Error("failed to look up user for password reset", "error", err)
+			}
+			accepted()
+			return
+		}
+
+		token, err := issueUserToken(r.Context(), s.repos, user.UserID, database.TokenPurposePasswordReset, s.cfg.Auth.PasswordResetTTL)
+		if err != nil {
+			writeJSONError(w, "Failed to create password reset token: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.sendMail(r.Context(), mail.Message{
+			To:      user.Email,
+			Subject: "Reset password akun JAGA",
+			Body: fmt.Sprintf("Halo %s,\n\nKami menerima permintaan reset password. Buka link berikut untuk membuat password baru:\n%s\n\nLink berlaku selama %s dan hanya dapat dipakai sekali. Abaikan email ini jika Anda tidak memintanya.\n",
+				user.Name, s.appLink("/reset-password", token), s.cfg.Auth.PasswordResetTTL),
+		})
+		accepted()
+	}
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (s *Server) handleResetPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req resetPasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Token == "" || req.Password == "" {
+			writeJSONError(w, "Token and password are required", http.StatusBadRequest)
+			return
+		}
+
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		repos := tx.Repos()
+
+		now := time.Now()
+		token, err := repos.UserTokens.Consume(r.Context(), auth.HashOneTimeToken(req.Token), database.TokenPurposePasswordReset, now)
+		if err != nil {
+			if errors.Is(err, database.ErrTokenInvalid) {
+				writeJSONError(w, "Password reset token is invalid or has expired", http.StatusBadRequest)
+			} else {
+				writeJSONError(w, "Failed to verify token: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		user, err := repos.Users.FindByID(r.Context(), token.UserID)
+		if err != nil {
+			writeJSONError(w, "Failed to retrieve user: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Token tetap terpakai hanya bila transaksi di-commit, jadi password
+		// yang ditolak tidak menghanguskan link.
+		if err := s.passwords.Validate(req.Password, user.Email, user.Name); err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			writeJSONError(w, "Failed to hash password: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		updates := map[string]interface{}{"password": string(hash)}
+		// Link reset hanya sampai ke pemilik email, jadi email sekaligus
+		// terbukti valid.
+		if user.EmailVerifiedAt == nil {
+			updates["email_verified_at"] = now
+		}
+		if err := repos.Users.Update(r.Context(), user.UserID, updates); err != nil {
+			writeJSONError(w, "Failed to update password: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := repos.UserTokens.InvalidateForUser(r.Context(), user.UserID, database.TokenPurposePasswordReset, now); err != nil {
+			writeJSONError(w, "Failed to invalidate reset tokens: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if s.limiter != nil {
+			s.limiter.Success(r.Context(), loginLockoutKey(user.Email))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"message": "Password has been reset"})
+	}
+}
+
+type verifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+func (s *Server) handleVerifyEmail() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req verifyEmailRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Token == "" {
+			writeJSONError(w, "Token is required", http.StatusBadRequest)
+			return
+		}
+
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		now := time.Now()
+		token, err := tx.Repos().UserTokens.Consume(r.Context(), auth.HashOneTimeToken(req.Token), database.TokenPurposeEmailVerification, now)
+		if err != nil {
+			if errors.Is(err, database.ErrTokenInvalid) {
+				writeJSONError(w, "Verification token is invalid or has expired", http.StatusBadRequest)
+			} else {
+				writeJSONError(w, "Failed to verify token: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if err := tx.Repos().Users.Update(r.Context(), token.UserID, map[string]interface{}{"email_verified_at": now}); err != nil {
+			writeJSONError(w, "Failed to verify email: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"message": "Email verified", "email_verified_at": now})
+	}
+}
+
+func (s *Server) handleResendVerification() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value(middleware.UserIDContextKey).(string)
+		if !ok || userID == "" {
+			writeJSONError(w, "Unauthorized: User ID not found in token", http.StatusUnauthorized)
+			return
+		}
+		user, err := s.repos.Users.FindByID(r.Context(), userID)
+		if err != nil {
+			writeJSONError(w, "Failed to retrieve user: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if user.EmailVerifiedAt != nil {
+			writeJSONError(w, "Email is already verified", http.StatusConflict)
+			return
+		}
+
+		token, err := issueUserToken(r.Context(), s.repos, user.UserID, database.TokenPurposeEmailVerification, s.cfg.Auth.EmailVerificationTTL)
+		if err != nil {
+			writeJSONError(w, "Failed to create verification token: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.sendVerificationEmail(r.Context(), user, token)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Verification email sent"})
+	}
+}
+
+func (s *Server) RegisterAccountRoutes(r *mux.Router) {
+	limit := s.rateLimit("account", s.cfg.RateLimit.Login, middleware.KeyByIP(s.cfg.RateLimit.TrustProxyHeaders))
+	r.Handle("/auth/forgot-password", limit(s.handleForgotPassword())).Methods("POST")
+	r.Handle("/auth/reset-password", limit(s.handleResetPassword())).Methods("POST")
+	r.Handle("/auth/verify-email", limit(s.handleVerifyEmail())).Methods("POST")
+}
+
+func (s *Server) RegisterAccountProtectedRoutes(r *mux.Router) {
+	limit := s.rateLimit("verification_email", s.cfg.RateLimit.Login, middleware.KeyByUser)
+	r.Handle("/users/me/verification-email", limit(s.handleResendVerification())).Methods("POST")
+}
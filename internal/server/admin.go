@@ -7,10 +7,10 @@ import (
 	"net/http"
 	"strings"
 	"time"
-	"fmt"
 
 	"github.com/gorilla/mux"
 	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/logging"
 	"github.com/jaga-project/jaga-backend/internal/middleware"
 )
 
@@ -28,15 +28,15 @@ func (s *Server) handleCreateAdmin() http.HandlerFunc {
 		}
 
 		admin.CreatedAt = time.Now()
-		tx, err := s.db.Get().BeginTx(r.Context(), nil)
+		tx, err := s.store.BeginTx(r.Context())
         if err != nil {
-            fmt.Printf("ERROR handleCreateDetected: Failed to start database transaction: %v\n", err)
+            logging.FromContext(r.Context()).Error("failed to begin transaction for admin creation", "error", err)
             writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
             return
         }
 		defer tx.Rollback()
 
-		if err := database.CreateAdminTx(r.Context(), tx, &admin); err != nil {
+		if err := tx.Repos().Admins.Create(r.Context(), &admin); err != nil {
 			if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
 				writeJSONError(w, "This user is already an admin", http.StatusConflict)
 				return
@@ -44,6 +44,10 @@ func (s *Server) handleCreateAdmin() http.HandlerFunc {
 			writeJSONError(w, "Failed to create admin: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
+		if err := s.audit(r, tx.Repos(), auditChange{Action: "admin.create", EntityType: "admin", EntityID: admin.UserID, After: admin}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
 		if err := tx.Commit(); err != nil {
             writeJSONError(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
             return
@@ -58,7 +62,7 @@ func (s *Server) handleGetAdmin() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID := mux.Vars(r)["user_id"] 
 		if userID != "" {
-			admin, err := database.GetAdminByUserID(r.Context(), s.db.Get(), userID)
+			admin, err := s.repos.Admins.GetByUserID(r.Context(), userID)
 			if err != nil {
 				if errors.Is(err, sql.ErrNoRows) {
 					writeJSONError(w, "Admin not found", http.StatusNotFound)
@@ -73,7 +77,7 @@ func (s *Server) handleGetAdmin() http.HandlerFunc {
 		}
 
 		// Jika tidak ada user_id di path, list semua admin
-		admins, err := database.ListAdmin(r.Context(), s.db.Get())
+		admins, err := s.repos.Admins.List(r.Context())
 		if err != nil {
 			writeJSONError(w, "Failed to list admins: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -92,17 +96,43 @@ func (s *Server) handleUpdateAdmin() http.HandlerFunc {
 			return
 		}
 
-		if err := database.UpdateAdmin(r.Context(), s.db.Get(), userID, &adminUpdates); err != nil {
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		existingAdmin, err := tx.Repos().Admins.GetByUserID(r.Context(), userID)
+		if err != nil {
+			if errors.Is(err, database.ErrAdminNotFound) {
+				writeJSONError(w, "Admin not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to get admin: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if err := tx.Repos().Admins.Update(r.Context(), userID, &adminUpdates); err != nil {
 			writeJSONError(w, "Failed to update admin: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		updatedAdmin, err := database.GetAdminByUserID(r.Context(), s.db.Get(), userID)
+		updatedAdmin, err := tx.Repos().Admins.GetByUserID(r.Context(), userID)
 		if err != nil {
 			writeJSONError(w, "Failed to retrieve updated admin data: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
+		if err := s.audit(r, tx.Repos(), auditChange{Action: "admin.update", EntityType: "admin", EntityID: userID, Before: existingAdmin, After: updatedAdmin}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(updatedAdmin)
@@ -113,7 +143,24 @@ func (s *Server) handleDeleteAdmin() http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
         userID := mux.Vars(r)["user_id"]
 
-        if err := database.DeleteAdmin(r.Context(), s.db.Get(), userID); err != nil {
+        tx, err := s.store.BeginTx(r.Context())
+        if err != nil {
+            writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+        defer tx.Rollback()
+
+        existingAdmin, err := tx.Repos().Admins.GetByUserID(r.Context(), userID)
+        if err != nil {
+            if errors.Is(err, database.ErrAdminNotFound) {
+                w.WriteHeader(http.StatusNoContent)
+                return
+            }
+            writeJSONError(w, "Failed to get admin: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+
+        if err := tx.Repos().Admins.Delete(r.Context(), userID); err != nil {
             if errors.Is(err, sql.ErrNoRows) {
                 w.WriteHeader(http.StatusNoContent)
                 return
@@ -122,6 +169,15 @@ func (s *Server) handleDeleteAdmin() http.HandlerFunc {
             return
         }
 
+        if err := s.audit(r, tx.Repos(), auditChange{Action: "admin.delete", EntityType: "admin", EntityID: userID, Before: existingAdmin}); err != nil {
+            writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+            return
+        }
+        if err := tx.Commit(); err != nil {
+            writeJSONError(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+
         w.WriteHeader(http.StatusNoContent)
     }
 }
@@ -0,0 +1,344 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+	"github.com/jaga-project/jaga-backend/internal/middleware"
+)
+
+// queueAnalysisRun mengantrekan run baru untuk laporan. Dipanggil di dalam
+// transaksi pembuatan laporan dan re-run admin.
+func (s *Server) queueAnalysisRun(ctx context.Context, repos database.Repositories, lostID int, p database.SearchParams, requestedBy string) (*database.AnalysisRun, error) {
+	run := database.AnalysisRun{LostID: lostID, RadiusKm: p.RadiusKm, WindowHours: p.WindowHours, CameraIDs: p.CameraIDs, Zones: p.Zones, CreatedAt: time.Now()}
+	if requestedBy != "" {
+		run.RequestedBy = &requestedBy
+	}
+	if err := repos.AnalysisRuns.Create(ctx, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+type rerunAnalysisRequest struct {
+	RadiusKm    *float64  `json:"radius_km"`
+	WindowHours *int      `json:"window_hours"`
+	CameraIDs   *[]int64  `json:"camera_ids"`
+	Zones       *[]string `json:"zones"`
+}
+
+// handleRerunAnalysis mengantrekan ulang analisis sebuah laporan. Parameter
+// yang tidak diisi diambil dari parameter pencarian laporan, lalu dari run
+// terakhir, lalu dari konfigurasi. Nilai di body hanya berlaku untuk run ini
+// dan tidak mengubah parameter tersimpan.
+func (s *Server) handleRerunAnalysis() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		lostID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "invalid lost_report_id: must be an integer", http.StatusBadRequest)
+			return
+		}
+		var req rerunAnalysisRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		adminID, _ := ctx.Value(middleware.UserIDContextKey).(string)
+
+		tx, err := s.store.BeginTx(ctx)
+		if err != nil {
+			writeJSONError(w, "Failed to start transaction", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		repos := tx.Repos()
+
+		report, err := repos.LostReports.GetByID(ctx, lostID)
+		if err != nil {
+			if errors.Is(err, database.ErrLostReportNotFound) {
+				writeJSONError(w, "Lost report not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to get lost report: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		search, err := s.effectiveSearch(ctx, repos, lostID)
+		if err != nil {
+			writeJSONError(w, "Failed to get search parameters: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		params := search.SearchParams
+		latest, err := repos.AnalysisRuns.GetLatestByLostReportID(ctx, lostID)
+		switch {
+		case err == nil:
+			if latest.Active() {
+				writeJSONError(w, "Analysis for this lost report is already queued or running", http.StatusConflict)
+				return
+			}
+			if !search.Custom {
+				params = latest.SearchParams()
+			}
+		case !errors.Is(err, database.ErrAnalysisRunNotFound):
+			writeJSONError(w, "Failed to get analysis run: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if req.RadiusKm != nil {
+			params.RadiusKm = *req.RadiusKm
+		}
+		if req.WindowHours != nil {
+			params.WindowHours = *req.WindowHours
+		}
+		if req.CameraIDs != nil {
+			params.CameraIDs = *req.CameraIDs
+		}
+		if req.Zones != nil {
+			params.Zones = *req.Zones
+		}
+		normalizeSearchParams(&params)
+		cameras, err := repos.Cameras.List(ctx)
+		if err != nil {
+			writeJSONError(w, "Failed to list cameras: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if _, err := validateSearchParams(&search.Limits, cameras, report, params); err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		run, err := s.queueAnalysisRun(ctx, repos, lostID, params, adminID)
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to queue analysis run", "lost_id", lostID, "error", err)
+			writeJSONError(w, "Failed to queue analysis run: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := s.audit(r, repos, auditChange{Action: "analysis_run.create", EntityType: "analysis_run", EntityID: run.RunID, After: run}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit transaction", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(run)
+	}
+}
+
+func (s *Server) handleListAnalysisRuns() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lostID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "invalid lost_report_id: must be an integer", http.StatusBadRequest)
+			return
+		}
+		runs, err := s.repos.AnalysisRuns.ListByLostReportID(r.Context(), lostID)
+		if err != nil {
+			writeJSONError(w, "Failed to list analysis runs: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if runs == nil {
+			runs = []database.AnalysisRun{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(runs)
+	}
+}
+
+// AnalysisJob adalah run yang diambil worker beserta data laporan yang
+// dibutuhkan untuk mencari deteksi. Worker hanya memeriksa deteksi dari
+// CameraIDs dalam WindowStart..WindowEnd.
+type AnalysisJob struct {
+	Run         database.AnalysisRun `json:"run"`
+	LostID      int                  `json:"lost_id"`
+	VehicleID   int                  `json:"vehicle_id"`
+	Timestamp   time.Time            `json:"timestamp"`
+	Latitude    *float64             `json:"latitude,omitempty"`
+	Longitude   *float64             `json:"longitude,omitempty"`
+	WindowStart time.Time            `json:"window_start"`
+	WindowEnd   time.Time            `json:"window_end"`
+	CameraIDs   []int64              `json:"camera_ids"`
+}
+
+// handleClaimAnalysisRun dipakai worker scorer (API key admin) untuk
+// mengambil run berikutnya. Antrean kosong dijawab 204. Laporan yang belum
+// diproses sekaligus dipindahkan ke SEDANG_DIPROSES.
+func (s *Server) handleClaimAnalysisRun() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		var req struct {
+			ScorerVersion string `json:"scorer_version"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		req.ScorerVersion = strings.TrimSpace(req.ScorerVersion)
+		if req.ScorerVersion == "" {
+			writeJSONError(w, "scorer_version is required", http.StatusBadRequest)
+			return
+		}
+
+		tx, err := s.store.BeginTx(ctx)
+		if err != nil {
+			writeJSONError(w, "Failed to start transaction", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		repos := tx.Repos()
+
+		run, err := repos.AnalysisRuns.Claim(ctx, req.ScorerVersion, time.Now())
+		if err != nil {
+			if errors.Is(err, database.ErrAnalysisRunNotFound) {
+				w.WriteHeader(http.StatusNoContent)
+			} else {
+				logging.FromContext(ctx).Error("failed to claim analysis run", "error", err)
+				writeJSONError(w, "Failed to claim analysis run: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		report, err := repos.LostReports.GetByID(ctx, run.LostID)
+		if err != nil {
+			writeJSONError(w, "Failed to get lost report: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		cameras, err := repos.Cameras.List(ctx)
+		if err != nil {
+			writeJSONError(w, "Failed to list cameras: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		scope := scopeCameras(cameras, report.Latitude, report.Longitude, run.SearchParams())
+		if err := s.audit(r, repos, auditChange{Action: "analysis_run.claim", EntityType: "analysis_run", EntityID: run.RunID, After: run}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if report.Status == database.StatusLostReportBelumDiproses {
+			before := *report
+			report.Status = database.StatusLostReportSedangDiproses
+			if err := repos.LostReports.Update(ctx, report.LostID, report); err != nil {
+				writeJSONError(w, "Failed to update lost report: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if err := s.audit(r, repos, auditChange{Action: "lost_report.status_change", EntityType: "lost_report", EntityID: report.LostID, Before: before, After: report}); err != nil {
+				writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+				return
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit transaction", http.StatusInternalServerError)
+			return
+		}
+
+		job := AnalysisJob{
+			Run:       *run,
+			LostID:    report.LostID,
+			VehicleID: report.VehicleID,
+			Timestamp: report.Timestamp,
+			Latitude:  report.Latitude,
+			Longitude: report.Longitude,
+			CameraIDs: searchCameraIDs(scope),
+		}
+		job.WindowStart, job.WindowEnd = run.SearchParams().Window(report.Timestamp)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(job)
+	}
+}
+
+type finishAnalysisRunRequest struct {
+	DetectionsScanned *int   `json:"detections_scanned"`
+	Error             string `json:"error"`
+}
+
+// handleFinishAnalysisRun menutup run RUNNING sebagai COMPLETED atau FAILED.
+// Suspect hasil run dikirim lebih dulu lewat POST /api/suspects/batch.
+func (s *Server) handleFinishAnalysisRun(status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			writeJSONError(w, "Invalid analysis run ID", http.StatusBadRequest)
+			return
+		}
+		var req finishAnalysisRunRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		req.Error = strings.TrimSpace(req.Error)
+		switch {
+		case req.DetectionsScanned != nil && *req.DetectionsScanned < 0:
+			writeJSONError(w, "detections_scanned must not be negative", http.StatusBadRequest)
+			return
+		case status == database.AnalysisRunCompleted && req.DetectionsScanned == nil:
+			writeJSONError(w, "detections_scanned is required", http.StatusBadRequest)
+			return
+		case status == database.AnalysisRunCompleted && req.Error != "":
+			writeJSONError(w, "error is only allowed when failing a run", http.StatusBadRequest)
+			return
+		case status == database.AnalysisRunFailed && req.Error == "":
+			writeJSONError(w, "error is required", http.StatusBadRequest)
+			return
+		}
+
+		tx, err := s.store.BeginTx(ctx)
+		if err != nil {
+			writeJSONError(w, "Failed to start transaction", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		repos := tx.Repos()
+
+		existing, err := repos.AnalysisRuns.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, database.ErrAnalysisRunNotFound) {
+				writeJSONError(w, "Analysis run not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to get analysis run: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if err := repos.AnalysisRuns.Finish(ctx, id, status, req.DetectionsScanned, req.Error, time.Now()); err != nil {
+			if errors.Is(err, database.ErrAnalysisRunNotRunning) {
+				writeJSONError(w, "Analysis run is not running", http.StatusConflict)
+			} else {
+				writeJSONError(w, "Failed to update analysis run: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		updated, err := repos.AnalysisRuns.GetByID(ctx, id)
+		if err != nil {
+			writeJSONError(w, "Failed to get analysis run: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := s.audit(r, repos, auditChange{Action: "analysis_run.finish", EntityType: "analysis_run", EntityID: id, Before: existing, After: updated}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit transaction", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(updated)
+	}
+}
+
+// RegisterAdminAnalysisRoutes harus didaftarkan sebelum RegisterAdminRoutes
+// agar path-nya tidak tertangkap route /{user_id}.
+func (s *Server) RegisterAdminAnalysisRoutes(r *mux.Router) {
+	r.Handle("/lost_reports/{id:[0-9]+}/analysis-runs", s.handleListAnalysisRuns()).Methods("GET")
+	r.Handle("/lost_reports/{id:[0-9]+}/analysis-runs", s.handleRerunAnalysis()).Methods("POST")
+	r.Handle("/analysis-runs/claim", s.handleClaimAnalysisRun()).Methods("POST")
+	r.Handle("/analysis-runs/{id:[0-9]+}/complete", s.handleFinishAnalysisRun(database.AnalysisRunCompleted)).Methods("POST")
+	r.Handle("/analysis-runs/{id:[0-9]+}/fail", s.handleFinishAnalysisRun(database.AnalysisRunFailed)).Methods("POST")
+}
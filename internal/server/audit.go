@@ -0,0 +1,385 @@
+package server
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+	"github.com/jaga-project/jaga-backend/internal/middleware"
+)
+
+const (
+	defaultAuditPageSize = 100
+	maxAuditPageSize     = 1000
+)
+
+// Field yang nilainya tidak boleh masuk audit_log: kredensial dan data
+// pribadi yang dihapus saat permintaan penghapusan data disetujui. Audit log
+// tidak bisa diubah tanpa merusak rantai hash-nya, jadi nilainya tidak
+// pernah disimpan. Perubahannya tetap tercatat, hanya nilainya yang
+// disamarkan.
+var redactedAuditFields = map[string]bool{
+	"password":             true,
+	"nik":                  true,
+	"phone":                true,
+	"secret":               true,
+	"key_hash":             true,
+	"email":                true,
+	"recipient_email":      true,
+	"name":                 true,
+	"user_name":            true,
+	"plate_number":         true,
+	"address":              true,
+	"distinguishing_marks": true,
+}
+
+// publicNameEntities adalah entitas yang field name-nya bukan nama orang.
+var publicNameEntities = map[string]bool{
+	"camera": true,
+}
+
+// auditChange adalah satu perubahan data yang dicatat ke audit_log. Before
+// nil berarti create, After nil berarti delete.
+type auditChange struct {
+	Action     string
+	EntityType string
+	EntityID   interface{}
+	Before     interface{}
+	After      interface{}
+}
+
+// auditScope menandai bahwa handler sudah menulis entri audit sendiri,
+// sehingga auditAdminRequests tidak perlu menulis entri umum.
+type auditScope struct {
+	recorded bool
+}
+
+type auditScopeKey struct{}
+
+// audit menulis perubahan ke audit_log lewat repos (biasanya milik transaksi
+// yang sama dengan perubahan tersebut). Panggil tepat sebelum Commit; bila
+// gagal, transaksi sebaiknya dibatalkan agar tidak ada perubahan tanpa jejak.
+// Error sudah dicatat ke log.
+func (s *Server) audit(r *http.Request, repos database.Repositories, c auditChange) error {
+	err := s.appendAudit(r, repos, c)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("failed to write audit log", "action", c.Action, "entity_id", c.EntityID, "error", err)
+	}
+	return err
+}
+
+func (s *Server) appendAudit(r *http.Request, repos database.Repositories, c auditChange) error {
+	before, after, err := auditDiff(c.EntityType, c.Before, c.After)
+	if err != nil {
+		return err
+	}
+	e := s.newAuditEntry(r)
+	e.Action = c.Action
+	e.EntityType = c.EntityType
+	e.EntityID = fmt.Sprint(c.EntityID)
+	e.Before = before
+	e.After = after
+	if err := repos.AuditLog.Append(r.Context(), e); err != nil {
+		return err
+	}
+	if scope, ok := r.Context().Value(auditScopeKey{}).(*auditScope); ok {
+		scope.recorded = true
+	}
+	return nil
+}
+
+func (s *Server) newAuditEntry(r *http.Request) *database.AuditEntry {
+	ctx := r.Context()
+	e := &database.AuditEntry{
+		AuthMethod: database.AuditAuthAnonymous,
+		IP:         middleware.ClientIP(r, s.cfg.RateLimit.TrustProxyHeaders),
+		RequestID:  logging.RequestID(ctx),
+	}
+	if userID, ok := ctx.Value(middleware.UserIDContextKey).(string); ok && userID != "" {
+		e.ActorUserID = &userID
+	}
+	switch ctx.Value(middleware.AuthMethodContextKey) {
+	case middleware.AuthTypeJWT:
+		e.AuthMethod = database.AuditAuthJWT
+	case middleware.AuthTypeAPIKey:
+		e.AuthMethod = database.AuditAuthAPIKey
+		if keyID, ok := ctx.Value(middleware.APIKeyIDContextKey).(int64); ok {
+			e.APIKeyID = &keyID
+		}
+	}
+	return e
+}
+
+// auditDiff mengubah before/after menjadi JSON. Bila keduanya ada, hanya
+// field yang berubah yang disimpan.
+func auditDiff(entityType string, before, after interface{}) (json.RawMessage, json.RawMessage, error) {
+	b, err := auditFields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := auditFields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b != nil && a != nil {
+		for k, v := range b {
+			if reflect.DeepEqual(v, a[k]) {
+				delete(b, k)
+				delete(a, k)
+			}
+		}
+	}
+	// Disamarkan setelah dibandingkan agar perubahan field rahasia tetap
+	// terlihat.
+	redactAuditValue(entityType, b)
+	redactAuditValue(entityType, a)
+	return marshalAuditFields(b), marshalAuditFields(a), nil
+}
+
+func auditFields(v interface{}) (map[string]interface{}, error) {
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// redactAuditValue menyamarkan field rahasia di v, termasuk di objek dan
+// array bersarang.
+func redactAuditValue(entityType string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if redactedAuditFields[k] && !(k == "name" && publicNameEntities[entityType]) {
+				if field != nil && field != "" {
+					v[k] = "[redacted]"
+				}
+				continue
+			}
+			redactAuditValue(entityType, field)
+		}
+	case []interface{}:
+		for _, item := range v {
+			redactAuditValue(entityType, item)
+		}
+	}
+}
+
+func marshalAuditFields(fields map[string]interface{}) json.RawMessage {
+	if fields == nil {
+		return nil
+	}
+	// encoding/json mengurutkan key map, sehingga hasilnya deterministik.
+	raw, _ := json.Marshal(fields)
+	return raw
+}
+
+type auditStatusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *auditStatusRecorder) WriteHeader(code int) {
+	if rec.status == 0 {
+		rec.status = code
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *auditStatusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// auditAdminRequests mencatat setiap request admin yang mengubah data dan
+// berhasil, bila handler-nya tidak menulis entri audit yang lebih rinci.
+// Dipasang setelah UnifiedAuthMiddleware.
+func (s *Server) auditAdminRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		isAdmin, _ := r.Context().Value(middleware.AdminStatusContextKey).(bool)
+		if !isAdmin || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		scope := &auditScope{}
+		rec := &auditStatusRecorder{ResponseWriter: w}
+		r = r.WithContext(context.WithValue(r.Context(), auditScopeKey{}, scope))
+		next.ServeHTTP(rec, r)
+
+		if scope.recorded || rec.status >= 400 {
+			return
+		}
+		route := r.URL.Path
+		if cur := mux.CurrentRoute(r); cur != nil {
+			if tpl, err := cur.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if err := s.auditRequest(r, route, status); err != nil {
+			logging.FromContext(r.Context()).Error("failed to write audit log for admin request", "route", route, "error", err)
+		}
+	})
+}
+
+func (s *Server) auditRequest(r *http.Request, route string, status int) error {
+	// Request mungkin sudah dibatalkan client setelah response terkirim.
+	ctx := context.WithoutCancel(r.Context())
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = s.appendAudit(r.WithContext(ctx), tx.Repos(), auditChange{
+		Action:     "admin.request",
+		EntityType: "route",
+		EntityID:   r.Method + " " + route,
+		After:      map[string]interface{}{"path": r.URL.Path, "status": status},
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func parseAuditFilter(r *http.Request, paginate bool) (database.AuditFilter, error) {
+	q := r.URL.Query()
+	f := database.AuditFilter{
+		ActorUserID: q.Get("actor_user_id"),
+		Action:      q.Get("action"),
+		EntityType:  q.Get("entity_type"),
+		EntityID:    q.Get("entity_id"),
+	}
+	for name, dst := range map[string]**time.Time{"from": &f.From, "to": &f.To} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return f, fmt.Errorf("invalid %s, expected RFC3339 timestamp", name)
+			}
+			*dst = &t
+		}
+	}
+	if paginate {
+		f.Limit = defaultAuditPageSize
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxAuditPageSize {
+			return f, fmt.Errorf("limit must be between 1 and %d", maxAuditPageSize)
+		}
+		f.Limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return f, fmt.Errorf("offset must be a non-negative integer")
+		}
+		f.Offset = n
+	}
+	return f, nil
+}
+
+func (s *Server) handleListAuditLog() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f, err := parseAuditFilter(r, true)
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		entries, err := s.repos.AuditLog.List(r.Context(), f)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to list audit log", "error", err)
+			writeJSONError(w, "Failed to list audit log: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"entries": entries,
+			"limit":   f.Limit,
+			"offset":  f.Offset,
+		})
+	}
+}
+
+func (s *Server) handleExportAuditLog() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f, err := parseAuditFilter(r, false)
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		entries, err := s.repos.AuditLog.List(r.Context(), f)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to export audit log", "error", err)
+			writeJSONError(w, "Failed to export audit log: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="audit_log.csv"`)
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"id", "occurred_at", "actor_user_id", "auth_method", "api_key_id", "action",
+			"entity_type", "entity_id", "before", "after", "ip", "request_id", "prev_hash", "hash"})
+		for _, e := range entries {
+			var actor, keyID string
+			if e.ActorUserID != nil {
+				actor = *e.ActorUserID
+			}
+			if e.APIKeyID != nil {
+				keyID = strconv.FormatInt(*e.APIKeyID, 10)
+			}
+			cw.Write([]string{strconv.FormatInt(e.ID, 10), e.OccurredAt.UTC().Format(time.RFC3339Nano), actor,
+				e.AuthMethod, keyID, e.Action, e.EntityType, e.EntityID, string(e.Before), string(e.After),
+				e.IP, e.RequestID, e.PrevHash, e.Hash})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			logging.FromContext(r.Context()).Warn("failed to write audit log CSV", "error", err)
+		}
+	}
+}
+
+func (s *Server) handleVerifyAuditLog() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, err := s.repos.AuditLog.Verify(r.Context())
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to verify audit log", "error", err)
+			writeJSONError(w, "Failed to verify audit log: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !result.Valid {
+			logging.FromContext(r.Context()).Error("audit log hash chain is broken", "broken_at_id", *result.BrokenAtID)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+// RegisterAuditRoutes harus didaftarkan sebelum RegisterAdminRoutes agar
+// /audit-log tidak tertangkap route /{user_id}.
+func (s *Server) RegisterAuditRoutes(r *mux.Router) {
+	r.Handle("/audit-log", s.handleListAuditLog()).Methods("GET")
+	r.Handle("/audit-log/export", s.handleExportAuditLog()).Methods("GET")
+	r.Handle("/audit-log/verify", s.handleVerifyAuditLog()).Methods("GET")
+}
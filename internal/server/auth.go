@@ -2,14 +2,16 @@ package server
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/jaga-project/jaga-backend/internal/auth"
-	"github.com/jaga-project/jaga-backend/internal/database"
 	"golang.org/x/crypto/bcrypt"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+	"github.com/jaga-project/jaga-backend/internal/middleware"
 )
 
 type LoginRequest struct {
@@ -27,6 +29,8 @@ type LoginResponse struct {
 	KTPImageID *int64    `json:"ktp_image_id,omitempty"`
 	NIK        string    `json:"nik"`
 	Phone      string    `json:"phone"`
+
+	EmailVerified bool `json:"email_verified"`
 }
 
 func (s *Server) handleLogin() http.HandlerFunc {
@@ -42,9 +46,21 @@ func (s *Server) handleLogin() http.HandlerFunc {
 			return
 		}
 
-		user, err := database.FindSingleUser(s.db.Get(), req.Email, r.Context())
+		// Penguncian dihitung per email (bukan per IP) agar brute force
+		// terdistribusi terhadap satu akun tetap tertahan.
+		lockKey := loginLockoutKey(req.Email)
+		if s.limiter != nil {
+			if wait := s.limiter.Locked(r.Context(), lockKey); wait > 0 {
+				middleware.SetRetryAfter(w, wait)
+				writeJSONError(w, "Too many failed login attempts, please retry later", http.StatusTooManyRequests)
+				return
+			}
+		}
+
+		user, err := s.repos.Users.FindByEmail(r.Context(), req.Email)
 		if err != nil {
-			if err.Error() == "user not found" {
+			if errors.Is(err, database.ErrUserNotFound) {
+				s.loginFailed(r, lockKey)
 				writeJSONError(w, "Invalid email or password", http.StatusUnauthorized)
 			} else {
 				writeJSONError(w, "Error finding user: "+err.Error(), http.StatusInternalServerError)
@@ -54,39 +70,73 @@ func (s *Server) handleLogin() http.HandlerFunc {
 
 		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 		if err != nil {
+			s.loginFailed(r, lockKey)
 			writeJSONError(w, "Invalid email or password", http.StatusUnauthorized)
 			return
 		}
 
-		isAdmin, err := database.IsUserAdmin(s.db.Get(), user.UserID)
+		isAdmin, err := s.repos.Admins.IsAdmin(r.Context(), user.UserID)
 		if err != nil {
-			log.Printf("Failed to check admin status for %s during login: %v", user.UserID, err)
+			logging.FromContext(r.Context()).Error("failed to check admin status during login", "user_id", user.UserID, "error", err)
 			isAdmin = false
 		}
 
-		tokenString, expiresAt, err := auth.GenerateJWT(user.UserID, isAdmin)
-		if err != nil {
-			writeJSONError(w, "Failed to generate token: "+err.Error(), http.StatusInternalServerError)
+		// Hitungan kegagalan baru direset setelah langkah 2FA selesai, agar
+		// password yang bocor tidak bisa dipakai untuk menebak kode TOTP
+		// tanpa batas.
+		if isAdmin && s.startMFAChallenge(w, r, user) {
 			return
 		}
+		if s.limiter != nil {
+			s.limiter.Success(r.Context(), lockKey)
+		}
+
+		s.writeLoginResponse(w, user, isAdmin)
+	}
+}
+
+func (s *Server) newLoginResponse(user *database.User, isAdmin bool) (*LoginResponse, error) {
+	tokenString, expiresAt, err := s.tokens.GenerateJWT(user.UserID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResponse{
+		Token:      tokenString,
+		ExpiresAt:  expiresAt,
+		UserID:     user.UserID,
+		Name:       user.Name,
+		Email:      user.Email,
+		IsAdmin:    isAdmin,
+		KTPImageID: user.KTPImageID,
+		NIK:        user.NIK,
+		Phone:      user.Phone,
+
+		EmailVerified: user.EmailVerifiedAt != nil,
+	}, nil
+}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(LoginResponse{
-			Token:      tokenString,
-			ExpiresAt:  expiresAt,
-			UserID:     user.UserID,
-			Name:       user.Name,
-			Email:      user.Email,
-			IsAdmin:    isAdmin,
-			KTPImageID: user.KTPImageID,
-			NIK:        user.NIK,
-			Phone:      user.Phone,
-		})
+func (s *Server) writeLoginResponse(w http.ResponseWriter, user *database.User, isAdmin bool) {
+	resp, err := s.newLoginResponse(user, isAdmin)
+	if err != nil {
+		writeJSONError(w, "Failed to generate token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) loginFailed(r *http.Request, lockKey string) {
+	if s.limiter == nil {
+		return
+	}
+	if locked := s.limiter.Failure(r.Context(), lockKey, s.cfg.RateLimit.Lockout); locked > 0 {
+		logging.FromContext(r.Context()).Warn("login locked after repeated failures", "key", lockKey, "locked_for", locked)
 	}
 }
 
 func (s *Server) RegisterAuthRoutes(r *mux.Router) {
-	r.HandleFunc("/auth/login", s.handleLogin()).Methods("POST")
+	loginLimit := s.rateLimit("login", s.cfg.RateLimit.Login, middleware.KeyByIP(s.cfg.RateLimit.TrustProxyHeaders))
+	r.Handle("/auth/login", loginLimit(s.handleLogin())).Methods("POST")
 }
 
 
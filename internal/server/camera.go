@@ -45,10 +45,25 @@ func (s *Server) handleCreateCamera() http.HandlerFunc {
 			return
 		}
 
-		if err := database.CreateCamera(r.Context(), s.db.Get(), &cam); err != nil {
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		if err := tx.Repos().Cameras.Create(r.Context(), &cam); err != nil {
 			writeJSONError(w, "Failed to create camera: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
+		if err := s.audit(r, tx.Repos(), auditChange{Action: "camera.create", EntityType: "camera", EntityID: cam.CameraID, After: cam}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusCreated)
 		json.NewEncoder(w).Encode(cam)
@@ -57,7 +72,7 @@ func (s *Server) handleCreateCamera() http.HandlerFunc {
 
 func (s *Server) handleListCameras() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		list, err := database.ListCameras(r.Context(), s.db.Get())
+		list, err := s.repos.Cameras.List(r.Context())
 		if err != nil {
 			writeJSONError(w, "Failed to list cameras: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -75,7 +90,7 @@ func (s *Server) handleGetCameraByID() http.HandlerFunc {
 			writeJSONError(w, "Invalid camera_id: must be an integer", http.StatusBadRequest)
 			return
 		}
-		cam, err := database.GetCameraByID(r.Context(), s.db.Get(), id)
+		cam, err := s.repos.Cameras.GetByID(r.Context(), id)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) || strings.Contains(err.Error(), "not found") {
 				writeJSONError(w, "Camera not found", http.StatusNotFound)
@@ -127,16 +142,41 @@ func (s *Server) handleUpdateCamera() http.HandlerFunc {
 			return
 		}
 
-		if err := database.UpdateCamera(r.Context(), s.db.Get(), id, &cam); err != nil {
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		existingCam, err := tx.Repos().Cameras.GetByID(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) || strings.Contains(err.Error(), "not found") {
+				writeJSONError(w, "Camera not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to get camera: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if err := tx.Repos().Cameras.Update(r.Context(), id, &cam); err != nil {
 			writeJSONError(w, "Failed to update camera: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		updatedCam, err := database.GetCameraByID(r.Context(), s.db.Get(), id)
+		updatedCam, err := tx.Repos().Cameras.GetByID(r.Context(), id)
 		if err != nil {
 			writeJSONError(w, "Failed to retrieve updated camera: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
+		if err := s.audit(r, tx.Repos(), auditChange{Action: "camera.update", EntityType: "camera", EntityID: id, Before: existingCam, After: updatedCam}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(updatedCam)
 	}
@@ -150,14 +190,79 @@ func (s *Server) handleDeleteCamera() http.HandlerFunc {
 			writeJSONError(w, "Invalid camera_id: must be an integer", http.StatusBadRequest)
 			return
 		}
-		if err := database.DeleteCamera(r.Context(), s.db.Get(), id); err != nil {
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		existingCam, err := tx.Repos().Cameras.GetByID(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) || strings.Contains(err.Error(), "not found") {
+				writeJSONError(w, "Camera not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to get camera: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if err := tx.Repos().Cameras.Delete(r.Context(), id); err != nil {
 			writeJSONError(w, "Failed to delete camera: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
+		if err := s.audit(r, tx.Repos(), auditChange{Action: "camera.delete", EntityType: "camera", EntityID: id, Before: existingCam}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
+func (s *Server) handleRestoreCamera() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			writeJSONError(w, "Invalid camera_id: must be an integer", http.StatusBadRequest)
+			return
+		}
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		if err := tx.Repos().Cameras.Restore(r.Context(), id); err != nil {
+			if errors.Is(err, database.ErrCameraNotFound) {
+				writeJSONError(w, "Deleted camera not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to restore camera: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		restoredCam, err := tx.Repos().Cameras.GetByID(r.Context(), id)
+		if err != nil {
+			writeJSONError(w, "Failed to get restored camera: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := s.audit(r, tx.Repos(), auditChange{Action: "camera.restore", EntityType: "camera", EntityID: id, After: restoredCam}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(restoredCam)
+	}
+}
+
 func (s *Server) RegisterPublicCameraRoutes(r *mux.Router) {
     r.HandleFunc("/cameras", s.handleListCameras()).Methods("GET")
     r.HandleFunc("/cameras/{id:[0-9]+}", s.handleGetCameraByID()).Methods("GET")
@@ -168,4 +273,5 @@ func (s *Server) RegisterProtectedCameraRoutes(r *mux.Router) {
     r.Handle("/cameras", adminOnlyMiddleware(s.handleCreateCamera())).Methods("POST")
     r.Handle("/cameras/{id:[0-9]+}", adminOnlyMiddleware(s.handleUpdateCamera())).Methods("PUT")
     r.Handle("/cameras/{id:[0-9]+}", adminOnlyMiddleware(s.handleDeleteCamera())).Methods("DELETE")
+    r.Handle("/cameras/{id:[0-9]+}/restore", adminOnlyMiddleware(s.handleRestoreCamera())).Methods("POST")
 }
\ No newline at end of file
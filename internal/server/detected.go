@@ -7,16 +7,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+	"github.com/jaga-project/jaga-backend/internal/metrics"
 	"github.com/jaga-project/jaga-backend/internal/middleware"
 )
 
@@ -28,50 +27,57 @@ type DetectedResponse struct {
 	Timestamp          time.Time `json:"timestamp"`
 	PersonImageURL     *string   `json:"person_image_url,omitempty"`
 	MotorcycleImageURL *string   `json:"motorcycle_image_url,omitempty"`
+	// Attributes kosong bila detector belum melampirkan prediksi.
+	Attributes *database.DetectedAttributes `json:"attributes,omitempty"`
 }
 
-func (s *Server) toDetectedResponse(ctx context.Context, dbQuerier database.Querier, d *database.Detected) DetectedResponse {
+func (s *Server) toDetectedResponse(ctx context.Context, images database.ImageRepo, d *database.Detected) DetectedResponse {
 	response := DetectedResponse{
 		DetectedID: d.DetectedID,
 		CameraID:   d.CameraID,
 		Timestamp:  d.Timestamp,
 	}
+	if !d.Attributes.Empty() {
+		attrs := d.Attributes
+		response.Attributes = &attrs
+	}
 
 	if d.PersonImageID.Valid {
-		path, err := database.GetImageStoragePath(ctx, dbQuerier, d.PersonImageID.Int64)
+		path, err := images.GetStoragePath(ctx, d.PersonImageID.Int64)
 		if err == nil && path != "" {
 			url := "/" + strings.TrimPrefix(path, "/")
 			response.PersonImageURL = &url
 		} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
-			fmt.Printf("WARN: Failed to get person image path for detected ID %d, image ID %d: %v\n", d.DetectedID, d.PersonImageID.Int64, err)
+			logging.FromContext(ctx).Warn("failed to get person image path", "detected_id", d.DetectedID, "image_id", d.PersonImageID.Int64, "error", err)
 		}
 	}
 
 	if d.MotorcycleImageID.Valid {
-		path, err := database.GetImageStoragePath(ctx, dbQuerier, d.MotorcycleImageID.Int64)
+		path, err := images.GetStoragePath(ctx, d.MotorcycleImageID.Int64)
 		if err == nil && path != "" {
 			url := "/" + strings.TrimPrefix(path, "/")
 			response.MotorcycleImageURL = &url
 		} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
-			fmt.Printf("WARN: Failed to get motorcycle image path for detected ID %d, image ID %d: %v\n", d.DetectedID, d.MotorcycleImageID.Int64, err)
+			logging.FromContext(ctx).Warn("failed to get motorcycle image path", "detected_id", d.DetectedID, "image_id", d.MotorcycleImageID.Int64, "error", err)
 		}
 	}
 	return response
 }
 
-func processImageUpload(r *http.Request, formFieldName string, tx *sql.Tx) (sql.NullInt64, string, error) {
+func (s *Server) processImageUpload(r *http.Request, formFieldName string, tx database.Tx) (imageID sql.NullInt64, storagePath string, err error) {
 	file, handler, err := r.FormFile(formFieldName)
 	if err != nil {
 		if err == http.ErrMissingFile {
-			fmt.Printf("DEBUG processImageUpload (detected): File not provided for field '%s'\n", formFieldName)
+			logging.FromContext(r.Context()).Debug("image not provided", "field", formFieldName)
 			return sql.NullInt64{Valid: false}, "", nil
 		}
-		fmt.Printf("ERROR processImageUpload (detected): Error retrieving file for field '%s': %v\n", formFieldName, err)
+		logging.FromContext(r.Context()).Error("failed to retrieve uploaded file", "field", formFieldName, "error", err)
 		return sql.NullInt64{Valid: false}, "", fmt.Errorf("error retrieving %s: %w", formFieldName, err)
 	}
 	defer file.Close()
+	defer func() { s.observeUpload(metrics.CategoryDetected, handler.Size, err) }()
 
-	fmt.Printf("DEBUG processImageUpload (detected): Processing file '%s' for field '%s', size: %d, header MIME: %s\n", handler.Filename, formFieldName, handler.Size, handler.Header.Get("Content-Type"))
+	logging.FromContext(r.Context()).Debug("processing uploaded image", "field", formFieldName, "filename", handler.Filename, "size", handler.Size, "header_mime", handler.Header.Get("Content-Type"))
 
 	if handler.Size == 0 {
 		return sql.NullInt64{}, "", fmt.Errorf("file for %s is empty", formFieldName)
@@ -86,52 +92,35 @@ func processImageUpload(r *http.Request, formFieldName string, tx *sql.Tx) (sql.
 		return sql.NullInt64{}, "", fmt.Errorf("MIME type validation failed for %s: %w", formFieldName, errMime)
 	}
 
-	originalFilename := handler.Filename
-	uniqueFilename := generateUniqueFilenameLocal(originalFilename)
-	storageDir := imageUploadPath
-	storagePath := filepath.Join(storageDir, uniqueFilename)
-
-	if err := os.MkdirAll(storageDir, os.ModePerm); err != nil {
-		return sql.NullInt64{Valid: false}, "", fmt.Errorf("failed to create upload directory '%s' for %s: %w", storageDir, formFieldName, err)
-	}
-
-	dst, err := os.Create(storagePath)
-	if err != nil {
-		return sql.NullInt64{Valid: false}, storagePath, fmt.Errorf("failed to create destination file '%s' for %s: %w", storagePath, formFieldName, err)
-	}
-	defer dst.Close()
-
 	if _, errSeek := file.Seek(0, io.SeekStart); errSeek != nil {
-		os.Remove(storagePath)
-		return sql.NullInt64{}, storagePath, fmt.Errorf("failed to reset file pointer before copy for %s: %w", formFieldName, errSeek)
+		return sql.NullInt64{}, "", fmt.Errorf("failed to reset file pointer before copy for %s: %w", formFieldName, errSeek)
 	}
 
-	bytesCopied, err := io.Copy(dst, file)
+	originalFilename := handler.Filename
+	storagePath, bytesCopied, err := s.storage.Save(r.Context(), generateUniqueFilenameLocal(originalFilename), file)
 	if err != nil {
-		os.Remove(storagePath)
-		return sql.NullInt64{Valid: false}, storagePath, fmt.Errorf("failed to copy %s file content to '%s': %w", formFieldName, storagePath, err)
+		return sql.NullInt64{Valid: false}, "", fmt.Errorf("failed to store %s: %w", formFieldName, err)
 	}
-	fmt.Printf("DEBUG processImageUpload (detected): Successfully saved %s to %s (%d bytes copied)\n", formFieldName, storagePath, bytesCopied)
+	logging.FromContext(r.Context()).Debug("image saved", "field", formFieldName, "path", storagePath, "bytes", bytesCopied)
 
 	imgRecord := database.Image{
-		StoragePath:      filepath.ToSlash(storagePath),
+		StoragePath:      storagePath,
 		FilenameOriginal: originalFilename,
 		MimeType:         validatedMimeType,
 		SizeBytes:        bytesCopied,
 	}
 
-	if err := database.CreateImageTx(r.Context(), tx, &imgRecord); err != nil {
-		os.Remove(storagePath)
+	if err := tx.Repos().Images.Create(r.Context(), &imgRecord); err != nil {
+		s.storage.Remove(r.Context(), storagePath)
 		return sql.NullInt64{Valid: false}, storagePath, fmt.Errorf("failed to save %s image metadata: %w", formFieldName, err)
 	}
-	fmt.Printf("DEBUG processImageUpload (detected): Successfully created image record for '%s'. ImageID: %d\n", formFieldName, imgRecord.ImageID)
+	logging.FromContext(r.Context()).Debug("image record created", "field", formFieldName, "image_id", imgRecord.ImageID)
 	return sql.NullInt64{Int64: imgRecord.ImageID, Valid: true}, storagePath, nil
 }
 
 func (s *Server) handleCreateDetected() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		fmt.Println("DEBUG: handleCreateDetected POST request received")
-		if err := r.ParseMultipartForm(20 << 20); err != nil {
+		if err := parseMultipartForm(r, 20 << 20); err != nil {
 			writeJSONError(w, "Request too large or invalid multipart form: "+err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -163,9 +152,16 @@ func (s *Server) handleCreateDetected() http.HandlerFunc {
 		}
 		newDetected.Timestamp = parsedTime
 
-		tx, err := s.db.Get().BeginTx(r.Context(), nil)
+		attrs, err := detectedAttributesFromForm(r)
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		newDetected.Attributes = attrs
+
+		tx, err := s.store.BeginTx(r.Context())
 		if err != nil {
-			fmt.Printf("ERROR handleCreateDetected: Failed to start database transaction: %v\n", err)
+			logging.FromContext(r.Context()).Error("failed to begin transaction for detected creation", "error", err)
 			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -176,54 +172,65 @@ func (s *Server) handleCreateDetected() http.HandlerFunc {
 			}
 		}()
 
-		personImageID, personImageStoragePath, err := processImageUpload(r, "person_image", tx)
+		personImageID, personImageStoragePath, err := s.processImageUpload(r, "person_image", tx)
 		if err != nil {
 			tx.Rollback() 
 			if personImageStoragePath != "" {
-				os.Remove(personImageStoragePath) 
+				s.storage.Remove(r.Context(), personImageStoragePath) 
 			}
 			writeJSONError(w, fmt.Sprintf("failed to process person_image: %v", err), http.StatusBadRequest)
 			return
 		}
 		newDetected.PersonImageID = personImageID
 
-		motorcycleImageID, motorcycleImageStoragePath, err := processImageUpload(r, "motorcycle_image", tx)
+		motorcycleImageID, motorcycleImageStoragePath, err := s.processImageUpload(r, "motorcycle_image", tx)
 		if err != nil {
 			tx.Rollback() 
 			if personImageStoragePath != "" {
-				os.Remove(personImageStoragePath) 
+				s.storage.Remove(r.Context(), personImageStoragePath) 
 			}
 			if motorcycleImageStoragePath != "" {
-				os.Remove(motorcycleImageStoragePath)
+				s.storage.Remove(r.Context(), motorcycleImageStoragePath)
 			}
 			writeJSONError(w, fmt.Sprintf("failed to process motorcycle_image: %v", err), http.StatusBadRequest)
 			return
 		}
 		newDetected.MotorcycleImageID = motorcycleImageID
 
-		if err := database.CreateDetectedTx(r.Context(), tx, &newDetected); err != nil {
+		if err := tx.Repos().Detected.Create(r.Context(), &newDetected); err != nil {
 			tx.Rollback()
 			if personImageStoragePath != "" {
-				os.Remove(personImageStoragePath)
+				s.storage.Remove(r.Context(), personImageStoragePath)
 			}
 			if motorcycleImageStoragePath != "" {
-				os.Remove(motorcycleImageStoragePath)
+				s.storage.Remove(r.Context(), motorcycleImageStoragePath)
 			}
 			writeJSONError(w, "Failed to create detected record: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
+		if err := s.audit(r, tx.Repos(), auditChange{Action: "detected.create", EntityType: "detected", EntityID: newDetected.DetectedID, After: newDetected}); err != nil {
+			tx.Rollback()
+			if personImageStoragePath != "" {
+				s.storage.Remove(r.Context(), personImageStoragePath)
+			}
+			if motorcycleImageStoragePath != "" {
+				s.storage.Remove(r.Context(), motorcycleImageStoragePath)
+			}
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+
 		if err := tx.Commit(); err != nil {
-			log.Printf("CRITICAL: Transaction commit failed after files were saved. Manual cleanup may be needed for: %s, %s. Error: %v", personImageStoragePath, motorcycleImageStoragePath, err)
+			logging.FromContext(r.Context()).Error("transaction commit failed after files were saved; manual cleanup may be needed", "person_image_path", personImageStoragePath, "motorcycle_image_path", motorcycleImageStoragePath, "error", err)
 			writeJSONError(w, "Failed to commit database transaction: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		response := s.toDetectedResponse(r.Context(), s.db.Get(), &newDetected)
+		response := s.toDetectedResponse(r.Context(), s.repos.Images, &newDetected)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusCreated)
 		json.NewEncoder(w).Encode(response)
-		fmt.Println("DEBUG: Response sent from handleCreateDetected")
 	}
 }
 
@@ -232,7 +239,6 @@ func (s *Server) handleGetDetected() http.HandlerFunc {
 		w.Header().Set("Content-Type", "application/json")
 		vars := mux.Vars(r)
 		idStr, idExists := vars["id"]
-		db := s.db.Get()
 
 		if idExists && idStr != "" {
 			id, err := strconv.Atoi(idStr)
@@ -240,17 +246,17 @@ func (s *Server) handleGetDetected() http.HandlerFunc {
 				writeJSONError(w, "Invalid detected_id: must be an integer", http.StatusBadRequest)
 				return
 			}
-			d, err := database.GetDetectedByID(r.Context(), db, id)
+			d, err := s.repos.Detected.GetByID(r.Context(), id)
 			if err != nil {
 				if errors.Is(err, sql.ErrNoRows) || err.Error() == "detected not found" {
 					writeJSONError(w, "Detected record not found", http.StatusNotFound)
 				} else {
-					fmt.Printf("ERROR: Failed to get detected by ID %d: %v\n", id, err)
+					logging.FromContext(r.Context()).Error("failed to get detected", "detected_id", id, "error", err)
 					writeJSONError(w, "Failed to retrieve detected record", http.StatusInternalServerError)
 				}
 				return
 			}
-			response := s.toDetectedResponse(r.Context(), db, d)
+			response := s.toDetectedResponse(r.Context(), s.repos.Images, d)
 			json.NewEncoder(w).Encode(response)
 			return
 		}
@@ -301,16 +307,16 @@ func (s *Server) handleGetDetected() http.HandlerFunc {
 				return
 			}
 
-			detectedListDB, err := database.ListDetectedByProximityAndTimestamp(r.Context(), db, lat, lon, radiusKm, startTime, endTime)
+			detectedListDB, err := s.repos.Detected.ListByProximityAndTimestamp(r.Context(), lat, lon, radiusKm, startTime, endTime)
 			if err != nil {
-				fmt.Printf("ERROR: Failed to list detected by proximity and time: %v\n", err)
+				logging.FromContext(r.Context()).Error("failed to list detected by proximity and time", "error", err)
 				writeJSONError(w, "Failed to retrieve detected records with combined filter", http.StatusInternalServerError)
 				return
 			}
 
 			responseList := make([]DetectedResponse, 0, len(detectedListDB))
 			for i := range detectedListDB {
-				responseList = append(responseList, s.toDetectedResponse(r.Context(), db, &detectedListDB[i]))
+				responseList = append(responseList, s.toDetectedResponse(r.Context(), s.repos.Images, &detectedListDB[i]))
 			}
 			json.NewEncoder(w).Encode(responseList)
 			return
@@ -336,15 +342,15 @@ func (s *Server) handleGetDetected() http.HandlerFunc {
 				endTime = endTime.Add(24*time.Hour - time.Nanosecond)
 			}
 
-			detectedListDB, err := database.ListDetectedByTimestampRange(r.Context(), db, startTime, endTime)
+			detectedListDB, err := s.repos.Detected.ListByTimestampRange(r.Context(), startTime, endTime)
 			if err != nil {
-				fmt.Printf("ERROR: Failed to list detected by timestamp range (%s - %s): %v\n", startTimeStr, endTimeStr, err)
+				logging.FromContext(r.Context()).Error("failed to list detected by timestamp range", "start_time", startTimeStr, "end_time", endTimeStr, "error", err)
 				writeJSONError(w, "Failed to retrieve detected records by timestamp", http.StatusInternalServerError)
 				return
 			}
 			responseList := make([]DetectedResponse, 0, len(detectedListDB))
 			for i := range detectedListDB {
-				responseList = append(responseList, s.toDetectedResponse(r.Context(), db, &detectedListDB[i]))
+				responseList = append(responseList, s.toDetectedResponse(r.Context(), s.repos.Images, &detectedListDB[i]))
 			}
 			json.NewEncoder(w).Encode(responseList)
 			return
@@ -374,29 +380,29 @@ func (s *Server) handleGetDetected() http.HandlerFunc {
 				writeJSONError(w, "lon must be between -180 and 180", http.StatusBadRequest)
 				return
 			}
-			detectedListDB, err := database.ListDetectedByCoordinates(r.Context(), db, lat, lon, radiusKm)
+			detectedListDB, err := s.repos.Detected.ListByCoordinates(r.Context(), lat, lon, radiusKm)
 			if err != nil {
-				fmt.Printf("ERROR: Failed to list detected by proximity (lat: %f, lon: %f, radius: %fkm): %v\n", lat, lon, radiusKm, err)
+				logging.FromContext(r.Context()).Error("failed to list detected by proximity", "lat", lat, "lon", lon, "radius_km", radiusKm, "error", err)
 				writeJSONError(w, "Failed to retrieve detected records by proximity", http.StatusInternalServerError)
 				return
 			}
 			responseList := make([]DetectedResponse, 0, len(detectedListDB))
 			for i := range detectedListDB {
-				responseList = append(responseList, s.toDetectedResponse(r.Context(), db, &detectedListDB[i]))
+				responseList = append(responseList, s.toDetectedResponse(r.Context(), s.repos.Images, &detectedListDB[i]))
 			}
 			json.NewEncoder(w).Encode(responseList)
 			return
 		}
 
-		detectedListDB, err := database.ListDetected(r.Context(), db)
+		detectedListDB, err := s.repos.Detected.List(r.Context())
 		if err != nil {
-			fmt.Printf("ERROR: Failed to list all detected records: %v\n", err)
+			logging.FromContext(r.Context()).Error("failed to list detected", "error", err)
 			writeJSONError(w, "Failed to retrieve detected records", http.StatusInternalServerError)
 			return
 		}
 		responseList := make([]DetectedResponse, 0, len(detectedListDB))
 		for i := range detectedListDB {
-			responseList = append(responseList, s.toDetectedResponse(r.Context(), db, &detectedListDB[i]))
+			responseList = append(responseList, s.toDetectedResponse(r.Context(), s.repos.Images, &detectedListDB[i]))
 		}
 		json.NewEncoder(w).Encode(responseList)
 	}
@@ -424,7 +430,7 @@ func (s *Server) handleUpdateDetected() http.HandlerFunc {
 		// Atau, jika ingin upload gambar baru saat update, perlu logika ParseMultipartForm dan processImageUpload.
 		// Untuk kesederhanaan, kita asumsikan ID gambar yang valid (jika ada) sudah ada di tabel images.
 
-		existingDetected, err := database.GetDetectedByID(r.Context(), s.db.Get(), id)
+		existingDetected, err := s.repos.Detected.GetByID(r.Context(), id)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				writeJSONError(w, "Detected record not found for update", http.StatusNotFound)
@@ -433,6 +439,7 @@ func (s *Server) handleUpdateDetected() http.HandlerFunc {
 			}
 			return
 		}
+		originalDetected := *existingDetected
 
 		if dUpdates.CameraID != 0 {
 			existingDetected.CameraID = dUpdates.CameraID
@@ -457,7 +464,14 @@ func (s *Server) handleUpdateDetected() http.HandlerFunc {
 			existingDetected.MotorcycleImageID = dUpdates.MotorcycleImageID
 		}
 
-		if err := database.UpdateDetected(r.Context(), s.db.Get(), id, existingDetected); err != nil {
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		if err := tx.Repos().Detected.Update(r.Context(), id, existingDetected); err != nil {
 			if errors.Is(err, sql.ErrNoRows) || err.Error() == "no detected record updated or record not found" {
 				writeJSONError(w, "Detected record not found or no changes made", http.StatusNotFound)
 			} else {
@@ -466,17 +480,101 @@ func (s *Server) handleUpdateDetected() http.HandlerFunc {
 			return
 		}
 
-		updatedDetected, err := database.GetDetectedByID(r.Context(), s.db.Get(), id)
+		updatedDetected, err := tx.Repos().Detected.GetByID(r.Context(), id)
 		if err != nil {
 			writeJSONError(w, "Update succeeded but failed to retrieve updated record: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
-		response := s.toDetectedResponse(r.Context(), s.db.Get(), updatedDetected)
+
+		if err := s.audit(r, tx.Repos(), auditChange{Action: "detected.update", EntityType: "detected", EntityID: id, Before: originalDetected, After: updatedDetected}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response := s.toDetectedResponse(r.Context(), s.repos.Images, updatedDetected)
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(response)
 	}
 }
 
+// detectedAttributesFromForm membaca atribut prediksi yang dikirim detector
+// bersama deteksi (brand, model, body_type, color, attribute_confidence).
+func detectedAttributesFromForm(r *http.Request) (database.DetectedAttributes, error) {
+	var a database.DetectedAttributes
+	text := func(field string) *string {
+		if v := r.FormValue(field); v != "" {
+			return &v
+		}
+		return nil
+	}
+	a.Brand, a.Model, a.BodyType, a.Color = text("brand"), text("model"), text("body_type"), text("color")
+	if v := r.FormValue("attribute_confidence"); v != "" {
+		c, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return a, errors.New("attribute_confidence must be a number")
+		}
+		a.Confidence = &c
+	}
+	return a, a.Normalize()
+}
+
+// handleSetDetectedAttributes dipakai detector yang memprediksi atribut
+// setelah deteksi dibuat. Body menggantikan seluruh atribut sebelumnya.
+func (s *Server) handleSetDetectedAttributes() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid detected_id: must be an integer", http.StatusBadRequest)
+			return
+		}
+		var attrs database.DetectedAttributes
+		if err := json.NewDecoder(r.Body).Decode(&attrs); err != nil {
+			writeJSONError(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := attrs.Normalize(); err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		repos := tx.Repos()
+
+		existing, err := repos.Detected.GetByID(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, database.ErrDetectedNotFound) {
+				writeJSONError(w, "Detected record not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to retrieve detected record: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if err := repos.Detected.SetAttributes(r.Context(), id, attrs); err != nil {
+			writeJSONError(w, "Failed to update detected attributes: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := s.audit(r, repos, auditChange{Action: "detected.attributes_update", EntityType: "detected", EntityID: id, Before: existing.Attributes, After: attrs}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		existing.Attributes = attrs
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.toDetectedResponse(r.Context(), s.repos.Images, existing))
+	}
+}
+
 func (s *Server) handleDeleteDetected() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		idStr := mux.Vars(r)["id"]
@@ -486,7 +584,7 @@ func (s *Server) handleDeleteDetected() http.HandlerFunc {
 			return
 		}
 
-		detectedData, err := database.GetDetectedByID(r.Context(), s.db.Get(), id)
+		detectedData, err := s.repos.Detected.GetByID(r.Context(), id)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				writeJSONError(w, "Detected record not found", http.StatusNotFound)
@@ -496,14 +594,14 @@ func (s *Server) handleDeleteDetected() http.HandlerFunc {
 			return
 		}
 
-		tx, err := s.db.Get().BeginTx(r.Context(), nil)
+		tx, err := s.store.BeginTx(r.Context())
 		if err != nil {
 			writeJSONError(w, "Failed to start transaction: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 		defer tx.Rollback() 
 
-		if err := database.DeleteDetectedTx(r.Context(), tx, id); err != nil {
+		if err := tx.Repos().Detected.Delete(r.Context(), id); err != nil {
 			writeJSONError(w, "Failed to delete detected record from DB: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -511,7 +609,7 @@ func (s *Server) handleDeleteDetected() http.HandlerFunc {
 		var imagePathsToDelete []string
 
 		if detectedData.PersonImageID.Valid {
-			path, err := database.GetImageStoragePathAndDeleteTx(r.Context(), tx, detectedData.PersonImageID.Int64)
+			path, err := tx.Repos().Images.GetStoragePathAndDelete(r.Context(), detectedData.PersonImageID.Int64)
 			if err != nil {
 				writeJSONError(w, "Failed to process person image deletion: "+err.Error(), http.StatusInternalServerError)
 				return
@@ -522,7 +620,7 @@ func (s *Server) handleDeleteDetected() http.HandlerFunc {
 		}
 
 		if detectedData.MotorcycleImageID.Valid {
-			path, err := database.GetImageStoragePathAndDeleteTx(r.Context(), tx, detectedData.MotorcycleImageID.Int64)
+			path, err := tx.Repos().Images.GetStoragePathAndDelete(r.Context(), detectedData.MotorcycleImageID.Int64)
 			if err != nil {
 				writeJSONError(w, "Failed to process motorcycle image deletion: "+err.Error(), http.StatusInternalServerError)
 				return
@@ -532,18 +630,24 @@ func (s *Server) handleDeleteDetected() http.HandlerFunc {
 			}
 		}
 
+		if err := s.audit(r, tx.Repos(), auditChange{Action: "detected.delete", EntityType: "detected", EntityID: id, Before: detectedData}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+
 		if err := tx.Commit(); err != nil {
 			writeJSONError(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		go func() {
+		reqLog := logging.FromContext(r.Context())
+		s.workers.Go("delete detected images", func(ctx context.Context) {
 			for _, path := range imagePathsToDelete {
-				if err := os.Remove(path); err != nil {
-					log.Printf("WARN: DB records deleted, but failed to delete image file on disk: %s. Error: %v", path, err)
+				if err := s.storage.Remove(ctx, path); err != nil {
+					reqLog.Warn("detected deleted but failed to delete image file", "path", path, "error", err)
 				}
 			}
-		}()
+		})
 
 		w.WriteHeader(http.StatusNoContent)
 	}
@@ -552,9 +656,10 @@ func (s *Server) handleDeleteDetected() http.HandlerFunc {
 func (s *Server) RegisterDetectedRoutes(r *mux.Router) {
 	adminOnlyMiddleware := middleware.AdminOnlyMiddleware()
 
-	r.Handle("/detected", adminOnlyMiddleware(s.handleCreateDetected())).Methods("POST")
+	r.Handle("/detected", adminOnlyMiddleware(s.uploadQuota(s.handleCreateDetected()))).Methods("POST")
 	r.Handle("/detected", adminOnlyMiddleware(s.handleGetDetected())).Methods("GET")
 	r.Handle("/detected/{id:[0-9]+}", adminOnlyMiddleware(s.handleGetDetected())).Methods("GET")
 	r.Handle("/detected/{id:[0-9]+}", adminOnlyMiddleware(s.handleUpdateDetected())).Methods("PUT")
 	r.Handle("/detected/{id:[0-9]+}", adminOnlyMiddleware(s.handleDeleteDetected())).Methods("DELETE")
+	r.Handle("/detected/{id:[0-9]+}/attributes", adminOnlyMiddleware(s.handleSetDetectedAttributes())).Methods("PUT")
 }
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/geo"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+)
+
+// maxDetectionSearchWindow membatasi rentang waktu pencarian agar query
+// atribut tidak memindai seluruh tabel detected.
+const maxDetectionSearchWindow = 31 * 24 * time.Hour
+
+// DetectionSearchResult adalah deteksi hasil pencarian beserta nama
+// kameranya.
+type DetectionSearchResult struct {
+	DetectedResponse
+	CameraName string `json:"camera_name"`
+}
+
+type DetectionSearchResponse struct {
+	Total     int                     `json:"total"`
+	Limit     int                     `json:"limit"`
+	Offset    int                     `json:"offset"`
+	CameraIDs []int64                 `json:"camera_ids,omitempty"`
+	Results   []DetectionSearchResult `json:"results"`
+}
+
+// parseDetectionSearch membaca filter pencarian. camera_id boleh diulang
+// atau dipisahkan koma; radius_km memperluas pencarian ke kamera lain di
+// sekitar kamera tersebut.
+func parseDetectionSearch(q url.Values) (database.DetectionSearchFilter, float64, error) {
+	f := database.DetectionSearchFilter{
+		Brand: strings.TrimSpace(q.Get("brand")),
+		Model: strings.TrimSpace(q.Get("model")),
+		Limit: 20,
+	}
+	var err error
+	if f.From, err = time.Parse(time.RFC3339, q.Get("start_time")); err != nil {
+		return f, 0, fmt.Errorf("start_time is required in RFC3339 format")
+	}
+	if f.To, err = time.Parse(time.RFC3339, q.Get("end_time")); err != nil {
+		return f, 0, fmt.Errorf("end_time is required in RFC3339 format")
+	}
+	if !f.To.After(f.From) {
+		return f, 0, fmt.Errorf("end_time must be after start_time")
+	}
+	if f.To.Sub(f.From) > maxDetectionSearchWindow {
+		return f, 0, fmt.Errorf("time window must not exceed 31 days")
+	}
+	if v := strings.ToLower(strings.TrimSpace(q.Get("body_type"))); v != "" {
+		if !database.ValidBodyType(v) {
+			return f, 0, fmt.Errorf("body_type must be one of %s", strings.Join(database.BodyTypes, ", "))
+		}
+		f.BodyType = v
+	}
+	if v := q.Get("color"); v != "" {
+		c, ok := database.NormalizeColor(v)
+		if !ok {
+			return f, 0, fmt.Errorf("color must be one of %s", strings.Join(database.ColorPalette, ", "))
+		}
+		f.Color = c
+	}
+	for _, raw := range q["camera_id"] {
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			id, err := strconv.ParseInt(part, 10, 64)
+			if err != nil {
+				return f, 0, fmt.Errorf("camera_id must be an integer")
+			}
+			f.CameraIDs = append(f.CameraIDs, id)
+		}
+	}
+	var radiusKm float64
+	if v := q.Get("radius_km"); v != "" {
+		if radiusKm, err = strconv.ParseFloat(v, 64); err != nil || radiusKm <= 0 || radiusKm > 50 {
+			return f, 0, fmt.Errorf("radius_km must be a number between 0 and 50")
+		}
+		if len(f.CameraIDs) == 0 {
+			return f, 0, fmt.Errorf("radius_km requires camera_id")
+		}
+	}
+	if v := q.Get("min_confidence"); v != "" {
+		if f.MinConfidence, err = strconv.ParseFloat(v, 64); err != nil || f.MinConfidence < 0 || f.MinConfidence > 1 {
+			return f, 0, fmt.Errorf("min_confidence must be a number between 0 and 1")
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit < 1 || f.Limit > 100 {
+			return f, 0, fmt.Errorf("limit must be an integer between 1 and 100")
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if f.Offset, err = strconv.Atoi(v); err != nil || f.Offset < 0 {
+			return f, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+	}
+	return f, radiusKm, nil
+}
+
+// handleSearchDetections mencari deteksi berdasarkan atribut prediksi,
+// kamera dan rentang waktu tanpa perlu laporan kehilangan.
+func (s *Server) handleSearchDetections() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		f, radiusKm, err := parseDetectionSearch(r.URL.Query())
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		cameras, err := s.repos.Cameras.List(ctx)
+		if err != nil {
+			writeJSONError(w, "Failed to list cameras: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		byID := make(map[int64]database.Camera, len(cameras))
+		for _, c := range cameras {
+			byID[c.CameraID] = c
+		}
+		for _, id := range f.CameraIDs {
+			if _, ok := byID[id]; !ok {
+				writeJSONError(w, fmt.Sprintf("camera %d not found", id), http.StatusBadRequest)
+				return
+			}
+		}
+		if radiusKm > 0 {
+			f.CameraIDs = camerasNear(cameras, f.CameraIDs, radiusKm)
+		}
+
+		total, err := s.repos.Detected.CountSearch(ctx, f)
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to count detection search", "error", err)
+			writeJSONError(w, "Failed to search detections: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		list, err := s.repos.Detected.Search(ctx, f)
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to search detections", "error", err)
+			writeJSONError(w, "Failed to search detections: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		resp := DetectionSearchResponse{Total: total, Limit: f.Limit, Offset: f.Offset, CameraIDs: f.CameraIDs, Results: []DetectionSearchResult{}}
+		for i := range list {
+			resp.Results = append(resp.Results, DetectionSearchResult{
+				DetectedResponse: s.toDetectedResponse(ctx, s.repos.Images, &list[i]),
+				CameraName:       byID[int64(list[i].CameraID)].Name,
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// camerasNear mengembalikan kamera yang berjarak paling jauh radiusKm dari
+// salah satu kamera pusat, termasuk kamera pusat itu sendiri.
+func camerasNear(cameras []database.Camera, centers []int64, radiusKm float64) []int64 {
+	isCenter := make(map[int64]bool, len(centers))
+	for _, id := range centers {
+		isCenter[id] = true
+	}
+	var ids []int64
+	for _, c := range cameras {
+		for _, center := range cameras {
+			if isCenter[center.CameraID] && geo.HaversineKm(center.Latitude, center.Longitude, c.Latitude, c.Longitude) <= radiusKm {
+				ids = append(ids, c.CameraID)
+				break
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// RegisterAdminDetectionRoutes harus didaftarkan sebelum RegisterAdminRoutes.
+func (s *Server) RegisterAdminDetectionRoutes(r *mux.Router) {
+	r.Handle("/detections/search", s.handleSearchDetections()).Methods("GET")
+}
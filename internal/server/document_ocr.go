@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/fieldcrypt"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+	"github.com/jaga-project/jaga-backend/internal/ocr"
+)
+
+// SetOCR mengganti engine OCR, mis. dengan ocr.Stub di test. nil
+// mematikan OCR.
+func (s *Server) SetOCR(e ocr.Engine) {
+	s.ocr = e
+}
+
+// startOCR membaca foto KTP atau STNK di background setelah upload
+// di-commit. Hasilnya ditampilkan di antrean verifikasi admin; kegagalan
+// OCR tidak memengaruhi upload.
+func (s *Server) startOCR(ctx context.Context, documentType string, imageID int64) {
+	engine := s.ocr
+	if engine == nil {
+		return
+	}
+	reqLog := logging.FromContext(ctx).With("image_id", imageID, "document_type", documentType)
+	s.workers.Go("document ocr", func(ctx context.Context) {
+		result := &database.DocumentOCR{ImageID: imageID, DocumentType: documentType, Status: database.OCRPending, Engine: engine.Name()}
+		if err := s.repos.DocumentOCR.Save(ctx, result); err != nil {
+			reqLog.Warn("failed to save pending OCR result", "error", err)
+			return
+		}
+		if err := s.runOCR(ctx, engine, result); err != nil {
+			reqLog.Warn("document OCR failed", "error", err)
+			result.Status, result.Error = database.OCRFailed, err.Error()
+		}
+		if err := s.repos.DocumentOCR.Save(ctx, result); err != nil {
+			reqLog.Warn("failed to save OCR result", "error", err)
+		}
+	})
+}
+
+// runOCR mengisi result dengan field yang terbaca dari foto.
+func (s *Server) runOCR(ctx context.Context, engine ocr.Engine, result *database.DocumentOCR) error {
+	if s.cfg.OCR.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.OCR.Timeout)
+		defer cancel()
+	}
+	path, err := s.repos.Images.GetStoragePath(ctx, result.ImageID)
+	if err != nil {
+		return err
+	}
+	file, err := s.storage.Open(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	text, err := engine.Recognize(ctx, file)
+	if err != nil {
+		return err
+	}
+
+	var fields ocr.Fields
+	if result.DocumentType == database.DocumentKTP {
+		fields = ocr.ExtractKTP(text.Text)
+	} else {
+		fields = ocr.ExtractSTNK(text.Text)
+		if plate, err := database.FormatPlate(fields.PlateNumber); err == nil {
+			fields.PlateNumber = plate
+		}
+	}
+	result.Status, result.Confidence = database.OCRDone, text.Confidence
+	result.NIK, result.Name, result.PlateNumber = fields.NIK, fields.Name, fields.PlateNumber
+	return nil
+}
+
+// ocrMismatches mengembalikan field yang diketik user tetapi berbeda
+// dengan hasil OCR. Field yang tidak terbaca tidak dianggap berbeda.
+func ocrMismatches(d database.DocumentVerification) []string {
+	if d.OCR == nil || d.OCR.Status != database.OCRDone {
+		return nil
+	}
+	var fields []string
+	switch d.DocumentType {
+	case database.DocumentKTP:
+		if d.OCR.NIK != "" && fieldcrypt.Normalize(d.OCR.NIK) != fieldcrypt.Normalize(d.NIK) {
+			fields = append(fields, "nik")
+		}
+		if d.OCR.Name != "" && normalizeName(d.OCR.Name) != normalizeName(d.UserName) {
+			fields = append(fields, "name")
+		}
+	case database.DocumentSTNK:
+		if d.OCR.PlateNumber != "" && database.NormalizePlate(d.OCR.PlateNumber) != database.NormalizePlate(d.PlateNumber) {
+			fields = append(fields, "plate_number")
+		}
+	}
+	return fields
+}
+
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(name)), " ")
+}
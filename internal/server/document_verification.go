@@ -0,0 +1,290 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/mail"
+	"github.com/jaga-project/jaga-backend/internal/middleware"
+)
+
+// documentVerificationRequest adalah keputusan admin. ImageID wajib diisi
+// dengan image_id dari antrean agar keputusan tidak berlaku untuk foto yang
+// diganti user setelah diperiksa.
+type documentVerificationRequest struct {
+	Status  string `json:"status"`
+	Note    string `json:"note"`
+	ImageID *int64 `json:"image_id"`
+}
+
+// DocumentVerificationResponse adalah satu dokumen di antrean verifikasi
+// beserta URL fotonya. OCRMismatches berisi field yang diketik user tetapi
+// tidak cocok dengan hasil OCR.
+type DocumentVerificationResponse struct {
+	database.DocumentVerification
+	ImageURL      string   `json:"image_url"`
+	OCRMismatches []string `json:"ocr_mismatches,omitempty"`
+}
+
+type DocumentVerificationListResponse struct {
+	Total   int                            `json:"total"`
+	Limit   int                            `json:"limit"`
+	Offset  int                            `json:"offset"`
+	Results []DocumentVerificationResponse `json:"results"`
+}
+
+// parseVerificationFilter membaca filter antrean. Tanpa status, hanya
+// dokumen PENDING yang dikembalikan.
+func parseVerificationFilter(q url.Values) (database.VerificationFilter, error) {
+	f := database.VerificationFilter{Status: database.VerificationPending, Limit: 20}
+	if v := strings.ToUpper(strings.TrimSpace(q.Get("document_type"))); v != "" {
+		valid := false
+		for _, t := range database.DocumentTypes {
+			valid = valid || t == v
+		}
+		if !valid {
+			return f, fmt.Errorf("document_type must be one of %s", strings.Join(database.DocumentTypes, ", "))
+		}
+		f.DocumentType = v
+	}
+	if v := strings.ToUpper(strings.TrimSpace(q.Get("status"))); v != "" {
+		switch v {
+		case database.VerificationPending, database.VerificationVerified, database.VerificationRejected:
+			f.Status = v
+		default:
+			return f, fmt.Errorf("status must be PENDING, VERIFIED or REJECTED")
+		}
+	}
+	var err error
+	if v := q.Get("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit < 1 || f.Limit > 100 {
+			return f, fmt.Errorf("limit must be an integer between 1 and 100")
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if f.Offset, err = strconv.Atoi(v); err != nil || f.Offset < 0 {
+			return f, fmt.Errorf("offset must be a non-negative integer")
+		}
+	}
+	return f, nil
+}
+
+// handleListDocumentVerifications mengembalikan antrean verifikasi KTP,
+// STNK dan KK, pengajuan terlama lebih dulu, lengkap dengan NIK dan plat
+// yang diketik user.
+func (s *Server) handleListDocumentVerifications() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		f, err := parseVerificationFilter(r.URL.Query())
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		total, err := s.repos.Verifications.Count(ctx, f)
+		if err != nil {
+			writeJSONError(w, "Failed to count document verifications: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		list, err := s.repos.Verifications.List(ctx, f)
+		if err != nil {
+			writeJSONError(w, "Failed to list document verifications: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		resp := DocumentVerificationListResponse{Total: total, Limit: f.Limit, Offset: f.Offset, Results: []DocumentVerificationResponse{}}
+		for _, d := range list {
+			resp.Results = append(resp.Results, DocumentVerificationResponse{
+				DocumentVerification: d,
+				ImageURL:             "/" + strings.TrimPrefix(d.ImagePath, "/"),
+				OCRMismatches:        ocrMismatches(d),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+const imageChangedMessage = "Document image has changed since it was reviewed; reload the verification queue"
+
+// verificationOutcome adalah hasil keputusan admin atas satu dokumen.
+type verificationOutcome struct {
+	change   auditChange
+	response interface{}
+	userID   string
+}
+
+// handleVerifyDocument menyimpan keputusan admin atas dokumen
+// documentType. {id} adalah user_id untuk KTP dan vehicle_id untuk STNK
+// dan KK. Pemilik dokumen diberi tahu lewat email.
+func (s *Server) handleVerifyDocument(documentType string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		var req documentVerificationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			writeJSONError(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Note = strings.TrimSpace(req.Note)
+		switch req.Status {
+		case database.VerificationVerified:
+		case database.VerificationRejected:
+			if req.Note == "" {
+				writeJSONError(w, "note is required when rejecting a document", http.StatusBadRequest)
+				return
+			}
+		default:
+			writeJSONError(w, "status must be VERIFIED or REJECTED", http.StatusBadRequest)
+			return
+		}
+		if req.ImageID == nil {
+			writeJSONError(w, "image_id is required", http.StatusBadRequest)
+			return
+		}
+		adminID, _ := ctx.Value(middleware.UserIDContextKey).(string)
+
+		tx, err := s.store.BeginTx(ctx)
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		repos := tx.Repos()
+
+		var outcome *verificationOutcome
+		var code int
+		var msg string
+		now := time.Now()
+		if documentType == database.DocumentKTP {
+			outcome, code, msg = s.verifyKTP(ctx, repos, mux.Vars(r)["id"], req, adminID, now)
+		} else {
+			id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+			if err != nil {
+				writeJSONError(w, "invalid vehicle_id format", http.StatusBadRequest)
+				return
+			}
+			outcome, code, msg = s.verifyVehicleDocument(ctx, repos, documentType, id, req, adminID, now)
+		}
+		if code != 0 {
+			writeJSONError(w, msg, code)
+			return
+		}
+		if err := s.audit(r, repos, outcome.change); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit database transaction", http.StatusInternalServerError)
+			return
+		}
+		s.sendDocumentVerificationEmail(ctx, outcome.userID, documentType, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(outcome.response)
+	}
+}
+
+func (s *Server) verifyKTP(ctx context.Context, repos database.Repositories, userID string, req documentVerificationRequest, adminID string, now time.Time) (*verificationOutcome, int, string) {
+	existing, err := repos.Users.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, database.ErrUserNotFound) {
+			return nil, http.StatusNotFound, "User not found"
+		}
+		return nil, http.StatusInternalServerError, "Failed to retrieve user: " + err.Error()
+	}
+	if existing.KTPImageID == nil {
+		return nil, http.StatusConflict, "User has no KTP image to verify"
+	}
+	if !sameImageID(existing.KTPImageID, req.ImageID) {
+		return nil, http.StatusConflict, imageChangedMessage
+	}
+	if err := repos.Users.SetKTPVerification(ctx, userID, req.Status, adminID, req.Note, now); err != nil {
+		return nil, http.StatusInternalServerError, "Failed to update KTP verification: " + err.Error()
+	}
+	updated, err := repos.Users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, "Failed to retrieve user: " + err.Error()
+	}
+	existing.Password, updated.Password = "", ""
+	return &verificationOutcome{
+		change:   auditChange{Action: "user.verify_ktp", EntityType: "user", EntityID: userID, Before: existing, After: updated},
+		response: updated,
+		userID:   userID,
+	}, 0, ""
+}
+
+func (s *Server) verifyVehicleDocument(ctx context.Context, repos database.Repositories, documentType string, id int64, req documentVerificationRequest, adminID string, now time.Time) (*verificationOutcome, int, string) {
+	existing, err := repos.Vehicles.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrVehicleNotFound) {
+			return nil, http.StatusNotFound, "Vehicle not found"
+		}
+		return nil, http.StatusInternalServerError, "Failed to retrieve vehicle: " + err.Error()
+	}
+	action, image := "vehicle.verify", existing.STNKImageID
+	if documentType == database.DocumentKK {
+		action, image = "vehicle.verify_kk", existing.KKImageID
+	}
+	if !image.Valid {
+		return nil, http.StatusConflict, "Vehicle has no " + documentType + " image to verify"
+	}
+	if !sameImageID(&image.Int64, req.ImageID) {
+		return nil, http.StatusConflict, imageChangedMessage
+	}
+	if documentType == database.DocumentKK {
+		err = repos.Vehicles.SetKKVerification(ctx, id, req.Status, adminID, req.Note, now)
+	} else {
+		err = repos.Vehicles.SetVerification(ctx, id, req.Status, adminID, req.Note, now)
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, "Failed to update vehicle verification: " + err.Error()
+	}
+	updated, err := repos.Vehicles.GetByID(ctx, id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, "Failed to retrieve vehicle: " + err.Error()
+	}
+	return &verificationOutcome{
+		change:   auditChange{Action: action, EntityType: "vehicle", EntityID: id, Before: existing, After: updated},
+		response: s.toVehicleResponse(ctx, repos.Images, updated),
+		userID:   updated.UserID,
+	}, 0, ""
+}
+
+func (s *Server) sendDocumentVerificationEmail(ctx context.Context, userID, documentType string, req documentVerificationRequest) {
+	user, err := s.repos.Users.FindByID(ctx, userID)
+	if err != nil {
+		return
+	}
+	body := fmt.Sprintf("Halo %s,\n\nDokumen %s Anda telah diverifikasi oleh admin.\n", user.Name, documentType)
+	subject := "Dokumen " + documentType + " terverifikasi"
+	if req.Status == database.VerificationRejected {
+		body = fmt.Sprintf("Halo %s,\n\nDokumen %s Anda ditolak oleh admin dengan alasan:\n\n%s\n\nSilakan unggah ulang dokumen yang benar.\n", user.Name, documentType, req.Note)
+		subject = "Dokumen " + documentType + " ditolak"
+	}
+	s.sendMail(ctx, mail.Message{To: user.Email, Subject: subject, Body: body})
+}
+
+// sameImageID bernilai true bila kedua ID gambar sama atau sama-sama kosong.
+func sameImageID(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// RegisterAdminVerificationRoutes harus didaftarkan sebelum
+// RegisterAdminRoutes.
+func (s *Server) RegisterAdminVerificationRoutes(r *mux.Router) {
+	r.Handle("/verifications", s.handleListDocumentVerifications()).Methods("GET")
+	r.Handle("/verifications/ktp/{id}", s.handleVerifyDocument(database.DocumentKTP)).Methods("POST")
+	r.Handle("/verifications/stnk/{id:[0-9]+}", s.handleVerifyDocument(database.DocumentSTNK)).Methods("POST")
+	r.Handle("/verifications/kk/{id:[0-9]+}", s.handleVerifyDocument(database.DocumentKK)).Methods("POST")
+}
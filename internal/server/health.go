@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jaga-project/jaga-backend/internal/storage"
+)
+
+const readinessTimeout = 2 * time.Second
+
+type ReadinessResponse struct {
+	Status string                       `json:"status"`
+	Checks map[string]map[string]string `json:"checks"`
+}
+
+// handleHealthz adalah liveness probe: selama proses bisa melayani HTTP,
+// jawabannya selalu 200.
+func (s *Server) handleHealthz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}
+}
+
+// handleReadyz adalah readiness probe. Mengembalikan 503 bila salah satu
+// dependensi tidak siap atau server sedang shutdown.
+func (s *Server) handleReadyz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		resp := ReadinessResponse{Status: "ready", Checks: make(map[string]map[string]string)}
+		ready := true
+
+		if s.db != nil {
+			dbHealth := s.db.Health(ctx)
+			resp.Checks["database"] = dbHealth
+			if dbHealth["status"] != "up" {
+				ready = false
+			}
+		}
+
+		resp.Checks["storage"] = checkResult(s.storage.Ping(ctx))
+		if resp.Checks["storage"]["status"] != "up" {
+			ready = false
+		}
+
+		resp.Checks["upload_dir"] = checkResult(storage.CheckWritableDir(imageDir(s.cfg)))
+		resp.Checks["upload_dir"]["path"] = imageDir(s.cfg)
+		if resp.Checks["upload_dir"]["status"] != "up" {
+			ready = false
+		}
+
+		if s.shuttingDown.Load() {
+			resp.Checks["server"] = map[string]string{"status": "down", "error": "shutting down"}
+			ready = false
+		}
+
+		statusCode := http.StatusOK
+		if !ready {
+			resp.Status = "not_ready"
+			statusCode = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func checkResult(err error) map[string]string {
+	if err != nil {
+		return map[string]string{"status": "down", "error": err.Error()}
+	}
+	return map[string]string{"status": "up"}
+}
+
+func (s *Server) RegisterHealthRoutes(r *mux.Router) {
+	r.HandleFunc("/healthz", s.handleHealthz()).Methods("GET")
+	r.HandleFunc("/readyz", s.handleReadyz()).Methods("GET")
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	_ "time/tzdata" // Image container belum tentu punya zoneinfo.
+
+	"github.com/jaga-project/jaga-backend/internal/geo"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+)
+
+const (
+	defaultHotspotRange       = 90 * 24 * time.Hour
+	defaultHotspotRadiusKm    = 0.5
+	defaultHotspotMinPoints   = 3
+	defaultHotspotWindowHours = 3
+	defaultHotspotCoverageKm  = 1.0
+	defaultHotspotTimezone    = "Asia/Jakarta"
+)
+
+type hotspotQuery struct {
+	From, To     time.Time
+	Options      geo.ClusterOptions
+	CoverageKm   float64
+	UnderCovered bool
+}
+
+// parseHotspotQuery membaca from/to, method (dbscan atau grid), radius_km,
+// window_hours, min_points, coverage_km, tz dan under_covered.
+func parseHotspotQuery(q url.Values, now time.Time) (hotspotQuery, error) {
+	hq := hotspotQuery{
+		Options: geo.ClusterOptions{
+			Method:    geo.MethodDBSCAN,
+			RadiusKm:  defaultHotspotRadiusKm,
+			MinPoints: defaultHotspotMinPoints,
+		},
+		CoverageKm: defaultHotspotCoverageKm,
+	}
+	var err error
+	if hq.From, hq.To, err = parseStatsRange(q, now, defaultHotspotRange); err != nil {
+		return hq, err
+	}
+	if v := q.Get("method"); v != "" {
+		if v != geo.MethodDBSCAN && v != geo.MethodGrid {
+			return hq, fmt.Errorf("method must be dbscan or grid")
+		}
+		hq.Options.Method = v
+	}
+	floatParam := func(name string, min, max float64, dst *float64) error {
+		v := q.Get(name)
+		if v == "" {
+			return nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < min || f > max {
+			return fmt.Errorf("%s must be a number between %g and %g", name, min, max)
+		}
+		*dst = f
+		return nil
+	}
+	if err := floatParam("radius_km", 0.05, 10, &hq.Options.RadiusKm); err != nil {
+		return hq, err
+	}
+	if err := floatParam("coverage_km", 0.1, 50, &hq.CoverageKm); err != nil {
+		return hq, err
+	}
+	windowHours := float64(defaultHotspotWindowHours)
+	if err := floatParam("window_hours", 0, 12, &windowHours); err != nil {
+		return hq, err
+	}
+	hq.Options.TimeWindow = time.Duration(windowHours * float64(time.Hour))
+	if v := q.Get("min_points"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 1000 {
+			return hq, fmt.Errorf("min_points must be between 1 and 1000")
+		}
+		hq.Options.MinPoints = n
+	}
+	tz := defaultHotspotTimezone
+	if v := q.Get("tz"); v != "" {
+		tz = v
+	}
+	if hq.Options.Location, err = time.LoadLocation(tz); err != nil {
+		return hq, fmt.Errorf("invalid tz %q", tz)
+	}
+	if v := q.Get("under_covered"); v != "" {
+		if hq.UnderCovered, err = strconv.ParseBool(v); err != nil {
+			return hq, fmt.Errorf("under_covered must be true or false")
+		}
+	}
+	return hq, nil
+}
+
+// handleGetHotspots meng-cluster lokasi dan waktu laporan kehilangan menjadi
+// hotspot GeoJSON. Hotspot tanpa kamera aktif dalam coverage_km ditandai
+// under_covered sebagai usulan lokasi kamera baru.
+func (s *Server) handleGetHotspots() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hq, err := parseHotspotQuery(r.URL.Query(), time.Now())
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log := logging.FromContext(r.Context())
+
+		locations, err := s.repos.LostReports.ListLocations(r.Context(), hq.From, hq.To)
+		if err != nil {
+			log.Error("failed to list lost report locations", "error", err)
+			writeJSONError(w, "Failed to retrieve lost reports: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		cameras, err := s.repos.Cameras.List(r.Context())
+		if err != nil {
+			log.Error("failed to list cameras", "error", err)
+			writeJSONError(w, "Failed to retrieve cameras: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		incidents := make([]geo.Incident, 0, len(locations))
+		for _, l := range locations {
+			incidents = append(incidents, geo.Incident{ID: l.LostID, Latitude: l.Latitude, Longitude: l.Longitude, Time: l.Timestamp})
+		}
+		var positions []geo.Position
+		for _, c := range cameras {
+			if c.IsActive {
+				positions = append(positions, geo.Position{Latitude: c.Latitude, Longitude: c.Longitude})
+			}
+		}
+		hotspots := geo.Cluster(incidents, hq.Options)
+		geo.MarkCoverage(hotspots, positions, hq.CoverageKm)
+
+		var features []geo.Feature
+		for _, h := range hotspots {
+			if !hq.UnderCovered || h.UnderCovered {
+				features = append(features, h.Feature())
+			}
+		}
+		w.Header().Set("Content-Type", "application/geo+json")
+		json.NewEncoder(w).Encode(geo.NewFeatureCollection(features))
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"os"
@@ -12,13 +12,13 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+    "github.com/jaga-project/jaga-backend/internal/metrics"
     "github.com/jaga-project/jaga-backend/internal/middleware"
 )
 
-const imageUploadPath = "./uploads/images" 
 const maxUploadSize = 5 * 1024 * 1024 
 
 var DefaultAllowedMimeTypes = map[string]bool{
@@ -34,11 +34,11 @@ func ValidateMimeType(file multipart.File, handler *multipart.FileHeader, allowe
     }
 
     headerMimeType := handler.Header.Get("Content-Type")
-    fmt.Printf("DEBUG ValidateMimeType: Header MIME for '%s': %s\n", handler.Filename, headerMimeType)
+    slog.Debug("mime validation: header type", "filename", handler.Filename, "mime", headerMimeType)
 
     for allowed := range allowedMimeTypes {
         if strings.HasPrefix(headerMimeType, allowed) {
-            fmt.Printf("DEBUG ValidateMimeType: '%s' validated by header as %s (prefix match with %s)\n", handler.Filename, headerMimeType, allowed)
+            slog.Debug("mime validation: accepted by header", "filename", handler.Filename, "mime", headerMimeType, "allowed", allowed)
             // Tidak perlu reset pointer jika validasi dari header sudah cukup
             // Namun, untuk konsistensi dan jika deteksi konten selalu diinginkan, reset bisa dilakukan di sini juga.
             // Untuk saat ini, kita anggap validasi header sudah cukup jika berhasil.
@@ -73,11 +73,11 @@ func ValidateMimeType(file multipart.File, handler *multipart.FileHeader, allowe
     // Jika tidak, kembalikan ke posisi semula.
 
     detectedMimeType := http.DetectContentType(buffer[:n])
-    fmt.Printf("DEBUG ValidateMimeType: Content-detected MIME for '%s': %s\n", handler.Filename, detectedMimeType)
+    slog.Debug("mime validation: detected type", "filename", handler.Filename, "mime", detectedMimeType)
 
     for allowed := range allowedMimeTypes {
         if strings.HasPrefix(detectedMimeType, allowed) {
-            fmt.Printf("DEBUG ValidateMimeType: '%s' validated by content as %s (prefix match with %s)\n", handler.Filename, detectedMimeType, allowed)
+            slog.Debug("mime validation: accepted by content", "filename", handler.Filename, "mime", detectedMimeType, "allowed", allowed)
             // Reset pointer ke awal karena file akan segera diproses (misalnya, io.Copy)
             if _, errSeekReset := file.Seek(0, io.SeekStart); errSeekReset != nil {
                 return "", fmt.Errorf("failed to reset file pointer for '%s' after successful content MIME detection: %w", handler.Filename, errSeekReset)
@@ -87,7 +87,7 @@ func ValidateMimeType(file multipart.File, handler *multipart.FileHeader, allowe
     }
 
     if _, errSeekRestore := file.Seek(currentPos, io.SeekStart); errSeekRestore != nil {
-        fmt.Printf("WARNING ValidateMimeType: Failed to restore file pointer for '%s' after failed MIME detection: %v\n", handler.Filename, errSeekRestore)
+        slog.Warn("mime validation: failed to restore file pointer", "filename", handler.Filename, "error", errSeekRestore)
     }
 
     allowedKeys := make([]string, 0, len(allowedMimeTypes))
@@ -107,14 +107,8 @@ func ensureUploadDir(dir string) error {
 
 func (s *Server) handleImageUpload() http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
-        if err := ensureUploadDir(imageUploadPath); err != nil {
-            log.Printf("Error ensuring upload directory: %v", err)
-            writeJSONError(w, "Internal server error: could not prepare upload directory", http.StatusInternalServerError)
-            return
-        }
-
         r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
-        if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+        if err := parseMultipartForm(r, maxUploadSize); err != nil {
             if err.Error() == "http: request body too large" {
                 writeJSONError(w, fmt.Sprintf("File too large. Maximum upload size is %dMB", maxUploadSize/(1024*1024)), http.StatusRequestEntityTooLarge)
             } else {
@@ -132,48 +126,37 @@ func (s *Server) handleImageUpload() http.HandlerFunc {
 
         mimeType, errMime := ValidateMimeType(file, handler, DefaultAllowedMimeTypes)
         if errMime != nil {
+            s.metrics.UploadFailed(metrics.CategoryOther)
             writeJSONError(w, fmt.Sprintf("MIME type validation failed: %v", errMime), http.StatusBadRequest)
             return
         }
         
         if _, errSeek := file.Seek(0, io.SeekStart); errSeek != nil {
-            log.Printf("Error seeking to start of file after MIME validation: %v", errSeek)
+            logging.FromContext(r.Context()).Error("failed to seek uploaded file after MIME validation", "error", errSeek)
             writeJSONError(w, "Internal server error: could not process file", http.StatusInternalServerError)
             return
         }
 
         originalFilename := handler.Filename
-        fileExtension := filepath.Ext(originalFilename)
-        uniqueFilename := fmt.Sprintf("%s%s", uuid.New().String(), fileExtension)
-        storagePath := filepath.Join(imageUploadPath, uniqueFilename)
-
-        dst, err := os.Create(storagePath)
+        storagePath, fileSize, err := s.storage.Save(r.Context(), generateUniqueFilenameLocal(originalFilename), file)
+        s.observeUpload(metrics.CategoryOther, fileSize, err)
         if err != nil {
-            log.Printf("Error creating destination file %s: %v", storagePath, err)
+            logging.FromContext(r.Context()).Error("failed to save uploaded file", "error", err)
             writeJSONError(w, "Internal server error: could not save file", http.StatusInternalServerError)
             return
         }
-        defer dst.Close()
-
-        fileSize, err := io.Copy(dst, file) // file sudah di-seek ke awal
-        if err != nil {
-            log.Printf("Error copying uploaded file to %s: %v", storagePath, err)
-            os.Remove(storagePath) // Hapus file parsial jika copy gagal
-            writeJSONError(w, "Internal server error: could not copy file content", http.StatusInternalServerError)
-            return
-        }
 
         dbImg := &database.Image{
-            StoragePath:      filepath.ToSlash(storagePath), 
+            StoragePath:      storagePath,
             FilenameOriginal: originalFilename,
             MimeType:         mimeType, 
             SizeBytes:        fileSize,
         }
 
-        tx, err := s.db.Get().BeginTx(r.Context(), nil)
+        tx, err := s.store.BeginTx(r.Context())
         if err != nil {
-            log.Printf("Error starting transaction for image upload: %v", err)
-            os.Remove(storagePath)
+            logging.FromContext(r.Context()).Error("failed to begin transaction for image upload", "error", err)
+            s.storage.Remove(r.Context(), storagePath)
             writeJSONError(w, "Internal server error: could not process image upload", http.StatusInternalServerError)
             return
         }
@@ -181,28 +164,24 @@ func (s *Server) handleImageUpload() http.HandlerFunc {
         defer func() {
             if p := recover(); p != nil {
                 tx.Rollback()
-                if _, statErr := os.Stat(storagePath); statErr == nil {
-                    os.Remove(storagePath)
-                }
+                s.storage.Remove(r.Context(), storagePath)
                 panic(p)
             } else if txErr != nil {
                 tx.Rollback()
-                if _, statErr := os.Stat(storagePath); statErr == nil {
-                    os.Remove(storagePath)
-                }
+                s.storage.Remove(r.Context(), storagePath)
             }
         }()
 
-        txErr = database.CreateImageTx(r.Context(), tx, dbImg)
+        txErr = tx.Repos().Images.Create(r.Context(), dbImg)
         if txErr != nil {
-            log.Printf("Error creating image record in DB for %s: %v", storagePath, txErr)
+            logging.FromContext(r.Context()).Error("failed to create image record", "path", storagePath, "error", txErr)
             writeJSONError(w, "Internal server error: could not save image metadata", http.StatusInternalServerError)
             return
         }
 
         txErr = tx.Commit()
         if txErr != nil {
-            log.Printf("Error committing transaction for image upload: %v", txErr)
+            logging.FromContext(r.Context()).Error("failed to commit image upload", "error", txErr)
             writeJSONError(w, "Internal server error: could not finalize image upload", http.StatusInternalServerError)
             return
         }
@@ -228,12 +207,12 @@ func (s *Server) handleGetImage() http.HandlerFunc {
             return
         }
 
-        imgData, err := database.GetImageByID(r.Context(), s.db.Get(), imageID)
+        imgData, err := s.repos.Images.GetByID(r.Context(), imageID)
         if err != nil {
             if err.Error() == "image not found" {
                 writeJSONError(w, "Image not found", http.StatusNotFound)
             } else {
-                log.Printf("Error getting image %d from DB: %v", imageID, err)
+                logging.FromContext(r.Context()).Error("failed to get image", "image_id", imageID, "error", err)
                 writeJSONError(w, "Internal server error retrieving image metadata", http.StatusInternalServerError)
             }
             return
@@ -242,7 +221,7 @@ func (s *Server) handleGetImage() http.HandlerFunc {
         cleanStoragePath := filepath.Clean(imgData.StoragePath)
 
         if _, err := os.Stat(cleanStoragePath); os.IsNotExist(err) {
-            log.Printf("Image file not found on disk: %s (DB ID: %d)", cleanStoragePath, imageID)
+            logging.FromContext(r.Context()).Warn("image file not found on disk", "path", cleanStoragePath, "image_id", imageID)
             writeJSONError(w, "Image file not found on disk", http.StatusNotFound)
             return
         }
@@ -275,7 +254,7 @@ func (s *Server) handleDeleteImage() http.HandlerFunc {
             return
         }
 
-        imgData, err := database.GetImageByID(r.Context(), s.db.Get(), imageID)
+        imgData, err := s.repos.Images.GetByID(r.Context(), imageID)
         if err != nil {
             if err.Error() == "image not found" {
                 writeJSONError(w, "Image not found in database", http.StatusNotFound)
@@ -285,7 +264,7 @@ func (s *Server) handleDeleteImage() http.HandlerFunc {
             return
         }
 
-        isAdmin, _ := r.Context().Value("isAdmin").(bool)
+        isAdmin, _ := r.Context().Value(middleware.AdminStatusContextKey).(bool)
 
         // Otorisasi: Hanya admin yang boleh menghapus gambar.
         if !isAdmin {
@@ -294,32 +273,38 @@ func (s *Server) handleDeleteImage() http.HandlerFunc {
         }
 
        // Mulai transaksi
-        tx, err := s.db.Get().BeginTx(r.Context(), nil)
+        tx, err := s.store.BeginTx(r.Context())
         if err != nil {
-            log.Printf("Error starting transaction for image deletion: %v", err)
+            logging.FromContext(r.Context()).Error("failed to begin transaction for image deletion", "error", err)
             writeJSONError(w, "Internal server error: could not process image deletion", http.StatusInternalServerError)
             return
         }
         
         // Hapus record dari database TERLEBIH DAHULU di dalam transaksi
-        if err := database.DeleteImageTx(r.Context(), tx, imageID); err != nil {
+        if err := tx.Repos().Images.Delete(r.Context(), imageID); err != nil {
             tx.Rollback() // Batalkan transaksi jika penghapusan DB gagal
-            log.Printf("Error deleting image record from DB for ID %d: %v", imageID, err)
+            logging.FromContext(r.Context()).Error("failed to delete image record", "image_id", imageID, "error", err)
             writeJSONError(w, "Failed to delete image record from database", http.StatusInternalServerError)
             return
         }
 
+        if err := s.audit(r, tx.Repos(), auditChange{Action: "image.delete", EntityType: "image", EntityID: imageID, Before: imgData}); err != nil {
+            tx.Rollback()
+            writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+            return
+        }
+
         // Commit transaksi jika penghapusan DB berhasil
         if err := tx.Commit(); err != nil {
-            log.Printf("Error committing transaction for image deletion: %v", err)
+            logging.FromContext(r.Context()).Error("failed to commit image deletion", "error", err)
             writeJSONError(w, "Internal server error: could not finalize image deletion", http.StatusInternalServerError)
             return
         }
 
         // HANYA SETELAH DATABASE BERHASIL DIUBAH, hapus file dari disk.
-        if err := os.Remove(imgData.StoragePath); err != nil {
+        if err := s.storage.Remove(r.Context(), imgData.StoragePath); err != nil {
             // Pada titik ini, DB sudah konsisten. Kita hanya perlu mencatat bahwa file gagal dihapus.
-            log.Printf("WARNING: DB record for image %d deleted, but failed to delete file on disk: %s. Error: %v", imageID, imgData.StoragePath, err)
+            logging.FromContext(r.Context()).Warn("image record deleted but failed to delete file", "image_id", imageID, "path", imgData.StoragePath, "error", err)
         }
 
         w.WriteHeader(http.StatusNoContent)
@@ -327,13 +312,13 @@ func (s *Server) handleDeleteImage() http.HandlerFunc {
 }
 
 func (s *Server) RegisterImageRoutes(r *mux.Router) {
-    if err := ensureUploadDir(imageUploadPath); err != nil {
-        log.Printf("WARNING: Could not create initial image upload directory %s: %v. Will attempt per-request.", imageUploadPath, err)
+    if err := ensureUploadDir(imageDir(s.cfg)); err != nil {
+        slog.Warn("could not create image upload directory; will retry per request", "dir", imageDir(s.cfg), "error", err)
         
     }
     adminOnlyMiddleware := middleware.AdminOnlyMiddleware()
 
-    r.Handle("/images", adminOnlyMiddleware(s.handleImageUpload())).Methods("POST")
+    r.Handle("/images", adminOnlyMiddleware(s.uploadQuota(s.handleImageUpload()))).Methods("POST")
     r.Handle("/images/{id:[0-9]+}", s.handleGetImage()).Methods("GET")
     r.Handle("/images/{id:[0-9]+}", adminOnlyMiddleware(s.handleDeleteImage())).Methods("DELETE")
 }
\ No newline at end of file
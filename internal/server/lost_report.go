@@ -9,15 +9,14 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+	"github.com/jaga-project/jaga-backend/internal/metrics"
 	"github.com/jaga-project/jaga-backend/internal/middleware"
 )
 
@@ -41,7 +40,7 @@ type LostReportResponse struct {
     Vehicle                *VehicleInfo `json:"vehicle,omitempty"` 
 }
 
-func (s *Server) toLostReportResponse(ctx context.Context, dbQuerier database.Querier, lr *database.LostReportWithVehicleInfo) LostReportResponse {
+func (s *Server) toLostReportResponse(ctx context.Context, images database.ImageRepo, lr *database.LostReportWithVehicleInfo) LostReportResponse {
     response := LostReportResponse{
         LostID:    lr.LostID,
         UserID:    lr.UserID,
@@ -54,22 +53,22 @@ func (s *Server) toLostReportResponse(ctx context.Context, dbQuerier database.Qu
     }
 
     if lr.MotorEvidenceImageID != nil && *lr.MotorEvidenceImageID > 0 {
-        path, err := database.GetImageStoragePath(ctx, dbQuerier, *lr.MotorEvidenceImageID)
+        path, err := images.GetStoragePath(ctx, *lr.MotorEvidenceImageID)
         if err == nil && path != "" {
             url := "/" + strings.TrimPrefix(path, "/")
             response.MotorEvidenceImageURL = &url
         } else if err != nil && !errors.Is(err, sql.ErrNoRows) {
-            fmt.Printf("WARN: Failed to get motor evidence image path for ID %d: %v\n", *lr.MotorEvidenceImageID, err)
+            logging.FromContext(ctx).Warn("failed to get motor evidence image path", "image_id", *lr.MotorEvidenceImageID, "error", err)
         }
     }
 
     if lr.PersonEvidenceImageID != nil && *lr.PersonEvidenceImageID > 0 {
-        path, err := database.GetImageStoragePath(ctx, dbQuerier, *lr.PersonEvidenceImageID)
+        path, err := images.GetStoragePath(ctx, *lr.PersonEvidenceImageID)
         if err == nil && path != "" {
             url := "/" + strings.TrimPrefix(path, "/")
             response.PersonEvidenceImageURL = &url
         } else if err != nil && !errors.Is(err, sql.ErrNoRows) {
-            fmt.Printf("WARN: Failed to get person evidence image path for ID %d: %v\n", *lr.PersonEvidenceImageID, err)
+            logging.FromContext(ctx).Warn("failed to get person evidence image path", "image_id", *lr.PersonEvidenceImageID, "error", err)
         }
     }
 
@@ -89,7 +88,7 @@ func (s *Server) handleCreateLostReport() http.HandlerFunc {
         const extraFormDataSize = 1 * 1024 * 1024
         maxTotalSize := extraFormDataSize + 2*maxEvidenceFileSize
 
-        if err := r.ParseMultipartForm(int64(maxTotalSize)); err != nil {
+        if err := parseMultipartForm(r, int64(maxTotalSize)); err != nil {
             writeJSONError(w, "Request too large or invalid multipart form: "+err.Error(), http.StatusBadRequest)
             return
         }
@@ -102,6 +101,15 @@ func (s *Server) handleCreateLostReport() http.HandlerFunc {
             writeJSONError(w, "Unauthorized: User ID not found in token", http.StatusUnauthorized)
             return
         }
+        reporter, err := s.repos.Users.FindByID(r.Context(), requestingUserID)
+        if err != nil {
+            writeJSONError(w, "Failed to retrieve user: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+        if reporter.EmailVerifiedAt == nil {
+            writeJSONError(w, "Email address must be verified before filing a lost report", http.StatusForbidden)
+            return
+        }
         lr.UserID = requestingUserID
 
         timestampStr := r.FormValue("timestamp")
@@ -139,6 +147,10 @@ func (s *Server) handleCreateLostReport() http.HandlerFunc {
             writeJSONError(w, "Invalid vehicle_id: must be an integer", http.StatusBadRequest)
             return
         }
+        if code, msg := s.checkReportableVehicle(r.Context(), lr.VehicleID, requestingUserID); code != 0 {
+            writeJSONError(w, msg, code)
+            return
+        }
 
         lr.Address = r.FormValue("address")
         if lr.Address == "" {
@@ -179,7 +191,7 @@ func (s *Server) handleCreateLostReport() http.HandlerFunc {
             lr.Status = database.StatusLostReportBelumDiproses
         }
 
-        tx, err := s.db.Get().BeginTx(r.Context(), nil)
+        tx, err := s.store.BeginTx(r.Context())
         if err != nil {
             writeJSONError(w, "Failed to start database transaction", http.StatusInternalServerError)
             return
@@ -192,19 +204,19 @@ func (s *Server) handleCreateLostReport() http.HandlerFunc {
             if p := recover(); p != nil {
                 _ = tx.Rollback()
                 if motorEvidenceImageStoragePath != "" {
-                    _ = os.Remove(motorEvidenceImageStoragePath)
+                    s.storage.Remove(r.Context(), motorEvidenceImageStoragePath)
                 }
                 if personEvidenceImageStoragePath != "" {
-                    _ = os.Remove(personEvidenceImageStoragePath)
+                    s.storage.Remove(r.Context(), personEvidenceImageStoragePath)
                 }
                 panic(p)
             } else if txErr != nil {
                 _ = tx.Rollback()
                 if motorEvidenceImageStoragePath != "" {
-                    _ = os.Remove(motorEvidenceImageStoragePath)
+                    s.storage.Remove(r.Context(), motorEvidenceImageStoragePath)
                 }
                 if personEvidenceImageStoragePath != "" {
-                    _ = os.Remove(personEvidenceImageStoragePath)
+                    s.storage.Remove(r.Context(), personEvidenceImageStoragePath)
                 }
             }
         }()
@@ -257,21 +269,36 @@ func (s *Server) handleCreateLostReport() http.HandlerFunc {
             return
         }
 
-        txErr = database.CreateLostReportTx(r.Context(), tx, &lr)
+        txErr = tx.Repos().LostReports.Create(r.Context(), &lr)
         if txErr != nil {
             writeJSONError(w, "Failed to create lost report record: "+txErr.Error(), http.StatusInternalServerError)
             return
         }
 
+        search, txErr := s.effectiveSearch(r.Context(), tx.Repos(), lr.LostID)
+        if txErr == nil {
+            _, txErr = s.queueAnalysisRun(r.Context(), tx.Repos(), lr.LostID, search.SearchParams, requestingUserID)
+        }
+        if txErr != nil {
+            writeJSONError(w, "Failed to queue analysis for lost report: "+txErr.Error(), http.StatusInternalServerError)
+            return
+        }
+
+        txErr = s.audit(r, tx.Repos(), auditChange{Action: "lost_report.create", EntityType: "lost_report", EntityID: lr.LostID, After: lr})
+        if txErr != nil {
+            writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+            return
+        }
+
         txErr = tx.Commit()
         if txErr != nil {
             writeJSONError(w, "Failed to commit database transaction", http.StatusInternalServerError)
             return
         }
 
-        createdLRFromDB, errGet := database.GetLostReportWithVehicleInfoByID(r.Context(), s.db.Get(), lr.LostID)
+        createdLRFromDB, errGet := s.repos.LostReports.GetWithVehicleInfoByID(r.Context(), lr.LostID)
         if errGet != nil {
-            fmt.Printf("WARN: handleCreateLostReport - Failed to retrieve created report for full response: %v\n", errGet)
+            logging.FromContext(r.Context()).Warn("lost report created but failed to load it for response", "error", errGet)
             fallbackResponse := LostReportResponse{
                 LostID:    lr.LostID,
                 UserID:    lr.UserID,
@@ -285,14 +312,16 @@ func (s *Server) handleCreateLostReport() http.HandlerFunc {
             json.NewEncoder(w).Encode(fallbackResponse)
             return
         }
-        response := s.toLostReportResponse(r.Context(), s.db.Get(), createdLRFromDB)
+        response := s.toLostReportResponse(r.Context(), s.repos.Images, createdLRFromDB)
         w.Header().Set("Content-Type", "application/json")
         w.WriteHeader(http.StatusCreated)
         json.NewEncoder(w).Encode(response)
     }
 }
 
-func (s *Server) uploadAndCreateImageRecordLr(ctx context.Context, tx *sql.Tx, file multipart.File, handler *multipart.FileHeader, formFieldName string) (sql.NullInt64, string, error) {
+func (s *Server) uploadAndCreateImageRecordLr(ctx context.Context, tx database.Tx, file multipart.File, handler *multipart.FileHeader, formFieldName string) (imageID sql.NullInt64, storagePath string, err error) {
+    defer func() { s.observeUpload(metrics.CategoryEvidence, handler.Size, err) }()
+
     if handler.Size == 0 {
         return sql.NullInt64{}, "", fmt.Errorf("file for %s is empty", formFieldName)
     }
@@ -307,43 +336,25 @@ func (s *Server) uploadAndCreateImageRecordLr(ctx context.Context, tx *sql.Tx, f
         return sql.NullInt64{}, "", fmt.Errorf("MIME type validation failed for %s: %w", formFieldName, errMime)
     }
 
-    originalFilename := handler.Filename
-    fileExtension := filepath.Ext(originalFilename)
-    uniqueFilename := fmt.Sprintf("%s%s", uuid.New().String(), fileExtension)
-
-    storageDir := imageUploadPath
-    storagePath := filepath.Join(storageDir, uniqueFilename)
-
-    if err := os.MkdirAll(storageDir, os.ModePerm); err != nil {
-        return sql.NullInt64{}, "", fmt.Errorf("failed to create upload directory '%s' for %s: %w", storageDir, formFieldName, err)
-    }
-
-    dst, err := os.Create(storagePath)
-    if err != nil {
-        return sql.NullInt64{}, storagePath, fmt.Errorf("failed to create destination file '%s' for %s: %w", storagePath, formFieldName, err)
-    }
-    defer dst.Close()
-
     if _, errSeek := file.Seek(0, io.SeekStart); errSeek != nil {
-        _ = os.Remove(storagePath)
-        return sql.NullInt64{}, storagePath, fmt.Errorf("failed to reset file pointer before copy for %s: %w", formFieldName, errSeek)
+        return sql.NullInt64{}, "", fmt.Errorf("failed to reset file pointer before copy for %s: %w", formFieldName, errSeek)
     }
 
-    bytesCopied, err := io.Copy(dst, file)
+    originalFilename := handler.Filename
+    storagePath, bytesCopied, err := s.storage.Save(ctx, generateUniqueFilenameLocal(originalFilename), file)
     if err != nil {
-        _ = os.Remove(storagePath)
-        return sql.NullInt64{}, storagePath, fmt.Errorf("failed to copy %s file content to '%s': %w", formFieldName, storagePath, err)
+        return sql.NullInt64{}, "", fmt.Errorf("failed to store %s: %w", formFieldName, err)
     }
 
     imgRecord := database.Image{
-        StoragePath:      filepath.ToSlash(storagePath),
+        StoragePath:      storagePath,
         FilenameOriginal: originalFilename,
         MimeType:         validatedMimeType,
         SizeBytes:        bytesCopied,
     }
 
-    if err := database.CreateImageTx(ctx, tx, &imgRecord); err != nil {
-        _ = os.Remove(storagePath)
+    if err := tx.Repos().Images.Create(ctx, &imgRecord); err != nil {
+        s.storage.Remove(ctx, storagePath)
         return sql.NullInt64{}, storagePath, fmt.Errorf("failed to save %s image metadata to DB: %w", formFieldName, err)
     }
     return sql.NullInt64{Int64: imgRecord.ImageID, Valid: true}, storagePath, nil
@@ -351,7 +362,6 @@ func (s *Server) uploadAndCreateImageRecordLr(ctx context.Context, tx *sql.Tx, f
 
 func (s *Server) handleListLostReports() http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
-        db := s.db.Get()
         statusFilter := r.URL.Query().Get("status")
         if statusFilter != "" {
             isValidStatus := false
@@ -368,7 +378,7 @@ func (s *Server) handleListLostReports() http.HandlerFunc {
             }
         }
 
-        list, err := database.ListLostReportsWithVehicleInfo(r.Context(), db, statusFilter)
+        list, err := s.repos.LostReports.ListWithVehicleInfo(r.Context(), statusFilter)
         if err != nil {
             writeJSONError(w, "Failed to list lost reports: "+err.Error(), http.StatusInternalServerError)
             return
@@ -376,7 +386,7 @@ func (s *Server) handleListLostReports() http.HandlerFunc {
 
         responseList := make([]LostReportResponse, 0, len(list))
         for i := range list {
-            responseList = append(responseList, s.toLostReportResponse(r.Context(), db, &list[i]))
+            responseList = append(responseList, s.toLostReportResponse(r.Context(), s.repos.Images, &list[i]))
         }
 
         w.Header().Set("Content-Type", "application/json")
@@ -393,8 +403,7 @@ func (s *Server) handleGetLostReportByID() http.HandlerFunc {
             return
         }
 
-        db := s.db.Get()
-        lr, err := database.GetLostReportWithVehicleInfoByID(r.Context(), db, id)
+        lr, err := s.repos.LostReports.GetWithVehicleInfoByID(r.Context(), id)
         if err != nil {
             if errors.Is(err, sql.ErrNoRows) || strings.Contains(err.Error(), "not found") {
                 writeJSONError(w, "Lost report not found", http.StatusNotFound)
@@ -412,7 +421,7 @@ func (s *Server) handleGetLostReportByID() http.HandlerFunc {
             return
         }
 
-        response := s.toLostReportResponse(r.Context(), db, lr)
+        response := s.toLostReportResponse(r.Context(), s.repos.Images, lr)
         w.Header().Set("Content-Type", "application/json")
         json.NewEncoder(w).Encode(response)
     }
@@ -426,8 +435,7 @@ func (s *Server) handleGetUserLostReports() http.HandlerFunc {
             return
         }
 
-        db := s.db.Get()
-        list, err := database.ListLostReportsWithVehicleInfoByUserID(r.Context(), db, requestingUserID)
+        list, err := s.repos.LostReports.ListWithVehicleInfoByUserID(r.Context(), requestingUserID)
         if err != nil {
             writeJSONError(w, "Failed to list your lost reports: "+err.Error(), http.StatusInternalServerError)
             return
@@ -435,7 +443,7 @@ func (s *Server) handleGetUserLostReports() http.HandlerFunc {
 
         responseList := make([]LostReportResponse, 0, len(list))
         for i := range list {
-            responseList = append(responseList, s.toLostReportResponse(r.Context(), db, &list[i]))
+            responseList = append(responseList, s.toLostReportResponse(r.Context(), s.repos.Images, &list[i]))
         }
 
         w.Header().Set("Content-Type", "application/json")
@@ -469,7 +477,7 @@ func (s *Server) handleUpdateLostReport() http.HandlerFunc {
         requestingUserID, _ := r.Context().Value(middleware.UserIDContextKey).(string)
         isAdmin, _ := r.Context().Value(middleware.AdminStatusContextKey).(bool)
 
-        existingLR, err := database.GetLostReportWithVehicleInfoByID(r.Context(), s.db.Get(), id)
+        existingLR, err := s.repos.LostReports.GetWithVehicleInfoByID(r.Context(), id)
         if err != nil {
             if errors.Is(err, sql.ErrNoRows) || strings.Contains(err.Error(), "not found") {
                 writeJSONError(w, "Lost report not found", http.StatusNotFound)
@@ -497,6 +505,7 @@ func (s *Server) handleUpdateLostReport() http.HandlerFunc {
             MotorEvidenceImageID:  existingLR.MotorEvidenceImageID,
             PersonEvidenceImageID: existingLR.PersonEvidenceImageID,
         }
+        originalReport := reportToUpdate
 
         anythingChanged := false
 
@@ -540,6 +549,10 @@ func (s *Server) handleUpdateLostReport() http.HandlerFunc {
                 anythingChanged = true
             }
             if updates.VehicleID != nil && reportToUpdate.VehicleID != *updates.VehicleID {
+                if code, msg := s.checkReportableVehicle(r.Context(), *updates.VehicleID, requestingUserID); code != 0 {
+                    writeJSONError(w, msg, code)
+                    return
+                }
                 reportToUpdate.VehicleID = *updates.VehicleID
                 anythingChanged = true
             }
@@ -562,24 +575,44 @@ func (s *Server) handleUpdateLostReport() http.HandlerFunc {
         }
 
         if !anythingChanged {
-            response := s.toLostReportResponse(r.Context(), s.db.Get(), existingLR)
+            response := s.toLostReportResponse(r.Context(), s.repos.Images, existingLR)
             w.Header().Set("Content-Type", "application/json")
             json.NewEncoder(w).Encode(response)
             return
         }
 
-        if err := database.UpdateLostReport(r.Context(), s.db.Get(), id, &reportToUpdate); err != nil {
+        tx, err := s.store.BeginTx(r.Context())
+        if err != nil {
+            writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+        defer tx.Rollback()
+
+        if err := tx.Repos().LostReports.Update(r.Context(), id, &reportToUpdate); err != nil {
             writeJSONError(w, "Failed to update lost report: "+err.Error(), http.StatusInternalServerError)
             return
         }
 
-        updatedReport, err := database.GetLostReportWithVehicleInfoByID(r.Context(), s.db.Get(), id)
+        action := "lost_report.update"
+        if originalReport.Status != reportToUpdate.Status {
+            action = "lost_report.status_change"
+        }
+        if err := s.audit(r, tx.Repos(), auditChange{Action: action, EntityType: "lost_report", EntityID: id, Before: originalReport, After: reportToUpdate}); err != nil {
+            writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+            return
+        }
+        if err := tx.Commit(); err != nil {
+            writeJSONError(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+
+        updatedReport, err := s.repos.LostReports.GetWithVehicleInfoByID(r.Context(), id)
         if err != nil {
             writeJSONError(w, "Failed to retrieve updated report after update: "+err.Error(), http.StatusInternalServerError)
             return
         }
 
-        response := s.toLostReportResponse(r.Context(), s.db.Get(), updatedReport)
+        response := s.toLostReportResponse(r.Context(), s.repos.Images, updatedReport)
         w.Header().Set("Content-Type", "application/json")
         json.NewEncoder(w).Encode(response)
     }
@@ -597,7 +630,7 @@ func (s *Server) handleDeleteLostReport() http.HandlerFunc {
         requestingUserID, _ := r.Context().Value(middleware.UserIDContextKey).(string)
         isAdmin, _ := r.Context().Value(middleware.AdminStatusContextKey).(bool)
 
-        existingLR, err := database.GetLostReportWithVehicleInfoByID(r.Context(), s.db.Get(), id)
+        existingLR, err := s.repos.LostReports.GetWithVehicleInfoByID(r.Context(), id)
         if err != nil {
             if errors.Is(err, sql.ErrNoRows) || strings.Contains(err.Error(), "not found") {
                 writeJSONError(w, "Lost report not found", http.StatusNotFound)
@@ -612,26 +645,105 @@ func (s *Server) handleDeleteLostReport() http.HandlerFunc {
             return
         }
 
-        // TODO: Consider deleting related images from storage and the 'images' table.
-        // This requires transactional logic.
+        // Gambar bukti tetap disimpan sampai laporan di-purge.
+
+        tx, err := s.store.BeginTx(r.Context())
+        if err != nil {
+            writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+        defer tx.Rollback()
 
-        if err := database.DeleteLostReport(r.Context(), s.db.Get(), id); err != nil {
+        if err := tx.Repos().LostReports.Delete(r.Context(), id); err != nil {
             writeJSONError(w, "Failed to delete lost report: "+err.Error(), http.StatusInternalServerError)
             return
         }
 
+        if err := s.audit(r, tx.Repos(), auditChange{Action: "lost_report.delete", EntityType: "lost_report", EntityID: id, Before: existingLR}); err != nil {
+            writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+            return
+        }
+        if err := tx.Commit(); err != nil {
+            writeJSONError(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+
         w.WriteHeader(http.StatusNoContent)
     }
 }
 
+// handleRestoreLostReport membatalkan soft delete laporan. Laporan untuk
+// kendaraan atau pelapor yang masih terhapus ditolak agar tidak ada laporan
+// aktif tanpa induk.
+func (s *Server) handleRestoreLostReport() http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        id, err := strconv.Atoi(mux.Vars(r)["id"])
+        if err != nil {
+            writeJSONError(w, "invalid lost_id: must be an integer", http.StatusBadRequest)
+            return
+        }
+
+        tx, err := s.store.BeginTx(r.Context())
+        if err != nil {
+            writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+        defer tx.Rollback()
+
+        if err := tx.Repos().LostReports.Restore(r.Context(), id); err != nil {
+            if errors.Is(err, database.ErrLostReportNotFound) {
+                writeJSONError(w, "Deleted lost report not found", http.StatusNotFound)
+            } else {
+                writeJSONError(w, "Failed to restore lost report: "+err.Error(), http.StatusInternalServerError)
+            }
+            return
+        }
+        restored, err := tx.Repos().LostReports.GetWithVehicleInfoByID(r.Context(), id)
+        if err != nil {
+            writeJSONError(w, "Failed to retrieve restored lost report: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+        if _, err := tx.Repos().Vehicles.GetByID(r.Context(), int64(restored.VehicleID)); err != nil {
+            if errors.Is(err, database.ErrVehicleNotFound) {
+                writeJSONError(w, "Vehicle of this lost report is deleted; restore the vehicle instead", http.StatusConflict)
+            } else {
+                writeJSONError(w, "Failed to retrieve vehicle: "+err.Error(), http.StatusInternalServerError)
+            }
+            return
+        }
+        if _, err := tx.Repos().Users.FindByID(r.Context(), restored.UserID); err != nil {
+            if errors.Is(err, database.ErrUserNotFound) {
+                writeJSONError(w, "Reporter of this lost report is deleted; restore the user instead", http.StatusConflict)
+            } else {
+                writeJSONError(w, "Failed to retrieve reporter: "+err.Error(), http.StatusInternalServerError)
+            }
+            return
+        }
+
+        if err := s.audit(r, tx.Repos(), auditChange{Action: "lost_report.restore", EntityType: "lost_report", EntityID: id, After: restored}); err != nil {
+            writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+            return
+        }
+        response := s.toLostReportResponse(r.Context(), tx.Repos().Images, restored)
+        if err := tx.Commit(); err != nil {
+            writeJSONError(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(response)
+    }
+}
+
 func (s *Server) RegisterLostReportRoutes(r *mux.Router) {
 
     adminOnlyMiddleware := middleware.AdminOnlyMiddleware()
 
     r.Handle("/lost_reports", adminOnlyMiddleware(s.handleListLostReports())).Methods("GET")
-    r.HandleFunc("/lost_reports", s.handleCreateLostReport()).Methods("POST")
+    r.Handle("/lost_reports", s.uploadQuota(s.handleCreateLostReport())).Methods("POST")
     r.HandleFunc("/lost_reports/my", s.handleGetUserLostReports()).Methods("GET")
     r.HandleFunc("/lost_reports/{id:[0-9]+}", s.handleGetLostReportByID()).Methods("GET")
     r.HandleFunc("/lost_reports/{id:[0-9]+}", s.handleUpdateLostReport()).Methods("PUT")
     r.HandleFunc("/lost_reports/{id:[0-9]+}", s.handleDeleteLostReport()).Methods("DELETE")
+    r.Handle("/lost_reports/{id:[0-9]+}/restore", adminOnlyMiddleware(s.handleRestoreLostReport())).Methods("POST")
 }
\ No newline at end of file
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/metrics"
+)
+
+// metricsSource menghubungkan gauge bisnis dengan repository.
+type metricsSource struct {
+	repos database.Repositories
+}
+
+func (m metricsSource) CountLostReportsByStatus(ctx context.Context) (map[string]int, error) {
+	return m.repos.LostReports.CountByStatus(ctx)
+}
+
+func (m metricsSource) CountActiveCameras(ctx context.Context) (int, error) {
+	return m.repos.Cameras.CountActive(ctx)
+}
+
+// observeUpload mencatat hasil satu upload gambar. Dipanggil lewat defer di
+// helper upload sehingga setiap jalur error ikut terhitung.
+func (s *Server) observeUpload(category string, size int64, err error) {
+	if err != nil {
+		s.metrics.UploadFailed(category)
+		return
+	}
+	s.metrics.UploadSucceeded(category, size)
+}
+
+// vehicleUploadCategory memetakan nama field form kendaraan ke kategori metrik.
+func vehicleUploadCategory(formFieldName string) string {
+	switch {
+	case strings.HasPrefix(formFieldName, "stnk"):
+		return metrics.CategorySTNK
+	case strings.HasPrefix(formFieldName, "kk"):
+		return metrics.CategoryKK
+	default:
+		return metrics.CategoryOther
+	}
+}
@@ -0,0 +1,604 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jaga-project/jaga-backend/internal/auth"
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+	"github.com/jaga-project/jaga-backend/internal/middleware"
+)
+
+const (
+	mfaChallengeTTL   = 5 * time.Minute
+	mfaIssuer         = "JAGA"
+	recoveryCodeCount = 10
+)
+
+// LoginChallengeResponse dikembalikan /auth/login (status 202) untuk admin
+// yang harus menyelesaikan 2FA. ChallengeToken tidak berlaku untuk API lain.
+type LoginChallengeResponse struct {
+	MFARequired           bool      `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool      `json:"mfa_enrollment_required,omitempty"`
+	ChallengeToken        string    `json:"challenge_token"`
+	ExpiresAt             time.Time `json:"expires_at"`
+}
+
+type mfaStatus struct {
+	Enabled  bool
+	Required bool
+}
+
+func (s *Server) adminMFAStatus(ctx context.Context, userID string) (mfaStatus, error) {
+	var st mfaStatus
+	m, err := s.repos.AdminMFA.Get(ctx, userID)
+	switch {
+	case err == nil:
+		st.Enabled = m.EnabledAt != nil
+	case !errors.Is(err, database.ErrMFANotFound):
+		return st, err
+	}
+
+	admin, err := s.repos.Admins.GetByUserID(ctx, userID)
+	if err != nil {
+		return st, err
+	}
+	policy, err := s.repos.AdminMFA.GetPolicy(ctx)
+	if err != nil {
+		return st, err
+	}
+	st.Required = policy.Requires(admin.AdminLevel)
+	return st, nil
+}
+
+// startMFAChallenge menjawab login admin dengan token challenge bila 2FA
+// aktif atau diwajibkan kebijakan. false berarti login boleh langsung
+// menerbitkan JWT.
+func (s *Server) startMFAChallenge(w http.ResponseWriter, r *http.Request, user *database.User) bool {
+	st, err := s.adminMFAStatus(r.Context(), user.UserID)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("failed to load 2FA status during login", "user_id", user.UserID, "error", err)
+		writeJSONError(w, "Failed to check two-factor authentication status", http.StatusInternalServerError)
+		return true
+	}
+
+	var resp LoginChallengeResponse
+	purpose := auth.PurposeMFALogin
+	switch {
+	case st.Enabled:
+		resp.MFARequired = true
+	case st.Required:
+		resp.MFAEnrollmentRequired = true
+		purpose = auth.PurposeMFAEnroll
+	default:
+		return false
+	}
+
+	resp.ChallengeToken, resp.ExpiresAt, err = s.tokens.GenerateChallenge(user.UserID, purpose, mfaChallengeTTL)
+	if err != nil {
+		writeJSONError(w, "Failed to generate challenge token: "+err.Error(), http.StatusInternalServerError)
+		return true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(resp)
+	return true
+}
+
+// verifyMFACode memeriksa kode TOTP atau kode pemulihan. Kode TOTP yang
+// sama tidak diterima dua kali.
+func (s *Server) verifyMFACode(ctx context.Context, repos database.Repositories, m *database.AdminMFA, code, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		return repos.AdminMFA.UseRecoveryCode(ctx, m.UserID, auth.HashRecoveryCode(recoveryCode), time.Now())
+	}
+	step, ok := auth.VerifyTOTP(m.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return repos.AdminMFA.UseStep(ctx, m.UserID, step)
+}
+
+type mfaLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+func (s *Server) handleMFALogin() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req mfaLoginRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.ChallengeToken == "" || (req.Code == "" && req.RecoveryCode == "") {
+			writeJSONError(w, "challenge_token and code or recovery_code are required", http.StatusBadRequest)
+			return
+		}
+		claims, err := s.tokens.ValidateChallenge(req.ChallengeToken, auth.PurposeMFALogin)
+		if err != nil {
+			writeJSONError(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		user, err := s.repos.Users.FindByID(r.Context(), claims.UserID)
+		if err != nil {
+			writeJSONError(w, "Unauthorized: user no longer exists", http.StatusUnauthorized)
+			return
+		}
+		lockKey := loginLockoutKey(user.Email)
+		if s.limiter != nil {
+			if wait := s.limiter.Locked(r.Context(), lockKey); wait > 0 {
+				middleware.SetRetryAfter(w, wait)
+				writeJSONError(w, "Too many failed login attempts, please retry later", http.StatusTooManyRequests)
+				return
+			}
+		}
+
+		m, err := s.repos.AdminMFA.Get(r.Context(), user.UserID)
+		if err != nil || m.EnabledAt == nil {
+			writeJSONError(w, "Two-factor authentication is not enabled for this account", http.StatusConflict)
+			return
+		}
+		ok, err := s.verifyMFACode(r.Context(), s.repos, m, req.Code, req.RecoveryCode)
+		if err != nil {
+			writeJSONError(w, "Failed to verify code: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			s.loginFailed(r, lockKey)
+			writeJSONError(w, "Invalid two-factor authentication code", http.StatusUnauthorized)
+			return
+		}
+		if s.limiter != nil {
+			s.limiter.Success(r.Context(), lockKey)
+		}
+
+		isAdmin, err := s.repos.Admins.IsAdmin(r.Context(), user.UserID)
+		if err != nil {
+			writeJSONError(w, "Failed to check admin status: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.writeLoginResponse(w, user, isAdmin)
+	}
+}
+
+// mfaEnrollSubject menerima token challenge pendaftaran (admin yang wajib
+// 2FA tetapi belum mendaftar) atau JWT sesi admin biasa.
+func (s *Server) mfaEnrollSubject(r *http.Request) (userID string, viaChallenge bool, err error) {
+	tokenStr := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if tokenStr == "" || tokenStr == r.Header.Get("Authorization") {
+		return "", false, errors.New("missing bearer token")
+	}
+	if claims, err := s.tokens.ValidateChallenge(tokenStr, auth.PurposeMFAEnroll); err == nil {
+		return claims.UserID, true, nil
+	}
+	claims, err := s.tokens.ValidateJWT(tokenStr)
+	if err != nil {
+		return "", false, err
+	}
+	isAdmin, err := s.repos.Admins.IsAdmin(r.Context(), claims.UserID)
+	if err != nil || !isAdmin {
+		return "", false, errors.New("two-factor authentication is only available for admin accounts")
+	}
+	return claims.UserID, false, nil
+}
+
+type mfaEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+func (s *Server) handleMFAEnroll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, _, err := s.mfaEnrollSubject(r)
+		if err != nil {
+			writeJSONError(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+		user, err := s.repos.Users.FindByID(r.Context(), userID)
+		if err != nil {
+			writeJSONError(w, "Failed to retrieve user: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		secret, err := auth.NewTOTPSecret()
+		if err != nil {
+			writeJSONError(w, "Failed to generate secret: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := s.repos.AdminMFA.SaveEnrollment(r.Context(), userID, secret); err != nil {
+			if errors.Is(err, database.ErrMFAAlreadyEnabled) {
+				writeJSONError(w, "Two-factor authentication is already enabled", http.StatusConflict)
+			} else {
+				writeJSONError(w, "Failed to save enrollment: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(mfaEnrollResponse{
+			Secret:     secret,
+			OTPAuthURL: auth.TOTPURI(secret, mfaIssuer, user.Email),
+		})
+	}
+}
+
+type mfaCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type mfaConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	// Login berisi sesi penuh bila pendaftaran dilakukan dengan token
+	// challenge dari /auth/login.
+	Login *LoginResponse `json:"login,omitempty"`
+}
+
+func (s *Server) handleMFAConfirm() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, viaChallenge, err := s.mfaEnrollSubject(r)
+		if err != nil {
+			writeJSONError(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+		var req mfaCodeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		repos := tx.Repos()
+
+		m, err := repos.AdminMFA.Get(r.Context(), userID)
+		if err != nil {
+			writeJSONError(w, "Start enrollment with POST /auth/2fa/enroll first", http.StatusConflict)
+			return
+		}
+		if m.EnabledAt != nil {
+			writeJSONError(w, "Two-factor authentication is already enabled", http.StatusConflict)
+			return
+		}
+		now := time.Now()
+		step, ok := auth.VerifyTOTP(m.Secret, req.Code, now)
+		if !ok {
+			writeJSONError(w, "Invalid two-factor authentication code", http.StatusBadRequest)
+			return
+		}
+		codes, hashes, err := auth.NewRecoveryCodes(recoveryCodeCount)
+		if err != nil {
+			writeJSONError(w, "Failed to generate recovery codes: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := repos.AdminMFA.Enable(r.Context(), userID, step, now); err != nil {
+			writeJSONError(w, "Failed to enable two-factor authentication: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := repos.AdminMFA.ReplaceRecoveryCodes(r.Context(), userID, hashes); err != nil {
+			writeJSONError(w, "Failed to save recovery codes: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resp := mfaConfirmResponse{RecoveryCodes: codes}
+		if viaChallenge {
+			user, err := s.repos.Users.FindByID(r.Context(), userID)
+			if err != nil {
+				writeJSONError(w, "Failed to retrieve user: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			resp.Login, err = s.newLoginResponse(user, true)
+			if err != nil {
+				writeJSONError(w, "Failed to generate token: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if s.limiter != nil {
+				s.limiter.Success(r.Context(), loginLockoutKey(user.Email))
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+type mfaStatusResponse struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+func (s *Server) handleGetMyMFA() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := r.Context().Value(middleware.UserIDContextKey).(string)
+		st, err := s.adminMFAStatus(r.Context(), userID)
+		if err != nil {
+			writeJSONError(w, "Failed to load two-factor authentication status: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		remaining, err := s.repos.AdminMFA.CountRecoveryCodes(r.Context(), userID)
+		if err != nil {
+			writeJSONError(w, "Failed to count recovery codes: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(mfaStatusResponse{Enabled: st.Enabled, Required: st.Required, RecoveryCodesRemaining: remaining})
+	}
+}
+
+// requireCurrentMFACode memastikan perubahan 2FA milik sendiri disertai kode
+// yang valid, bukan hanya JWT yang mungkin dicuri.
+func (s *Server) requireCurrentMFACode(w http.ResponseWriter, r *http.Request, repos database.Repositories, userID string) (*database.AdminMFA, bool) {
+	var req mfaLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	m, err := repos.AdminMFA.Get(r.Context(), userID)
+	if err != nil || m.EnabledAt == nil {
+		writeJSONError(w, "Two-factor authentication is not enabled", http.StatusConflict)
+		return nil, false
+	}
+	ok, err := s.verifyMFACode(r.Context(), repos, m, req.Code, req.RecoveryCode)
+	if err != nil {
+		writeJSONError(w, "Failed to verify code: "+err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if !ok {
+		writeJSONError(w, "Invalid two-factor authentication code", http.StatusUnauthorized)
+		return nil, false
+	}
+	return m, true
+}
+
+func (s *Server) handleRegenerateRecoveryCodes() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := r.Context().Value(middleware.UserIDContextKey).(string)
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		if _, ok := s.requireCurrentMFACode(w, r, tx.Repos(), userID); !ok {
+			return
+		}
+		codes, hashes, err := auth.NewRecoveryCodes(recoveryCodeCount)
+		if err != nil {
+			writeJSONError(w, "Failed to generate recovery codes: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Repos().AdminMFA.ReplaceRecoveryCodes(r.Context(), userID, hashes); err != nil {
+			writeJSONError(w, "Failed to save recovery codes: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(mfaConfirmResponse{RecoveryCodes: codes})
+	}
+}
+
+func (s *Server) handleDisableMyMFA() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := r.Context().Value(middleware.UserIDContextKey).(string)
+		st, err := s.adminMFAStatus(r.Context(), userID)
+		if err != nil {
+			writeJSONError(w, "Failed to load two-factor authentication status: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if st.Required {
+			writeJSONError(w, "Two-factor authentication is required for your admin level and cannot be disabled", http.StatusForbidden)
+			return
+		}
+
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		if _, ok := s.requireCurrentMFACode(w, r, tx.Repos(), userID); !ok {
+			return
+		}
+		if err := tx.Repos().AdminMFA.Delete(r.Context(), userID); err != nil {
+			writeJSONError(w, "Failed to disable two-factor authentication: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := s.audit(r, tx.Repos(), auditChange{Action: "admin_mfa.disable", EntityType: "admin_mfa", EntityID: userID}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleResetAdminMFA menghapus 2FA admin lain (mis. perangkat hilang dan
+// kode pemulihan habis). Admin tersebut akan diminta mendaftar ulang saat
+// login bila kebijakan mewajibkan.
+func (s *Server) handleResetAdminMFA() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		targetID := mux.Vars(r)["user_id"]
+		requesterID, _ := r.Context().Value(middleware.UserIDContextKey).(string)
+		if targetID == requesterID {
+			writeJSONError(w, "Use DELETE /api/admins/me/2fa to change your own two-factor authentication", http.StatusBadRequest)
+			return
+		}
+		requester, err := s.repos.Admins.GetByUserID(r.Context(), requesterID)
+		if err != nil {
+			writeJSONError(w, "Failed to get admin: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		target, err := s.repos.Admins.GetByUserID(r.Context(), targetID)
+		if err != nil {
+			if errors.Is(err, database.ErrAdminNotFound) {
+				writeJSONError(w, "Admin not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to get admin: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Reset 2FA admin yang levelnya lebih tinggi membuka jalan
+		// pengambilalihan akun.
+		if requester.AdminLevel < target.AdminLevel {
+			writeJSONError(w, "Forbidden: You cannot reset two-factor authentication of a higher-level admin", http.StatusForbidden)
+			return
+		}
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		if err := tx.Repos().AdminMFA.Delete(r.Context(), targetID); err != nil {
+			if errors.Is(err, database.ErrMFANotFound) {
+				writeJSONError(w, "Two-factor authentication is not set up for this admin", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to reset two-factor authentication: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if err := s.audit(r, tx.Repos(), auditChange{Action: "admin_mfa.reset", EntityType: "admin_mfa", EntityID: targetID}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		logging.FromContext(r.Context()).Warn("admin 2FA reset", "target_user_id", targetID, "by_user_id", requesterID)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) handleGetMFAPolicy() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		policy, err := s.repos.AdminMFA.GetPolicy(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to load 2FA policy: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(policy)
+	}
+}
+
+type mfaPolicyRequest struct {
+	RequireAboveLevel *int `json:"require_above_level"`
+}
+
+func (s *Server) handleUpdateMFAPolicy() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req mfaPolicyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.RequireAboveLevel == nil || *req.RequireAboveLevel < 0 {
+			writeJSONError(w, "require_above_level must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		requesterID, _ := r.Context().Value(middleware.UserIDContextKey).(string)
+		top, err := s.isTopLevelAdmin(r.Context(), requesterID)
+		if err != nil {
+			writeJSONError(w, "Failed to get admin: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !top {
+			writeJSONError(w, "Forbidden: Only top-level admins can change the 2FA policy", http.StatusForbidden)
+			return
+		}
+		policy := database.MFAPolicy{RequireAboveLevel: *req.RequireAboveLevel, UpdatedBy: &requesterID}
+
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		previous, err := tx.Repos().AdminMFA.GetPolicy(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to load 2FA policy: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Repos().AdminMFA.UpdatePolicy(r.Context(), &policy); err != nil {
+			writeJSONError(w, "Failed to update 2FA policy: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := s.audit(r, tx.Repos(), auditChange{Action: "mfa_policy.update", EntityType: "mfa_policy", EntityID: "default", Before: previous, After: policy}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(policy)
+	}
+}
+
+// isTopLevelAdmin bernilai true bila tidak ada admin lain dengan
+// admin_level lebih tinggi dari userID.
+func (s *Server) isTopLevelAdmin(ctx context.Context, userID string) (bool, error) {
+	admins, err := s.repos.Admins.List(ctx)
+	if err != nil {
+		return false, err
+	}
+	level, found := 0, false
+	for _, a := range admins {
+		if a.UserID == userID {
+			level, found = a.AdminLevel, true
+		}
+	}
+	if !found {
+		return false, nil
+	}
+	for _, a := range admins {
+		if a.AdminLevel > level {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (s *Server) RegisterMFARoutes(r *mux.Router) {
+	limit := s.rateLimit("login", s.cfg.RateLimit.Login, middleware.KeyByIP(s.cfg.RateLimit.TrustProxyHeaders))
+	r.Handle("/auth/login/2fa", limit(s.handleMFALogin())).Methods("POST")
+	r.Handle("/auth/2fa/enroll", limit(s.handleMFAEnroll())).Methods("POST")
+	r.Handle("/auth/2fa/confirm", limit(s.handleMFAConfirm())).Methods("POST")
+}
+
+// RegisterAdminMFARoutes dipasang di router /api/admins sebelum
+// RegisterAdminRoutes agar "me" tidak dianggap sebagai user_id.
+func (s *Server) RegisterAdminMFARoutes(r *mux.Router) {
+	r.Handle("/me/2fa", s.handleGetMyMFA()).Methods("GET")
+	r.Handle("/me/2fa", s.handleDisableMyMFA()).Methods("DELETE")
+	r.Handle("/me/2fa/recovery-codes", s.handleRegenerateRecoveryCodes()).Methods("POST")
+	r.Handle("/security/mfa-policy", s.handleGetMFAPolicy()).Methods("GET")
+	r.Handle("/security/mfa-policy", s.handleUpdateMFAPolicy()).Methods("PUT")
+	r.Handle("/{user_id}/2fa", s.handleResetAdminMFA()).Methods("DELETE")
+}
@@ -0,0 +1,440 @@
+package server
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+	"github.com/jaga-project/jaga-backend/internal/mail"
+	"github.com/jaga-project/jaga-backend/internal/middleware"
+)
+
+// ExportImage menjelaskan satu gambar yang diunggah user. File adalah path
+// di dalam ZIP ekspor, kosong bila filenya sudah tidak ada di storage.
+type ExportImage struct {
+	ImageID          int64     `json:"image_id"`
+	Kind             string    `json:"kind"`
+	File             string    `json:"file,omitempty"`
+	FilenameOriginal string    `json:"filename_original,omitempty"`
+	MimeType         string    `json:"mime_type,omitempty"`
+	SizeBytes        int64     `json:"size_bytes,omitempty"`
+	UploadedAt       time.Time `json:"uploaded_at"`
+}
+
+// UserDataExport adalah isi data.json pada ZIP /api/users/me/export.
+type UserDataExport struct {
+	ExportedAt  time.Time            `json:"exported_at"`
+	Profile     database.User        `json:"profile"`
+	Vehicles    []VehicleResponse    `json:"vehicles"`
+	LostReports []LostReportResponse `json:"lost_reports"`
+	Results     []ResultResponse     `json:"results"`
+	Images      []ExportImage        `json:"images"`
+}
+
+// handleExportUserData mengirim ZIP berisi data.json dan semua gambar yang
+// diunggah user. Data dikumpulkan lebih dulu agar error database masih bisa
+// dijawab dengan status yang benar sebelum ZIP mulai dikirim.
+func (s *Server) handleExportUserData() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID, ok := ctx.Value(middleware.UserIDContextKey).(string)
+		if !ok || userID == "" {
+			writeJSONError(w, "Unauthorized: User ID not found in token", http.StatusUnauthorized)
+			return
+		}
+
+		user, err := s.repos.Users.FindByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, database.ErrUserNotFound) {
+				writeJSONError(w, "User not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to retrieve user: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		user.Password = ""
+		export := UserDataExport{
+			ExportedAt:  time.Now(),
+			Profile:     *user,
+			Vehicles:    []VehicleResponse{},
+			LostReports: []LostReportResponse{},
+			Results:     []ResultResponse{},
+			Images:      []ExportImage{},
+		}
+
+		var imageRefs []ExportImage
+		addImage := func(kind string, id *int64) {
+			if id != nil {
+				imageRefs = append(imageRefs, ExportImage{ImageID: *id, Kind: kind})
+			}
+		}
+		addImage("ktp", user.KTPImageID)
+
+		vehicles, err := s.repos.Vehicles.ListByUserID(ctx, userID)
+		if err != nil {
+			writeJSONError(w, "Failed to retrieve vehicles: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for i := range vehicles {
+			v := &vehicles[i]
+			export.Vehicles = append(export.Vehicles, s.toVehicleResponse(ctx, s.repos.Images, v))
+			if v.STNKImageID.Valid {
+				addImage("stnk", &v.STNKImageID.Int64)
+			}
+			if v.KKImageID.Valid {
+				addImage("kk", &v.KKImageID.Int64)
+			}
+		}
+
+		reports, err := s.repos.LostReports.ListWithVehicleInfoByUserID(ctx, userID)
+		if err != nil {
+			writeJSONError(w, "Failed to retrieve lost reports: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for i := range reports {
+			lr := &reports[i]
+			export.LostReports = append(export.LostReports, s.toLostReportResponse(ctx, s.repos.Images, lr))
+			addImage("motor_evidence", lr.MotorEvidenceImageID)
+			addImage("person_evidence", lr.PersonEvidenceImageID)
+
+			results, err := s.repos.Suspects.ListResultsByLostReportID(ctx, lr.LostID, database.SuspectResultFilter{})
+			if err != nil {
+				writeJSONError(w, "Failed to retrieve analysis results: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			run, err := s.repos.AnalysisRuns.GetLatestByLostReportID(ctx, lr.LostID)
+			if err != nil && !errors.Is(err, database.ErrAnalysisRunNotFound) {
+				writeJSONError(w, "Failed to get analysis run: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			export.Results = append(export.Results, toResultResponse(lr.LostID, lr.Status, run, results, len(results), database.SuspectResultFilter{}))
+		}
+
+		var storagePaths []string
+		for _, ref := range imageRefs {
+			img, err := s.repos.Images.GetByID(ctx, ref.ImageID)
+			if err != nil {
+				if errors.Is(err, database.ErrImageNotFound) {
+					continue
+				}
+				writeJSONError(w, "Failed to retrieve image metadata: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			ref.FilenameOriginal = img.FilenameOriginal
+			ref.MimeType = img.MimeType
+			ref.SizeBytes = img.SizeBytes
+			ref.UploadedAt = img.UploadedAt
+			export.Images = append(export.Images, ref)
+			storagePaths = append(storagePaths, img.StoragePath)
+		}
+
+		// Audit dicatat dan di-commit sebelum ZIP dikirim.
+		tx, err := s.store.BeginTx(ctx)
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		if err := s.audit(r, tx.Repos(), auditChange{Action: "user.export", EntityType: "user", EntityID: userID}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="jaga-data-%s.zip"`, export.ExportedAt.Format("20060102-150405")))
+		zw := zip.NewWriter(w)
+		for i := range export.Images {
+			img := &export.Images[i]
+			name := fmt.Sprintf("images/%d_%s", img.ImageID, path.Base(storagePaths[i]))
+			if err := s.copyToZip(r, zw, name, storagePaths[i]); err != nil {
+				logging.FromContext(ctx).Warn("failed to export image file", "image_id", img.ImageID, "error", err)
+				continue
+			}
+			img.File = name
+		}
+		f, err := zw.Create("data.json")
+		if err == nil {
+			enc := json.NewEncoder(f)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(export)
+		}
+		if err == nil {
+			err = zw.Close()
+		}
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to write data export", "error", err)
+		}
+	}
+}
+
+func (s *Server) copyToZip(r *http.Request, zw *zip.Writer, name, storagePath string) error {
+	src, err := s.storage.Open(r.Context(), storagePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+type erasureRequestPayload struct {
+	Reason string `json:"reason"`
+}
+
+func (s *Server) handleCreateErasureRequest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value(middleware.UserIDContextKey).(string)
+		if !ok || userID == "" {
+			writeJSONError(w, "Unauthorized: User ID not found in token", http.StatusUnauthorized)
+			return
+		}
+		var payload erasureRequestPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+			writeJSONError(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		repos := tx.Repos()
+
+		latest, err := repos.Erasures.GetLatestByUserID(r.Context(), userID)
+		switch {
+		case err == nil && latest.Status == database.ErasureStatusPending:
+			writeJSONError(w, "An erasure request is already pending", http.StatusConflict)
+			return
+		case err == nil && latest.Status == database.ErasureStatusApproved:
+			writeJSONError(w, "Personal data of this account has already been erased", http.StatusConflict)
+			return
+		case err != nil && !errors.Is(err, database.ErrErasureRequestNotFound):
+			writeJSONError(w, "Failed to retrieve erasure request: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		req := database.ErasureRequest{UserID: userID, Reason: strings.TrimSpace(payload.Reason)}
+		if err := repos.Erasures.Create(r.Context(), &req); err != nil {
+			if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+				writeJSONError(w, "An erasure request is already pending", http.StatusConflict)
+				return
+			}
+			writeJSONError(w, "Failed to create erasure request: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := s.audit(r, repos, auditChange{Action: "erasure_request.create", EntityType: "erasure_request", EntityID: req.RequestID, After: req}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(req)
+	}
+}
+
+func (s *Server) handleGetMyErasureRequest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value(middleware.UserIDContextKey).(string)
+		if !ok || userID == "" {
+			writeJSONError(w, "Unauthorized: User ID not found in token", http.StatusUnauthorized)
+			return
+		}
+		req, err := s.repos.Erasures.GetLatestByUserID(r.Context(), userID)
+		if err != nil {
+			if errors.Is(err, database.ErrErasureRequestNotFound) {
+				writeJSONError(w, "No erasure request found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to retrieve erasure request: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(req)
+	}
+}
+
+func (s *Server) handleListErasureRequests() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := strings.ToUpper(r.URL.Query().Get("status"))
+		switch status {
+		case "", database.ErasureStatusPending, database.ErasureStatusApproved, database.ErasureStatusRejected:
+		default:
+			writeJSONError(w, "Invalid status filter: must be PENDING, APPROVED or REJECTED", http.StatusBadRequest)
+			return
+		}
+		list, err := s.repos.Erasures.List(r.Context(), status)
+		if err != nil {
+			writeJSONError(w, "Failed to retrieve erasure requests: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if list == nil {
+			list = []database.ErasureRequest{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	}
+}
+
+type erasureDecisionPayload struct {
+	Note string `json:"note"`
+}
+
+// handleDecideErasureRequest menyetujui atau menolak permintaan penghapusan.
+// Persetujuan menganonimkan user dalam transaksi yang sama; file gambarnya
+// dihapus setelah commit. Email pemberitahuan dikirim ke alamat lama user.
+func (s *Server) handleDecideErasureRequest(status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			writeJSONError(w, "Invalid erasure request ID", http.StatusBadRequest)
+			return
+		}
+		adminID, _ := ctx.Value(middleware.UserIDContextKey).(string)
+		var payload erasureDecisionPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+			writeJSONError(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tx, err := s.store.BeginTx(ctx)
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		repos := tx.Repos()
+
+		existing, err := repos.Erasures.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, database.ErrErasureRequestNotFound) {
+				writeJSONError(w, "Erasure request not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to retrieve erasure request: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if existing.UserID == adminID {
+			writeJSONError(w, "Forbidden: You cannot decide your own erasure request", http.StatusForbidden)
+			return
+		}
+		if err := repos.Erasures.Decide(ctx, id, status, adminID, strings.TrimSpace(payload.Note), time.Now()); err != nil {
+			if errors.Is(err, database.ErrErasureRequestDecided) {
+				writeJSONError(w, "Erasure request has already been decided", http.StatusConflict)
+			} else {
+				writeJSONError(w, "Failed to update erasure request: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		user, err := repos.Users.FindByID(ctx, existing.UserID)
+		if err != nil && !errors.Is(err, database.ErrUserNotFound) {
+			writeJSONError(w, "Failed to retrieve user: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var paths []string
+		if status == database.ErasureStatusApproved {
+			if user == nil {
+				writeJSONError(w, "User of this erasure request is deleted; restore the user first", http.StatusConflict)
+				return
+			}
+			imageIDs, err := repos.Users.Anonymize(ctx, existing.UserID)
+			if err != nil {
+				writeJSONError(w, "Failed to anonymize user: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			paths, err = deleteImageRecords(ctx, repos.Images, imageIDs)
+			if err != nil {
+				writeJSONError(w, "Failed to delete personal images: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			// Entri ini sengaja tidak memuat data lama agar audit_log tidak
+			// menyimpan ulang data yang dihapus.
+			anonymized := map[string]interface{}{"erasure_request_id": id, "images_deleted": len(imageIDs)}
+			if err := s.audit(r, repos, auditChange{Action: "user.anonymize", EntityType: "user", EntityID: existing.UserID, After: anonymized}); err != nil {
+				writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		decided, err := repos.Erasures.GetByID(ctx, id)
+		if err != nil {
+			writeJSONError(w, "Failed to retrieve erasure request: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		action := "erasure_request.approve"
+		if status == database.ErasureStatusRejected {
+			action = "erasure_request.reject"
+		}
+		if err := s.audit(r, repos, auditChange{Action: action, EntityType: "erasure_request", EntityID: id, Before: existing, After: decided}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.removeImageFiles(ctx, paths)
+		if user != nil {
+			s.sendErasureDecisionEmail(ctx, user, decided)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(decided)
+	}
+}
+
+func (s *Server) sendErasureDecisionEmail(ctx context.Context, user *database.User, req *database.ErasureRequest) {
+	msg := mail.Message{To: user.Email}
+	if req.Status == database.ErasureStatusApproved {
+		msg.Subject = "Data pribadi Anda telah dihapus"
+		msg.Body = fmt.Sprintf("Halo %s,\n\nPermintaan penghapusan data Anda telah disetujui. Data identitas, dokumen dan foto yang Anda unggah sudah dihapus dari JAGA dan akun Anda tidak dapat digunakan lagi.\n", user.Name)
+	} else {
+		msg.Subject = "Permintaan penghapusan data ditolak"
+		msg.Body = fmt.Sprintf("Halo %s,\n\nPermintaan penghapusan data Anda ditolak.\n", user.Name)
+		if req.DecisionNote != "" {
+			msg.Body += "\nCatatan admin: " + req.DecisionNote + "\n"
+		}
+	}
+	s.sendMail(ctx, msg)
+}
+
+func (s *Server) RegisterPrivacyRoutes(r *mux.Router) {
+	r.Handle("/users/me/export", s.rateLimit("data_export", s.cfg.RateLimit.Login, middleware.KeyByUser)(s.handleExportUserData())).Methods("GET")
+	r.Handle("/users/me/erasure-request", s.handleCreateErasureRequest()).Methods("POST")
+	r.Handle("/users/me/erasure-request", s.handleGetMyErasureRequest()).Methods("GET")
+}
+
+// RegisterAdminPrivacyRoutes harus didaftarkan sebelum RegisterAdminRoutes
+// agar /erasure-requests tidak tertangkap route /{user_id}.
+func (s *Server) RegisterAdminPrivacyRoutes(r *mux.Router) {
+	r.Handle("/erasure-requests", s.handleListErasureRequests()).Methods("GET")
+	r.Handle("/erasure-requests/{id:[0-9]+}/approve", s.handleDecideErasureRequest(database.ErasureStatusApproved)).Methods("POST")
+	r.Handle("/erasure-requests/{id:[0-9]+}/reject", s.handleDecideErasureRequest(database.ErasureStatusRejected)).Methods("POST")
+}
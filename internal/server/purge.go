@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+)
+
+// PurgeResult adalah jumlah baris dan file gambar yang dihapus permanen oleh
+// satu kali purge.
+type PurgeResult struct {
+	LostReports int
+	Vehicles    int
+	Users       int
+	Cameras     int
+	Images      int
+}
+
+// PurgeDeleted menghapus permanen baris yang di-soft delete sebelum before
+// beserta gambarnya. Urutannya laporan, kendaraan, user, lalu kamera agar
+// gambar milik baris anak terkumpul sebelum cascade menghapusnya. File
+// gambar baru dihapus setelah commit sehingga rollback tidak meninggalkan
+// record tanpa file.
+func (s *Server) PurgeDeleted(ctx context.Context, before time.Time) (*PurgeResult, error) {
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	repos := tx.Repos()
+
+	result := &PurgeResult{}
+	steps := []struct {
+		count *int
+		purge func(context.Context, time.Time) (*database.PurgedRows, error)
+	}{
+		{&result.LostReports, repos.LostReports.PurgeDeleted},
+		{&result.Vehicles, repos.Vehicles.PurgeDeleted},
+		{&result.Users, repos.Users.PurgeDeleted},
+		{&result.Cameras, repos.Cameras.PurgeDeleted},
+	}
+	var imageIDs []int64
+	for _, step := range steps {
+		purged, err := step.purge(ctx, before)
+		if err != nil {
+			return nil, err
+		}
+		*step.count = purged.Count
+		imageIDs = append(imageIDs, purged.ImageIDs...)
+	}
+
+	paths, err := deleteImageRecords(ctx, repos.Images, imageIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	result.Images = s.removeImageFiles(ctx, paths)
+	return result, nil
+}
+
+// deleteImageRecords menghapus record gambar dan mengembalikan path filenya.
+// Filenya dihapus dengan removeImageFiles setelah transaksi di-commit.
+func deleteImageRecords(ctx context.Context, images database.ImageRepo, ids []int64) ([]string, error) {
+	var paths []string
+	for _, id := range ids {
+		path, err := images.GetStoragePathAndDelete(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// removeImageFiles mengembalikan jumlah file yang berhasil dihapus.
+// Kegagalan hanya dicatat karena record-nya sudah tidak ada.
+func (s *Server) removeImageFiles(ctx context.Context, paths []string) int {
+	removed := 0
+	for _, path := range paths {
+		if err := s.storage.Remove(ctx, path); err != nil {
+			logging.FromContext(ctx).Warn("failed to remove image file", "path", path, "error", err)
+			continue
+		}
+		removed++
+	}
+	return removed
+}
+
+// startPurgeJob menjalankan PurgeDeleted secara berkala bila retensi soft
+// delete diaktifkan.
+func (s *Server) startPurgeJob() {
+	period := s.cfg.Retention.SoftDeletePeriod
+	if period <= 0 {
+		return
+	}
+	s.workers.Go("soft delete purge", func(ctx context.Context) {
+		ticker := time.NewTicker(s.cfg.Retention.PurgeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				result, err := s.PurgeDeleted(ctx, time.Now().Add(-period))
+				if err != nil {
+					slog.Warn("soft delete purge failed", "error", err)
+					continue
+				}
+				if result.LostReports+result.Vehicles+result.Users+result.Cameras > 0 {
+					slog.Info("purged soft-deleted rows", "lost_reports", result.LostReports, "vehicles", result.Vehicles,
+						"users", result.Users, "cameras", result.Cameras, "images", result.Images)
+				}
+			}
+		}
+	})
+}
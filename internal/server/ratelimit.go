@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/middleware"
+	"github.com/jaga-project/jaga-backend/internal/ratelimit"
+)
+
+const rateLimitCleanupInterval = 10 * time.Minute
+
+// rateLimit mengembalikan middleware limiter untuk satu aturan, atau
+// middleware kosong bila rate limiting dimatikan.
+func (s *Server) rateLimit(rule string, limit ratelimit.Limit, key middleware.KeyFunc) func(http.Handler) http.Handler {
+	if s.limiter == nil {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return middleware.RateLimit(s.limiter, rule, limit, key, s.metrics)
+}
+
+// uploadQuota membatasi jumlah upload per user (atau per IP untuk endpoint
+// publik) di atas limit umum.
+func (s *Server) uploadQuota(h http.Handler) http.Handler {
+	return s.rateLimit("upload", s.cfg.RateLimit.Upload, middleware.KeyByUserOrIP(s.cfg.RateLimit.TrustProxyHeaders))(h)
+}
+
+// apiKeyLockout mengembalikan penguncian tebakan API key per IP, atau nil
+// bila rate limiting dimatikan. Kebijakannya sama dengan lockout login.
+func (s *Server) apiKeyLockout() *middleware.APIKeyLockout {
+	if s.limiter == nil {
+		return nil
+	}
+	return &middleware.APIKeyLockout{
+		Limiter:    s.limiter,
+		Policy:     s.cfg.RateLimit.Lockout,
+		TrustProxy: s.cfg.RateLimit.TrustProxyHeaders,
+	}
+}
+
+func loginLockoutKey(email string) string {
+	return "login:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// startRateLimitCleanup menghapus bucket dan catatan kegagalan login yang
+// sudah tidak relevan secara berkala.
+func (s *Server) startRateLimitCleanup() {
+	if s.limiter == nil {
+		return
+	}
+	retention := s.cfg.RateLimit.Lockout.ResetAfter
+	if retention < time.Hour {
+		retention = time.Hour
+	}
+	s.workers.Go("rate limit cleanup", func(ctx context.Context) {
+		ticker := time.NewTicker(rateLimitCleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.limiter.Store().Cleanup(ctx, time.Now().Add(-retention)); err != nil {
+					slog.Warn("rate limit cleanup failed", "error", err)
+				}
+			}
+		}
+	})
+}
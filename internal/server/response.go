@@ -4,11 +4,13 @@ import (
     "encoding/json"
    
     "net/http"
-   
+
+    "github.com/jaga-project/jaga-backend/internal/middleware"
 )
 
 type ErrorDetail struct {
-    Message string `json:"message"`
+    Message   string `json:"message"`
+    RequestID string `json:"request_id,omitempty"`
 }
 
 type JSONErrorResponse struct {
@@ -19,7 +21,8 @@ func writeJSONError(w http.ResponseWriter, message string, statusCode int) {
     w.Header().Set("Content-Type", "application/json; charset=utf-8")
     w.WriteHeader(statusCode)
     response := JSONErrorResponse{
-        Error: ErrorDetail{Message: message},
+        // Header diisi middleware.RequestID sebelum handler dipanggil.
+        Error: ErrorDetail{Message: message, RequestID: w.Header().Get(middleware.RequestIDHeader)},
     }
     json.NewEncoder(w).Encode(response)
 }
\ No newline at end of file
@@ -1,8 +1,12 @@
 package server
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -21,6 +25,7 @@ type CameraInfoResult struct {
 
 type SuspectInfo struct {
     SuspectID              int64            `json:"suspect_id"`
+    DetectedID             int64            `json:"detected_id"`
     PersonEvidenceImageURL *string          `json:"person_evidence_image_url,omitempty"` 
     MotorEvidenceImageURL  *string          `json:"motor_evidence_image_url,omitempty"`  
     TimestampDetected      time.Time        `json:"timestamp_detected"`
@@ -28,12 +33,56 @@ type SuspectInfo struct {
     MotorScore             float64          `json:"motor_score"`
     FinalScore             float64          `json:"final_score"`
     Camera                 CameraInfoResult `json:"camera"`
+    ReviewStatus           string           `json:"review_status"`
+    OwnerFeedback          *string          `json:"owner_feedback,omitempty"`
 }
 
+// AnalysisStatusNotStarted dipakai bila laporan belum punya analysis run;
+// selain itu analysis_status sama dengan status run terakhir (QUEUED,
+// RUNNING, COMPLETED atau FAILED).
+const AnalysisStatusNotStarted = "NOT_STARTED"
+
+const (
+    defaultResultPageSize = 20
+    maxResultPageSize     = 100
+)
+
 type ResultResponse struct {
-    LostReportID   int           `json:"lost_report_id"`
-    AnalysisStatus string        `json:"analysis_status"`
-    Suspects       []SuspectInfo `json:"suspects"`
+    LostReportID     int                   `json:"lost_report_id"`
+    LostReportStatus string                `json:"lost_report_status"`
+    AnalysisStatus   string                `json:"analysis_status"`
+    AnalysisRun      *database.AnalysisRun `json:"analysis_run,omitempty"`
+    Total            int                   `json:"total"`
+    Limit            int                   `json:"limit,omitempty"`
+    Offset           int                   `json:"offset"`
+    MinScore         float64               `json:"min_score"`
+    Suspects         []SuspectInfo         `json:"suspects"`
+}
+
+func parseResultFilter(q url.Values) (database.SuspectResultFilter, error) {
+    f := database.SuspectResultFilter{Limit: defaultResultPageSize}
+    if v := q.Get("limit"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 1 || n > maxResultPageSize {
+            return f, fmt.Errorf("limit must be between 1 and %d", maxResultPageSize)
+        }
+        f.Limit = n
+    }
+    if v := q.Get("offset"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 0 {
+            return f, fmt.Errorf("offset must be a non-negative integer")
+        }
+        f.Offset = n
+    }
+    if v := q.Get("min_score"); v != "" {
+        score, err := strconv.ParseFloat(v, 64)
+        if err != nil || score < 0 || score > 1 {
+            return f, fmt.Errorf("min_score must be a number between 0 and 1")
+        }
+        f.MinScore = score
+    }
+    return f, nil
 }
 
 func (s *Server) handleGetResultByLostReportID() http.HandlerFunc {
@@ -44,11 +93,16 @@ func (s *Server) handleGetResultByLostReportID() http.HandlerFunc {
             writeJSONError(w, "invalid lost_report_id: must be an integer", http.StatusBadRequest)
             return
         }
+        filter, err := parseResultFilter(r.URL.Query())
+        if err != nil {
+            writeJSONError(w, err.Error(), http.StatusBadRequest)
+            return
+        }
 
         requestingUserID, _ := r.Context().Value(middleware.UserIDContextKey).(string)
         isAdmin, _ := r.Context().Value(middleware.AdminStatusContextKey).(bool)
 
-        report, err := database.GetLostReportWithVehicleInfoByID(r.Context(), s.db.Get(), lostReportID)
+        report, err := s.repos.LostReports.GetWithVehicleInfoByID(r.Context(), lostReportID)
         if err != nil {
             if strings.Contains(err.Error(), "not found") {
                 writeJSONError(w, "Lost report not found", http.StatusNotFound)
@@ -63,61 +117,81 @@ func (s *Server) handleGetResultByLostReportID() http.HandlerFunc {
             return
         }
 
-        suspectsFromDB, err := database.GetSuspectsByLostReportID(r.Context(), s.db.Get(), lostReportID)
+        suspectsFromDB, err := s.repos.Suspects.ListResultsByLostReportID(r.Context(), lostReportID, filter)
         if err != nil {
             writeJSONError(w, "Failed to retrieve analysis results: "+err.Error(), http.StatusInternalServerError)
             return
         }
-
-        response := ResultResponse{
-            LostReportID: lostReportID,
-            Suspects:     []SuspectInfo{},
+        total, err := s.repos.Suspects.CountResultsByLostReportID(r.Context(), lostReportID, filter)
+        if err != nil {
+            writeJSONError(w, "Failed to count analysis results: "+err.Error(), http.StatusInternalServerError)
+            return
         }
 
-        if len(suspectsFromDB) == 0 {
-            response.AnalysisStatus = "Processing or No Suspects Found"
-        } else {
-            response.AnalysisStatus = "Completed"
-
-            groupedSuspects := make(map[int64]*SuspectInfo)
-
-            for _, dbSuspect := range suspectsFromDB {
-                if _, ok := groupedSuspects[dbSuspect.SuspectID]; !ok {
-                    groupedSuspects[dbSuspect.SuspectID] = &SuspectInfo{
-                        SuspectID:         dbSuspect.SuspectID,
-                        TimestampDetected: dbSuspect.DetectedTimestamp,
-                        PersonScore:       dbSuspect.PersonScore,
-                        MotorScore:        dbSuspect.MotorScore,
-                        FinalScore:        dbSuspect.FinalScore,
-                        Camera: CameraInfoResult{
-                            CameraID:  dbSuspect.CameraID,
-                            Name:      dbSuspect.CameraName,
-                            Latitude:  dbSuspect.CameraLatitude,
-                            Longitude: dbSuspect.CameraLongitude,
-                        },
-                    }
-                }
-
-                if dbSuspect.EvidenceImagePath.Valid && dbSuspect.EvidenceImagePath.String != "" {
-                    url := "/" + strings.TrimPrefix(dbSuspect.EvidenceImagePath.String, "/")
-                    if strings.Contains(dbSuspect.EvidenceImagePath.String, "person_") {
-                        groupedSuspects[dbSuspect.SuspectID].PersonEvidenceImageURL = &url
-                    } else if strings.Contains(dbSuspect.EvidenceImagePath.String, "motor_") {
-                        groupedSuspects[dbSuspect.SuspectID].MotorEvidenceImageURL = &url
-                    }
-                }
-            }
-
-            for _, suspectInfo := range groupedSuspects {
-                response.Suspects = append(response.Suspects, *suspectInfo)
-            }
+        run, err := s.repos.AnalysisRuns.GetLatestByLostReportID(r.Context(), lostReportID)
+        if err != nil && !errors.Is(err, database.ErrAnalysisRunNotFound) {
+            writeJSONError(w, "Failed to get analysis run: "+err.Error(), http.StatusInternalServerError)
+            return
         }
 
+        response := toResultResponse(lostReportID, report.Status, run, suspectsFromDB, total, filter)
+
         w.Header().Set("Content-Type", "application/json")
         json.NewEncoder(w).Encode(response)
     }
 }
 
+// toResultResponse mempertahankan urutan baris dari repository (skor
+// tertinggi lebih dulu). run nil berarti laporan belum pernah dianalisis.
+// Dipakai juga oleh ekspor data user.
+func toResultResponse(lostReportID int, lostReportStatus string, run *database.AnalysisRun, suspectsFromDB []database.SuspectResult, total int, filter database.SuspectResultFilter) ResultResponse {
+        response := ResultResponse{
+            LostReportID:     lostReportID,
+            LostReportStatus: lostReportStatus,
+            AnalysisStatus:   AnalysisStatusNotStarted,
+            AnalysisRun:      run,
+            Total:            total,
+            Limit:            filter.Limit,
+            Offset:           filter.Offset,
+            MinScore:         filter.MinScore,
+            Suspects:         make([]SuspectInfo, 0, len(suspectsFromDB)),
+        }
+
+        if run != nil {
+            response.AnalysisStatus = run.Status
+        }
+
+        imageURL := func(path sql.NullString) *string {
+            if !path.Valid || path.String == "" {
+                return nil
+            }
+            imageURL := "/" + strings.TrimPrefix(path.String, "/")
+            return &imageURL
+        }
+        for _, dbSuspect := range suspectsFromDB {
+            response.Suspects = append(response.Suspects, SuspectInfo{
+                SuspectID:              dbSuspect.SuspectID,
+                DetectedID:             dbSuspect.DetectedID,
+                PersonEvidenceImageURL: imageURL(dbSuspect.PersonImagePath),
+                MotorEvidenceImageURL:  imageURL(dbSuspect.MotorImagePath),
+                TimestampDetected:      dbSuspect.DetectedTimestamp,
+                PersonScore:            dbSuspect.PersonScore,
+                MotorScore:             dbSuspect.MotorScore,
+                FinalScore:             dbSuspect.FinalScore,
+                Camera: CameraInfoResult{
+                    CameraID:  dbSuspect.CameraID,
+                    Name:      dbSuspect.CameraName,
+                    Latitude:  dbSuspect.CameraLatitude,
+                    Longitude: dbSuspect.CameraLongitude,
+                },
+                ReviewStatus:  dbSuspect.ReviewStatus,
+                OwnerFeedback: dbSuspect.OwnerFeedback,
+            })
+        }
+        return response
+}
+
 func (s *Server) RegisterResultRoutes(r *mux.Router) {
     r.HandleFunc("/results/{id:[0-9]+}", s.handleGetResultByLostReportID()).Methods("GET")
-}
\ No newline at end of file
+    r.HandleFunc("/results/{id:[0-9]+}/trajectory", s.handleGetTrajectory()).Methods("GET")
+}
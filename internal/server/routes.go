@@ -10,6 +10,8 @@ import (
 
 func (s *Server) RegisterRoutes() http.Handler {
 	mainRouter := mux.NewRouter()
+	mainRouter.Use(middleware.RouteTemplate)
+	mainRouter.Use(s.rateLimit("ip", s.cfg.RateLimit.PerIP, middleware.KeyByIP(s.cfg.RateLimit.TrustProxyHeaders)))
 
 	mainRouter.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json") 
@@ -23,36 +25,56 @@ func (s *Server) RegisterRoutes() http.Handler {
 	}).Methods("GET")
 
 	userPublicRouter := mainRouter.PathPrefix("/users").Subrouter()
-	userPublicRouter.HandleFunc("", s.handleCreateUser()).Methods("POST")
+	userPublicRouter.Handle("", s.uploadQuota(s.handleCreateUser())).Methods("POST")
 
-	fs := http.FileServer(http.Dir("./uploads/"))
+	fs := http.FileServer(http.Dir(s.cfg.Storage.UploadDir))
 	mainRouter.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", fs))
 
+	s.RegisterHealthRoutes(mainRouter)
+	mainRouter.Handle("/metrics", s.metrics.Handler()).Methods("GET")
 	s.RegisterAuthRoutes(mainRouter)
+	s.RegisterAccountRoutes(mainRouter)
+	s.RegisterMFARoutes(mainRouter)
 
 	publicApiRouter := mainRouter.PathPrefix("/api").Subrouter()
 	s.RegisterPublicCameraRoutes(publicApiRouter)
 
 	apiRouter := mainRouter.PathPrefix("/api").Subrouter()
-	apiRouter.Use(middleware.UnifiedAuthMiddleware(s.db.Get()))
+	apiRouter.Use(middleware.UnifiedAuthMiddleware(s.tokens, s.repos.Users, s.repos.Admins, s.apiKeyLockout(), s.metrics))
+	apiRouter.Use(s.rateLimit("api_key", s.cfg.RateLimit.PerAPIKey, middleware.KeyByAPIKey))
+	apiRouter.Use(s.rateLimit("user", s.cfg.RateLimit.PerUser, middleware.KeyByUser))
+	apiRouter.Use(s.auditAdminRequests)
 
 	adminOnlyMiddleware := middleware.AdminOnlyMiddleware()
 
 	adminRouter := apiRouter.PathPrefix("/admins").Subrouter()
 	adminRouter.Use(adminOnlyMiddleware)
+	s.RegisterAdminMFARoutes(adminRouter)
+	s.RegisterAuditRoutes(adminRouter)
+	s.RegisterAdminPrivacyRoutes(adminRouter)
+	s.RegisterAdminStatsRoutes(adminRouter)
+	s.RegisterAdminSuspectRoutes(adminRouter)
+	s.RegisterAdminAnalysisRoutes(adminRouter)
+	s.RegisterAdminSearchRoutes(adminRouter)
+	s.RegisterAdminDetectionRoutes(adminRouter)
+	s.RegisterAdminVehicleRoutes(adminRouter)
+	s.RegisterAdminVerificationRoutes(adminRouter)
 	s.RegisterAdminRoutes(adminRouter)
 
 
 	s.RegisterUserProtectedRoutes(apiRouter)
+	s.RegisterAccountProtectedRoutes(apiRouter)
+	s.RegisterPrivacyRoutes(apiRouter)
 	s.RegisterVehicleRoutes(apiRouter)
 	s.RegisterDetectedRoutes(apiRouter)
 	s.RegisterProtectedCameraRoutes(apiRouter)
 	s.RegisterLostReportRoutes(apiRouter)
+	s.RegisterSearchRoutes(apiRouter)
 	s.RegisterSuspectRoutes(apiRouter)
 	s.RegisterImageRoutes(apiRouter)
 	s.RegisterResultRoutes(apiRouter)
 
-	return mainRouter
+	return middleware.RequestID(middleware.Tracing(middleware.AccessLog(s.metrics.ObserveRequest)(mainRouter)))
 }
 
 
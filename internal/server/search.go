@@ -0,0 +1,508 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/geo"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+	"github.com/jaga-project/jaga-backend/internal/middleware"
+)
+
+// SearchParamsResponse adalah parameter pencarian yang berlaku untuk
+// laporan. Custom bernilai false bila laporan masih memakai default.
+type SearchParamsResponse struct {
+	LostID int `json:"lost_id"`
+	database.SearchParams
+	Custom    bool                    `json:"custom"`
+	UpdatedBy *string                 `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time              `json:"updated_at,omitempty"`
+	Limits    database.AnalysisLimits `json:"limits"`
+}
+
+// effectiveSearch mengembalikan parameter tersimpan laporan, atau default
+// konfigurasi yang dijepit ke batas admin.
+func (s *Server) effectiveSearch(ctx context.Context, repos database.Repositories, lostID int) (*SearchParamsResponse, error) {
+	limits, err := repos.Search.GetLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &SearchParamsResponse{LostID: lostID, Limits: *limits}
+	saved, err := repos.Search.GetByLostReportID(ctx, lostID)
+	switch {
+	case err == nil:
+		resp.SearchParams = saved.SearchParams
+		resp.Custom = true
+		resp.UpdatedBy = saved.UpdatedBy
+		resp.UpdatedAt = &saved.UpdatedAt
+	case errors.Is(err, database.ErrSearchParamsNotFound):
+		resp.RadiusKm = math.Min(s.cfg.Analysis.DefaultRadiusKm, limits.MaxRadiusKm)
+		resp.WindowHours = min(s.cfg.Analysis.DefaultWindowHours, limits.MaxWindowHours)
+	default:
+		return nil, err
+	}
+	if resp.CameraIDs == nil {
+		resp.CameraIDs = []int64{}
+	}
+	if resp.Zones == nil {
+		resp.Zones = []string{}
+	}
+	return resp, nil
+}
+
+// normalizeSearchParams mengurutkan dan menghapus duplikat kamera dan zona
+// agar parameter yang sama selalu tersimpan dengan bentuk yang sama.
+func normalizeSearchParams(p *database.SearchParams) {
+	seenCam := make(map[int64]bool)
+	cameraIDs := []int64{}
+	for _, id := range p.CameraIDs {
+		if !seenCam[id] {
+			seenCam[id] = true
+			cameraIDs = append(cameraIDs, id)
+		}
+	}
+	sort.Slice(cameraIDs, func(i, j int) bool { return cameraIDs[i] < cameraIDs[j] })
+	seenZone := make(map[string]bool)
+	zones := []string{}
+	for _, z := range p.Zones {
+		z = strings.TrimSpace(z)
+		if z != "" && !seenZone[z] {
+			seenZone[z] = true
+			zones = append(zones, z)
+		}
+	}
+	sort.Strings(zones)
+	p.CameraIDs, p.Zones = cameraIDs, zones
+}
+
+// validateSearchParams memeriksa batas admin, memastikan kamera serta zona
+// yang dipilih memang ada, lalu mengembalikan cakupan kamera laporan.
+// Jumlah kamera dihitung dari cakupan akhir sehingga zona yang luas tidak
+// bisa melewati MaxCameras.
+func validateSearchParams(limits *database.AnalysisLimits, cameras []database.Camera, report *database.LostReport, p database.SearchParams) ([]SearchCamera, error) {
+	if err := limits.Validate(p); err != nil {
+		return nil, err
+	}
+	known := make(map[int64]bool, len(cameras))
+	zones := make(map[string]bool)
+	for _, c := range cameras {
+		known[c.CameraID] = true
+		zones[c.Zone] = true
+	}
+	for _, id := range p.CameraIDs {
+		if !known[id] {
+			return nil, fmt.Errorf("camera %d not found", id)
+		}
+	}
+	for _, z := range p.Zones {
+		if !zones[z] {
+			return nil, fmt.Errorf("zone %q has no cameras", z)
+		}
+	}
+	scope := scopeCameras(cameras, report.Latitude, report.Longitude, p)
+	if len(scope) > limits.MaxCameras {
+		return nil, fmt.Errorf("search covers %d cameras; at most %d cameras can be searched, narrow the radius or zones", len(scope), limits.MaxCameras)
+	}
+	return scope, nil
+}
+
+// SearchCamera adalah kamera yang masuk cakupan pencarian.
+type SearchCamera struct {
+	CameraID   int64    `json:"camera_id"`
+	Name       string   `json:"name"`
+	Zone       string   `json:"zone"`
+	DistanceKm *float64 `json:"distance_km,omitempty"`
+	Detections int      `json:"detections"`
+}
+
+// scopeCameras memilih kamera aktif yang diperiksa untuk laporan. Kamera
+// yang dipilih lewat camera_ids selalu ikut; kamera dari zona yang dipilih
+// ikut bila berada dalam radius dari lokasi laporan (atau semuanya bila
+// laporan tanpa lokasi). Tanpa pilihan kamera atau zona, kamera dipilih
+// berdasarkan jarak dari lokasi laporan. Laporan tanpa lokasi dan tanpa
+// pilihan kamera tidak punya cakupan.
+func scopeCameras(cameras []database.Camera, lat, lon *float64, p database.SearchParams) []SearchCamera {
+	selected := make(map[int64]bool, len(p.CameraIDs))
+	for _, id := range p.CameraIDs {
+		selected[id] = true
+	}
+	zones := make(map[string]bool, len(p.Zones))
+	for _, z := range p.Zones {
+		zones[z] = true
+	}
+	var scope []SearchCamera
+	for _, c := range cameras {
+		if !c.IsActive {
+			continue
+		}
+		sc := SearchCamera{CameraID: c.CameraID, Name: c.Name, Zone: c.Zone}
+		if lat != nil && lon != nil {
+			d := geo.HaversineKm(*lat, *lon, c.Latitude, c.Longitude)
+			sc.DistanceKm = &d
+		}
+		inRadius := sc.DistanceKm != nil && *sc.DistanceKm <= p.RadiusKm
+		switch {
+		case selected[c.CameraID]:
+		case zones[c.Zone]:
+			if sc.DistanceKm != nil && !inRadius {
+				continue
+			}
+		case p.Restricted() || !inRadius:
+			continue
+		}
+		scope = append(scope, sc)
+	}
+	sort.Slice(scope, func(i, j int) bool { return scope[i].CameraID < scope[j].CameraID })
+	return scope
+}
+
+func searchCameraIDs(scope []SearchCamera) []int64 {
+	ids := make([]int64, 0, len(scope))
+	for _, c := range scope {
+		ids = append(ids, c.CameraID)
+	}
+	return ids
+}
+
+// loadOwnLostReport memuat laporan dan memastikan peminta adalah pemilik
+// atau admin. Response error sudah ditulis bila ok=false.
+func loadOwnLostReport(w http.ResponseWriter, r *http.Request, repos database.Repositories) (*database.LostReport, bool) {
+	lostID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSONError(w, "invalid lost_report_id: must be an integer", http.StatusBadRequest)
+		return nil, false
+	}
+	report, err := repos.LostReports.GetByID(r.Context(), lostID)
+	if err != nil {
+		if errors.Is(err, database.ErrLostReportNotFound) {
+			writeJSONError(w, "Lost report not found", http.StatusNotFound)
+		} else {
+			writeJSONError(w, "Failed to get lost report: "+err.Error(), http.StatusInternalServerError)
+		}
+		return nil, false
+	}
+	requestingUserID, _ := r.Context().Value(middleware.UserIDContextKey).(string)
+	isAdmin, _ := r.Context().Value(middleware.AdminStatusContextKey).(bool)
+	if !isAdmin && report.UserID != requestingUserID {
+		writeJSONError(w, "Forbidden: You can only manage search parameters of your own reports.", http.StatusForbidden)
+		return nil, false
+	}
+	return report, true
+}
+
+func (s *Server) handleGetSearchParams() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report, ok := loadOwnLostReport(w, r, s.repos)
+		if !ok {
+			return
+		}
+		resp, err := s.effectiveSearch(r.Context(), s.repos, report.LostID)
+		if err != nil {
+			writeJSONError(w, "Failed to get search parameters: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+type updateSearchParamsRequest struct {
+	RadiusKm    *float64  `json:"search_radius_km"`
+	WindowHours *int      `json:"search_window_hours"`
+	CameraIDs   *[]int64  `json:"camera_ids"`
+	Zones       *[]string `json:"zones"`
+}
+
+// handleUpdateSearchParams menyimpan parameter pencarian laporan. Field yang
+// tidak diisi tetap memakai nilai yang berlaku. Run yang masih antre ikut
+// diperbarui; run yang sedang berjalan tidak diubah.
+func (s *Server) handleUpdateSearchParams() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		var req updateSearchParamsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		requestingUserID, _ := ctx.Value(middleware.UserIDContextKey).(string)
+
+		tx, err := s.store.BeginTx(ctx)
+		if err != nil {
+			writeJSONError(w, "Failed to start transaction", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		repos := tx.Repos()
+
+		report, ok := loadOwnLostReport(w, r, repos)
+		if !ok {
+			return
+		}
+		current, err := s.effectiveSearch(ctx, repos, report.LostID)
+		if err != nil {
+			writeJSONError(w, "Failed to get search parameters: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		params := current.SearchParams
+		if req.RadiusKm != nil {
+			params.RadiusKm = *req.RadiusKm
+		}
+		if req.WindowHours != nil {
+			params.WindowHours = *req.WindowHours
+		}
+		if req.CameraIDs != nil {
+			params.CameraIDs = *req.CameraIDs
+		}
+		if req.Zones != nil {
+			params.Zones = *req.Zones
+		}
+		normalizeSearchParams(&params)
+		cameras, err := repos.Cameras.List(ctx)
+		if err != nil {
+			writeJSONError(w, "Failed to list cameras: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if _, err := validateSearchParams(&current.Limits, cameras, report, params); err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		saved := database.LostReportSearch{LostID: report.LostID, SearchParams: params, UpdatedBy: &requestingUserID, UpdatedAt: time.Now()}
+		if err := repos.Search.Save(ctx, &saved); err != nil {
+			writeJSONError(w, "Failed to save search parameters: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		latest, err := repos.AnalysisRuns.GetLatestByLostReportID(ctx, report.LostID)
+		switch {
+		case err == nil && latest.Status == database.AnalysisRunQueued:
+			if _, err := repos.AnalysisRuns.UpdateQueuedScope(ctx, latest.RunID, params); err != nil {
+				writeJSONError(w, "Failed to update queued analysis run: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		case err != nil && !errors.Is(err, database.ErrAnalysisRunNotFound):
+			writeJSONError(w, "Failed to get analysis run: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := s.audit(r, repos, auditChange{Action: "lost_report.search_update", EntityType: "lost_report", EntityID: report.LostID, Before: current.SearchParams, After: params}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit transaction", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(SearchParamsResponse{
+			LostID:       report.LostID,
+			SearchParams: params,
+			Custom:       true,
+			UpdatedBy:    saved.UpdatedBy,
+			UpdatedAt:    &saved.UpdatedAt,
+			Limits:       current.Limits,
+		})
+	}
+}
+
+// SearchPreview memperkirakan cakupan pencarian sebelum parameter disimpan.
+type SearchPreview struct {
+	LostID         int                   `json:"lost_id"`
+	Params         database.SearchParams `json:"params"`
+	WindowStart    time.Time             `json:"window_start"`
+	WindowEnd      time.Time             `json:"window_end"`
+	CameraCount    int                   `json:"camera_count"`
+	DetectionCount int                   `json:"detection_count"`
+	Cameras        []SearchCamera        `json:"cameras"`
+	Warnings       []string              `json:"warnings,omitempty"`
+}
+
+// parseSearchOverrides menimpa parameter dengan query string preview.
+// camera_ids dan zones dipisahkan koma; nilai kosong menghapus pilihan.
+func parseSearchOverrides(q url.Values, p *database.SearchParams) error {
+	if v := q.Get("search_radius_km"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return errors.New("search_radius_km must be a number")
+		}
+		p.RadiusKm = f
+	}
+	if v := q.Get("search_window_hours"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return errors.New("search_window_hours must be an integer")
+		}
+		p.WindowHours = n
+	}
+	if q.Has("camera_ids") {
+		p.CameraIDs = nil
+		for _, part := range strings.Split(q.Get("camera_ids"), ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			id, err := strconv.ParseInt(part, 10, 64)
+			if err != nil {
+				return errors.New("camera_ids must be a comma-separated list of integers")
+			}
+			p.CameraIDs = append(p.CameraIDs, id)
+		}
+	}
+	if q.Has("zones") {
+		p.Zones = strings.Split(q.Get("zones"), ",")
+	}
+	return nil
+}
+
+func (s *Server) handlePreviewSearch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		report, ok := loadOwnLostReport(w, r, s.repos)
+		if !ok {
+			return
+		}
+		current, err := s.effectiveSearch(ctx, s.repos, report.LostID)
+		if err != nil {
+			writeJSONError(w, "Failed to get search parameters: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		params := current.SearchParams
+		if err := parseSearchOverrides(r.URL.Query(), &params); err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		normalizeSearchParams(&params)
+		cameras, err := s.repos.Cameras.List(ctx)
+		if err != nil {
+			writeJSONError(w, "Failed to list cameras: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		scope, err := validateSearchParams(&current.Limits, cameras, report, params)
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		preview := SearchPreview{LostID: report.LostID, Params: params, Cameras: scope}
+		preview.WindowStart, preview.WindowEnd = params.Window(report.Timestamp)
+		counts, err := s.repos.Search.CountDetectionsByCamera(ctx, searchCameraIDs(preview.Cameras), preview.WindowStart, preview.WindowEnd)
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to count detections for search preview", "lost_id", report.LostID, "error", err)
+			writeJSONError(w, "Failed to count detections: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for i := range preview.Cameras {
+			preview.Cameras[i].Detections = counts[preview.Cameras[i].CameraID]
+			preview.DetectionCount += preview.Cameras[i].Detections
+		}
+		preview.CameraCount = len(preview.Cameras)
+		if preview.Cameras == nil {
+			preview.Cameras = []SearchCamera{}
+		}
+
+		if !params.Restricted() && report.Latitude == nil {
+			preview.Warnings = append(preview.Warnings, "lost report has no location; select cameras or zones to search")
+		}
+		for _, c := range cameras {
+			for _, id := range params.CameraIDs {
+				if c.CameraID == id && !c.IsActive {
+					preview.Warnings = append(preview.Warnings, fmt.Sprintf("camera %d is inactive and will be skipped", id))
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(preview)
+	}
+}
+
+func (s *Server) handleGetAnalysisLimits() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limits, err := s.repos.Search.GetLimits(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to load analysis limits: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(limits)
+	}
+}
+
+type analysisLimitsRequest struct {
+	MaxRadiusKm    *float64 `json:"max_radius_km"`
+	MaxWindowHours *int     `json:"max_window_hours"`
+	MaxCameras     *int     `json:"max_cameras"`
+}
+
+// handleUpdateAnalysisLimits mengganti batas parameter pencarian. Parameter
+// yang sudah tersimpan tidak diubah; batas baru berlaku saat parameter
+// berikutnya disimpan.
+func (s *Server) handleUpdateAnalysisLimits() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req analysisLimitsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		switch {
+		case req.MaxRadiusKm == nil || *req.MaxRadiusKm < database.MinSearchRadiusKm:
+			writeJSONError(w, fmt.Sprintf("max_radius_km must be at least %v", database.MinSearchRadiusKm), http.StatusBadRequest)
+			return
+		case req.MaxWindowHours == nil || *req.MaxWindowHours < 1:
+			writeJSONError(w, "max_window_hours must be a positive integer", http.StatusBadRequest)
+			return
+		case req.MaxCameras == nil || *req.MaxCameras < 1:
+			writeJSONError(w, "max_cameras must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		requesterID, _ := r.Context().Value(middleware.UserIDContextKey).(string)
+		limits := database.AnalysisLimits{MaxRadiusKm: *req.MaxRadiusKm, MaxWindowHours: *req.MaxWindowHours, MaxCameras: *req.MaxCameras, UpdatedBy: &requesterID}
+
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		previous, err := tx.Repos().Search.GetLimits(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to load analysis limits: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Repos().Search.UpdateLimits(r.Context(), &limits); err != nil {
+			writeJSONError(w, "Failed to update analysis limits: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := s.audit(r, tx.Repos(), auditChange{Action: "analysis_limits.update", EntityType: "analysis_limits", EntityID: "default", Before: previous, After: limits}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit database transaction: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(limits)
+	}
+}
+
+func (s *Server) RegisterSearchRoutes(r *mux.Router) {
+	r.HandleFunc("/lost_reports/{id:[0-9]+}/search", s.handleGetSearchParams()).Methods("GET")
+	r.HandleFunc("/lost_reports/{id:[0-9]+}/search", s.handleUpdateSearchParams()).Methods("PUT")
+	r.HandleFunc("/lost_reports/{id:[0-9]+}/search/preview", s.handlePreviewSearch()).Methods("GET")
+}
+
+// RegisterAdminSearchRoutes harus didaftarkan sebelum RegisterAdminRoutes.
+func (s *Server) RegisterAdminSearchRoutes(r *mux.Router) {
+	r.Handle("/analysis-limits", s.handleGetAnalysisLimits()).Methods("GET")
+	r.Handle("/analysis-limits", s.handleUpdateAnalysisLimits()).Methods("PUT")
+}
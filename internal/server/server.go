@@ -1,45 +1,113 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
-	"strconv"
+	"path/filepath"
+	"sync/atomic"
 	"time"
-	"strings"
 
 	"github.com/gorilla/handlers" 
+	"github.com/jaga-project/jaga-backend/internal/auth"
+	"github.com/jaga-project/jaga-backend/internal/config"
 	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/fieldcrypt"
+	"github.com/jaga-project/jaga-backend/internal/lifecycle"
+	"github.com/jaga-project/jaga-backend/internal/mail"
+	"github.com/jaga-project/jaga-backend/internal/metrics"
+	"github.com/jaga-project/jaga-backend/internal/ocr"
+	"github.com/jaga-project/jaga-backend/internal/ratelimit"
+	"github.com/jaga-project/jaga-backend/internal/storage"
 
-	_ "github.com/joho/godotenv/autoload"
 	_ "github.com/lib/pq"
 )
 
 type Server struct {
-	port int
-	db   database.Service
+	cfg     *config.Config
+	port    int
+	db      database.Service
+	store   database.Store
+	repos   database.Repositories
+	tokens  *auth.Manager
+	storage storage.Storage
+	workers *lifecycle.Group
+	metrics *metrics.Metrics
+	limiter *ratelimit.Limiter
+
+	mailer    mail.Sender
+	ocr       ocr.Engine
+	passwords *auth.PasswordPolicy
+	fields    *fieldcrypt.KeyRing
+
+	httpServer   *http.Server
+	shuttingDown atomic.Bool
 }
 
-func NewServer() *http.Server {
-	portStr := os.Getenv("PORT")
-	port, err := strconv.Atoi(portStr)
-	if err != nil || port == 0 {
-		log.Fatalf("PORT environment variable is not set or invalid: got '%s'", portStr)
+// New membuat Server di atas store yang diberikan. NewServer memakai store
+// Postgres; test handler dapat memakai memory.NewStore(). Store dibungkus
+// WithFieldEncryption, sehingga cfg.Encryption harus sudah lolos Validate.
+func New(cfg *config.Config, store database.Store) *Server {
+	fields, err := cfg.Encryption.KeyRing()
+	if err != nil {
+		panic(fmt.Sprintf("server: invalid encryption config: %v", err))
 	}
+	store = database.WithFieldEncryption(store, fields)
+	s := &Server{
+		cfg:     cfg,
+		port:    cfg.Server.Port,
+		store:   store,
+		repos:   store.Repos(),
+		tokens:  auth.NewManager(cfg.Auth.JWTSecret, cfg.Auth.TokenTTL),
+		storage: storage.Traced(storage.NewLocal(imageDir(cfg))),
+		workers: lifecycle.NewGroup(),
+		metrics: metrics.New(),
 
-	newServer := &Server{
-		port: port,
-		db:   database.New(),
+		mailer:    mail.New(cfg.Mail),
+		ocr:       ocr.New(cfg.OCR),
+		passwords: auth.NewPasswordPolicy(cfg.Auth.PasswordMinLength),
+		fields:    fields,
 	}
+	s.metrics.RegisterBusiness(metricsSource{repos: s.repos})
+	if cfg.RateLimit.Enabled {
+		s.limiter = ratelimit.NewLimiter(ratelimit.NewMemoryStore())
+	}
+	return s
+}
 
-	corsOriginsStr := os.Getenv("CORS_ALLOWED_ORIGINS")
-    if corsOriginsStr == "" {
-        corsOriginsStr = "http://localhost:3000"
-        log.Printf("WARN: CORS_ALLOWED_ORIGINS environment variable not set. Defaulting to '%s'", corsOriginsStr)
-    }
-    allowedOriginsList := strings.Split(corsOriginsStr, ",")
-    log.Printf("INFO: Configuring CORS with allowed origins: %v", allowedOriginsList)
+// NewServer menyiapkan server produksi dari config yang sudah divalidasi.
+// Migrasi database yang belum dijalankan diterapkan lebih dulu.
+func NewServer(cfg *config.Config) (*Server, error) {
+	db, err := database.New(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	migrateCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	err = database.Migrate(migrateCtx, db.Get())
+	cancel()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate database: %w", err)
+	}
+	newServer := New(cfg, database.NewPostgresStore(db.Get()))
+	newServer.db = db
+	if cfg.Auth.BreachedPasswordsFile != "" {
+		if err := newServer.passwords.LoadFile(cfg.Auth.BreachedPasswordsFile); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	newServer.metrics.RegisterDB(db.Get())
+	if cfg.RateLimit.Enabled && cfg.RateLimit.Store == "postgres" {
+		newServer.limiter = ratelimit.NewLimiter(ratelimit.NewPostgresStore(db.Get()))
+	}
+	newServer.startRateLimitCleanup()
+	newServer.startPurgeJob()
+
+	allowedOriginsList := cfg.Server.CORSAllowedOrigins
+    slog.Info("configuring CORS", "allowed_origins", allowedOriginsList)
 
     allowedOrigins := handlers.AllowedOrigins(allowedOriginsList)
     allowedMethods := handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"})
@@ -47,15 +115,68 @@ func NewServer() *http.Server {
     allowCredentials := handlers.AllowCredentials()
 	mainHandler := newServer.RegisterRoutes()
 
-	server := &http.Server{
+	newServer.httpServer = &http.Server{
 		Addr:         fmt.Sprintf(":%d", newServer.port),
 		Handler:      handlers.CORS(allowedOrigins, allowedMethods, allowedHeaders, allowCredentials)(mainHandler),
-		IdleTimeout:  time.Minute,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 30 * time.Second,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
 	}
 
-	return server
+	return newServer, nil
+}
+
+// SetMailer mengganti pengirim email, mis. dengan mail.Recorder di test.
+func (s *Server) SetMailer(m mail.Sender) {
+	s.mailer = m
+}
+
+// imageDir adalah direktori penyimpanan gambar di bawah root upload.
+func imageDir(cfg *config.Config) string {
+	return filepath.Join(cfg.Storage.UploadDir, "images")
+}
+
+func (s *Server) Addr() string {
+	return s.httpServer.Addr
+}
+
+// Run melayani HTTP sampai ctx dibatalkan (mis. SIGTERM), lalu berhenti
+// menerima koneksi baru, menunggu request yang sedang berjalan, menghentikan
+// pekerjaan latar belakang dan menutup koneksi database.
+func (s *Server) Run(ctx context.Context) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutdown signal received, draining in-flight requests", "timeout", s.cfg.Server.ShutdownTimeout)
+	s.shuttingDown.Store(true)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	var shutdownErr error
+	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+		shutdownErr = fmt.Errorf("http server shutdown: %w", err)
+	}
+	if err := s.workers.Shutdown(shutdownCtx); err != nil && shutdownErr == nil {
+		shutdownErr = fmt.Errorf("background workers shutdown: %w", err)
+	}
+	if s.db != nil {
+		if err := s.db.Close(); err != nil && shutdownErr == nil {
+			shutdownErr = fmt.Errorf("database close: %w", err)
+		}
+	}
+	return shutdownErr
 }
 
 
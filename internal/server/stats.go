@@ -0,0 +1,124 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+)
+
+const (
+	defaultStatsRange        = 30 * 24 * time.Hour
+	maxStatsRange            = 366 * 24 * time.Hour
+	defaultStatsTopLocations = 10
+	maxStatsTopLocations     = 100
+)
+
+// StatsResponse adalah hasil GET /api/admins/stats beserta filter yang
+// dipakai.
+type StatsResponse struct {
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Zone   string    `json:"zone,omitempty"`
+	Bucket string    `json:"bucket"`
+	*database.DashboardStats
+}
+
+// parseStatsFilter membaca from/to, zone, bucket (day atau week) dan top.
+func parseStatsFilter(q url.Values, now time.Time) (database.StatsFilter, error) {
+	f := database.StatsFilter{
+		Zone:         q.Get("zone"),
+		Bucket:       database.StatsBucketDay,
+		TopLocations: defaultStatsTopLocations,
+	}
+	var err error
+	if f.From, f.To, err = parseStatsRange(q, now, defaultStatsRange); err != nil {
+		return f, err
+	}
+	if v := q.Get("bucket"); v != "" {
+		if v != database.StatsBucketDay && v != database.StatsBucketWeek {
+			return f, fmt.Errorf("bucket must be day or week")
+		}
+		f.Bucket = v
+	}
+	if v := q.Get("top"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxStatsTopLocations {
+			return f, fmt.Errorf("top must be between 1 and %d", maxStatsTopLocations)
+		}
+		f.TopLocations = n
+	}
+	return f, nil
+}
+
+// parseStatsRange membaca from/to (RFC3339 atau YYYY-MM-DD; tanggal to ikut
+// dihitung). Tanpa from, rentangnya defaultRange sebelum to.
+func parseStatsRange(q url.Values, now time.Time, defaultRange time.Duration) (from, to time.Time, err error) {
+	to = now
+	if v := q.Get("to"); v != "" {
+		if to, err = parseStatsTime(v, true); err != nil {
+			return from, to, fmt.Errorf("invalid to, expected RFC3339 timestamp or YYYY-MM-DD")
+		}
+	}
+	from = to.Add(-defaultRange)
+	if v := q.Get("from"); v != "" {
+		if from, err = parseStatsTime(v, false); err != nil {
+			return from, to, fmt.Errorf("invalid from, expected RFC3339 timestamp or YYYY-MM-DD")
+		}
+	}
+	if !from.Before(to) {
+		return from, to, fmt.Errorf("from must be before to")
+	}
+	if to.Sub(from) > maxStatsRange {
+		return from, to, fmt.Errorf("date range must not exceed %d days", int(maxStatsRange.Hours()/24))
+	}
+	return from, to, nil
+}
+
+// parseStatsTime menerima tanggal tanpa jam (UTC); untuk batas akhir,
+// tanggal tersebut ikut dihitung sehingga batasnya adalah awal hari
+// berikutnya.
+func parseStatsTime(v string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func (s *Server) handleGetStats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f, err := parseStatsFilter(r.URL.Query(), time.Now())
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		stats, err := s.repos.Stats.Dashboard(r.Context(), f)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to compute dashboard stats", "error", err)
+			writeJSONError(w, "Failed to compute statistics: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(StatsResponse{From: f.From, To: f.To, Zone: f.Zone, Bucket: f.Bucket, DashboardStats: stats})
+	}
+}
+
+// RegisterAdminStatsRoutes harus didaftarkan sebelum RegisterAdminRoutes
+// agar /stats tidak tertangkap route /{user_id}.
+func (s *Server) RegisterAdminStatsRoutes(r *mux.Router) {
+	r.Handle("/stats", s.handleGetStats()).Methods("GET")
+	r.Handle("/stats/hotspots", s.handleGetHotspots()).Methods("GET")
+}
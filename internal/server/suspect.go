@@ -2,15 +2,15 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
 	"time"
-	"sync"
 
 	"github.com/gorilla/mux"
 	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/logging"
 	"github.com/jaga-project/jaga-backend/internal/middleware"
 )
 
@@ -22,11 +22,12 @@ func (s *Server) handleCreateSuspect() http.HandlerFunc {
 			return
 		}
 		suspect.CreatedAt = time.Now()
-		if err := database.CreateSuspect(r.Context(), s.db.Get(), &suspect); err != nil {
-			log.Printf("ERROR: Failed to create suspect: %v", err)
+		if err := s.repos.Suspects.Create(r.Context(), &suspect); err != nil {
+			logging.FromContext(r.Context()).Error("failed to create suspect", "error", err)
 			writeJSONError(w, "Failed to create suspect", http.StatusInternalServerError)
 			return
 		}
+		s.metrics.SuspectsCreated(1)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusCreated)
 		json.NewEncoder(w).Encode(suspect)
@@ -46,45 +47,31 @@ func (s *Server) handleCreateManySuspects() http.HandlerFunc {
             return
         }
 
-        tx, err := s.db.Get().BeginTx(r.Context(), nil)
+        tx, err := s.store.BeginTx(r.Context())
         if err != nil {
-            log.Printf("ERROR: Failed to begin transaction for batch suspect creation: %v", err)
+            logging.FromContext(r.Context()).Error("failed to begin transaction for batch suspect creation", "error", err)
             writeJSONError(w, "Failed to start database transaction", http.StatusInternalServerError)
             return
         }
         defer tx.Rollback()
 
-        var wg sync.WaitGroup
-        errChan := make(chan error, len(suspects))
+        // Satu transaksi hanya boleh dipakai satu goroutine, jadi insert dilakukan berurutan.
         now := time.Now()
-
         for _, suspect := range suspects {
-            wg.Add(1)
-            go func(sp *database.Suspect) {
-                defer wg.Done()
-                sp.CreatedAt = now
-                if err := database.CreateSuspectTx(r.Context(), tx, sp); err != nil {
-                    errChan <- err
-                }
-            }(suspect)
-        }
-
-        wg.Wait()
-        close(errChan)
-
-        for err := range errChan {
-            if err != nil {
-                log.Printf("ERROR: Failed to create a suspect in batch: %v", err)
+            suspect.CreatedAt = now
+            if err := tx.Repos().Suspects.Create(r.Context(), suspect); err != nil {
+                logging.FromContext(r.Context()).Error("failed to create suspect in batch", "error", err)
                 writeJSONError(w, fmt.Sprintf("Failed to create one or more suspects: %v", err), http.StatusInternalServerError)
                 return
             }
         }
 
         if err := tx.Commit(); err != nil {
-            log.Printf("ERROR: Failed to commit transaction for batch suspect creation: %v", err)
+            logging.FromContext(r.Context()).Error("failed to commit batch suspect creation", "error", err)
             writeJSONError(w, "Failed to commit transaction", http.StatusInternalServerError)
             return
         }
+        s.metrics.SuspectsCreated(len(suspects))
 
         w.Header().Set("Content-Type", "application/json")
         w.WriteHeader(http.StatusCreated)
@@ -94,9 +81,9 @@ func (s *Server) handleCreateManySuspects() http.HandlerFunc {
 
 func (s *Server) handleListSuspects() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		list, err := database.ListSuspects(r.Context(), s.db.Get())
+		list, err := s.repos.Suspects.List(r.Context())
 		if err != nil {
-			log.Printf("ERROR: Failed to list suspects: %v", err)
+			logging.FromContext(r.Context()).Error("failed to list suspects", "error", err)
 			writeJSONError(w, fmt.Sprintf("Failed to retrieve suspects: %v", err), http.StatusInternalServerError)
 			return
 		}
@@ -114,12 +101,12 @@ func (s *Server) handleGetSuspectByID() http.HandlerFunc {
 			return
 		}
 
-		suspect, err := database.GetSuspectByID(r.Context(), s.db.Get(), id)
+		suspect, err := s.repos.Suspects.GetByID(r.Context(), id)
 		if err != nil {
 			if err.Error() == "suspect not found" {
 				writeJSONError(w, err.Error(), http.StatusNotFound)
 			} else {
-				log.Printf("ERROR: Failed to get suspect by ID %d: %v", id, err)
+				logging.FromContext(r.Context()).Error("failed to get suspect", "suspect_id", id, "error", err)
 				writeJSONError(w, "Failed to retrieve suspect", http.StatusInternalServerError)
 			}
 			return
@@ -143,11 +130,40 @@ func (s *Server) handleUpdateSuspect() http.HandlerFunc {
 			return
 		}
 
-		if err := database.UpdateSuspect(r.Context(), s.db.Get(), id, &suspect); err != nil {
-			log.Printf("ERROR: Failed to update suspect %d: %v", id, err)
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start transaction", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		existing, err := tx.Repos().Suspects.GetByID(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, database.ErrSuspectNotFound) {
+				writeJSONError(w, "Suspect not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to get suspect", http.StatusInternalServerError)
+			}
+			return
+		}
+		if err := tx.Repos().Suspects.Update(r.Context(), id, &suspect); err != nil {
+			logging.FromContext(r.Context()).Error("failed to update suspect", "suspect_id", id, "error", err)
 			writeJSONError(w, "Failed to update suspect", http.StatusInternalServerError)
 			return
 		}
+		updated, err := tx.Repos().Suspects.GetByID(r.Context(), id)
+		if err != nil {
+			writeJSONError(w, "Failed to get suspect", http.StatusInternalServerError)
+			return
+		}
+		if err := s.audit(r, tx.Repos(), auditChange{Action: "suspect.update", EntityType: "suspect", EntityID: id, Before: existing, After: updated}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit transaction", http.StatusInternalServerError)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(map[string]string{"message": "Suspect updated successfully"})
@@ -162,11 +178,35 @@ func (s *Server) handleDeleteSuspect() http.HandlerFunc {
 			writeJSONError(w, "Invalid suspect ID", http.StatusBadRequest)
 			return
 		}
-		if err := database.DeleteSuspect(r.Context(), s.db.Get(), id); err != nil {
-			log.Printf("ERROR: Failed to delete suspect %d: %v", id, err)
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start transaction", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		existing, err := tx.Repos().Suspects.GetByID(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, database.ErrSuspectNotFound) {
+				writeJSONError(w, "Suspect not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to get suspect", http.StatusInternalServerError)
+			}
+			return
+		}
+		if err := tx.Repos().Suspects.Delete(r.Context(), id); err != nil {
+			logging.FromContext(r.Context()).Error("failed to delete suspect", "suspect_id", id, "error", err)
 			writeJSONError(w, "Failed to delete suspect", http.StatusInternalServerError)
 			return
 		}
+		if err := s.audit(r, tx.Repos(), auditChange{Action: "suspect.delete", EntityType: "suspect", EntityID: id, Before: existing}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit transaction", http.StatusInternalServerError)
+			return
+		}
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
@@ -180,5 +220,7 @@ func (s *Server) RegisterSuspectRoutes(r *mux.Router) {
 	r.Handle("/suspects/{id:[0-9]+}", adminOnlyMiddleware(s.handleGetSuspectByID())).Methods("GET")
 	r.Handle("/suspects/{id:[0-9]+}", adminOnlyMiddleware(s.handleUpdateSuspect())).Methods("PUT")
 	r.Handle("/suspects/{id:[0-9]+}", adminOnlyMiddleware(s.handleDeleteSuspect())).Methods("DELETE")
+	r.Handle("/suspects/{id:[0-9]+}/review", adminOnlyMiddleware(s.handleReviewSuspect())).Methods("POST")
+	r.Handle("/suspects/{id:[0-9]+}/feedback", s.handleSuspectFeedback()).Methods("POST")
 }
 
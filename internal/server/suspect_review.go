@@ -0,0 +1,267 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+	"github.com/jaga-project/jaga-backend/internal/middleware"
+)
+
+type suspectReviewRequest struct {
+	Status  string `json:"status"`
+	Comment string `json:"comment"`
+	// MarkFound memindahkan laporan ke SUDAH_DITEMUKAN; hanya untuk
+	// CONFIRMED.
+	MarkFound bool `json:"mark_found"`
+}
+
+// handleReviewSuspect menyimpan keputusan admin atas suspect sekaligus
+// labelnya untuk training ulang.
+func (s *Server) handleReviewSuspect() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			writeJSONError(w, "Invalid suspect ID", http.StatusBadRequest)
+			return
+		}
+		var req suspectReviewRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		switch req.Status {
+		case database.SuspectReviewPending, database.SuspectReviewConfirmed, database.SuspectReviewRejected:
+		default:
+			writeJSONError(w, "status must be PENDING, CONFIRMED or REJECTED", http.StatusBadRequest)
+			return
+		}
+		if req.MarkFound && req.Status != database.SuspectReviewConfirmed {
+			writeJSONError(w, "mark_found is only allowed when confirming a suspect", http.StatusBadRequest)
+			return
+		}
+		reviewerID, _ := r.Context().Value(middleware.UserIDContextKey).(string)
+		log := logging.FromContext(r.Context())
+
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start transaction", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		repos := tx.Repos()
+
+		existing, err := repos.Suspects.GetByID(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, database.ErrSuspectNotFound) {
+				writeJSONError(w, "Suspect not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to get suspect", http.StatusInternalServerError)
+			}
+			return
+		}
+		now := time.Now()
+		if err := repos.Suspects.Review(r.Context(), id, req.Status, reviewerID, req.Comment, now); err != nil {
+			log.Error("failed to review suspect", "suspect_id", id, "error", err)
+			writeJSONError(w, "Failed to review suspect", http.StatusInternalServerError)
+			return
+		}
+		label := database.SuspectLabel{SuspectID: id, Source: database.SuspectLabelSourceAdmin, Label: req.Status, LabeledBy: &reviewerID, Comment: req.Comment, CreatedAt: now}
+		if err := repos.SuspectLabels.Create(r.Context(), &label); err != nil {
+			log.Error("failed to store suspect label", "suspect_id", id, "error", err)
+			writeJSONError(w, "Failed to store suspect label", http.StatusInternalServerError)
+			return
+		}
+		updated, err := repos.Suspects.GetByID(r.Context(), id)
+		if err != nil {
+			writeJSONError(w, "Failed to get suspect", http.StatusInternalServerError)
+			return
+		}
+		if err := s.audit(r, repos, auditChange{Action: "suspect.review", EntityType: "suspect", EntityID: id, Before: existing, After: updated}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+
+		if req.MarkFound {
+			report, err := repos.LostReports.GetByID(r.Context(), int(existing.LostID))
+			if err != nil {
+				if errors.Is(err, database.ErrLostReportNotFound) {
+					writeJSONError(w, "The lost report of this suspect has been deleted", http.StatusConflict)
+				} else {
+					writeJSONError(w, "Failed to get lost report: "+err.Error(), http.StatusInternalServerError)
+				}
+				return
+			}
+			if report.Status != database.StatusLostReportSudahDitemukan {
+				before := *report
+				report.Status = database.StatusLostReportSudahDitemukan
+				if err := repos.LostReports.Update(r.Context(), report.LostID, report); err != nil {
+					writeJSONError(w, "Failed to update lost report: "+err.Error(), http.StatusInternalServerError)
+					return
+				}
+				if err := s.audit(r, repos, auditChange{Action: "lost_report.status_change", EntityType: "lost_report", EntityID: report.LostID, Before: before, After: report}); err != nil {
+					writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+					return
+				}
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit transaction", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(updated)
+	}
+}
+
+// handleSuspectFeedback dipakai pemilik laporan untuk menandai suspect
+// sebagai motornya (MINE) atau bukan (NOT_MINE).
+func (s *Server) handleSuspectFeedback() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			writeJSONError(w, "Invalid suspect ID", http.StatusBadRequest)
+			return
+		}
+		var req struct {
+			Feedback string `json:"feedback"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Feedback != database.OwnerFeedbackMine && req.Feedback != database.OwnerFeedbackNotMine {
+			writeJSONError(w, "feedback must be MINE or NOT_MINE", http.StatusBadRequest)
+			return
+		}
+		userID, _ := r.Context().Value(middleware.UserIDContextKey).(string)
+
+		tx, err := s.store.BeginTx(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to start transaction", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		repos := tx.Repos()
+
+		existing, err := repos.Suspects.GetByID(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, database.ErrSuspectNotFound) {
+				writeJSONError(w, "Suspect not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to get suspect", http.StatusInternalServerError)
+			}
+			return
+		}
+		report, err := repos.LostReports.GetByID(r.Context(), int(existing.LostID))
+		if err != nil {
+			if errors.Is(err, database.ErrLostReportNotFound) {
+				writeJSONError(w, "Suspect not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to get lost report: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if report.UserID != userID {
+			writeJSONError(w, "Forbidden: Only the owner of the lost report can give feedback on its suspects.", http.StatusForbidden)
+			return
+		}
+
+		now := time.Now()
+		if err := repos.Suspects.SetOwnerFeedback(r.Context(), id, req.Feedback, now); err != nil {
+			logging.FromContext(r.Context()).Error("failed to save suspect feedback", "suspect_id", id, "error", err)
+			writeJSONError(w, "Failed to save feedback", http.StatusInternalServerError)
+			return
+		}
+		label := database.SuspectLabel{SuspectID: id, Source: database.SuspectLabelSourceOwner, Label: req.Feedback, LabeledBy: &userID, CreatedAt: now}
+		if err := repos.SuspectLabels.Create(r.Context(), &label); err != nil {
+			logging.FromContext(r.Context()).Error("failed to store suspect label", "suspect_id", id, "error", err)
+			writeJSONError(w, "Failed to store suspect label", http.StatusInternalServerError)
+			return
+		}
+		updated, err := repos.Suspects.GetByID(r.Context(), id)
+		if err != nil {
+			writeJSONError(w, "Failed to get suspect", http.StatusInternalServerError)
+			return
+		}
+		if err := s.audit(r, repos, auditChange{Action: "suspect.feedback", EntityType: "suspect", EntityID: id, Before: existing, After: updated}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit transaction", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(updated)
+	}
+}
+
+// handleExportSuspectLabels mengunduh label review sebagai CSV untuk
+// training ulang model skor. Filter: source (ADMIN/OWNER), from, to.
+func (s *Server) handleExportSuspectLabels() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var f database.SuspectLabelFilter
+		switch source := q.Get("source"); source {
+		case "", database.SuspectLabelSourceAdmin, database.SuspectLabelSourceOwner:
+			f.Source = source
+		default:
+			writeJSONError(w, "source must be ADMIN or OWNER", http.StatusBadRequest)
+			return
+		}
+		for name, dst := range map[string]**time.Time{"from": &f.From, "to": &f.To} {
+			if v := q.Get(name); v != "" {
+				t, err := time.Parse(time.RFC3339, v)
+				if err != nil {
+					writeJSONError(w, "invalid "+name+", expected RFC3339 timestamp", http.StatusBadRequest)
+					return
+				}
+				*dst = &t
+			}
+		}
+		labels, err := s.repos.SuspectLabels.List(r.Context(), f)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to export suspect labels", "error", err)
+			writeJSONError(w, "Failed to export suspect labels: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="suspect_labels.csv"`)
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"label_id", "created_at", "suspect_id", "lost_id", "detected_id", "person_image_path", "motorcycle_image_path",
+			"person_score", "motor_score", "final_score", "source", "label", "is_match", "labeled_by", "comment"})
+		formatScore := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+		for _, l := range labels {
+			var isMatch, labeledBy string
+			if m := l.IsMatch(); m != nil {
+				isMatch = strconv.FormatBool(*m)
+			}
+			if l.LabeledBy != nil {
+				labeledBy = *l.LabeledBy
+			}
+			cw.Write([]string{strconv.FormatInt(l.LabelID, 10), l.CreatedAt.UTC().Format(time.RFC3339Nano),
+				strconv.FormatInt(l.SuspectID, 10), strconv.FormatInt(l.LostID, 10), strconv.FormatInt(l.DetectedID, 10),
+				l.PersonImagePath, l.MotorcycleImagePath, formatScore(l.PersonScore), formatScore(l.MotorScore), formatScore(l.FinalScore),
+				l.Source, l.Label, isMatch, labeledBy, l.Comment})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			logging.FromContext(r.Context()).Warn("failed to write suspect labels CSV", "error", err)
+		}
+	}
+}
+
+// RegisterAdminSuspectRoutes harus didaftarkan sebelum RegisterAdminRoutes
+// agar path-nya tidak tertangkap route /{user_id}.
+func (s *Server) RegisterAdminSuspectRoutes(r *mux.Router) {
+	r.Handle("/suspect-labels/export", s.handleExportSuspectLabels()).Methods("GET")
+}
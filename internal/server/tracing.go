@@ -0,0 +1,19 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/jaga-project/jaga-backend/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// parseMultipartForm sama dengan r.ParseMultipartForm tetapi dicatat sebagai
+// span agar waktu parsing upload terlihat terpisah dari penulisan file dan
+// transaksi.
+func parseMultipartForm(r *http.Request, maxMemory int64) error {
+	_, span := tracing.Start(r.Context(), "parse multipart form",
+		attribute.Int64("http.request.body.size", r.ContentLength))
+	err := r.ParseMultipartForm(maxMemory)
+	tracing.End(span, err)
+	return err
+}
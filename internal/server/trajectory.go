@@ -0,0 +1,93 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/geo"
+	"github.com/jaga-project/jaga-backend/internal/logging"
+	"github.com/jaga-project/jaga-backend/internal/middleware"
+)
+
+// defaultMaxSpeedKmh adalah batas kecepatan motor antar kamera; hop yang
+// lebih cepat dianggap tidak mungkin.
+const defaultMaxSpeedKmh = 120
+
+// handleGetTrajectory merangkai suspect sebuah laporan menjadi rute
+// GeoJSON LineString berurutan waktu. Suspect REJECTED atau NOT_MINE
+// diabaikan.
+func (s *Server) handleGetTrajectory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lostReportID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "invalid lost_report_id: must be an integer", http.StatusBadRequest)
+			return
+		}
+		maxSpeed := float64(defaultMaxSpeedKmh)
+		if v := r.URL.Query().Get("max_speed_kmh"); v != "" {
+			if maxSpeed, err = strconv.ParseFloat(v, 64); err != nil || maxSpeed < 10 || maxSpeed > 300 {
+				writeJSONError(w, "max_speed_kmh must be a number between 10 and 300", http.StatusBadRequest)
+				return
+			}
+		}
+		minScore := 0.0
+		if v := r.URL.Query().Get("min_score"); v != "" {
+			if minScore, err = strconv.ParseFloat(v, 64); err != nil || minScore < 0 || minScore > 1 {
+				writeJSONError(w, "min_score must be a number between 0 and 1", http.StatusBadRequest)
+				return
+			}
+		}
+
+		requestingUserID, _ := r.Context().Value(middleware.UserIDContextKey).(string)
+		isAdmin, _ := r.Context().Value(middleware.AdminStatusContextKey).(bool)
+
+		report, err := s.repos.LostReports.GetWithVehicleInfoByID(r.Context(), lostReportID)
+		if err != nil {
+			if errors.Is(err, database.ErrLostReportNotFound) {
+				writeJSONError(w, "Lost report not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to get lost report for authorization: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if !isAdmin && report.UserID != requestingUserID {
+			writeJSONError(w, "Forbidden: You can only view results for your own reports.", http.StatusForbidden)
+			return
+		}
+
+		rows, err := s.repos.Suspects.ListResultsByLostReportID(r.Context(), lostReportID, database.SuspectResultFilter{MinScore: minScore})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to list suspects for trajectory", "lost_id", lostReportID, "error", err)
+			writeJSONError(w, "Failed to retrieve analysis results: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var sightings []geo.Sighting
+		for _, row := range rows {
+			// Suspect yang ditolak admin atau pemilik tidak ikut dirangkai.
+			if row.ReviewStatus == database.SuspectReviewRejected ||
+				row.OwnerFeedback != nil && *row.OwnerFeedback == database.OwnerFeedbackNotMine {
+				continue
+			}
+			sightings = append(sightings, geo.Sighting{
+				SuspectID:  row.SuspectID,
+				CameraID:   row.CameraID,
+				CameraName: row.CameraName,
+				Latitude:   row.CameraLatitude,
+				Longitude:  row.CameraLongitude,
+				Time:       row.DetectedTimestamp,
+				Score:      row.FinalScore,
+			})
+		}
+
+		trajectory := geo.BuildTrajectory(sightings, maxSpeed)
+		w.Header().Set("Content-Type", "application/geo+json")
+		json.NewEncoder(w).Encode(trajectory.Feature(map[string]interface{}{
+			"lost_report_id": lostReportID,
+			"max_speed_kmh":  maxSpeed,
+		}))
+	}
+}
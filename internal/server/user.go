@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -15,6 +14,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/fieldcrypt"
+	"github.com/jaga-project/jaga-backend/internal/metrics"
 	"github.com/jaga-project/jaga-backend/internal/middleware"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -37,7 +38,7 @@ func (s *Server) handleCreateUser() http.HandlerFunc {
         const extraFormDataSizeUser = 1 * 1024 * 1024
         maxTotalUserFormSize := int64(extraFormDataSizeUser + maxKTPFileSize)
 
-        if err := r.ParseMultipartForm(maxTotalUserFormSize); err != nil {
+        if err := parseMultipartForm(r, maxTotalUserFormSize); err != nil {
             writeJSONError(w, "Request too large or invalid multipart form: "+err.Error(), http.StatusBadRequest)
             return
         }
@@ -56,6 +57,10 @@ func (s *Server) handleCreateUser() http.HandlerFunc {
             writeJSONError(w, "Missing required user fields (name, email, password, nik)", http.StatusBadRequest)
             return
         }
+        if err := s.passwords.Validate(password, newUser.Email, newUser.Name); err != nil {
+            writeJSONError(w, err.Error(), http.StatusBadRequest)
+            return
+        }
 
         hashedPasswordBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
         if err != nil {
@@ -64,7 +69,7 @@ func (s *Server) handleCreateUser() http.HandlerFunc {
         }
         newUser.Password = string(hashedPasswordBytes)
 
-        tx, err := s.db.Get().BeginTx(r.Context(), nil)
+        tx, err := s.store.BeginTx(r.Context())
         if err != nil {
             writeJSONError(w, "Failed to start database transaction: "+err.Error(), http.StatusInternalServerError)
             return
@@ -82,59 +87,82 @@ func (s *Server) handleCreateUser() http.HandlerFunc {
 
             validatedMimeType, errMime := ValidateMimeType(file, handler, DefaultAllowedMimeTypes)
             if errMime != nil {
+                s.metrics.UploadFailed(metrics.CategoryKTP)
                 writeJSONError(w, fmt.Sprintf("KTP image MIME type validation failed: %v", errMime), http.StatusBadRequest)
                 return
             }
 
             if handler.Size > maxKTPFileSize {
+                s.metrics.UploadFailed(metrics.CategoryKTP)
                 writeJSONError(w, fmt.Sprintf("KTP image file size exceeds %dMB limit.", maxKTPFileSize/(1024*1024)), http.StatusBadRequest)
                 return
             }
 
-            uniqueFilename := generateUniqueFilenameLocal(handler.Filename)
-            ktpStoragePath := filepath.Join(imageUploadPath, uniqueFilename)
-
-            if err := saveUploadedFile(file, ktpStoragePath); err != nil {
+            if _, err := file.Seek(0, io.SeekStart); err != nil {
+                s.metrics.UploadFailed(metrics.CategoryKTP)
+                writeJSONError(w, "Failed to save KTP image file: "+err.Error(), http.StatusInternalServerError)
+                return
+            }
+            ktpStoragePath, ktpBytes, err := s.storage.Save(r.Context(), generateUniqueFilenameLocal(handler.Filename), file)
+            s.observeUpload(metrics.CategoryKTP, ktpBytes, err)
+            if err != nil {
                 writeJSONError(w, "Failed to save KTP image file: "+err.Error(), http.StatusInternalServerError)
                 return
             }
 
             imgRecord := database.Image{
-                StoragePath:      filepath.ToSlash(ktpStoragePath),
+                StoragePath:      ktpStoragePath,
                 FilenameOriginal: handler.Filename,
                 MimeType:         validatedMimeType,
                 SizeBytes:        handler.Size,
             }
-            if err := database.CreateImageTx(r.Context(), tx, &imgRecord); err != nil {
-                os.Remove(ktpStoragePath) 
+            if err := tx.Repos().Images.Create(r.Context(), &imgRecord); err != nil {
+                s.storage.Remove(r.Context(), ktpStoragePath) 
                 writeJSONError(w, "Failed to save KTP image metadata: "+err.Error(), http.StatusInternalServerError)
                 return
             }
             newUser.KTPImageID = &imgRecord.ImageID
+            newUser.KTPVerificationStatus = database.VerificationPending
         }
 
-        if err := database.CreateUserTx(r.Context(), tx, &newUser); err != nil {
+        if err := tx.Repos().Users.Create(r.Context(), &newUser); err != nil {
             if newUser.KTPImageID != nil {
-                path, _ := database.GetImageStoragePath(r.Context(), tx, *newUser.KTPImageID)
+                path, _ := tx.Repos().Images.GetStoragePath(r.Context(), *newUser.KTPImageID)
                 if path != "" {
-                    os.Remove(path)
+                    s.storage.Remove(r.Context(), path)
                 }
             }
             writeJSONError(w, "Failed to create user: "+err.Error(), http.StatusInternalServerError)
             return
         }
 
+        verificationToken, err := issueUserToken(r.Context(), tx.Repos(), newUser.UserID, database.TokenPurposeEmailVerification, s.cfg.Auth.EmailVerificationTTL)
+        if err != nil {
+            writeJSONError(w, "Failed to create verification token: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+
+        if err := s.audit(r, tx.Repos(), auditChange{Action: "user.create", EntityType: "user", EntityID: newUser.UserID, After: newUser}); err != nil {
+            writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+            return
+        }
+
         if err := tx.Commit(); err != nil {
             if newUser.KTPImageID != nil {
-                path, _ := database.GetImageStoragePath(r.Context(), s.db.Get(), *newUser.KTPImageID)
+                path, _ := s.repos.Images.GetStoragePath(r.Context(), *newUser.KTPImageID)
                 if path != "" {
-                    os.Remove(path)
+                    s.storage.Remove(r.Context(), path)
                 }
             }
             writeJSONError(w, "Failed to commit database transaction: "+err.Error(), http.StatusInternalServerError)
             return
         }
 
+        if newUser.KTPImageID != nil {
+            s.startOCR(r.Context(), database.DocumentKTP, *newUser.KTPImageID)
+        }
+        s.sendVerificationEmail(r.Context(), &newUser, verificationToken)
+
         newUser.Password = "" 
         w.Header().Set("Content-Type", "application/json")
         w.WriteHeader(http.StatusCreated)
@@ -145,9 +173,23 @@ func (s *Server) handleCreateUser() http.HandlerFunc {
 func (s *Server) handleGetUser() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
+		if nik := r.URL.Query().Get("nik"); nik != "" {
+			user, err := s.repos.Users.FindByNIKHash(r.Context(), s.fields.BlindIndex(nik))
+			if err != nil {
+				if errors.Is(err, database.ErrUserNotFound) {
+					writeJSONError(w, "User not found", http.StatusNotFound)
+				} else {
+					writeJSONError(w, "Failed to retrieve user: "+err.Error(), http.StatusInternalServerError)
+				}
+				return
+			}
+			user.Password = ""
+			json.NewEncoder(w).Encode(user)
+			return
+		}
 		email := r.URL.Query().Get("email")
 		if email == "" {
-			users, err := database.FindManyUser(s.db.Get(), r.Context())
+			users, err := s.repos.Users.List(r.Context())
 			if err != nil {
         writeJSONError(w, "Failed to retrieve users: "+err.Error(), http.StatusInternalServerError)
 				return
@@ -159,9 +201,9 @@ func (s *Server) handleGetUser() http.HandlerFunc {
 			return
 		}
 
-		user, err := database.FindSingleUser(s.db.Get(), email, r.Context())
+		user, err := s.repos.Users.FindByEmail(r.Context(), email)
 		if err != nil {
-			if err == sql.ErrNoRows || err.Error() == "user not found" {
+			if errors.Is(err, sql.ErrNoRows) || errors.Is(err, database.ErrUserNotFound) {
 				writeJSONError(w, "User not found", http.StatusNotFound)
 
 			} else {
@@ -183,9 +225,9 @@ func (s *Server) handleGetUserByID() http.HandlerFunc {
 			return
 		}
 
-		user, err := database.FindUserByID(s.db.Get(), userID, r.Context())
+		user, err := s.repos.Users.FindByID(r.Context(), userID)
 		if err != nil {
-			if err == sql.ErrNoRows || err.Error() == "user not found" {
+			if errors.Is(err, sql.ErrNoRows) || errors.Is(err, database.ErrUserNotFound) {
 				writeJSONError(w, "User not found", http.StatusNotFound)
       } else {
         writeJSONError(w, "Failed to retrieve user: "+err.Error(), http.StatusInternalServerError)
@@ -193,10 +235,22 @@ func (s *Server) handleGetUserByID() http.HandlerFunc {
 			return
 		}
 		user.Password = ""
+		requestingUserID, _ := r.Context().Value(middleware.UserIDContextKey).(string)
+		isAdmin, _ := r.Context().Value(middleware.AdminStatusContextKey).(bool)
+		if !isAdmin && requestingUserID != user.UserID {
+			maskPersonalData(user)
+		}
 		json.NewEncoder(w).Encode(user)
 	}
 }
 
+// maskPersonalData menyamarkan NIK dan nomor telepon untuk peminta yang
+// bukan pemilik data maupun admin.
+func maskPersonalData(u *database.User) {
+	u.NIK = fieldcrypt.Mask(u.NIK)
+	u.Phone = fieldcrypt.Mask(u.Phone)
+}
+
 func (s *Server) handleUpdateUser() http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
         targetUserID := mux.Vars(r)["id"]
@@ -219,7 +273,47 @@ func (s *Server) handleUpdateUser() http.HandlerFunc {
             return
         }
 
+        // Status verifikasi hanya diubah lewat alur verifikasi email, dan
+        // nik_hash selalu dihitung ulang dari nik oleh repository.
+        delete(updates, "email_verified_at")
+        delete(updates, "nik_hash")
+        for _, col := range []string{"nik", "phone"} {
+            if v, ok := updates[col]; ok {
+                if _, isString := v.(string); !isString {
+                    writeJSONError(w, col+" must be a string", http.StatusBadRequest)
+                    return
+                }
+            }
+        }
+
+        existingUser, err := s.repos.Users.FindByID(r.Context(), targetUserID)
+        if err != nil {
+            if errors.Is(err, database.ErrUserNotFound) {
+                writeJSONError(w, "User not found or no effective changes made", http.StatusNotFound)
+            } else {
+                writeJSONError(w, "Failed to retrieve user: "+err.Error(), http.StatusInternalServerError)
+            }
+            return
+        }
+
+        emailChanged := false
+        if email, ok := updates["email"].(string); ok && email != existingUser.Email {
+            emailChanged = true
+            updates["email_verified_at"] = nil
+        }
+
         if password, ok := updates["password"].(string); ok && password != "" {
+            email, name := existingUser.Email, existingUser.Name
+            if v, ok := updates["email"].(string); ok {
+                email = v
+            }
+            if v, ok := updates["name"].(string); ok {
+                name = v
+            }
+            if err := s.passwords.Validate(password, email, name); err != nil {
+                writeJSONError(w, err.Error(), http.StatusBadRequest)
+                return
+            }
             hashedPasswordBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
             if err != nil {
                 writeJSONError(w, "Failed to hash new password: "+err.Error(), http.StatusInternalServerError)
@@ -235,14 +329,14 @@ func (s *Server) handleUpdateUser() http.HandlerFunc {
             return
         }
 
-        tx, err := s.db.Get().BeginTx(r.Context(), nil)
+        tx, err := s.store.BeginTx(r.Context())
         if err != nil {
             writeJSONError(w, "Failed to start transaction", http.StatusInternalServerError)
             return
         }
         defer tx.Rollback()
 
-        if err := database.UpdateUserTx(r.Context(), tx, targetUserID, updates); err != nil {
+        if err := tx.Repos().Users.Update(r.Context(), targetUserID, updates); err != nil {
             if errors.Is(err, sql.ErrNoRows) {
                 writeJSONError(w, "User not found or no effective changes made", http.StatusNotFound)
             } else {
@@ -251,17 +345,50 @@ func (s *Server) handleUpdateUser() http.HandlerFunc {
             return
         }
 
-        if err := tx.Commit(); err != nil {
-            writeJSONError(w, "Failed to commit transaction", http.StatusInternalServerError)
-            return
+        var verificationToken string
+        if emailChanged {
+            verificationToken, err = issueUserToken(r.Context(), tx.Repos(), targetUserID, database.TokenPurposeEmailVerification, s.cfg.Auth.EmailVerificationTTL)
+            if err != nil {
+                writeJSONError(w, "Failed to create verification token: "+err.Error(), http.StatusInternalServerError)
+                return
+            }
         }
 
-        updatedUser, err := database.FindUserByID(s.db.Get(), targetUserID, r.Context())
+        updatedUser, err := tx.Repos().Users.FindByID(r.Context(), targetUserID)
         if err != nil {
             writeJSONError(w, "User updated, but failed to retrieve new data", http.StatusInternalServerError)
             return
         }
+
+        // Verifikasi KTP berlaku untuk NIK dan foto yang diperiksa admin.
+        if updatedUser.NIK != existingUser.NIK || !sameImageID(updatedUser.KTPImageID, existingUser.KTPImageID) {
+            status := database.VerificationUnverified
+            if updatedUser.KTPImageID != nil {
+                status = database.VerificationPending
+            }
+            if err := tx.Repos().Users.SetKTPVerification(r.Context(), targetUserID, status, "", "", time.Now()); err != nil {
+                writeJSONError(w, "Failed to reset KTP verification: "+err.Error(), http.StatusInternalServerError)
+                return
+            }
+            updatedUser.KTPVerification = database.KTPVerification{KTPVerificationStatus: status}
+        }
+
+        if err := s.audit(r, tx.Repos(), auditChange{Action: "user.update", EntityType: "user", EntityID: targetUserID, Before: existingUser, After: updatedUser}); err != nil {
+            writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+            return
+        }
+
+        if err := tx.Commit(); err != nil {
+            writeJSONError(w, "Failed to commit transaction", http.StatusInternalServerError)
+            return
+        }
         updatedUser.Password = "" 
+        if updatedUser.KTPImageID != nil && !sameImageID(updatedUser.KTPImageID, existingUser.KTPImageID) {
+            s.startOCR(r.Context(), database.DocumentKTP, *updatedUser.KTPImageID)
+        }
+        if emailChanged {
+            s.sendVerificationEmail(r.Context(), updatedUser, verificationToken)
+        }
 
         w.Header().Set("Content-Type", "application/json")
         w.WriteHeader(http.StatusOK)
@@ -273,16 +400,27 @@ func (s *Server) handleDeleteUser() http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
         userID := mux.Vars(r)["id"]
 
-        tx, err := s.db.Get().BeginTx(r.Context(), nil)
+        tx, err := s.store.BeginTx(r.Context())
         if err != nil {
             writeJSONError(w, "Failed to start transaction", http.StatusInternalServerError)
             return
         }
         defer tx.Rollback()
 
-        _ = database.DeleteAdminTx(r.Context(), tx, userID) 
+        existingUser, err := tx.Repos().Users.FindByID(r.Context(), userID)
+        if err != nil {
+            if errors.Is(err, database.ErrUserNotFound) {
+                writeJSONError(w, "User not found", http.StatusNotFound)
+            } else {
+                writeJSONError(w, "Failed to retrieve user: "+err.Error(), http.StatusInternalServerError)
+            }
+            return
+        }
 
-        if err := database.DeleteUserTx(r.Context(), tx, userID); err != nil {
+        // Hak admin dicabut permanen; user yang di-restore kembali sebagai user biasa.
+        _ = tx.Repos().Admins.Delete(r.Context(), userID) 
+
+        if err := tx.Repos().Users.Delete(r.Context(), userID); err != nil {
             if errors.Is(err, sql.ErrNoRows) {
                 writeJSONError(w, "User not found", http.StatusNotFound)
             } else {
@@ -291,6 +429,11 @@ func (s *Server) handleDeleteUser() http.HandlerFunc {
             return
         }
 
+        if err := s.audit(r, tx.Repos(), auditChange{Action: "user.delete", EntityType: "user", EntityID: userID, Before: existingUser}); err != nil {
+            writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+            return
+        }
+
         if err := tx.Commit(); err != nil {
             writeJSONError(w, "Failed to commit transaction", http.StatusInternalServerError)
             return
@@ -300,27 +443,52 @@ func (s *Server) handleDeleteUser() http.HandlerFunc {
     }
 }
 
-func saveUploadedFile(file io.ReadSeeker, path string) error {
-    if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
-        return err
-    }
+// handleRestoreUser membatalkan soft delete user beserta kendaraan dan
+// laporan yang terhapus bersamanya.
+func (s *Server) handleRestoreUser() http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        userID := mux.Vars(r)["id"]
 
-    dst, err := os.Create(path)
-    if err != nil {
-        return err
-    }
-    defer dst.Close()
+        tx, err := s.store.BeginTx(r.Context())
+        if err != nil {
+            writeJSONError(w, "Failed to start transaction", http.StatusInternalServerError)
+            return
+        }
+        defer tx.Rollback()
 
-    if _, err := file.Seek(0, io.SeekStart); err != nil {
-        return err
-    }
+        if err := tx.Repos().Users.Restore(r.Context(), userID); err != nil {
+            if errors.Is(err, database.ErrUserNotFound) {
+                writeJSONError(w, "Deleted user not found", http.StatusNotFound)
+            } else if errors.Is(err, database.ErrPlateTaken) {
+                writeJSONError(w, "A vehicle of this user has a plate number that is now registered to another vehicle", http.StatusConflict)
+            } else {
+                writeJSONError(w, "Failed to restore user: "+err.Error(), http.StatusInternalServerError)
+            }
+            return
+        }
+        restoredUser, err := tx.Repos().Users.FindByID(r.Context(), userID)
+        if err != nil {
+            writeJSONError(w, "Failed to retrieve restored user: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+
+        if err := s.audit(r, tx.Repos(), auditChange{Action: "user.restore", EntityType: "user", EntityID: userID, After: restoredUser}); err != nil {
+            writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+            return
+        }
+        if err := tx.Commit(); err != nil {
+            writeJSONError(w, "Failed to commit transaction", http.StatusInternalServerError)
+            return
+        }
 
-    _, err = io.Copy(dst, file)
-    return err
+        restoredUser.Password = ""
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(restoredUser)
+    }
 }
 
 func (s *Server) RegisterUserRoutes(r *mux.Router) {
-	r.HandleFunc("/users", s.handleCreateUser()).Methods("POST")
+	r.Handle("/users", s.uploadQuota(s.handleCreateUser())).Methods("POST")
 }
 
 func (s *Server) RegisterUserProtectedRoutes(r *mux.Router) {
@@ -329,4 +497,5 @@ func (s *Server) RegisterUserProtectedRoutes(r *mux.Router) {
 	r.HandleFunc("/users/{id}", s.handleGetUserByID()).Methods("GET") 
 	r.HandleFunc("/users/{id}", s.handleUpdateUser()).Methods("PUT")
 	r.Handle("/users/{id}", adminOnlyMiddleware(s.handleDeleteUser())).Methods("DELETE")
+	r.Handle("/users/{id}/restore", adminOnlyMiddleware(s.handleRestoreUser())).Methods("POST")
 }
@@ -9,14 +9,13 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/logging"
 	"github.com/jaga-project/jaga-backend/internal/middleware"
 )
 
@@ -32,15 +31,21 @@ type VehicleResponse struct {
     STNKImageURL *string                 `json:"stnk_image_url,omitempty"`
     KKImageURL   *string                 `json:"kk_image_url,omitempty"`
     Ownership    *database.OwnershipType `json:"ownership,omitempty"`
+    database.VehicleAttributes
+    database.VehicleVerification
+    database.KKVerification
 }
 
-func (s *Server) toVehicleResponse(ctx context.Context, dbQuerier database.Querier, v *database.Vehicle) VehicleResponse {
+func (s *Server) toVehicleResponse(ctx context.Context, images database.ImageRepo, v *database.Vehicle) VehicleResponse {
     response := VehicleResponse{
         VehicleID:   v.VehicleID,
         VehicleName: v.VehicleName,
         Color:       v.Color,
         UserID:      v.UserID,
         PlateNumber: v.PlateNumber,
+        VehicleAttributes: v.VehicleAttributes,
+        VehicleVerification: v.VehicleVerification,
+        KKVerification: v.KKVerification,
     }
 
 		if v.Ownership.Valid {
@@ -51,22 +56,22 @@ func (s *Server) toVehicleResponse(ctx context.Context, dbQuerier database.Queri
     }
 
     if v.STNKImageID.Valid {
-        path, err := database.GetImageStoragePath(ctx, dbQuerier, v.STNKImageID.Int64)
+        path, err := images.GetStoragePath(ctx, v.STNKImageID.Int64)
         if err == nil && path != "" {
             url := "/" + strings.TrimPrefix(path, "/")
             response.STNKImageURL = &url
         } else if err != nil && !errors.Is(err, sql.ErrNoRows) {
-            fmt.Printf("WARN: Failed to get STNK image path for vehicle ID %d, image ID %d: %v\n", v.VehicleID, v.STNKImageID.Int64, err)
+            logging.FromContext(ctx).Warn("failed to get STNK image path", "vehicle_id", v.VehicleID, "image_id", v.STNKImageID.Int64, "error", err)
         }
     }
 
     if v.KKImageID.Valid {
-        path, err := database.GetImageStoragePath(ctx, dbQuerier, v.KKImageID.Int64)
+        path, err := images.GetStoragePath(ctx, v.KKImageID.Int64)
         if err == nil && path != "" {
             url := "/" + strings.TrimPrefix(path, "/")
             response.KKImageURL = &url
         } else if err != nil && !errors.Is(err, sql.ErrNoRows) {
-            fmt.Printf("WARN: Failed to get KK image path for vehicle ID %d, image ID %d: %v\n", v.VehicleID, v.KKImageID.Int64, err)
+            logging.FromContext(ctx).Warn("failed to get KK image path", "vehicle_id", v.VehicleID, "image_id", v.KKImageID.Int64, "error", err)
         }
     }
     return response
@@ -75,7 +80,7 @@ func (s *Server) toVehicleResponse(ctx context.Context, dbQuerier database.Queri
 func (s *Server) handleCreateVehicle() http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
         maxTotalSize := extraFormDataSizeVehicle + 2*maxFileSizeVehicle
-        if err := r.ParseMultipartForm(int64(maxTotalSize)); err != nil {
+        if err := parseMultipartForm(r, int64(maxTotalSize)); err != nil {
             writeJSONError(w, "Request too large or invalid multipart form: "+err.Error(), http.StatusBadRequest)
             return
         }
@@ -99,6 +104,10 @@ func (s *Server) handleCreateVehicle() http.HandlerFunc {
             writeJSONError(w, "vehicle_name and plate_number are required", http.StatusBadRequest)
             return
         }
+        if newVehicleDB.PlateNumber, err = database.FormatPlate(newVehicleDB.PlateNumber); err != nil {
+            writeJSONError(w, err.Error(), http.StatusBadRequest)
+            return
+        }
 
         if ownershipStr != "" {
             if ownershipStr == string(database.OwnershipPribadi) || ownershipStr == string(database.OwnershipKeluarga) {
@@ -111,9 +120,16 @@ func (s *Server) handleCreateVehicle() http.HandlerFunc {
             newVehicleDB.Ownership = sql.NullString{Valid: false}
         }
 
-        tx, err := s.db.Get().BeginTx(r.Context(), nil)
+        attributes, err := vehicleAttributeUpdates(r.Form)
+        if err != nil {
+            writeJSONError(w, err.Error(), http.StatusBadRequest)
+            return
+        }
+        applyVehicleAttributes(&newVehicleDB.VehicleAttributes, attributes)
+
+        tx, err := s.store.BeginTx(r.Context())
         if err != nil {
-            fmt.Printf("ERROR: Failed to start database transaction for vehicle creation: %v\n", err)
+            logging.FromContext(r.Context()).Error("failed to begin transaction for vehicle creation", "error", err)
             writeJSONError(w, "Failed to start database transaction", http.StatusInternalServerError)
             return
         }
@@ -130,10 +146,10 @@ func (s *Server) handleCreateVehicle() http.HandlerFunc {
 
         cleanupFiles := func() {
             if stnkImageStoragePath != "" {
-                os.Remove(stnkImageStoragePath)
+                s.storage.Remove(r.Context(), stnkImageStoragePath)
             }
             if kkImageStoragePath != "" {
-                os.Remove(kkImageStoragePath)
+                s.storage.Remove(r.Context(), kkImageStoragePath)
             }
         }
 
@@ -148,6 +164,7 @@ func (s *Server) handleCreateVehicle() http.HandlerFunc {
                 return
             }
             newVehicleDB.STNKImageID = stnkImageID
+            newVehicleDB.VerificationStatus = database.VerificationPending
             stnkImageStoragePath = tempPath
         } else if errSTNK != http.ErrMissingFile {
             errorMsg := fmt.Sprintf("Gagal mengambil file gambar STNK dari request: %v", errSTNK)
@@ -166,6 +183,7 @@ func (s *Server) handleCreateVehicle() http.HandlerFunc {
                 return
             }
             newVehicleDB.KKImageID = kkImageID
+            newVehicleDB.KKVerificationStatus = database.VerificationPending
             kkImageStoragePath = tempPath
         } else if errKK != http.ErrMissingFile {
             errorMsg := fmt.Sprintf("Gagal mengambil file gambar KK dari request: %v", errKK)
@@ -173,35 +191,50 @@ func (s *Server) handleCreateVehicle() http.HandlerFunc {
             return
         }
 
-        if err := database.CreateVehicleTx(r.Context(), tx, &newVehicleDB); err != nil {
+        if err := tx.Repos().Vehicles.Create(r.Context(), &newVehicleDB); err != nil {
             cleanupFiles()
-            fmt.Printf("ERROR: Failed to create vehicle record in transaction: %v\n", err)
+            if errors.Is(err, database.ErrPlateTaken) {
+                writeJSONError(w, "A vehicle with this plate number is already registered", http.StatusConflict)
+                return
+            }
+            logging.FromContext(r.Context()).Error("failed to create vehicle", "error", err)
             writeJSONError(w, "Failed to create vehicle record: "+err.Error(), http.StatusInternalServerError)
             return
         }
 
+        if err := s.audit(r, tx.Repos(), auditChange{Action: "vehicle.create", EntityType: "vehicle", EntityID: newVehicleDB.VehicleID, After: newVehicleDB}); err != nil {
+            cleanupFiles()
+            writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+            return
+        }
+
         if err := tx.Commit(); err != nil {
             cleanupFiles()
-            fmt.Printf("ERROR: Failed to commit database transaction for vehicle creation: %v\n", err)
+            logging.FromContext(r.Context()).Error("failed to commit vehicle creation", "error", err)
             writeJSONError(w, "Failed to commit database transaction", http.StatusInternalServerError)
             return
         }
         committed = true 
+        if newVehicleDB.STNKImageID.Valid {
+            s.startOCR(r.Context(), database.DocumentSTNK, newVehicleDB.STNKImageID.Int64)
+        }
 
-        createdVehicle, errGet := database.GetVehicleByID(r.Context(), s.db.Get(), newVehicleDB.VehicleID)
+        createdVehicle, errGet := s.repos.Vehicles.GetByID(r.Context(), newVehicleDB.VehicleID)
         if errGet != nil {
-            fmt.Printf("WARN: Vehicle created (ID: %d), but failed to retrieve for full response: %v\n", newVehicleDB.VehicleID, errGet)
+            logging.FromContext(r.Context()).Warn("vehicle created but failed to load it for response", "vehicle_id", newVehicleDB.VehicleID, "error", errGet)
             createdVehicle = &newVehicleDB // Fallback ke data yang ada
         }
 
-        response := s.toVehicleResponse(r.Context(), s.db.Get(), createdVehicle)
+        response := s.toVehicleResponse(r.Context(), s.repos.Images, createdVehicle)
         w.Header().Set("Content-Type", "application/json")
         w.WriteHeader(http.StatusCreated)
         json.NewEncoder(w).Encode(response)
     }
 }
 
-func (s *Server) uploadAndCreateImageRecord(ctx context.Context, tx *sql.Tx, file multipart.File, handler *multipart.FileHeader, formFieldName string, maxFileSize int64) (sql.NullInt64, string, error) {
+func (s *Server) uploadAndCreateImageRecord(ctx context.Context, tx database.Tx, file multipart.File, handler *multipart.FileHeader, formFieldName string, maxFileSize int64) (imageID sql.NullInt64, storagePath string, err error) {
+    defer func() { s.observeUpload(vehicleUploadCategory(formFieldName), handler.Size, err) }()
+
     if handler.Size == 0 {
         return sql.NullInt64{}, "", fmt.Errorf("file for %s is empty", formFieldName)
     }
@@ -214,42 +247,24 @@ func (s *Server) uploadAndCreateImageRecord(ctx context.Context, tx *sql.Tx, fil
         return sql.NullInt64{}, "", fmt.Errorf("MIME type validation failed for %s: %w", formFieldName, errMime)
     }
 
-    originalFilename := handler.Filename
-    fileExtension := filepath.Ext(originalFilename)
-    uniqueFilename := fmt.Sprintf("%s%s", uuid.New().String(), fileExtension)
-
-    storageDir := imageUploadPath
-    storagePath := filepath.Join(storageDir, uniqueFilename)
-
-    if err := os.MkdirAll(storageDir, os.ModePerm); err != nil {
-        return sql.NullInt64{}, "", fmt.Errorf("failed to create upload directory '%s' for %s: %w", storageDir, formFieldName, err)
-    }
-
-    dst, err := os.Create(storagePath)
-    if err != nil {
-        return sql.NullInt64{}, storagePath, fmt.Errorf("failed to create destination file '%s' for %s: %w", storagePath, formFieldName, err)
-    }
-    defer dst.Close()
-
     if _, errSeek := file.Seek(0, io.SeekStart); errSeek != nil {
-        os.Remove(storagePath)
-        return sql.NullInt64{}, storagePath, fmt.Errorf("failed to reset file pointer before copy for %s: %w", formFieldName, errSeek)
+        return sql.NullInt64{}, "", fmt.Errorf("failed to reset file pointer before copy for %s: %w", formFieldName, errSeek)
     }
 
-    bytesCopied, err := io.Copy(dst, file)
+    originalFilename := handler.Filename
+    storagePath, bytesCopied, err := s.storage.Save(ctx, generateUniqueFilenameLocal(originalFilename), file)
     if err != nil {
-        os.Remove(storagePath)
-        return sql.NullInt64{}, storagePath, fmt.Errorf("failed to copy %s file content to '%s': %w", formFieldName, storagePath, err)
+        return sql.NullInt64{}, "", fmt.Errorf("failed to store %s: %w", formFieldName, err)
     }
 
     imgRecord := database.Image{
-        StoragePath:      filepath.ToSlash(storagePath),
+        StoragePath:      storagePath,
         FilenameOriginal: originalFilename,
         MimeType:         validatedMimeType,
         SizeBytes:        bytesCopied,
     }
-    if err := database.CreateImageTx(ctx, tx, &imgRecord); err != nil {
-        os.Remove(storagePath)
+    if err := tx.Repos().Images.Create(ctx, &imgRecord); err != nil {
+        s.storage.Remove(ctx, storagePath)
         return sql.NullInt64{}, storagePath, fmt.Errorf("failed to save %s image metadata to DB: %w", formFieldName, err)
     }
 
@@ -260,7 +275,6 @@ func (s *Server) handleGetVehicle() http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
         vars := mux.Vars(r)
         idStr, idExists := vars["id"]
-        db := s.db.Get()
 
         if idExists && idStr != "" {
             id, err := strconv.ParseInt(idStr, 10, 64)
@@ -268,31 +282,31 @@ func (s *Server) handleGetVehicle() http.HandlerFunc {
                 writeJSONError(w, "invalid vehicle_id format", http.StatusBadRequest)
                 return
             }
-            v, err := database.GetVehicleByID(r.Context(), db, id)
+            v, err := s.repos.Vehicles.GetByID(r.Context(), id)
             if err != nil {
                 if errors.Is(err, sql.ErrNoRows) || err.Error() == "vehicle not found" {
                     writeJSONError(w, "Vehicle not found", http.StatusNotFound)
                 } else {
-                    fmt.Printf("ERROR: Failed to get vehicle by ID %d: %v\n", id, err)
+                    logging.FromContext(r.Context()).Error("failed to get vehicle", "vehicle_id", id, "error", err)
                     writeJSONError(w, "Failed to retrieve vehicle", http.StatusInternalServerError)
                 }
                 return
             }
-            response := s.toVehicleResponse(r.Context(), db, v)
+            response := s.toVehicleResponse(r.Context(), s.repos.Images, v)
             w.Header().Set("Content-Type", "application/json")
             json.NewEncoder(w).Encode(response)
             return
         }
 
-        vehiclesDB, err := database.ListVehicles(r.Context(), db)
+        vehiclesDB, err := s.repos.Vehicles.List(r.Context())
         if err != nil {
-            fmt.Printf("ERROR: Failed to list vehicles: %v\n", err)
+            logging.FromContext(r.Context()).Error("failed to list vehicles", "error", err)
             writeJSONError(w, "Failed to retrieve vehicles", http.StatusInternalServerError)
             return
         }
         responseList := make([]VehicleResponse, 0, len(vehiclesDB))
         for i := range vehiclesDB {
-            responseList = append(responseList, s.toVehicleResponse(r.Context(), db, &vehiclesDB[i]))
+            responseList = append(responseList, s.toVehicleResponse(r.Context(), s.repos.Images, &vehiclesDB[i]))
         }
         w.Header().Set("Content-Type", "application/json")
         json.NewEncoder(w).Encode(responseList)
@@ -306,18 +320,17 @@ func (s *Server) handleGetVehicleByPlate() http.HandlerFunc {
             writeJSONError(w, "plate_number is required in path", http.StatusBadRequest)
             return
         }
-        db := s.db.Get()
-        v, err := database.GetVehicleByPlate(r.Context(), db, plate)
+        v, err := s.repos.Vehicles.GetByPlate(r.Context(), plate)
         if err != nil {
             if errors.Is(err, sql.ErrNoRows) || err.Error() == "vehicle not found" {
                 writeJSONError(w, "Vehicle not found for plate: "+plate, http.StatusNotFound)
             } else {
-                fmt.Printf("ERROR: Failed to get vehicle by plate %s: %v\n", plate, err)
+                logging.FromContext(r.Context()).Error("failed to get vehicle by plate", "plate", plate, "error", err)
                 writeJSONError(w, "Failed to retrieve vehicle by plate", http.StatusInternalServerError)
             }
             return
         }
-        response := s.toVehicleResponse(r.Context(), db, v)
+        response := s.toVehicleResponse(r.Context(), s.repos.Images, v)
         w.Header().Set("Content-Type", "application/json")
         json.NewEncoder(w).Encode(response)
     }
@@ -331,17 +344,16 @@ func (s *Server) handleGetUserVehicles() http.HandlerFunc {
             return
         }
 
-        db := s.db.Get() 
-        vehiclesDB, err := database.ListVehiclesByUserID(r.Context(), db, userIDFromCtx)
+        vehiclesDB, err := s.repos.Vehicles.ListByUserID(r.Context(), userIDFromCtx)
         if err != nil {
-            fmt.Printf("ERROR: Failed to list vehicles for user %s: %v\n", userIDFromCtx, err)
+            logging.FromContext(r.Context()).Error("failed to list vehicles for user", "user_id", userIDFromCtx, "error", err)
             writeJSONError(w, "Failed to retrieve user's vehicles", http.StatusInternalServerError)
             return
         }
 
         responseList := make([]VehicleResponse, 0, len(vehiclesDB))
         for i := range vehiclesDB {
-            responseList = append(responseList, s.toVehicleResponse(r.Context(), db, &vehiclesDB[i]))
+            responseList = append(responseList, s.toVehicleResponse(r.Context(), s.repos.Images, &vehiclesDB[i]))
         }
 
         w.Header().Set("Content-Type", "application/json")
@@ -360,15 +372,14 @@ func (s *Server) handleUpdateVehicle() http.HandlerFunc {
         }
 
         maxTotalSize := extraFormDataSizeVehicle + 2*maxFileSizeVehicle
-        if err := r.ParseMultipartForm(int64(maxTotalSize)); err != nil {
+        if err := parseMultipartForm(r, int64(maxTotalSize)); err != nil {
             writeJSONError(w, "Request too large or invalid multipart form: "+err.Error(), http.StatusBadRequest)
             return
         }
 
-        db := s.db.Get()
-        existingVehicle, err := database.GetVehicleByID(r.Context(), db, id)
+        existingVehicle, err := s.repos.Vehicles.GetByID(r.Context(), id)
         if err != nil {
-            if errors.Is(err, sql.ErrNoRows) {
+            if errors.Is(err, sql.ErrNoRows) || errors.Is(err, database.ErrVehicleNotFound) {
                 writeJSONError(w, "Vehicle not found", http.StatusNotFound)
             } else {
                 writeJSONError(w, "Failed to retrieve existing vehicle", http.StatusInternalServerError)
@@ -392,7 +403,12 @@ func (s *Server) handleUpdateVehicle() http.HandlerFunc {
             updates["color"] = val[0]
         }
         if val, ok := r.Form["plate_number"]; ok {
-            updates["plate_number"] = val[0]
+            plate, err := database.FormatPlate(val[0])
+            if err != nil {
+                writeJSONError(w, err.Error(), http.StatusBadRequest)
+                return
+            }
+            updates["plate_number"] = plate
         }
         if val, ok := r.Form["ownership"]; ok {
             ownershipStr := val[0]
@@ -405,8 +421,16 @@ func (s *Server) handleUpdateVehicle() http.HandlerFunc {
                 return
             }
         }
+        attributes, err := vehicleAttributeUpdates(r.Form)
+        if err != nil {
+            writeJSONError(w, err.Error(), http.StatusBadRequest)
+            return
+        }
+        for col, val := range attributes {
+            updates[col] = val
+        }
 
-        tx, err := db.BeginTx(r.Context(), nil)
+        tx, err := s.store.BeginTx(r.Context())
         if err != nil {
             writeJSONError(w, "Failed to start database transaction", http.StatusInternalServerError)
             return
@@ -423,8 +447,8 @@ func (s *Server) handleUpdateVehicle() http.HandlerFunc {
         oldKkImageID := existingVehicle.KKImageID
 
         cleanupNewFiles := func() {
-            if newStnkImageStoragePath != "" { os.Remove(newStnkImageStoragePath) }
-            if newKkImageStoragePath != "" { os.Remove(newKkImageStoragePath) }
+            if newStnkImageStoragePath != "" { s.storage.Remove(r.Context(), newStnkImageStoragePath) }
+            if newKkImageStoragePath != "" { s.storage.Remove(r.Context(), newKkImageStoragePath) }
         }
 
         stnkFile, stnkHandler, errSTNK := r.FormFile("stnk_image")
@@ -464,16 +488,53 @@ func (s *Server) handleUpdateVehicle() http.HandlerFunc {
             return
         }
 
-        if err := database.UpdateVehicleTx(r.Context(), tx, id, updates); err != nil {
+        if err := tx.Repos().Vehicles.Update(r.Context(), id, updates); err != nil {
             cleanupNewFiles()
             if errors.Is(err, sql.ErrNoRows) {
                 writeJSONError(w, "Vehicle not found or no effective changes made", http.StatusNotFound)
+            } else if errors.Is(err, database.ErrPlateTaken) {
+                writeJSONError(w, "A vehicle with this plate number is already registered", http.StatusConflict)
             } else {
                 writeJSONError(w, "Failed to update vehicle: "+err.Error(), http.StatusInternalServerError)
             }
             return
         }
 
+        // Verifikasi admin berlaku untuk STNK dan plat yang diperiksa; bila
+        // salah satunya berubah, kendaraan harus diverifikasi ulang.
+        _, newSTNK := updates["stnk_image_id"]
+        plateChanged := updates["plate_number"] != nil && updates["plate_number"] != existingVehicle.PlateNumber
+        if newSTNK || plateChanged {
+            status := database.VerificationUnverified
+            if newSTNK || existingVehicle.STNKImageID.Valid {
+                status = database.VerificationPending
+            }
+            if err := tx.Repos().Vehicles.SetVerification(r.Context(), id, status, "", "", time.Now()); err != nil {
+                cleanupNewFiles()
+                writeJSONError(w, "Failed to reset vehicle verification: "+err.Error(), http.StatusInternalServerError)
+                return
+            }
+        }
+        if _, newKK := updates["kk_image_id"]; newKK {
+            if err := tx.Repos().Vehicles.SetKKVerification(r.Context(), id, database.VerificationPending, "", "", time.Now()); err != nil {
+                cleanupNewFiles()
+                writeJSONError(w, "Failed to reset KK verification: "+err.Error(), http.StatusInternalServerError)
+                return
+            }
+        }
+
+        updatedVehicle, err := tx.Repos().Vehicles.GetByID(r.Context(), id)
+        if err != nil {
+            cleanupNewFiles()
+            writeJSONError(w, "Failed to retrieve updated vehicle: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+        if err := s.audit(r, tx.Repos(), auditChange{Action: "vehicle.update", EntityType: "vehicle", EntityID: id, Before: existingVehicle, After: updatedVehicle}); err != nil {
+            cleanupNewFiles()
+            writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+            return
+        }
+
         if err := tx.Commit(); err != nil {
             cleanupNewFiles()
             writeJSONError(w, "Failed to commit database transaction", http.StatusInternalServerError)
@@ -481,23 +542,26 @@ func (s *Server) handleUpdateVehicle() http.HandlerFunc {
         }
         committed = true 
 
+        if updatedVehicle.STNKImageID.Valid && newSTNK {
+            s.startOCR(r.Context(), database.DocumentSTNK, updatedVehicle.STNKImageID.Int64)
+        }
         if _, ok := updates["stnk_image_id"]; ok && oldStnkImageID.Valid {
-            if errDel := s.deleteImageRecordAndFile(r.Context(), nil, oldStnkImageID.Int64); errDel != nil {
-                fmt.Printf("WARN: DB updated but failed to delete old STNK image (ID: %d): %v\n", oldStnkImageID.Int64, errDel)
+            if errDel := s.deleteImageRecordAndFile(r.Context(), s.repos.Images, oldStnkImageID.Int64); errDel != nil {
+                logging.FromContext(r.Context()).Warn("vehicle updated but failed to delete old STNK image", "image_id", oldStnkImageID.Int64, "error", errDel)
             }
         }
         if _, ok := updates["kk_image_id"]; ok && oldKkImageID.Valid {
-            if errDel := s.deleteImageRecordAndFile(r.Context(), nil, oldKkImageID.Int64); errDel != nil {
-                fmt.Printf("WARN: DB updated but failed to delete old KK image (ID: %d): %v\n", oldKkImageID.Int64, errDel)
+            if errDel := s.deleteImageRecordAndFile(r.Context(), s.repos.Images, oldKkImageID.Int64); errDel != nil {
+                logging.FromContext(r.Context()).Warn("vehicle updated but failed to delete old KK image", "image_id", oldKkImageID.Int64, "error", errDel)
             }
         }
 
-        updatedVehicleDB, errGet := database.GetVehicleByID(r.Context(), db, id)
+        updatedVehicleDB, errGet := s.repos.Vehicles.GetByID(r.Context(), id)
         if errGet != nil {
             writeJSONError(w, "Vehicle updated successfully, but failed to retrieve updated data.", http.StatusInternalServerError)
             return
         }
-        response := s.toVehicleResponse(r.Context(), db, updatedVehicleDB)
+        response := s.toVehicleResponse(r.Context(), s.repos.Images, updatedVehicleDB)
         w.Header().Set("Content-Type", "application/json")
         w.WriteHeader(http.StatusOK)
         json.NewEncoder(w).Encode(response)
@@ -514,8 +578,7 @@ func (s *Server) handleDeleteVehicle() http.HandlerFunc {
             return
         }
 
-        db := s.db.Get()
-        vehicleToDelete, err := database.GetVehicleByID(r.Context(), db, id)
+        vehicleToDelete, err := s.repos.Vehicles.GetByID(r.Context(), id)
         if err != nil {
             if errors.Is(err, sql.ErrNoRows) || err.Error() == "vehicle not found" {
                 writeJSONError(w, "Vehicle not found", http.StatusNotFound)
@@ -532,7 +595,7 @@ func (s *Server) handleDeleteVehicle() http.HandlerFunc {
             return
         }
 
-        tx, err := db.BeginTx(r.Context(), nil)
+        tx, err := s.store.BeginTx(r.Context())
         if err != nil {
             writeJSONError(w, "Failed to start database transaction", http.StatusInternalServerError)
             return
@@ -547,28 +610,9 @@ func (s *Server) handleDeleteVehicle() http.HandlerFunc {
             }
         }()
 
-        if vehicleToDelete.STNKImageID.Valid {
-            txErr = s.deleteImageRecordAndFile(r.Context(), tx, vehicleToDelete.STNKImageID.Int64)
-            if txErr != nil {
-                fmt.Printf("WARN: Failed to delete STNK image (ID: %d) during vehicle deletion: %v\n", vehicleToDelete.STNKImageID.Int64, txErr)
-                // Tidak menggagalkan commit utama, hanya warning. Atau bisa juga digagalkan.
-                // writeJSONError(w, "Failed to delete associated STNK image: "+txErr.Error(), http.StatusInternalServerError)
-                // return
-                txErr = nil 
-            }
-        }
-
-        if vehicleToDelete.KKImageID.Valid {
-            txErr = s.deleteImageRecordAndFile(r.Context(), tx, vehicleToDelete.KKImageID.Int64)
-            if txErr != nil {
-                fmt.Printf("WARN: Failed to delete KK image (ID: %d) during vehicle deletion: %v\n", vehicleToDelete.KKImageID.Int64, txErr)
-                // writeJSONError(w, "Failed to delete associated KK image: "+txErr.Error(), http.StatusInternalServerError)
-                // return
-                txErr = nil
-            }
-        }
-
-        txErr = database.DeleteVehicleTx(r.Context(), tx, id)
+        // Gambar STNK/KK tetap disimpan agar kendaraan bisa di-restore; job
+        // purge menghapusnya setelah masa retensi.
+        txErr = tx.Repos().Vehicles.Delete(r.Context(), id)
         if txErr != nil {
             if errors.Is(txErr, sql.ErrNoRows) || strings.Contains(txErr.Error(), "no vehicle record deleted") {
                 writeJSONError(w, "Vehicle not found", http.StatusNotFound)
@@ -578,6 +622,12 @@ func (s *Server) handleDeleteVehicle() http.HandlerFunc {
             return
         }
 
+        txErr = s.audit(r, tx.Repos(), auditChange{Action: "vehicle.delete", EntityType: "vehicle", EntityID: id, Before: vehicleToDelete})
+        if txErr != nil {
+            writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+            return
+        }
+
         txErr = tx.Commit()
         if txErr != nil {
             writeJSONError(w, "Failed to commit database transaction", http.StatusInternalServerError)
@@ -588,8 +638,64 @@ func (s *Server) handleDeleteVehicle() http.HandlerFunc {
     }
 }
 
-func (s *Server) deleteImageRecordAndFile(ctx context.Context, tx *sql.Tx, imageID int64) error {
-    imagePath, err := database.GetImageStoragePath(ctx, tx, imageID)
+// handleRestoreVehicle membatalkan soft delete kendaraan. Kendaraan milik
+// user yang masih terhapus harus di-restore lewat user-nya.
+func (s *Server) handleRestoreVehicle() http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+        if err != nil {
+            writeJSONError(w, "invalid vehicle_id format", http.StatusBadRequest)
+            return
+        }
+
+        tx, err := s.store.BeginTx(r.Context())
+        if err != nil {
+            writeJSONError(w, "Failed to start database transaction", http.StatusInternalServerError)
+            return
+        }
+        defer tx.Rollback()
+
+        if err := tx.Repos().Vehicles.Restore(r.Context(), id); err != nil {
+            if errors.Is(err, database.ErrVehicleNotFound) {
+                writeJSONError(w, "Deleted vehicle not found", http.StatusNotFound)
+            } else if errors.Is(err, database.ErrPlateTaken) {
+                writeJSONError(w, "The plate number is now registered to another vehicle", http.StatusConflict)
+            } else {
+                writeJSONError(w, "Failed to restore vehicle: "+err.Error(), http.StatusInternalServerError)
+            }
+            return
+        }
+        restored, err := tx.Repos().Vehicles.GetByID(r.Context(), id)
+        if err != nil {
+            writeJSONError(w, "Failed to retrieve restored vehicle: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+        if _, err := tx.Repos().Users.FindByID(r.Context(), restored.UserID); err != nil {
+            if errors.Is(err, database.ErrUserNotFound) {
+                writeJSONError(w, "Vehicle owner is deleted; restore the user instead", http.StatusConflict)
+            } else {
+                writeJSONError(w, "Failed to retrieve vehicle owner: "+err.Error(), http.StatusInternalServerError)
+            }
+            return
+        }
+
+        if err := s.audit(r, tx.Repos(), auditChange{Action: "vehicle.restore", EntityType: "vehicle", EntityID: id, After: restored}); err != nil {
+            writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+            return
+        }
+        response := s.toVehicleResponse(r.Context(), tx.Repos().Images, restored)
+        if err := tx.Commit(); err != nil {
+            writeJSONError(w, "Failed to commit database transaction", http.StatusInternalServerError)
+            return
+        }
+
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(response)
+    }
+}
+
+func (s *Server) deleteImageRecordAndFile(ctx context.Context, images database.ImageRepo, imageID int64) error {
+    imagePath, err := images.GetStoragePath(ctx, imageID)
     if err != nil {
         if errors.Is(err, sql.ErrNoRows) {
             return nil
@@ -597,7 +703,7 @@ func (s *Server) deleteImageRecordAndFile(ctx context.Context, tx *sql.Tx, image
         return fmt.Errorf("failed to get image path for ID %d: %w", imageID, err)
     }
 
-    err = database.DeleteImageTx(ctx, tx, imageID)
+    err = images.Delete(ctx, imageID)
     if err != nil {
         return fmt.Errorf("failed to delete image record for ID %d: %w", imageID, err)
     }
@@ -616,7 +722,7 @@ func (s *Server) deleteImageRecordAndFile(ctx context.Context, tx *sql.Tx, image
         // }
         fullDiskPath := imagePath 
 
-        if errOs := os.Remove(fullDiskPath); errOs != nil && !os.IsNotExist(errOs) {
+        if errOs := s.storage.Remove(ctx, fullDiskPath); errOs != nil {
             // Jika file fisik gagal dihapus setelah record DB berhasil dihapus, ini adalah masalah.
             // Anda bisa memilih untuk mengembalikan error ini yang akan menyebabkan rollback jika ini bagian dari transaksi yang lebih besar,
             // atau hanya log sebagai warning.
@@ -632,10 +738,18 @@ func (s *Server) RegisterVehicleRoutes(r *mux.Router) {
     r.Handle("/vehicles/plate/{plate_number}", adminOnlyMiddleware( s.handleGetVehicleByPlate())).Methods("GET")
     r.Handle("/vehicles/{id:[0-9]+}", adminOnlyMiddleware(s.handleGetVehicle())).Methods("GET")
     
-    r.HandleFunc("/vehicles", s.handleCreateVehicle()).Methods("POST")
+    r.Handle("/vehicles", s.uploadQuota(s.handleCreateVehicle())).Methods("POST")
 	r.HandleFunc("/vehicles/my", s.handleGetUserVehicles()).Methods("GET")
-    r.HandleFunc("/vehicles/{id:[0-9]+}", s.handleUpdateVehicle()).Methods("PUT")
+    r.Handle("/vehicles/{id:[0-9]+}", s.uploadQuota(s.handleUpdateVehicle())).Methods("PUT")
     r.HandleFunc("/vehicles/{id:[0-9]+}", s.handleDeleteVehicle()).Methods("DELETE")
+    r.Handle("/vehicles/{id:[0-9]+}/restore", adminOnlyMiddleware(s.handleRestoreVehicle())).Methods("POST")
+
+    r.Handle("/vehicles/transfers", s.handleListMyVehicleTransfers()).Methods("GET")
+    r.Handle("/vehicles/transfers/{id:[0-9]+}/accept", s.handleDecideVehicleTransfer(database.TransferAccepted)).Methods("POST")
+    r.Handle("/vehicles/transfers/{id:[0-9]+}/reject", s.handleDecideVehicleTransfer(database.TransferRejected)).Methods("POST")
+    r.Handle("/vehicles/transfers/{id:[0-9]+}/cancel", s.handleDecideVehicleTransfer(database.TransferCancelled)).Methods("POST")
+    r.Handle("/vehicles/{id:[0-9]+}/transfers", s.handleCreateVehicleTransfer()).Methods("POST")
+    r.Handle("/vehicles/{id:[0-9]+}/transfers", s.handleListVehicleTransfers()).Methods("GET")
 }
 
 
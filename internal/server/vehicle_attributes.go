@@ -0,0 +1,95 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+// vehicleAttributeUpdates membaca atribut terstruktur dari form kendaraan
+// menjadi map kolom untuk VehicleRepo.Update. Hanya field yang dikirim yang
+// masuk ke map; nilai kosong menghapus atribut. Bila normalized_color tidak
+// dikirim tetapi color dikirim, warna palet diturunkan dari color bila
+// dikenali.
+func vehicleAttributeUpdates(form url.Values) (map[string]interface{}, error) {
+	updates := make(map[string]interface{})
+	text := func(field string) (string, bool) {
+		val, ok := form[field]
+		if !ok || len(val) == 0 {
+			return "", false
+		}
+		return strings.TrimSpace(val[0]), true
+	}
+	for _, field := range []string{"brand", "model", "distinguishing_marks"} {
+		if val, ok := text(field); ok {
+			updates[field] = nilIfEmpty(val)
+		}
+	}
+	if val, ok := text("year"); ok {
+		if val == "" {
+			updates["year"] = nil
+		} else {
+			year, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, errors.New("year must be an integer")
+			}
+			if err := database.ValidateVehicleYear(year); err != nil {
+				return nil, err
+			}
+			updates["year"] = year
+		}
+	}
+	if val, ok := text("body_type"); ok {
+		val = strings.ToLower(val)
+		if val != "" && !database.ValidBodyType(val) {
+			return nil, fmt.Errorf("body_type must be one of %s", strings.Join(database.BodyTypes, ", "))
+		}
+		updates["body_type"] = nilIfEmpty(val)
+	}
+	if val, ok := text("normalized_color"); ok {
+		if val == "" {
+			updates["normalized_color"] = nil
+		} else {
+			c, known := database.NormalizeColor(val)
+			if !known {
+				return nil, fmt.Errorf("normalized_color must be one of %s", strings.Join(database.ColorPalette, ", "))
+			}
+			updates["normalized_color"] = c
+		}
+	} else if val, ok := text("color"); ok {
+		if c, known := database.NormalizeColor(val); known {
+			updates["normalized_color"] = c
+		}
+	}
+	return updates, nil
+}
+
+func nilIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// applyVehicleAttributes menerapkan hasil vehicleAttributeUpdates ke
+// kendaraan yang belum disimpan.
+func applyVehicleAttributes(a *database.VehicleAttributes, updates map[string]interface{}) {
+	str := func(col string) *string {
+		if s, ok := updates[col].(string); ok {
+			return &s
+		}
+		return nil
+	}
+	a.Brand = str("brand")
+	a.Model = str("model")
+	a.BodyType = str("body_type")
+	a.NormalizedColor = str("normalized_color")
+	a.DistinguishingMarks = str("distinguishing_marks")
+	if year, ok := updates["year"].(int); ok {
+		a.Year = &year
+	}
+}
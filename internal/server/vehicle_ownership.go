@@ -0,0 +1,374 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/mail"
+	"github.com/jaga-project/jaga-backend/internal/middleware"
+)
+
+// vehicleTransferTTL adalah masa berlaku permintaan pindah kepemilikan
+// sebelum penerima menerimanya.
+const vehicleTransferTTL = 7 * 24 * time.Hour
+
+type createVehicleTransferRequest struct {
+	RecipientEmail string `json:"recipient_email"`
+	Note           string `json:"note"`
+}
+
+// handleCreateVehicleTransfer dipanggil pemilik kendaraan untuk memindahkan
+// kepemilikan ke user lain. Kendaraan baru berpindah setelah penerima
+// menerima permintaan.
+func (s *Server) handleCreateVehicleTransfer() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		vehicleID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			writeJSONError(w, "invalid vehicle_id format", http.StatusBadRequest)
+			return
+		}
+		var req createVehicleTransferRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.RecipientEmail = strings.TrimSpace(req.RecipientEmail)
+		if req.RecipientEmail == "" {
+			writeJSONError(w, "recipient_email is required", http.StatusBadRequest)
+			return
+		}
+		userID, _ := ctx.Value(middleware.UserIDContextKey).(string)
+
+		tx, err := s.store.BeginTx(ctx)
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		repos := tx.Repos()
+
+		vehicle, err := repos.Vehicles.GetByID(ctx, vehicleID)
+		if err != nil {
+			if errors.Is(err, database.ErrVehicleNotFound) {
+				writeJSONError(w, "Vehicle not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to retrieve vehicle: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if vehicle.UserID != userID {
+			writeJSONError(w, "Forbidden: You can only transfer your own vehicles.", http.StatusForbidden)
+			return
+		}
+		recipient, err := repos.Users.FindByEmail(ctx, req.RecipientEmail)
+		if err != nil {
+			if errors.Is(err, database.ErrUserNotFound) {
+				writeJSONError(w, "Recipient account not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to retrieve recipient: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if recipient.UserID == userID {
+			writeJSONError(w, "You cannot transfer a vehicle to yourself", http.StatusBadRequest)
+			return
+		}
+		if recipient.EmailVerifiedAt == nil {
+			writeJSONError(w, "Recipient email address is not verified", http.StatusBadRequest)
+			return
+		}
+		if recipient.KTPVerificationStatus != database.VerificationVerified {
+			writeJSONError(w, "Recipient KTP has not been verified by an admin", http.StatusBadRequest)
+			return
+		}
+		if !s.checkNoOpenLostReports(w, r, repos, vehicleID) {
+			return
+		}
+
+		now := time.Now()
+		transfer := database.VehicleTransfer{
+			VehicleID:   vehicleID,
+			VehicleName: vehicle.VehicleName,
+			PlateNumber: vehicle.PlateNumber,
+			FromUserID:  userID,
+			ToUserID:    recipient.UserID,
+			Note:        strings.TrimSpace(req.Note),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(vehicleTransferTTL),
+		}
+		if err := repos.Transfers.Create(ctx, &transfer); err != nil {
+			if errors.Is(err, database.ErrVehicleTransferPending) {
+				writeJSONError(w, "Vehicle already has a pending transfer", http.StatusConflict)
+			} else {
+				writeJSONError(w, "Failed to create vehicle transfer: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if err := s.audit(r, repos, auditChange{Action: "vehicle_transfer.create", EntityType: "vehicle_transfer", EntityID: transfer.TransferID, After: transfer}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit database transaction", http.StatusInternalServerError)
+			return
+		}
+		s.sendMail(ctx, mail.Message{
+			To:      recipient.Email,
+			Subject: "Permintaan pindah kepemilikan kendaraan",
+			Body: fmt.Sprintf("Halo %s,\n\nAnda menerima permintaan pindah kepemilikan kendaraan %s (%s). Terima atau tolak permintaan ini di aplikasi JAGA sebelum %s.\n",
+				recipient.Name, transfer.VehicleName, transfer.PlateNumber, transfer.ExpiresAt.Format("2 January 2006 15:04")),
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(transfer)
+	}
+}
+
+// handleListMyVehicleTransfers mengembalikan permintaan yang dikirim atau
+// diterima user yang login.
+func (s *Server) handleListMyVehicleTransfers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := r.Context().Value(middleware.UserIDContextKey).(string)
+		list, err := s.repos.Transfers.ListByUserID(r.Context(), userID)
+		if err != nil {
+			writeJSONError(w, "Failed to list vehicle transfers: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeVehicleTransfers(w, list)
+	}
+}
+
+// handleListVehicleTransfers mengembalikan riwayat pindah kepemilikan
+// sebuah kendaraan untuk pemilik saat ini atau admin.
+func (s *Server) handleListVehicleTransfers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		vehicleID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			writeJSONError(w, "invalid vehicle_id format", http.StatusBadRequest)
+			return
+		}
+		vehicle, err := s.repos.Vehicles.GetByID(ctx, vehicleID)
+		if err != nil {
+			if errors.Is(err, database.ErrVehicleNotFound) {
+				writeJSONError(w, "Vehicle not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to retrieve vehicle: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		userID, _ := ctx.Value(middleware.UserIDContextKey).(string)
+		isAdmin, _ := ctx.Value(middleware.AdminStatusContextKey).(bool)
+		if !isAdmin && vehicle.UserID != userID {
+			writeJSONError(w, "Forbidden: You can only view transfers of your own vehicles.", http.StatusForbidden)
+			return
+		}
+		list, err := s.repos.Transfers.ListByVehicleID(ctx, vehicleID)
+		if err != nil {
+			writeJSONError(w, "Failed to list vehicle transfers: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeVehicleTransfers(w, list)
+	}
+}
+
+func writeVehicleTransfers(w http.ResponseWriter, list []database.VehicleTransfer) {
+	now := time.Now()
+	for i := range list {
+		list[i].Status = list[i].EffectiveStatus(now)
+	}
+	if list == nil {
+		list = []database.VehicleTransfer{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// handleDecideVehicleTransfer menerima atau menolak permintaan (oleh
+// penerima) atau membatalkannya (oleh pengirim). Saat diterima, kendaraan
+// berpindah ke penerima dan STNK/KK pemilik lama dihapus.
+func (s *Server) handleDecideVehicleTransfer(status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			writeJSONError(w, "Invalid transfer ID", http.StatusBadRequest)
+			return
+		}
+		userID, _ := ctx.Value(middleware.UserIDContextKey).(string)
+
+		tx, err := s.store.BeginTx(ctx)
+		if err != nil {
+			writeJSONError(w, "Failed to start database transaction", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		repos := tx.Repos()
+
+		existing, err := repos.Transfers.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, database.ErrVehicleTransferNotFound) {
+				writeJSONError(w, "Vehicle transfer not found", http.StatusNotFound)
+			} else {
+				writeJSONError(w, "Failed to retrieve vehicle transfer: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		actor, notify := existing.ToUserID, existing.FromUserID
+		if status == database.TransferCancelled {
+			actor, notify = existing.FromUserID, existing.ToUserID
+		}
+		if actor != userID {
+			writeJSONError(w, "Forbidden: You are not allowed to decide this transfer.", http.StatusForbidden)
+			return
+		}
+		now := time.Now()
+		if existing.EffectiveStatus(now) == database.TransferExpired {
+			writeJSONError(w, "Vehicle transfer has expired", http.StatusConflict)
+			return
+		}
+		if err := repos.Transfers.Decide(ctx, id, status, now); err != nil {
+			if errors.Is(err, database.ErrVehicleTransferDecided) {
+				writeJSONError(w, "Vehicle transfer has already been decided", http.StatusConflict)
+			} else {
+				writeJSONError(w, "Failed to update vehicle transfer: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		var paths []string
+		if status == database.TransferAccepted {
+			// Laporan bisa dibuat setelah permintaan dikirim.
+			if !s.checkNoOpenLostReports(w, r, repos, existing.VehicleID) {
+				return
+			}
+			before, err := repos.Vehicles.GetByID(ctx, existing.VehicleID)
+			if err != nil && !errors.Is(err, database.ErrVehicleNotFound) {
+				writeJSONError(w, "Failed to retrieve vehicle: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			imageIDs, err := repos.Vehicles.Transfer(ctx, existing.VehicleID, existing.FromUserID, existing.ToUserID)
+			if err != nil {
+				if errors.Is(err, database.ErrVehicleNotFound) {
+					writeJSONError(w, "Vehicle is no longer owned by the sender", http.StatusConflict)
+				} else {
+					writeJSONError(w, "Failed to transfer vehicle: "+err.Error(), http.StatusInternalServerError)
+				}
+				return
+			}
+			if paths, err = deleteImageRecords(ctx, repos.Images, imageIDs); err != nil {
+				writeJSONError(w, "Failed to delete previous owner's documents: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			after, err := repos.Vehicles.GetByID(ctx, existing.VehicleID)
+			if err != nil {
+				writeJSONError(w, "Failed to retrieve transferred vehicle: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if err := s.audit(r, repos, auditChange{Action: "vehicle.transfer", EntityType: "vehicle", EntityID: existing.VehicleID, Before: before, After: after}); err != nil {
+				writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		decided, err := repos.Transfers.GetByID(ctx, id)
+		if err != nil {
+			writeJSONError(w, "Failed to retrieve vehicle transfer: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		action := "vehicle_transfer." + map[string]string{
+			database.TransferAccepted:  "accept",
+			database.TransferRejected:  "reject",
+			database.TransferCancelled: "cancel",
+		}[status]
+		if err := s.audit(r, repos, auditChange{Action: action, EntityType: "vehicle_transfer", EntityID: id, Before: existing, After: decided}); err != nil {
+			writeJSONError(w, "Failed to write audit log", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeJSONError(w, "Failed to commit database transaction", http.StatusInternalServerError)
+			return
+		}
+		s.removeImageFiles(ctx, paths)
+		s.sendTransferDecisionEmail(ctx, notify, decided)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(decided)
+	}
+}
+
+// checkNoOpenLostReports menolak pindah kepemilikan selama kendaraan masih
+// punya laporan kehilangan yang belum ditemukan. Response error sudah
+// ditulis bila hasilnya false.
+func (s *Server) checkNoOpenLostReports(w http.ResponseWriter, r *http.Request, repos database.Repositories, vehicleID int64) bool {
+	n, err := repos.LostReports.CountOpenByVehicleID(r.Context(), vehicleID)
+	if err != nil {
+		writeJSONError(w, "Failed to retrieve lost reports: "+err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if n > 0 {
+		writeJSONError(w, "Vehicle has an open lost report and cannot be transferred", http.StatusConflict)
+		return false
+	}
+	return true
+}
+
+func (s *Server) sendTransferDecisionEmail(ctx context.Context, userID string, t *database.VehicleTransfer) {
+	user, err := s.repos.Users.FindByID(ctx, userID)
+	if err != nil {
+		return
+	}
+	verb := map[string]string{
+		database.TransferAccepted:  "diterima",
+		database.TransferRejected:  "ditolak",
+		database.TransferCancelled: "dibatalkan",
+	}[t.Status]
+	s.sendMail(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Permintaan pindah kepemilikan kendaraan " + verb,
+		Body:    fmt.Sprintf("Halo %s,\n\nPermintaan pindah kepemilikan kendaraan %s (%s) telah %s.\n", user.Name, t.VehicleName, t.PlateNumber, verb),
+	})
+}
+
+// checkReportableVehicle memastikan kendaraan milik userID dan sudah
+// diverifikasi admin, begitu juga KTP pelapornya, sebelum dilaporkan
+// hilang. Bila tidak, mengembalikan
+// status HTTP beserta pesan untuk client.
+func (s *Server) checkReportableVehicle(ctx context.Context, vehicleID int, userID string) (int, string) {
+	v, err := s.repos.Vehicles.GetByID(ctx, int64(vehicleID))
+	if err != nil {
+		if errors.Is(err, database.ErrVehicleNotFound) {
+			return http.StatusBadRequest, "Vehicle not found"
+		}
+		return http.StatusInternalServerError, "Failed to retrieve vehicle: " + err.Error()
+	}
+	if v.UserID != userID {
+		return http.StatusForbidden, "Forbidden: You can only report your own vehicles."
+	}
+	if v.VerificationStatus != database.VerificationVerified {
+		return http.StatusForbidden, "Vehicle must be verified by an admin before it can be reported lost"
+	}
+	u, err := s.repos.Users.FindByID(ctx, userID)
+	if err != nil {
+		return http.StatusInternalServerError, "Failed to retrieve user: " + err.Error()
+	}
+	if u.KTPVerificationStatus != database.VerificationVerified {
+		return http.StatusForbidden, "KTP must be verified by an admin before a vehicle can be reported lost"
+	}
+	return 0, ""
+}
+
+// RegisterAdminVehicleRoutes harus didaftarkan sebelum RegisterAdminRoutes.
+func (s *Server) RegisterAdminVehicleRoutes(r *mux.Router) {
+	r.Handle("/vehicles/{id:[0-9]+}/verification", s.handleVerifyDocument(database.DocumentSTNK)).Methods("POST")
+}
@@ -0,0 +1,87 @@
+// Package storage menyimpan file upload (KTP, STNK, KK, bukti, hasil deteksi).
+// Path yang dikembalikan Save adalah nilai yang dicatat di kolom
+// images.storage_path.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type Storage interface {
+	// Save menulis isi src ke file bernama name dan mengembalikan path
+	// penyimpanan beserta jumlah byte yang ditulis.
+	Save(ctx context.Context, name string, src io.Reader) (string, int64, error)
+	// Open membuka file yang disimpan di path untuk dibaca. Error untuk file
+	// yang tidak ada memenuhi errors.Is(err, fs.ErrNotExist).
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+	// Remove menghapus file. File yang sudah tidak ada tidak dianggap error.
+	Remove(ctx context.Context, path string) error
+	// Ping memastikan backend bisa ditulisi; dipakai oleh /readyz.
+	Ping(ctx context.Context) error
+}
+
+// Local menyimpan file di direktori lokal.
+type Local struct {
+	dir string
+}
+
+func NewLocal(dir string) *Local {
+	return &Local{dir: dir}
+}
+
+func (l *Local) Save(ctx context.Context, name string, src io.Reader) (string, int64, error) {
+	if err := os.MkdirAll(l.dir, os.ModePerm); err != nil {
+		return "", 0, fmt.Errorf("failed to create upload directory '%s': %w", l.dir, err)
+	}
+
+	path := filepath.Join(l.dir, name)
+	dst, err := os.Create(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create destination file '%s': %w", path, err)
+	}
+	defer dst.Close()
+
+	n, err := io.Copy(dst, src)
+	if err != nil {
+		os.Remove(path)
+		return "", 0, fmt.Errorf("failed to copy file content to '%s': %w", path, err)
+	}
+	return filepath.ToSlash(path), n, nil
+}
+
+func (l *Local) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	return os.Open(filepath.Clean(path))
+}
+
+func (l *Local) Remove(ctx context.Context, path string) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.Remove(filepath.Clean(path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) Ping(ctx context.Context) error {
+	return CheckWritableDir(l.dir)
+}
+
+// CheckWritableDir membuat dir bila belum ada lalu mencoba menulis dan
+// menghapus file sementara di dalamnya.
+func CheckWritableDir(dir string) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".probe-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
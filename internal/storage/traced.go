@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"io"
+
+	"github.com/jaga-project/jaga-backend/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Traced membungkus Storage sehingga setiap operasi tercatat sebagai span.
+func Traced(s Storage) Storage {
+	return traced{next: s}
+}
+
+type traced struct {
+	next Storage
+}
+
+func (t traced) Save(ctx context.Context, name string, src io.Reader) (string, int64, error) {
+	ctx, span := tracing.Start(ctx, "storage save", attribute.String("storage.name", name))
+	path, n, err := t.next.Save(ctx, name, src)
+	span.SetAttributes(attribute.String("storage.path", path), attribute.Int64("storage.bytes", n))
+	tracing.End(span, err)
+	return path, n, err
+}
+
+func (t traced) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	ctx, span := tracing.Start(ctx, "storage open", attribute.String("storage.path", path))
+	rc, err := t.next.Open(ctx, path)
+	tracing.End(span, err)
+	return rc, err
+}
+
+func (t traced) Remove(ctx context.Context, path string) error {
+	ctx, span := tracing.Start(ctx, "storage remove", attribute.String("storage.path", path))
+	err := t.next.Remove(ctx, path)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) Ping(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "storage ping")
+	err := t.next.Ping(ctx)
+	tracing.End(span, err)
+	return err
+}
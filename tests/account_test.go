@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/server"
+)
+
+// lastMailToken mengambil token dari link di email terakhir yang terkirim ke
+// alamat tersebut.
+func (f *fixture) lastMailToken(to string) string {
+	f.t.Helper()
+	msgs := f.mail.Messages()
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].To != to {
+			continue
+		}
+		_, rest, ok := strings.Cut(msgs[i].Body, "?token=")
+		if !ok {
+			f.t.Fatalf("email %q has no token link", msgs[i].Subject)
+		}
+		raw, _, _ := strings.Cut(rest, "\n")
+		token, err := url.QueryUnescape(raw)
+		if err != nil {
+			f.t.Fatal(err)
+		}
+		return token
+	}
+	f.t.Fatalf("no email sent to %s", to)
+	return ""
+}
+
+func (f *fixture) postLostReport(token string, vehicleID int64) *httptest.ResponseRecorder {
+	f.t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("vehicle_id", strconv.FormatInt(vehicleID, 10))
+	mw.WriteField("address", "Jl. Thamrin")
+	mw.WriteField("timestamp", time.Now().Add(-time.Hour).UTC().Format(time.RFC3339))
+	fw, _ := mw.CreateFormFile("motor_evidence_image", "motor.png")
+	fw.Write(pngBytes(f.t))
+	mw.Close()
+	req := httptest.NewRequest("POST", "/api/lost_reports", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRegistrationPasswordPolicy(t *testing.T) {
+	f := newFixture(t)
+	tests := []struct {
+		name     string
+		password string
+		want     int
+	}{
+		{"too short", "abc12", http.StatusBadRequest},
+		{"breached", "Password123", http.StatusBadRequest},
+		{"contains email", "dewi-motor-2024", http.StatusBadRequest},
+		{"too long", strings.Repeat("x", 73), http.StatusBadRequest},
+		{"ok", "kuda-lari-pagi", http.StatusCreated},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := f.doMultipart("POST", "/users", "", map[string]string{
+				"name": "Dewi", "email": "dewi@example.com", "password": tt.password, "nik": "nik-" + strconv.Itoa(i),
+			})
+			expectStatus(t, rec, tt.want)
+		})
+	}
+}
+
+func TestEmailVerificationGatesLostReports(t *testing.T) {
+	f := newFixture(t)
+	rec := f.doMultipart("POST", "/users", "", map[string]string{
+		"name": "Dewi", "email": "dewi@example.com", "password": testPassword, "nik": "3171",
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	var created database.User
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if created.EmailVerifiedAt != nil {
+		t.Fatal("new user must start unverified")
+	}
+
+	rec = f.do("POST", "/auth/login", "", map[string]string{"email": "dewi@example.com", "password": testPassword})
+	expectStatus(t, rec, http.StatusOK)
+	var login server.LoginResponse
+	json.NewDecoder(rec.Body).Decode(&login)
+	if login.EmailVerified {
+		t.Error("login reports email_verified = true for unverified user")
+	}
+
+	vid := f.createVehicle(created.UserID)
+	// Gerbang KTP diuji di document_verification_test.go.
+	if err := f.store.Repos().Users.SetKTPVerification(context.Background(), created.UserID, database.VerificationVerified, "", "", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(t, f.postLostReport(login.Token, vid), http.StatusForbidden)
+
+	// Kirim ulang membatalkan link pertama.
+	first := f.lastMailToken("dewi@example.com")
+	expectStatus(t, f.do("POST", "/api/users/me/verification-email", login.Token, nil), http.StatusAccepted)
+	second := f.lastMailToken("dewi@example.com")
+	expectStatus(t, f.do("POST", "/auth/verify-email", "", map[string]string{"token": first}), http.StatusBadRequest)
+
+	expectStatus(t, f.do("POST", "/auth/verify-email", "", map[string]string{"token": second}), http.StatusOK)
+	expectStatus(t, f.do("POST", "/auth/verify-email", "", map[string]string{"token": second}), http.StatusBadRequest)
+	expectStatus(t, f.do("POST", "/api/users/me/verification-email", login.Token, nil), http.StatusConflict)
+	expectStatus(t, f.postLostReport(login.Token, vid), http.StatusCreated)
+
+	// Mengganti email mewajibkan verifikasi ulang.
+	rec = f.do("PUT", "/api/users/"+created.UserID, login.Token, map[string]string{"email": "dewi.baru@example.com"})
+	expectStatus(t, rec, http.StatusOK)
+	var updated database.User
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if updated.EmailVerifiedAt != nil {
+		t.Error("email change kept the old verification")
+	}
+	f.lastMailToken("dewi.baru@example.com")
+
+	// email_verified_at tidak bisa di-set langsung oleh user.
+	rec = f.do("PUT", "/api/users/"+created.UserID, login.Token, map[string]interface{}{"name": "Dewi", "email_verified_at": time.Now()})
+	expectStatus(t, rec, http.StatusOK)
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if updated.EmailVerifiedAt != nil {
+		t.Error("user verified their own email through PUT")
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	cfg := testConfig()
+	cfg.RateLimit.Login.Burst = 20
+	f := newFixtureWithConfig(t, cfg)
+	f.createUser("u1", "u1@example.com", false)
+
+	forgot := func(email string) {
+		t.Helper()
+		expectStatus(t, f.do("POST", "/auth/forgot-password", "", map[string]string{"email": email}), http.StatusAccepted)
+	}
+	reset := func(token, password string) *httptest.ResponseRecorder {
+		return f.do("POST", "/auth/reset-password", "", map[string]string{"token": token, "password": password})
+	}
+
+	// Email tak terdaftar dijawab sama tanpa mengirim email.
+	forgot("nobody@example.com")
+	if n := len(f.mail.Messages()); n != 0 {
+		t.Fatalf("sent %d emails for unknown address", n)
+	}
+
+	forgot("u1@example.com")
+	stale := f.lastMailToken("u1@example.com")
+	forgot("u1@example.com")
+	token := f.lastMailToken("u1@example.com")
+	expectStatus(t, reset(stale, "kuda-lari-pagi"), http.StatusBadRequest)
+
+	// Password yang ditolak kebijakan tidak menghanguskan token.
+	expectStatus(t, reset(token, "qwerty123"), http.StatusBadRequest)
+	expectStatus(t, reset(token, "kuda-lari-pagi"), http.StatusOK)
+	expectStatus(t, reset(token, "kuda-lari-siang"), http.StatusBadRequest)
+
+	expectStatus(t, f.do("POST", "/auth/login", "", map[string]string{"email": "u1@example.com", "password": testPassword}), http.StatusUnauthorized)
+	expectStatus(t, f.do("POST", "/auth/login", "", map[string]string{"email": "u1@example.com", "password": "kuda-lari-pagi"}), http.StatusOK)
+}
+
+func TestPasswordResetTokenExpires(t *testing.T) {
+	cfg := testConfig()
+	cfg.Auth.PasswordResetTTL = time.Nanosecond
+	f := newFixtureWithConfig(t, cfg)
+	f.createUser("u1", "u1@example.com", false)
+
+	expectStatus(t, f.do("POST", "/auth/forgot-password", "", map[string]string{"email": "u1@example.com"}), http.StatusAccepted)
+	token := f.lastMailToken("u1@example.com")
+	time.Sleep(time.Millisecond)
+	expectStatus(t, f.do("POST", "/auth/reset-password", "", map[string]string{"token": token, "password": "kuda-lari-pagi"}), http.StatusBadRequest)
+}
@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/server"
+)
+
+func TestAnalysisRunLifecycle(t *testing.T) {
+	f := newFixture(t)
+	owner := f.createUser("u1", "u1@example.com", false)
+	admin := f.createUser("a1", "a1@example.com", true)
+	vehicleID := f.createVehicle("u1")
+
+	rec := f.doMultipart("POST", "/api/lost_reports", owner, map[string]string{
+		"vehicle_id": strconv.FormatInt(vehicleID, 10),
+		"address":    "Jl. Thamrin",
+		"latitude":   "-6.1862",
+		"longitude":  "106.8281",
+		"timestamp":  time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	var created server.LostReportResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	lostID := created.LostID
+	resultPath := "/api/results/" + strconv.Itoa(lostID)
+	runsPath := "/api/admins/lost_reports/" + strconv.Itoa(lostID) + "/analysis-runs"
+
+	result := func() server.ResultResponse {
+		t.Helper()
+		rec := f.do("GET", resultPath, owner, nil)
+		expectStatus(t, rec, http.StatusOK)
+		var res server.ResultResponse
+		json.NewDecoder(rec.Body).Decode(&res)
+		return res
+	}
+	claim := func() *server.AnalysisJob {
+		t.Helper()
+		rec := f.do("POST", "/api/admins/analysis-runs/claim", admin, map[string]string{"scorer_version": "reid-v2"})
+		if rec.Code == http.StatusNoContent {
+			return nil
+		}
+		expectStatus(t, rec, http.StatusOK)
+		var job server.AnalysisJob
+		json.NewDecoder(rec.Body).Decode(&job)
+		return &job
+	}
+
+	// Laporan baru langsung diantrekan dengan parameter default.
+	res := result()
+	if res.AnalysisStatus != database.AnalysisRunQueued || res.AnalysisRun == nil || res.AnalysisRun.RadiusKm != 5 || res.AnalysisRun.WindowHours != 24 {
+		t.Fatalf("new report result = %+v", res)
+	}
+
+	expectStatus(t, f.do("POST", "/api/admins/analysis-runs/claim", owner, map[string]string{"scorer_version": "reid-v2"}), http.StatusForbidden)
+	expectStatus(t, f.do("POST", "/api/admins/analysis-runs/claim", admin, map[string]string{}), http.StatusBadRequest)
+	job := claim()
+	if job == nil || job.LostID != lostID || job.Run.Status != database.AnalysisRunRunning || job.Run.ScorerVersion != "reid-v2" ||
+		job.Latitude == nil || !job.WindowEnd.Equal(job.WindowStart.Add(24*time.Hour)) {
+		t.Fatalf("claimed job = %+v", job)
+	}
+	if job := claim(); job != nil {
+		t.Fatalf("second claim = %+v, want empty queue", job)
+	}
+	if res := result(); res.AnalysisStatus != database.AnalysisRunRunning || res.LostReportStatus != database.StatusLostReportSedangDiproses {
+		t.Errorf("running result = %+v", res)
+	}
+	expectStatus(t, f.do("POST", runsPath, admin, map[string]float64{"radius_km": 2}), http.StatusConflict)
+
+	finishPath := func(id int64, action string) string {
+		return "/api/admins/analysis-runs/" + strconv.FormatInt(id, 10) + "/" + action
+	}
+	expectStatus(t, f.do("POST", finishPath(job.Run.RunID, "complete"), admin, map[string]int{}), http.StatusBadRequest)
+	expectStatus(t, f.do("POST", finishPath(job.Run.RunID, "fail"), admin, map[string]string{}), http.StatusBadRequest)
+	expectStatus(t, f.do("POST", finishPath(9999, "complete"), admin, map[string]int{"detections_scanned": 1}), http.StatusNotFound)
+	expectStatus(t, f.do("POST", finishPath(job.Run.RunID, "complete"), admin, map[string]int{"detections_scanned": 42}), http.StatusOK)
+	expectStatus(t, f.do("POST", finishPath(job.Run.RunID, "complete"), admin, map[string]int{"detections_scanned": 42}), http.StatusConflict)
+
+	// Selesai tanpa suspect berbeda dengan belum dianalisis.
+	res = result()
+	if res.AnalysisStatus != database.AnalysisRunCompleted || len(res.Suspects) != 0 || res.AnalysisRun.DetectionsScanned == nil ||
+		*res.AnalysisRun.DetectionsScanned != 42 || res.AnalysisRun.FinishedAt == nil {
+		t.Errorf("completed result = %+v", res)
+	}
+
+	// Re-run memakai parameter baru; yang tidak diisi diwarisi dari run
+	// sebelumnya.
+	expectStatus(t, f.do("POST", runsPath, owner, map[string]float64{"radius_km": 2}), http.StatusForbidden)
+	expectStatus(t, f.do("POST", runsPath, admin, map[string]float64{"radius_km": 100}), http.StatusBadRequest)
+	expectStatus(t, f.do("POST", runsPath, admin, map[string]int{"window_hours": 0}), http.StatusBadRequest)
+	expectStatus(t, f.do("POST", "/api/admins/lost_reports/9999/analysis-runs", admin, nil), http.StatusNotFound)
+	rec = f.do("POST", runsPath, admin, map[string]float64{"radius_km": 2})
+	expectStatus(t, rec, http.StatusCreated)
+	var rerun database.AnalysisRun
+	json.NewDecoder(rec.Body).Decode(&rerun)
+	if rerun.Status != database.AnalysisRunQueued || rerun.RadiusKm != 2 || rerun.WindowHours != 24 || rerun.RequestedBy == nil || *rerun.RequestedBy != "a1" {
+		t.Errorf("rerun = %+v", rerun)
+	}
+	if job := claim(); job == nil || job.Run.RunID != rerun.RunID {
+		t.Fatalf("claimed rerun = %+v", job)
+	}
+	expectStatus(t, f.do("POST", finishPath(rerun.RunID, "fail"), admin, map[string]string{"error": "camera feed unavailable"}), http.StatusOK)
+	if res := result(); res.AnalysisStatus != database.AnalysisRunFailed || res.AnalysisRun.Error != "camera feed unavailable" {
+		t.Errorf("failed result = %+v", res)
+	}
+
+	rec = f.do("GET", runsPath, admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var runs []database.AnalysisRun
+	json.NewDecoder(rec.Body).Decode(&runs)
+	if len(runs) != 2 || runs[0].RunID != rerun.RunID || runs[1].Status != database.AnalysisRunCompleted {
+		t.Errorf("runs = %+v", runs)
+	}
+	if n := len(f.auditEntries(admin, "?action=analysis_run.finish")); n != 2 {
+		t.Errorf("got %d analysis_run.finish entries, want 2", n)
+	}
+}
+
+func TestAnalysisRunClaimSkipsDeletedReports(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	f.createUser("u1", "u1@example.com", false)
+	vehicleID := f.createVehicle("u1")
+	ctx := context.Background()
+	repos := f.store.Repos()
+
+	var lostIDs []int
+	for i := 0; i < 2; i++ {
+		lostID := f.createLostReport("u1", vehicleID)
+		if err := repos.AnalysisRuns.Create(ctx, &database.AnalysisRun{LostID: lostID, RadiusKm: 5, WindowHours: 24}); err != nil {
+			t.Fatal(err)
+		}
+		lostIDs = append(lostIDs, lostID)
+	}
+	// Satu laporan hanya boleh punya satu run aktif.
+	if err := repos.AnalysisRuns.Create(ctx, &database.AnalysisRun{LostID: lostIDs[1], RadiusKm: 5, WindowHours: 24}); err == nil {
+		t.Error("second active run for the same report was accepted")
+	}
+	if err := repos.LostReports.Delete(ctx, lostIDs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := f.do("POST", "/api/admins/analysis-runs/claim", admin, map[string]string{"scorer_version": "reid-v2"})
+	expectStatus(t, rec, http.StatusOK)
+	var job server.AnalysisJob
+	json.NewDecoder(rec.Body).Decode(&job)
+	if job.LostID != lostIDs[1] {
+		t.Errorf("claimed lost_id = %d, want %d", job.LostID, lostIDs[1])
+	}
+	expectStatus(t, f.do("POST", "/api/admins/analysis-runs/claim", admin, map[string]string{"scorer_version": "reid-v2"}), http.StatusNoContent)
+}
@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+func (f *fixture) auditEntries(token, query string) []database.AuditEntry {
+	f.t.Helper()
+	rec := f.do("GET", "/api/admins/audit-log"+query, token, nil)
+	expectStatus(f.t, rec, http.StatusOK)
+	var resp struct {
+		Entries []database.AuditEntry `json:"entries"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		f.t.Fatal(err)
+	}
+	return resp.Entries
+}
+
+func TestAuditLogRecordsChanges(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	f.createUser("u1", "u1@example.com", false)
+	lostID := f.createLostReport("u1", f.createVehicle("u1"))
+
+	rec := f.do("PUT", "/api/lost_reports/"+strconv.Itoa(lostID), admin, map[string]string{"status": database.StatusLostReportSedangDiproses})
+	expectStatus(t, rec, http.StatusOK)
+	requestID := rec.Header().Get("X-Request-ID")
+
+	entries := f.auditEntries(admin, "?entity_type=lost_report")
+	if len(entries) != 1 {
+		t.Fatalf("got %d lost_report entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Action != "lost_report.status_change" || e.EntityID != strconv.Itoa(lostID) {
+		t.Errorf("entry = %s %s", e.Action, e.EntityID)
+	}
+	if e.ActorUserID == nil || *e.ActorUserID != "a1" || e.AuthMethod != database.AuditAuthJWT || e.APIKeyID != nil {
+		t.Errorf("actor = %v via %s", e.ActorUserID, e.AuthMethod)
+	}
+	if e.RequestID != requestID || e.IP == "" {
+		t.Errorf("request_id = %q (want %q), ip = %q", e.RequestID, requestID, e.IP)
+	}
+	// Hanya field yang berubah yang disimpan.
+	if string(e.Before) != `{"status":"BELUM_DIPROSES"}` || string(e.After) != `{"status":"SEDANG_DIPROSES"}` {
+		t.Errorf("diff = %s -> %s", e.Before, e.After)
+	}
+
+	expectStatus(t, f.do("DELETE", "/api/users/u1", admin, nil), http.StatusNoContent)
+	entries = f.auditEntries(admin, "?action=user.delete&entity_id=u1")
+	if len(entries) != 1 {
+		t.Fatalf("got %d user.delete entries, want 1", len(entries))
+	}
+	var before map[string]interface{}
+	json.Unmarshal(entries[0].Before, &before)
+	if before["email"] != "[redacted]" || before["user_id"] != "u1" || before["password"] != "[redacted]" || before["nik"] != "[redacted]" {
+		t.Errorf("deleted user snapshot = %v", before)
+	}
+	if entries[0].After != nil {
+		t.Errorf("delete entry has after = %s", entries[0].After)
+	}
+}
+
+func TestAuditLogAPIKeyAndFallback(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	if err := f.store.AddAPIKey("a1", "kunci-admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", "/api/cameras", strings.NewReader(`{"name":"Simpang Lima","ip_camera":"10.0.0.5","latitude":-6.99,"longitude":110.42}`))
+	req.Header.Set("X-API-Key", "kunci-admin")
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	expectStatus(t, rec, http.StatusCreated)
+
+	entries := f.auditEntries(admin, "?action=camera.create")
+	if len(entries) != 1 {
+		t.Fatalf("got %d camera.create entries, want 1", len(entries))
+	}
+	if e := entries[0]; e.AuthMethod != database.AuditAuthAPIKey || e.APIKeyID == nil || *e.APIKeyID != 1 {
+		t.Errorf("auth = %s key %v", e.AuthMethod, e.APIKeyID)
+	}
+
+	// Endpoint admin tanpa entri rinci tetap tercatat oleh middleware.
+	suspects := []database.Suspect{{DetectedID: 1, LostID: 1}}
+	expectStatus(t, f.do("POST", "/api/suspects/batch", admin, suspects), http.StatusCreated)
+	entries = f.auditEntries(admin, "?action=admin.request")
+	if len(entries) != 1 || entries[0].EntityID != "POST /api/suspects/batch" {
+		t.Fatalf("fallback entries = %+v", entries)
+	}
+
+	// Request yang gagal tidak dicatat.
+	expectStatus(t, f.do("DELETE", "/api/cameras/999", admin, nil), http.StatusNotFound)
+	if n := len(f.auditEntries(admin, "")); n != 2 {
+		t.Errorf("got %d entries, want 2", n)
+	}
+}
+
+func TestAuditLogQueryExportAndVerify(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	user := f.createUser("u1", "u1@example.com", false)
+	for _, id := range []string{"u2", "u3", "u4"} {
+		f.createUser(id, id+"@example.com", false)
+		expectStatus(t, f.do("DELETE", "/api/users/"+id, admin, nil), http.StatusNoContent)
+	}
+
+	expectStatus(t, f.do("GET", "/api/admins/audit-log", user, nil), http.StatusForbidden)
+	expectStatus(t, f.do("GET", "/api/admins/audit-log?limit=0", admin, nil), http.StatusBadRequest)
+	expectStatus(t, f.do("GET", "/api/admins/audit-log?from=kemarin", admin, nil), http.StatusBadRequest)
+
+	page := f.auditEntries(admin, "?limit=2&offset=1")
+	if len(page) != 2 || page[0].EntityID != "u3" || page[1].EntityID != "u2" {
+		t.Errorf("page = %+v", page)
+	}
+	if n := len(f.auditEntries(admin, "?actor_user_id=u1")); n != 0 {
+		t.Errorf("actor filter returned %d entries", n)
+	}
+	if n := len(f.auditEntries(admin, "?from=2000-01-01T00:00:00Z&to=2000-01-02T00:00:00Z")); n != 0 {
+		t.Errorf("time filter returned %d entries", n)
+	}
+
+	rec := f.do("GET", "/api/admins/audit-log/export?action=user.delete", admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if ct := rec.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Errorf("content type = %q", ct)
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || rows[0][0] != "id" || rows[1][5] != "user.delete" || rows[1][2] != "a1" {
+		t.Errorf("csv rows = %v", rows)
+	}
+
+	verify := func() map[string]interface{} {
+		rec := f.do("GET", "/api/admins/audit-log/verify", admin, nil)
+		expectStatus(t, rec, http.StatusOK)
+		var result map[string]interface{}
+		json.NewDecoder(rec.Body).Decode(&result)
+		return result
+	}
+	if result := verify(); result["valid"] != true || result["checked"] != float64(3) {
+		t.Fatalf("verify = %v", result)
+	}
+	entries := f.auditEntries(admin, "")
+	for i := 0; i < len(entries)-1; i++ {
+		if entries[i].PrevHash != entries[i+1].Hash {
+			t.Errorf("entry %d does not chain to entry %d", entries[i].ID, entries[i+1].ID)
+		}
+	}
+
+	f.store.TamperAuditEntry(2, "user.update")
+	if result := verify(); result["valid"] != false || result["broken_at_id"] != float64(2) {
+		t.Errorf("verify after tampering = %v", result)
+	}
+}
@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/config"
+)
+
+// clearConfigEnv memastikan environment proses tidak memengaruhi test.
+func clearConfigEnv(t *testing.T) {
+	t.Helper()
+	for _, k := range []string{
+		"PORT", "CORS_ALLOWED_ORIGINS", "POSTGRES_URI", "DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS",
+		"DB_CONN_MAX_LIFETIME", "HTTP_READ_TIMEOUT", "HTTP_WRITE_TIMEOUT", "HTTP_IDLE_TIMEOUT",
+		"SHUTDOWN_TIMEOUT", "JWT_SECRET", "JWT_TTL", "UPLOAD_DIR", "JAGA_CONFIG",
+		"FIELD_ENCRYPTION_KEYS", "FIELD_ENCRYPTION_ACTIVE_KEY", "BLIND_INDEX_KEY",
+	} {
+		t.Setenv(k, "")
+	}
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	clearConfigEnv(t)
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	yml := "server:\n  port: 7000\n  write_timeout: 45s\ndatabase:\n  uri: postgres://file\n  max_open_conns: 20\nauth:\n  jwt_secret: from-file\n" +
+		"encryption:\n  keys: {k1: amFnYS10ZXN0LWZpZWxkLWtleS0xLTAxMjM0NTY3ODk=}\n  blind_index_key: amFnYS10ZXN0LWJsaW5kLWluZGV4LTAxMjM0NTY3ODk=\n"
+	if err := os.WriteFile(path, []byte(yml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PORT", "7100")
+	t.Setenv("DB_MAX_IDLE_CONNS", "8")
+
+	cfg, err := config.Load([]string{"-config", path, "-port", "7200"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 7200 {
+		t.Errorf("port = %d, want flag value 7200", cfg.Server.Port)
+	}
+	if cfg.Server.WriteTimeout != 45*time.Second {
+		t.Errorf("write timeout = %s, want 45s from file", cfg.Server.WriteTimeout)
+	}
+	if cfg.Server.ReadTimeout != 10*time.Second {
+		t.Errorf("read timeout = %s, want default 10s", cfg.Server.ReadTimeout)
+	}
+	if cfg.Database.MaxOpenConns != 20 || cfg.Database.MaxIdleConns != 8 {
+		t.Errorf("pool = %d/%d, want 20/8", cfg.Database.MaxOpenConns, cfg.Database.MaxIdleConns)
+	}
+	if cfg.Auth.JWTSecret != "from-file" {
+		t.Errorf("jwt secret = %q, want from-file", cfg.Auth.JWTSecret)
+	}
+}
+
+func TestConfigValidationReportsAllErrors(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DB_MAX_IDLE_CONNS", "50")
+
+	_, err := config.Load(nil)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"server.port", "database.uri", "database.max_idle_conns", "auth.jwt_secret", "encryption.keys"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %s: %v", want, err)
+		}
+	}
+}
+
+func TestConfigKeyRotationNeedsOnlyDatabaseAndKeys(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("POSTGRES_URI", "postgres://rotate")
+
+	_, err := config.LoadKeyRotation(nil)
+	if err == nil || !strings.Contains(err.Error(), "encryption.keys") {
+		t.Fatalf("err = %v, want encryption.keys error", err)
+	}
+
+	t.Setenv("FIELD_ENCRYPTION_KEYS", "k1:"+testEncryption().Keys["k1"])
+	t.Setenv("BLIND_INDEX_KEY", testEncryption().BlindIndexKey)
+	cfg, err := config.LoadKeyRotation(nil)
+	if err != nil {
+		t.Fatalf("LoadKeyRotation without JWT secret or port: %v", err)
+	}
+	if cfg.Database.URI != "postgres://rotate" || len(cfg.Encryption.Keys) != 1 {
+		t.Errorf("cfg = %+v / %+v", cfg.Database, cfg.Encryption)
+	}
+	if _, err := config.Load(nil); err == nil || !strings.Contains(err.Error(), "auth.jwt_secret") {
+		t.Errorf("Load err = %v, want auth.jwt_secret error", err)
+	}
+}
+
+func TestConfigRejectsMalformedEnv(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("HTTP_READ_TIMEOUT", "ten seconds")
+
+	_, err := config.Load(nil)
+	if err == nil || !strings.Contains(err.Error(), "HTTP_READ_TIMEOUT") {
+		t.Fatalf("err = %v, want HTTP_READ_TIMEOUT parse error", err)
+	}
+}
+
+func TestConfigRetention(t *testing.T) {
+	cfg := config.Default()
+	cfg.Server.Port = 8080
+	cfg.Database.URI = "postgres://x"
+	cfg.Auth.JWTSecret = "s"
+	cfg.Encryption = testEncryption()
+
+	cfg.Retention.SoftDeletePeriod = 0
+	cfg.Retention.PurgeInterval = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("disabled purge rejected: %v", err)
+	}
+	cfg.Retention.SoftDeletePeriod = 24 * time.Hour
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "retention.purge_interval") {
+		t.Errorf("err = %v, want retention.purge_interval error", err)
+	}
+	cfg.Retention.SoftDeletePeriod = -time.Hour
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "retention.soft_delete_period") {
+		t.Errorf("err = %v, want retention.soft_delete_period error", err)
+	}
+}
+
+func TestConfigOCR(t *testing.T) {
+	cfg := config.Default()
+	cfg.Server.Port = 8080
+	cfg.Database.URI = "postgres://x"
+	cfg.Auth.JWTSecret = "s"
+	cfg.Encryption = testEncryption()
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("default OCR config rejected: %v", err)
+	}
+	cfg.OCR.Engine = "tesseract"
+	cfg.OCR.Timeout = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "ocr.timeout") {
+		t.Errorf("err = %v, want ocr.timeout error", err)
+	}
+	cfg.OCR.Engine = "cloud"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "ocr.engine") {
+		t.Errorf("err = %v, want ocr.engine error", err)
+	}
+}
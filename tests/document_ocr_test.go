@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/ocr"
+	"github.com/jaga-project/jaga-backend/internal/server"
+)
+
+// waitOCR menunggu job OCR background untuk imageID selesai.
+func (f *fixture) waitOCR(imageID int64) *database.DocumentOCR {
+	f.t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		o, err := f.store.Repos().DocumentOCR.GetByImageID(context.Background(), imageID)
+		if err == nil && o.Status != database.OCRPending {
+			return o
+		}
+		if time.Now().After(deadline) {
+			f.t.Fatalf("OCR of image %d not finished: %+v, %v", imageID, o, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// vehicleImages mengembalikan ID foto STNK dan KK kendaraan.
+func (f *fixture) vehicleImages(vehicleID int64) (stnk, kk int64) {
+	f.t.Helper()
+	v, err := f.store.Repos().Vehicles.GetByID(context.Background(), vehicleID)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return v.STNKImageID.Int64, v.KKImageID.Int64
+}
+
+func (f *fixture) verificationQueue(token, query string) server.DocumentVerificationListResponse {
+	f.t.Helper()
+	rec := f.do("GET", "/api/admins/verifications"+query, token, nil)
+	expectStatus(f.t, rec, http.StatusOK)
+	var queue server.DocumentVerificationListResponse
+	json.NewDecoder(rec.Body).Decode(&queue)
+	return queue
+}
+
+func TestDocumentOCRFlagsMismatches(t *testing.T) {
+	f := newFixture(t)
+	stub := &ocr.Stub{}
+	f.srv.SetOCR(stub)
+	admin := f.createUser("a1", "a1@example.com", true)
+
+	stub.Set(ocr.Result{Text: "PROVINSI DKI JAKARTA\nNIK : 3171O1O1O19OOOO9\nNama : DEWI  LESTARI\nTempat/Tgl Lahir : JAKARTA", Confidence: 0.87}, nil)
+	rec := f.doUpload("POST", "/users", "", map[string]string{
+		"name": "Dewi Lestari", "email": "dewi@example.com", "password": testPassword, "nik": "3171010101900008",
+	}, map[string][]byte{"ktp_image": pngBytes(t)})
+	expectStatus(t, rec, http.StatusCreated)
+	var user database.User
+	json.NewDecoder(rec.Body).Decode(&user)
+	ktp := f.waitOCR(*user.KTPImageID)
+	if ktp.NIK == "3171010101900009" || !strings.HasPrefix(ktp.NIK, "enc:") {
+		t.Errorf("OCR NIK stored unencrypted: %q", ktp.NIK)
+	}
+	if ktp.Status != database.OCRDone || ktp.Name != "DEWI LESTARI" || ktp.Confidence != 0.87 || ktp.Engine != "stub" {
+		t.Errorf("KTP OCR = %+v", ktp)
+	}
+
+	// Plat diketik salah: STNK terbaca B 1234 XYZ.
+	stub.Set(ocr.Result{Text: "NAMA PEMILIK : DEWI LESTARI\nNO. REGISTRASI : B1234 XYZ", Confidence: 0.9}, nil)
+	owner := f.createUser("u1", "u1@example.com", false)
+	rec = f.doUpload("POST", "/api/vehicles", owner, map[string]string{"vehicle_name": "Beat", "plate_number": "B 1284 XYZ"},
+		map[string][]byte{"stnk_image": pngBytes(t)})
+	expectStatus(t, rec, http.StatusCreated)
+	var vehicle server.VehicleResponse
+	json.NewDecoder(rec.Body).Decode(&vehicle)
+	stnkImage, _ := f.vehicleImages(vehicle.VehicleID)
+	stnk := f.waitOCR(stnkImage)
+	if stnk.Status != database.OCRDone || stnk.PlateNumber != "B 1234 XYZ" {
+		t.Errorf("STNK OCR = %+v", stnk)
+	}
+
+	queue := f.verificationQueue(admin, "")
+	if queue.Total != 2 {
+		t.Fatalf("queue = %+v", queue)
+	}
+	d := queue.Results[0]
+	if d.OCR == nil || d.OCR.NIK != "3171010101900009" || d.OCR.Name != "DEWI LESTARI" {
+		t.Errorf("KTP entry OCR = %+v", d.OCR)
+	}
+	if strings.Join(d.OCRMismatches, ",") != "nik" {
+		t.Errorf("KTP mismatches = %v, want [nik]", d.OCRMismatches)
+	}
+	if d = queue.Results[1]; strings.Join(d.OCRMismatches, ",") != "plate_number" || d.OCR.PlateNumber != "B 1234 XYZ" {
+		t.Errorf("STNK entry = %+v", d)
+	}
+
+	// Setelah NIK diperbaiki tidak ada lagi yang ditandai.
+	rec = f.do("POST", "/auth/login", "", map[string]string{"email": "dewi@example.com", "password": testPassword})
+	expectStatus(t, rec, http.StatusOK)
+	var login server.LoginResponse
+	json.NewDecoder(rec.Body).Decode(&login)
+	dewi := login.Token
+	expectStatus(t, f.do("PUT", "/api/users/"+user.UserID, dewi, map[string]string{"nik": "3171 0101 0190 0009"}), http.StatusOK)
+	queue = f.verificationQueue(admin, "?document_type=KTP")
+	if len(queue.Results) != 1 || queue.Results[0].OCRMismatches != nil {
+		t.Errorf("KTP entry after fix = %+v", queue.Results)
+	}
+}
+
+func TestDocumentOCRFailure(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+
+	// Tanpa engine, upload tidak diproses OCR.
+	rec := f.doUpload("POST", "/users", "", map[string]string{
+		"name": "Dewi", "email": "dewi@example.com", "password": testPassword, "nik": "3171010101900009",
+	}, map[string][]byte{"ktp_image": pngBytes(t)})
+	expectStatus(t, rec, http.StatusCreated)
+	queue := f.verificationQueue(admin, "")
+	if len(queue.Results) != 1 || queue.Results[0].OCR != nil {
+		t.Fatalf("queue without OCR = %+v", queue.Results)
+	}
+
+	stub := &ocr.Stub{}
+	stub.Set(ocr.Result{}, errors.New("engine unavailable"))
+	f.srv.SetOCR(stub)
+	owner := f.createUser("u1", "u1@example.com", false)
+	rec = f.doUpload("POST", "/api/vehicles", owner, map[string]string{"vehicle_name": "Beat", "plate_number": "B 1 ABC"},
+		map[string][]byte{"stnk_image": pngBytes(t), "kk_image": pngBytes(t)})
+	expectStatus(t, rec, http.StatusCreated)
+	var vehicle server.VehicleResponse
+	json.NewDecoder(rec.Body).Decode(&vehicle)
+	stnkImage, kkImage := f.vehicleImages(vehicle.VehicleID)
+	if o := f.waitOCR(stnkImage); o.Status != database.OCRFailed || o.Error != "engine unavailable" {
+		t.Errorf("failed OCR = %+v", o)
+	}
+	// KK tidak diproses OCR.
+	if _, err := f.store.Repos().DocumentOCR.GetByImageID(context.Background(), kkImage); !errors.Is(err, database.ErrDocumentOCRNotFound) {
+		t.Errorf("KK OCR err = %v, want ErrDocumentOCRNotFound", err)
+	}
+	if n := stub.Calls(); n != 1 {
+		t.Errorf("Recognize called %d times, want 1", n)
+	}
+	queue = f.verificationQueue(admin, "?document_type=STNK")
+	if len(queue.Results) != 1 || queue.Results[0].OCR.Status != database.OCRFailed || queue.Results[0].OCRMismatches != nil {
+		t.Errorf("STNK entry = %+v", queue.Results)
+	}
+}
+
+// TestTesseractEngine menjalankan engine tesseract dengan binary palsu yang
+// mencetak output TSV.
+func TestTesseractEngine(t *testing.T) {
+	tsv := "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+		"1\t1\t0\t0\t0\t0\t0\t0\t100\t100\t-1\t\n" +
+		"5\t1\t1\t1\t1\t1\t0\t0\t10\t10\t90\tNIK\n" +
+		"5\t1\t1\t1\t1\t2\t0\t0\t10\t10\t80\t3171010101900009\n" +
+		"5\t1\t1\t1\t2\t1\t0\t0\t10\t10\t70\tNama\n" +
+		"5\t1\t1\t1\t2\t2\t0\t0\t10\t10\t60\tDEWI\n"
+	bin := filepath.Join(t.TempDir(), "tesseract")
+	script := "#!/bin/sh\ncat > /dev/null\nprintf '" + strings.ReplaceAll(strings.ReplaceAll(tsv, "\t", `\t`), "\n", `\n`) + "'\n"
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := &ocr.Tesseract{Binary: bin, Language: "ind"}
+	res, err := engine.Recognize(context.Background(), strings.NewReader("image"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Text != "NIK 3171010101900009\nNama DEWI" || res.Confidence != 0.75 {
+		t.Errorf("result = %+v", res)
+	}
+	if fields := ocr.ExtractKTP(res.Text); fields.NIK != "3171010101900009" || fields.Name != "DEWI" {
+		t.Errorf("fields = %+v", fields)
+	}
+
+	engine.Binary = filepath.Join(t.TempDir(), "missing")
+	if _, err := engine.Recognize(context.Background(), strings.NewReader("image")); err == nil {
+		t.Error("expected error for missing binary")
+	}
+}
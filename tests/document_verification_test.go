@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/server"
+)
+
+func TestDocumentVerificationWorkflow(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+
+	rec := f.doUpload("POST", "/users", "", map[string]string{
+		"name": "Dewi", "email": "dewi@example.com", "password": testPassword, "nik": "3171010101900009",
+	}, map[string][]byte{"ktp_image": pngBytes(t)})
+	expectStatus(t, rec, http.StatusCreated)
+	var user database.User
+	json.NewDecoder(rec.Body).Decode(&user)
+	if user.KTPVerificationStatus != database.VerificationPending {
+		t.Fatalf("ktp_verification_status = %q, want PENDING", user.KTPVerificationStatus)
+	}
+	rec = f.do("POST", "/auth/login", "", map[string]string{"email": "dewi@example.com", "password": testPassword})
+	expectStatus(t, rec, http.StatusOK)
+	var login server.LoginResponse
+	json.NewDecoder(rec.Body).Decode(&login)
+	dewi := login.Token
+	expectStatus(t, f.do("POST", "/auth/verify-email", "", map[string]string{"token": f.lastMailToken("dewi@example.com")}), http.StatusOK)
+
+	rec = f.doUpload("POST", "/api/vehicles", dewi, map[string]string{"vehicle_name": "Beat", "plate_number": "B 1 ABC"},
+		map[string][]byte{"stnk_image": pngBytes(t), "kk_image": pngBytes(t)})
+	expectStatus(t, rec, http.StatusCreated)
+	var vehicle server.VehicleResponse
+	json.NewDecoder(rec.Body).Decode(&vehicle)
+	if vehicle.VerificationStatus != database.VerificationPending || vehicle.KKVerificationStatus != database.VerificationPending {
+		t.Fatalf("vehicle = %+v", vehicle)
+	}
+	vid := strconv.FormatInt(vehicle.VehicleID, 10)
+
+	// Antrean menampilkan NIK dan plat yang diketik user.
+	rec = f.do("GET", "/api/admins/verifications", admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var queue server.DocumentVerificationListResponse
+	json.NewDecoder(rec.Body).Decode(&queue)
+	if queue.Total != 3 || len(queue.Results) != 3 {
+		t.Fatalf("queue = %+v", queue)
+	}
+	for i, want := range []string{"KTP", "STNK", "KK"} {
+		d := queue.Results[i]
+		if d.DocumentType != want || d.NIK != "3171010101900009" || d.UserID != user.UserID || !strings.HasPrefix(d.ImageURL, "/") {
+			t.Errorf("queue[%d] = %+v", i, d)
+		}
+	}
+	ktpImage, stnkImage, kkImage := queue.Results[0].ImageID, queue.Results[1].ImageID, queue.Results[2].ImageID
+	if queue.Results[1].PlateNumber != "B 1 ABC" || queue.Results[1].VehicleID == nil {
+		t.Errorf("STNK entry = %+v", queue.Results[1])
+	}
+	rec = f.do("GET", "/api/admins/verifications?document_type=kk&limit=1", admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	queue = server.DocumentVerificationListResponse{}
+	json.NewDecoder(rec.Body).Decode(&queue)
+	if queue.Total != 1 || queue.Results[0].DocumentType != database.DocumentKK {
+		t.Errorf("KK queue = %+v", queue)
+	}
+	expectStatus(t, f.do("GET", "/api/admins/verifications?document_type=sim", admin, nil), http.StatusBadRequest)
+	expectStatus(t, f.do("GET", "/api/admins/verifications", dewi, nil), http.StatusForbidden)
+
+	// KTP dan STNK harus terverifikasi sebelum melapor.
+	expectStatus(t, f.postLostReport(dewi, vehicle.VehicleID), http.StatusForbidden)
+
+	ktpPath := "/api/admins/verifications/ktp/" + user.UserID
+	expectStatus(t, f.do("POST", ktpPath, admin, map[string]string{"status": "VERIFIED"}), http.StatusBadRequest)
+	expectStatus(t, f.do("POST", ktpPath, admin, verifyBody("REJECTED", ktpImage)), http.StatusBadRequest)
+	expectStatus(t, f.do("POST", ktpPath, admin, verifyBody("VERIFIED", stnkImage)), http.StatusConflict)
+	rec = f.do("POST", ktpPath, admin, map[string]interface{}{"status": "REJECTED", "note": "Foto KTP buram", "image_id": ktpImage})
+	expectStatus(t, rec, http.StatusOK)
+	json.NewDecoder(rec.Body).Decode(&user)
+	if user.KTPVerificationStatus != database.VerificationRejected || user.KTPVerificationNote == nil || *user.KTPVerificationNote != "Foto KTP buram" {
+		t.Errorf("rejected user = %+v", user)
+	}
+	msgs := f.mail.Messages()
+	if last := msgs[len(msgs)-1]; last.To != "dewi@example.com" || !strings.Contains(last.Body, "Foto KTP buram") {
+		t.Errorf("rejection mail = %+v", last)
+	}
+	rec = f.do("GET", "/api/admins/verifications?status=REJECTED", admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	queue = server.DocumentVerificationListResponse{}
+	json.NewDecoder(rec.Body).Decode(&queue)
+	if queue.Total != 1 || queue.Results[0].DocumentType != database.DocumentKTP {
+		t.Errorf("rejected queue = %+v", queue)
+	}
+
+	expectStatus(t, f.do("POST", ktpPath, admin, verifyBody("VERIFIED", ktpImage)), http.StatusOK)
+	expectStatus(t, f.postLostReport(dewi, vehicle.VehicleID), http.StatusForbidden)
+	expectStatus(t, f.do("POST", "/api/admins/verifications/stnk/"+vid, admin, verifyBody("VERIFIED", stnkImage)), http.StatusOK)
+	rec = f.do("POST", "/api/admins/verifications/kk/"+vid, admin, verifyBody("VERIFIED", kkImage))
+	expectStatus(t, rec, http.StatusOK)
+	json.NewDecoder(rec.Body).Decode(&vehicle)
+	if vehicle.KKVerificationStatus != database.VerificationVerified || vehicle.KKVerifiedBy == nil || *vehicle.KKVerifiedBy != "a1" {
+		t.Errorf("vehicle after KK verification = %+v", vehicle)
+	}
+	expectStatus(t, f.postLostReport(dewi, vehicle.VehicleID), http.StatusCreated)
+
+	rec = f.do("GET", "/api/admins/verifications", admin, nil)
+	queue = server.DocumentVerificationListResponse{}
+	json.NewDecoder(rec.Body).Decode(&queue)
+	if queue.Total != 0 || len(queue.Results) != 0 {
+		t.Errorf("pending queue after review = %+v", queue)
+	}
+
+	// NIK berubah: KTP masuk antrean lagi.
+	rec = f.do("PUT", "/api/users/"+user.UserID, dewi, map[string]string{"nik": "3171010101900010"})
+	expectStatus(t, rec, http.StatusOK)
+	user = database.User{}
+	json.NewDecoder(rec.Body).Decode(&user)
+	if user.KTPVerificationStatus != database.VerificationPending || user.KTPVerifiedBy != nil {
+		t.Errorf("user after NIK change = %+v", user)
+	}
+
+	expectStatus(t, f.do("POST", "/api/admins/verifications/ktp/a1", admin, verifyBody("VERIFIED", ktpImage)), http.StatusConflict)
+	expectStatus(t, f.do("POST", "/api/admins/verifications/ktp/nobody", admin, verifyBody("VERIFIED", ktpImage)), http.StatusNotFound)
+	expectStatus(t, f.do("POST", ktpPath, dewi, verifyBody("VERIFIED", ktpImage)), http.StatusForbidden)
+
+	if n := len(f.auditEntries(admin, "?action=user.verify_ktp")); n != 2 {
+		t.Errorf("got %d user.verify_ktp entries, want 2", n)
+	}
+	if n := len(f.auditEntries(admin, "?action=vehicle.verify_kk")); n != 1 {
+		t.Errorf("got %d vehicle.verify_kk entries, want 1", n)
+	}
+}
+
+// Foto STNK diganti setelah admin membuka antrean: keputusan atas foto lama
+// ditolak.
+func TestVerificationRejectsReplacedImage(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	owner := f.createUser("u1", "u1@example.com", false)
+
+	rec := f.postVehicleWithSTNK(owner, "D 1 ABC")
+	expectStatus(t, rec, http.StatusCreated)
+	var v server.VehicleResponse
+	json.NewDecoder(rec.Body).Decode(&v)
+	vid := strconv.FormatInt(v.VehicleID, 10)
+	queue := f.verificationQueue(admin, "?document_type=stnk")
+	if queue.Total != 1 {
+		t.Fatalf("queue = %+v", queue)
+	}
+	reviewed := queue.Results[0].ImageID
+
+	rec = f.doUpload("PUT", "/api/vehicles/"+vid, owner, nil, map[string][]byte{"stnk_image": pngBytes(t)})
+	expectStatus(t, rec, http.StatusOK)
+	current, _ := f.vehicleImages(v.VehicleID)
+	if current == reviewed {
+		t.Fatalf("STNK image was not replaced")
+	}
+
+	path := "/api/admins/verifications/stnk/" + vid
+	expectStatus(t, f.do("POST", path, admin, verifyBody("VERIFIED", reviewed)), http.StatusConflict)
+	rec = f.do("POST", path, admin, verifyBody("VERIFIED", current))
+	expectStatus(t, rec, http.StatusOK)
+	json.NewDecoder(rec.Body).Decode(&v)
+	if v.VerificationStatus != database.VerificationVerified {
+		t.Errorf("vehicle = %+v", v)
+	}
+}
+
+func verifyBody(status string, imageID int64) map[string]interface{} {
+	return map[string]interface{}{"status": status, "image_id": imageID}
+}
+
+func TestTransferRequiresVerifiedRecipientKTP(t *testing.T) {
+	f := newFixture(t)
+	owner := f.createUser("u1", "u1@example.com", false)
+	f.createUser("u2", "u2@example.com", false)
+	vid := f.createVehicle("u1")
+	if err := f.store.Repos().Users.SetKTPVerification(context.Background(), "u2", database.VerificationPending, "", "", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	path := "/api/vehicles/" + strconv.FormatInt(vid, 10) + "/transfers"
+	expectStatus(t, f.do("POST", path, owner, map[string]string{"recipient_email": "u2@example.com"}), http.StatusBadRequest)
+}
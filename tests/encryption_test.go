@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jaga-project/jaga-backend/internal/config"
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/fieldcrypt"
+)
+
+func TestFieldEncryptionKeyRing(t *testing.T) {
+	enc := testEncryption()
+	oldRing, err := enc.KeyRing()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := oldRing.Encrypt("3201010101900001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(ciphertext, "enc:v1:k1:") || strings.Contains(ciphertext, "3201010101900001") {
+		t.Fatalf("ciphertext = %q", ciphertext)
+	}
+	if again, _ := oldRing.Encrypt("3201010101900001"); again == ciphertext {
+		t.Error("encryption is deterministic")
+	}
+	if pt, err := oldRing.Decrypt(ciphertext); err != nil || pt != "3201010101900001" {
+		t.Errorf("Decrypt = %q, %v", pt, err)
+	}
+	if pt, err := oldRing.Decrypt("081234567890"); err != nil || pt != "081234567890" {
+		t.Errorf("legacy plaintext = %q, %v", pt, err)
+	}
+	tampered := ciphertext[:len(ciphertext)-2] + "AA"
+	if _, err := oldRing.Decrypt(tampered); err == nil {
+		t.Error("tampered ciphertext decrypted")
+	}
+
+	// Key baru aktif: nilai lama masih terbaca tetapi perlu di-rotate.
+	enc.Keys["k2"] = "amFnYS10ZXN0LWZpZWxkLWtleS0yLTAxMjM0NTY3ODk="
+	enc.ActiveKeyID = "k2"
+	newRing, err := enc.KeyRing()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pt, err := newRing.Decrypt(ciphertext); err != nil || pt != "3201010101900001" {
+		t.Errorf("Decrypt with rotated ring = %q, %v", pt, err)
+	}
+	if !newRing.NeedsRotation(ciphertext) || !newRing.NeedsRotation("081234567890") || oldRing.NeedsRotation(ciphertext) {
+		t.Error("NeedsRotation mismatch")
+	}
+	rotated, _ := newRing.Encrypt("3201010101900001")
+	if _, err := oldRing.Decrypt(rotated); !errors.Is(err, fieldcrypt.ErrUnknownKey) {
+		t.Errorf("old ring decrypting k2 value: err = %v, want ErrUnknownKey", err)
+	}
+
+	if oldRing.BlindIndex("3201 0101.0190-0001") != newRing.BlindIndex("3201010101900001") {
+		t.Error("blind index must ignore formatting and not depend on the active key")
+	}
+	if got := fieldcrypt.Mask("3201010101900001"); got != "3201********0001" {
+		t.Errorf("Mask = %q", got)
+	}
+
+	enc.ActiveKeyID = "k3"
+	if _, err := enc.KeyRing(); err == nil {
+		t.Error("unknown active key accepted")
+	}
+	if _, err := (config.EncryptionConfig{Keys: map[string]string{"k1": "c2hvcnQ="}, BlindIndexKey: testEncryption().BlindIndexKey}).KeyRing(); err == nil {
+		t.Error("short key accepted")
+	}
+}
+
+func TestUserFieldsEncryptedAtRest(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	other := f.createUser("u2", "u2@example.com", false)
+	ctx := context.Background()
+
+	rec := f.doMultipart("POST", "/users", "", map[string]string{
+		"name": "Budi", "email": "budi@example.com", "password": "Rahasia!2024x",
+		"phone": "081234567890", "nik": "3201010101900001",
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	var created database.User
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := f.store.Repos().Users.FindByID(ctx, created.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stored.NIK, "enc:v1:") || !strings.HasPrefix(stored.Phone, "enc:v1:") || stored.NIKHash == "" {
+		t.Fatalf("stored user not encrypted: %+v", stored)
+	}
+
+	login := f.do("POST", "/auth/login", "", map[string]string{"email": "budi@example.com", "password": "Rahasia!2024x"})
+	expectStatus(t, login, http.StatusOK)
+	var session struct {
+		Token string `json:"token"`
+		NIK   string `json:"nik"`
+		Phone string `json:"phone"`
+	}
+	json.NewDecoder(login.Body).Decode(&session)
+	if session.NIK != "3201010101900001" || session.Phone != "081234567890" {
+		t.Errorf("login nik/phone = %q/%q, want plaintext for owner", session.NIK, session.Phone)
+	}
+
+	get := func(token string) database.User {
+		t.Helper()
+		rec := f.do("GET", "/api/users/"+created.UserID, token, nil)
+		expectStatus(t, rec, http.StatusOK)
+		var u database.User
+		json.NewDecoder(rec.Body).Decode(&u)
+		return u
+	}
+	if u := get(session.Token); u.NIK != "3201010101900001" || u.Phone != "081234567890" {
+		t.Errorf("owner sees %q/%q", u.NIK, u.Phone)
+	}
+	if u := get(admin); u.NIK != "3201010101900001" {
+		t.Errorf("admin sees %q", u.NIK)
+	}
+	if u := get(other); u.NIK != "3201********0001" || u.Phone != "0812****7890" {
+		t.Errorf("other user sees %q/%q, want masked", u.NIK, u.Phone)
+	}
+
+	rec = f.do("GET", "/api/users?nik=3201-0101-0190-0001", admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var found database.User
+	json.NewDecoder(rec.Body).Decode(&found)
+	if found.UserID != created.UserID || found.NIK != "3201010101900001" {
+		t.Errorf("lookup by NIK = %+v", found)
+	}
+	expectStatus(t, f.do("GET", "/api/users?nik=3201010101909999", admin, nil), http.StatusNotFound)
+	expectStatus(t, f.do("GET", "/api/users?nik=3201010101900001", other, nil), http.StatusForbidden)
+
+	// NIK yang diubah tetap bisa dicari dan NIK lama tidak lagi cocok.
+	expectStatus(t, f.do("PUT", "/api/users/"+created.UserID, session.Token, map[string]string{"nik": "3201010101900002"}), http.StatusOK)
+	expectStatus(t, f.do("GET", "/api/users?nik=3201010101900001", admin, nil), http.StatusNotFound)
+	expectStatus(t, f.do("GET", "/api/users?nik=3201010101900002", admin, nil), http.StatusOK)
+	if stored, _ := f.store.Repos().Users.FindByID(ctx, created.UserID); !strings.HasPrefix(stored.NIK, "enc:v1:") {
+		t.Errorf("updated NIK stored as %q", stored.NIK)
+	}
+}
+
+func TestUserUpdateIgnoresNIKHash(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	attacker := f.createUser("u2", "u2@example.com", false)
+	ctx := context.Background()
+
+	rec := f.doMultipart("POST", "/users", "", map[string]string{
+		"name": "Budi", "email": "budi@example.com", "password": "Rahasia!2024x", "nik": "3201010101900001",
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	var victim database.User
+	json.NewDecoder(rec.Body).Decode(&victim)
+	stored, err := f.store.Repos().Users.FindByID(ctx, victim.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := map[string]string{"name": "Penyusup", "nik_hash": stored.NIKHash}
+	expectStatus(t, f.do("PUT", "/api/users/u2", attacker, body), http.StatusOK)
+	if u, _ := f.store.Repos().Users.FindByID(ctx, "u2"); u.NIKHash == stored.NIKHash {
+		t.Errorf("client-supplied nik_hash was stored")
+	}
+	rec = f.do("GET", "/api/users?nik=3201010101900001", admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var found database.User
+	json.NewDecoder(rec.Body).Decode(&found)
+	if found.UserID != victim.UserID {
+		t.Errorf("lookup by NIK returned %q, want %q", found.UserID, victim.UserID)
+	}
+
+	expectStatus(t, f.do("PUT", "/api/users/u2", attacker, map[string]interface{}{"nik": 3201010101900002}), http.StatusBadRequest)
+	expectStatus(t, f.do("PUT", "/api/users/u2", attacker, map[string]interface{}{"phone": true}), http.StatusBadRequest)
+}
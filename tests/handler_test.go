@@ -1 +1,554 @@
-package tests
\ No newline at end of file
+package tests
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/auth"
+	"github.com/jaga-project/jaga-backend/internal/config"
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/database/memory"
+	"github.com/jaga-project/jaga-backend/internal/mail"
+	"github.com/jaga-project/jaga-backend/internal/server"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPassword = "rahasia123"
+
+func TestMain(m *testing.M) {
+
+	// Access log tiap request tidak perlu memenuhi output test.
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// Handler upload menulis ke ./uploads, jadi jalankan test di direktori sementara.
+	dir, err := os.MkdirTemp("", "jaga-handler-test")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// testConfig adalah config minimal untuk test handler; port dan database
+// tidak dipakai karena handler dipanggil langsung lewat httptest.
+func testConfig() *config.Config {
+	cfg := config.Default()
+	cfg.Auth.JWTSecret = "test-secret"
+	cfg.Encryption = testEncryption()
+	return &cfg
+}
+
+// testEncryption berisi key ring dengan satu key aktif "k1".
+func testEncryption() config.EncryptionConfig {
+	return config.EncryptionConfig{
+		Keys:          map[string]string{"k1": "amFnYS10ZXN0LWZpZWxkLWtleS0xLTAxMjM0NTY3ODk="},
+		ActiveKeyID:   "k1",
+		BlindIndexKey: "amFnYS10ZXN0LWJsaW5kLWluZGV4LTAxMjM0NTY3ODk=",
+	}
+}
+
+var testTokens = auth.NewManager("test-secret", 24*time.Hour)
+
+type fixture struct {
+	t       *testing.T
+	store   *memory.Store
+	srv     *server.Server
+	handler http.Handler
+	mail    *mail.Recorder
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	return newFixtureWithConfig(t, testConfig())
+}
+
+func newFixtureWithConfig(t *testing.T, cfg *config.Config) *fixture {
+	t.Helper()
+	store := memory.NewStore()
+	srv := server.New(cfg, store)
+	mailer := &mail.Recorder{}
+	srv.SetMailer(mailer)
+	return &fixture{t: t, store: store, srv: srv, handler: srv.RegisterRoutes(), mail: mailer}
+}
+
+func (f *fixture) createUser(id, email string, isAdmin bool) string {
+	f.t.Helper()
+	ctx := context.Background()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	now := time.Now()
+	u := database.User{UserID: id, Name: id, Email: email, Password: string(hash), NIK: "nik-" + id, CreatedAt: now, EmailVerifiedAt: &now}
+	// KTP terverifikasi diperlukan untuk melapor dan menerima kendaraan.
+	u.KTPVerificationStatus = database.VerificationVerified
+	if err := f.store.Repos().Users.Create(ctx, &u); err != nil {
+		f.t.Fatal(err)
+	}
+	if isAdmin {
+		if err := f.store.Repos().Admins.Create(ctx, &database.Admin{UserID: id, AdminLevel: 1}); err != nil {
+			f.t.Fatal(err)
+		}
+	}
+	token, _, err := testTokens.GenerateJWT(id, isAdmin)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return token
+}
+
+func (f *fixture) createVehicle(userID string) int64 {
+	f.t.Helper()
+	v := database.Vehicle{VehicleName: "Beat", Color: "Hitam", UserID: userID, PlateNumber: "B 1234 " + userID}
+	// Hanya kendaraan terverifikasi yang bisa dilaporkan hilang.
+	v.VerificationStatus = database.VerificationVerified
+	if err := f.store.Repos().Vehicles.Create(context.Background(), &v); err != nil {
+		f.t.Fatal(err)
+	}
+	return v.VehicleID
+}
+
+func (f *fixture) createLostReport(userID string, vehicleID int64) int {
+	f.t.Helper()
+	lr := database.LostReport{
+		UserID:    userID,
+		Timestamp: time.Now().Add(-time.Hour),
+		VehicleID: int(vehicleID),
+		Address:   "Jl. Sudirman",
+		Status:    database.StatusLostReportBelumDiproses,
+	}
+	if err := f.store.Repos().LostReports.Create(context.Background(), &lr); err != nil {
+		f.t.Fatal(err)
+	}
+	return lr.LostID
+}
+
+func (f *fixture) do(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	f.t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			f.t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func (f *fixture) doMultipart(method, path, token string, fields map[string]string) *httptest.ResponseRecorder {
+	f.t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for k, v := range fields {
+		mw.WriteField(k, v)
+	}
+	mw.Close()
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// doUpload seperti doMultipart, ditambah file dengan nama field sebagai key.
+func (f *fixture) doUpload(method, path, token string, fields map[string]string, files map[string][]byte) *httptest.ResponseRecorder {
+	f.t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for k, v := range fields {
+		mw.WriteField(k, v)
+	}
+	for field, data := range files {
+		fw, _ := mw.CreateFormFile(field, field+".png")
+		fw.Write(data)
+	}
+	mw.Close()
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if rec.Code != want {
+		t.Fatalf("status = %d, want %d; body: %s", rec.Code, want, rec.Body.String())
+	}
+}
+
+func TestLogin(t *testing.T) {
+	f := newFixture(t)
+	f.createUser("u1", "u1@example.com", false)
+	f.createUser("a1", "a1@example.com", true)
+	// Alur 2FA admin diuji di mfa_test.go.
+	if err := f.store.Repos().AdminMFA.UpdatePolicy(context.Background(), &database.MFAPolicy{RequireAboveLevel: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		email     string
+		password  string
+		want      int
+		wantAdmin bool
+	}{
+		{"user", "u1@example.com", testPassword, http.StatusOK, false},
+		{"admin", "a1@example.com", testPassword, http.StatusOK, true},
+		{"wrong password", "u1@example.com", "salah", http.StatusUnauthorized, false},
+		{"unknown email", "nobody@example.com", testPassword, http.StatusUnauthorized, false},
+		{"missing fields", "", "", http.StatusBadRequest, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := f.do("POST", "/auth/login", "", map[string]string{"email": tt.email, "password": tt.password})
+			expectStatus(t, rec, tt.want)
+			if tt.want != http.StatusOK {
+				return
+			}
+			var resp server.LoginResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.IsAdmin != tt.wantAdmin {
+				t.Errorf("is_admin = %v, want %v", resp.IsAdmin, tt.wantAdmin)
+			}
+			claims, err := testTokens.ValidateJWT(resp.Token)
+			if err != nil {
+				t.Fatalf("token invalid: %v", err)
+			}
+			if claims.IsAdmin != tt.wantAdmin {
+				t.Errorf("claims.IsAdmin = %v, want %v", claims.IsAdmin, tt.wantAdmin)
+			}
+		})
+	}
+}
+
+// wrappingStore membungkus error repository user seperti lapisan store
+// lain (mis. enkripsi) agar handler harus memakai errors.Is.
+type wrappingStore struct{ *memory.Store }
+
+type wrappingUserRepo struct{ database.UserRepo }
+
+func (s wrappingStore) Repos() database.Repositories {
+	r := s.Store.Repos()
+	r.Users = wrappingUserRepo{r.Users}
+	return r
+}
+
+func (r wrappingUserRepo) FindByEmail(ctx context.Context, email string) (*database.User, error) {
+	u, err := r.UserRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("find user by email: %w", err)
+	}
+	return u, nil
+}
+
+func TestLoginUnknownEmailWrappedError(t *testing.T) {
+	srv := server.New(testConfig(), wrappingStore{memory.NewStore()})
+	srv.SetMailer(&mail.Recorder{})
+	f := &fixture{t: t, handler: srv.RegisterRoutes()}
+	expectStatus(t, f.do("POST", "/auth/login", "", map[string]string{"email": "nobody@example.com", "password": testPassword}), http.StatusUnauthorized)
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	f := newFixture(t)
+	f.createUser("u1", "u1@example.com", false)
+	f.createUser("a1", "a1@example.com", true)
+	if err := f.store.AddAPIKey("a1", "kunci-admin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.store.AddAPIKey("u1", "kunci-user"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("missing token", func(t *testing.T) {
+		expectStatus(t, f.do("GET", "/api/vehicles/my", "", nil), http.StatusUnauthorized)
+	})
+
+	t.Run("invalid token format", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/vehicles/my", nil)
+		req.Header.Set("Authorization", "Token abc")
+		rec := httptest.NewRecorder()
+		f.handler.ServeHTTP(rec, req)
+		expectStatus(t, rec, http.StatusUnauthorized)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		expectStatus(t, f.do("GET", "/api/vehicles/my", "bukan.token.valid", nil), http.StatusUnauthorized)
+	})
+
+	apiKeyTests := []struct {
+		name string
+		key  string
+		path string
+		want int
+	}{
+		{"invalid api key", "salah", "/api/vehicles/my", http.StatusForbidden},
+		{"user api key", "kunci-user", "/api/vehicles/my", http.StatusOK},
+		{"user api key on admin route", "kunci-user", "/api/suspects", http.StatusForbidden},
+		{"admin api key on admin route", "kunci-admin", "/api/suspects", http.StatusOK},
+	}
+	for _, tt := range apiKeyTests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("X-API-Key", tt.key)
+			rec := httptest.NewRecorder()
+			f.handler.ServeHTTP(rec, req)
+			expectStatus(t, rec, tt.want)
+		})
+	}
+}
+
+func TestAdminOnlyRoutes(t *testing.T) {
+	f := newFixture(t)
+	userToken := f.createUser("u1", "u1@example.com", false)
+	adminToken := f.createUser("a1", "a1@example.com", true)
+
+	paths := []string{"/api/users", "/api/lost_reports", "/api/suspects", "/api/detected", "/api/admins/"}
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			expectStatus(t, f.do("GET", path, userToken, nil), http.StatusForbidden)
+			expectStatus(t, f.do("GET", path, adminToken, nil), http.StatusOK)
+		})
+	}
+
+	t.Run("delete user", func(t *testing.T) {
+		expectStatus(t, f.do("DELETE", "/api/users/a1", userToken, nil), http.StatusForbidden)
+	})
+}
+
+func TestLostReportOwnership(t *testing.T) {
+	f := newFixture(t)
+	ownerToken := f.createUser("owner", "owner@example.com", false)
+	otherToken := f.createUser("other", "other@example.com", false)
+	adminToken := f.createUser("a1", "a1@example.com", true)
+	lostID := f.createLostReport("owner", f.createVehicle("owner"))
+	path := "/api/lost_reports/" + strconv.Itoa(lostID)
+
+	expectStatus(t, f.do("GET", path, ownerToken, nil), http.StatusOK)
+	expectStatus(t, f.do("GET", path, adminToken, nil), http.StatusOK)
+	expectStatus(t, f.do("GET", path, otherToken, nil), http.StatusForbidden)
+	expectStatus(t, f.do("GET", "/api/lost_reports/9999", ownerToken, nil), http.StatusNotFound)
+
+	expectStatus(t, f.do("GET", "/api/results/"+strconv.Itoa(lostID), ownerToken, nil), http.StatusOK)
+	expectStatus(t, f.do("GET", "/api/results/"+strconv.Itoa(lostID), otherToken, nil), http.StatusForbidden)
+
+	expectStatus(t, f.do("PUT", path, otherToken, map[string]string{"address": "Jl. Lain"}), http.StatusForbidden)
+	expectStatus(t, f.do("PUT", path, ownerToken, map[string]string{"address": "Jl. Baru"}), http.StatusOK)
+
+	expectStatus(t, f.do("DELETE", path, otherToken, nil), http.StatusForbidden)
+	expectStatus(t, f.do("DELETE", path, ownerToken, nil), http.StatusNoContent)
+	expectStatus(t, f.do("GET", path, ownerToken, nil), http.StatusNotFound)
+}
+
+func TestResultsOrderingAndFilters(t *testing.T) {
+	f := newFixture(t)
+	ownerToken := f.createUser("owner", "owner@example.com", false)
+	adminToken := f.createUser("a1", "a1@example.com", true)
+	lostID := f.createLostReport("owner", f.createVehicle("owner"))
+	ctx := context.Background()
+	repos := f.store.Repos()
+
+	cam := database.Camera{Name: "Semanggi", Latitude: -6.22, Longitude: 106.814, IsActive: true}
+	if err := repos.Cameras.Create(ctx, &cam); err != nil {
+		t.Fatal(err)
+	}
+	// Nama file tidak memuat "person_" atau "motor_"; jenis gambar harus
+	// diambil dari kolom deteksinya.
+	imageID := func(path string) sql.NullInt64 {
+		img := database.Image{StoragePath: path}
+		if err := repos.Images.Create(ctx, &img); err != nil {
+			t.Fatal(err)
+		}
+		return sql.NullInt64{Int64: img.ImageID, Valid: true}
+	}
+	dets := []database.Detected{
+		{CameraID: int(cam.CameraID), PersonImageID: imageID("uploads/detected/1.jpg"), MotorcycleImageID: imageID("uploads/detected/2.jpg")},
+		{CameraID: int(cam.CameraID), MotorcycleImageID: imageID("/uploads/detected/3.jpg")},
+		{CameraID: int(cam.CameraID)},
+		{CameraID: int(cam.CameraID)},
+	}
+	var ids []int64
+	for i, score := range []float64{0.6, 0.9, 0.6, 0.3} {
+		dets[i].Timestamp = time.Now()
+		if err := repos.Detected.Create(ctx, &dets[i]); err != nil {
+			t.Fatal(err)
+		}
+		sp := database.Suspect{DetectedID: int64(dets[i].DetectedID), LostID: int64(lostID), FinalScore: score}
+		if err := repos.Suspects.Create(ctx, &sp); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, sp.SuspectID)
+	}
+	path := "/api/results/" + strconv.Itoa(lostID)
+	get := func(query string) server.ResultResponse {
+		t.Helper()
+		rec := f.do("GET", path+query, ownerToken, nil)
+		expectStatus(t, rec, http.StatusOK)
+		var res server.ResultResponse
+		if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	res := get("")
+	var order []int64
+	for _, sp := range res.Suspects {
+		order = append(order, sp.SuspectID)
+	}
+	// Skor sama diurutkan berdasarkan suspect_id.
+	if want := []int64{ids[1], ids[0], ids[2], ids[3]}; len(order) != 4 || order[0] != want[0] || order[1] != want[1] || order[2] != want[2] || order[3] != want[3] {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	first := res.Suspects[1]
+	if first.PersonEvidenceImageURL == nil || *first.PersonEvidenceImageURL != "/uploads/detected/1.jpg" ||
+		first.MotorEvidenceImageURL == nil || *first.MotorEvidenceImageURL != "/uploads/detected/2.jpg" {
+		t.Errorf("evidence urls = %v, %v", first.PersonEvidenceImageURL, first.MotorEvidenceImageURL)
+	}
+	if top := res.Suspects[0]; top.PersonEvidenceImageURL != nil || top.MotorEvidenceImageURL == nil || *top.MotorEvidenceImageURL != "/uploads/detected/3.jpg" {
+		t.Errorf("motor-only suspect urls = %v, %v", top.PersonEvidenceImageURL, top.MotorEvidenceImageURL)
+	}
+	if res.Total != 4 || res.Limit != 20 || res.AnalysisStatus != server.AnalysisStatusNotStarted || res.AnalysisRun != nil || res.LostReportStatus != database.StatusLostReportBelumDiproses {
+		t.Errorf("result = %+v", res)
+	}
+
+	res = get("?min_score=0.5&limit=1&offset=1")
+	if res.Total != 3 || len(res.Suspects) != 1 || res.Suspects[0].SuspectID != ids[0] {
+		t.Errorf("filtered result = %+v", res)
+	}
+	if res = get("?offset=10"); res.Total != 4 || res.Suspects == nil || len(res.Suspects) != 0 {
+		t.Errorf("offset past end = %+v", res)
+	}
+
+	expectStatus(t, f.do("PUT", "/api/lost_reports/"+strconv.Itoa(lostID), adminToken, map[string]string{"status": database.StatusLostReportSedangDiproses}), http.StatusOK)
+	// Status laporan tidak lagi menentukan status analisis.
+	if res = get(""); res.AnalysisStatus != server.AnalysisStatusNotStarted || res.LostReportStatus != database.StatusLostReportSedangDiproses {
+		t.Errorf("after status change: analysis_status = %q, lost_report_status = %q", res.AnalysisStatus, res.LostReportStatus)
+	}
+
+	for _, q := range []string{"?limit=0", "?limit=101", "?offset=-1", "?min_score=1.5", "?min_score=x"} {
+		expectStatus(t, f.do("GET", path+q, ownerToken, nil), http.StatusBadRequest)
+	}
+}
+
+func TestLostReportStatusRules(t *testing.T) {
+	f := newFixture(t)
+	ownerToken := f.createUser("owner", "owner@example.com", false)
+	adminToken := f.createUser("a1", "a1@example.com", true)
+	lostID := f.createLostReport("owner", f.createVehicle("owner"))
+	path := "/api/lost_reports/" + strconv.Itoa(lostID)
+
+	currentStatus := func() string {
+		t.Helper()
+		lr, err := f.store.Repos().LostReports.GetByID(context.Background(), lostID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return lr.Status
+	}
+
+	// Pemilik tidak boleh mengubah status; field status diabaikan.
+	f.do("PUT", path, ownerToken, map[string]string{"status": database.StatusLostReportSudahDitemukan})
+	if got := currentStatus(); got != database.StatusLostReportBelumDiproses {
+		t.Errorf("owner changed status to %q", got)
+	}
+
+	expectStatus(t, f.do("PUT", path, adminToken, map[string]string{"status": "HILANG"}), http.StatusBadRequest)
+
+	expectStatus(t, f.do("PUT", path, adminToken, map[string]string{"status": database.StatusLostReportSedangDiproses}), http.StatusOK)
+	if got := currentStatus(); got != database.StatusLostReportSedangDiproses {
+		t.Errorf("status = %q, want %q", got, database.StatusLostReportSedangDiproses)
+	}
+
+	expectStatus(t, f.do("GET", "/api/lost_reports?status=HILANG", adminToken, nil), http.StatusBadRequest)
+
+	rec := f.do("GET", "/api/lost_reports?status="+database.StatusLostReportSedangDiproses, adminToken, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var list []server.LostReportResponse
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].LostID != lostID {
+		t.Errorf("filtered list = %+v, want report %d only", list, lostID)
+	}
+}
+
+func TestVehicleOwnership(t *testing.T) {
+	f := newFixture(t)
+	ownerToken := f.createUser("owner", "owner@example.com", false)
+	otherToken := f.createUser("other", "other@example.com", false)
+	path := "/api/vehicles/" + strconv.Itoa(int(f.createVehicle("owner")))
+
+	expectStatus(t, f.doMultipart("PUT", path, otherToken, map[string]string{"color": "Merah"}), http.StatusForbidden)
+	expectStatus(t, f.doMultipart("PUT", path, ownerToken, map[string]string{"color": "Merah"}), http.StatusOK)
+	expectStatus(t, f.doMultipart("PUT", "/api/vehicles/9999", ownerToken, map[string]string{"color": "Merah"}), http.StatusNotFound)
+
+	expectStatus(t, f.do("DELETE", path, otherToken, nil), http.StatusForbidden)
+	expectStatus(t, f.do("DELETE", path, ownerToken, nil), http.StatusNoContent)
+}
+
+func TestUserOwnership(t *testing.T) {
+	f := newFixture(t)
+	ownerToken := f.createUser("owner", "owner@example.com", false)
+	otherToken := f.createUser("other", "other@example.com", false)
+	adminToken := f.createUser("a1", "a1@example.com", true)
+
+	expectStatus(t, f.do("PUT", "/api/users/owner", otherToken, map[string]string{"name": "Bukan"}), http.StatusForbidden)
+	expectStatus(t, f.do("PUT", "/api/users/owner", ownerToken, map[string]string{"name": "Pemilik"}), http.StatusOK)
+	expectStatus(t, f.do("PUT", "/api/users/owner", adminToken, map[string]string{"name": "Diubah Admin"}), http.StatusOK)
+
+	u, err := f.store.Repos().Users.FindByID(context.Background(), "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "Diubah Admin" {
+		t.Errorf("name = %q, want %q", u.Name, "Diubah Admin")
+	}
+}
+
+func TestHealthProbes(t *testing.T) {
+	f := newFixture(t)
+
+	expectStatus(t, f.do("GET", "/healthz", "", nil), http.StatusOK)
+
+	rec := f.do("GET", "/readyz", "", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var resp server.ReadinessResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != "ready" {
+		t.Errorf("status = %q, want ready", resp.Status)
+	}
+	for _, check := range []string{"storage", "upload_dir"} {
+		if resp.Checks[check]["status"] != "up" {
+			t.Errorf("check %s = %v, want up", check, resp.Checks[check])
+		}
+	}
+}
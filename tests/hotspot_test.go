@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/geo"
+)
+
+func TestClusterHotspots(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*3600)
+	at := func(day, hour int) time.Time { return time.Date(2024, 5, day, hour, 0, 0, 0, wib) }
+	incidents := []geo.Incident{
+		// Sekitar Bundaran HI, jarak antar titik < 300 m.
+		{ID: 1, Latitude: -6.1950, Longitude: 106.8230, Time: at(6, 22)},
+		{ID: 2, Latitude: -6.1960, Longitude: 106.8240, Time: at(7, 22)},
+		{ID: 3, Latitude: -6.1940, Longitude: 106.8220, Time: at(13, 9)},
+		{ID: 4, Latitude: -6.1970, Longitude: 106.8235, Time: at(8, 22)},
+		// Dua titik di Bandung dan satu titik terpencil.
+		{ID: 5, Latitude: -6.8850, Longitude: 107.6130, Time: at(4, 1)},
+		{ID: 6, Latitude: -6.8855, Longitude: 107.6135, Time: at(4, 2)},
+		{ID: 7, Latitude: -7.2500, Longitude: 112.7500, Time: at(5, 3)},
+	}
+
+	hotspots := geo.Cluster(incidents, geo.ClusterOptions{Method: geo.MethodDBSCAN, RadiusKm: 0.5, MinPoints: 2, Location: wib})
+	if len(hotspots) != 2 {
+		t.Fatalf("got %d hotspots, want 2: %+v", len(hotspots), hotspots)
+	}
+	jakarta := hotspots[0]
+	if !reflect.DeepEqual(jakarta.IncidentIDs, []int{1, 2, 3, 4}) || jakarta.RadiusKm > 0.5 {
+		t.Errorf("jakarta hotspot = %+v", jakarta)
+	}
+	if !reflect.DeepEqual(jakarta.PeakHours, []int{22}) || jakarta.HourCounts[22] != 3 {
+		t.Errorf("peak hours = %v, hour counts = %v", jakarta.PeakHours, jakarta.HourCounts)
+	}
+	// 6, 13 Mei 2024 Senin; 7 Selasa; 8 Rabu.
+	if w := jakarta.WeekdayCounts; w[time.Monday] != 2 || w[time.Tuesday] != 1 || w[time.Wednesday] != 1 {
+		t.Errorf("weekday counts = %v", w)
+	}
+	if !reflect.DeepEqual(hotspots[1].IncidentIDs, []int{5, 6}) {
+		t.Errorf("bandung hotspot = %+v", hotspots[1])
+	}
+
+	// Dengan jendela waktu, kejadian pukul 09.00 tidak bertetangga dengan
+	// kejadian pukul 22.00 walau lokasinya berdekatan.
+	timed := geo.Cluster(incidents, geo.ClusterOptions{Method: geo.MethodDBSCAN, RadiusKm: 0.5, MinPoints: 2, TimeWindow: 3 * time.Hour, Location: wib})
+	if len(timed) != 2 || !reflect.DeepEqual(timed[0].IncidentIDs, []int{1, 2, 4}) || !reflect.DeepEqual(timed[1].IncidentIDs, []int{5, 6}) {
+		t.Errorf("hotspots with time window = %+v", timed)
+	}
+	// Selisih jam dihitung melingkar lewat tengah malam.
+	night := []geo.Incident{
+		{ID: 1, Latitude: -6.1950, Longitude: 106.8230, Time: at(6, 23)},
+		{ID: 2, Latitude: -6.1960, Longitude: 106.8240, Time: at(7, 1)},
+	}
+	if got := geo.Cluster(night, geo.ClusterOptions{Method: geo.MethodDBSCAN, RadiusKm: 0.5, MinPoints: 2, TimeWindow: 3 * time.Hour, Location: wib}); len(got) != 1 {
+		t.Errorf("23.00 and 01.00: got %d hotspots, want 1", len(got))
+	}
+
+	if got := geo.Cluster(incidents, geo.ClusterOptions{Method: geo.MethodDBSCAN, RadiusKm: 0.5, MinPoints: 3}); len(got) != 1 {
+		t.Errorf("min_points 3: got %d hotspots, want 1", len(got))
+	}
+	grid := geo.Cluster(incidents, geo.ClusterOptions{Method: geo.MethodGrid, RadiusKm: 5, MinPoints: 2})
+	if len(grid) != 2 || grid[0].Count() != 4 || grid[1].Count() != 2 {
+		t.Errorf("grid hotspots = %+v", grid)
+	}
+
+	geo.MarkCoverage(hotspots, []geo.Position{{Latitude: -6.1950, Longitude: 106.8230}}, 1)
+	if hotspots[0].UnderCovered || hotspots[0].NearestCameraKm == nil || *hotspots[0].NearestCameraKm > 0.5 {
+		t.Errorf("jakarta coverage = %+v", hotspots[0])
+	}
+	if !hotspots[1].UnderCovered {
+		t.Error("bandung hotspot should be under-covered")
+	}
+
+	f := hotspots[0].Feature()
+	coords := f.Geometry.Coordinates.([]float64)
+	if f.Geometry.Type != "Point" || coords[0] < 106 || coords[1] > -6 {
+		t.Errorf("feature geometry = %+v, want [lon, lat]", f.Geometry)
+	}
+}
+
+func TestHotspotsEndpoint(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	user := f.createUser("u1", "u1@example.com", false)
+	vehicleID := f.createVehicle("u1")
+	ctx := context.Background()
+
+	report := func(lat, lon float64, ts time.Time) {
+		t.Helper()
+		lr := database.LostReport{UserID: "u1", Timestamp: ts, VehicleID: int(vehicleID), Address: "Jl. Sudirman",
+			Latitude: &lat, Longitude: &lon, Status: database.StatusLostReportBelumDiproses}
+		if err := f.store.Repos().LostReports.Create(ctx, &lr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		report(-6.1950+float64(i)*0.001, 106.8230, now.Add(-time.Duration(i+1)*24*time.Hour-time.Duration(i)*time.Hour))
+		report(-6.9000+float64(i)*0.001, 107.6000, now.Add(-time.Duration(i+1)*24*time.Hour))
+	}
+	report(-6.1950, 106.8230, now.AddDate(-2, 0, 0))
+	for _, c := range []database.Camera{
+		{Name: "Bundaran HI", Latitude: -6.1955, Longitude: 106.8232, IsActive: true},
+		{Name: "Dago", Latitude: -6.9000, Longitude: 107.6000, IsActive: false},
+	} {
+		if err := f.store.Repos().Cameras.Create(ctx, &c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	get := func(query string) geo.FeatureCollection {
+		t.Helper()
+		rec := f.do("GET", "/api/admins/stats/hotspots"+query, admin, nil)
+		expectStatus(t, rec, http.StatusOK)
+		if ct := rec.Header().Get("Content-Type"); ct != "application/geo+json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		var fc geo.FeatureCollection
+		if err := json.NewDecoder(rec.Body).Decode(&fc); err != nil {
+			t.Fatal(err)
+		}
+		return fc
+	}
+
+	fc := get("")
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 2 {
+		t.Fatalf("hotspots = %+v", fc)
+	}
+	for _, feat := range fc.Features {
+		if feat.Properties["count"] != float64(3) {
+			t.Errorf("hotspot count = %v, want 3 (report outside range excluded)", feat.Properties["count"])
+		}
+	}
+
+	// Kamera Dago tidak aktif sehingga hanya hotspot Bandung yang kurang
+	// terpantau.
+	under := get("?under_covered=true&coverage_km=2")
+	if len(under.Features) != 1 || under.Features[0].Properties["under_covered"] != true {
+		t.Fatalf("under-covered hotspots = %+v", under)
+	}
+	if coords := under.Features[0].Geometry.Coordinates.([]interface{}); coords[0].(float64) < 107 {
+		t.Errorf("under-covered hotspot at %v, want Bandung", coords)
+	}
+
+	// Laporan di Jakarta terjadi pada jam yang berbeda-beda: hanya satu
+	// hotspot bila jendela waktunya dipersempit.
+	if fc := get("?window_hours=0.5&min_points=2"); len(fc.Features) != 1 {
+		t.Errorf("window_hours 0.5 returned %d hotspots, want 1", len(fc.Features))
+	}
+	if fc := get("?method=grid&radius_km=2&min_points=4"); len(fc.Features) != 0 {
+		t.Errorf("min_points 4 returned %d hotspots", len(fc.Features))
+	}
+
+	for _, q := range []string{"?method=kmeans", "?radius_km=0", "?min_points=abc", "?tz=Mars/Olympus", "?window_hours=13", "?under_covered=maybe", "?from=2024-02-01&to=2024-01-01"} {
+		expectStatus(t, f.do("GET", "/api/admins/stats/hotspots"+q, admin, nil), http.StatusBadRequest)
+	}
+	expectStatus(t, f.do("GET", "/api/admins/stats/hotspots", user, nil), http.StatusForbidden)
+}
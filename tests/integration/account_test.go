@@ -0,0 +1,58 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+func TestUserTokensSingleUse(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	repo := testStore.Repos().UserTokens
+	now := time.Now()
+
+	for _, tok := range []database.UserToken{
+		{TokenHash: "h1", UserID: "budi", Purpose: database.TokenPurposePasswordReset, ExpiresAt: now.Add(time.Hour)},
+		{TokenHash: "h2", UserID: "budi", Purpose: database.TokenPurposePasswordReset, ExpiresAt: now.Add(-time.Minute)},
+		{TokenHash: "h3", UserID: "budi", Purpose: database.TokenPurposePasswordReset, ExpiresAt: now.Add(time.Hour)},
+	} {
+		if err := repo.Create(ctx, &tok); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := repo.Consume(ctx, "h1", database.TokenPurposeEmailVerification, now); !errors.Is(err, database.ErrTokenInvalid) {
+		t.Errorf("wrong purpose: err = %v", err)
+	}
+	got, err := repo.Consume(ctx, "h1", database.TokenPurposePasswordReset, now)
+	if err != nil || got.UserID != "budi" {
+		t.Fatalf("Consume = %+v, %v", got, err)
+	}
+	if _, err := repo.Consume(ctx, "h1", database.TokenPurposePasswordReset, now); !errors.Is(err, database.ErrTokenInvalid) {
+		t.Errorf("reused token: err = %v", err)
+	}
+	if _, err := repo.Consume(ctx, "h2", database.TokenPurposePasswordReset, now); !errors.Is(err, database.ErrTokenInvalid) {
+		t.Errorf("expired token: err = %v", err)
+	}
+
+	if err := repo.InvalidateForUser(ctx, "budi", database.TokenPurposePasswordReset, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Consume(ctx, "h3", database.TokenPurposePasswordReset, now); !errors.Is(err, database.ErrTokenInvalid) {
+		t.Errorf("invalidated token: err = %v", err)
+	}
+
+	u, err := testStore.Repos().Users.FindByID(ctx, "budi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.EmailVerifiedAt == nil {
+		t.Error("fixture user budi should be verified")
+	}
+}
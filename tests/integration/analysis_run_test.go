@@ -0,0 +1,66 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+func TestAnalysisRunQueue(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	repos := testStore.Repos()
+
+	if _, err := repos.AnalysisRuns.GetLatestByLostReportID(ctx, 1); !errors.Is(err, database.ErrAnalysisRunNotFound) {
+		t.Fatalf("latest run without runs: err = %v", err)
+	}
+	runs := []database.AnalysisRun{{LostID: 2, RadiusKm: 5, WindowHours: 24}, {LostID: 1, RadiusKm: 2.5, WindowHours: 48}}
+	for i := range runs {
+		if err := repos.AnalysisRuns.Create(ctx, &runs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repos.AnalysisRuns.Create(ctx, &database.AnalysisRun{LostID: 1, RadiusKm: 5, WindowHours: 24}); err == nil {
+		t.Error("second active run for lost report 1 was accepted")
+	}
+
+	at := time.Date(2024, 5, 3, 9, 0, 0, 0, time.UTC)
+	claimed, err := repos.AnalysisRuns.Claim(ctx, "reid-v2", at)
+	if err != nil || claimed.RunID != runs[0].RunID || claimed.Status != database.AnalysisRunRunning || !claimed.StartedAt.Equal(at) {
+		t.Fatalf("claim = %+v, %v", claimed, err)
+	}
+	if err := repos.AnalysisRuns.Finish(ctx, runs[1].RunID, database.AnalysisRunCompleted, nil, "", at); !errors.Is(err, database.ErrAnalysisRunNotRunning) {
+		t.Errorf("finish queued run: err = %v", err)
+	}
+	scanned := 17
+	if err := repos.AnalysisRuns.Finish(ctx, claimed.RunID, database.AnalysisRunCompleted, &scanned, "", at.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repos.AnalysisRuns.GetLatestByLostReportID(ctx, 2)
+	if err != nil || got.Status != database.AnalysisRunCompleted || got.DetectionsScanned == nil || *got.DetectionsScanned != 17 || got.ScorerVersion != "reid-v2" {
+		t.Errorf("finished run = %+v, %v", got, err)
+	}
+
+	// Run dari laporan yang sudah dihapus tidak diambil worker.
+	if err := repos.LostReports.Delete(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repos.AnalysisRuns.Claim(ctx, "reid-v2", at); !errors.Is(err, database.ErrAnalysisRunNotFound) {
+		t.Errorf("claim with only deleted reports queued: err = %v", err)
+	}
+
+	// Setelah run selesai, laporan boleh diantrekan ulang.
+	rerun := database.AnalysisRun{LostID: 2, RadiusKm: 1, WindowHours: 12}
+	if err := repos.AnalysisRuns.Create(ctx, &rerun); err != nil {
+		t.Fatal(err)
+	}
+	list, err := repos.AnalysisRuns.ListByLostReportID(ctx, 2)
+	if err != nil || len(list) != 2 || list[0].RunID != rerun.RunID {
+		t.Errorf("runs of lost report 2 = %+v, %v", list, err)
+	}
+}
@@ -0,0 +1,289 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/server"
+)
+
+const fixturePassword = "rahasia123"
+
+func doJSON(t *testing.T, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	testHandler.ServeHTTP(rec, req)
+	return rec
+}
+
+func doMultipart(t *testing.T, method, path, token string, fields map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for k, v := range fields {
+		mw.WriteField(k, v)
+	}
+	mw.Close()
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	testHandler.ServeHTTP(rec, req)
+	return rec
+}
+
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if rec.Code != want {
+		t.Fatalf("status = %d, want %d; body: %s", rec.Code, want, rec.Body.String())
+	}
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+		t.Fatalf("decode response: %v; body: %s", err, rec.Body.String())
+	}
+}
+
+func login(t *testing.T, email string) string {
+	t.Helper()
+	rec := doJSON(t, "POST", "/auth/login", "", map[string]string{"email": email, "password": fixturePassword})
+	expectStatus(t, rec, http.StatusOK)
+	var resp server.LoginResponse
+	decode(t, rec, &resp)
+	return resp.Token
+}
+
+func TestLoginWithFixtureUsers(t *testing.T) {
+	resetDB(t)
+
+	rec := doJSON(t, "POST", "/auth/login", "", map[string]string{"email": "admin@example.com", "password": fixturePassword})
+	expectStatus(t, rec, http.StatusOK)
+	var resp server.LoginResponse
+	decode(t, rec, &resp)
+	if !resp.IsAdmin {
+		t.Errorf("admin fixture should log in as admin")
+	}
+
+	rec = doJSON(t, "POST", "/auth/login", "", map[string]string{"email": "budi@example.com", "password": "salah"})
+	expectStatus(t, rec, http.StatusUnauthorized)
+}
+
+func TestGetLostReportByIDReturnsCoordinates(t *testing.T) {
+	resetDB(t)
+
+	lr, err := testStore.Repos().LostReports.GetByID(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lr.Latitude == nil || lr.Longitude == nil {
+		t.Fatalf("latitude/longitude not loaded: %+v", lr)
+	}
+	if *lr.Latitude != -6.1862 || *lr.Longitude != 106.8281 {
+		t.Errorf("coordinates = (%v, %v), want (-6.1862, 106.8281)", *lr.Latitude, *lr.Longitude)
+	}
+
+	list, err := database.ListLostReportsByUserID(context.Background(), testDB, "budi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Latitude == nil {
+		t.Errorf("ListLostReportsByUserID did not load coordinates: %+v", list)
+	}
+}
+
+func TestCreateSuspectTx(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+
+	tx, err := testDB.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	sp := database.Suspect{DetectedID: 1, LostID: 1, PersonScore: 0.8, MotorScore: 0.9, FinalScore: 0.85, CreatedAt: time.Now()}
+	if err := database.CreateSuspectTx(ctx, tx, &sp); err != nil {
+		t.Fatalf("CreateSuspectTx: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := database.GetSuspectByID(ctx, testDB, sp.SuspectID)
+	if err != nil {
+		t.Fatalf("suspect created by CreateSuspectTx not readable: %v", err)
+	}
+	if got.LostID != 1 || got.DetectedID != 1 {
+		t.Errorf("got suspect %+v", got)
+	}
+}
+
+func TestLostReportLifecycle(t *testing.T) {
+	resetDB(t)
+	budi := login(t, "budi@example.com")
+	siti := login(t, "siti@example.com")
+	admin := login(t, "admin@example.com")
+
+	rec := doMultipart(t, "POST", "/api/lost_reports", budi, map[string]string{
+		"vehicle_id": "1",
+		"address":    "Jl. Medan Merdeka",
+		"latitude":   "-6.1754",
+		"longitude":  "106.8272",
+		"timestamp":  "2024-05-04T09:00:00Z",
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	var created server.LostReportResponse
+	decode(t, rec, &created)
+	path := "/api/lost_reports/" + strconv.Itoa(created.LostID)
+
+	rec = doJSON(t, "GET", path, budi, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var got server.LostReportResponse
+	decode(t, rec, &got)
+	if got.Latitude == nil || *got.Latitude != -6.1754 {
+		t.Errorf("latitude = %v, want -6.1754", got.Latitude)
+	}
+	if got.Vehicle == nil || got.Vehicle.PlateNumber != "B 1234 XYZ" {
+		t.Errorf("vehicle info = %+v", got.Vehicle)
+	}
+
+	expectStatus(t, doJSON(t, "GET", path, siti, nil), http.StatusForbidden)
+
+	expectStatus(t, doJSON(t, "PUT", path, admin, map[string]string{"status": database.StatusLostReportSedangDiproses}), http.StatusOK)
+	rec = doJSON(t, "GET", "/api/lost_reports?status="+database.StatusLostReportSedangDiproses, admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var list []server.LostReportResponse
+	decode(t, rec, &list)
+	if len(list) != 2 {
+		t.Errorf("got %d reports with status %s, want 2", len(list), database.StatusLostReportSedangDiproses)
+	}
+
+	expectStatus(t, doJSON(t, "DELETE", path, budi, nil), http.StatusNoContent)
+	expectStatus(t, doJSON(t, "GET", path, budi, nil), http.StatusNotFound)
+}
+
+func TestBatchSuspectsAndResults(t *testing.T) {
+	resetDB(t)
+	budi := login(t, "budi@example.com")
+	siti := login(t, "siti@example.com")
+	admin := login(t, "admin@example.com")
+
+	suspects := []database.Suspect{
+		{DetectedID: 1, LostID: 1, PersonScore: 0.7, MotorScore: 0.9, FinalScore: 0.82},
+		{DetectedID: 2, LostID: 1, PersonScore: 0.6, MotorScore: 0.6, FinalScore: 0.6},
+		{DetectedID: 3, LostID: 1, PersonScore: 0.9, MotorScore: 0.95, FinalScore: 0.93},
+	}
+	// Deteksi 3 punya gambar orang dan motor sekaligus; nama file sengaja
+	// tidak memuat jenis gambarnya.
+	ctx := context.Background()
+	repos := testStore.Repos()
+	personImg := database.Image{StoragePath: "uploads/detected/a.jpg"}
+	motorImg := database.Image{StoragePath: "uploads/detected/b.jpg"}
+	for _, img := range []*database.Image{&personImg, &motorImg} {
+		if err := repos.Images.Create(ctx, img); err != nil {
+			t.Fatal(err)
+		}
+	}
+	det := database.Detected{CameraID: 3, Timestamp: time.Date(2024, 5, 2, 11, 0, 0, 0, time.UTC),
+		PersonImageID: sql.NullInt64{Int64: personImg.ImageID, Valid: true}, MotorcycleImageID: sql.NullInt64{Int64: motorImg.ImageID, Valid: true}}
+	if err := repos.Detected.Update(ctx, 3, &det); err != nil {
+		t.Fatal(err)
+	}
+
+	expectStatus(t, doJSON(t, "POST", "/api/suspects/batch", budi, suspects), http.StatusForbidden)
+	expectStatus(t, doJSON(t, "POST", "/api/suspects/batch", admin, suspects), http.StatusCreated)
+
+	expectStatus(t, doJSON(t, "GET", "/api/results/1", siti, nil), http.StatusForbidden)
+
+	rec := doJSON(t, "GET", "/api/results/1", budi, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var result server.ResultResponse
+	decode(t, rec, &result)
+	if len(result.Suspects) != 3 {
+		t.Fatalf("got %d suspects, want 3", len(result.Suspects))
+	}
+	if result.Suspects[0].FinalScore != 0.93 || result.Suspects[0].Camera.Name != "Bandung Dago" {
+		t.Errorf("suspects not ordered by final_score: %+v", result.Suspects[0])
+	}
+	top := result.Suspects[0]
+	if top.PersonEvidenceImageURL == nil || *top.PersonEvidenceImageURL != "/uploads/detected/a.jpg" ||
+		top.MotorEvidenceImageURL == nil || *top.MotorEvidenceImageURL != "/uploads/detected/b.jpg" {
+		t.Errorf("evidence urls = %v, %v", top.PersonEvidenceImageURL, top.MotorEvidenceImageURL)
+	}
+	if result.Total != 3 || result.AnalysisStatus != server.AnalysisStatusNotStarted || result.LostReportStatus != database.StatusLostReportBelumDiproses {
+		t.Errorf("result = %+v", result)
+	}
+
+	rec = doJSON(t, "GET", "/api/results/1?min_score=0.7&limit=1&offset=1", admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &result)
+	if result.Total != 2 || len(result.Suspects) != 1 || result.Suspects[0].FinalScore != 0.82 {
+		t.Errorf("filtered result = %+v", result)
+	}
+}
+
+func TestDetectedProximitySearch(t *testing.T) {
+	resetDB(t)
+	admin := login(t, "admin@example.com")
+
+	rec := doJSON(t, "GET", "/api/detected?lat=-6.1862&lon=106.8281&radius_km=5&start_time=2024-05-02T00:00:00Z&end_time=2024-05-03T00:00:00Z", admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var list []server.DetectedResponse
+	decode(t, rec, &list)
+	if len(list) != 2 {
+		t.Fatalf("got %d detections within 5 km, want 2", len(list))
+	}
+	for _, d := range list {
+		if d.CameraID == 3 {
+			t.Errorf("camera in Bandung should be outside the radius")
+		}
+	}
+}
+
+func TestVehiclesOfUser(t *testing.T) {
+	resetDB(t)
+	budi := login(t, "budi@example.com")
+	siti := login(t, "siti@example.com")
+
+	rec := doJSON(t, "GET", "/api/vehicles/my", budi, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var vehicles []server.VehicleResponse
+	decode(t, rec, &vehicles)
+	if len(vehicles) != 1 || vehicles[0].PlateNumber != "B 1234 XYZ" {
+		t.Errorf("vehicles = %+v", vehicles)
+	}
+
+	expectStatus(t, doMultipart(t, "PUT", "/api/vehicles/1", siti, map[string]string{"color": "Merah"}), http.StatusForbidden)
+	expectStatus(t, doMultipart(t, "PUT", "/api/vehicles/1", budi, map[string]string{"color": "Merah"}), http.StatusOK)
+
+	v, err := testStore.Repos().Vehicles.GetByID(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Color != "Merah" {
+		t.Errorf("color = %q, want Merah", v.Color)
+	}
+}
@@ -0,0 +1,69 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+func TestAuditLogHashChain(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	actor := "budi"
+
+	for i, action := range []string{"user.update", "user.delete"} {
+		tx, err := testStore.BeginTx(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := database.AuditEntry{
+			ActorUserID: &actor,
+			AuthMethod:  database.AuditAuthJWT,
+			Action:      action,
+			EntityType:  "user",
+			EntityID:    "siti",
+			// Spasi dan urutan key harus tersimpan apa adanya agar hash cocok.
+			Before:    json.RawMessage(`{"name": "Siti", "email":"siti@example.com"}`),
+			RequestID: "req-" + action,
+		}
+		if i == 1 {
+			e.Before, e.After = nil, json.RawMessage(`{"deleted":true}`)
+		}
+		if err := tx.Repos().AuditLog.Append(ctx, &e); err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo := testStore.Repos().AuditLog
+	result, err := repo.Verify(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Valid || result.Checked != 2 {
+		t.Fatalf("Verify = %+v", result)
+	}
+
+	entries, err := repo.List(ctx, database.AuditFilter{EntityID: "siti", Action: "user.update"})
+	if err != nil || len(entries) != 1 || entries[0].After != nil {
+		t.Fatalf("List = %+v, %v", entries, err)
+	}
+
+	// Di luar transaksi LOCK TABLE ditolak, sehingga rantai tidak bisa bercabang.
+	if err := repo.Append(ctx, &database.AuditEntry{AuthMethod: database.AuditAuthAnonymous, Action: "x", EntityType: "x"}); err == nil {
+		t.Error("Append outside a transaction succeeded")
+	}
+
+	if _, err := testDB.ExecContext(ctx, `UPDATE audit_log SET action = 'user.create'`); err == nil {
+		t.Error("UPDATE on audit_log succeeded")
+	}
+	if _, err := testDB.ExecContext(ctx, `DELETE FROM audit_log`); err == nil {
+		t.Error("DELETE on audit_log succeeded")
+	}
+}
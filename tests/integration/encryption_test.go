@@ -0,0 +1,131 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/server"
+)
+
+func TestRotateUserFields(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+
+	ring, err := testEncryption.KeyRing()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fixture masih plaintext: rotate pertama mengenkripsi semua baris.
+	updated, err := database.RotateUserFields(ctx, testDB, ring, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var users int
+	testDB.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&users)
+	if updated != users {
+		t.Errorf("updated %d rows, want %d", updated, users)
+	}
+	var nik, phone, hash string
+	if err := testDB.QueryRowContext(ctx, `SELECT nik, phone, nik_hash FROM users WHERE user_id = 'budi'`).Scan(&nik, &phone, &hash); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(nik, "enc:v1:k1:") || !strings.HasPrefix(phone, "enc:v1:k1:") || hash != ring.BlindIndex("3171010101900001") {
+		t.Errorf("budi after rotation: nik=%q phone=%q hash=%q", nik, phone, hash)
+	}
+	if again, err := database.RotateUserFields(ctx, testDB, ring, 2); err != nil || again != 0 {
+		t.Errorf("second rotation updated %d rows, %v", again, err)
+	}
+
+	rec := doJSON(t, "POST", "/auth/login", "", map[string]string{"email": "budi@example.com", "password": fixturePassword})
+	expectStatus(t, rec, http.StatusOK)
+	var resp server.LoginResponse
+	decode(t, rec, &resp)
+	if resp.NIK != "3171010101900001" || resp.Phone != "081234567890" {
+		t.Errorf("login after rotation = %q/%q", resp.NIK, resp.Phone)
+	}
+	admin := login(t, "admin@example.com")
+	rec = doJSON(t, "GET", "/api/users?nik=3171010101900001", admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var found database.User
+	decode(t, rec, &found)
+	if found.UserID != "budi" {
+		t.Errorf("lookup by NIK = %+v", found)
+	}
+
+	// Key baru: nilai k1 di-rotate ke k2 dan tetap terbaca.
+	rotatedCfg := testEncryption
+	rotatedCfg.Keys = map[string]string{"k1": testEncryption.Keys["k1"], "k2": "amFnYS10ZXN0LWZpZWxkLWtleS0yLTAxMjM0NTY3ODk="}
+	rotatedCfg.ActiveKeyID = "k2"
+	ring2, err := rotatedCfg.KeyRing()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated, err := database.RotateUserFields(ctx, testDB, ring2, 2); err != nil || updated != users {
+		t.Errorf("rotation to k2 updated %d rows, %v", updated, err)
+	}
+	testDB.QueryRowContext(ctx, `SELECT nik FROM users WHERE user_id = 'budi'`).Scan(&nik)
+	if pt, err := ring2.Decrypt(nik); !strings.HasPrefix(nik, "enc:v1:k2:") || err != nil || pt != "3171010101900001" {
+		t.Errorf("budi nik after k2 rotation = %q (%q, %v)", nik, pt, err)
+	}
+}
+
+func TestRotateDocumentOCRFieldsRollsBackFailedBatch(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+
+	ring, err := testEncryption.KeyRing()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos := testStore.Repos()
+	var ids []int64
+	for _, path := range []string{"uploads/images/ktp_a.jpg", "uploads/images/ktp_b.jpg"} {
+		img := database.Image{StoragePath: path}
+		if err := repos.Images.Create(ctx, &img); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, img.ImageID)
+	}
+	valid, err := ring.Encrypt("3171010101900001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Baris kedua memakai key yang tidak ada di ring sehingga batch gagal
+	// setelah baris pertama sudah di-UPDATE.
+	for i, nik := range []string{valid, "enc:v1:k9:rusak"} {
+		if _, err := testDB.ExecContext(ctx, `INSERT INTO document_ocr_results (image_id, document_type, status, nik)
+            VALUES ($1, 'KTP', 'DONE', $2)`, ids[i], nik); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rotatedCfg := testEncryption
+	rotatedCfg.Keys = map[string]string{"k1": testEncryption.Keys["k1"], "k2": "amFnYS10ZXN0LWZpZWxkLWtleS0yLTAxMjM0NTY3ODk="}
+	rotatedCfg.ActiveKeyID = "k2"
+	ring2, err := rotatedCfg.KeyRing()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated, err := database.RotateDocumentOCRFields(ctx, testDB, ring2, 10); err == nil {
+		t.Fatalf("rotation with an undecryptable row updated %d rows without error", updated)
+	}
+	var nik string
+	if err := testDB.QueryRowContext(ctx, `SELECT nik FROM document_ocr_results WHERE image_id = $1`, ids[0]).Scan(&nik); err != nil {
+		t.Fatal(err)
+	}
+	if nik != valid {
+		t.Errorf("first row after failed batch = %q, want unchanged %q", nik, valid)
+	}
+
+	if _, err := testDB.ExecContext(ctx, `DELETE FROM document_ocr_results WHERE image_id = $1`, ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	if updated, err := database.RotateDocumentOCRFields(ctx, testDB, ring2, 10); err != nil || updated != 1 {
+		t.Errorf("rotation after removing the bad row updated %d rows, %v", updated, err)
+	}
+}
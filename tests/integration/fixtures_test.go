@@ -0,0 +1,97 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+var fixturesPath string
+
+// fixtureTables menentukan urutan pemuatan fixture (mengikuti foreign key)
+// beserta kolom ID serial yang sequence-nya perlu disesuaikan.
+var fixtureTables = []struct {
+	file  string
+	table string
+	idCol string
+}{
+	{"users.yml", "users", ""},
+	{"admins.yml", "admins", ""},
+	{"vehicles.yml", "vehicle", "vehicle_id"},
+	{"cameras.yml", "cameras", "camera_id"},
+	{"detected.yml", "detected", "detected_id"},
+	{"lost_reports.yml", "lost_report", "lost_id"},
+}
+
+var truncateTables = []string{
+	"vehicle_transfers", "analysis_limits", "lost_report_search", "analysis_runs", "suspect_labels", "suspect", "lost_report", "detected", "cameras", "vehicle",
+	"audit_log", "erasure_requests", "service_api_keys", "user_tokens", "admin_recovery_codes", "admin_mfa", "admins", "users", "images",
+}
+
+// resetDB mengosongkan semua tabel lalu memuat ulang fixture, sehingga
+// setiap test dimulai dari data yang sama.
+func resetDB(t *testing.T) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, err := testDB.ExecContext(ctx, "TRUNCATE "+strings.Join(truncateTables, ", ")+" RESTART IDENTITY CASCADE"); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+
+	for _, ft := range fixtureTables {
+		rows, err := readFixture(ft.file)
+		if err != nil {
+			t.Fatalf("read fixture %s: %v", ft.file, err)
+		}
+		for _, row := range rows {
+			if err := insertRow(ctx, ft.table, row); err != nil {
+				t.Fatalf("load fixture %s: %v", ft.file, err)
+			}
+		}
+		if ft.idCol != "" && len(rows) > 0 {
+			q := fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', '%s'), (SELECT MAX(%s) FROM %s))", ft.table, ft.idCol, ft.idCol, ft.table)
+			if _, err := testDB.ExecContext(ctx, q); err != nil {
+				t.Fatalf("reset sequence %s: %v", ft.table, err)
+			}
+		}
+	}
+}
+
+func readFixture(name string) ([]map[string]interface{}, error) {
+	data, err := os.ReadFile(filepath.Join(fixturesPath, name))
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]interface{}
+	if err := yaml.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func insertRow(ctx context.Context, table string, row map[string]interface{}) error {
+	cols := make([]string, 0, len(row))
+	for col := range row {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	placeholders := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+	for i, col := range cols {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = row[col]
+	}
+
+	q := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(cols, ", "), strings.Join(placeholders, ", "))
+	_, err := testDB.ExecContext(ctx, q, args...)
+	return err
+}
@@ -0,0 +1,101 @@
+//go:build integration
+
+package integration
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+func TestErasureRequestsAndAnonymize(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	repos := testStore.Repos()
+
+	req := database.ErasureRequest{UserID: "budi", Reason: "pindah kota"}
+	if err := repos.Erasures.Create(ctx, &req); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Erasures.Create(ctx, &database.ErasureRequest{UserID: "budi"}); err == nil {
+		t.Error("second pending request was accepted")
+	}
+	if pending, err := repos.Erasures.List(ctx, database.ErasureStatusPending); err != nil || len(pending) != 1 {
+		t.Errorf("pending = %+v, %v", pending, err)
+	}
+	if err := repos.Erasures.Decide(ctx, req.RequestID, database.ErasureStatusApproved, "admin", "", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Erasures.Decide(ctx, req.RequestID, database.ErasureStatusRejected, "admin", "", time.Now()); !errors.Is(err, database.ErrErasureRequestDecided) {
+		t.Errorf("deciding twice: %v", err)
+	}
+	latest, err := repos.Erasures.GetLatestByUserID(ctx, "budi")
+	if err != nil || latest.Status != database.ErasureStatusApproved || latest.DecidedBy == nil {
+		t.Errorf("latest = %+v, %v", latest, err)
+	}
+
+	img := database.Image{StoragePath: "uploads/images/ktp_budi.jpg"}
+	if err := repos.Images.Create(ctx, &img); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Users.Update(ctx, "budi", map[string]interface{}{"ktp_image_id": img.ImageID}); err != nil {
+		t.Fatal(err)
+	}
+	var imageIDs []int64
+	inTx(t, func(r database.Repositories) (err error) {
+		imageIDs, err = r.Users.Anonymize(ctx, "budi")
+		return err
+	})
+	if len(imageIDs) != 1 || imageIDs[0] != img.ImageID {
+		t.Errorf("image IDs = %v, want [%d]", imageIDs, img.ImageID)
+	}
+
+	u, err := repos.Users.FindByID(ctx, "budi")
+	if err != nil || u.Name != database.AnonymizedUserName || u.NIK != "" || u.Email != "erased-budi@jaga.invalid" || u.KTPImageID != nil {
+		t.Errorf("user after anonymize = %+v, %v", u, err)
+	}
+	v, err := repos.Vehicles.GetByID(ctx, 1)
+	if err != nil || v.PlateNumber != "" || v.VehicleName != "Honda Beat" {
+		t.Errorf("vehicle after anonymize = %+v, %v", v, err)
+	}
+	lr, err := repos.LostReports.GetByID(ctx, 1)
+	if err != nil || lr.Address != "" || lr.Latitude == nil || *lr.Latitude != -6.19 || *lr.Longitude != 106.83 {
+		t.Errorf("lost report after anonymize = %+v, %v", lr, err)
+	}
+	if _, err := repos.Users.Anonymize(ctx, "nobody"); !errors.Is(err, database.ErrUserNotFound) {
+		t.Errorf("anonymize unknown user: %v", err)
+	}
+}
+
+func TestDataExportWritesAuditEntry(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+
+	rec := doJSON(t, "GET", "/api/users/me/export", login(t, "budi@example.com"), nil)
+	expectStatus(t, rec, http.StatusOK)
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, f := range zr.File {
+		found = found || f.Name == "data.json"
+	}
+	if !found {
+		t.Error("export has no data.json")
+	}
+
+	entries, err := testStore.Repos().AuditLog.List(ctx, database.AuditFilter{Action: "user.export", EntityID: "budi"})
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("user.export entries = %+v, %v", entries, err)
+	}
+	if result, err := testStore.Repos().AuditLog.Verify(ctx); err != nil || !result.Valid {
+		t.Errorf("Verify = %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,52 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/ratelimit"
+)
+
+func TestPostgresRateLimitStore(t *testing.T) {
+	ctx := context.Background()
+	store := ratelimit.NewPostgresStore(testDB)
+	t.Cleanup(func() { store.Cleanup(ctx, time.Now().Add(24*time.Hour)) })
+
+	now := time.Now().Truncate(time.Microsecond)
+	limit := ratelimit.Limit{Requests: 1, Per: time.Minute, Burst: 2}
+	for i, want := range []bool{true, true, false} {
+		d, err := store.Take(ctx, "bucket:"+t.Name(), limit, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Allowed != want {
+			t.Fatalf("take %d: allowed = %v, want %v", i+1, d.Allowed, want)
+		}
+	}
+	if d, _ := store.Take(ctx, "bucket:"+t.Name(), limit, now.Add(time.Minute)); !d.Allowed {
+		t.Error("bucket not refilled after one period")
+	}
+
+	key := "login:" + t.Name()
+	policy := ratelimit.LockoutPolicy{Threshold: 2, BaseDelay: time.Minute, MaxDelay: time.Hour, ResetAfter: time.Hour}
+	store.RecordFailure(ctx, key, policy, now)
+	until, err := store.RecordFailure(ctx, key, policy, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !until.Equal(now.Add(time.Minute)) {
+		t.Errorf("locked until %v, want %v", until, now.Add(time.Minute))
+	}
+	if got, _ := store.LockedUntil(ctx, key, now); !got.Equal(until) {
+		t.Errorf("LockedUntil = %v, want %v", got, until)
+	}
+	if err := store.ResetFailures(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.LockedUntil(ctx, key, now); !got.IsZero() {
+		t.Errorf("still locked after reset: %v", got)
+	}
+}
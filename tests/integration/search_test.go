@@ -0,0 +1,78 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+func TestLostReportSearchStorage(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	repos := testStore.Repos()
+
+	// Tabel batas dikosongkan resetDB; GetLimits jatuh ke default migrasi.
+	limits, err := repos.Search.GetLimits(ctx)
+	if err != nil || *limits != database.DefaultAnalysisLimits() {
+		t.Fatalf("default limits = %+v, %v", limits, err)
+	}
+	admin := "a1"
+	updated := database.AnalysisLimits{MaxRadiusKm: 10, MaxWindowHours: 48, MaxCameras: 3, UpdatedBy: &admin}
+	if err := repos.Search.UpdateLimits(ctx, &updated); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := repos.Search.GetLimits(ctx); err != nil || got.MaxCameras != 3 || got.UpdatedBy == nil || *got.UpdatedBy != "a1" {
+		t.Errorf("updated limits = %+v, %v", got, err)
+	}
+
+	if _, err := repos.Search.GetByLostReportID(ctx, 1); !errors.Is(err, database.ErrSearchParamsNotFound) {
+		t.Fatalf("search of lost report 1 before save: err = %v", err)
+	}
+	params := database.SearchParams{RadiusKm: 3, WindowHours: 12, CameraIDs: []int64{1, 2}, Zones: []string{"jakarta"}}
+	if err := repos.Search.Save(ctx, &database.LostReportSearch{LostID: 1, SearchParams: params, UpdatedBy: &admin}); err != nil {
+		t.Fatal(err)
+	}
+	params.CameraIDs = []int64{2}
+	if err := repos.Search.Save(ctx, &database.LostReportSearch{LostID: 1, SearchParams: params, UpdatedBy: &admin}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repos.Search.GetByLostReportID(ctx, 1)
+	if err != nil || !reflect.DeepEqual(got.SearchParams, params) {
+		t.Errorf("saved search = %+v, %v", got, err)
+	}
+
+	// Cakupan run yang masih antre ikut diganti; run yang berjalan tidak.
+	run := database.AnalysisRun{LostID: 1, RadiusKm: 5, WindowHours: 24}
+	if err := repos.AnalysisRuns.Create(ctx, &run); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := repos.AnalysisRuns.UpdateQueuedScope(ctx, run.RunID, params); err != nil || !ok {
+		t.Fatalf("update queued scope = %v, %v", ok, err)
+	}
+	claimed, err := repos.AnalysisRuns.Claim(ctx, "reid-v2", time.Now())
+	if err != nil || claimed.RadiusKm != 3 || !reflect.DeepEqual(claimed.CameraIDs, []int64{2}) || !reflect.DeepEqual(claimed.Zones, []string{"jakarta"}) {
+		t.Fatalf("claimed run = %+v, %v", claimed, err)
+	}
+	if ok, err := repos.AnalysisRuns.UpdateQueuedScope(ctx, run.RunID, database.SearchParams{RadiusKm: 1, WindowHours: 1}); err != nil || ok {
+		t.Errorf("update running scope = %v, %v", ok, err)
+	}
+
+	counts, err := repos.Search.CountDetectionsByCamera(ctx, []int64{1, 2, 3},
+		time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	if total != 3 {
+		t.Errorf("detections on 2024-05-02 = %v, want 3 in total", counts)
+	}
+}
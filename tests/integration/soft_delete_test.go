@@ -0,0 +1,91 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+func inTx(t *testing.T, fn func(repos database.Repositories) error) {
+	t.Helper()
+	ctx := context.Background()
+	tx, err := testStore.BeginTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if err := fn(tx.Repos()); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSoftDeleteRestoreAndPurge(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	repos := testStore.Repos()
+
+	inTx(t, func(r database.Repositories) error { return r.Users.Delete(ctx, "budi") })
+	if _, err := repos.Users.FindByID(ctx, "budi"); !errors.Is(err, database.ErrUserNotFound) {
+		t.Errorf("FindByID after delete: %v", err)
+	}
+	if _, err := repos.Vehicles.GetByID(ctx, 1); !errors.Is(err, database.ErrVehicleNotFound) {
+		t.Errorf("vehicle of deleted user: %v", err)
+	}
+	if counts, _ := repos.LostReports.CountByStatus(ctx); counts[database.StatusLostReportBelumDiproses] != 0 {
+		t.Errorf("counts after delete = %v", counts)
+	}
+
+	inTx(t, func(r database.Repositories) error { return r.Users.Restore(ctx, "budi") })
+	if _, err := repos.LostReports.GetByID(ctx, 1); err != nil {
+		t.Errorf("lost report after restore: %v", err)
+	}
+	if err := repos.Users.Restore(ctx, "budi"); !errors.Is(err, database.ErrUserNotFound) {
+		t.Errorf("restore of live user: %v", err)
+	}
+
+	inTx(t, func(r database.Repositories) error {
+		if err := r.Users.Delete(ctx, "budi"); err != nil {
+			return err
+		}
+		return r.Cameras.Delete(ctx, 1)
+	})
+	var history int
+	if err := testDB.QueryRowContext(ctx, `SELECT COUNT(*) FROM detected WHERE camera_id = 1`).Scan(&history); err != nil || history != 1 {
+		t.Errorf("detections of deleted camera = %d, %v", history, err)
+	}
+
+	if purged, err := repos.Users.PurgeDeleted(ctx, time.Now().Add(-time.Hour)); err != nil || purged.Count != 0 {
+		t.Errorf("purge within retention = %+v, %v", purged, err)
+	}
+	before := time.Now().Add(time.Second)
+	// Urutan sama dengan job purge: anak lebih dulu agar tidak terhapus
+	// lewat cascade.
+	for _, step := range []struct {
+		name  string
+		purge func(context.Context, time.Time) (*database.PurgedRows, error)
+	}{
+		{"lost_report", repos.LostReports.PurgeDeleted},
+		{"vehicle", repos.Vehicles.PurgeDeleted},
+		{"users", repos.Users.PurgeDeleted},
+		{"cameras", repos.Cameras.PurgeDeleted},
+	} {
+		purged, err := step.purge(ctx, before)
+		if err != nil || purged.Count != 1 {
+			t.Errorf("purge %s = %+v, %v", step.name, purged, err)
+		}
+	}
+	var remaining int
+	if err := testDB.QueryRowContext(ctx, `SELECT
+        (SELECT COUNT(*) FROM users WHERE user_id = 'budi') +
+        (SELECT COUNT(*) FROM detected WHERE camera_id = 1)`).Scan(&remaining); err != nil || remaining != 0 {
+		t.Errorf("rows left after purge = %d, %v", remaining, err)
+	}
+}
@@ -0,0 +1,71 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/config"
+	"github.com/jaga-project/jaga-backend/internal/server"
+)
+
+// TestNewServerMigratesEmptyDatabase menjalankan jalur startup produksi
+// terhadap database kosong: semua migrasi harus diterapkan oleh NewServer.
+func TestNewServerMigratesEmptyDatabase(t *testing.T) {
+	name := fmt.Sprintf("jaga_startup_%d", time.Now().UnixNano())
+	if _, err := testDB.Exec("CREATE DATABASE " + name); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { testDB.Exec("DROP DATABASE IF EXISTS " + name + " WITH (FORCE)") })
+	u, err := url.Parse(testDSN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Path = "/" + name
+
+	cfg := config.Default()
+	cfg.Auth.JWTSecret = "integration-test-secret"
+	cfg.Encryption = testEncryption
+	cfg.Database.URI = u.String()
+	cfg.RateLimit.Enabled = false
+	// Dua kali: replika kedua tidak boleh gagal karena migrasi sudah ada.
+	for i := 0; i < 2; i++ {
+		srv, err := server.NewServer(&cfg)
+		if err != nil {
+			t.Fatalf("NewServer #%d: %v", i+1, err)
+		}
+		rec := httptest.NewRecorder()
+		srv.RegisterRoutes().ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("readyz = %d: %s", rec.Code, rec.Body.String())
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := srv.Run(ctx); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	}
+
+	db, err := sql.Open("postgres", u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, table := range []string{"user_tokens", "audit_log", "analysis_runs", "vehicle_transfers", "document_ocr_results"} {
+		var exists bool
+		if err := db.QueryRow(`SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists); err != nil || !exists {
+			t.Errorf("table %s missing after startup (err %v)", table, err)
+		}
+	}
+	var migrations int
+	if err := db.QueryRow(`SELECT count(*) FROM schema_migrations`).Scan(&migrations); err != nil || migrations < 16 {
+		t.Errorf("schema_migrations has %d rows (err %v), want all migrations", migrations, err)
+	}
+}
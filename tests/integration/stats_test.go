@@ -0,0 +1,82 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+func TestDashboardStats(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+
+	for _, s := range []database.Suspect{
+		{DetectedID: 1, LostID: 1, FinalScore: 0.91, CreatedAt: time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)},
+		{DetectedID: 2, LostID: 1, FinalScore: 0.55, CreatedAt: time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)},
+		{DetectedID: 3, LostID: 2, FinalScore: 0.7, CreatedAt: time.Date(2024, 5, 3, 15, 0, 0, 0, time.UTC)},
+	} {
+		if err := database.CreateSuspect(ctx, testDB, &s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := testDB.ExecContext(ctx, `UPDATE lost_report SET status = 'SUDAH_DITEMUKAN', found_at = '2024-05-04T10:30:00Z' WHERE lost_id = 1`); err != nil {
+		t.Fatal(err)
+	}
+
+	f := database.StatsFilter{
+		From:         time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		To:           time.Date(2024, 5, 8, 0, 0, 0, 0, time.UTC),
+		Bucket:       database.StatsBucketDay,
+		TopLocations: 10,
+	}
+	stats, err := testStore.Repos().Stats.Dashboard(ctx, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.LostReportsByStatus) != 2 || stats.LostReportsByStatus[0].Counts[database.StatusLostReportSudahDitemukan] != 1 ||
+		stats.LostReportsByStatus[1].Counts[database.StatusLostReportSedangDiproses] != 1 {
+		t.Errorf("status buckets = %+v", stats.LostReportsByStatus)
+	}
+	if stats.TimeToFound.FoundReports != 1 || stats.TimeToFound.MeanHours == nil || math.Abs(*stats.TimeToFound.MeanHours-48) > 1e-6 {
+		t.Errorf("time to found = %+v", stats.TimeToFound)
+	}
+	if len(stats.DetectionsPerCamera) != 3 || stats.DetectionsPerCamera[0].CameraID != 1 || stats.DetectionsPerCamera[2].Zone != "bandung" {
+		t.Errorf("detections per camera = %+v", stats.DetectionsPerCamera)
+	}
+	if s := stats.Suspects; s.Reports != 2 || s.Suspects != 3 || s.MaxPerReport != 2 || s.ScoreDistribution[9].Count != 1 || s.ScoreDistribution[5].Count != 1 {
+		t.Errorf("suspect stats = %+v", s)
+	}
+	if len(stats.TopTheftLocations) != 1 || stats.TopTheftLocations[0].Latitude != -6.19 || stats.TopTheftLocations[0].Count != 1 {
+		t.Errorf("top locations = %+v", stats.TopTheftLocations)
+	}
+	if len(stats.NewUsers) != 1 || stats.NewUsers[0].Count != 3 {
+		t.Errorf("new users = %+v", stats.NewUsers)
+	}
+
+	// Zona bandung hanya mencakup laporan siti lewat suspect dari kamera 3.
+	f.Zone, f.Bucket = "bandung", database.StatsBucketWeek
+	stats, err = testStore.Repos().Stats.Dashboard(ctx, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.LostReportsByStatus) != 1 || stats.LostReportsByStatus[0].Counts[database.StatusLostReportSedangDiproses] != 1 ||
+		!stats.LostReportsByStatus[0].Start.Equal(time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("bandung weekly buckets = %+v", stats.LostReportsByStatus)
+	}
+	if stats.TimeToFound.FoundReports != 0 || stats.Suspects.Suspects != 1 || len(stats.DetectionsPerCamera) != 1 {
+		t.Errorf("bandung stats = %+v", stats)
+	}
+
+	locations, err := testStore.Repos().LostReports.ListLocations(ctx, f.From, f.To)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locations) != 1 || locations[0].LostID != 1 || locations[0].Latitude != -6.1862 {
+		t.Errorf("lost report locations = %+v", locations)
+	}
+}
@@ -0,0 +1,72 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+func TestSuspectReviewLabels(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	repos := testStore.Repos()
+
+	s := database.Suspect{DetectedID: 1, LostID: 1, PersonScore: 0.7, MotorScore: 0.9, FinalScore: 0.84, CreatedAt: time.Now()}
+	if err := repos.Suspects.Create(ctx, &s); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repos.Suspects.GetByID(ctx, s.SuspectID)
+	if err != nil || got.ReviewStatus != database.SuspectReviewPending || got.ReviewedAt != nil {
+		t.Fatalf("new suspect = %+v, %v", got, err)
+	}
+
+	at := time.Date(2024, 5, 3, 9, 0, 0, 0, time.UTC)
+	if err := repos.Suspects.Review(ctx, s.SuspectID, database.SuspectReviewConfirmed, "admin", "plat cocok", at); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Suspects.SetOwnerFeedback(ctx, s.SuspectID, database.OwnerFeedbackMine, at); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Suspects.Review(ctx, 9999, database.SuspectReviewRejected, "admin", "", at); !errors.Is(err, database.ErrSuspectNotFound) {
+		t.Errorf("review missing suspect: err = %v", err)
+	}
+	got, _ = repos.Suspects.GetByID(ctx, s.SuspectID)
+	if got.ReviewStatus != database.SuspectReviewConfirmed || *got.ReviewedBy != "admin" || !got.ReviewedAt.Equal(at) ||
+		got.OwnerFeedback == nil || *got.OwnerFeedback != database.OwnerFeedbackMine {
+		t.Errorf("reviewed suspect = %+v", got)
+	}
+	results, err := repos.Suspects.ListResultsByLostReportID(ctx, 1, database.SuspectResultFilter{})
+	if err != nil || len(results) != 1 || results[0].ReviewStatus != database.SuspectReviewConfirmed {
+		t.Errorf("results = %+v, %v", results, err)
+	}
+
+	reviewer := "admin"
+	label := database.SuspectLabel{SuspectID: s.SuspectID, Source: database.SuspectLabelSourceAdmin, Label: database.SuspectReviewConfirmed, LabeledBy: &reviewer, Comment: "plat cocok", CreatedAt: at}
+	if err := repos.SuspectLabels.Create(ctx, &label); err != nil {
+		t.Fatal(err)
+	}
+	if label.LostID != 1 || label.DetectedID != 1 || label.FinalScore != 0.84 {
+		t.Errorf("label snapshot = %+v", label)
+	}
+	if err := repos.SuspectLabels.Create(ctx, &database.SuspectLabel{SuspectID: 9999, Source: database.SuspectLabelSourceOwner, Label: database.OwnerFeedbackMine}); !errors.Is(err, database.ErrSuspectNotFound) {
+		t.Errorf("label for missing suspect: err = %v", err)
+	}
+
+	// Label tetap ada setelah suspect-nya dihapus.
+	if err := repos.Suspects.Delete(ctx, s.SuspectID); err != nil {
+		t.Fatal(err)
+	}
+	labels, err := repos.SuspectLabels.List(ctx, database.SuspectLabelFilter{Source: database.SuspectLabelSourceAdmin})
+	if err != nil || len(labels) != 1 || labels[0].SuspectID != s.SuspectID || labels[0].Comment != "plat cocok" {
+		t.Errorf("labels = %+v, %v", labels, err)
+	}
+	from := at.Add(time.Second)
+	if labels, _ := repos.SuspectLabels.List(ctx, database.SuspectLabelFilter{From: &from}); len(labels) != 0 {
+		t.Errorf("labels after %s = %+v", from, labels)
+	}
+}
@@ -0,0 +1,55 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jaga-project/jaga-backend/internal/tracing"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestSQLSpans(t *testing.T) {
+	resetDB(t)
+	budi := login(t, "budi@example.com")
+
+	exp := tracetest.NewInMemoryExporter()
+	tracing.SetProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)))
+	t.Cleanup(func() { tracing.SetProvider(noop.NewTracerProvider()) })
+
+	expectStatus(t, doMultipart(t, "PUT", "/api/vehicles/1", budi, map[string]string{"color": "Biru"}), http.StatusOK)
+
+	var server, tx, update bool
+	var traceID, txSpanID string
+	var updateParents []string
+	for _, s := range exp.GetSpans() {
+		switch s.Name {
+		case "PUT /api/vehicles/{id:[0-9]+}":
+			server = true
+			traceID = s.SpanContext.TraceID().String()
+		case "db transaction":
+			tx = true
+			txSpanID = s.SpanContext.SpanID().String()
+		case "db UPDATE":
+			update = true
+			updateParents = append(updateParents, s.Parent.SpanID().String())
+		}
+	}
+	if !server || !tx || !update {
+		t.Fatalf("missing spans: server=%v transaction=%v update=%v", server, tx, update)
+	}
+	// UPDATE kendaraan berjalan di dalam transaksi handler.
+	for _, parent := range updateParents {
+		if parent != txSpanID {
+			t.Errorf("db UPDATE parent = %s, want transaction span %s", parent, txSpanID)
+		}
+	}
+	for _, s := range exp.GetSpans() {
+		if s.SpanContext.TraceID().String() != traceID {
+			t.Errorf("span %q belongs to another trace", s.Name)
+		}
+	}
+}
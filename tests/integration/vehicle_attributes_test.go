@@ -0,0 +1,87 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+func TestDetectionAttributeSearch(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	repos := testStore.Repos()
+
+	str := func(s string) *string { return &s }
+	conf := 0.85
+	for id, a := range map[int]database.DetectedAttributes{
+		1: {Brand: str("Honda"), Model: str("Beat Street"), Color: str("red"), BodyType: str("scooter"), Confidence: &conf},
+		2: {Brand: str("Honda"), Model: str("Vario"), Color: str("red")},
+		3: {Brand: str("HONDA"), Model: str("beat"), Color: str("red")},
+	} {
+		if err := repos.Detected.SetAttributes(ctx, id, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repos.Detected.SetAttributes(ctx, 999, database.DetectedAttributes{}); !errors.Is(err, database.ErrDetectedNotFound) {
+		t.Errorf("set attributes of missing detection: err = %v", err)
+	}
+	// Update biasa tidak menghapus atribut.
+	d, err := repos.Detected.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Detected.Update(ctx, 1, d); err != nil {
+		t.Fatal(err)
+	}
+	if d, err := repos.Detected.GetByID(ctx, 1); err != nil || d.Attributes.Brand == nil || *d.Attributes.Confidence != conf {
+		t.Fatalf("detected 1 after update = %+v, %v", d, err)
+	}
+
+	f := database.DetectionSearchFilter{
+		Brand: "honda", Model: "BEAT", Color: "red",
+		From: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC),
+	}
+	list, err := repos.Detected.Search(ctx, f)
+	if err != nil || len(list) != 2 || list[0].DetectedID != 3 || list[1].DetectedID != 1 {
+		t.Fatalf("search = %+v, %v", list, err)
+	}
+	f.CameraIDs = []int64{1, 2}
+	f.MinConfidence = 0.5
+	if n, err := repos.Detected.CountSearch(ctx, f); err != nil || n != 1 {
+		t.Errorf("count with cameras and confidence = %d, %v", n, err)
+	}
+	f = database.DetectionSearchFilter{Color: "red", From: f.From, To: f.To, Limit: 1, Offset: 1}
+	if list, err := repos.Detected.Search(ctx, f); err != nil || len(list) != 1 || list[0].DetectedID != 2 {
+		t.Errorf("paginated search = %+v, %v", list, err)
+	}
+
+	// %, _ dan \ di model dicari apa adanya, bukan sebagai wildcard.
+	if err := repos.Detected.SetAttributes(ctx, 2, database.DetectedAttributes{Brand: str("Honda"), Model: str(`CB150_R\x`), Color: str("red")}); err != nil {
+		t.Fatal(err)
+	}
+	for model, want := range map[string]int{"_": 1, "0_R": 1, "0R": 0, "%": 0, `\`: 1, `R\x`: 1} {
+		f := database.DetectionSearchFilter{Model: model, From: f.From, To: f.To}
+		if n, err := repos.Detected.CountSearch(ctx, f); err != nil || n != want {
+			t.Errorf("count with model %q = %d, %v; want %d", model, n, err, want)
+		}
+	}
+
+	year := 2021
+	v := database.Vehicle{VehicleName: "Honda Beat", UserID: "budi", PlateNumber: "B 9 XYZ",
+		VehicleAttributes: database.VehicleAttributes{Brand: str("Honda"), Year: &year, NormalizedColor: str("black")}}
+	if err := repos.Vehicles.Create(ctx, &v); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Vehicles.Update(ctx, v.VehicleID, map[string]interface{}{"year": nil, "body_type": "scooter"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repos.Vehicles.GetByID(ctx, v.VehicleID)
+	if err != nil || got.Year != nil || got.BodyType == nil || *got.BodyType != "scooter" || *got.NormalizedColor != "black" {
+		t.Errorf("vehicle attributes = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/lifecycle"
+)
+
+func TestGroupShutdownWaitsForWorkers(t *testing.T) {
+	g := lifecycle.NewGroup()
+	var stopped atomic.Int32
+
+	for i := 0; i < 3; i++ {
+		g.Go("worker", func(ctx context.Context) {
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond)
+			stopped.Add(1)
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := g.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if got := stopped.Load(); got != 3 {
+		t.Errorf("%d workers stopped before Shutdown returned, want 3", got)
+	}
+
+	if g.Go("late", func(ctx context.Context) {}) {
+		t.Errorf("Go after Shutdown should not start a task")
+	}
+}
+
+func TestGroupShutdownTimeout(t *testing.T) {
+	g := lifecycle.NewGroup()
+	release := make(chan struct{})
+	defer close(release)
+	g.Go("stuck", func(ctx context.Context) { <-release })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := g.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown error = %v, want deadline exceeded", err)
+	}
+}
@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jaga-project/jaga-backend/internal/logging"
+)
+
+// captureLogs mengarahkan logger default ke buffer selama test berjalan.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "debug", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func accessLogEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line is not JSON: %q", line)
+		}
+		if entry["msg"] == "http request" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	f := newFixture(t)
+	token := f.createUser("u1", "u1@example.com", false)
+
+	req := httptest.NewRequest("DELETE", "/api/vehicles/999", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Request-ID", "abc-123")
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+
+	expectStatus(t, rec, http.StatusNotFound)
+	if got := rec.Header().Get("X-Request-ID"); got != "abc-123" {
+		t.Errorf("X-Request-ID = %q, want abc-123", got)
+	}
+	var body struct {
+		Error struct {
+			Message   string `json:"message"`
+			RequestID string `json:"request_id"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error.RequestID != "abc-123" {
+		t.Errorf("error body request_id = %q, want abc-123", body.Error.RequestID)
+	}
+
+	// Tanpa header (atau header tidak valid) server membuat ID baru.
+	req = httptest.NewRequest("GET", "/ping", nil)
+	req.Header.Set("X-Request-ID", "bad id\n")
+	rec = httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get("X-Request-ID"); got == "" || got == "bad id\n" {
+		t.Errorf("expected generated request ID, got %q", got)
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	buf := captureLogs(t)
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	f.createUser("u1", "u1@example.com", false)
+	vid := f.createVehicle("u1")
+	f.store.AddAPIKey("u1", "service-key")
+
+	expectStatus(t, f.do("GET", "/api/vehicles/"+strconv.FormatInt(vid, 10), admin, nil), http.StatusOK)
+
+	req := httptest.NewRequest("GET", "/api/vehicles/my", nil)
+	req.Header.Set("X-API-Key", "service-key")
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	expectStatus(t, rec, http.StatusOK)
+
+	entries := accessLogEntries(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d access log entries, want 2:\n%s", len(entries), buf.String())
+	}
+	want := []map[string]interface{}{
+		{"method": "GET", "route": "/api/vehicles/{id:[0-9]+}", "status": float64(200), "user_id": "a1", "auth_type": "jwt"},
+		{"method": "GET", "route": "/api/vehicles/my", "status": float64(200), "user_id": "u1", "auth_type": "api_key"},
+	}
+	for i, w := range want {
+		for k, v := range w {
+			if entries[i][k] != v {
+				t.Errorf("entry %d: %s = %v, want %v", i, k, entries[i][k], v)
+			}
+		}
+		if entries[i]["request_id"] == "" || entries[i]["request_id"] == nil {
+			t.Errorf("entry %d has no request_id", i)
+		}
+		if _, ok := entries[i]["latency_ms"]; !ok {
+			t.Errorf("entry %d has no latency_ms", i)
+		}
+	}
+}
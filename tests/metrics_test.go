@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+)
+
+// scrapeMetrics mengembalikan nilai sampel /metrics, dengan kunci nama
+// metrik beserta label persis seperti di output teks.
+func scrapeMetrics(t *testing.T, f *fixture) map[string]float64 {
+	t.Helper()
+	rec := f.do("GET", "/metrics", "", nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	samples := make(map[string]float64)
+	sc := bufio.NewScanner(rec.Body)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndex(line, " ")
+		v, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("bad sample line %q", line)
+		}
+		samples[line[:i]] = v
+	}
+	return samples
+}
+
+func pngBytes(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	admin := f.createUser("a1", "a1@example.com", true)
+	user := f.createUser("u1", "u1@example.com", false)
+	vid := f.createVehicle("u1")
+	f.createLostReport("u1", vid)
+	f.createLostReport("u1", vid)
+	for _, active := range []bool{true, true, false} {
+		if err := f.store.Repos().Cameras.Create(ctx, &database.Camera{Name: "cam", IsActive: active}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expectStatus(t, f.do("GET", "/api/vehicles/my", user, nil), http.StatusOK)
+	expectStatus(t, f.do("GET", "/api/vehicles/my", user, nil), http.StatusOK)
+
+	req := httptest.NewRequest("GET", "/api/vehicles/my", nil)
+	req.Header.Set("X-API-Key", "salah")
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	expectStatus(t, rec, http.StatusForbidden)
+
+	// STNK valid tersimpan, KK berisi teks ditolak.
+	img := pngBytes(t)
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("vehicle_name", "Vario")
+	mw.WriteField("plate_number", "D 1 ABC")
+	fw, _ := mw.CreateFormFile("stnk_image", "stnk.png")
+	fw.Write(img)
+	fw, _ = mw.CreateFormFile("kk_image", "kk.txt")
+	fw.Write([]byte("bukan gambar"))
+	mw.Close()
+	req = httptest.NewRequest("POST", "/api/vehicles", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+user)
+	rec = httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	suspects := []database.Suspect{{DetectedID: 1, LostID: 1}, {DetectedID: 2, LostID: 1}, {DetectedID: 3, LostID: 1}}
+	expectStatus(t, f.do("POST", "/api/suspects/batch", admin, suspects), http.StatusCreated)
+
+	m := scrapeMetrics(t, f)
+	want := map[string]float64{
+		`jaga_http_requests_total{method="GET",route="/api/vehicles/my",status="200"}`:    2,
+		`jaga_http_request_duration_seconds_count{method="GET",route="/api/vehicles/my"}`: 3,
+		`jaga_api_key_auth_failures_total`:                                                1,
+		`jaga_upload_bytes_total{category="stnk"}`:                                        float64(len(img)),
+		`jaga_upload_failures_total{category="kk"}`:                                       1,
+		`jaga_upload_failures_total{category="stnk"}`:                                     0,
+		`jaga_suspects_created_total`:                                                     3,
+		`jaga_suspects_per_run_count`:                                                     1,
+		`jaga_lost_reports{status="` + database.StatusLostReportBelumDiproses + `"}`:      2,
+		`jaga_active_cameras`:                                                             2,
+	}
+	for k, v := range want {
+		got, ok := m[k]
+		if !ok {
+			t.Errorf("metric %s missing", k)
+			continue
+		}
+		if got != v {
+			t.Errorf("%s = %v, want %v", k, got, v)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/auth"
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/server"
+)
+
+func TestTOTPVectors(t *testing.T) {
+	// RFC 6238 lampiran B (SHA1), dipotong ke 6 digit.
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	for unix, want := range map[int64]string{59: "287082", 1111111109: "081804", 2000000000: "279037"} {
+		got, err := auth.TOTPCode(secret, auth.TOTPStep(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("code at %d = %s, want %s", unix, got, want)
+		}
+	}
+	if _, ok := auth.VerifyTOTP(secret, "287082", time.Unix(59+30, 0)); !ok {
+		t.Error("code from previous step rejected")
+	}
+	if _, ok := auth.VerifyTOTP(secret, "287082", time.Unix(59+90, 0)); ok {
+		t.Error("code three steps old accepted")
+	}
+}
+
+func mfaFixture(t *testing.T) *fixture {
+	cfg := testConfig()
+	cfg.RateLimit.Login.Burst = 50
+	return newFixtureWithConfig(t, cfg)
+}
+
+func (f *fixture) login(email string) (*http.Response, map[string]interface{}) {
+	f.t.Helper()
+	rec := f.do("POST", "/auth/login", "", map[string]string{"email": email, "password": testPassword})
+	var body map[string]interface{}
+	json.NewDecoder(rec.Body).Decode(&body)
+	return rec.Result(), body
+}
+
+func totpNow(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	code, err := auth.TOTPCode(secret, auth.TOTPStep(time.Now())+offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestAdminMFAEnrollmentAndLogin(t *testing.T) {
+	f := mfaFixture(t)
+	f.createUser("a1", "a1@example.com", true)
+
+	resp, body := f.login("a1@example.com")
+	if resp.StatusCode != http.StatusAccepted || body["mfa_enrollment_required"] != true {
+		t.Fatalf("login = %d %v, want enrollment challenge", resp.StatusCode, body)
+	}
+	challenge := body["challenge_token"].(string)
+
+	// Token challenge tidak berlaku sebagai sesi.
+	expectStatus(t, f.do("GET", "/api/admins/", challenge, nil), http.StatusUnauthorized)
+
+	rec := f.do("POST", "/auth/2fa/enroll", challenge, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var enroll struct {
+		Secret     string `json:"secret"`
+		OTPAuthURL string `json:"otpauth_url"`
+	}
+	json.NewDecoder(rec.Body).Decode(&enroll)
+	if enroll.Secret == "" || enroll.OTPAuthURL == "" {
+		t.Fatalf("enroll response missing fields: %+v", enroll)
+	}
+
+	expectStatus(t, f.do("POST", "/auth/2fa/confirm", challenge, map[string]string{"code": "000000"}), http.StatusBadRequest)
+	rec = f.do("POST", "/auth/2fa/confirm", challenge, map[string]string{"code": totpNow(t, enroll.Secret, 0)})
+	expectStatus(t, rec, http.StatusOK)
+	var confirm struct {
+		RecoveryCodes []string              `json:"recovery_codes"`
+		Login         *server.LoginResponse `json:"login"`
+	}
+	json.NewDecoder(rec.Body).Decode(&confirm)
+	if len(confirm.RecoveryCodes) != 10 || confirm.Login == nil || !confirm.Login.IsAdmin {
+		t.Fatalf("confirm response = %+v", confirm)
+	}
+	expectStatus(t, f.do("GET", "/api/admins/", confirm.Login.Token, nil), http.StatusOK)
+
+	resp, body = f.login("a1@example.com")
+	if resp.StatusCode != http.StatusAccepted || body["mfa_required"] != true {
+		t.Fatalf("login after enrollment = %d %v", resp.StatusCode, body)
+	}
+	challenge = body["challenge_token"].(string)
+	verify := func(fields map[string]string) int {
+		fields["challenge_token"] = challenge
+		return f.do("POST", "/auth/login/2fa", "", fields).Code
+	}
+
+	// Kode yang sudah dipakai saat konfirmasi tidak bisa diputar ulang.
+	if got := verify(map[string]string{"code": totpNow(t, enroll.Secret, 0)}); got != http.StatusUnauthorized {
+		t.Errorf("replayed code: status %d", got)
+	}
+	if got := verify(map[string]string{"code": totpNow(t, enroll.Secret, 1)}); got != http.StatusOK {
+		t.Errorf("fresh code: status %d", got)
+	}
+	if got := verify(map[string]string{"recovery_code": confirm.RecoveryCodes[0]}); got != http.StatusOK {
+		t.Errorf("recovery code: status %d", got)
+	}
+	if got := verify(map[string]string{"recovery_code": confirm.RecoveryCodes[0]}); got != http.StatusUnauthorized {
+		t.Errorf("reused recovery code: status %d", got)
+	}
+
+	// Challenge login tidak bisa dipakai untuk mendaftar ulang.
+	expectStatus(t, f.do("POST", "/auth/2fa/enroll", challenge, nil), http.StatusUnauthorized)
+
+	rec = f.do("GET", "/api/admins/me/2fa", confirm.Login.Token, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var status map[string]interface{}
+	json.NewDecoder(rec.Body).Decode(&status)
+	if status["enabled"] != true || status["required"] != true || status["recovery_codes_remaining"] != float64(9) {
+		t.Errorf("status = %v", status)
+	}
+
+	// Kebijakan mewajibkan 2FA untuk level ini, jadi tidak bisa dimatikan.
+	expectStatus(t, f.do("DELETE", "/api/admins/me/2fa", confirm.Login.Token, map[string]string{"recovery_code": confirm.RecoveryCodes[1]}), http.StatusForbidden)
+}
+
+func TestAdminMFAPolicy(t *testing.T) {
+	f := mfaFixture(t)
+	ctx := context.Background()
+	a1 := f.createUser("a1", "a1@example.com", true)
+	senior := f.createUser("a2", "a2@example.com", true)
+	if err := f.store.Repos().Admins.Update(ctx, "a2", &database.Admin{AdminLevel: 2, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	user := f.createUser("u1", "u1@example.com", false)
+
+	expectStatus(t, f.do("PUT", "/api/admins/security/mfa-policy", user, map[string]int{"require_above_level": 1}), http.StatusForbidden)
+	// Hanya admin level tertinggi yang boleh mengubah kebijakan.
+	expectStatus(t, f.do("PUT", "/api/admins/security/mfa-policy", a1, map[string]int{"require_above_level": 5}), http.StatusForbidden)
+	expectStatus(t, f.do("PUT", "/api/admins/security/mfa-policy", senior, map[string]int{"require_above_level": -1}), http.StatusBadRequest)
+	rec := f.do("PUT", "/api/admins/security/mfa-policy", senior, map[string]int{"require_above_level": 1})
+	expectStatus(t, rec, http.StatusOK)
+	var policy database.MFAPolicy
+	json.NewDecoder(rec.Body).Decode(&policy)
+	if policy.UpdatedBy == nil || *policy.UpdatedBy != "a2" {
+		t.Errorf("updated_by = %v", policy.UpdatedBy)
+	}
+
+	// Level 1 tidak lagi wajib; level 2 tetap wajib.
+	if resp, _ := f.login("a1@example.com"); resp.StatusCode != http.StatusOK {
+		t.Errorf("level 1 admin login = %d, want 200", resp.StatusCode)
+	}
+	if resp, _ := f.login("a2@example.com"); resp.StatusCode != http.StatusAccepted {
+		t.Errorf("level 2 admin login = %d, want 202", resp.StatusCode)
+	}
+	if resp, _ := f.login("u1@example.com"); resp.StatusCode != http.StatusOK {
+		t.Errorf("user login = %d, want 200", resp.StatusCode)
+	}
+
+	// Non-admin tidak bisa mendaftar 2FA.
+	expectStatus(t, f.do("POST", "/auth/2fa/enroll", user, nil), http.StatusUnauthorized)
+
+	// Admin level 1 mendaftar sukarela lalu mematikannya lagi.
+	rec = f.do("POST", "/auth/2fa/enroll", a1, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var enroll struct{ Secret string }
+	json.NewDecoder(rec.Body).Decode(&enroll)
+	rec = f.do("POST", "/auth/2fa/confirm", a1, map[string]string{"code": totpNow(t, enroll.Secret, 0)})
+	expectStatus(t, rec, http.StatusOK)
+	var confirm struct {
+		RecoveryCodes []string              `json:"recovery_codes"`
+		Login         *server.LoginResponse `json:"login"`
+	}
+	json.NewDecoder(rec.Body).Decode(&confirm)
+	if confirm.Login != nil {
+		t.Error("voluntary enrollment must not issue a new session")
+	}
+	if resp, body := f.login("a1@example.com"); resp.StatusCode != http.StatusAccepted || body["mfa_required"] != true {
+		t.Errorf("login with 2FA enabled = %d %v", resp.StatusCode, body)
+	}
+	expectStatus(t, f.do("DELETE", "/api/admins/me/2fa", a1, map[string]string{"code": "123456"}), http.StatusUnauthorized)
+	expectStatus(t, f.do("DELETE", "/api/admins/me/2fa", a1, map[string]string{"code": totpNow(t, enroll.Secret, 1)}), http.StatusNoContent)
+	if resp, _ := f.login("a1@example.com"); resp.StatusCode != http.StatusOK {
+		t.Errorf("login after disabling 2FA = %d", resp.StatusCode)
+	}
+}
+
+func TestAdminMFALockout(t *testing.T) {
+	f := mfaFixture(t)
+	f.createUser("a1", "a1@example.com", true)
+	secret, _ := auth.NewTOTPSecret()
+	repos := f.store.Repos()
+	ctx := context.Background()
+	repos.AdminMFA.SaveEnrollment(ctx, "a1", secret)
+	repos.AdminMFA.Enable(ctx, "a1", 0, time.Now())
+
+	var challenge string
+	for i := 0; i < 5; i++ {
+		_, body := f.login("a1@example.com")
+		challenge = body["challenge_token"].(string)
+		rec := f.do("POST", "/auth/login/2fa", "", map[string]string{"challenge_token": challenge, "code": "000000"})
+		if rec.Code == http.StatusTooManyRequests {
+			return
+		}
+		expectStatus(t, rec, http.StatusUnauthorized)
+	}
+	// Password yang benar tidak mereset hitungan sebelum 2FA selesai.
+	rec := f.do("POST", "/auth/login/2fa", "", map[string]string{"challenge_token": challenge, "code": totpNow(t, secret, 0)})
+	expectRetryAfter(t, rec)
+}
+
+func TestResetAnotherAdminMFA(t *testing.T) {
+	f := mfaFixture(t)
+	ctx := context.Background()
+	f.createUser("a1", "a1@example.com", true)
+	a2 := f.createUser("a2", "a2@example.com", true)
+	f.store.Repos().AdminMFA.SaveEnrollment(ctx, "a1", "JBSWY3DPEHPK3PXP")
+	f.store.Repos().AdminMFA.Enable(ctx, "a1", 0, time.Now())
+
+	expectStatus(t, f.do("DELETE", "/api/admins/a2/2fa", a2, nil), http.StatusBadRequest)
+	expectStatus(t, f.do("DELETE", "/api/admins/a1/2fa", a2, nil), http.StatusNoContent)
+	expectStatus(t, f.do("DELETE", "/api/admins/a1/2fa", a2, nil), http.StatusNotFound)
+	expectStatus(t, f.do("DELETE", "/api/admins/nobody/2fa", a2, nil), http.StatusNotFound)
+
+	// Admin level 1 tidak boleh mereset 2FA admin level 2.
+	f.createUser("a3", "a3@example.com", true)
+	if err := f.store.Repos().Admins.Update(ctx, "a3", &database.Admin{AdminLevel: 2, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	f.store.Repos().AdminMFA.SaveEnrollment(ctx, "a3", "JBSWY3DPEHPK3PXP")
+	f.store.Repos().AdminMFA.Enable(ctx, "a3", 0, time.Now())
+	expectStatus(t, f.do("DELETE", "/api/admins/a3/2fa", a2, nil), http.StatusForbidden)
+	if _, err := f.store.Repos().AdminMFA.Get(ctx, "a3"); err != nil {
+		t.Errorf("a3 2FA after forbidden reset: %v", err)
+	}
+	if _, body := f.login("a1@example.com"); body["mfa_enrollment_required"] != true {
+		t.Errorf("after reset, login = %v, want enrollment challenge", body)
+	}
+}
@@ -0,0 +1,278 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/server"
+)
+
+// storeImage menulis file gambar ke direktori upload dan mencatatnya di
+// tabel images.
+func (f *fixture) storeImage(name, content string) (int64, string) {
+	f.t.Helper()
+	path := filepath.Join("uploads", "images", name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		f.t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		f.t.Fatal(err)
+	}
+	img := database.Image{StoragePath: filepath.ToSlash(path), FilenameOriginal: name, MimeType: "image/jpeg", SizeBytes: int64(len(content))}
+	if err := f.store.Repos().Images.Create(context.Background(), &img); err != nil {
+		f.t.Fatal(err)
+	}
+	return img.ImageID, path
+}
+
+func TestUserDataExport(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	user := f.createUser("u1", "u1@example.com", false)
+	ctx := context.Background()
+
+	ktpID, _ := f.storeImage("ktp_export.jpg", "ktp-u1")
+	if err := f.store.Repos().Users.Update(ctx, "u1", map[string]interface{}{"ktp_image_id": ktpID}); err != nil {
+		t.Fatal(err)
+	}
+	vehicleID := f.createVehicle("u1")
+	expectStatus(t, f.postLostReport(user, vehicleID), http.StatusCreated)
+	f.createLostReport("a1", f.createVehicle("a1"))
+
+	rec := f.do("GET", "/api/users/me/export", user, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	for _, zf := range zr.File {
+		rc, err := zf.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[zf.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+
+	var export server.UserDataExport
+	if err := json.Unmarshal(files["data.json"], &export); err != nil {
+		t.Fatalf("data.json: %v", err)
+	}
+	if export.Profile.Email != "u1@example.com" || export.Profile.Password != "" {
+		t.Errorf("profile = %+v", export.Profile)
+	}
+	if len(export.Vehicles) != 1 || len(export.LostReports) != 1 || len(export.Results) != 1 {
+		t.Errorf("got %d vehicles, %d lost reports, %d results; want 1 each", len(export.Vehicles), len(export.LostReports), len(export.Results))
+	}
+	kinds := map[string]server.ExportImage{}
+	for _, img := range export.Images {
+		kinds[img.Kind] = img
+		if img.File == "" || len(files[img.File]) == 0 {
+			t.Errorf("image %+v missing from ZIP", img)
+		}
+	}
+	if ktp := kinds["ktp"]; string(files[ktp.File]) != "ktp-u1" {
+		t.Errorf("KTP file content = %q", files[ktp.File])
+	}
+	if _, ok := kinds["motor_evidence"]; !ok || len(export.Images) != 2 {
+		t.Errorf("images = %+v, want ktp and motor_evidence", export.Images)
+	}
+
+	if n := len(f.auditEntries(admin, "?action=user.export&entity_id=u1")); n != 1 {
+		t.Errorf("got %d user.export entries, want 1", n)
+	}
+}
+
+func TestErasureRequestApproval(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	user := f.createUser("u1", "u1@example.com", false)
+	ctx := context.Background()
+
+	ktpID, ktpPath := f.storeImage("ktp_erase.jpg", "ktp")
+	if err := f.store.Repos().Users.Update(ctx, "u1", map[string]interface{}{"ktp_image_id": ktpID}); err != nil {
+		t.Fatal(err)
+	}
+	vehicleID := f.createVehicle("u1")
+	lostID := f.createLostReport("u1", vehicleID)
+	if err := f.store.AddAPIKey("u1", "u1-key"); err != nil {
+		t.Fatal(err)
+	}
+
+	expectStatus(t, f.do("GET", "/api/users/me/erasure-request", user, nil), http.StatusNotFound)
+	rec := f.do("POST", "/api/users/me/erasure-request", user, map[string]string{"reason": "tidak memakai JAGA lagi"})
+	expectStatus(t, rec, http.StatusCreated)
+	var req database.ErasureRequest
+	if err := json.NewDecoder(rec.Body).Decode(&req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Status != database.ErasureStatusPending {
+		t.Errorf("status = %q", req.Status)
+	}
+	expectStatus(t, f.do("POST", "/api/users/me/erasure-request", user, nil), http.StatusConflict)
+	expectStatus(t, f.do("GET", "/api/admins/erasure-requests", user, nil), http.StatusForbidden)
+
+	rec = f.do("GET", "/api/admins/erasure-requests?status=pending", admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var pending []database.ErasureRequest
+	if err := json.NewDecoder(rec.Body).Decode(&pending); err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].RequestID != req.RequestID {
+		t.Fatalf("pending = %+v", pending)
+	}
+
+	decidePath := "/api/admins/erasure-requests/" + strconv.FormatInt(req.RequestID, 10)
+	expectStatus(t, f.do("POST", decidePath+"/approve", user, nil), http.StatusForbidden)
+	expectStatus(t, f.do("POST", "/api/admins/erasure-requests/999/approve", admin, nil), http.StatusNotFound)
+	expectStatus(t, f.do("POST", decidePath+"/approve", admin, nil), http.StatusOK)
+	expectStatus(t, f.do("POST", decidePath+"/reject", admin, nil), http.StatusConflict)
+
+	u, err := f.store.Repos().Users.FindByID(ctx, "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != database.AnonymizedUserName || u.NIK != "" || u.Phone != "" || u.KTPImageID != nil || strings.Contains(u.Email, "u1@example.com") {
+		t.Errorf("user not anonymized: %+v", u)
+	}
+	if _, err := os.Stat(ktpPath); !os.IsNotExist(err) {
+		t.Errorf("KTP file still exists: %v", err)
+	}
+	if v, err := f.store.Repos().Vehicles.GetByID(ctx, vehicleID); err != nil || v.PlateNumber != "" {
+		t.Errorf("vehicle after erasure = %+v, %v", v, err)
+	}
+	lr, err := f.store.Repos().LostReports.GetByID(ctx, lostID)
+	if err != nil || lr.Address != "" {
+		t.Errorf("lost report after erasure = %+v, %v", lr, err)
+	}
+	if counts, _ := f.store.Repos().LostReports.CountByStatus(ctx); counts[database.StatusLostReportBelumDiproses] != 1 {
+		t.Errorf("statistics changed after erasure: %v", counts)
+	}
+	expectStatus(t, f.do("POST", "/auth/login", "", map[string]string{"email": "u1@example.com", "password": testPassword}), http.StatusUnauthorized)
+	if _, _, err := f.store.Repos().Users.FindByAPIKey(ctx, "u1-key"); err == nil {
+		t.Error("API key still valid after erasure")
+	}
+	expectStatus(t, f.do("POST", "/api/users/me/erasure-request", user, nil), http.StatusConflict)
+
+	msgs := f.mail.Messages()
+	if last := msgs[len(msgs)-1]; last.To != "u1@example.com" || !strings.Contains(last.Subject, "dihapus") {
+		t.Errorf("last email = %+v", last)
+	}
+	entries := f.auditEntries(admin, "?action=user.anonymize")
+	if len(entries) != 1 || strings.Contains(string(entries[0].After), "example.com") {
+		t.Errorf("user.anonymize entries = %+v", entries)
+	}
+	if n := len(f.auditEntries(admin, "?action=erasure_request.approve")); n != 1 {
+		t.Errorf("got %d erasure_request.approve entries, want 1", n)
+	}
+}
+
+func TestErasureRequestRejection(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	user := f.createUser("u1", "u1@example.com", false)
+
+	// Admin tidak boleh memutuskan permintaannya sendiri.
+	rec := f.do("POST", "/api/users/me/erasure-request", admin, nil)
+	expectStatus(t, rec, http.StatusCreated)
+	var own database.ErasureRequest
+	json.NewDecoder(rec.Body).Decode(&own)
+	expectStatus(t, f.do("POST", "/api/admins/erasure-requests/"+strconv.FormatInt(own.RequestID, 10)+"/approve", admin, nil), http.StatusForbidden)
+
+	rec = f.do("POST", "/api/users/me/erasure-request", user, nil)
+	expectStatus(t, rec, http.StatusCreated)
+	var req database.ErasureRequest
+	json.NewDecoder(rec.Body).Decode(&req)
+
+	rec = f.do("POST", "/api/admins/erasure-requests/"+strconv.FormatInt(req.RequestID, 10)+"/reject", admin, map[string]string{"note": "laporan masih diproses polisi"})
+	expectStatus(t, rec, http.StatusOK)
+	var decided database.ErasureRequest
+	json.NewDecoder(rec.Body).Decode(&decided)
+	if decided.Status != database.ErasureStatusRejected || decided.DecidedBy == nil || *decided.DecidedBy != "a1" {
+		t.Errorf("decided = %+v", decided)
+	}
+	if u, _ := f.store.Repos().Users.FindByID(context.Background(), "u1"); u == nil || u.Email != "u1@example.com" {
+		t.Errorf("rejected erasure changed user: %+v", u)
+	}
+	msgs := f.mail.Messages()
+	if last := msgs[len(msgs)-1]; last.To != "u1@example.com" || !strings.Contains(last.Body, "laporan masih diproses polisi") {
+		t.Errorf("last email = %+v", last)
+	}
+
+	// Setelah ditolak, user boleh mengajukan lagi.
+	expectStatus(t, f.do("POST", "/api/users/me/erasure-request", user, nil), http.StatusCreated)
+}
+
+// TestErasureLeavesNoPIIInAuditLog memastikan audit_log, yang tidak ikut
+// dianonimkan, tidak pernah menyimpan data pribadi user.
+func TestErasureLeavesNoPIIInAuditLog(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+
+	rec := f.doUpload("POST", "/users", "", map[string]string{
+		"name": "Dewi Lestari", "email": "dewi@example.com", "password": testPassword, "nik": "3171010101900009", "phone": "081234567890",
+	}, map[string][]byte{"ktp_image": pngBytes(t)})
+	expectStatus(t, rec, http.StatusCreated)
+	var user database.User
+	json.NewDecoder(rec.Body).Decode(&user)
+	rec = f.do("POST", "/auth/login", "", map[string]string{"email": "dewi@example.com", "password": testPassword})
+	expectStatus(t, rec, http.StatusOK)
+	var login server.LoginResponse
+	json.NewDecoder(rec.Body).Decode(&login)
+	dewi := login.Token
+
+	expectStatus(t, f.do("PUT", "/api/users/"+user.UserID, admin, map[string]string{"name": "Dewi Anggraini", "email": "dewi.a@example.com"}), http.StatusOK)
+	ctx := context.Background()
+	if err := f.store.Repos().Users.Update(ctx, user.UserID, map[string]interface{}{"email_verified_at": time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(t, f.do("POST", "/api/admins/verifications/ktp/"+user.UserID, admin, verifyBody("VERIFIED", *user.KTPImageID)), http.StatusOK)
+	rec = f.postVehicleWithSTNK(dewi, "B 4321 KLM")
+	expectStatus(t, rec, http.StatusCreated)
+	var vehicle server.VehicleResponse
+	json.NewDecoder(rec.Body).Decode(&vehicle)
+	stnkImage, _ := f.vehicleImages(vehicle.VehicleID)
+	expectStatus(t, f.do("POST", "/api/admins/verifications/stnk/"+strconv.FormatInt(vehicle.VehicleID, 10), admin, verifyBody("VERIFIED", stnkImage)), http.StatusOK)
+	rec = f.postLostReport(dewi, vehicle.VehicleID)
+	expectStatus(t, rec, http.StatusCreated)
+	var report database.LostReport
+	json.NewDecoder(rec.Body).Decode(&report)
+	lostPath := "/api/lost_reports/" + strconv.Itoa(report.LostID)
+	expectStatus(t, f.do("PUT", lostPath, admin, map[string]string{"status": database.StatusLostReportSedangDiproses}), http.StatusOK)
+	expectStatus(t, f.do("DELETE", "/api/users/"+user.UserID, admin, nil), http.StatusNoContent)
+	expectStatus(t, f.do("POST", "/api/users/"+user.UserID+"/restore", admin, nil), http.StatusOK)
+
+	rec = f.do("POST", "/api/users/me/erasure-request", login.Token, nil)
+	expectStatus(t, rec, http.StatusCreated)
+	var req database.ErasureRequest
+	json.NewDecoder(rec.Body).Decode(&req)
+	expectStatus(t, f.do("POST", "/api/admins/erasure-requests/"+strconv.FormatInt(req.RequestID, 10)+"/approve", admin, nil), http.StatusOK)
+
+	entries := f.auditEntries(admin, "?entity_id="+user.UserID)
+	if len(entries) < 3 {
+		t.Fatalf("got %d audit entries of the user, want the update, delete and restore", len(entries))
+	}
+	rec = f.do("GET", "/api/admins/audit-log/export", admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	log := rec.Body.String()
+	for _, pii := range []string{"Dewi", "dewi@example.com", "dewi.a@example.com", "3171010101900009", "081234567890", "4321", "Thamrin"} {
+		if strings.Contains(log, pii) {
+			t.Errorf("audit log still contains %q after erasure", pii)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/ratelimit"
+)
+
+func expectRetryAfter(t *testing.T, rec *httptest.ResponseRecorder) {
+	t.Helper()
+	expectStatus(t, rec, http.StatusTooManyRequests)
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("429 without Retry-After header")
+	}
+}
+
+func TestRateLimitPerUser(t *testing.T) {
+	cfg := testConfig()
+	cfg.RateLimit.PerUser = ratelimit.Limit{Requests: 1, Per: time.Hour, Burst: 3}
+	f := newFixtureWithConfig(t, cfg)
+	u1 := f.createUser("u1", "u1@example.com", false)
+	u2 := f.createUser("u2", "u2@example.com", false)
+
+	for i := 0; i < 3; i++ {
+		expectStatus(t, f.do("GET", "/api/vehicles/my", u1, nil), http.StatusOK)
+	}
+	rec := f.do("GET", "/api/vehicles/my", u1, nil)
+	expectRetryAfter(t, rec)
+	if got := rec.Header().Get("Retry-After"); got != "3600" {
+		t.Errorf("Retry-After = %q, want 3600", got)
+	}
+
+	// Bucket per user terpisah.
+	expectStatus(t, f.do("GET", "/api/vehicles/my", u2, nil), http.StatusOK)
+}
+
+func TestRateLimitPerAPIKey(t *testing.T) {
+	cfg := testConfig()
+	cfg.RateLimit.PerAPIKey = ratelimit.Limit{Requests: 1, Per: time.Hour, Burst: 2}
+	cfg.RateLimit.Lockout = ratelimit.LockoutPolicy{Threshold: 3, BaseDelay: time.Minute, MaxDelay: time.Hour, ResetAfter: time.Hour}
+	f := newFixtureWithConfig(t, cfg)
+	f.createUser("u1", "u1@example.com", false)
+	f.createUser("u2", "u2@example.com", false)
+	if err := f.store.AddAPIKey("u1", "kunci-u1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.store.AddAPIKey("u2", "kunci-u2"); err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(key, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/vehicles/my", nil)
+		req.RemoteAddr = ip + ":1234"
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		f.handler.ServeHTTP(rec, req)
+		return rec
+	}
+	// Bucket per key yang valid.
+	expectStatus(t, send("kunci-u1", "192.0.2.1"), http.StatusOK)
+	expectStatus(t, send("kunci-u1", "192.0.2.2"), http.StatusOK)
+	expectRetryAfter(t, send("kunci-u1", "192.0.2.1"))
+	expectStatus(t, send("kunci-u2", "192.0.2.1"), http.StatusOK)
+
+	// Tebakan yang salah mengunci IP, berapa pun variasi key-nya.
+	for _, guess := range []string{"tebakan-1", "tebakan-2", "tebakan-3"} {
+		expectStatus(t, send(guess, "192.0.2.9"), http.StatusForbidden)
+	}
+	rec := send("tebakan-4", "192.0.2.9")
+	expectRetryAfter(t, rec)
+	if got := rec.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want 60", got)
+	}
+	expectRetryAfter(t, send("kunci-u2", "192.0.2.9"))
+	expectStatus(t, send("kunci-u2", "192.0.2.1"), http.StatusOK)
+}
+
+func TestLoginLockout(t *testing.T) {
+	cfg := testConfig()
+	cfg.RateLimit.Login = ratelimit.Limit{Requests: 100, Per: time.Minute, Burst: 100}
+	cfg.RateLimit.Lockout = ratelimit.LockoutPolicy{Threshold: 3, BaseDelay: time.Minute, MaxDelay: time.Hour, ResetAfter: time.Hour}
+	f := newFixtureWithConfig(t, cfg)
+	f.createUser("u1", "u1@example.com", false)
+	f.createUser("u2", "u2@example.com", false)
+
+	login := func(email, password string) *httptest.ResponseRecorder {
+		return f.do("POST", "/auth/login", "", map[string]string{"email": email, "password": password})
+	}
+
+	// Login sukses mereset hitungan kegagalan.
+	expectStatus(t, login("u1@example.com", "salah"), http.StatusUnauthorized)
+	expectStatus(t, login("u1@example.com", "salah"), http.StatusUnauthorized)
+	expectStatus(t, login("u1@example.com", testPassword), http.StatusOK)
+
+	for i := 0; i < 3; i++ {
+		expectStatus(t, login("U1@example.com", "salah"), http.StatusUnauthorized)
+	}
+	rec := login("u1@example.com", testPassword)
+	expectRetryAfter(t, rec)
+	if got := rec.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want 60", got)
+	}
+
+	// Akun lain tidak ikut terkunci.
+	expectStatus(t, login("u2@example.com", testPassword), http.StatusOK)
+}
+
+func TestLoginRateLimitPerIP(t *testing.T) {
+	cfg := testConfig()
+	cfg.RateLimit.Login = ratelimit.Limit{Requests: 1, Per: time.Minute, Burst: 2}
+	f := newFixtureWithConfig(t, cfg)
+	f.createUser("u1", "u1@example.com", false)
+
+	for i := 0; i < 2; i++ {
+		expectStatus(t, f.do("POST", "/auth/login", "", map[string]string{"email": "nobody@example.com", "password": "x"}), http.StatusUnauthorized)
+	}
+	expectRetryAfter(t, f.do("POST", "/auth/login", "", map[string]string{"email": "u1@example.com", "password": testPassword}))
+}
+
+func TestUploadQuota(t *testing.T) {
+	cfg := testConfig()
+	cfg.RateLimit.Upload = ratelimit.Limit{Requests: 1, Per: time.Hour, Burst: 1}
+	f := newFixtureWithConfig(t, cfg)
+	user := f.createUser("u1", "u1@example.com", false)
+
+	upload := func(plate string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		mw.WriteField("vehicle_name", "Vario")
+		mw.WriteField("plate_number", plate)
+		mw.WriteField("color", "Hitam")
+		fw, _ := mw.CreateFormFile("stnk_image", "stnk.png")
+		fw.Write(pngBytes(t))
+		mw.Close()
+		req := httptest.NewRequest("POST", "/api/vehicles", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+user)
+		rec := httptest.NewRecorder()
+		f.handler.ServeHTTP(rec, req)
+		return rec
+	}
+	expectStatus(t, upload("D 1 ABC"), http.StatusCreated)
+	expectRetryAfter(t, upload("D 2 ABC"))
+
+	// Endpoint non-upload tidak terkena kuota.
+	expectStatus(t, f.do("GET", "/api/vehicles/my", user, nil), http.StatusOK)
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	cfg := testConfig()
+	cfg.RateLimit.Enabled = false
+	cfg.RateLimit.Login = ratelimit.Limit{Requests: 1, Per: time.Hour, Burst: 1}
+	f := newFixtureWithConfig(t, cfg)
+	for i := 0; i < 3; i++ {
+		expectStatus(t, f.do("POST", "/auth/login", "", map[string]string{"email": "nobody@example.com", "password": "x"}), http.StatusUnauthorized)
+	}
+}
+
+func TestLockoutBackoff(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	policy := ratelimit.LockoutPolicy{Threshold: 2, BaseDelay: time.Second, MaxDelay: 5 * time.Second, ResetAfter: time.Hour}
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+
+	want := []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		until, err := store.RecordFailure(ctx, "k", policy, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got time.Duration
+		if until.After(now) {
+			got = until.Sub(now)
+		}
+		if got != w {
+			t.Errorf("failure %d: lock = %v, want %v", i+1, got, w)
+		}
+	}
+
+	// Kegagalan setelah ResetAfter memulai hitungan dari awal.
+	later := now.Add(2 * time.Hour)
+	until, _ := store.RecordFailure(ctx, "k", policy, later)
+	if until.After(later) {
+		t.Errorf("lock after reset window = %v, want none", until.Sub(later))
+	}
+}
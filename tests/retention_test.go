@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/server"
+)
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	user := f.createUser("u1", "u1@example.com", false)
+	vehicleID := f.createVehicle("u1")
+	lostID := f.createLostReport("u1", vehicleID)
+	vehiclePath := "/api/vehicles/" + strconv.FormatInt(vehicleID, 10)
+	lostPath := "/api/lost_reports/" + strconv.Itoa(lostID)
+
+	expectStatus(t, f.do("DELETE", "/api/users/u1", admin, nil), http.StatusNoContent)
+	expectStatus(t, f.do("GET", "/api/users/u1", admin, nil), http.StatusNotFound)
+	expectStatus(t, f.do("GET", vehiclePath, admin, nil), http.StatusNotFound)
+	expectStatus(t, f.do("GET", lostPath, admin, nil), http.StatusNotFound)
+	expectStatus(t, f.do("DELETE", "/api/users/u1", admin, nil), http.StatusNotFound)
+
+	// Kendaraan dan laporan ikut terhapus bersama user, jadi harus
+	// dikembalikan lewat user-nya.
+	expectStatus(t, f.do("POST", vehiclePath+"/restore", admin, nil), http.StatusConflict)
+	expectStatus(t, f.do("POST", lostPath+"/restore", admin, nil), http.StatusConflict)
+	expectStatus(t, f.do("POST", "/api/users/u1/restore", user, nil), http.StatusForbidden)
+	expectStatus(t, f.do("POST", "/api/users/u1/restore", admin, nil), http.StatusOK)
+	expectStatus(t, f.do("POST", "/api/users/u1/restore", admin, nil), http.StatusNotFound)
+	expectStatus(t, f.do("GET", "/api/users/u1", admin, nil), http.StatusOK)
+	expectStatus(t, f.do("GET", vehiclePath, admin, nil), http.StatusOK)
+	expectStatus(t, f.do("GET", lostPath, user, nil), http.StatusOK)
+
+	// Laporan yang dihapus sendiri tidak ikut kembali saat kendaraannya
+	// di-restore.
+	expectStatus(t, f.do("DELETE", lostPath, user, nil), http.StatusNoContent)
+	expectStatus(t, f.do("DELETE", vehiclePath, user, nil), http.StatusNoContent)
+	expectStatus(t, f.do("POST", vehiclePath+"/restore", admin, nil), http.StatusOK)
+	expectStatus(t, f.do("GET", lostPath, user, nil), http.StatusNotFound)
+	expectStatus(t, f.do("POST", lostPath+"/restore", admin, nil), http.StatusOK)
+	expectStatus(t, f.do("GET", lostPath, user, nil), http.StatusOK)
+
+	// Pelapor yang bukan pemilik kendaraan: laporannya tidak boleh kembali
+	// selama user pelapor masih terhapus, walau kendaraannya aktif.
+	reporter := f.createUser("u2", "u2@example.com", false)
+	otherID := f.createLostReport("u2", vehicleID)
+	otherPath := "/api/lost_reports/" + strconv.Itoa(otherID)
+	expectStatus(t, f.do("DELETE", otherPath, reporter, nil), http.StatusNoContent)
+	expectStatus(t, f.do("DELETE", "/api/users/u2", admin, nil), http.StatusNoContent)
+	expectStatus(t, f.do("POST", otherPath+"/restore", admin, nil), http.StatusConflict)
+	expectStatus(t, f.do("GET", otherPath, admin, nil), http.StatusNotFound)
+
+	for _, action := range []string{"user.restore", "vehicle.restore", "lost_report.restore"} {
+		if n := len(f.auditEntries(admin, "?action="+action)); n != 1 {
+			t.Errorf("got %d %s entries, want 1", n, action)
+		}
+	}
+}
+
+func TestDeletedCameraKeepsDetectionHistory(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	f.createUser("u1", "u1@example.com", false)
+	lostID := f.createLostReport("u1", f.createVehicle("u1"))
+	ctx := context.Background()
+
+	cam := database.Camera{Name: "Tugu", Latitude: -7.78, Longitude: 110.37, IsActive: true}
+	if err := f.store.Repos().Cameras.Create(ctx, &cam); err != nil {
+		t.Fatal(err)
+	}
+	det := database.Detected{CameraID: int(cam.CameraID), Timestamp: time.Now()}
+	if err := f.store.Repos().Detected.Create(ctx, &det); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.store.Repos().Suspects.Create(ctx, &database.Suspect{DetectedID: int64(det.DetectedID), LostID: int64(lostID), FinalScore: 0.9}); err != nil {
+		t.Fatal(err)
+	}
+	camPath := "/api/cameras/" + strconv.FormatInt(cam.CameraID, 10)
+
+	expectStatus(t, f.do("DELETE", camPath, admin, nil), http.StatusNoContent)
+	expectStatus(t, f.do("GET", camPath, admin, nil), http.StatusNotFound)
+	if n, _ := f.store.Repos().Cameras.CountActive(ctx); n != 0 {
+		t.Errorf("active cameras = %d, want 0", n)
+	}
+	results, err := f.store.Repos().Suspects.ListResultsByLostReportID(ctx, lostID, database.SuspectResultFilter{})
+	if err != nil || len(results) != 1 || results[0].CameraName != "Tugu" {
+		t.Errorf("results after camera delete = %+v, %v", results, err)
+	}
+
+	expectStatus(t, f.do("POST", camPath+"/restore", admin, nil), http.StatusOK)
+	expectStatus(t, f.do("GET", camPath, admin, nil), http.StatusOK)
+}
+
+func TestPurgeDeleted(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	f.createUser("u1", "u1@example.com", false)
+	lostID := f.createLostReport("u1", f.createVehicle("u1"))
+	ctx := context.Background()
+
+	path := filepath.Join("uploads", "images", "ktp_purge.jpg")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("ktp"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	img := database.Image{StoragePath: path}
+	if err := f.store.Repos().Images.Create(ctx, &img); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.store.Repos().Users.Update(ctx, "u1", map[string]interface{}{"ktp_image_id": img.ImageID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.store.Repos().Suspects.Create(ctx, &database.Suspect{DetectedID: 1, LostID: int64(lostID)}); err != nil {
+		t.Fatal(err)
+	}
+
+	expectStatus(t, f.do("DELETE", "/api/users/u1", admin, nil), http.StatusNoContent)
+
+	// Masih dalam masa retensi: tidak ada yang dihapus.
+	result, err := f.srv.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *result != (server.PurgeResult{}) {
+		t.Errorf("purge within retention = %+v", result)
+	}
+
+	result, err = f.srv.PurgeDeleted(ctx, time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := server.PurgeResult{LostReports: 1, Vehicles: 1, Users: 1, Images: 1}
+	if *result != want {
+		t.Errorf("purge = %+v, want %+v", result, want)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("KTP file still exists: %v", err)
+	}
+	if _, err := f.store.Repos().Images.GetStoragePath(ctx, img.ImageID); err != sql.ErrNoRows {
+		t.Errorf("KTP image record: %v", err)
+	}
+	if suspects, _ := f.store.Repos().Suspects.List(ctx); len(suspects) != 0 {
+		t.Errorf("suspects after purge = %+v", suspects)
+	}
+	expectStatus(t, f.do("POST", "/api/users/u1/restore", admin, nil), http.StatusNotFound)
+
+	// Email user yang sudah di-purge bisa dipakai lagi.
+	f.createUser("u1", "u1@example.com", false)
+}
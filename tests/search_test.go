@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/server"
+)
+
+func TestLostReportSearchParams(t *testing.T) {
+	f := newFixture(t)
+	owner := f.createUser("u1", "u1@example.com", false)
+	other := f.createUser("u2", "u2@example.com", false)
+	admin := f.createUser("a1", "a1@example.com", true)
+	vehicleID := f.createVehicle("u1")
+	ctx := context.Background()
+	repos := f.store.Repos()
+
+	reportTime := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	rec := f.doMultipart("POST", "/api/lost_reports", owner, map[string]string{
+		"vehicle_id": strconv.FormatInt(vehicleID, 10),
+		"address":    "Jl. Thamrin",
+		"latitude":   "-6.1862",
+		"longitude":  "106.8281",
+		"timestamp":  reportTime.Format(time.RFC3339),
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	var created server.LostReportResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	searchPath := "/api/lost_reports/" + strconv.Itoa(created.LostID) + "/search"
+
+	cams := []database.Camera{
+		{Name: "Bundaran HI", Latitude: -6.1955, Longitude: 106.8232, IsActive: true, Zone: "jakarta-pusat"},
+		{Name: "Semanggi", Latitude: -6.2200, Longitude: 106.8140, IsActive: true, Zone: "jakarta-selatan"},
+		{Name: "Dago", Latitude: -6.9000, Longitude: 107.6000, IsActive: true, Zone: "bandung"},
+		{Name: "Monas", Latitude: -6.1754, Longitude: 106.8272, IsActive: false, Zone: "jakarta-pusat"},
+	}
+	for i := range cams {
+		if err := repos.Cameras.Create(ctx, &cams[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, d := range []struct {
+		cam int
+		at  time.Time
+	}{
+		{0, reportTime.Add(30 * time.Minute)},
+		{0, reportTime.Add(2 * time.Hour)},
+		{0, reportTime.Add(-48 * time.Hour)},
+		{1, reportTime.Add(time.Hour)},
+		{2, reportTime.Add(10 * time.Hour)},
+		{3, reportTime.Add(time.Hour)},
+	} {
+		if err := repos.Detected.Create(ctx, &database.Detected{CameraID: int(cams[d.cam].CameraID), Timestamp: d.at}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	preview := func(query string) server.SearchPreview {
+		t.Helper()
+		rec := f.do("GET", searchPath+"/preview"+query, owner, nil)
+		expectStatus(t, rec, http.StatusOK)
+		var p server.SearchPreview
+		json.NewDecoder(rec.Body).Decode(&p)
+		return p
+	}
+
+	rec = f.do("GET", searchPath, owner, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var params server.SearchParamsResponse
+	json.NewDecoder(rec.Body).Decode(&params)
+	if params.Custom || params.RadiusKm != 5 || params.WindowHours != 24 || params.Limits.MaxRadiusKm != 50 {
+		t.Errorf("default search params = %+v", params)
+	}
+	expectStatus(t, f.do("GET", searchPath, other, nil), http.StatusForbidden)
+	expectStatus(t, f.do("GET", "/api/lost_reports/9999/search", owner, nil), http.StatusNotFound)
+
+	// Default: radius 5 km dari lokasi laporan; kamera nonaktif dilewati
+	// dan deteksi di luar jendela waktu tidak dihitung.
+	p := preview("")
+	if p.CameraCount != 2 || p.DetectionCount != 3 || !p.WindowEnd.Equal(reportTime.Add(24*time.Hour)) || len(p.Warnings) != 0 {
+		t.Errorf("default preview = %+v", p)
+	}
+	if p := preview("?search_radius_km=2&search_window_hours=1"); p.CameraCount != 1 || p.DetectionCount != 1 || p.Cameras[0].DistanceKm == nil {
+		t.Errorf("narrow preview = %+v", p)
+	}
+	if p := preview("?camera_ids=" + strconv.FormatInt(cams[3].CameraID, 10) + "&zones=jakarta-selatan"); p.CameraCount != 1 || p.DetectionCount != 1 || len(p.Warnings) != 1 {
+		t.Errorf("zone preview = %+v", p)
+	}
+	// Kamera zona tetap dibatasi radius dari lokasi laporan; kamera yang
+	// dipilih langsung tidak.
+	if p := preview("?zones=bandung"); p.CameraCount != 0 {
+		t.Errorf("zone preview outside radius = %+v", p)
+	}
+	if p := preview("?camera_ids=" + strconv.FormatInt(cams[2].CameraID, 10)); p.CameraCount != 1 || p.Cameras[0].CameraID != cams[2].CameraID {
+		t.Errorf("camera preview outside radius = %+v", p)
+	}
+	expectStatus(t, f.do("GET", searchPath+"/preview?search_radius_km=100", owner, nil), http.StatusBadRequest)
+	expectStatus(t, f.do("GET", searchPath+"/preview?camera_ids=9999", owner, nil), http.StatusBadRequest)
+	expectStatus(t, f.do("GET", searchPath+"/preview?zones=surabaya", owner, nil), http.StatusBadRequest)
+
+	// Parameter tersimpan ikut mengubah run yang masih antre.
+	expectStatus(t, f.do("PUT", searchPath, other, map[string]interface{}{"zones": []string{"jakarta-selatan"}}), http.StatusForbidden)
+	rec = f.do("PUT", searchPath, owner, map[string]interface{}{"zones": []string{"jakarta-selatan", " jakarta-selatan "}, "search_window_hours": 12})
+	expectStatus(t, rec, http.StatusOK)
+	json.NewDecoder(rec.Body).Decode(&params)
+	if !params.Custom || params.WindowHours != 12 || params.RadiusKm != 5 || !reflect.DeepEqual(params.Zones, []string{"jakarta-selatan"}) {
+		t.Errorf("saved search params = %+v", params)
+	}
+	if p := preview(""); p.CameraCount != 1 || p.Cameras[0].CameraID != cams[1].CameraID || p.DetectionCount != 1 {
+		t.Errorf("saved preview = %+v", p)
+	}
+
+	// Batas baru berlaku untuk penyimpanan berikutnya.
+	expectStatus(t, f.do("PUT", "/api/admins/analysis-limits", owner, map[string]interface{}{"max_radius_km": 10, "max_window_hours": 6, "max_cameras": 5}), http.StatusForbidden)
+	expectStatus(t, f.do("PUT", "/api/admins/analysis-limits", admin, map[string]interface{}{"max_radius_km": 10, "max_window_hours": 0, "max_cameras": 5}), http.StatusBadRequest)
+	expectStatus(t, f.do("PUT", "/api/admins/analysis-limits", admin, map[string]interface{}{"max_radius_km": 10, "max_window_hours": 6, "max_cameras": 5}), http.StatusOK)
+	rec = f.do("GET", "/api/admins/analysis-limits", admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var limits database.AnalysisLimits
+	json.NewDecoder(rec.Body).Decode(&limits)
+	if limits.MaxWindowHours != 6 || limits.UpdatedBy == nil || *limits.UpdatedBy != "a1" {
+		t.Errorf("limits = %+v", limits)
+	}
+	expectStatus(t, f.do("PUT", searchPath, owner, map[string]interface{}{"search_radius_km": 2}), http.StatusBadRequest)
+	expectStatus(t, f.do("PUT", searchPath, admin, map[string]interface{}{"search_window_hours": 6}), http.StatusOK)
+
+	rec = f.do("POST", "/api/admins/analysis-runs/claim", admin, map[string]string{"scorer_version": "reid-v2"})
+	expectStatus(t, rec, http.StatusOK)
+	var job server.AnalysisJob
+	json.NewDecoder(rec.Body).Decode(&job)
+	if job.Run.WindowHours != 6 || !reflect.DeepEqual(job.Run.Zones, []string{"jakarta-selatan"}) ||
+		!reflect.DeepEqual(job.CameraIDs, []int64{cams[1].CameraID}) || !job.WindowEnd.Equal(reportTime.Add(6*time.Hour)) {
+		t.Errorf("claimed job = %+v", job)
+	}
+
+	if n := len(f.auditEntries(admin, "?action=lost_report.search_update")); n != 2 {
+		t.Errorf("got %d lost_report.search_update entries, want 2", n)
+	}
+	if n := len(f.auditEntries(admin, "?action=analysis_limits.update")); n != 1 {
+		t.Errorf("got %d analysis_limits.update entries, want 1", n)
+	}
+}
+
+func TestSearchPreviewWithoutLocation(t *testing.T) {
+	f := newFixture(t)
+	owner := f.createUser("u1", "u1@example.com", false)
+	lostID := f.createLostReport("u1", f.createVehicle("u1"))
+	if err := f.store.Repos().Cameras.Create(context.Background(), &database.Camera{Name: "Bundaran HI", IsActive: true, Zone: "jakarta-pusat"}); err != nil {
+		t.Fatal(err)
+	}
+	path := "/api/lost_reports/" + strconv.Itoa(lostID) + "/search/preview"
+
+	rec := f.do("GET", path, owner, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var p server.SearchPreview
+	json.NewDecoder(rec.Body).Decode(&p)
+	if p.CameraCount != 0 || len(p.Cameras) != 0 || len(p.Warnings) != 1 {
+		t.Errorf("preview without location = %+v", p)
+	}
+	rec = f.do("GET", path+"?zones=jakarta-pusat", owner, nil)
+	expectStatus(t, rec, http.StatusOK)
+	p = server.SearchPreview{}
+	json.NewDecoder(rec.Body).Decode(&p)
+	if p.CameraCount != 1 || len(p.Warnings) != 0 || p.Cameras[0].DistanceKm != nil {
+		t.Errorf("zone preview without location = %+v", p)
+	}
+}
+
+// TestSearchScopeCameraLimit memastikan max_cameras dihitung dari cakupan
+// akhir, termasuk kamera dari zona dan radius.
+func TestSearchScopeCameraLimit(t *testing.T) {
+	f := newFixture(t)
+	owner := f.createUser("u1", "u1@example.com", false)
+	admin := f.createUser("a1", "a1@example.com", true)
+	ctx := context.Background()
+	lostID := f.createLostReport("u1", f.createVehicle("u1"))
+	lat, lon := -6.1862, 106.8281
+	report, err := f.store.Repos().LostReports.GetByID(ctx, lostID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report.Latitude, report.Longitude = &lat, &lon
+	if err := f.store.Repos().LostReports.Update(ctx, lostID, report); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		cam := database.Camera{Name: "Thamrin " + strconv.Itoa(i), Latitude: lat + float64(i)*0.001, Longitude: lon, IsActive: true, Zone: "jakarta-pusat"}
+		if err := f.store.Repos().Cameras.Create(ctx, &cam); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectStatus(t, f.do("PUT", "/api/admins/analysis-limits", admin, map[string]interface{}{"max_radius_km": 10, "max_window_hours": 24, "max_cameras": 2}), http.StatusOK)
+
+	searchPath := "/api/lost_reports/" + strconv.Itoa(lostID) + "/search"
+	for _, q := range []string{"?zones=jakarta-pusat", "", "?search_radius_km=0.25"} {
+		expectStatus(t, f.do("GET", searchPath+"/preview"+q, owner, nil), http.StatusBadRequest)
+	}
+	expectStatus(t, f.do("PUT", searchPath, owner, map[string]interface{}{"zones": []string{"jakarta-pusat"}}), http.StatusBadRequest)
+	expectStatus(t, f.do("POST", "/api/admins/lost_reports/"+strconv.Itoa(lostID)+"/analysis-runs", admin, map[string]interface{}{"zones": []string{"jakarta-pusat"}}), http.StatusBadRequest)
+	// Radius yang dipersempit membuat cakupan zona muat dalam batas.
+	expectStatus(t, f.do("PUT", searchPath, owner, map[string]interface{}{"zones": []string{"jakarta-pusat"}, "search_radius_km": 0.15}), http.StatusOK)
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/server"
+)
+
+func TestAdminDashboardStats(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	user := f.createUser("u1", "u1@example.com", false)
+	vehicleID := f.createVehicle("u1")
+	foundID := f.createLostReport("u1", vehicleID)
+	otherID := f.createLostReport("u1", vehicleID)
+	ctx := context.Background()
+	now := time.Now()
+
+	cameras := map[string]database.Camera{}
+	for _, zone := range []string{"jakarta", "bandung"} {
+		cam := database.Camera{Name: "Cam " + zone, IsActive: true, Zone: zone}
+		if err := f.store.Repos().Cameras.Create(ctx, &cam); err != nil {
+			t.Fatal(err)
+		}
+		cameras[zone] = cam
+	}
+	addSuspect := func(zone string, lostID int, score float64) {
+		t.Helper()
+		det := database.Detected{CameraID: int(cameras[zone].CameraID), Timestamp: now.Add(-30 * time.Minute)}
+		if err := f.store.Repos().Detected.Create(ctx, &det); err != nil {
+			t.Fatal(err)
+		}
+		s := database.Suspect{DetectedID: int64(det.DetectedID), LostID: int64(lostID), FinalScore: score, CreatedAt: now.Add(-20 * time.Minute)}
+		if err := f.store.Repos().Suspects.Create(ctx, &s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addSuspect("jakarta", foundID, 0.95)
+	addSuspect("jakarta", foundID, 0.42)
+	addSuspect("bandung", otherID, 0.8)
+
+	expectStatus(t, f.do("PUT", "/api/lost_reports/"+strconv.Itoa(foundID), admin, map[string]string{"status": database.StatusLostReportSudahDitemukan}), http.StatusOK)
+
+	get := func(query string) server.StatsResponse {
+		t.Helper()
+		rec := f.do("GET", "/api/admins/stats"+query, admin, nil)
+		expectStatus(t, rec, http.StatusOK)
+		var resp server.StatsResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	all := get("")
+	if all.Bucket != database.StatsBucketDay || all.To.Sub(all.From) != 30*24*time.Hour {
+		t.Errorf("default filter = %s..%s bucket %q", all.From, all.To, all.Bucket)
+	}
+	total := map[string]int{}
+	for _, b := range all.LostReportsByStatus {
+		for status, n := range b.Counts {
+			total[status] += n
+		}
+	}
+	if total[database.StatusLostReportSudahDitemukan] != 1 || total[database.StatusLostReportBelumDiproses] != 1 {
+		t.Errorf("status counts = %v", total)
+	}
+	if all.TimeToFound.FoundReports != 1 || all.TimeToFound.MeanHours == nil {
+		t.Errorf("time to found = %+v", all.TimeToFound)
+	}
+	if all.Suspects.Reports != 2 || all.Suspects.Suspects != 3 || all.Suspects.MaxPerReport != 2 {
+		t.Errorf("suspect stats = %+v", all.Suspects)
+	}
+	if n := all.Suspects.ScoreDistribution[9].Count; n != 1 {
+		t.Errorf("suspects scoring 0.9-1.0 = %d, want 1", n)
+	}
+	if len(all.DetectionsPerCamera) != 2 {
+		t.Errorf("detections per camera = %+v", all.DetectionsPerCamera)
+	}
+	users := 0
+	for _, b := range all.NewUsers {
+		users += b.Count
+	}
+	if users != 2 {
+		t.Errorf("new users = %d, want 2", users)
+	}
+
+	jakarta := get("?zone=jakarta&bucket=week")
+	if jakarta.Zone != "jakarta" || jakarta.Bucket != database.StatsBucketWeek {
+		t.Errorf("filter echo = %q/%q", jakarta.Zone, jakarta.Bucket)
+	}
+	if jakarta.Suspects.Suspects != 2 || len(jakarta.DetectionsPerCamera) != 1 || jakarta.DetectionsPerCamera[0].Count != 2 {
+		t.Errorf("jakarta suspects=%+v detections=%+v", jakarta.Suspects, jakarta.DetectionsPerCamera)
+	}
+	if len(jakarta.LostReportsByStatus) != 1 || jakarta.LostReportsByStatus[0].Start.Weekday() != time.Monday {
+		t.Errorf("jakarta weekly buckets = %+v", jakarta.LostReportsByStatus)
+	}
+
+	past := get("?from=2020-01-01&to=2020-01-31")
+	if len(past.LostReportsByStatus) != 0 || past.Suspects.Suspects != 0 || past.TimeToFound.MeanHours != nil {
+		t.Errorf("stats outside range = %+v", past)
+	}
+
+	for _, q := range []string{"?bucket=month", "?from=yesterday", "?from=2024-02-01&to=2024-01-01", "?from=2020-01-01&to=2024-01-01", "?top=0"} {
+		expectStatus(t, f.do("GET", "/api/admins/stats"+q, admin, nil), http.StatusBadRequest)
+	}
+	expectStatus(t, f.do("GET", "/api/admins/stats", user, nil), http.StatusForbidden)
+}
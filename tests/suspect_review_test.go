@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/server"
+)
+
+func TestSuspectReviewWorkflow(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	owner := f.createUser("u1", "u1@example.com", false)
+	other := f.createUser("u2", "u2@example.com", false)
+	lostID := f.createLostReport("u1", f.createVehicle("u1"))
+	ctx := context.Background()
+
+	cam := database.Camera{Name: "Bundaran HI", Latitude: -6.195, Longitude: 106.823, IsActive: true}
+	if err := f.store.Repos().Cameras.Create(ctx, &cam); err != nil {
+		t.Fatal(err)
+	}
+	img := database.Image{StoragePath: "uploads/images/motor_1.jpg"}
+	if err := f.store.Repos().Images.Create(ctx, &img); err != nil {
+		t.Fatal(err)
+	}
+	var suspectIDs []int64
+	for _, score := range []float64{0.92, 0.41} {
+		det := database.Detected{CameraID: int(cam.CameraID), MotorcycleImageID: sql.NullInt64{Int64: img.ImageID, Valid: true}, Timestamp: time.Now()}
+		if err := f.store.Repos().Detected.Create(ctx, &det); err != nil {
+			t.Fatal(err)
+		}
+		// review_status dari body diabaikan: suspect baru selalu PENDING.
+		rec := f.do("POST", "/api/suspects", admin, map[string]interface{}{
+			"detected_id": det.DetectedID, "lost_id": lostID, "final_score": score, "review_status": "CONFIRMED",
+		})
+		expectStatus(t, rec, http.StatusCreated)
+		var created database.Suspect
+		json.NewDecoder(rec.Body).Decode(&created)
+		if created.ReviewStatus != database.SuspectReviewPending {
+			t.Errorf("new suspect review_status = %q", created.ReviewStatus)
+		}
+		suspectIDs = append(suspectIDs, created.SuspectID)
+	}
+	path := func(id int64, action string) string { return "/api/suspects/" + strconv.FormatInt(id, 10) + "/" + action }
+
+	// Feedback hanya dari pemilik laporan.
+	expectStatus(t, f.do("POST", path(suspectIDs[0], "feedback"), other, map[string]string{"feedback": "MINE"}), http.StatusForbidden)
+	expectStatus(t, f.do("POST", path(suspectIDs[0], "feedback"), admin, map[string]string{"feedback": "MINE"}), http.StatusForbidden)
+	expectStatus(t, f.do("POST", path(suspectIDs[0], "feedback"), owner, map[string]string{"feedback": "MAYBE"}), http.StatusBadRequest)
+	expectStatus(t, f.do("POST", path(9999, "feedback"), owner, map[string]string{"feedback": "MINE"}), http.StatusNotFound)
+	rec := f.do("POST", path(suspectIDs[0], "feedback"), owner, map[string]string{"feedback": database.OwnerFeedbackMine})
+	expectStatus(t, rec, http.StatusOK)
+	var sp database.Suspect
+	json.NewDecoder(rec.Body).Decode(&sp)
+	if sp.OwnerFeedback == nil || *sp.OwnerFeedback != database.OwnerFeedbackMine || sp.ReviewStatus != database.SuspectReviewPending {
+		t.Errorf("after owner feedback: %+v", sp)
+	}
+
+	expectStatus(t, f.do("POST", path(suspectIDs[1], "review"), owner, map[string]string{"status": "REJECTED"}), http.StatusForbidden)
+	expectStatus(t, f.do("POST", path(suspectIDs[1], "review"), admin, map[string]string{"status": "MAYBE"}), http.StatusBadRequest)
+	expectStatus(t, f.do("POST", path(suspectIDs[1], "review"), admin, map[string]interface{}{"status": "REJECTED", "mark_found": true}), http.StatusBadRequest)
+	expectStatus(t, f.do("POST", path(suspectIDs[1], "review"), admin, map[string]string{"status": "REJECTED", "comment": "beda warna"}), http.StatusOK)
+
+	rec = f.do("POST", path(suspectIDs[0], "review"), admin, map[string]interface{}{"status": "CONFIRMED", "comment": "plat cocok", "mark_found": true})
+	expectStatus(t, rec, http.StatusOK)
+	json.NewDecoder(rec.Body).Decode(&sp)
+	if sp.ReviewStatus != database.SuspectReviewConfirmed || sp.ReviewedBy == nil || *sp.ReviewedBy != "a1" || sp.ReviewComment != "plat cocok" {
+		t.Errorf("after review: %+v", sp)
+	}
+	if lr, _ := f.store.Repos().LostReports.GetByID(ctx, lostID); lr.Status != database.StatusLostReportSudahDitemukan {
+		t.Errorf("lost report status = %q, want %q", lr.Status, database.StatusLostReportSudahDitemukan)
+	}
+	if n := len(f.auditEntries(admin, "?action=suspect.review")); n != 2 {
+		t.Errorf("got %d suspect.review entries, want 2", n)
+	}
+	if n := len(f.auditEntries(admin, "?action=lost_report.status_change")); n != 1 {
+		t.Errorf("got %d lost_report.status_change entries, want 1", n)
+	}
+
+	rec = f.do("GET", "/api/results/"+strconv.Itoa(lostID), owner, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var result server.ResultResponse
+	json.NewDecoder(rec.Body).Decode(&result)
+	statuses := map[int64]string{}
+	for _, s := range result.Suspects {
+		statuses[s.SuspectID] = s.ReviewStatus
+	}
+	if statuses[suspectIDs[0]] != database.SuspectReviewConfirmed || statuses[suspectIDs[1]] != database.SuspectReviewRejected {
+		t.Errorf("result review statuses = %v", statuses)
+	}
+
+	// Skor yang berubah setelah review tidak mengubah label yang sudah
+	// tersimpan.
+	expectStatus(t, f.do("PUT", "/api/suspects/"+strconv.FormatInt(suspectIDs[0], 10), admin, map[string]interface{}{
+		"detected_id": sp.DetectedID, "lost_id": lostID, "final_score": 0.5,
+	}), http.StatusOK)
+	if got, _ := f.store.Repos().Suspects.GetByID(ctx, suspectIDs[0]); got.ReviewStatus != database.SuspectReviewConfirmed {
+		t.Errorf("update reset review status to %q", got.ReviewStatus)
+	}
+
+	exportCSV := func(query string) [][]string {
+		t.Helper()
+		rec := f.do("GET", "/api/admins/suspect-labels/export"+query, admin, nil)
+		expectStatus(t, rec, http.StatusOK)
+		rows, err := csv.NewReader(rec.Body).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rows
+	}
+	rows := exportCSV("")
+	if len(rows) != 4 || rows[0][12] != "is_match" {
+		t.Fatalf("export = %v", rows)
+	}
+	want := [][]string{{"OWNER", "MINE", "true"}, {"ADMIN", "REJECTED", "false"}, {"ADMIN", "CONFIRMED", "true"}}
+	for i, w := range want {
+		if r := rows[i+1]; r[10] != w[0] || r[11] != w[1] || r[12] != w[2] {
+			t.Errorf("label %d = %v, want %v", i+1, r, w)
+		}
+	}
+	if r := rows[3]; r[9] != "0.92" || r[6] != "uploads/images/motor_1.jpg" || r[14] != "plat cocok" {
+		t.Errorf("confirmed label = %v", r)
+	}
+	if rows := exportCSV("?source=OWNER"); len(rows) != 2 {
+		t.Errorf("owner labels = %v", rows)
+	}
+	expectStatus(t, f.do("GET", "/api/admins/suspect-labels/export?source=ML", admin, nil), http.StatusBadRequest)
+	expectStatus(t, f.do("GET", "/api/admins/suspect-labels/export", owner, nil), http.StatusForbidden)
+}
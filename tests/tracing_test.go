@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	testTraceID    = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testParentSpan = "00f067aa0ba902b7"
+)
+
+func useInMemoryTracer(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exp := tracetest.NewInMemoryExporter()
+	tracing.SetProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)))
+	t.Cleanup(func() { tracing.SetProvider(noop.NewTracerProvider()) })
+	return exp
+}
+
+func findSpan(spans tracetest.SpanStubs, name string) *tracetest.SpanStub {
+	for i := range spans {
+		if spans[i].Name == name {
+			return &spans[i]
+		}
+	}
+	return nil
+}
+
+func spanAttr(s *tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range s.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracingContinuesIncomingTrace(t *testing.T) {
+	exp := useInMemoryTracer(t)
+	f := newFixture(t)
+	token := f.createUser("u1", "u1@example.com", false)
+	vid := f.createVehicle("u1")
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("vehicle_id", strconv.FormatInt(vid, 10))
+	mw.WriteField("address", "Jl. Thamrin")
+	mw.WriteField("timestamp", time.Now().Add(-time.Hour).UTC().Format(time.RFC3339))
+	fw, _ := mw.CreateFormFile("motor_evidence_image", "motor.png")
+	fw.Write(pngBytes(t))
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/api/lost_reports", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("traceparent", "00-"+testTraceID+"-"+testParentSpan+"-01")
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	expectStatus(t, rec, http.StatusCreated)
+
+	spans := exp.GetSpans()
+	server := findSpan(spans, "POST /api/lost_reports")
+	if server == nil {
+		t.Fatalf("server span not found; got %d spans", len(spans))
+	}
+	if server.SpanKind != trace.SpanKindServer {
+		t.Errorf("span kind = %v, want server", server.SpanKind)
+	}
+	if got := server.SpanContext.TraceID().String(); got != testTraceID {
+		t.Errorf("trace ID = %s, want %s from traceparent", got, testTraceID)
+	}
+	if got := server.Parent.SpanID().String(); got != testParentSpan {
+		t.Errorf("parent span = %s, want %s", got, testParentSpan)
+	}
+	if got := spanAttr(server, "http.route").AsString(); got != "/api/lost_reports" {
+		t.Errorf("http.route = %q", got)
+	}
+	if got := spanAttr(server, "http.response.status_code").AsInt64(); got != http.StatusCreated {
+		t.Errorf("status attribute = %d", got)
+	}
+
+	for _, name := range []string{"parse multipart form", "storage save"} {
+		child := findSpan(spans, name)
+		if child == nil {
+			t.Errorf("span %q not recorded", name)
+			continue
+		}
+		if child.Parent.SpanID() != server.SpanContext.SpanID() {
+			t.Errorf("span %q is not a child of the server span", name)
+		}
+	}
+}
+
+func TestTracingPropagatesToOutgoingRequests(t *testing.T) {
+	exp := useInMemoryTracer(t)
+
+	var gotTraceparent string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTraceparent = r.Header.Get("traceparent")
+	}))
+	defer webhook.Close()
+
+	ctx, parent := tracing.Start(t.Context(), "notify")
+	req, _ := http.NewRequestWithContext(ctx, "POST", webhook.URL, nil)
+	resp, err := tracing.NewHTTPClient(nil).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	parent.End()
+
+	client := findSpan(exp.GetSpans(), "HTTP POST")
+	if client == nil {
+		t.Fatal("client span not recorded")
+	}
+	want := "00-" + parent.SpanContext().TraceID().String() + "-" + client.SpanContext.SpanID().String() + "-01"
+	if gotTraceparent != want {
+		t.Errorf("traceparent = %q, want %q", gotTraceparent, want)
+	}
+}
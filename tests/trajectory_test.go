@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/geo"
+)
+
+func TestBuildTrajectory(t *testing.T) {
+	base := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	sightings := []geo.Sighting{
+		// Bundaran HI -> Semanggi (~2.9 km) -> Blok M (~4.5 km).
+		{SuspectID: 2, CameraID: 1, Latitude: -6.1950, Longitude: 106.8230, Time: base.Add(10 * time.Minute), Score: 0.9},
+		{SuspectID: 3, CameraID: 2, Latitude: -6.2200, Longitude: 106.8140, Time: base.Add(20 * time.Minute), Score: 0.8},
+		{SuspectID: 4, CameraID: 3, Latitude: -6.2440, Longitude: 106.8000, Time: base.Add(35 * time.Minute), Score: 0.7},
+		// False positive di Surabaya lebih dulu, dan satu di Bandung lima
+		// menit setelah Semanggi.
+		{SuspectID: 1, CameraID: 9, Latitude: -7.2500, Longitude: 112.7500, Time: base, Score: 0.95},
+		{SuspectID: 5, CameraID: 8, Latitude: -6.8850, Longitude: 107.6130, Time: base.Add(25 * time.Minute), Score: 0.99},
+		// Deteksi lain di kamera yang sama pada waktu yang sama tetap
+		// mungkin.
+		{SuspectID: 6, CameraID: 3, Latitude: -6.2440, Longitude: 106.8000, Time: base.Add(35 * time.Minute), Score: 0.6},
+	}
+
+	tr := geo.BuildTrajectory(sightings, 120)
+	var ids []int64
+	for _, p := range tr.Points {
+		ids = append(ids, p.SuspectID)
+	}
+	if want := []int64{2, 3, 4, 6}; len(ids) != len(want) || ids[0] != 2 || ids[1] != 3 || ids[2] != 4 || ids[3] != 6 {
+		t.Fatalf("route = %v, want %v", ids, want)
+	}
+	if len(tr.Hops) != 3 || tr.Hops[0].ElapsedSeconds != 600 || tr.Hops[0].SpeedKmh < 15 || tr.Hops[0].SpeedKmh > 20 || tr.Hops[2].DistanceKm != 0 {
+		t.Errorf("hops = %+v", tr.Hops)
+	}
+	if len(tr.Rejected) != 2 || tr.Rejected[0].SuspectID != 1 || tr.Rejected[1].SuspectID != 5 {
+		t.Fatalf("rejected = %+v", tr.Rejected)
+	}
+	for _, r := range tr.Rejected {
+		if r.RequiredSpeedKmh == nil || *r.RequiredSpeedKmh <= 120 {
+			t.Errorf("rejected %d required speed = %v", r.SuspectID, r.RequiredSpeedKmh)
+		}
+	}
+
+	// Batas kecepatan yang sangat rendah menyisakan titik di Blok M saja.
+	if tr := geo.BuildTrajectory(sightings, 10); len(tr.Points) != 2 || tr.Feature(nil).Geometry == nil {
+		t.Errorf("slow route = %+v", tr.Points)
+	}
+	if f := geo.BuildTrajectory(sightings[:1], 120).Feature(nil); f.Geometry != nil {
+		t.Errorf("single point trajectory geometry = %+v, want null", f.Geometry)
+	}
+}
+
+func TestTrajectoryEndpoint(t *testing.T) {
+	f := newFixture(t)
+	owner := f.createUser("u1", "u1@example.com", false)
+	other := f.createUser("u2", "u2@example.com", false)
+	admin := f.createUser("a1", "a1@example.com", true)
+	lostID := f.createLostReport("u1", f.createVehicle("u1"))
+	ctx := context.Background()
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	var suspectIDs []int64
+	for i, c := range []struct {
+		lat, lon float64
+		offset   time.Duration
+		score    float64
+	}{
+		{-6.1950, 106.8230, 0, 0.9},
+		{-6.2200, 106.8140, 10 * time.Minute, 0.4},
+		{-6.8850, 107.6130, 12 * time.Minute, 0.8},
+	} {
+		cam := database.Camera{Name: "Cam " + strconv.Itoa(i), Latitude: c.lat, Longitude: c.lon, IsActive: true}
+		if err := f.store.Repos().Cameras.Create(ctx, &cam); err != nil {
+			t.Fatal(err)
+		}
+		det := database.Detected{CameraID: int(cam.CameraID), Timestamp: base.Add(c.offset)}
+		if err := f.store.Repos().Detected.Create(ctx, &det); err != nil {
+			t.Fatal(err)
+		}
+		sp := database.Suspect{DetectedID: int64(det.DetectedID), LostID: int64(lostID), FinalScore: c.score}
+		if err := f.store.Repos().Suspects.Create(ctx, &sp); err != nil {
+			t.Fatal(err)
+		}
+		suspectIDs = append(suspectIDs, sp.SuspectID)
+	}
+	path := "/api/results/" + strconv.Itoa(lostID) + "/trajectory"
+
+	var feature struct {
+		Type     string `json:"type"`
+		Geometry *struct {
+			Type        string      `json:"type"`
+			Coordinates [][]float64 `json:"coordinates"`
+		} `json:"geometry"`
+		Properties struct {
+			LostReportID int                    `json:"lost_report_id"`
+			Points       []geo.Sighting         `json:"points"`
+			Hops         []geo.Hop              `json:"hops"`
+			Rejected     []geo.RejectedSighting `json:"rejected"`
+		} `json:"properties"`
+	}
+	rec := f.do("GET", path, owner, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if err := json.NewDecoder(rec.Body).Decode(&feature); err != nil {
+		t.Fatal(err)
+	}
+	if feature.Type != "Feature" || feature.Geometry == nil || feature.Geometry.Type != "LineString" || len(feature.Geometry.Coordinates) != 2 {
+		t.Fatalf("trajectory = %+v", feature)
+	}
+	if c := feature.Geometry.Coordinates[0]; c[0] != 106.8230 || c[1] != -6.1950 {
+		t.Errorf("first coordinate = %v, want [lon, lat]", c)
+	}
+	if len(feature.Properties.Hops) != 1 || len(feature.Properties.Rejected) != 1 || feature.Properties.Rejected[0].CameraName != "Cam 2" {
+		t.Errorf("properties = %+v", feature.Properties)
+	}
+
+	// Dengan min_score 0.5 suspect Semanggi terbuang, sehingga rute memilih
+	// salah satu titik saja dan geometry-nya null.
+	rec = f.do("GET", path+"?min_score=0.5", admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	feature.Geometry = nil
+	json.NewDecoder(rec.Body).Decode(&feature)
+	if feature.Geometry != nil || len(feature.Properties.Points) != 1 {
+		t.Errorf("min_score trajectory = %+v", feature)
+	}
+
+	// Suspect yang ditolak admin atau ditandai bukan milik pemilik keluar
+	// dari rute dan dari daftar rejected.
+	suspectPath := func(id int64, action string) string { return "/api/suspects/" + strconv.FormatInt(id, 10) + "/" + action }
+	expectStatus(t, f.do("POST", suspectPath(suspectIDs[0], "review"), admin, map[string]string{"status": database.SuspectReviewRejected}), http.StatusOK)
+	expectStatus(t, f.do("POST", suspectPath(suspectIDs[2], "feedback"), owner, map[string]string{"feedback": database.OwnerFeedbackNotMine}), http.StatusOK)
+	rec = f.do("GET", path, owner, nil)
+	expectStatus(t, rec, http.StatusOK)
+	feature.Geometry, feature.Properties.Points, feature.Properties.Rejected = nil, nil, nil
+	json.NewDecoder(rec.Body).Decode(&feature)
+	if len(feature.Properties.Points) != 1 || feature.Properties.Points[0].SuspectID != suspectIDs[1] || len(feature.Properties.Rejected) != 0 {
+		t.Errorf("trajectory after review = %+v", feature.Properties)
+	}
+
+	expectStatus(t, f.do("GET", path, other, nil), http.StatusForbidden)
+	expectStatus(t, f.do("GET", path+"?max_speed_kmh=1000", owner, nil), http.StatusBadRequest)
+	expectStatus(t, f.do("GET", "/api/results/9999/trajectory", admin, nil), http.StatusNotFound)
+}
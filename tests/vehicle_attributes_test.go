@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/server"
+)
+
+func TestVehicleAttributes(t *testing.T) {
+	f := newFixture(t)
+	owner := f.createUser("u1", "u1@example.com", false)
+
+	fields := map[string]string{
+		"vehicle_name":         "Honda Beat",
+		"plate_number":         "B 1234 XYZ",
+		"color":                "Merah marun",
+		"brand":                "Honda",
+		"model":                "Beat",
+		"year":                 "2021",
+		"body_type":            "Scooter",
+		"distinguishing_marks": "stiker klub di spakbor belakang",
+	}
+	rec := f.doMultipart("POST", "/api/vehicles", owner, fields)
+	expectStatus(t, rec, http.StatusCreated)
+	var v server.VehicleResponse
+	json.NewDecoder(rec.Body).Decode(&v)
+	if v.Brand == nil || *v.Brand != "Honda" || v.Year == nil || *v.Year != 2021 || v.BodyType == nil || *v.BodyType != "scooter" ||
+		v.NormalizedColor == nil || *v.NormalizedColor != "red" || v.DistinguishingMarks == nil {
+		t.Fatalf("created vehicle = %+v", v)
+	}
+
+	for field, value := range map[string]string{"year": "1800", "body_type": "truck", "normalized_color": "transparan"} {
+		bad := map[string]string{"vehicle_name": "Vario", "plate_number": "B 1 A", field: value}
+		expectStatus(t, f.doMultipart("POST", "/api/vehicles", owner, bad), http.StatusBadRequest)
+	}
+
+	path := "/api/vehicles/" + strconv.FormatInt(v.VehicleID, 10)
+	rec = f.doMultipart("PUT", path, owner, map[string]string{"year": "", "color": "hitam doff"})
+	expectStatus(t, rec, http.StatusOK)
+	v = server.VehicleResponse{}
+	json.NewDecoder(rec.Body).Decode(&v)
+	if v.Year != nil || v.NormalizedColor == nil || *v.NormalizedColor != "black" || v.Brand == nil || *v.Brand != "Honda" {
+		t.Errorf("updated vehicle = %+v", v)
+	}
+}
+
+func TestDetectionAttributeSearch(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	owner := f.createUser("u1", "u1@example.com", false)
+	ctx := context.Background()
+
+	cams := []database.Camera{
+		{Name: "Bundaran HI", Latitude: -6.1955, Longitude: 106.8232, IsActive: true},
+		{Name: "Sarinah", Latitude: -6.1874, Longitude: 106.8236, IsActive: true},
+		{Name: "Dago", Latitude: -6.9000, Longitude: 107.6000, IsActive: true},
+	}
+	for i := range cams {
+		if err := f.store.Repos().Cameras.Create(ctx, &cams[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	evening := time.Date(2024, 5, 2, 18, 0, 0, 0, time.UTC)
+
+	detect := func(cam int, at time.Time, attrs map[string]string) int {
+		t.Helper()
+		fields := map[string]string{"camera_id": strconv.FormatInt(cams[cam].CameraID, 10), "timestamp": at.Format(time.RFC3339)}
+		for k, v := range attrs {
+			fields[k] = v
+		}
+		rec := f.doMultipart("POST", "/api/detected", admin, fields)
+		expectStatus(t, rec, http.StatusCreated)
+		var d server.DetectedResponse
+		json.NewDecoder(rec.Body).Decode(&d)
+		return d.DetectedID
+	}
+	red := map[string]string{"brand": "Honda", "model": "Beat Street", "color": "merah", "body_type": "scooter", "attribute_confidence": "0.9"}
+	first := detect(0, evening, red)
+	detect(1, evening.Add(time.Hour), red)
+	detect(2, evening, red)
+	detect(0, evening, map[string]string{"brand": "Yamaha", "color": "red"})
+	detect(0, evening.Add(-24*time.Hour), red)
+	unlabeled := detect(1, evening.Add(30*time.Minute), nil)
+
+	expectStatus(t, f.doMultipart("POST", "/api/detected", admin, map[string]string{
+		"camera_id": strconv.FormatInt(cams[0].CameraID, 10), "timestamp": evening.Format(time.RFC3339), "color": "transparan",
+	}), http.StatusBadRequest)
+
+	// Detector dapat melampirkan atribut setelah deteksi dibuat.
+	attrPath := "/api/detected/" + strconv.Itoa(unlabeled) + "/attributes"
+	expectStatus(t, f.do("PUT", attrPath, owner, map[string]string{"brand": "Honda"}), http.StatusForbidden)
+	expectStatus(t, f.do("PUT", attrPath, admin, map[string]interface{}{"brand": "Honda", "confidence": 1.5}), http.StatusBadRequest)
+	expectStatus(t, f.do("PUT", "/api/detected/9999/attributes", admin, map[string]string{"brand": "Honda"}), http.StatusNotFound)
+	rec := f.do("PUT", attrPath, admin, map[string]interface{}{"brand": "honda", "model": "BEAT", "color": "Red", "confidence": 0.6})
+	expectStatus(t, rec, http.StatusOK)
+	var labeled server.DetectedResponse
+	json.NewDecoder(rec.Body).Decode(&labeled)
+	if labeled.Attributes == nil || *labeled.Attributes.Color != "red" {
+		t.Errorf("labeled detection = %+v", labeled)
+	}
+
+	search := func(q url.Values) server.DetectionSearchResponse {
+		t.Helper()
+		q.Set("start_time", evening.Add(-time.Hour).Format(time.RFC3339))
+		q.Set("end_time", evening.Add(3*time.Hour).Format(time.RFC3339))
+		rec := f.do("GET", "/api/admins/detections/search?"+q.Encode(), admin, nil)
+		expectStatus(t, rec, http.StatusOK)
+		var resp server.DetectionSearchResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return resp
+	}
+
+	// "Honda Beat merah di sekitar Bundaran HI kemarin sore."
+	near := url.Values{"color": {"merah"}, "brand": {"HONDA"}, "model": {"beat"}, "camera_id": {strconv.FormatInt(cams[0].CameraID, 10)}, "radius_km": {"2"}}
+	resp := search(near)
+	if resp.Total != 3 || len(resp.Results) != 3 || len(resp.CameraIDs) != 2 || resp.Results[2].DetectedID != first || resp.Results[2].CameraName != "Bundaran HI" {
+		t.Errorf("near search = %+v", resp)
+	}
+	near.Set("min_confidence", "0.8")
+	if resp := search(near); resp.Total != 2 {
+		t.Errorf("min_confidence search total = %d, want 2", resp.Total)
+	}
+	if resp := search(url.Values{"color": {"red"}, "limit": {"2"}, "offset": {"3"}}); resp.Total != 5 || len(resp.Results) != 2 {
+		t.Errorf("paginated search = %+v", resp)
+	}
+	// Karakter wildcard LIKE di model dicari apa adanya.
+	underscore := detect(2, evening, map[string]string{"brand": "Honda", "model": "CB150_R", "color": "merah"})
+	if resp := search(url.Values{"model": {"_"}}); resp.Total != 1 || resp.Results[0].DetectedID != underscore {
+		t.Errorf("model _ search = %+v", resp)
+	}
+	if resp := search(url.Values{"model": {"%"}}); resp.Total != 0 {
+		t.Errorf("model %% search total = %d, want 0", resp.Total)
+	}
+
+	expectStatus(t, f.do("GET", "/api/admins/detections/search?color=red", admin, nil), http.StatusBadRequest)
+	expectStatus(t, f.do("GET", "/api/admins/detections/search?color=red&start_time=2024-05-01T00:00:00Z&end_time=2024-07-01T00:00:00Z", admin, nil), http.StatusBadRequest)
+	expectStatus(t, f.do("GET", "/api/admins/detections/search?color=transparan&start_time=2024-05-01T00:00:00Z&end_time=2024-05-02T00:00:00Z", admin, nil), http.StatusBadRequest)
+	expectStatus(t, f.do("GET", "/api/admins/detections/search?start_time=2024-05-01T00:00:00Z&end_time=2024-05-02T00:00:00Z", owner, nil), http.StatusForbidden)
+}
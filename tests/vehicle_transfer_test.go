@@ -0,0 +1,236 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jaga-project/jaga-backend/internal/database"
+	"github.com/jaga-project/jaga-backend/internal/server"
+)
+
+func (f *fixture) postVehicleWithSTNK(token, plate string) *httptest.ResponseRecorder {
+	f.t.Helper()
+	return f.doUpload("POST", "/api/vehicles", token,
+		map[string]string{"vehicle_name": "Vario", "plate_number": plate},
+		map[string][]byte{"stnk_image": pngBytes(f.t)})
+}
+
+func TestVehiclePlateUniqueness(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	budi := f.createUser("u1", "u1@example.com", false)
+	siti := f.createUser("u2", "u2@example.com", false)
+
+	rec := f.doMultipart("POST", "/api/vehicles", budi, map[string]string{"vehicle_name": "Beat", "plate_number": " b-1234-xyz "})
+	expectStatus(t, rec, http.StatusCreated)
+	var v server.VehicleResponse
+	json.NewDecoder(rec.Body).Decode(&v)
+	if v.PlateNumber != "B 1234 XYZ" || v.VerificationStatus != database.VerificationUnverified {
+		t.Errorf("created vehicle = %+v", v)
+	}
+
+	expectStatus(t, f.doMultipart("POST", "/api/vehicles", siti, map[string]string{"vehicle_name": "Vario", "plate_number": "B1234XYZ"}), http.StatusConflict)
+	expectStatus(t, f.doMultipart("POST", "/api/vehicles", siti, map[string]string{"vehicle_name": "Vario", "plate_number": "12345"}), http.StatusBadRequest)
+
+	rec = f.do("GET", "/api/vehicles/plate/b1234xyz", admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	// Plat kendaraan yang dihapus boleh didaftarkan ulang; kendaraan lama
+	// tidak bisa di-restore selama platnya dipakai.
+	path := "/api/vehicles/" + strconv.FormatInt(v.VehicleID, 10)
+	expectStatus(t, f.do("DELETE", path, budi, nil), http.StatusNoContent)
+	rec = f.doMultipart("POST", "/api/vehicles", siti, map[string]string{"vehicle_name": "Vario", "plate_number": "B 1234 XYZ"})
+	expectStatus(t, rec, http.StatusCreated)
+	var other server.VehicleResponse
+	json.NewDecoder(rec.Body).Decode(&other)
+	expectStatus(t, f.do("POST", path+"/restore", admin, nil), http.StatusConflict)
+
+	budiVehicle := f.createVehicle("u1")
+	expectStatus(t, f.doMultipart("PUT", "/api/vehicles/"+strconv.FormatInt(budiVehicle, 10), budi, map[string]string{"plate_number": "b 1234 xyz"}), http.StatusConflict)
+}
+
+func TestVehicleVerification(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	owner := f.createUser("u1", "u1@example.com", false)
+
+	rec := f.postVehicleWithSTNK(owner, "D 1 ABC")
+	expectStatus(t, rec, http.StatusCreated)
+	var v server.VehicleResponse
+	json.NewDecoder(rec.Body).Decode(&v)
+	if v.VerificationStatus != database.VerificationPending {
+		t.Fatalf("verification_status = %q, want PENDING", v.VerificationStatus)
+	}
+	expectStatus(t, f.postLostReport(owner, v.VehicleID), http.StatusForbidden)
+
+	verifyPath := "/api/admins/vehicles/" + strconv.FormatInt(v.VehicleID, 10) + "/verification"
+	stnkImage, _ := f.vehicleImages(v.VehicleID)
+	expectStatus(t, f.do("POST", verifyPath, owner, verifyBody("VERIFIED", stnkImage)), http.StatusForbidden)
+	expectStatus(t, f.do("POST", verifyPath, admin, verifyBody("REJECTED", stnkImage)), http.StatusBadRequest)
+	expectStatus(t, f.do("POST", verifyPath, admin, verifyBody("UNVERIFIED", stnkImage)), http.StatusBadRequest)
+	rec = f.do("POST", verifyPath, admin, verifyBody("VERIFIED", stnkImage))
+	expectStatus(t, rec, http.StatusOK)
+	json.NewDecoder(rec.Body).Decode(&v)
+	if v.VerificationStatus != database.VerificationVerified || v.VerifiedBy == nil || *v.VerifiedBy != "a1" || v.VerifiedAt == nil {
+		t.Errorf("verified vehicle = %+v", v)
+	}
+	expectStatus(t, f.postLostReport(owner, v.VehicleID), http.StatusCreated)
+
+	// Plat berubah: STNK harus diperiksa ulang.
+	rec = f.doMultipart("PUT", "/api/vehicles/"+strconv.FormatInt(v.VehicleID, 10), owner, map[string]string{"plate_number": "D 2 ABC"})
+	expectStatus(t, rec, http.StatusOK)
+	v = server.VehicleResponse{}
+	json.NewDecoder(rec.Body).Decode(&v)
+	if v.VerificationStatus != database.VerificationPending || v.VerifiedBy != nil {
+		t.Errorf("vehicle after plate change = %+v", v)
+	}
+
+	noSTNK := f.doMultipart("POST", "/api/vehicles", owner, map[string]string{"vehicle_name": "Beat", "plate_number": "D 3 ABC"})
+	expectStatus(t, noSTNK, http.StatusCreated)
+	var bare server.VehicleResponse
+	json.NewDecoder(noSTNK.Body).Decode(&bare)
+	expectStatus(t, f.do("POST", "/api/admins/vehicles/"+strconv.FormatInt(bare.VehicleID, 10)+"/verification", admin, verifyBody("VERIFIED", stnkImage)), http.StatusConflict)
+
+	// Laporan hanya untuk kendaraan milik sendiri.
+	expectStatus(t, f.postLostReport(admin, f.createVehicle("u1")), http.StatusForbidden)
+
+	if n := len(f.auditEntries(admin, "?action=vehicle.verify")); n != 1 {
+		t.Errorf("got %d vehicle.verify entries, want 1", n)
+	}
+}
+
+func TestVehicleTransfer(t *testing.T) {
+	f := newFixture(t)
+	admin := f.createUser("a1", "a1@example.com", true)
+	budi := f.createUser("u1", "u1@example.com", false)
+	siti := f.createUser("u2", "u2@example.com", false)
+	other := f.createUser("u3", "u3@example.com", false)
+	vehicleID := f.createVehicle("u1")
+	path := "/api/vehicles/" + strconv.FormatInt(vehicleID, 10) + "/transfers"
+
+	expectStatus(t, f.do("POST", path, siti, map[string]string{"recipient_email": "u3@example.com"}), http.StatusForbidden)
+	expectStatus(t, f.do("POST", path, budi, map[string]string{"recipient_email": "u1@example.com"}), http.StatusBadRequest)
+	expectStatus(t, f.do("POST", path, budi, map[string]string{"recipient_email": "nobody@example.com"}), http.StatusNotFound)
+
+	rec := f.do("POST", path, budi, map[string]string{"recipient_email": "u2@example.com", "note": "dijual"})
+	expectStatus(t, rec, http.StatusCreated)
+	var transfer database.VehicleTransfer
+	json.NewDecoder(rec.Body).Decode(&transfer)
+	if transfer.Status != database.TransferPending || transfer.ToUserID != "u2" {
+		t.Fatalf("transfer = %+v", transfer)
+	}
+	expectStatus(t, f.do("POST", path, budi, map[string]string{"recipient_email": "u3@example.com"}), http.StatusConflict)
+	if msgs := f.mail.Messages(); len(msgs) != 1 || msgs[0].To != "u2@example.com" {
+		t.Errorf("transfer mails = %+v", msgs)
+	}
+
+	rec = f.do("GET", "/api/vehicles/transfers", siti, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var incoming []database.VehicleTransfer
+	json.NewDecoder(rec.Body).Decode(&incoming)
+	if len(incoming) != 1 || incoming[0].PlateNumber != "B 1234 u1" {
+		t.Errorf("incoming transfers = %+v", incoming)
+	}
+
+	decide := func(token, action string) *httptest.ResponseRecorder {
+		return f.do("POST", "/api/vehicles/transfers/"+strconv.FormatInt(transfer.TransferID, 10)+"/"+action, token, nil)
+	}
+	expectStatus(t, decide(other, "accept"), http.StatusForbidden)
+	expectStatus(t, decide(budi, "accept"), http.StatusForbidden)
+	expectStatus(t, decide(siti, "accept"), http.StatusOK)
+	expectStatus(t, decide(siti, "accept"), http.StatusConflict)
+
+	v, err := f.store.Repos().Vehicles.GetByID(context.Background(), vehicleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.UserID != "u2" || v.VerificationStatus != database.VerificationUnverified {
+		t.Errorf("transferred vehicle = %+v", v)
+	}
+	expectStatus(t, f.postLostReport(budi, vehicleID), http.StatusForbidden)
+	expectStatus(t, f.postLostReport(siti, vehicleID), http.StatusForbidden)
+
+	// Riwayat hanya untuk pemilik saat ini dan admin.
+	expectStatus(t, f.do("GET", path, budi, nil), http.StatusForbidden)
+	rec = f.do("GET", path, admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var history []database.VehicleTransfer
+	json.NewDecoder(rec.Body).Decode(&history)
+	if len(history) != 1 || history[0].Status != database.TransferAccepted || history[0].DecidedAt == nil {
+		t.Errorf("history = %+v", history)
+	}
+
+	// Permintaan kedaluwarsa tidak bisa diterima dan tidak menghalangi
+	// permintaan baru.
+	stale := database.VehicleTransfer{VehicleID: vehicleID, FromUserID: "u2", ToUserID: "u3", CreatedAt: time.Now().Add(-8 * 24 * time.Hour), ExpiresAt: time.Now().Add(-24 * time.Hour)}
+	if err := f.store.Repos().Transfers.Create(context.Background(), &stale); err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(t, f.do("POST", "/api/vehicles/transfers/"+strconv.FormatInt(stale.TransferID, 10)+"/accept", other, nil), http.StatusConflict)
+	rec = f.do("POST", path, siti, map[string]string{"recipient_email": "u3@example.com"})
+	expectStatus(t, rec, http.StatusCreated)
+	json.NewDecoder(rec.Body).Decode(&transfer)
+	expectStatus(t, decide(other, "cancel"), http.StatusForbidden)
+	expectStatus(t, decide(siti, "cancel"), http.StatusOK)
+
+	// Kendaraan dengan laporan kehilangan yang masih terbuka tidak bisa
+	// dipindahkan.
+	reported := f.createVehicle("u3")
+	f.createLostReport("u3", reported)
+	expectStatus(t, f.do("POST", "/api/vehicles/"+strconv.FormatInt(reported, 10)+"/transfers", other, map[string]string{"recipient_email": "u2@example.com"}), http.StatusConflict)
+
+	if n := len(f.auditEntries(admin, "?action=vehicle.transfer")); n != 1 {
+		t.Errorf("got %d vehicle.transfer entries, want 1", n)
+	}
+}
+
+func TestVehicleTransferWithOpenLostReport(t *testing.T) {
+	f := newFixture(t)
+	budi := f.createUser("u1", "u1@example.com", false)
+	siti := f.createUser("u2", "u2@example.com", false)
+	f.createUser("u3", "u3@example.com", false)
+	ctx := context.Background()
+	setStatus := func(lostID int, status string) {
+		t.Helper()
+		lr, err := f.store.Repos().LostReports.GetByID(ctx, lostID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lr.Status = status
+		if err := f.store.Repos().LostReports.Update(ctx, lostID, lr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Permintaan baru ditolak selama ada laporan terbuka untuk kendaraan,
+	// termasuk laporan yang dibuat user lain.
+	vehicleID := f.createVehicle("u1")
+	path := "/api/vehicles/" + strconv.FormatInt(vehicleID, 10) + "/transfers"
+	lostID := f.createLostReport("u3", vehicleID)
+	expectStatus(t, f.do("POST", path, budi, map[string]string{"recipient_email": "u2@example.com"}), http.StatusConflict)
+	setStatus(lostID, database.StatusLostReportSedangDiproses)
+	expectStatus(t, f.do("POST", path, budi, map[string]string{"recipient_email": "u2@example.com"}), http.StatusConflict)
+	setStatus(lostID, database.StatusLostReportSudahDitemukan)
+
+	// Laporan yang dibuat setelah permintaan dikirim menahan penerimaan.
+	rec := f.do("POST", path, budi, map[string]string{"recipient_email": "u2@example.com"})
+	expectStatus(t, rec, http.StatusCreated)
+	var transfer database.VehicleTransfer
+	json.NewDecoder(rec.Body).Decode(&transfer)
+	acceptPath := "/api/vehicles/transfers/" + strconv.FormatInt(transfer.TransferID, 10) + "/accept"
+	lostID = f.createLostReport("u1", vehicleID)
+	expectStatus(t, f.do("POST", acceptPath, siti, nil), http.StatusConflict)
+	if v, err := f.store.Repos().Vehicles.GetByID(ctx, vehicleID); err != nil || v.UserID != "u1" {
+		t.Errorf("vehicle after rejected accept = %+v, %v", v, err)
+	}
+	if tr, err := f.store.Repos().Transfers.GetByID(ctx, transfer.TransferID); err != nil || tr.Status != database.TransferPending {
+		t.Errorf("transfer after rejected accept = %+v, %v", tr, err)
+	}
+	setStatus(lostID, database.StatusLostReportSudahDitemukan)
+	expectStatus(t, f.do("POST", acceptPath, siti, nil), http.StatusOK)
+}